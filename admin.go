@@ -0,0 +1,210 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard"
+)
+
+// captureControl starts and stops a global capture out of band (see Instance.AdminHandler and Instance.ToggleCaptureOnSignal).
+// The events of the last capture stay available until the next capture is started.
+type captureControl struct {
+	mu      sync.Mutex
+	capture *CaptureHandle
+	active  bool
+}
+
+// startControlledCapture starts a global capture, it fails if a capture is already active
+func (i *Instance) startControlledCapture(capacity uint64) error {
+	i.control.mu.Lock()
+	defer i.control.mu.Unlock()
+
+	if i.control.active {
+		return errors.New("capture already active")
+	}
+	capture, err := i.StartGlobalCapture(capacity)
+	if err != nil {
+		return err
+	}
+	i.control.capture = capture
+	i.control.active = true
+	return nil
+}
+
+// stopControlledCapture stops the global capture and reports whether it was active
+func (i *Instance) stopControlledCapture() bool {
+	i.control.mu.Lock()
+	defer i.control.mu.Unlock()
+
+	if !i.control.active {
+		return false
+	}
+	i.control.capture.Stop()
+	i.control.active = false
+	return true
+}
+
+// toggleControlledCapture starts a global capture or stops the active one
+func (i *Instance) toggleControlledCapture() {
+	if !i.stopControlledCapture() {
+		_ = i.startControlledCapture(dashboard.DefaultStorageCapacity)
+	}
+}
+
+// AdminHandler returns a minimal handler to control a global capture without the dashboard,
+// e.g. from CI or scripts around a reproduction scenario:
+//
+//	POST /capture/start  starts capturing (form value "capacity", default: dashboard.DefaultStorageCapacity)
+//	POST /capture/stop   stops capturing
+//	GET  /capture/status returns whether it is capturing and the number of captured events as JSON
+//	GET  /capture/events returns the events of the active or last capture as JSON, oldest first
+//	                     (query parameters "limit", "children" ("nested", "exclude" or "flatten"), "depth",
+//	                     "maxBytes" and "last" (e.g. "5m"), see collector.ReadOptions)
+//	POST /enable         enables collecting (see Instance.Enable)
+//	POST /disable        disables collecting, all collectors pass through (see Instance.Disable)
+//
+// The handler is not protected, mount it only where it cannot be reached from the outside.
+func (i *Instance) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /capture/start", func(w http.ResponseWriter, r *http.Request) {
+		capacity := dashboard.DefaultStorageCapacity
+		if value := r.FormValue("capacity"); value != "" {
+			var err error
+			capacity, err = strconv.ParseUint(value, 10, 64)
+			if err != nil || capacity == 0 {
+				http.Error(w, fmt.Sprintf("Invalid capacity: %q", value), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := i.startControlledCapture(capacity); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /capture/stop", func(w http.ResponseWriter, r *http.Request) {
+		if !i.stopControlledCapture() {
+			http.Error(w, "No capture active", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /capture/status", func(w http.ResponseWriter, r *http.Request) {
+		i.control.mu.Lock()
+		status := AdminCaptureStatus{Enabled: i.Enabled(), Capturing: i.control.active}
+		if i.control.capture != nil {
+			status.EventCount = len(i.control.capture.Events())
+		}
+		i.control.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	})
+
+	mux.HandleFunc("GET /capture/events", func(w http.ResponseWriter, r *http.Request) {
+		i.control.mu.Lock()
+		capture := i.control.capture
+		i.control.mu.Unlock()
+
+		if capture == nil {
+			http.Error(w, "No capture started", http.StatusNotFound)
+			return
+		}
+		opts, err := parseReadOptions(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(capture.ReadEvents(opts))
+	})
+
+	mux.HandleFunc("POST /enable", func(w http.ResponseWriter, r *http.Request) {
+		i.Enable()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /disable", func(w http.ResponseWriter, r *http.Request) {
+		i.Disable()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+// parseReadOptions parses the query parameters "limit", "children", "depth", "maxBytes" and "last" of a request for
+// events
+func parseReadOptions(r *http.Request) (collector.ReadOptions, error) {
+	var opts collector.ReadOptions
+	query := r.URL.Query()
+
+	var err error
+	opts.Children, err = collector.ParseChildrenMode(query.Get("children"))
+	if err != nil {
+		return opts, err
+	}
+	for name, target := range map[string]*uint64{"limit": &opts.Limit, "maxBytes": &opts.MaxBytes} {
+		if value := query.Get(name); value != "" {
+			*target, err = strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s: %q", name, value)
+			}
+		}
+	}
+	if value := query.Get("depth"); value != "" {
+		opts.MaxDepth, err = strconv.Atoi(value)
+		if err != nil || opts.MaxDepth < 0 {
+			return opts, fmt.Errorf("invalid depth: %q", value)
+		}
+	}
+	if value := query.Get("last"); value != "" {
+		last, err := time.ParseDuration(value)
+		if err != nil || last <= 0 {
+			return opts, fmt.Errorf("invalid last: %q", value)
+		}
+		opts.Since = time.Now().Add(-last)
+	}
+	return opts, nil
+}
+
+// ToggleCaptureOnSignal starts a global capture when one of the signals is received and stops it on the next one,
+// e.g. with syscall.SIGUSR1: `kill -USR1 <pid>`. The events can be fetched with the events endpoint of AdminHandler.
+// Call the returned function to stop handling the signals.
+func (i *Instance) ToggleCaptureOnSignal(signals ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				i.toggleControlledCapture()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
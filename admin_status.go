@@ -0,0 +1,9 @@
+package devlog
+
+// AdminCaptureStatus is the response of the status endpoint of Instance.AdminHandler
+type AdminCaptureStatus struct {
+	// Enabled is false if collecting is disabled (see Instance.Disable)
+	Enabled    bool `json:"enabled"`
+	Capturing  bool `json:"capturing"`
+	EventCount int  `json:"eventCount"`
+}
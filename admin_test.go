@@ -0,0 +1,155 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/networkteam/devlog"
+)
+
+func TestInstance_AdminHandler(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	admin := httptest.NewServer(dlog.AdminHandler())
+	defer admin.Close()
+
+	app := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer app.Close()
+
+	post := func(path string) int {
+		t.Helper()
+		resp, err := http.Post(admin.URL+path, "application/x-www-form-urlencoded", nil)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	get := func(url string, v any) int {
+		t.Helper()
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	if status := get(admin.URL+"/capture/events", nil); status != http.StatusNotFound {
+		t.Errorf("expected status %d without capture, got %d", http.StatusNotFound, status)
+	}
+	if status := post("/capture/start?capacity=invalid"); status != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid capacity, got %d", http.StatusBadRequest, status)
+	}
+	if status := post("/capture/start?capacity=10"); status != http.StatusNoContent {
+		t.Fatalf("expected status %d for start, got %d", http.StatusNoContent, status)
+	}
+	if status := post("/capture/start"); status != http.StatusConflict {
+		t.Errorf("expected status %d for second start, got %d", http.StatusConflict, status)
+	}
+
+	get(app.URL+"/reproduce", nil)
+
+	var captureStatus devlog.AdminCaptureStatus
+	get(admin.URL+"/capture/status", &captureStatus)
+	if !captureStatus.Capturing || captureStatus.EventCount != 1 {
+		t.Errorf("unexpected status %+v", captureStatus)
+	}
+
+	if status := post("/capture/stop"); status != http.StatusNoContent {
+		t.Fatalf("expected status %d for stop, got %d", http.StatusNoContent, status)
+	}
+	if status := post("/capture/stop"); status != http.StatusConflict {
+		t.Errorf("expected status %d for second stop, got %d", http.StatusConflict, status)
+	}
+
+	get(app.URL+"/after", nil)
+
+	var events []json.RawMessage
+	if status := get(admin.URL+"/capture/events", &events); status != http.StatusOK {
+		t.Fatalf("expected status %d for events, got %d", http.StatusOK, status)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected 1 event of the stopped capture, got %d", len(events))
+	}
+	if status := get(admin.URL+"/capture/events?children=exclude&maxBytes=1000000", &events); status != http.StatusOK || len(events) != 1 {
+		t.Errorf("expected status %d and 1 event with read options, got %d and %d", http.StatusOK, status, len(events))
+	}
+	if status := get(admin.URL+"/capture/events?last=1h", &events); status != http.StatusOK || len(events) != 1 {
+		t.Errorf("expected status %d and 1 event of the last hour, got %d and %d", http.StatusOK, status, len(events))
+	}
+	if status := get(admin.URL+"/capture/events?children=deep", nil); status != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid children mode, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func TestInstance_AdminHandler_EnableDisable(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	admin := httptest.NewServer(dlog.AdminHandler())
+	defer admin.Close()
+
+	app := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer app.Close()
+
+	post := func(path string) {
+		t.Helper()
+		resp, err := http.Post(admin.URL+path, "application/x-www-form-urlencoded", nil)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected status %d for %s, got %d", http.StatusNoContent, path, resp.StatusCode)
+		}
+	}
+	status := func() devlog.AdminCaptureStatus {
+		t.Helper()
+		resp, err := http.Get(admin.URL + "/capture/status")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var status devlog.AdminCaptureStatus
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return status
+	}
+	request := func() {
+		t.Helper()
+		resp, err := http.Get(app.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	post("/capture/start")
+	post("/disable")
+	if dlog.Enabled() {
+		t.Fatal("expected instance to be disabled")
+	}
+
+	request()
+	if s := status(); s.Enabled || s.EventCount != 0 {
+		t.Errorf("expected no events while disabled, got status %+v", s)
+	}
+
+	post("/enable")
+	request()
+	if s := status(); !s.Enabled || s.EventCount != 1 {
+		t.Errorf("expected 1 event after enabling, got status %+v", s)
+	}
+}
@@ -0,0 +1,69 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// CaptureHandle controls a capture that was started programmatically (e.g. in a test), independent of the dashboard.
+type CaptureHandle struct {
+	storage    *collector.CaptureStorage
+	aggregator *collector.EventAggregator
+
+	stopOnce sync.Once
+}
+
+// StartGlobalCapture starts capturing all events into a new storage that keeps the most recent capacity top-level events.
+// The capture runs until Stop is called, captured events stay available after stopping.
+//
+//	capture, err := dlog.StartGlobalCapture(100)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer capture.Stop()
+func (i *Instance) StartGlobalCapture(capacity uint64) (*CaptureHandle, error) {
+	if capacity == 0 {
+		return nil, errors.New("capacity must be greater than zero")
+	}
+
+	storage := collector.NewCaptureStorage(uuid.Nil, capacity, collector.CaptureModeGlobal)
+	i.eventAggregator.RegisterStorage(storage)
+
+	return &CaptureHandle{
+		storage:    storage,
+		aggregator: i.eventAggregator,
+	}, nil
+}
+
+// Events returns the captured top-level events, oldest first
+func (h *CaptureHandle) Events() []*collector.Event {
+	return h.storage.GetEvents(h.storage.Stats().Capacity)
+}
+
+// ReadEvents returns the captured top-level events restricted by opts (e.g. without children or within a size budget),
+// oldest first
+func (h *CaptureHandle) ReadEvents(opts collector.ReadOptions) []*collector.Event {
+	return h.storage.ReadEvents(opts)
+}
+
+// Subscribe returns a channel that receives captured events until ctx is done or the capture is stopped.
+// Events of requests in progress are sent again when they complete (see collector.Event.InProgress).
+func (h *CaptureHandle) Subscribe(ctx context.Context) <-chan *collector.Event {
+	return h.storage.Subscribe(ctx)
+}
+
+// Stop stops capturing and closes all subscriptions, it is safe to call multiple times
+func (h *CaptureHandle) Stop() {
+	h.stopOnce.Do(func() {
+		h.aggregator.UnregisterStorage(h.storage.ID())
+		h.storage.SetCapturing(false)
+		h.storage.Close()
+	})
+}
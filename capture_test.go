@@ -0,0 +1,75 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkteam/devlog"
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInstance_StartGlobalCapture(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	if _, err := dlog.StartGlobalCapture(0); err == nil {
+		t.Error("expected error for zero capacity")
+	}
+
+	capture, err := dlog.StartGlobalCapture(10)
+	if err != nil {
+		t.Fatalf("failed to start capture: %v", err)
+	}
+	defer capture.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := capture.Subscribe(ctx)
+
+	server := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/tea")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case event := <-events:
+		request, ok := event.Data.(collector.HTTPServerRequest)
+		if !ok || request.Path != "/tea" {
+			t.Errorf("unexpected event data %#v", event.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a captured event")
+	}
+
+	if got := len(capture.Events()); got != 1 {
+		t.Errorf("expected 1 captured event, got %d", got)
+	}
+
+	capture.Stop()
+	capture.Stop()
+
+	if _, ok := <-events; ok {
+		t.Error("expected subscription to be closed after stopping")
+	}
+
+	resp, err = http.Get(server.URL + "/coffee")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := len(capture.Events()); got != 1 {
+		t.Errorf("expected events after stopping to be kept, got %d", got)
+	}
+}
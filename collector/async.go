@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncOptions configures processing of collected requests on a worker pool.
+// Transformers and the dispatch to storages then run after the response has been sent.
+type AsyncOptions struct {
+	// Workers is the number of goroutines processing collected requests.
+	// Default: 1
+	Workers int
+
+	// QueueSize is the maximum number of collected requests waiting to be processed.
+	// Requests are dropped if the queue is full.
+	// Default: 1000
+	QueueSize int
+}
+
+// DefaultAsyncOptions returns default options for asynchronous processing
+func DefaultAsyncOptions() AsyncOptions {
+	return AsyncOptions{
+		Workers:   1,
+		QueueSize: 1000,
+	}
+}
+
+// workerPool runs jobs on a fixed number of goroutines with a bounded queue
+type workerPool struct {
+	jobs    chan func()
+	wg      sync.WaitGroup
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newWorkerPool(options AsyncOptions) *workerPool {
+	defaults := DefaultAsyncOptions()
+	if options.Workers <= 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaults.QueueSize
+	}
+
+	p := &workerPool{
+		jobs: make(chan func(), options.QueueSize),
+	}
+	p.wg.Add(options.Workers)
+	for range options.Workers {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// submit queues a job without blocking. Returns false if the job was dropped because the queue is full or the pool is closed.
+func (p *workerPool) submit(job func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+// close waits until all queued jobs are processed and stops the workers
+func (p *workerPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
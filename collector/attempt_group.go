@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// maxAttemptGroupKeys is the number of recent attempt group keys that are remembered for grouping by key
+const maxAttemptGroupKeys = 1024
+
+// HTTPClientAttempt is the result of a single attempt of a retried outgoing request
+type HTTPClientAttempt struct {
+	// RequestID is the ID of the HTTPClientRequest of the attempt
+	RequestID  uuid.UUID
+	Attempt    int
+	StatusCode int
+	Duration   time.Duration
+	Error      error
+}
+
+type attemptGroupKeyType struct{}
+
+var attemptGroupKey = attemptGroupKeyType{}
+
+// attemptGroup numbers the attempts of one logical call and keeps the results of completed attempts
+type attemptGroup struct {
+	id        uuid.UUID
+	mu        sync.Mutex
+	started   int
+	completed []HTTPClientAttempt
+}
+
+func newAttemptGroup() *attemptGroup {
+	return &attemptGroup{id: generateID()}
+}
+
+// start returns the number of a new attempt and the results of the attempts completed so far
+func (g *attemptGroup) start() (int, []HTTPClientAttempt) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.started++
+	return g.started, append([]HTTPClientAttempt(nil), g.completed...)
+}
+
+// finish records the result of an attempt
+func (g *attemptGroup) finish(attempt HTTPClientAttempt) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.completed = append(g.completed, attempt)
+}
+
+// WithAttemptGroup returns a new context that groups all outgoing requests made with it as attempts of one logical call.
+// Use it around calls with clients that retry requests (e.g. hashicorp/go-retryablehttp),
+// so the dashboard shows attempt numbers and the results of previous attempts.
+func WithAttemptGroup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptGroupKey, newAttemptGroup())
+}
+
+// AttemptGroupByHeader returns a function for HTTPClientOptions.AttemptGroupKey that groups requests
+// by the value of the given header (e.g. "Idempotency-Key")
+func AttemptGroupByHeader(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// attemptGroupsByKey remembers attempt groups for the most recent keys
+type attemptGroupsByKey struct {
+	mu     sync.Mutex
+	groups map[string]*attemptGroup
+	keys   []string
+}
+
+func newAttemptGroupsByKey() *attemptGroupsByKey {
+	return &attemptGroupsByKey{groups: make(map[string]*attemptGroup)}
+}
+
+// get returns the attempt group for a key and creates it if needed, the oldest key is forgotten if there are too many
+func (a *attemptGroupsByKey) get(key string) *attemptGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if group, ok := a.groups[key]; ok {
+		return group
+	}
+
+	if len(a.keys) >= maxAttemptGroupKeys {
+		delete(a.groups, a.keys[0])
+		a.keys = a.keys[1:]
+	}
+	group := newAttemptGroup()
+	a.groups[key] = group
+	a.keys = append(a.keys, key)
+	return group
+}
+
+// attemptGroupFor returns the attempt group of an outgoing request from its context or key, nil if it is not grouped
+func (c *HTTPClientCollector) attemptGroupFor(req *http.Request) *attemptGroup {
+	if group, ok := req.Context().Value(attemptGroupKey).(*attemptGroup); ok {
+		return group
+	}
+	if c.options.AttemptGroupKey == nil {
+		return nil
+	}
+	key := c.options.AttemptGroupKey(req)
+	if key == "" {
+		return nil
+	}
+	return c.attemptGroups.get(key)
+}
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"container/list"
 	"errors"
 	"io"
 	"sync"
@@ -25,6 +26,14 @@ type Body struct {
 	mu               sync.RWMutex
 	closed           bool
 	consumedOriginal bool // True if the original body has been completely read
+	evicted          bool // True if the buffer was dropped by the pool
+
+	// Accounting of the pool, protected by the lock of the pool
+	pool        *BodyBufferPool
+	poolElement *list.Element
+	poolSize    uint64
+	poolRefs    int
+	poolEvicted bool
 }
 
 // NewBody creates a new Body wrapper for capturing HTTP body content.
@@ -38,30 +47,30 @@ func NewBody(rc io.ReadCloser, limit int) *Body {
 }
 
 func (b *Body) Read(p []byte) (n int, err error) {
+	var victims []*Body
+	defer func() { evictBodies(victims) }()
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if b == nil || b.reader == nil {
-		return 0, io.EOF
-	}
-
 	if b.closed {
 		return 0, ErrBodyClosed
 	}
+	if b.reader == nil || b.consumedOriginal {
+		return 0, io.EOF
+	}
 
 	// Read from the original reader
 	n, err = b.reader.Read(p)
 
 	if n > 0 {
-		b.buffer.Write(p[:n])
+		victims = b.capture(p[:n])
 	}
 
-	// If EOF, mark as fully consumed
+	// If EOF, mark as fully consumed. The original body is kept to be closed by Close.
 	if err == io.EOF {
 		b.consumedOriginal = true
-
-		// Remove original body
-		b.reader = nil
+		b.isFullyCaptured = b.buffer != nil && !b.buffer.IsTruncated()
 	}
 
 	return n, err
@@ -70,6 +79,9 @@ func (b *Body) Read(p []byte) (n int, err error) {
 // Close closes the original body and finalizes the buffer.
 // This will attempt to read any unread data from the original body up to the maximum size limit.
 func (b *Body) Close() error {
+	var victims []*Body
+	defer func() { evictBodies(victims) }()
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -88,7 +100,7 @@ func (b *Body) Close() error {
 	fullyConsumed := b.consumedOriginal
 
 	// If the body wasn't fully read, read the rest of it into our buffer
-	if !fullyConsumed {
+	if !fullyConsumed && !b.evicted {
 		// Create a buffer for reading
 		buf := make([]byte, 32*1024) // 32KB chunks
 
@@ -99,7 +111,7 @@ func (b *Body) Close() error {
 			n, readErr = b.reader.Read(buf)
 
 			if n > 0 {
-				b.buffer.Write(buf[:n])
+				victims = append(victims, b.capture(buf[:n])...)
 			}
 
 			if readErr != nil {
@@ -111,8 +123,10 @@ func (b *Body) Close() error {
 
 	// Now close the original reader - its implementation should handle any cleanup
 	err := b.reader.Close()
+	// Release the original body, its buffers are not needed anymore
+	b.reader = nil
 
-	if !b.buffer.IsTruncated() {
+	if b.buffer != nil && !b.buffer.IsTruncated() {
 		// Mark as fully captured
 		b.isFullyCaptured = true
 	}
@@ -120,12 +134,51 @@ func (b *Body) Close() error {
 	return err
 }
 
+// write captures p for a body that is explicitly written to (e.g. a response body)
+func (b *Body) write(p []byte) {
+	var victims []*Body
+	defer func() { evictBodies(victims) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	victims = b.capture(p)
+}
+
+// capture writes p to the buffer and accounts the captured bytes in the pool. It returns the bodies to evict, which
+// must be done after the lock of the body is released. Must be called with the lock held.
+func (b *Body) capture(p []byte) (victims []*Body) {
+	if b.buffer == nil {
+		return nil
+	}
+	before := b.buffer.Len()
+	_, _ = b.buffer.Write(p)
+	if b.pool == nil || b.buffer.Len() == before {
+		return nil
+	}
+	return b.pool.grow(b, b.buffer.Len()-before)
+}
+
+// evict drops the buffer of a body that was evicted from its pool
+func (b *Body) evict() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buffer = nil
+	b.evicted = true
+	b.isFullyCaptured = false
+}
+
 // String returns the body content as a string
 func (b *Body) String() string {
+	if b == nil {
+		return ""
+	}
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	if b == nil || b.buffer == nil {
+	if b.buffer == nil {
 		return ""
 	}
 
@@ -134,7 +187,14 @@ func (b *Body) String() string {
 
 // Bytes returns the body content as a byte slice
 func (b *Body) Bytes() []byte {
-	if b == nil || b.buffer == nil {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.buffer == nil {
 		return nil
 	}
 
@@ -143,7 +203,14 @@ func (b *Body) Bytes() []byte {
 
 // Size returns the captured size of the body
 func (b *Body) Size() uint64 {
-	if b == nil || b.buffer == nil {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.buffer == nil {
 		return 0
 	}
 
@@ -156,7 +223,10 @@ func (b *Body) IsTruncated() bool {
 		return false
 	}
 
-	return b.buffer.IsTruncated()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.buffer != nil && b.buffer.IsTruncated()
 }
 
 // IsFullyCaptured returns true if the body was fully captured
@@ -165,5 +235,20 @@ func (b *Body) IsFullyCaptured() bool {
 		return false
 	}
 
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.isFullyCaptured
 }
+
+// IsEvicted returns true if the captured content was dropped by a BodyBufferPool to limit the memory of bodies
+func (b *Body) IsEvicted() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.evicted
+}
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"container/list"
+	"io"
+	"sync"
+)
+
+// DefaultBodyBufferPoolSize is the default limit of the memory of all bodies in a BodyBufferPool
+const DefaultBodyBufferPoolSize = 100 * 1024 * 1024 // 100MB
+
+// BodyBufferPool bounds the memory of bodies captured by HTTP collectors that share it. If the captured content of all
+// bodies exceeds the limit, the least recently written bodies are evicted: their buffer is dropped and they are shown
+// as evicted in the dashboard. A body is released from the pool when no storage holds an event with it anymore.
+//
+// A nil pool does not limit the memory of bodies.
+type BodyBufferPool struct {
+	limit uint64
+
+	mu      sync.Mutex
+	size    uint64
+	bodies  *list.List // bodies with captured content, least recently written first
+	evicted uint64
+}
+
+// NewBodyBufferPool creates a pool that limits the captured content of all its bodies to limit bytes
+func NewBodyBufferPool(limit uint64) *BodyBufferPool {
+	return &BodyBufferPool{
+		limit:  limit,
+		bodies: list.New(),
+	}
+}
+
+// NewBody creates a Body (see NewBody) whose captured content is accounted by the pool
+func (p *BodyBufferPool) NewBody(rc io.ReadCloser, limit int) *Body {
+	body := NewBody(rc, limit)
+	body.pool = p
+	return body
+}
+
+// Size returns the captured content of all bodies in the pool in bytes
+func (p *BodyBufferPool) Size() uint64 {
+	if p == nil {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.size
+}
+
+// Evicted returns the number of bodies that were evicted to stay within the limit
+func (p *BodyBufferPool) Evicted() uint64 {
+	if p == nil {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.evicted
+}
+
+// grow accounts n bytes written to body and returns the bodies to evict to stay within the limit, which can include
+// body itself if it is the least recently written one. The bodies must be evicted without holding the lock of body.
+func (p *BodyBufferPool) grow(body *Body, n int) (victims []*Body) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if body.poolEvicted {
+		return nil
+	}
+	body.poolSize += uint64(n)
+	p.size += uint64(n)
+	if body.poolElement == nil {
+		body.poolElement = p.bodies.PushBack(body)
+	} else {
+		p.bodies.MoveToBack(body.poolElement)
+	}
+
+	for p.size > p.limit && p.bodies.Len() > 0 {
+		victim := p.bodies.Remove(p.bodies.Front()).(*Body)
+		victim.poolElement = nil
+		victim.poolEvicted = true
+		p.size -= victim.poolSize
+		p.evicted++
+		victims = append(victims, victim)
+	}
+	return victims
+}
+
+// retain references body from a stored event, a released body is added to the pool again
+func (p *BodyBufferPool) retain(body *Body) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	body.poolRefs++
+	if body.poolEvicted || body.poolElement != nil || body.poolSize == 0 {
+		return
+	}
+	// Evicting bodies is deferred to the next write, since the lock of the tracker is held
+	body.poolElement = p.bodies.PushBack(body)
+	p.size += body.poolSize
+}
+
+// release removes the reference of a stored event to body, the body leaves the pool with its last reference
+func (p *BodyBufferPool) release(body *Body) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	body.poolRefs--
+	if body.poolRefs > 0 || body.poolElement == nil {
+		return
+	}
+	p.bodies.Remove(body.poolElement)
+	body.poolElement = nil
+	p.size -= body.poolSize
+}
+
+// evictBodies drops the buffers of bodies evicted from their pool
+func evictBodies(victims []*Body) {
+	for _, victim := range victims {
+		victim.evict()
+	}
+}
+
+// eventBodies calls fn for the pooled bodies of an event and its children
+func eventBodies(event *Event, fn func(p *BodyBufferPool, body *Body)) {
+	for _, evt := range event.Visit() {
+		var bodies [2]*Body
+		switch data := evt.Data.(type) {
+		case HTTPServerRequest:
+			bodies = [2]*Body{data.RequestBody, data.ResponseBody}
+		case HTTPClientRequest:
+			bodies = [2]*Body{data.RequestBody, data.ResponseBody}
+		}
+		for _, body := range bodies {
+			if body != nil && body.pool != nil {
+				fn(body.pool, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package collector_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestBodyBufferPool_EvictsLeastRecentlyWrittenBodies(t *testing.T) {
+	pool := collector.NewBodyBufferPool(100)
+
+	first := newCapturedBody(t, pool, strings.Repeat("a", 60), collector.DefaultMaxBodySize)
+	assert.Equal(t, uint64(60), pool.Size())
+	assert.False(t, first.IsEvicted())
+
+	second := newCapturedBody(t, pool, strings.Repeat("b", 60), collector.DefaultMaxBodySize)
+
+	assert.True(t, first.IsEvicted())
+	assert.Equal(t, "", first.String())
+	assert.Equal(t, uint64(0), first.Size())
+	assert.False(t, first.IsFullyCaptured())
+
+	assert.False(t, second.IsEvicted())
+	assert.Equal(t, strings.Repeat("b", 60), second.String())
+
+	assert.Equal(t, uint64(60), pool.Size())
+	assert.Equal(t, uint64(1), pool.Evicted())
+}
+
+func TestBodyBufferPool_BodyLargerThanLimit(t *testing.T) {
+	pool := collector.NewBodyBufferPool(10)
+
+	body := newCapturedBody(t, pool, strings.Repeat("a", 30), collector.DefaultMaxBodySize)
+
+	assert.True(t, body.IsEvicted())
+	assert.Equal(t, uint64(0), pool.Size())
+}
+
+func TestBodyBufferPool_NilPool(t *testing.T) {
+	var pool *collector.BodyBufferPool
+
+	body := newCapturedBody(t, pool, strings.Repeat("a", 30), collector.DefaultMaxBodySize)
+
+	assert.False(t, body.IsEvicted())
+	assert.Equal(t, uint64(30), body.Size())
+	assert.Equal(t, uint64(0), pool.Size())
+}
+
+func TestBodyBufferPool_ConcurrentBodies(t *testing.T) {
+	pool := collector.NewBodyBufferPool(1000)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				body := pool.NewBody(io.NopCloser(strings.NewReader(strings.Repeat("a", 100))), collector.DefaultMaxBodySize)
+				_, err := io.Copy(io.Discard, body)
+				assert.NoError(t, err)
+				assert.NoError(t, body.Close())
+				_ = body.String()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, pool.Size(), uint64(1000))
+	assert.Equal(t, uint64(20*50-10), pool.Evicted())
+}
+
+// pooledServer returns a handler that collects requests with bodies of 7 and 100 bytes in storage
+func pooledServer(t *testing.T, pool *collector.BodyBufferPool) (serve func(path string), storage *collector.CaptureStorage) {
+	t.Helper()
+
+	aggregator := collector.NewEventAggregator()
+	t.Cleanup(aggregator.Close)
+
+	storage = collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 2, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.BodyBufferPool = pool
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	serve = func(path string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, strings.NewReader("request")))
+	}
+	return serve, storage
+}
+
+func storedRequests(t *testing.T, storage *collector.CaptureStorage) map[string]collector.HTTPServerRequest {
+	t.Helper()
+
+	requests := make(map[string]collector.HTTPServerRequest)
+	for _, event := range storage.GetEvents(10) {
+		req := event.Data.(collector.HTTPServerRequest)
+		requests[req.Path] = req
+	}
+	return requests
+}
+
+func TestBodyBufferPool_ReleasesBodiesOfRemovedEvents(t *testing.T) {
+	pool := collector.NewBodyBufferPool(1000)
+	serve, storage := pooledServer(t, pool)
+
+	serve("/first")
+	serve("/second")
+	assert.Equal(t, uint64(214), pool.Size())
+
+	// The storage holds two events, the bodies of the first request are released when it is removed
+	serve("/third")
+	assert.Equal(t, uint64(214), pool.Size())
+	assert.Equal(t, uint64(0), pool.Evicted())
+
+	storage.Clear()
+	assert.Equal(t, uint64(0), pool.Size())
+}
+
+func TestBodyBufferPool_EvictsBodiesOfStoredEvents(t *testing.T) {
+	pool := collector.NewBodyBufferPool(150)
+	serve, storage := pooledServer(t, pool)
+
+	serve("/first")
+	serve("/second")
+
+	requests := storedRequests(t, storage)
+	require.Len(t, requests, 2)
+	assert.True(t, requests["/first"].RequestBody.IsEvicted())
+	assert.True(t, requests["/first"].ResponseBody.IsEvicted())
+	assert.False(t, requests["/second"].RequestBody.IsEvicted())
+	assert.Equal(t, strings.Repeat("a", 100), requests["/second"].ResponseBody.String())
+	assert.Equal(t, uint64(107), pool.Size())
+}
@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// bodySizeLimits selects the maximum size of a body by its content type (see HTTPServerOptions.MaxBodySizeByContentType)
+type bodySizeLimits struct {
+	defaultSize int
+	// patterns are compiled like SkipContentTypes, exact media types are ordered before patterns of all subtypes
+	patterns []string
+	sizes    []int
+}
+
+func newBodySizeLimits(defaultSize int, byContentType map[string]int) bodySizeLimits {
+	limits := bodySizeLimits{defaultSize: defaultSize}
+	for pattern, size := range byContentType {
+		compiled := compileContentTypePatterns([]string{pattern})
+		if len(compiled) == 0 {
+			continue
+		}
+		limits.patterns = append(limits.patterns, compiled[0])
+		limits.sizes = append(limits.sizes, size)
+	}
+	sort.Sort(limits)
+	return limits
+}
+
+// forContentType returns the maximum size of a body with the content type, the default size if no pattern matches
+func (l bodySizeLimits) forContentType(contentType string) int {
+	if len(l.patterns) == 0 || contentType == "" {
+		return l.defaultSize
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return l.defaultSize
+	}
+	for i, pattern := range l.patterns {
+		if matchContentTypePattern(pattern, mediaType) {
+			return l.sizes[i]
+		}
+	}
+	return l.defaultSize
+}
+
+// forResponse returns the maximum size of a response body written by a handler, the content type is detected from the
+// first written bytes like net/http does if the handler did not set it
+func (l bodySizeLimits) forResponse(header http.Header, firstWrite []byte) int {
+	if len(l.patterns) == 0 {
+		return l.defaultSize
+	}
+	if values, ok := header["Content-Type"]; ok {
+		if len(values) == 0 {
+			return l.defaultSize
+		}
+		return l.forContentType(values[0])
+	}
+	return l.forContentType(http.DetectContentType(firstWrite))
+}
+
+func (l bodySizeLimits) Len() int {
+	return len(l.patterns)
+}
+
+func (l bodySizeLimits) Less(i, j int) bool {
+	iWildcard, jWildcard := strings.HasSuffix(l.patterns[i], "/"), strings.HasSuffix(l.patterns[j], "/")
+	if iWildcard != jWildcard {
+		return jWildcard
+	}
+	return l.patterns[i] < l.patterns[j]
+}
+
+func (l bodySizeLimits) Swap(i, j int) {
+	l.patterns[i], l.patterns[j] = l.patterns[j], l.patterns[i]
+	l.sizes[i], l.sizes[j] = l.sizes[j], l.sizes[i]
+}
@@ -1,8 +1,11 @@
 package collector_test
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,3 +68,126 @@ func TestBody_ReadAfterClose(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, collector.ErrBodyClosed, err)
 }
+
+// chunkedReader returns the data in reads of the given sizes (a size of 0 returns no data), an error after the data
+// simulates a broken connection
+type chunkedReader struct {
+	data  []byte
+	sizes []byte
+	err   error
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+	size := len(r.data)
+	if len(r.sizes) > 0 {
+		size = int(r.sizes[0])
+		r.sizes = r.sizes[1:]
+	}
+	n := copy(p, r.data[:min(size, len(r.data))])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func FuzzBody(f *testing.F) {
+	f.Add([]byte("hello world"), uint16(100), []byte{3, 0, 4}, uint8(2), false)
+	f.Add([]byte("truncated body"), uint16(4), []byte{1}, uint8(0), false)
+	f.Add([]byte("broken connection"), uint16(100), []byte{8}, uint8(255), true)
+	f.Add([]byte{}, uint16(0), []byte{}, uint8(1), false)
+
+	f.Fuzz(func(t *testing.T, data []byte, limit uint16, sizes []byte, reads uint8, broken bool) {
+		reader := &chunkedReader{data: data, sizes: sizes}
+		if broken {
+			reader.err = errors.New("connection reset")
+		}
+		body := collector.NewBody(io.NopCloser(reader), int(limit))
+
+		// The application reads a part of the body before closing it
+		var read []byte
+		buf := make([]byte, 7)
+		for i := 0; i < int(reads); i++ {
+			n, err := body.Read(buf)
+			read = append(read, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+		if !bytes.HasPrefix(data, read) {
+			t.Fatalf("read %q is not a prefix of %q", read, data)
+		}
+		_ = body.Close()
+
+		// Close captures the rest of the body up to the limit
+		expected := data[:min(int(limit), len(data))]
+		if !bytes.Equal(body.Bytes(), expected) {
+			t.Fatalf("expected captured %q, got %q", expected, body.Bytes())
+		}
+		if truncated := len(data) > int(limit); body.IsTruncated() != truncated {
+			t.Fatalf("expected truncated %v for %d bytes with limit %d", truncated, len(data), limit)
+		}
+		if body.IsFullyCaptured() == body.IsTruncated() {
+			t.Fatalf("a body is fully captured if and only if it is not truncated")
+		}
+		if _, err := body.Read(buf); !errors.Is(err, collector.ErrBodyClosed) && !errors.Is(err, io.EOF) {
+			t.Fatalf("expected error reading after close, got %v", err)
+		}
+	})
+}
+
+func FuzzBody_ConcurrentReadClose(f *testing.F) {
+	f.Add([]byte("concurrent body"), uint16(8), []byte{1, 2, 3})
+	f.Add([]byte{}, uint16(0), []byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte, limit uint16, sizes []byte) {
+		body := collector.NewBody(io.NopCloser(&chunkedReader{data: data, sizes: sizes}), int(limit))
+
+		// An application can close a body while another goroutine still reads it (e.g. on a timeout)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = io.Copy(io.Discard, body)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = body.Close()
+		}()
+		wg.Wait()
+
+		captured := body.Bytes()
+		if len(captured) > int(limit) || !bytes.HasPrefix(data, captured) {
+			t.Fatalf("captured %q is not a prefix of %q within the limit %d", captured, data, limit)
+		}
+		_ = body.String()
+		_ = body.Size()
+	})
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBody_ReadToEOF(t *testing.T) {
+	original := &closeTracker{Reader: strings.NewReader("complete body")}
+	body := collector.NewBody(original, 100)
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "complete body", string(data))
+	assert.True(t, body.IsFullyCaptured())
+
+	require.NoError(t, body.Close())
+	assert.True(t, original.closed, "the original body is closed after it was read completely")
+	assert.True(t, body.IsFullyCaptured())
+}
@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"context"
+	"time"
+	"unsafe"
+)
+
+// CacheOperation is the operation of a cache access
+type CacheOperation string
+
+const (
+	CacheOperationGet    CacheOperation = "get"
+	CacheOperationSet    CacheOperation = "set"
+	CacheOperationDelete CacheOperation = "delete"
+)
+
+// CacheOp represents a captured operation on a cache (e.g. Redis, memcached or an in-memory cache)
+type CacheOp struct {
+	// Cache is the name of the cache, e.g. "redis" or "sessions" (optional)
+	Cache string
+	// Operation is the kind of access, e.g. CacheOperationGet
+	Operation CacheOperation
+	// Key of the accessed entry
+	Key string
+	// Hit is true if a get found the entry
+	Hit bool
+	// ValueSize is the size of the read or written value in bytes
+	ValueSize int
+	// Timestamp when the operation was started, defaults to the start derived from Duration
+	Timestamp time.Time
+	// Duration of the operation
+	Duration time.Duration
+	// Error if the operation failed, a miss is not an error
+	Error error
+}
+
+// Size returns the estimated memory size of this operation in bytes
+func (o CacheOp) Size() uint64 {
+	size := uint64(unsafe.Sizeof(o))
+	size += uint64(len(o.Cache) + len(o.Operation) + len(o.Key))
+	size += errorSize(o.Error)
+	return size
+}
+
+// CacheStats summarizes the cache operations of an event and its children
+type CacheStats struct {
+	// Operations is the number of all operations
+	Operations int
+	// Gets is the number of successful gets, Hits the number of them that found the entry
+	Gets int
+	Hits int
+	// Errors is the number of failed operations
+	Errors int
+	// Duration is the total duration of all operations
+	Duration time.Duration
+}
+
+// Misses returns the number of successful gets that did not find the entry
+func (s CacheStats) Misses() int {
+	return s.Gets - s.Hits
+}
+
+// HitRate returns the share of gets that found the entry (0 to 1), 0 if there were no gets
+func (s CacheStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// CalculateCacheStats summarizes the cache operations of the event and its children, e.g. of a request
+func CalculateCacheStats(event *Event) CacheStats {
+	var stats CacheStats
+	for _, evt := range event.Visit() {
+		op, ok := evt.Data.(CacheOp)
+		if !ok {
+			continue
+		}
+		stats.Operations++
+		stats.Duration += op.Duration
+		switch {
+		case op.Error != nil:
+			stats.Errors++
+		case op.Operation == CacheOperationGet:
+			stats.Gets++
+			if op.Hit {
+				stats.Hits++
+			}
+		}
+	}
+	return stats
+}
+
+type CacheOpCollector struct {
+	notifier        *Notifier[CacheOp]
+	eventAggregator *EventAggregator
+}
+
+// Collect collects a finished cache operation
+func (c *CacheOpCollector) Collect(ctx context.Context, op CacheOp) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
+	if op.Timestamp.IsZero() {
+		op.Timestamp = time.Now().Add(-op.Duration)
+	}
+	c.notifier.Notify(op)
+	if c.eventAggregator != nil {
+		c.eventAggregator.CollectEvent(ctx, op)
+	}
+}
+
+// Subscribe returns a channel that receives notifications of new cache operations
+func (c *CacheOpCollector) Subscribe(ctx context.Context) <-chan CacheOp {
+	return c.notifier.Subscribe(ctx)
+}
+
+type CacheOpOptions struct {
+	// NotifierOptions are options for notification about new operations
+	NotifierOptions *NotifierOptions
+
+	// EventAggregator is the aggregator for collecting operations as grouped events
+	EventAggregator *EventAggregator
+}
+
+func DefaultCacheOpOptions() CacheOpOptions {
+	return CacheOpOptions{}
+}
+
+func NewCacheOpCollector() *CacheOpCollector {
+	return NewCacheOpCollectorWithOptions(DefaultCacheOpOptions())
+}
+
+func NewCacheOpCollectorWithOptions(options CacheOpOptions) *CacheOpCollector {
+	notifierOptions := DefaultNotifierOptions()
+	if options.NotifierOptions != nil {
+		notifierOptions = *options.NotifierOptions
+	}
+
+	return &CacheOpCollector{
+		notifier:        NewNotifierWithOptions[CacheOp](notifierOptions),
+		eventAggregator: options.EventAggregator,
+	}
+}
+
+// Close releases resources used by the collector
+func (c *CacheOpCollector) Close() {
+	c.notifier.Close()
+}
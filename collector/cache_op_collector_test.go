@@ -0,0 +1,78 @@
+package collector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestCacheOpCollector_Collect(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 10, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	cacheCollector := collector.NewCacheOpCollectorWithOptions(collector.CacheOpOptions{EventAggregator: aggregator})
+	defer cacheCollector.Close()
+
+	ctx := aggregator.StartEvent(context.Background())
+	cacheCollector.Collect(ctx, collector.CacheOp{Operation: collector.CacheOperationGet, Key: "user:1", Hit: true, ValueSize: 128, Duration: time.Millisecond})
+	cacheCollector.Collect(ctx, collector.CacheOp{Operation: collector.CacheOperationGet, Key: "user:2", Duration: time.Millisecond})
+	cacheCollector.Collect(ctx, collector.CacheOp{Operation: collector.CacheOperationSet, Key: "user:2", ValueSize: 96, Duration: 2 * time.Millisecond})
+	cacheCollector.Collect(ctx, collector.CacheOp{Operation: collector.CacheOperationGet, Key: "user:3", Error: errors.New("connection refused")})
+	cacheCollector.Collect(ctx, collector.CacheOp{Operation: collector.CacheOperationDelete, Key: "user:1"})
+	aggregator.EndEvent(ctx, collector.HTTPServerRequest{Method: http.MethodGet, Path: "/users", StatusCode: http.StatusOK})
+
+	events := storage.GetEvents(1)
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Children, 5)
+	assert.Equal(t, collector.EventKindCacheOp, events[0].Children[0].Kind())
+	assert.Equal(t, collector.SeverityError, events[0].Children[3].Severity)
+	assert.False(t, events[0].Children[0].Data.(collector.CacheOp).Timestamp.IsZero(), "timestamp defaults to the start")
+
+	stats := collector.CalculateCacheStats(events[0])
+	assert.Equal(t, 5, stats.Operations)
+	assert.Equal(t, 2, stats.Gets)
+	assert.Equal(t, 1, stats.Hits)
+	assert.Equal(t, 1, stats.Misses())
+	assert.Equal(t, 1, stats.Errors)
+	assert.InDelta(t, 0.5, stats.HitRate(), 0.001)
+	assert.Equal(t, 4*time.Millisecond, stats.Duration)
+}
+
+func TestCacheOp_JSON(t *testing.T) {
+	op := collector.CacheOp{
+		Cache:     "redis",
+		Operation: collector.CacheOperationGet,
+		Key:       "session:abc",
+		Hit:       true,
+		ValueSize: 512,
+		Timestamp: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  300 * time.Microsecond,
+	}
+
+	data, err := json.Marshal(op)
+	require.NoError(t, err)
+
+	var decoded collector.CacheOp
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, op, decoded)
+}
+
+func TestCacheStats_HitRateWithoutGets(t *testing.T) {
+	event := &collector.Event{Data: collector.CacheOp{Operation: collector.CacheOperationSet, Key: "a"}}
+
+	stats := collector.CalculateCacheStats(event)
+	assert.Equal(t, 1, stats.Operations)
+	assert.Zero(t, stats.HitRate())
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// CaptureHeader overrides the capture decision for a single incoming request. With "off" the request is not captured
+// (e.g. for load generators that should not pollute a session), with "force" it is captured by all capturing sessions
+// regardless of their capture mode, skipped paths and content types (e.g. for a one-off webhook of an external service).
+const CaptureHeader = "X-Devlog-Capture"
+
+const (
+	// CaptureOff is the value of CaptureHeader to exclude a request from capturing
+	CaptureOff = "off"
+	// CaptureForce is the value of CaptureHeader to force capturing a request
+	CaptureForce = "force"
+)
+
+type forcedCaptureKeyType struct{}
+
+var forcedCaptureKey = forcedCaptureKeyType{}
+
+// captureOverride returns the normalized value of CaptureHeader of a request, an empty string if it has none or an
+// unknown value
+func captureOverride(r *http.Request) string {
+	switch value := strings.ToLower(strings.TrimSpace(r.Header.Get(CaptureHeader))); value {
+	case CaptureOff, CaptureForce:
+		return value
+	default:
+		return ""
+	}
+}
+
+// withForcedCapture marks the context of a request that is captured regardless of the capture mode (see CaptureForce)
+func withForcedCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcedCaptureKey, true)
+}
+
+// isForcedCapture checks if events of ctx are captured regardless of the capture mode
+func isForcedCapture(ctx context.Context) bool {
+	forced, _ := ctx.Value(forcedCaptureKey).(bool)
+	return forced
+}
@@ -2,9 +2,15 @@ package collector_test
 
 import (
 	"context"
+	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
 )
 
 // TestCollector collects items from a subscription channel for testing.
@@ -81,3 +87,22 @@ func (c *TestCollector[T]) Wait(n int) []T {
 
 	return c.Stop()
 }
+
+// newCapturedBody captures content with a body like the HTTP collectors do, reading it fully and closing it.
+// The body uses pool for its buffer if it is not nil.
+func newCapturedBody(t testing.TB, pool *collector.BodyBufferPool, content string, limit int) *collector.Body {
+	t.Helper()
+
+	var body *collector.Body
+	if pool != nil {
+		body = pool.NewBody(io.NopCloser(strings.NewReader(content)), limit)
+	} else {
+		body = collector.NewBody(io.NopCloser(strings.NewReader(content)), limit)
+	}
+	read, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	// The reader always gets the full content, even if the body is truncated or evicted
+	require.Equal(t, content, string(read))
+	return body
+}
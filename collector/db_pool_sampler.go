@@ -0,0 +1,281 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// DBPoolStats is a snapshot of the connection pool statistics of a registered database handle
+type DBPoolStats struct {
+	// Name of the registered database handle
+	Name string
+	sql.DBStats
+	// Timestamp when the snapshot was taken
+	Timestamp time.Time
+	// ThresholdExceeded describes the exceeded threshold if the snapshot was collected as an event when the pool
+	// crossed a threshold
+	ThresholdExceeded string
+	// Recovered is true if the snapshot was collected as an event when the pool recovered after a threshold was
+	// exceeded
+	Recovered bool
+}
+
+// Size returns the estimated memory size of this snapshot in bytes
+func (s DBPoolStats) Size() uint64 {
+	return uint64(unsafe.Sizeof(s)) + uint64(len(s.Name)+len(s.ThresholdExceeded))
+}
+
+// Status describes why the snapshot was collected as an event
+func (s DBPoolStats) Status() string {
+	if s.Recovered {
+		return "recovered"
+	}
+	return s.ThresholdExceeded
+}
+
+type DBPoolSamplerOptions struct {
+	// Interval between two samples of all registered handles.
+	// Default: 5s
+	Interval time.Duration
+
+	// InUseThreshold collects an event if the ratio of in-use connections to MaxOpenConnections reaches this value.
+	// Only applies to handles with a connection limit, 0 disables the threshold.
+	InUseThreshold float64
+
+	// InUseRecoveryThreshold is the ratio of in-use connections below which the pool recovers after InUseThreshold was
+	// reached. The gap to InUseThreshold prevents an event for every sample while the ratio moves around the threshold.
+	// Default: 0, will use 80% of InUseThreshold
+	InUseRecoveryThreshold float64
+
+	// WaitCountThreshold collects an event if the number of connections waited for since the last sample reaches this value.
+	// The pool recovers with the first sample without waiting. 0 disables the threshold.
+	WaitCountThreshold int64
+
+	// NotifierOptions are options for notification about new samples
+	NotifierOptions *NotifierOptions
+
+	// EventAggregator is the aggregator for collecting threshold violations as events
+	EventAggregator *EventAggregator
+}
+
+func DefaultDBPoolSamplerOptions() DBPoolSamplerOptions {
+	return DBPoolSamplerOptions{
+		Interval:           5 * time.Second,
+		InUseThreshold:     0.9,
+		WaitCountThreshold: 1,
+	}
+}
+
+// DBPoolSampler periodically reads sql.DBStats from registered *sql.DB handles. An event is collected when a pool
+// crosses a threshold and again when it recovered, not for every sample in between.
+type DBPoolSampler struct {
+	options         DBPoolSamplerOptions
+	notifier        *Notifier[DBPoolStats]
+	eventAggregator *EventAggregator
+
+	mu     sync.RWMutex
+	dbs    map[string]*sql.DB
+	latest map[string]DBPoolStats
+	// exceeded holds the names of handles that crossed a threshold and did not recover yet
+	exceeded map[string]bool
+	started  bool
+	closed   bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewDBPoolSampler() *DBPoolSampler {
+	return NewDBPoolSamplerWithOptions(DefaultDBPoolSamplerOptions())
+}
+
+func NewDBPoolSamplerWithOptions(options DBPoolSamplerOptions) *DBPoolSampler {
+	notifierOptions := DefaultNotifierOptions()
+	if options.NotifierOptions != nil {
+		notifierOptions = *options.NotifierOptions
+	}
+	if options.Interval <= 0 {
+		options.Interval = DefaultDBPoolSamplerOptions().Interval
+	}
+	if options.InUseRecoveryThreshold <= 0 || options.InUseRecoveryThreshold > options.InUseThreshold {
+		options.InUseRecoveryThreshold = options.InUseThreshold * 0.8
+	}
+
+	return &DBPoolSampler{
+		options:         options,
+		notifier:        NewNotifierWithOptions[DBPoolStats](notifierOptions),
+		eventAggregator: options.EventAggregator,
+		dbs:             make(map[string]*sql.DB),
+		latest:          make(map[string]DBPoolStats),
+		exceeded:        make(map[string]bool),
+		done:            make(chan struct{}),
+	}
+}
+
+// Register adds a database handle to be sampled under the given name.
+// Sampling starts with the first registered handle.
+func (s *DBPoolSampler) Register(name string, db *sql.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.dbs[name] = db
+	s.latest[name] = DBPoolStats{
+		Name:      name,
+		DBStats:   db.Stats(),
+		Timestamp: time.Now(),
+	}
+
+	if !s.started {
+		s.started = true
+		s.wg.Add(1)
+		go s.sampleLoop()
+	}
+}
+
+// Unregister removes a database handle from sampling
+func (s *DBPoolSampler) Unregister(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.dbs, name)
+	delete(s.latest, name)
+	delete(s.exceeded, name)
+}
+
+// Stats returns the latest snapshot of each registered handle ordered by name
+func (s *DBPoolSampler) Stats() []DBPoolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]DBPoolStats, 0, len(s.latest))
+	for _, stats := range s.latest {
+		result = append(result, stats)
+	}
+	slices.SortFunc(result, func(a, b DBPoolStats) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// Subscribe returns a channel that receives every sample of registered handles
+func (s *DBPoolSampler) Subscribe(ctx context.Context) <-chan DBPoolStats {
+	return s.notifier.Subscribe(ctx)
+}
+
+// Close stops sampling and releases resources used by the sampler
+func (s *DBPoolSampler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.notifier.Close()
+}
+
+func (s *DBPoolSampler) sampleLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *DBPoolSampler) sample() {
+	if !s.eventAggregator.Enabled() {
+		return
+	}
+
+	var (
+		samples []DBPoolStats
+		events  []DBPoolStats
+	)
+
+	s.mu.Lock()
+	now := time.Now()
+	for name, db := range s.dbs {
+		stats := DBPoolStats{
+			Name:      name,
+			DBStats:   db.Stats(),
+			Timestamp: now,
+		}
+		previous := s.latest[name]
+		if !s.exceeded[name] {
+			if exceeded := s.exceededThreshold(previous, stats); exceeded != "" {
+				stats.ThresholdExceeded = exceeded
+				s.exceeded[name] = true
+				events = append(events, stats)
+			}
+		} else if s.recovered(previous, stats) {
+			stats.Recovered = true
+			delete(s.exceeded, name)
+			events = append(events, stats)
+		}
+		s.latest[name] = stats
+		samples = append(samples, stats)
+	}
+	s.mu.Unlock()
+
+	for _, stats := range samples {
+		s.notifier.Notify(stats)
+	}
+	if s.eventAggregator != nil {
+		for _, stats := range events {
+			// Pool stats are not bound to a request, so only storages capturing globally will receive them
+			s.eventAggregator.CollectEvent(context.Background(), stats)
+		}
+	}
+}
+
+func (s *DBPoolSampler) exceededThreshold(previous, current DBPoolStats) string {
+	if s.options.InUseThreshold > 0 && current.MaxOpenConnections > 0 {
+		ratio := float64(current.InUse) / float64(current.MaxOpenConnections)
+		if ratio >= s.options.InUseThreshold {
+			return fmt.Sprintf("%d of %d connections in use", current.InUse, current.MaxOpenConnections)
+		}
+	}
+	if s.options.WaitCountThreshold > 0 {
+		waited := current.WaitCount - previous.WaitCount
+		if waited >= s.options.WaitCountThreshold {
+			return fmt.Sprintf("waited for %d connections (%s)", waited, current.WaitDuration-previous.WaitDuration)
+		}
+	}
+	return ""
+}
+
+// recovered returns true if the pool is below all thresholds again, the in-use ratio must fall below the recovery
+// threshold
+func (s *DBPoolSampler) recovered(previous, current DBPoolStats) bool {
+	if s.options.InUseThreshold > 0 && current.MaxOpenConnections > 0 {
+		ratio := float64(current.InUse) / float64(current.MaxOpenConnections)
+		if ratio >= s.options.InUseRecoveryThreshold {
+			return false
+		}
+	}
+	if s.options.WaitCountThreshold > 0 && current.WaitCount > previous.WaitCount {
+		return false
+	}
+	return true
+}
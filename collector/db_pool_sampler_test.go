@@ -0,0 +1,129 @@
+package collector_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// stubDriver opens connections that cannot execute anything, which is enough to exercise pool stats
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("devlog-stub", stubDriver{})
+}
+
+func TestDBPoolSampler_Stats(t *testing.T) {
+	db, err := sql.Open("devlog-stub", "")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(5)
+
+	sampler := collector.NewDBPoolSampler()
+	defer sampler.Close()
+
+	sampler.Register("primary", db)
+
+	stats := sampler.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "primary", stats[0].Name)
+	assert.Equal(t, 5, stats[0].MaxOpenConnections)
+
+	sampler.Unregister("primary")
+	assert.Empty(t, sampler.Stats())
+}
+
+func TestDBPoolSampler_InUseThresholdCollectsEvent(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+	events := Collect(t, storage.Subscribe)
+
+	db, err := sql.Open("devlog-stub", "")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	options := collector.DefaultDBPoolSamplerOptions()
+	options.Interval = 10 * time.Millisecond
+	options.EventAggregator = aggregator
+	sampler := collector.NewDBPoolSamplerWithOptions(options)
+	defer sampler.Close()
+
+	sampler.Register("primary", db)
+
+	collected := events.Wait(1)
+	stats, ok := collected[0].Data.(collector.DBPoolStats)
+	require.True(t, ok)
+	assert.Equal(t, "primary", stats.Name)
+	assert.Equal(t, 1, stats.InUse)
+	assert.Equal(t, "1 of 1 connections in use", stats.ThresholdExceeded)
+}
+
+func TestDBPoolSampler_CollectsCrossingAndRecovery(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	db, err := sql.Open("devlog-stub", "")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+
+	options := collector.DefaultDBPoolSamplerOptions()
+	options.Interval = 10 * time.Millisecond
+	options.EventAggregator = aggregator
+	sampler := collector.NewDBPoolSamplerWithOptions(options)
+	defer sampler.Close()
+
+	samples := Collect(t, sampler.Subscribe)
+	sampler.Register("primary", db)
+
+	// The pool stays exhausted for several samples, but only the crossing is collected
+	samples.Wait(5)
+	events := storage.GetEvents(100)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1 of 1 connections in use", events[0].Data.(collector.DBPoolStats).ThresholdExceeded)
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		return len(storage.GetEvents(100)) == 2
+	}, time.Second, 5*time.Millisecond)
+	recovered := storage.GetEvents(100)[1].Data.(collector.DBPoolStats)
+	assert.True(t, recovered.Recovered)
+	assert.Empty(t, recovered.ThresholdExceeded)
+	assert.Equal(t, 0, recovered.InUse)
+
+	// No further events while the pool is healthy
+	samples = Collect(t, sampler.Subscribe)
+	samples.Wait(3)
+	assert.Len(t, storage.GetEvents(100), 2)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"time"
+	"unsafe"
 
 	"github.com/networkteam/devlog/internal/utils"
 )
@@ -20,19 +21,44 @@ type DBQuery struct {
 	Timestamp time.Time
 	// SQL dialect / language for highlighting and formatting
 	Language string
+	// Database is the name of the database, to tell apart queries of multiple databases
+	Database string
+	// Tags are static tags of the database adapter
+	Tags map[string]string
+	// ConnID identifies the connection the query was executed on (0 if unknown)
+	ConnID int64
+	// TxID identifies the transaction the query was executed in (0 if not in a transaction)
+	TxID int64
 	// Error if any error occurred
 	Error error
+	// RowsReturned is the number of rows read from the result of a query (nil if unknown)
+	RowsReturned *int64
+	// RowsAffected is the number of rows affected by a statement (nil if unknown)
+	RowsAffected *int64
+	// Prepared is true if the query was executed through a prepared statement
+	Prepared bool
+	// StatementReused is true if the prepared statement was executed before
+	StatementReused bool
 }
 
 // Size returns the estimated memory size of this query in bytes
 func (q DBQuery) Size() uint64 {
-	size := uint64(100) // base struct overhead
+	size := uint64(unsafe.Sizeof(q))
 	size += uint64(len(q.Query))
 	size += uint64(len(q.Language))
+	size += uint64(len(q.Database))
+	size += tagsSize(q.Tags)
+	if q.RowsReturned != nil {
+		size += 8
+	}
+	if q.RowsAffected != nil {
+		size += 8
+	}
+	size += errorSize(q.Error)
 	// Calculate actual size of arguments using reflection
+	size += uint64(cap(q.Args)) * uint64(unsafe.Sizeof(driver.NamedValue{}))
 	for _, arg := range q.Args {
 		size += uint64(len(arg.Name))
-		size += 8 // Ordinal int field
 		argSize := utils.SizeOf(arg.Value)
 		if argSize > 0 {
 			size += uint64(argSize)
@@ -47,6 +73,9 @@ type DBQueryCollector struct {
 }
 
 func (c *DBQueryCollector) Collect(ctx context.Context, query DBQuery) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
 	c.notifier.Notify(query)
 	if c.eventAggregator != nil {
 		c.eventAggregator.CollectEvent(ctx, query)
@@ -0,0 +1,23 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+)
+
+type derivedFromKeyType struct{}
+
+var derivedFromKey = derivedFromKeyType{}
+
+// WithDerivedFrom returns a new context that links outgoing requests made with it to the event with the given ID
+// (see HTTPClientRequest.DerivedFrom), e.g. to the captured request that is replayed with changes
+func WithDerivedFrom(ctx context.Context, eventID uuid.UUID) context.Context {
+	return context.WithValue(ctx, derivedFromKey, eventID)
+}
+
+// derivedFromContext returns the ID of the event requests made with ctx are derived from, zero if there is none
+func derivedFromContext(ctx context.Context) uuid.UUID {
+	eventID, _ := ctx.Value(derivedFromKey).(uuid.UUID)
+	return eventID
+}
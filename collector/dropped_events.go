@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// recentDroppedEvents is the number of dropped events remembered to discard their nested events
+const recentDroppedEvents = 1000
+
+// droppedEvents remembers the IDs of the most recent events that were dropped on purpose (e.g. by a processor or a
+// skip rule), so nested events completing after them are discarded as well instead of being detached. The oldest IDs
+// are forgotten when it is full. It is guarded by the lock of the aggregator.
+type droppedEvents struct {
+	ids  []uuid.UUID
+	next int
+	set  map[uuid.UUID]struct{}
+}
+
+func newDroppedEvents(capacity int) *droppedEvents {
+	return &droppedEvents{
+		ids: make([]uuid.UUID, 0, capacity),
+		set: make(map[uuid.UUID]struct{}, capacity),
+	}
+}
+
+// add remembers a dropped event and its nested events
+func (d *droppedEvents) add(evt *Event) {
+	d.addID(evt.ID)
+	for _, child := range evt.Children {
+		d.add(child)
+	}
+}
+
+func (d *droppedEvents) addID(id uuid.UUID) {
+	if _, ok := d.set[id]; ok {
+		return
+	}
+	if len(d.ids) < cap(d.ids) {
+		d.ids = append(d.ids, id)
+	} else {
+		delete(d.set, d.ids[d.next])
+		d.ids[d.next] = id
+		d.next = (d.next + 1) % len(d.ids)
+	}
+	d.set[id] = struct{}{}
+}
+
+// contains returns true if the event with the given ID was dropped recently
+func (d *droppedEvents) contains(id uuid.UUID) bool {
+	_, ok := d.set[id]
+	return ok
+}
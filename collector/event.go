@@ -2,9 +2,14 @@ package collector
 
 import (
 	"iter"
+	"log/slog"
+	"slices"
 	"time"
+	"unsafe"
 
 	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/internal/utils"
 )
 
 // Sizer is implemented by event data types to report their memory size
@@ -22,6 +27,33 @@ type Event struct {
 	Start time.Time
 	End   time.Time
 
+	// Ambient is true for top-level events collected outside of a request (see IsAmbientContext)
+	Ambient bool
+
+	// InProgress is true for a preview of an event that is not completed yet.
+	// A later event with the same ID (a newer preview or the completed event) replaces it.
+	InProgress bool
+
+	// LateChildren is true if children completed after the event and were added to it later (see CaptureStorage.AddChild)
+	LateChildren bool
+
+	// Detached is true for a nested event that completed after its parent, when the parent was not stored anymore
+	// (e.g. it was evicted or deleted). It is collected as a top-level event instead of being discarded.
+	Detached bool
+
+	// Archived is true for an event read from an archive after it was evicted from a storage (see EventArchive)
+	Archived bool
+
+	// Tenant is the tenant of the context the event was started or collected with (see WithTenant)
+	Tenant string
+
+	// Severity rates the data of the event (see SeverityThresholds.Rate), it is set when the event is collected
+	Severity Severity
+
+	// Sequence orders events by their start. It increases monotonically for the events of an aggregator, so events
+	// started in the same millisecond keep their order (unlike the timestamp of the UUIDv7 ID).
+	Sequence uint64
+
 	// Children is a slice of events that are children of this event
 	Children []*Event
 
@@ -29,16 +61,82 @@ type Event struct {
 	Size uint64
 }
 
-// calculateSize computes the memory size of this event (excluding children)
+// addChild adds a completed child event, children are ordered by their sequence (not by their completion)
+func (e *Event) addChild(child *Event) {
+	i := len(e.Children)
+	for i > 0 && e.Children[i-1].Sequence > child.Sequence {
+		i--
+	}
+	e.Children = slices.Insert(e.Children, i, child)
+}
+
+// withChild returns a copy of the event with child added to the event with the given ID, which is the event itself or
+// one of its descendants. Only the events on the path to the parent are copied. It returns nil if there is no event
+// with the ID.
+func (e *Event) withChild(parentID uuid.UUID, child *Event) *Event {
+	if e.ID == parentID {
+		updated := *e
+		updated.Children = slices.Clone(e.Children)
+		updated.addChild(child)
+		return &updated
+	}
+	for i, existing := range e.Children {
+		if updatedChild := existing.withChild(parentID, child); updatedChild != nil {
+			updated := *e
+			updated.Children = slices.Clone(e.Children)
+			updated.Children[i] = updatedChild
+			return &updated
+		}
+	}
+	return nil
+}
+
+// calculateSize computes the memory size of this event (excluding children). Data without a Size method is measured
+// by reflection.
 func (e *Event) calculateSize() uint64 {
-	const baseEventSize = 100 // UUID, pointers, time.Time fields, slice header
-	size := uint64(baseEventSize)
-	if sizer, ok := e.Data.(Sizer); ok {
-		size += sizer.Size()
+	size := uint64(unsafe.Sizeof(*e))
+	if e.GroupID != nil {
+		size += uint64(unsafe.Sizeof(*e.GroupID))
+	}
+	size += uint64(len(e.Tenant))
+	switch data := e.Data.(type) {
+	case nil:
+	case Sizer:
+		size += data.Size()
+	case slog.Record:
+		size += recordSize(data)
+	default:
+		if dataSize := utils.SizeOf(data); dataSize > 0 {
+			size += uint64(dataSize)
+		}
 	}
 	return size
 }
 
+// Tags returns the tags of the event data, only requests and database queries have tags
+func (e *Event) Tags() map[string]string {
+	switch data := e.Data.(type) {
+	case HTTPServerRequest:
+		return data.Tags
+	case HTTPClientRequest:
+		return data.Tags
+	case DBQuery:
+		return data.Tags
+	}
+	return nil
+}
+
+// HasTag returns true if the event or one of its children has a tag with the given key and value.
+// An empty value matches any value of the key.
+func (e *Event) HasTag(key, value string) bool {
+	for _, evt := range e.Visit() {
+		if tagValue, ok := evt.Tags()[key]; ok && (value == "" || tagValue == value) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Event) Visit() iter.Seq2[uuid.UUID, *Event] {
 	return func(yield func(uuid.UUID, *Event) bool) {
 		e.visitInternal(yield)
@@ -1,8 +1,12 @@
 package collector
 
 import (
+	"cmp"
 	"context"
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -13,29 +17,106 @@ import (
 type EventAggregator struct {
 	storages   map[uuid.UUID]EventStorage
 	openGroups map[uuid.UUID]*Event
+	// previewed holds the IDs of open events with a preview in the storages (see previewEvent)
+	previewed map[uuid.UUID]struct{}
+	// dropped holds the IDs of recently dropped events, their nested events are discarded instead of detached
+	dropped *droppedEvents
+	memory  *memoryTracker
+	closed  bool
+
+	// sequence numbers events in the order they are started
+	sequence atomic.Uint64
+
+	// recoveredPanics counts panics in collector code that were recovered (see RecordPanic)
+	recoveredPanics atomic.Uint64
+	// internalErrors keeps the most recent recovered panics for diagnostics
+	internalErrors *RingBuffer[InternalError]
+	// orphanedEvents counts nested events that were detached because their parent was completed and not stored
+	orphanedEvents atomic.Uint64
+	// disabled turns the collectors using the aggregator into a pass-through (see SetEnabled)
+	disabled atomic.Bool
+	// processors run on every completed event before it is dispatched (see EventProcessor)
+	processors []EventProcessor
+	// droppedByProcessors counts events dropped by a processor
+	droppedByProcessors atomic.Uint64
+	// severityThresholds rate completed events (see Event.Severity)
+	severityThresholds SeverityThresholds
+
+	logger *slog.Logger
 
 	mu sync.RWMutex
 }
 
+// EventAggregatorOptions configures an EventAggregator
+type EventAggregatorOptions struct {
+	// Logger is used for internal logging, e.g. recovered panics and dropped events.
+	// Default: nil, nothing is logged
+	Logger *slog.Logger
+
+	// Processors run in order on every completed event of all collectors, e.g. to redact, sample, enrich or drop events.
+	// Default: nil, events are collected unchanged
+	Processors []EventProcessor
+
+	// SeverityThresholds rate slow events as SeverityWarn, processors can change the severity of an event.
+	// Default: see DefaultSeverityThresholds
+	SeverityThresholds SeverityThresholds
+}
+
 // NewEventAggregator creates a new EventAggregator.
 func NewEventAggregator() *EventAggregator {
+	return NewEventAggregatorWithOptions(EventAggregatorOptions{})
+}
+
+// NewEventAggregatorWithOptions creates a new EventAggregator with the specified options.
+func NewEventAggregatorWithOptions(options EventAggregatorOptions) *EventAggregator {
 	return &EventAggregator{
 		storages:   make(map[uuid.UUID]EventStorage),
 		openGroups: make(map[uuid.UUID]*Event),
+		previewed:  make(map[uuid.UUID]struct{}),
+		dropped:    newDroppedEvents(recentDroppedEvents),
+		memory:     newMemoryTracker(),
+
+		internalErrors: NewRingBuffer[InternalError](recentInternalErrors),
+		processors:     slices.Clone(options.Processors),
+		logger:         loggerOrDiscard(options.Logger),
+
+		severityThresholds: options.SeverityThresholds.withDefaults(),
+	}
+}
+
+// Logger returns the logger for internal logging, it discards everything if no logger was configured
+func (a *EventAggregator) Logger() *slog.Logger {
+	if a == nil {
+		return loggerOrDiscard(nil)
 	}
+	return a.logger
 }
 
 // RegisterStorage registers a storage with the aggregator.
+// A storage registered after the aggregator was closed is closed immediately and does not capture events.
 func (a *EventAggregator) RegisterStorage(storage EventStorage) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if a.closed {
+		storage.Close()
+		return
+	}
 	a.storages[storage.ID()] = storage
+	if tracked, ok := storage.(memoryTrackingStorage); ok {
+		tracked.setMemoryTracker(a.memory)
+	}
+	if logging, ok := storage.(loggingStorage); ok {
+		logging.setLogger(a.logger)
+	}
 }
 
 // UnregisterStorage removes a storage from the aggregator.
 func (a *EventAggregator) UnregisterStorage(id uuid.UUID) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if tracked, ok := a.storages[id].(memoryTrackingStorage); ok {
+		tracked.setMemoryTracker(nil)
+	}
 	delete(a.storages, id)
 }
 
@@ -48,6 +129,10 @@ func (a *EventAggregator) GetStorage(id uuid.UUID) EventStorage {
 
 // ShouldCapture returns true if any registered storage wants to capture events for the given context.
 func (a *EventAggregator) ShouldCapture(ctx context.Context) bool {
+	if a.disabled.Load() {
+		return false
+	}
+
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -59,6 +144,25 @@ func (a *EventAggregator) ShouldCapture(ctx context.Context) bool {
 	return false
 }
 
+// SetEnabled enables or disables collecting at runtime. When disabled, nothing is captured and the collectors using
+// the aggregator pass requests, logs and queries through without inspecting or copying them, so devlog can be compiled
+// into a production build and stay dormant. Events in progress are still completed.
+func (a *EventAggregator) SetEnabled(enabled bool) {
+	if wasDisabled := a.disabled.Swap(!enabled); wasDisabled != enabled {
+		return
+	}
+	if enabled {
+		a.logger.Info("Enabled collecting")
+	} else {
+		a.logger.Info("Disabled collecting, collectors pass through")
+	}
+}
+
+// Enabled returns false if collecting is disabled (see SetEnabled). A nil aggregator is always enabled.
+func (a *EventAggregator) Enabled() bool {
+	return a == nil || !a.disabled.Load()
+}
+
 // StartEvent starts a new event and returns a new context with the group ID.
 // Child events collected with this context will be grouped under this event.
 // Call EndEvent to finish the event.
@@ -69,14 +173,18 @@ func (a *EventAggregator) StartEvent(ctx context.Context) context.Context {
 	defer a.mu.Unlock()
 
 	evt := &Event{
-		ID:    eventID,
-		Start: time.Now(),
+		ID:       eventID,
+		Start:    time.Now(),
+		Tenant:   TenantFromContext(ctx),
+		Sequence: a.sequence.Add(1),
 	}
 
 	// Check if there's an outer group
 	outerGroupID, ok := groupIDFromContext(ctx)
 	if ok {
 		evt.GroupID = &outerGroupID
+	} else {
+		evt.Ambient = IsAmbientContext(ctx)
 	}
 
 	a.openGroups[eventID] = evt
@@ -86,6 +194,11 @@ func (a *EventAggregator) StartEvent(ctx context.Context) context.Context {
 
 // EndEvent finishes an event started with StartEvent and dispatches it to matching storages.
 func (a *EventAggregator) EndEvent(ctx context.Context, data any) {
+	a.endEvent(ctx, data, time.Now())
+}
+
+// endEvent finishes an event at the given end time, which can be earlier than now if the event is processed asynchronously.
+func (a *EventAggregator) endEvent(ctx context.Context, data any, end time.Time) {
 	groupID, ok := groupIDFromContext(ctx)
 	if !ok {
 		return
@@ -100,18 +213,25 @@ func (a *EventAggregator) EndEvent(ctx context.Context, data any) {
 	}
 
 	evt.Data = data
-	evt.End = time.Now()
+	evt.End = end
+	evt.Severity = a.severityThresholds.Rate(data)
 	evt.Size = evt.calculateSize()
 
-	// Link to parent if exists
-	if evt.GroupID != nil {
-		parentEvt := a.openGroups[*evt.GroupID]
-		if parentEvt != nil {
-			parentEvt.Children = append(parentEvt.Children, evt)
-		}
+	delete(a.openGroups, groupID)
+
+	completed := evt
+	evt, keep := a.process(ctx, evt)
+	if !keep {
+		a.dropped.add(completed)
+		a.removePreview(groupID)
+		return
 	}
+	delete(a.previewed, groupID)
 
-	delete(a.openGroups, groupID)
+	// Link to parent if exists
+	if evt.GroupID != nil && !a.attachToParent(evt) {
+		return
+	}
 
 	// Only dispatch top-level events to storages
 	if evt.GroupID == nil {
@@ -119,8 +239,83 @@ func (a *EventAggregator) EndEvent(ctx context.Context, data any) {
 	}
 }
 
+// previewEvent dispatches a snapshot of a top-level event that is still in progress to matching storages.
+// The completed event replaces the preview in the storages, since it has the same ID.
+func (a *EventAggregator) previewEvent(ctx context.Context, data any) {
+	groupID, ok := groupIDFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	evt := a.openGroups[groupID]
+	// Only top-level events are shown in the dashboard on their own
+	if evt == nil || evt.GroupID != nil {
+		return
+	}
+
+	preview := &Event{
+		ID:         evt.ID,
+		Data:       data,
+		Start:      evt.Start,
+		End:        time.Now(),
+		Ambient:    evt.Ambient,
+		Tenant:     evt.Tenant,
+		InProgress: true,
+		Sequence:   evt.Sequence,
+		Severity:   a.severityThresholds.Rate(data),
+		Children:   slices.Clone(evt.Children),
+	}
+	preview.Size = preview.calculateSize()
+
+	preview, keep := a.process(ctx, preview)
+	if !keep {
+		return
+	}
+	a.previewed[groupID] = struct{}{}
+	a.dispatchToStorages(ctx, preview)
+}
+
+// removePreview removes the preview of a dropped event from the storages, so it is not left in progress forever.
+// Must be called with lock held.
+func (a *EventAggregator) removePreview(id uuid.UUID) {
+	if _, ok := a.previewed[id]; !ok {
+		return
+	}
+	delete(a.previewed, id)
+
+	for _, storage := range a.storages {
+		if removing, ok := storage.(removingStorage); ok {
+			a.removeFromStorage(removing, id)
+		}
+	}
+}
+
+// discardEvent removes an event started with StartEvent without dispatching it, e.g. if it was dropped.
+func (a *EventAggregator) discardEvent(ctx context.Context) {
+	groupID, ok := groupIDFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if evt := a.openGroups[groupID]; evt != nil {
+		a.dropped.add(evt)
+	}
+	delete(a.openGroups, groupID)
+	a.removePreview(groupID)
+}
+
 // CollectEvent creates and immediately completes an event, dispatching to matching storages.
 func (a *EventAggregator) CollectEvent(ctx context.Context, data any) {
+	if a.disabled.Load() {
+		return
+	}
+
 	eventID := uuid.Must(uuid.NewV7())
 	now := time.Now()
 
@@ -128,10 +323,13 @@ func (a *EventAggregator) CollectEvent(ctx context.Context, data any) {
 	defer a.mu.Unlock()
 
 	evt := &Event{
-		ID:    eventID,
-		Data:  data,
-		Start: now,
-		End:   now,
+		ID:       eventID,
+		Data:     data,
+		Start:    now,
+		End:      now,
+		Tenant:   TenantFromContext(ctx),
+		Sequence: a.sequence.Add(1),
+		Severity: a.severityThresholds.Rate(data),
 	}
 	evt.Size = evt.calculateSize()
 
@@ -139,10 +337,17 @@ func (a *EventAggregator) CollectEvent(ctx context.Context, data any) {
 	outerGroupID, ok := groupIDFromContext(ctx)
 	if ok {
 		evt.GroupID = &outerGroupID
-		parentEvt := a.openGroups[outerGroupID]
-		if parentEvt != nil {
-			parentEvt.Children = append(parentEvt.Children, evt)
-		}
+	} else {
+		evt.Ambient = IsAmbientContext(ctx)
+	}
+
+	evt, keep := a.process(ctx, evt)
+	if !keep {
+		return
+	}
+
+	if evt.GroupID != nil && !a.attachToParent(evt) {
+		return
 	}
 
 	// Only dispatch top-level events to storages
@@ -151,22 +356,168 @@ func (a *EventAggregator) CollectEvent(ctx context.Context, data any) {
 	}
 }
 
+// linkToParent adds a completed nested event to its parent. If the parent was already completed (e.g. the event was
+// collected by a goroutine that outlived its request), it is added to the event containing the parent: an event that
+// is still open or the top-level event in the storages. It returns false if the parent was not found.
+// Must be called with lock held.
+func (a *EventAggregator) linkToParent(evt *Event) bool {
+	parentID := *evt.GroupID
+	if parentEvt := a.openGroups[parentID]; parentEvt != nil {
+		parentEvt.addChild(evt)
+		return true
+	}
+
+	// The parent is nested in an open event, the path to it is copied since previews of the open event share it
+	for _, openEvt := range a.openGroups {
+		for i, child := range openEvt.Children {
+			if updated := child.withChild(parentID, evt); updated != nil {
+				openEvt.Children[i] = updated
+				return true
+			}
+		}
+	}
+
+	added := false
+	for _, storage := range a.storages {
+		if childStorage, ok := storage.(childAddingStorage); ok && a.addChildToStorage(childStorage, evt) {
+			added = true
+		}
+	}
+	return added
+}
+
+// attachToParent links a completed nested event to its parent, it is detached if the parent is not found. It returns
+// false if the event is discarded since its parent was dropped, its nested events are discarded as well then.
+// Must be called with lock held.
+func (a *EventAggregator) attachToParent(evt *Event) bool {
+	if a.dropped.contains(*evt.GroupID) {
+		a.dropped.add(evt)
+		return false
+	}
+	if !a.linkToParent(evt) {
+		a.detach(evt)
+	}
+	return true
+}
+
+// detach turns a nested event whose parent was not found into a top-level event, so it is dispatched to the storages
+// instead of being discarded. Must be called with lock held.
+func (a *EventAggregator) detach(evt *Event) {
+	a.orphanedEvents.Add(1)
+	a.logger.Debug("Collected nested event as top-level event, its parent is not stored anymore", "event", evt.ID, "parent", *evt.GroupID)
+	evt.GroupID = nil
+	evt.Detached = true
+}
+
+// addChildToStorage adds a nested event to a completed parent in a storage, a panic of the storage is recovered.
+func (a *EventAggregator) addChildToStorage(storage childAddingStorage, evt *Event) (added bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			a.countPanic(newInternalError("storage", v))
+			added = false
+		}
+	}()
+
+	return storage.AddChild(evt)
+}
+
+// removeFromStorage removes an event from a storage, a panic of the storage is recovered.
+func (a *EventAggregator) removeFromStorage(storage removingStorage, id uuid.UUID) {
+	defer func() {
+		if v := recover(); v != nil {
+			a.countPanic(newInternalError("storage", v))
+		}
+	}()
+
+	storage.Remove(id)
+}
+
 // dispatchToStorages sends the event to all storages that want to capture it.
+// A panic in a storage is recovered and dispatched as an InternalError event to the other storages.
 // Must be called with lock held.
 func (a *EventAggregator) dispatchToStorages(ctx context.Context, evt *Event) {
-	for _, storage := range a.storages {
-		if storage.ShouldCapture(ctx) {
-			storage.Add(evt)
+	var failed map[uuid.UUID]InternalError
+	for id, storage := range a.storages {
+		if internalErr, ok := a.addToStorage(ctx, storage, evt); !ok {
+			if failed == nil {
+				failed = make(map[uuid.UUID]InternalError)
+			}
+			failed[id] = internalErr
+		}
+	}
+
+	for _, internalErr := range failed {
+		now := time.Now()
+		errEvt := &Event{
+			ID:       uuid.Must(uuid.NewV7()),
+			Data:     internalErr,
+			Start:    now,
+			End:      now,
+			Ambient:  evt.Ambient,
+			Tenant:   evt.Tenant,
+			Sequence: a.sequence.Add(1),
 		}
+		errEvt.Size = errEvt.calculateSize()
+		for id, storage := range a.storages {
+			if _, ok := failed[id]; !ok {
+				a.addToStorage(ctx, storage, errEvt)
+			}
+		}
+	}
+}
+
+// addToStorage adds the event to a storage if it wants to capture it, a panic of the storage is recovered and returned
+// as an internal error.
+func (a *EventAggregator) addToStorage(ctx context.Context, storage EventStorage, evt *Event) (internalErr InternalError, ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			internalErr, ok = newInternalError("storage", v), false
+			a.countPanic(internalErr)
+		}
+	}()
+
+	if storage.ShouldCapture(ctx) {
+		storage.Add(evt)
 	}
+	return InternalError{}, true
 }
 
-// Close releases resources used by the aggregator.
+// drainInterval is how often Drain checks for events in progress
+const drainInterval = 10 * time.Millisecond
+
+// Drain waits until all events started with StartEvent (e.g. requests in progress) are completed and dispatched,
+// or returns the error of ctx when it is done first. Stop accepting new requests before draining.
+func (a *EventAggregator) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		a.mu.RLock()
+		open := len(a.openGroups)
+		a.mu.RUnlock()
+		if open == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close releases resources used by the aggregator and closes all storages.
+// Events collected after Close are discarded, it is safe to call Close multiple times.
 func (a *EventAggregator) Close() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.closed = true
 	for _, storage := range a.storages {
+		if tracked, ok := storage.(memoryTrackingStorage); ok {
+			tracked.setMemoryTracker(nil)
+		}
 		storage.Close()
 	}
 	a.storages = make(map[uuid.UUID]EventStorage)
@@ -178,34 +529,75 @@ type Stats struct {
 	TotalMemory  uint64
 	EventCount   int
 	StorageCount int
+	// Storages holds the memory usage of each storage that accounts it incrementally (e.g. CaptureStorage)
+	Storages []StorageStats
+	// RecoveredPanics is the number of panics in collector code that were recovered (see InternalError)
+	RecoveredPanics uint64
 }
 
-// CalculateStats computes stats across all storages, de-duplicating events by ID
+// CalculateStats computes stats across all storages, events stored in multiple storages are counted once.
+// Storages accounting their memory incrementally don't need to be iterated.
 func (a *EventAggregator) CalculateStats() Stats {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	seen := make(map[uuid.UUID]struct{})
-	var totalMemory uint64
+	totalMemory, eventCount := a.memory.totals()
+	stats := Stats{
+		StorageCount:    len(a.storages),
+		RecoveredPanics: a.recoveredPanics.Load(),
+	}
 
+	seen := make(map[uuid.UUID]struct{})
 	for _, storage := range a.storages {
+		if tracked, ok := storage.(memoryTrackingStorage); ok {
+			stats.Storages = append(stats.Storages, tracked.Stats())
+			continue
+		}
+
 		// Get all events from storage (use a large limit to get all)
-		events := storage.GetEvents(100000)
-		for _, event := range events {
+		for _, event := range storage.GetEvents(100000) {
 			if _, exists := seen[event.ID]; !exists {
 				seen[event.ID] = struct{}{}
-				totalMemory += event.Size
-				// Add children sizes
-				for _, child := range event.Children {
-					totalMemory += child.Size
-				}
+				totalMemory += event.totalSize()
+				eventCount++
 			}
 		}
 	}
+	slices.SortFunc(stats.Storages, func(a, b StorageStats) int {
+		return cmp.Compare(b.MemoryBytes, a.MemoryBytes)
+	})
+
+	stats.TotalMemory = totalMemory
+	stats.EventCount = eventCount
+	return stats
+}
+
+// Diagnostics describes the health of the aggregator, e.g. to debug why events are not captured
+type Diagnostics struct {
+	// OpenEvents is the number of events started with StartEvent that are not completed yet (e.g. requests in progress)
+	OpenEvents int `json:"openEvents"`
+	// OrphanedEvents is the number of nested events collected as detached top-level events because their parent was
+	// already completed and is not stored anymore, e.g. logs of a goroutine that outlived its request which was deleted
+	OrphanedEvents uint64 `json:"orphanedEvents"`
+	// DroppedByProcessors is the number of events dropped by an EventProcessor, e.g. by sampling
+	DroppedByProcessors uint64 `json:"droppedByProcessors"`
+	// RecoveredPanics is the number of panics in collector code that were recovered
+	RecoveredPanics uint64 `json:"recoveredPanics"`
+	// RecentInternalErrors are the most recent recovered panics, oldest first
+	RecentInternalErrors []InternalError `json:"recentInternalErrors"`
+}
 
-	return Stats{
-		TotalMemory:  totalMemory,
-		EventCount:   len(seen),
-		StorageCount: len(a.storages),
+// Diagnostics returns the current health of the aggregator
+func (a *EventAggregator) Diagnostics() Diagnostics {
+	a.mu.RLock()
+	openEvents := len(a.openGroups)
+	a.mu.RUnlock()
+
+	return Diagnostics{
+		OpenEvents:           openEvents,
+		OrphanedEvents:       a.orphanedEvents.Load(),
+		DroppedByProcessors:  a.droppedByProcessors.Load(),
+		RecoveredPanics:      a.recoveredPanics.Load(),
+		RecentInternalErrors: a.RecentInternalErrors(),
 	}
 }
@@ -1,7 +1,10 @@
 package collector_test
 
 import (
+	"bytes"
 	"context"
+	"database/sql/driver"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
@@ -22,6 +25,30 @@ func TestEventAggregator_ShouldCapture_NoStorages(t *testing.T) {
 	assert.False(t, aggregator.ShouldCapture(ctx))
 }
 
+func TestEventAggregator_Disabled(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	ctx := context.Background()
+
+	aggregator.SetEnabled(false)
+	assert.False(t, aggregator.Enabled())
+	assert.False(t, aggregator.ShouldCapture(ctx))
+	aggregator.CollectEvent(ctx, "while disabled")
+
+	aggregator.SetEnabled(true)
+	assert.True(t, aggregator.Enabled())
+	assert.True(t, aggregator.ShouldCapture(ctx))
+	aggregator.CollectEvent(ctx, "while enabled")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "while enabled", events[0].Data)
+}
+
 func TestEventAggregator_ShouldCapture_SessionModeMatch(t *testing.T) {
 	aggregator := collector.NewEventAggregator()
 	defer aggregator.Close()
@@ -176,6 +203,32 @@ func TestEventAggregator_StartEndEvent_WithCapture(t *testing.T) {
 	assert.True(t, events[0].End.After(events[0].Start))
 }
 
+func TestEventAggregator_AmbientEvents(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	// Top-level event outside of a request with a nested child
+	ctx := aggregator.StartEvent(context.Background())
+	aggregator.CollectEvent(ctx, "child event")
+	aggregator.EndEvent(ctx, "background event")
+
+	// Event in a session context
+	aggregator.CollectEvent(collector.WithSessionIDs(context.Background(), []uuid.UUID{sessionID}), "session event")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, "background event", events[0].Data)
+	assert.True(t, events[0].Ambient)
+	require.Len(t, events[0].Children, 1)
+	assert.False(t, events[0].Children[0].Ambient, "only top-level events are marked as ambient")
+	assert.Equal(t, "session event", events[1].Data)
+	assert.False(t, events[1].Ambient)
+}
+
 func TestEventAggregator_StartEndEvent_NoCapture(t *testing.T) {
 	aggregator := collector.NewEventAggregator()
 	defer aggregator.Close()
@@ -218,6 +271,34 @@ func TestEventAggregator_NestedEvents_WithCapture(t *testing.T) {
 	assert.Equal(t, "child event", parent.Children[0].Data)
 }
 
+func TestEventAggregator_NestedEvents_DetachedContext(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeSession)
+	aggregator.RegisterStorage(storage)
+
+	ctx, cancel := context.WithCancel(collector.WithSessionIDs(context.Background(), []uuid.UUID{sessionID}))
+	ctx = aggregator.StartEvent(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		aggregator.CollectEvent(ctx, "goroutine event")
+	}(collector.DetachedContext(ctx))
+	wg.Wait()
+
+	cancel()
+	aggregator.EndEvent(ctx, "request event")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Children, 1)
+	assert.Equal(t, "goroutine event", events[0].Children[0].Data)
+}
+
 func TestEventAggregator_DeeplyNestedEvents(t *testing.T) {
 	aggregator := collector.NewEventAggregator()
 	defer aggregator.Close()
@@ -310,6 +391,180 @@ func TestEventAggregator_EndEventWithoutStart(t *testing.T) {
 	assert.Len(t, events, 0)
 }
 
+func TestEventAggregator_NestedEvents_CompletedAfterParent(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := storage.Subscribe(ctx)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	childCtx := aggregator.StartEvent(parentCtx)
+	nestedCtx := aggregator.StartEvent(childCtx)
+	aggregator.EndEvent(childCtx, "child")
+	aggregator.EndEvent(parentCtx, "parent")
+
+	parent := <-ch
+	assert.False(t, parent.LateChildren)
+
+	// Completed after its parent and the top-level event
+	aggregator.EndEvent(nestedCtx, "nested")
+	aggregator.CollectEvent(parentCtx, "late log")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	updated := events[0]
+	assert.True(t, updated.LateChildren)
+	require.Len(t, updated.Children, 2)
+	assert.Equal(t, "child", updated.Children[0].Data)
+	assert.Equal(t, "late log", updated.Children[1].Data)
+	require.Len(t, updated.Children[0].Children, 1)
+	assert.Equal(t, "nested", updated.Children[0].Children[0].Data)
+
+	// Events handed out before are not modified
+	assert.Len(t, parent.Children, 1)
+	assert.Empty(t, parent.Children[0].Children)
+
+	// Subscribers are notified about each update
+	for range 2 {
+		select {
+		case event := <-ch:
+			assert.Equal(t, parent.ID, event.ID)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	}
+	assert.Zero(t, aggregator.Diagnostics().OrphanedEvents)
+}
+
+func TestEventAggregator_NestedEvents_CompletedAfterParentInOpenEvent(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	rootCtx := aggregator.StartEvent(context.Background())
+	parentCtx := aggregator.StartEvent(rootCtx)
+	aggregator.EndEvent(parentCtx, "parent")
+	// The parent is completed, but the top-level event is still open
+	aggregator.CollectEvent(parentCtx, "late log")
+	aggregator.EndEvent(rootCtx, "root")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.False(t, events[0].LateChildren)
+	require.Len(t, events[0].Children, 1)
+	require.Len(t, events[0].Children[0].Children, 1)
+	assert.Equal(t, "late log", events[0].Children[0].Children[0].Data)
+}
+
+func TestEventAggregator_NestedEvents_CompletedAfterParentNotStored(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	childCtx := aggregator.StartEvent(parentCtx)
+	aggregator.EndEvent(parentCtx, "parent")
+	storage.Clear()
+
+	// The parent is not stored anymore, the child is collected as a top-level event
+	aggregator.EndEvent(childCtx, "child")
+	// A grandchild completed later is added to the detached child
+	aggregator.CollectEvent(childCtx, "late log")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	detached := events[0]
+	assert.Equal(t, "child", detached.Data)
+	assert.True(t, detached.Detached)
+	assert.Nil(t, detached.GroupID)
+	require.Len(t, detached.Children, 1)
+	assert.Equal(t, "late log", detached.Children[0].Data)
+	assert.False(t, detached.Children[0].Detached)
+	assert.Equal(t, uint64(1), aggregator.Diagnostics().OrphanedEvents)
+}
+
+func TestEventAggregator_NestedEvents_CompletedAfterParentDropped(t *testing.T) {
+	aggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		Processors: []collector.EventProcessor{
+			collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+				return evt, evt.Data != "dropped"
+			}),
+		},
+	})
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	earlyChildCtx := aggregator.StartEvent(parentCtx)
+	lateChildCtx := aggregator.StartEvent(parentCtx)
+	aggregator.EndEvent(earlyChildCtx, "early child")
+	aggregator.EndEvent(parentCtx, "dropped")
+
+	// Nested events of a dropped event are dropped as well, also if they complete after it
+	aggregator.EndEvent(lateChildCtx, "late child")
+	aggregator.CollectEvent(lateChildCtx, "late log")
+	aggregator.CollectEvent(earlyChildCtx, "late log of early child")
+
+	assert.Empty(t, storage.GetEvents(10))
+	assert.Zero(t, aggregator.Diagnostics().OrphanedEvents)
+}
+
+func TestEventAggregator_NestedEvents_ChildEndsBeforeOrAfterParent(t *testing.T) {
+	tests := []struct {
+		name             string
+		childEndsFirst   bool
+		wantLateChildren bool
+	}{
+		{name: "child ends first", childEndsFirst: true, wantLateChildren: false},
+		{name: "parent ends first", childEndsFirst: false, wantLateChildren: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := collector.NewEventAggregator()
+			defer aggregator.Close()
+
+			sessionID := uuid.Must(uuid.NewV4())
+			storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+			aggregator.RegisterStorage(storage)
+
+			parentCtx := aggregator.StartEvent(context.Background())
+			childCtx := aggregator.StartEvent(parentCtx)
+			if tt.childEndsFirst {
+				aggregator.EndEvent(childCtx, "child")
+				aggregator.EndEvent(parentCtx, "parent")
+			} else {
+				aggregator.EndEvent(parentCtx, "parent")
+				aggregator.EndEvent(childCtx, "child")
+			}
+
+			// Either way the child is nested under the stored parent
+			events := storage.GetEvents(10)
+			require.Len(t, events, 1)
+			assert.Equal(t, "parent", events[0].Data)
+			assert.Equal(t, tt.wantLateChildren, events[0].LateChildren)
+			require.Len(t, events[0].Children, 1)
+			assert.Equal(t, "child", events[0].Children[0].Data)
+			assert.False(t, events[0].Children[0].Detached)
+			assert.Zero(t, aggregator.Diagnostics().OrphanedEvents)
+		})
+	}
+}
+
 func TestEventAggregator_MultipleTopLevelEvents(t *testing.T) {
 	aggregator := collector.NewEventAggregator()
 	defer aggregator.Close()
@@ -347,6 +602,37 @@ func TestEventAggregator_MultipleTopLevelEvents(t *testing.T) {
 	assert.True(t, foundEvents["Event 3"], "Event 3 should be found")
 }
 
+func TestEventAggregator_OrderedBySequence(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	ctx := context.Background()
+
+	// Events started in the same millisecond are completed in reverse order
+	firstCtx := aggregator.StartEvent(ctx)
+	secondCtx := aggregator.StartEvent(ctx)
+	childACtx := aggregator.StartEvent(secondCtx)
+	childBCtx := aggregator.StartEvent(secondCtx)
+	aggregator.EndEvent(childBCtx, "Child B")
+	aggregator.EndEvent(childACtx, "Child A")
+	aggregator.EndEvent(secondCtx, "Second")
+	aggregator.EndEvent(firstCtx, "First")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, "First", events[0].Data)
+	assert.Equal(t, "Second", events[1].Data)
+	assert.Less(t, events[0].Sequence, events[1].Sequence)
+
+	require.Len(t, events[1].Children, 2)
+	assert.Equal(t, "Child A", events[1].Children[0].Data)
+	assert.Equal(t, "Child B", events[1].Children[1].Data)
+}
+
 func TestEventAggregator_WithCustomData(t *testing.T) {
 	aggregator := collector.NewEventAggregator()
 	defer aggregator.Close()
@@ -410,3 +696,201 @@ func TestEventAggregator_WithCustomData(t *testing.T) {
 	assert.True(t, foundHTTP, "HTTP event should be found")
 	assert.True(t, foundLog, "Log event should be found")
 }
+
+func TestEventAggregator_CalculateStats(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storageA := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	storageB := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storageA)
+	aggregator.RegisterStorage(storageB)
+
+	// Events dispatched to both global storages are counted once
+	aggregator.CollectEvent(context.Background(), "first")
+	aggregator.CollectEvent(context.Background(), "second")
+	storageA.SetCapturing(false)
+	aggregator.CollectEvent(context.Background(), "only in B")
+
+	stats := aggregator.CalculateStats()
+	assert.Equal(t, 3, stats.EventCount)
+	assert.Equal(t, 2, stats.StorageCount)
+	require.Len(t, stats.Storages, 2)
+	// Storages are sorted by memory usage
+	assert.Equal(t, storageB.ID(), stats.Storages[0].StorageID)
+	assert.Equal(t, 3, stats.Storages[0].EventCount)
+	assert.Equal(t, storageA.ID(), stats.Storages[1].StorageID)
+	assert.Equal(t, 2, stats.Storages[1].EventCount)
+	assert.Equal(t, stats.Storages[0].MemoryBytes, stats.TotalMemory)
+
+	// Clearing a storage keeps the events of other storages
+	storageB.Clear()
+	stats = aggregator.CalculateStats()
+	assert.Equal(t, 2, stats.EventCount)
+	assert.Equal(t, storageA.Stats().MemoryBytes, stats.TotalMemory)
+
+	// Unregistering a storage releases its events
+	aggregator.UnregisterStorage(storageA.ID())
+	stats = aggregator.CalculateStats()
+	assert.Equal(t, 0, stats.EventCount)
+	assert.Equal(t, uint64(0), stats.TotalMemory)
+}
+
+func TestEventAggregator_Drain(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	eventCtx := aggregator.StartEvent(context.Background())
+
+	// Drain waits for the event in progress
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, aggregator.Drain(ctx), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		aggregator.EndEvent(eventCtx, "completed")
+	}()
+
+	require.NoError(t, aggregator.Drain(context.Background()))
+	assert.Len(t, storage.GetEvents(10), 1)
+}
+
+func TestEventAggregator_RegisterStorage_AfterClose(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	events := storage.Subscribe(context.Background())
+	aggregator.RegisterStorage(storage)
+
+	// The storage is closed and does not capture events
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.False(t, aggregator.ShouldCapture(context.Background()))
+
+	// Closing again is a no-op
+	aggregator.Close()
+}
+
+// panickingStorage is a storage with a bug that panics when adding events
+type panickingStorage struct {
+	*collector.CaptureStorage
+}
+
+func (s panickingStorage) Add(event *collector.Event) {
+	panic("broken storage")
+}
+
+func TestEventAggregator_StoragePanic(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	aggregator.RegisterStorage(panickingStorage{collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)})
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	aggregator.CollectEvent(context.Background(), "data")
+
+	// The other storage receives the event and the internal error
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, "data", events[0].Data)
+	internalErr, ok := events[1].Data.(collector.InternalError)
+	require.True(t, ok)
+	assert.Equal(t, "storage", internalErr.Component)
+	assert.Equal(t, "broken storage", internalErr.Panic)
+
+	assert.Equal(t, uint64(1), aggregator.CalculateStats().RecoveredPanics)
+}
+
+func TestEventAggregator_Diagnostics(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	eventCtx := aggregator.StartEvent(context.Background())
+	assert.Equal(t, 1, aggregator.Diagnostics().OpenEvents)
+
+	aggregator.EndEvent(eventCtx, "request")
+	storage.Clear()
+	// Collected after the parent was completed and is not stored anymore
+	aggregator.CollectEvent(eventCtx, "late log")
+	aggregator.RecordPanic(nil, "test", "boom")
+
+	diagnostics := aggregator.Diagnostics()
+	assert.Equal(t, 0, diagnostics.OpenEvents)
+	assert.Equal(t, uint64(1), diagnostics.OrphanedEvents)
+	assert.Equal(t, uint64(1), diagnostics.RecoveredPanics)
+	require.Len(t, diagnostics.RecentInternalErrors, 1)
+	assert.Equal(t, "boom", diagnostics.RecentInternalErrors[0].Panic)
+}
+
+func TestEventAggregator_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	aggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		Logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	defer aggregator.Close()
+
+	eventCtx := aggregator.StartEvent(context.Background())
+	aggregator.EndEvent(eventCtx, "request")
+	aggregator.CollectEvent(eventCtx, "late log")
+	aggregator.RecordPanic(nil, "test", "boom")
+
+	assert.Contains(t, buf.String(), "Collected nested event as top-level event")
+	assert.Contains(t, buf.String(), "Recovered panic in devlog")
+	assert.Contains(t, buf.String(), "component=test")
+}
+
+func TestEventAggregator_Logger_Default(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	assert.False(t, aggregator.Logger().Enabled(context.Background(), slog.LevelError), "expected a silent logger by default")
+}
+
+func BenchmarkEventAggregator_CollectEvent(b *testing.B) {
+	benchmarks := []struct {
+		name            string
+		globalStorages  int
+		sessionStorages int
+	}{
+		{name: "without storages"},
+		{name: "one global storage", globalStorages: 1},
+		{name: "ten session storages", sessionStorages: 10},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			aggregator := collector.NewEventAggregator()
+			defer aggregator.Close()
+
+			for i := 0; i < bm.globalStorages; i++ {
+				storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 1000, collector.CaptureModeGlobal)
+				defer storage.Close()
+				aggregator.RegisterStorage(storage)
+			}
+			ctx := context.Background()
+			for i := 0; i < bm.sessionStorages; i++ {
+				sessionID := uuid.Must(uuid.NewV4())
+				storage := collector.NewCaptureStorage(sessionID, 1000, collector.CaptureModeSession)
+				defer storage.Close()
+				aggregator.RegisterStorage(storage)
+				// The events are captured by the last session only
+				ctx = collector.WithSessionIDs(context.Background(), []uuid.UUID{sessionID})
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				aggregator.CollectEvent(ctx, collector.DBQuery{Query: "SELECT * FROM orders WHERE id = $1", Args: []driver.NamedValue{{Ordinal: 1, Value: int64(i)}}, Duration: time.Millisecond})
+			}
+		})
+	}
+}
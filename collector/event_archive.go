@@ -0,0 +1,474 @@
+package collector
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gofrs/uuid"
+)
+
+// ErrArchiveSegmentNotFound is returned when reading a segment that was deleted to stay within the size of the archive
+var ErrArchiveSegmentNotFound = errors.New("archive segment not found")
+
+// ErrArchiveClosed is returned when adding events to a closed archive
+var ErrArchiveClosed = errors.New("archive closed")
+
+// Default sizes of an EventArchive
+const (
+	DefaultArchiveMaxBytes     int64 = 64 << 20
+	DefaultArchiveSegmentBytes int64 = 4 << 20
+)
+
+// DefaultArchiveQueueSize is the default number of events waiting to be written to an EventArchive
+const DefaultArchiveQueueSize = 1000
+
+// EventArchiveOptions configures an EventArchive
+type EventArchiveOptions struct {
+	// MaxBytes limits the total compressed size of all segments, the oldest segments are deleted when it is exceeded.
+	// Default: 0, will use DefaultArchiveMaxBytes
+	MaxBytes int64
+
+	// SegmentBytes is the compressed size of a segment after which a new segment is started. Segments are deleted as a
+	// whole, so smaller segments keep the archive closer to MaxBytes.
+	// Default: 0, will use DefaultArchiveSegmentBytes (or MaxBytes if it is smaller)
+	SegmentBytes int64
+
+	// QueueSize is the number of events queued with AddAsync that wait for the writer, further events are dropped.
+	// Default: 0, will use DefaultArchiveQueueSize
+	QueueSize int
+
+	// Logger is used to log failures of the writer (optional)
+	Logger *slog.Logger
+}
+
+// EventArchiveStats describes the events in an archive
+type EventArchiveStats struct {
+	Events   int   `json:"events"`
+	Segments int   `json:"segments"`
+	Bytes    int64 `json:"bytes"`
+	// DeletedEvents is the number of events that were deleted with their segment to stay within MaxBytes
+	DeletedEvents int `json:"deletedEvents"`
+	// DroppedEvents is the number of events that were not archived because the queue of the writer was full
+	DroppedEvents uint64 `json:"droppedEvents"`
+}
+
+// EventArchiveSegment describes a segment of an archive, IDs increase with every segment started
+type EventArchiveSegment struct {
+	ID     uint64 `json:"id"`
+	Events int    `json:"events"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// EventArchive keeps events evicted from the memory of a CaptureStorage (see CaptureStorage.SetArchive) in gzip
+// compressed segments of newline delimited JSON (see JSONEventCodec) on disk. Archived events are read lazily by
+// segment and marked as Archived. The archive owns its directory, it is deleted with all segments when the archive is
+// closed. Events queued with AddAsync are written by a goroutine of the archive.
+type EventArchive struct {
+	dir     string
+	options EventArchiveOptions
+	logger  *slog.Logger
+
+	// queueMu guards closing the queue, it is separate from mu so queuing doesn't wait for the disk
+	queueMu     sync.RWMutex
+	queueClosed bool
+	queue       chan queuedEvent
+	writerDone  chan struct{}
+	// generation is increased by Clear, queued events of an older generation are discarded
+	generation atomic.Uint64
+	dropped    atomic.Uint64
+
+	mu       sync.Mutex
+	closed   bool
+	nextID   uint64
+	segments []*archiveSegment // oldest first, the last one is written
+	index    map[uuid.UUID]*archiveSegment
+	deleted  int
+
+	// writer of the last segment, nil until an event is added to it
+	file    *os.File
+	counter *countingWriter
+	gzip    *gzip.Writer
+	encoder EventEncoder
+}
+
+// queuedEvent is an event waiting for the writer of an archive, or a flush if flushed is set
+type queuedEvent struct {
+	event      *Event
+	generation uint64
+	flushed    chan struct{}
+}
+
+// archiveSegment is a file of archived events
+type archiveSegment struct {
+	id     uint64
+	path   string
+	size   int64
+	events int
+	// ids are the IDs of the archived events and their children
+	ids []uuid.UUID
+}
+
+// countingWriter counts the bytes written to a segment file
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// NewEventArchive creates an archive writing segments to dir, which is created if it doesn't exist.
+// Segments left in dir from a previous archive are not read.
+func NewEventArchive(dir string, options EventArchiveOptions) (*EventArchive, error) {
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultArchiveMaxBytes
+	}
+	if options.SegmentBytes <= 0 {
+		options.SegmentBytes = min(DefaultArchiveSegmentBytes, options.MaxBytes)
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultArchiveQueueSize
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating archive directory: %w", err)
+	}
+
+	a := &EventArchive{
+		dir:        dir,
+		options:    options,
+		logger:     loggerOrDiscard(options.Logger),
+		queue:      make(chan queuedEvent, options.QueueSize),
+		writerDone: make(chan struct{}),
+		index:      make(map[uuid.UUID]*archiveSegment),
+	}
+	go a.write()
+
+	return a, nil
+}
+
+// Dir returns the directory of the segments
+func (a *EventArchive) Dir() string {
+	return a.dir
+}
+
+// Add appends events to the last segment. A new segment is started when it is full and the oldest segments are deleted
+// if the archive exceeds its maximum size.
+func (a *EventArchive) Add(events ...*Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrArchiveClosed
+	}
+	return a.add(events)
+}
+
+// add appends events to the last segment. Must be called with the lock held.
+func (a *EventArchive) add(events []*Event) error {
+	for _, event := range events {
+		if a.encoder == nil {
+			if err := a.startSegment(); err != nil {
+				return err
+			}
+		}
+
+		segment := a.segments[len(a.segments)-1]
+		if err := a.encoder.Encode(event); err != nil {
+			return fmt.Errorf("archiving event %s: %w", event.ID, err)
+		}
+		// Flushing writes the compressed event to the file, so the size is known and the event can be read
+		if err := a.gzip.Flush(); err != nil {
+			return fmt.Errorf("archiving event %s: %w", event.ID, err)
+		}
+		segment.events++
+		for id := range event.Visit() {
+			segment.ids = append(segment.ids, id)
+			a.index[id] = segment
+		}
+		segment.size = a.counter.n
+
+		if segment.size >= a.options.SegmentBytes {
+			if err := a.finishSegment(); err != nil {
+				return err
+			}
+		}
+	}
+	a.enforceMaxBytes()
+
+	return nil
+}
+
+// AddAsync queues an event for the writer of the archive, so the caller doesn't wait for the disk. The event is
+// dropped if the queue is full (see EventArchiveStats.DroppedEvents) or the archive is closed. Failures of the writer
+// are logged.
+func (a *EventArchive) AddAsync(event *Event) {
+	a.queueMu.RLock()
+	defer a.queueMu.RUnlock()
+	if a.queueClosed {
+		return
+	}
+
+	select {
+	case a.queue <- queuedEvent{event: event, generation: a.generation.Load()}:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Flush waits until the events queued with AddAsync before were written
+func (a *EventArchive) Flush() {
+	flushed := make(chan struct{})
+	a.queueMu.RLock()
+	if a.queueClosed {
+		a.queueMu.RUnlock()
+		return
+	}
+	a.queue <- queuedEvent{flushed: flushed}
+	a.queueMu.RUnlock()
+
+	<-flushed
+}
+
+// write adds queued events until the queue is closed
+func (a *EventArchive) write() {
+	defer close(a.writerDone)
+
+	for queued := range a.queue {
+		if queued.flushed != nil {
+			close(queued.flushed)
+			continue
+		}
+
+		a.mu.Lock()
+		var err error
+		// Events queued before the archive was cleared or closed are discarded
+		if !a.closed && queued.generation == a.generation.Load() {
+			err = a.add([]*Event{queued.event})
+		}
+		a.mu.Unlock()
+		if err != nil {
+			a.logger.Warn("Failed to archive evicted event", "event", queued.event.ID, "error", err)
+		}
+	}
+}
+
+// startSegment creates the file of a new segment. Must be called with the lock held.
+func (a *EventArchive) startSegment() error {
+	a.nextID++
+	segment := &archiveSegment{
+		id:   a.nextID,
+		path: filepath.Join(a.dir, fmt.Sprintf("segment-%08d.ndjson.gz", a.nextID)),
+	}
+	file, err := os.OpenFile(segment.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating archive segment: %w", err)
+	}
+
+	a.file = file
+	a.counter = &countingWriter{w: file}
+	a.gzip = gzip.NewWriter(a.counter)
+	a.encoder = JSONEventCodec.NewEncoder(a.gzip)
+	a.segments = append(a.segments, segment)
+	return nil
+}
+
+// finishSegment completes the compressed stream of the last segment and closes its file. Must be called with the lock
+// held.
+func (a *EventArchive) finishSegment() error {
+	if a.encoder == nil {
+		return nil
+	}
+	segment := a.segments[len(a.segments)-1]
+	err := errors.Join(a.gzip.Close(), a.file.Close())
+	segment.size = a.counter.n
+	a.file, a.counter, a.gzip, a.encoder = nil, nil, nil, nil
+	if err != nil {
+		return fmt.Errorf("finishing archive segment: %w", err)
+	}
+	return nil
+}
+
+// enforceMaxBytes deletes the oldest segments until the archive is within its maximum size, the last segment is kept.
+// Must be called with the lock held.
+func (a *EventArchive) enforceMaxBytes() {
+	for len(a.segments) > 1 && a.size() > a.options.MaxBytes {
+		a.deleteSegment(a.segments[0])
+		a.segments = a.segments[1:]
+	}
+}
+
+// deleteSegment removes the file and the index entries of a segment that is not written. Must be called with the lock
+// held.
+func (a *EventArchive) deleteSegment(segment *archiveSegment) {
+	for _, id := range segment.ids {
+		if a.index[id] == segment {
+			delete(a.index, id)
+		}
+	}
+	a.deleted += segment.events
+	_ = os.Remove(segment.path)
+}
+
+// size returns the compressed size of all segments. Must be called with the lock held.
+func (a *EventArchive) size() int64 {
+	var size int64
+	for _, segment := range a.segments {
+		size += segment.size
+	}
+	return size
+}
+
+// Stats returns the number and size of the archived events
+func (a *EventArchive) Stats() EventArchiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := EventArchiveStats{
+		Segments:      len(a.segments),
+		Bytes:         a.size(),
+		DeletedEvents: a.deleted,
+		DroppedEvents: a.dropped.Load(),
+	}
+	for _, segment := range a.segments {
+		stats.Events += segment.events
+	}
+	return stats
+}
+
+// Segments returns the segments of the archive, oldest first
+func (a *EventArchive) Segments() []EventArchiveSegment {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	segments := make([]EventArchiveSegment, len(a.segments))
+	for i, segment := range a.segments {
+		segments[i] = EventArchiveSegment{
+			ID:     segment.id,
+			Events: segment.events,
+			Bytes:  segment.size,
+		}
+	}
+	return segments
+}
+
+// ReadSegment reads the events of a segment in the order they were archived, the events are marked as Archived
+func (a *EventArchive) ReadSegment(id uint64) ([]*Event, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i := slices.IndexFunc(a.segments, func(segment *archiveSegment) bool {
+		return segment.id == id
+	})
+	if i == -1 {
+		return nil, ErrArchiveSegmentNotFound
+	}
+	return a.readSegment(a.segments[i])
+}
+
+// GetEvent reads an archived event or a child of an archived event by its ID from its segment
+func (a *EventArchive) GetEvent(id uuid.UUID) (*Event, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	segment, ok := a.index[id]
+	if !ok {
+		return nil, false, nil
+	}
+	events, err := a.readSegment(segment)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, event := range events {
+		for entryID, entry := range event.Visit() {
+			if entryID == id {
+				return entry, true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// readSegment decodes the events of a segment. Must be called with the lock held.
+func (a *EventArchive) readSegment(segment *archiveSegment) ([]*Event, error) {
+	// The compressed stream of the written segment is not completed yet
+	written := a.encoder != nil && segment == a.segments[len(a.segments)-1]
+
+	file, err := os.Open(segment.path)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive segment: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive segment: %w", err)
+	}
+	events := make([]*Event, 0, segment.events)
+	decoder := JSONEventCodec.NewDecoder(reader)
+	for len(events) < segment.events {
+		event, err := decoder.Decode()
+		// The stream of the written segment ends after its last flushed event
+		if errors.Is(err, io.EOF) || (written && errors.Is(err, io.ErrUnexpectedEOF)) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding archived event %d: %w", len(events)+1, err)
+		}
+		for _, entry := range event.Visit() {
+			entry.Archived = true
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Clear deletes all segments, events that are still queued are discarded
+func (a *EventArchive) Clear() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.generation.Add(1)
+	a.dropped.Store(0)
+	err := a.finishSegment()
+	for _, segment := range a.segments {
+		a.deleteSegment(segment)
+	}
+	a.segments = nil
+	a.deleted = 0
+	return err
+}
+
+// Close closes the archive and deletes its directory with all segments, events that are still queued are discarded.
+// It waits until the writer has stopped.
+func (a *EventArchive) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	err := a.finishSegment()
+	clear(a.index)
+	a.segments = nil
+	a.mu.Unlock()
+
+	a.queueMu.Lock()
+	a.queueClosed = true
+	close(a.queue)
+	a.queueMu.Unlock()
+	<-a.writerDone
+
+	return errors.Join(err, os.RemoveAll(a.dir))
+}
@@ -0,0 +1,192 @@
+package collector_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func newArchiveTestEvent(i int, query string) *collector.Event {
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC).Add(time.Duration(i) * time.Second)
+	return &collector.Event{
+		ID:       uuid.Must(uuid.NewV7()),
+		Data:     collector.DBQuery{Query: query, Timestamp: start},
+		Start:    start,
+		End:      start.Add(time.Millisecond),
+		Sequence: uint64(i + 1),
+	}
+}
+
+func TestEventArchive(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	archive, err := collector.NewEventArchive(dir, collector.EventArchiveOptions{})
+	require.NoError(t, err)
+
+	parent := newArchiveTestEvent(0, "SELECT 0")
+	child := newArchiveTestEvent(1, "SELECT 1")
+	child.GroupID = &parent.ID
+	parent.Children = []*collector.Event{child}
+	require.NoError(t, archive.Add(parent, newArchiveTestEvent(2, "SELECT 2")))
+
+	// The segment is read while it is still written
+	segments := archive.Segments()
+	require.Len(t, segments, 1)
+	assert.Equal(t, 2, segments[0].Events)
+	events, err := archive.ReadSegment(segments[0].ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, parent.ID, events[0].ID)
+	assert.Equal(t, collector.DBQuery{Query: "SELECT 0", Timestamp: parent.Start}, events[0].Data)
+	assert.True(t, events[0].Archived)
+	require.Len(t, events[0].Children, 1)
+	assert.True(t, events[0].Children[0].Archived)
+	assert.Equal(t, "SELECT 2", events[1].Data.(collector.DBQuery).Query)
+
+	// Events added after reading are appended to the same segment
+	require.NoError(t, archive.Add(newArchiveTestEvent(3, "SELECT 3")))
+	events, err = archive.ReadSegment(segments[0].ID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	archived, ok, err := archive.GetEvent(child.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "SELECT 1", archived.Data.(collector.DBQuery).Query)
+
+	_, ok, err = archive.GetEvent(uuid.Must(uuid.NewV7()))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	stats := archive.Stats()
+	assert.Equal(t, 3, stats.Events)
+	assert.Equal(t, 1, stats.Segments)
+	assert.Positive(t, stats.Bytes)
+
+	require.NoError(t, archive.Close())
+	assert.NoDirExists(t, dir)
+	assert.ErrorIs(t, archive.Add(newArchiveTestEvent(4, "SELECT 4")), collector.ErrArchiveClosed)
+}
+
+func TestEventArchive_MaxBytes(t *testing.T) {
+	archive, err := collector.NewEventArchive(t.TempDir(), collector.EventArchiveOptions{
+		MaxBytes:     4096,
+		SegmentBytes: 1024,
+	})
+	require.NoError(t, err)
+	defer archive.Close()
+
+	var ids []uuid.UUID
+	for i := range 200 {
+		// Random IDs in the query compress badly, so segments fill up quickly
+		event := newArchiveTestEvent(i, fmt.Sprintf("SELECT '%s'", strings.Repeat(uuid.Must(uuid.NewV4()).String(), 4)))
+		ids = append(ids, event.ID)
+		require.NoError(t, archive.Add(event))
+	}
+
+	stats := archive.Stats()
+	assert.Greater(t, stats.Segments, 1)
+	// The last segment may exceed its size by one event
+	assert.LessOrEqual(t, stats.Bytes, int64(4096+1024))
+	assert.Positive(t, stats.DeletedEvents)
+	assert.Equal(t, 200, stats.Events+stats.DeletedEvents)
+
+	segments := archive.Segments()
+	_, err = archive.ReadSegment(segments[0].ID - 1)
+	assert.ErrorIs(t, err, collector.ErrArchiveSegmentNotFound)
+
+	// Events of deleted segments are gone, the most recent events are kept
+	_, ok, err := archive.GetEvent(ids[0])
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = archive.GetEvent(ids[len(ids)-1])
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	var read int
+	for _, segment := range segments {
+		events, err := archive.ReadSegment(segment.ID)
+		require.NoError(t, err)
+		assert.Len(t, events, segment.Events)
+		read += len(events)
+	}
+	assert.Equal(t, stats.Events, read)
+
+	files, err := os.ReadDir(archive.Dir())
+	require.NoError(t, err)
+	assert.Len(t, files, len(segments))
+}
+
+func TestCaptureStorage_SetArchive(t *testing.T) {
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 2, collector.CaptureModeGlobal)
+	dir := filepath.Join(t.TempDir(), "archive")
+	archive, err := collector.NewEventArchive(dir, collector.EventArchiveOptions{})
+	require.NoError(t, err)
+	storage.SetArchive(archive)
+	assert.Same(t, archive, storage.Archive())
+
+	var events []*collector.Event
+	for i := range 5 {
+		event := newArchiveTestEvent(i, fmt.Sprintf("SELECT %d", i))
+		events = append(events, event)
+		storage.Add(event)
+	}
+
+	// Evicted events are archived in the background and still found by their ID
+	archive.Flush()
+	assert.Len(t, storage.GetEvents(10), 2)
+	assert.Equal(t, 3, archive.Stats().Events)
+	archived, ok := storage.GetEvent(events[0].ID)
+	require.True(t, ok)
+	assert.True(t, archived.Archived)
+	assert.Equal(t, "SELECT 0", archived.Data.(collector.DBQuery).Query)
+	stored, ok := storage.GetEvent(events[4].ID)
+	require.True(t, ok)
+	assert.False(t, stored.Archived)
+
+	// Removed events and evicted events in progress are not archived
+	require.True(t, storage.Remove(events[4].ID))
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), InProgress: true})
+	storage.Add(newArchiveTestEvent(5, "SELECT 5"))
+	storage.Add(newArchiveTestEvent(6, "SELECT 6"))
+	archive.Flush()
+	assert.Equal(t, 4, archive.Stats().Events)
+
+	storage.Clear()
+	assert.Equal(t, collector.EventArchiveStats{}, archive.Stats())
+	_, ok = storage.GetEvent(events[0].ID)
+	assert.False(t, ok)
+
+	storage.Close()
+	assert.NoDirExists(t, dir)
+}
+
+func TestEventArchive_AddAsync(t *testing.T) {
+	archive, err := collector.NewEventArchive(filepath.Join(t.TempDir(), "archive"), collector.EventArchiveOptions{QueueSize: 1})
+	require.NoError(t, err)
+	defer archive.Close()
+
+	// Events are dropped while the queue is full
+	const n = 100
+	for i := range n {
+		archive.AddAsync(newArchiveTestEvent(i, fmt.Sprintf("SELECT %d", i)))
+	}
+	archive.Flush()
+	stats := archive.Stats()
+	assert.Positive(t, stats.Events)
+	assert.Equal(t, n, stats.Events+int(stats.DroppedEvents), "every event should be archived or dropped")
+
+	// Events queued after closing are dropped
+	require.NoError(t, archive.Close())
+	archive.AddAsync(newArchiveTestEvent(n, "SELECT"))
+	archive.Flush()
+	assert.Equal(t, collector.EventArchiveStats{DroppedEvents: stats.DroppedEvents}, archive.Stats())
+}
@@ -0,0 +1,572 @@
+package collector
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ErrUnsupportedSchemaVersion is returned when decoding an event of an unknown schema version
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// eventRecord is the serialized form of an event, the data is the JSON of the payload for all codecs
+type eventRecord struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	ID            uuid.UUID       `json:"id"`
+	GroupID       *uuid.UUID      `json:"groupId,omitempty"`
+	Kind          EventKind       `json:"kind"`
+	Start         time.Time       `json:"start"`
+	End           time.Time       `json:"end"`
+	Ambient       bool            `json:"ambient,omitempty"`
+	InProgress    bool            `json:"inProgress,omitempty"`
+	Detached      bool            `json:"detached,omitempty"`
+	Tenant        string          `json:"tenant,omitempty"`
+	Sequence      uint64          `json:"sequence,omitempty"`
+	Severity      Severity        `json:"severity,omitempty"`
+	Data          json.RawMessage `json:"data"`
+	Children      []eventRecord   `json:"children,omitempty"`
+}
+
+// newEventRecord serializes an event with its children
+func newEventRecord(e *Event) (eventRecord, error) {
+	payload := e.Payload()
+	data, err := payload.MarshalJSON()
+	if err != nil {
+		return eventRecord{}, fmt.Errorf("serializing %s data of event %s: %w", payload.Kind(), e.ID, err)
+	}
+
+	record := eventRecord{
+		SchemaVersion: EventSchemaVersion,
+		ID:            e.ID,
+		GroupID:       e.GroupID,
+		Kind:          payload.Kind(),
+		Start:         e.Start,
+		End:           e.End,
+		Ambient:       e.Ambient,
+		InProgress:    e.InProgress,
+		Detached:      e.Detached,
+		Tenant:        e.Tenant,
+		Sequence:      e.Sequence,
+		Severity:      e.Severity,
+		Data:          data,
+	}
+	for _, child := range e.Children {
+		childRecord, err := newEventRecord(child)
+		if err != nil {
+			return eventRecord{}, err
+		}
+		record.Children = append(record.Children, childRecord)
+	}
+	return record, nil
+}
+
+// event deserializes the event with its children, data of unknown kinds is decoded as generic JSON values
+func (r eventRecord) event() (*Event, error) {
+	if r.SchemaVersion != EventSchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, r.SchemaVersion)
+	}
+
+	data, err := decodePayload(r.Kind, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("deserializing %s data of event %s: %w", r.Kind, r.ID, err)
+	}
+
+	e := &Event{
+		ID:         r.ID,
+		GroupID:    r.GroupID,
+		Data:       data,
+		Start:      r.Start,
+		End:        r.End,
+		Ambient:    r.Ambient,
+		InProgress: r.InProgress,
+		Detached:   r.Detached,
+		Tenant:     r.Tenant,
+		Sequence:   r.Sequence,
+		Severity:   r.Severity,
+	}
+	if e.Severity == "" {
+		// Events serialized before severities were introduced are rated with the default thresholds
+		e.Severity = DefaultSeverityThresholds().Rate(data)
+	}
+	for _, childRecord := range r.Children {
+		child, err := childRecord.event()
+		if err != nil {
+			return nil, err
+		}
+		e.Children = append(e.Children, child)
+	}
+	e.Size = e.calculateSize()
+	return e, nil
+}
+
+// decodePayload deserializes the data of an event by its kind
+func decodePayload(kind EventKind, data []byte) (any, error) {
+	switch kind {
+	case EventKindHTTPServerRequest:
+		var r HTTPServerRequest
+		err := json.Unmarshal(data, &r)
+		return r, err
+	case EventKindHTTPClientRequest:
+		var r HTTPClientRequest
+		err := json.Unmarshal(data, &r)
+		return r, err
+	case EventKindDBQuery:
+		var q DBQuery
+		err := json.Unmarshal(data, &q)
+		return q, err
+	case EventKindDBPoolStats:
+		var s DBPoolStats
+		err := json.Unmarshal(data, &s)
+		return s, err
+	case EventKindRPCCall:
+		var c RPCCall
+		err := json.Unmarshal(data, &c)
+		return c, err
+	case EventKindCacheOp:
+		var o CacheOp
+		err := json.Unmarshal(data, &o)
+		return o, err
+	case EventKindFlagEvaluation:
+		var f FlagEvaluation
+		err := json.Unmarshal(data, &f)
+		return f, err
+	case EventKindMail:
+		var m Mail
+		err := json.Unmarshal(data, &m)
+		return m, err
+	case EventKindLog:
+		return decodeLogRecord(data)
+	case EventKindInternalError:
+		var e InternalError
+		err := json.Unmarshal(data, &e)
+		return e, err
+	case EventKindTask:
+		var t Task
+		err := json.Unmarshal(data, &t)
+		return t, err
+	}
+
+	eventKindsMu.RLock()
+	decode, ok := eventKindDecoders[kind]
+	eventKindsMu.RUnlock()
+	if ok {
+		return decode(data)
+	}
+
+	var v any
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// MarshalJSON serializes the event with its data and children in the versioned event schema (see EventSchemaVersion)
+func (e *Event) MarshalJSON() ([]byte, error) {
+	record, err := newEventRecord(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(record)
+}
+
+// UnmarshalJSON deserializes an event serialized with MarshalJSON.
+// The data of built-in and registered kinds (see RegisterEventKind) is restored with its type,
+// the data of other kinds is decoded as generic JSON values.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var record eventRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	decoded, err := record.event()
+	if err != nil {
+		return err
+	}
+	*e = *decoded
+	return nil
+}
+
+// EventEncoder writes events to a stream
+type EventEncoder interface {
+	Encode(event *Event) error
+}
+
+// EventDecoder reads events from a stream, Decode returns io.EOF at the end of the stream
+type EventDecoder interface {
+	Decode() (*Event, error)
+}
+
+// EventCodec creates encoders and decoders for streams of events, e.g. to persist, export or ship events to another process
+type EventCodec interface {
+	NewEncoder(w io.Writer) EventEncoder
+	NewDecoder(r io.Reader) EventDecoder
+}
+
+var (
+	// JSONEventCodec encodes events as newline delimited JSON
+	JSONEventCodec EventCodec = jsonEventCodec{}
+	// GobEventCodec encodes events with encoding/gob, which is more compact than JSON
+	GobEventCodec EventCodec = gobEventCodec{}
+)
+
+type jsonEventCodec struct{}
+
+func (jsonEventCodec) NewEncoder(w io.Writer) EventEncoder {
+	return jsonEventEncoder{json.NewEncoder(w)}
+}
+
+func (jsonEventCodec) NewDecoder(r io.Reader) EventDecoder {
+	return jsonEventDecoder{json.NewDecoder(bufio.NewReader(r))}
+}
+
+type jsonEventEncoder struct {
+	enc *json.Encoder
+}
+
+func (e jsonEventEncoder) Encode(event *Event) error {
+	return e.enc.Encode(event)
+}
+
+type jsonEventDecoder struct {
+	dec *json.Decoder
+}
+
+func (d jsonEventDecoder) Decode() (*Event, error) {
+	event := new(Event)
+	if err := d.dec.Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+type gobEventCodec struct{}
+
+func (gobEventCodec) NewEncoder(w io.Writer) EventEncoder {
+	return gobEventEncoder{gob.NewEncoder(w)}
+}
+
+func (gobEventCodec) NewDecoder(r io.Reader) EventDecoder {
+	return gobEventDecoder{gob.NewDecoder(r)}
+}
+
+type gobEventEncoder struct {
+	enc *gob.Encoder
+}
+
+func (e gobEventEncoder) Encode(event *Event) error {
+	record, err := newEventRecord(event)
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(record)
+}
+
+type gobEventDecoder struct {
+	dec *gob.Decoder
+}
+
+func (d gobEventDecoder) Decode() (*Event, error) {
+	var record eventRecord
+	if err := d.dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	return record.event()
+}
+
+// newDecodedBody restores a captured body, it can't be read anymore since the original reader is gone
+func newDecodedBody(b *bodyJSON) *Body {
+	if b == nil {
+		return nil
+	}
+	content := b.ContentBase64
+	if content == nil {
+		content = []byte(b.Content)
+	}
+	body := NewBody(nil, len(content))
+	_, _ = body.buffer.Write(content)
+	body.buffer.truncated = b.Truncated
+	body.isFullyCaptured = b.FullyCaptured
+	if b.Evicted {
+		body.buffer = nil
+		body.evicted = true
+	}
+	return body
+}
+
+// decodedError restores an error from its message
+func decodedError(message string) error {
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
+
+// UnmarshalJSON deserializes a request serialized with MarshalJSON, errors are restored with their message only
+func (r *HTTPServerRequest) UnmarshalJSON(data []byte) error {
+	var j httpServerRequestJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*r = HTTPServerRequest{
+		ID:                     j.ID,
+		Method:                 j.Method,
+		Path:                   j.Path,
+		RoutePattern:           j.RoutePattern,
+		URL:                    j.URL,
+		RemoteAddr:             j.RemoteAddr,
+		RequestTime:            j.RequestTime,
+		ResponseTime:           j.ResponseTime,
+		StatusCode:             j.StatusCode,
+		RequestSize:            j.RequestSize,
+		ResponseSize:           j.ResponseSize,
+		RequestHeaders:         j.RequestHeaders,
+		ResponseHeaders:        j.ResponseHeaders,
+		RequestBody:            newDecodedBody(j.RequestBody),
+		ResponseBody:           newDecodedBody(j.ResponseBody),
+		ResponseTrailers:       j.ResponseTrailers,
+		InformationalResponses: j.InformationalResponses,
+		Streaming:              j.Streaming,
+		ClientDisconnected:     j.ClientDisconnected,
+		Tags:                   j.Tags,
+		Error:                  decodedError(j.Error),
+		Panic:                  j.Panic,
+	}
+	if j.DisconnectTime != nil {
+		r.DisconnectTime = *j.DisconnectTime
+	}
+	if r.Tags == nil {
+		r.Tags = make(map[string]string)
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes a request serialized with MarshalJSON, errors are restored with their message only
+func (r *HTTPClientRequest) UnmarshalJSON(data []byte) error {
+	var j httpClientRequestJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*r = HTTPClientRequest{
+		ID:              j.ID,
+		Method:          j.Method,
+		URL:             j.URL,
+		RequestTime:     j.RequestTime,
+		ResponseTime:    j.ResponseTime,
+		StatusCode:      j.StatusCode,
+		RequestSize:     j.RequestSize,
+		ResponseSize:    j.ResponseSize,
+		RequestHeaders:  j.RequestHeaders,
+		ResponseHeaders: j.ResponseHeaders,
+		RequestBody:     newDecodedBody(j.RequestBody),
+		ResponseBody:    newDecodedBody(j.ResponseBody),
+		Attempt:         j.Attempt,
+		Tags:            j.Tags,
+		Error:           decodedError(j.Error),
+		ErrorInfo:       j.ErrorInfo,
+	}
+	if j.AttemptGroupID != nil {
+		r.AttemptGroupID = *j.AttemptGroupID
+	}
+	if j.DerivedFrom != nil {
+		r.DerivedFrom = *j.DerivedFrom
+	}
+	for _, attempt := range j.PreviousAttempts {
+		r.PreviousAttempts = append(r.PreviousAttempts, HTTPClientAttempt{
+			RequestID:  attempt.RequestID,
+			Attempt:    attempt.Attempt,
+			StatusCode: attempt.StatusCode,
+			Duration:   time.Duration(attempt.DurationNs),
+			Error:      decodedError(attempt.Error),
+		})
+	}
+	if r.Tags == nil {
+		r.Tags = make(map[string]string)
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes a query serialized with MarshalJSON.
+// Arguments are restored as JSON values (e.g. numbers as float64), errors with their message only.
+func (q *DBQuery) UnmarshalJSON(data []byte) error {
+	var j dbQueryJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*q = DBQuery{
+		Query:           j.Query,
+		Duration:        time.Duration(j.DurationNs),
+		Timestamp:       j.Timestamp,
+		Language:        j.Language,
+		Database:        j.Database,
+		Tags:            j.Tags,
+		ConnID:          j.ConnID,
+		TxID:            j.TxID,
+		Error:           decodedError(j.Error),
+		RowsReturned:    j.RowsReturned,
+		RowsAffected:    j.RowsAffected,
+		Prepared:        j.Prepared,
+		StatementReused: j.StatementReused,
+	}
+	for _, arg := range j.Args {
+		q.Args = append(q.Args, driver.NamedValue{Name: arg.Name, Ordinal: arg.Ordinal, Value: arg.Value})
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes pool stats serialized with MarshalJSON
+func (s *DBPoolStats) UnmarshalJSON(data []byte) error {
+	var j dbPoolStatsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = DBPoolStats{
+		Name:              j.Name,
+		Timestamp:         j.Timestamp,
+		ThresholdExceeded: j.ThresholdExceeded,
+		Recovered:         j.Recovered,
+	}
+	s.MaxOpenConnections = j.MaxOpenConnections
+	s.OpenConnections = j.OpenConnections
+	s.InUse = j.InUse
+	s.Idle = j.Idle
+	s.WaitCount = j.WaitCount
+	s.WaitDuration = time.Duration(j.WaitDurationNs)
+	s.MaxIdleClosed = j.MaxIdleClosed
+	s.MaxIdleTimeClosed = j.MaxIdleTimeClosed
+	s.MaxLifetimeClosed = j.MaxLifetimeClosed
+	return nil
+}
+
+// UnmarshalJSON deserializes a call serialized with MarshalJSON, errors are restored with their message only
+func (c *RPCCall) UnmarshalJSON(data []byte) error {
+	var j rpcCallJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*c = RPCCall{
+		Procedure: j.Procedure,
+		Protocol:  j.Protocol,
+		Client:    j.Client,
+		Stream:    j.Stream,
+		Peer:      j.Peer,
+		Timestamp: j.Timestamp,
+		Duration:  time.Duration(j.DurationNs),
+		Request:   rawJSONString(j.Request),
+		Response:  rawJSONString(j.Response),
+		Code:      j.Code,
+		Error:     decodedError(j.Error),
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes an operation serialized with MarshalJSON, errors are restored with their message only
+func (o *CacheOp) UnmarshalJSON(data []byte) error {
+	var j cacheOpJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*o = CacheOp{
+		Cache:     j.Cache,
+		Operation: CacheOperation(j.Operation),
+		Key:       j.Key,
+		Hit:       j.Hit,
+		ValueSize: j.ValueSize,
+		Timestamp: j.Timestamp,
+		Duration:  time.Duration(j.DurationNs),
+		Error:     decodedError(j.Error),
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes an evaluation serialized with MarshalJSON, errors are restored with their message only
+func (f *FlagEvaluation) UnmarshalJSON(data []byte) error {
+	var j flagEvaluationJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*f = FlagEvaluation{
+		Flag:      j.Flag,
+		Value:     j.Value,
+		Variant:   j.Variant,
+		Reason:    j.Reason,
+		Provider:  j.Provider,
+		Timestamp: j.Timestamp,
+		Error:     decodedError(j.Error),
+	}
+	return nil
+}
+
+// UnmarshalJSON deserializes a mail serialized with MarshalJSON, errors are restored with their message only
+func (m *Mail) UnmarshalJSON(data []byte) error {
+	var j mailJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	var attachments []MailAttachment
+	for _, attachment := range j.Attachments {
+		attachments = append(attachments, MailAttachment(attachment))
+	}
+	*m = Mail{
+		From:          j.From,
+		To:            j.To,
+		Cc:            j.Cc,
+		Bcc:           j.Bcc,
+		Subject:       j.Subject,
+		TextBody:      j.TextBody,
+		HTMLBody:      j.HTMLBody,
+		BodyTruncated: j.BodyTruncated,
+		Attachments:   attachments,
+		Server:        j.Server,
+		Timestamp:     j.Timestamp,
+		Duration:      time.Duration(j.DurationNs),
+		Error:         decodedError(j.Error),
+	}
+	return nil
+}
+
+// rawJSONString reverses rawJSONMessage, strings are unquoted and other JSON values are kept as they are
+func rawJSONString(m json.RawMessage) string {
+	if len(m) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(m, &s); err == nil {
+		return s
+	}
+	return string(m)
+}
+
+// decodeLogRecord restores a log record serialized by logPayload.MarshalJSON.
+// Attributes are restored in the order of their keys, nested objects as groups.
+func decodeLogRecord(data []byte) (slog.Record, error) {
+	var j logJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return slog.Record{}, err
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(j.Level)); err != nil {
+		return slog.Record{}, err
+	}
+	record := slog.NewRecord(j.Time, level, j.Message, 0)
+	record.AddAttrs(logAttrs(j.Attrs)...)
+	return record, nil
+}
+
+func logAttrs(values map[string]any) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if group, ok := values[key].(map[string]any); ok {
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(logAttrs(group)...)})
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, values[key]))
+	}
+	return attrs
+}
@@ -0,0 +1,312 @@
+package collector_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+type codecTestEvent struct {
+	Name  string
+	Count int
+}
+
+type customCodecTestEvent struct {
+	Name string
+}
+
+func newCodecTestEvent(t *testing.T) *collector.Event {
+	t.Helper()
+
+	start := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	groupID := uuid.Must(uuid.NewV4())
+	rowsReturned := int64(3)
+
+	record := slog.NewRecord(start.Add(time.Millisecond), slog.LevelWarn, "cache miss", 0)
+	record.AddAttrs(slog.String("key", "todos"), slog.Group("request", slog.String("method", "GET")))
+
+	poolStats := collector.DBPoolStats{Name: "primary", Timestamp: start, ThresholdExceeded: "in use > 80%"}
+	poolStats.OpenConnections = 10
+	poolStats.InUse = 8
+	poolStats.WaitDuration = 250 * time.Millisecond
+
+	event := &collector.Event{
+		ID:      uuid.Must(uuid.NewV4()),
+		GroupID: &groupID,
+		Start:   start,
+		End:     start.Add(50 * time.Millisecond),
+		Tenant:  "acme",
+		Data: collector.HTTPServerRequest{
+			ID:              uuid.Must(uuid.NewV4()),
+			Method:          http.MethodPost,
+			Path:            "/todos",
+			URL:             "/todos?draft=1",
+			RemoteAddr:      "127.0.0.1:51234",
+			RequestTime:     start,
+			ResponseTime:    start.Add(50 * time.Millisecond),
+			StatusCode:      http.StatusCreated,
+			RequestSize:     40,
+			ResponseSize:    2048,
+			RequestHeaders:  http.Header{"Content-Type": {"application/json"}},
+			ResponseHeaders: http.Header{"Content-Type": {"application/octet-stream"}},
+			RequestBody:     newCapturedBody(t, nil, `{"title":"Write tests","done":false}`, 1024),
+			// Binary content that is truncated at the limit
+			ResponseBody: newCapturedBody(t, nil, strings.Repeat("\xff\x00", 1024), 16),
+			InformationalResponses: []collector.InformationalResponse{
+				{StatusCode: http.StatusEarlyHints, Header: http.Header{"Link": {"</app.css>; rel=preload"}}},
+			},
+			Tags: map[string]string{"tenant": "acme"},
+		},
+		Children: []*collector.Event{
+			{
+				ID:       uuid.Must(uuid.NewV4()),
+				Start:    start.Add(time.Millisecond),
+				End:      start.Add(3 * time.Millisecond),
+				Severity: collector.SeverityError,
+				Data: collector.DBQuery{
+					Query:        "SELECT * FROM todos WHERE id = $1",
+					Args:         nil,
+					Duration:     2 * time.Millisecond,
+					Timestamp:    start.Add(time.Millisecond),
+					Language:     "postgresql",
+					Database:     "app",
+					Tags:         map[string]string{"replica": "primary"},
+					ConnID:       3,
+					TxID:         7,
+					RowsReturned: &rowsReturned,
+					Error:        errors.New("deadlock detected"),
+				},
+			},
+			{
+				ID:    uuid.Must(uuid.NewV4()),
+				Start: start.Add(4 * time.Millisecond),
+				End:   start.Add(20 * time.Millisecond),
+				Data: collector.HTTPClientRequest{
+					ID:             uuid.Must(uuid.NewV4()),
+					Method:         http.MethodGet,
+					URL:            "https://api.example.com/users/1",
+					RequestTime:    start.Add(4 * time.Millisecond),
+					ResponseTime:   start.Add(20 * time.Millisecond),
+					StatusCode:     http.StatusOK,
+					AttemptGroupID: uuid.Must(uuid.NewV4()),
+					Attempt:        2,
+					PreviousAttempts: []collector.HTTPClientAttempt{
+						{RequestID: uuid.Must(uuid.NewV4()), Attempt: 1, StatusCode: http.StatusServiceUnavailable, Duration: 5 * time.Millisecond},
+					},
+					DerivedFrom: uuid.Must(uuid.NewV7()),
+					Tags:        map[string]string{},
+				},
+			},
+			{
+				ID:    uuid.Must(uuid.NewV4()),
+				Start: start.Add(21 * time.Millisecond),
+				End:   start.Add(30 * time.Millisecond),
+				Data: collector.RPCCall{
+					Procedure: "/todo.v1.TodoService/GetTodo",
+					Protocol:  "connect",
+					Client:    true,
+					Timestamp: start.Add(21 * time.Millisecond),
+					Duration:  9 * time.Millisecond,
+					Request:   `{"id":"1"}`,
+					Response:  "not json",
+					Code:      "not_found",
+					Error:     errors.New("not_found: todo not found"),
+				},
+			},
+			{
+				ID:    uuid.Must(uuid.NewV4()),
+				Start: record.Time,
+				End:   record.Time,
+				Data:  record,
+			},
+			{
+				ID:      uuid.Must(uuid.NewV4()),
+				Start:   start,
+				End:     start,
+				Ambient: true,
+				Data:    poolStats,
+			},
+			{
+				ID:    uuid.Must(uuid.NewV4()),
+				Start: start.Add(31 * time.Millisecond),
+				End:   start.Add(31 * time.Millisecond),
+				Data:  collector.InternalError{Component: "storage", Panic: "broken storage", Stack: "goroutine 1 [running]:"},
+			},
+			{
+				ID:       uuid.Must(uuid.NewV4()),
+				Start:    start.Add(32 * time.Millisecond),
+				End:      start.Add(40 * time.Millisecond),
+				Detached: true,
+				Data:     collector.Task{Name: "fetch orders", Error: "context canceled"},
+			},
+		},
+	}
+	for _, child := range event.Children {
+		child.GroupID = &groupID
+	}
+	return event
+}
+
+func TestEventCodecs_RoundTrip(t *testing.T) {
+	codecs := map[string]collector.EventCodec{
+		"json": collector.JSONEventCodec,
+		"gob":  collector.GobEventCodec,
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			original := newCodecTestEvent(t)
+
+			var buf bytes.Buffer
+			enc := codec.NewEncoder(&buf)
+			require.NoError(t, enc.Encode(original))
+			require.NoError(t, enc.Encode(original.Children[0]))
+
+			dec := codec.NewDecoder(&buf)
+			decoded, err := dec.Decode()
+			require.NoError(t, err)
+			second, err := dec.Decode()
+			require.NoError(t, err)
+			_, err = dec.Decode()
+			assert.ErrorIs(t, err, io.EOF)
+
+			assert.Equal(t, original.Children[0].ID, second.ID)
+
+			assert.Equal(t, original.ID, decoded.ID)
+			assert.Equal(t, original.GroupID, decoded.GroupID)
+			assert.True(t, original.Start.Equal(decoded.Start))
+			assert.True(t, original.End.Equal(decoded.End))
+			assert.NotZero(t, decoded.Size)
+
+			// HTTP server request with bodies
+			req, ok := decoded.Data.(collector.HTTPServerRequest)
+			require.True(t, ok, "expected HTTPServerRequest, got %T", decoded.Data)
+			originalReq := original.Data.(collector.HTTPServerRequest)
+			assert.Equal(t, originalReq.Method, req.Method)
+			assert.Equal(t, originalReq.URL, req.URL)
+			assert.Equal(t, originalReq.StatusCode, req.StatusCode)
+			assert.Equal(t, originalReq.ResponseSize, req.ResponseSize)
+			assert.Equal(t, originalReq.RequestHeaders, req.RequestHeaders)
+			assert.Equal(t, originalReq.InformationalResponses, req.InformationalResponses)
+			assert.Equal(t, originalReq.Tags, req.Tags)
+			assert.Equal(t, originalReq.RequestBody.String(), req.RequestBody.String())
+			assert.True(t, req.RequestBody.IsFullyCaptured())
+			assert.False(t, req.ResponseBody.IsFullyCaptured())
+			assert.False(t, req.RequestBody.IsTruncated())
+			assert.Equal(t, originalReq.ResponseBody.Bytes(), req.ResponseBody.Bytes())
+			assert.True(t, req.ResponseBody.IsTruncated())
+
+			require.Len(t, decoded.Children, len(original.Children))
+			for _, child := range decoded.Children {
+				assert.Equal(t, original.GroupID, child.GroupID)
+			}
+
+			// DB query
+			query, ok := decoded.Children[0].Data.(collector.DBQuery)
+			require.True(t, ok, "expected DBQuery, got %T", decoded.Children[0].Data)
+			assert.Equal(t, "SELECT * FROM todos WHERE id = $1", query.Query)
+			assert.Equal(t, 2*time.Millisecond, query.Duration)
+			assert.Equal(t, "postgresql", query.Language)
+			require.NotNil(t, query.RowsReturned)
+			assert.Equal(t, int64(3), *query.RowsReturned)
+			assert.EqualError(t, query.Error, "deadlock detected")
+			assert.Equal(t, collector.SeverityError, decoded.Children[0].Severity)
+			// Events without a severity are rated when decoded
+			assert.Equal(t, collector.SeverityInfo, decoded.Severity)
+
+			// HTTP client request with previous attempts
+			clientReq, ok := decoded.Children[1].Data.(collector.HTTPClientRequest)
+			require.True(t, ok, "expected HTTPClientRequest, got %T", decoded.Children[1].Data)
+			originalClientReq := original.Children[1].Data.(collector.HTTPClientRequest)
+			assert.Equal(t, originalClientReq.URL, clientReq.URL)
+			assert.Equal(t, originalClientReq.AttemptGroupID, clientReq.AttemptGroupID)
+			assert.Equal(t, 2, clientReq.Attempt)
+			assert.Equal(t, originalClientReq.PreviousAttempts, clientReq.PreviousAttempts)
+			assert.Equal(t, originalClientReq.DerivedFrom, clientReq.DerivedFrom)
+			assert.NoError(t, clientReq.Error)
+
+			// RPC call
+			call, ok := decoded.Children[2].Data.(collector.RPCCall)
+			require.True(t, ok, "expected RPCCall, got %T", decoded.Children[2].Data)
+			assert.Equal(t, `{"id":"1"}`, call.Request)
+			assert.Equal(t, "not json", call.Response)
+			assert.True(t, call.Client)
+			assert.EqualError(t, call.Error, "not_found: todo not found")
+
+			// Log record with flattened attributes
+			record, ok := decoded.Children[3].Data.(slog.Record)
+			require.True(t, ok, "expected slog.Record, got %T", decoded.Children[3].Data)
+			assert.Equal(t, slog.LevelWarn, record.Level)
+			assert.Equal(t, "cache miss", record.Message)
+			assert.Equal(t, "WARN cache miss", collector.PayloadOf(record).Summary())
+			attrs := make(map[string]string)
+			record.Attrs(func(attr slog.Attr) bool {
+				attrs[attr.Key] = attr.Value.String()
+				return true
+			})
+			assert.Equal(t, "todos", attrs["key"])
+			assert.Equal(t, "[method=GET]", attrs["request"])
+
+			// DB pool stats
+			assert.True(t, decoded.Children[4].Ambient)
+			stats, ok := decoded.Children[4].Data.(collector.DBPoolStats)
+			require.True(t, ok, "expected DBPoolStats, got %T", decoded.Children[4].Data)
+			assert.Equal(t, original.Children[4].Data, stats)
+
+			// Internal error
+			internalErr, ok := decoded.Children[5].Data.(collector.InternalError)
+			require.True(t, ok, "expected InternalError, got %T", decoded.Children[5].Data)
+			assert.Equal(t, original.Children[5].Data, internalErr)
+		})
+	}
+}
+
+func TestEvent_UnmarshalJSON_RegisteredAndCustomKinds(t *testing.T) {
+	collector.RegisterEventKind("codec_test", collector.EventKindHandler[codecTestEvent]{
+		Summary: func(data codecTestEvent) string {
+			return data.Name
+		},
+	})
+
+	for _, data := range []any{codecTestEvent{Name: "registered", Count: 3}, customCodecTestEvent{Name: "custom"}} {
+		original := &collector.Event{ID: uuid.Must(uuid.NewV4()), Data: data}
+		encoded, err := json.Marshal(original)
+		require.NoError(t, err)
+
+		var decoded collector.Event
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, original.ID, decoded.ID)
+
+		switch data.(type) {
+		case codecTestEvent:
+			// Registered kinds are restored with their type
+			assert.Equal(t, data, decoded.Data)
+		default:
+			// Custom kinds are restored as generic JSON values
+			assert.Equal(t, map[string]any{"Name": "custom"}, decoded.Data)
+		}
+	}
+}
+
+func TestEvent_UnmarshalJSON_UnsupportedSchemaVersion(t *testing.T) {
+	for _, version := range []int{0, collector.EventSchemaVersion + 1} {
+		data := fmt.Sprintf(`{"schemaVersion":%d,"id":"0195946e-7a3c-7c2a-9b1f-2f2f3e4d5c6b","kind":"custom","data":{}}`, version)
+
+		var event collector.Event
+		err := json.Unmarshal([]byte(data), &event)
+		assert.ErrorIs(t, err, collector.ErrUnsupportedSchemaVersion)
+	}
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// EventSchemaVersion is the version of the JSON representation of events (see Event.MarshalJSON).
+// It is incremented on incompatible changes, so consumers of exported events can detect them.
+const EventSchemaVersion = 1
+
+// EventKind identifies the kind of data of an event
+type EventKind string
+
+const (
+	EventKindHTTPServerRequest EventKind = "http_server_request"
+	EventKindHTTPClientRequest EventKind = "http_client_request"
+	EventKindDBQuery           EventKind = "db_query"
+	EventKindDBPoolStats       EventKind = "db_pool_stats"
+	EventKindRPCCall           EventKind = "rpc_call"
+	EventKindCacheOp           EventKind = "cache_op"
+	EventKindFlagEvaluation    EventKind = "flag_evaluation"
+	EventKindMail              EventKind = "mail"
+	EventKindLog               EventKind = "log"
+	// EventKindCustom is the kind of event data with a type that is not registered (see RegisterEventKind)
+	EventKindCustom EventKind = "custom"
+)
+
+// EventPayload is implemented by event data with first-class support for summaries, search and serialization
+type EventPayload interface {
+	json.Marshaler
+	// Kind returns the kind of the event data
+	Kind() EventKind
+	// Summary returns a short single line description of the event
+	Summary() string
+	// SearchText returns the text that is matched when searching events
+	SearchText() string
+}
+
+// EventKindHandler provides the methods of EventPayload for data of type T that doesn't implement it (e.g. custom events)
+type EventKindHandler[T any] struct {
+	// Summary returns a short single line description of the event
+	Summary func(data T) string
+	// SearchText returns the text that is matched when searching events (optional, defaults to the summary)
+	SearchText func(data T) string
+	// MarshalJSON serializes the data (optional, defaults to json.Marshal)
+	MarshalJSON func(data T) ([]byte, error)
+	// UnmarshalJSON deserializes data serialized with MarshalJSON (optional, defaults to json.Unmarshal)
+	UnmarshalJSON func(data []byte) (T, error)
+}
+
+// eventKindHandler is the type erased version of EventKindHandler
+type eventKindHandler struct {
+	kind          EventKind
+	summary       func(data any) string
+	searchText    func(data any) string
+	marshalJSON   func(data any) ([]byte, error)
+	unmarshalJSON func(data []byte) (any, error)
+}
+
+var (
+	eventKindsMu sync.RWMutex
+	eventKinds   = make(map[reflect.Type]eventKindHandler)
+	// eventKindDecoders deserialize payloads of registered kinds (see Event.UnmarshalJSON)
+	eventKindDecoders = make(map[EventKind]func(data []byte) (any, error))
+)
+
+// RegisterEventKind registers a kind for event data of type T, so it has a summary, can be searched and serialized
+// like the built-in kinds. Types implementing EventPayload don't need to be registered.
+func RegisterEventKind[T any](kind EventKind, handler EventKindHandler[T]) {
+	h := eventKindHandler{kind: kind}
+	if handler.Summary != nil {
+		h.summary = func(data any) string { return handler.Summary(data.(T)) }
+	}
+	if handler.SearchText != nil {
+		h.searchText = func(data any) string { return handler.SearchText(data.(T)) }
+	}
+	if handler.MarshalJSON != nil {
+		h.marshalJSON = func(data any) ([]byte, error) { return handler.MarshalJSON(data.(T)) }
+	}
+	h.unmarshalJSON = func(data []byte) (any, error) {
+		if handler.UnmarshalJSON != nil {
+			return handler.UnmarshalJSON(data)
+		}
+		var v T
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}
+
+	eventKindsMu.Lock()
+	defer eventKindsMu.Unlock()
+
+	eventKinds[reflect.TypeFor[T]()] = h
+	eventKindDecoders[kind] = h.unmarshalJSON
+}
+
+// PayloadOf returns the payload for event data.
+// Data of types that neither implement EventPayload nor are registered is of kind EventKindCustom.
+func PayloadOf(data any) EventPayload {
+	switch data := data.(type) {
+	case EventPayload:
+		return data
+	case slog.Record:
+		return logPayload{data}
+	}
+
+	eventKindsMu.RLock()
+	handler, ok := eventKinds[reflect.TypeOf(data)]
+	eventKindsMu.RUnlock()
+
+	if !ok {
+		handler = eventKindHandler{kind: EventKindCustom}
+	}
+	return registeredPayload{data: data, handler: handler}
+}
+
+// registeredPayload implements EventPayload for data of a registered or custom kind
+type registeredPayload struct {
+	data    any
+	handler eventKindHandler
+}
+
+func (p registeredPayload) Kind() EventKind {
+	return p.handler.kind
+}
+
+func (p registeredPayload) Summary() string {
+	if p.handler.summary != nil {
+		return p.handler.summary(p.data)
+	}
+	return typeName(p.data)
+}
+
+func (p registeredPayload) SearchText() string {
+	if p.handler.searchText != nil {
+		return p.handler.searchText(p.data)
+	}
+	if p.handler.summary != nil {
+		return p.handler.summary(p.data)
+	}
+	return fmt.Sprintf("%s %+v", typeName(p.data), p.data)
+}
+
+func (p registeredPayload) MarshalJSON() ([]byte, error) {
+	if p.handler.marshalJSON != nil {
+		return p.handler.marshalJSON(p.data)
+	}
+	data, err := json.Marshal(p.data)
+	if err != nil {
+		// Not every value can be serialized (e.g. functions or channels), fall back to the formatted value
+		return json.Marshal(fmt.Sprintf("%+v", p.data))
+	}
+	return data, nil
+}
+
+// typeName returns the name of the type of data without package and pointers
+func typeName(data any) string {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return "nil"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// Kind returns the kind of the event data
+func (e *Event) Kind() EventKind {
+	return PayloadOf(e.Data).Kind()
+}
+
+// Payload returns the event data as EventPayload
+func (e *Event) Payload() EventPayload {
+	return PayloadOf(e.Data)
+}
@@ -0,0 +1,216 @@
+package collector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestPayloadOf_BuiltinKinds(t *testing.T) {
+	record := slog.NewRecord(time.Now(), slog.LevelWarn, "cache miss", 0)
+	record.AddAttrs(slog.String("key", "todos"))
+
+	tests := []struct {
+		name            string
+		data            any
+		expectedKind    collector.EventKind
+		expectedSummary string
+		searchContains  string
+	}{
+		{
+			name: "http server request",
+			data: collector.HTTPServerRequest{
+				Method:         http.MethodGet,
+				Path:           "/todos/1",
+				URL:            "/todos/1?include=tags",
+				StatusCode:     http.StatusNotFound,
+				RequestHeaders: http.Header{"Accept": {"application/json"}},
+			},
+			expectedKind:    collector.EventKindHTTPServerRequest,
+			expectedSummary: "GET /todos/1 404",
+			searchContains:  "Accept: application/json",
+		},
+		{
+			name: "http client request",
+			data: collector.HTTPClientRequest{
+				Method: http.MethodPost,
+				URL:    "https://example.com/hooks",
+				Error:  errors.New("connection refused"),
+			},
+			expectedKind:    collector.EventKindHTTPClientRequest,
+			expectedSummary: "POST https://example.com/hooks: connection refused",
+			searchContains:  "connection refused",
+		},
+		{
+			name: "db query",
+			data: collector.DBQuery{
+				Query: "SELECT *\n  FROM todos\n  WHERE id = ?",
+			},
+			expectedKind:    collector.EventKindDBQuery,
+			expectedSummary: "SELECT * FROM todos WHERE id = ?",
+			searchContains:  "FROM todos",
+		},
+		{
+			name: "rpc call",
+			data: collector.RPCCall{
+				Procedure: "/todo.v1.TodoService/GetTodo",
+				Code:      "not_found",
+			},
+			expectedKind:    collector.EventKindRPCCall,
+			expectedSummary: "/todo.v1.TodoService/GetTodo not_found",
+			searchContains:  "not_found",
+		},
+		{
+			name: "cache op",
+			data: collector.CacheOp{
+				Cache:     "redis",
+				Operation: collector.CacheOperationGet,
+				Key:       "user:42",
+			},
+			expectedKind:    collector.EventKindCacheOp,
+			expectedSummary: "GET user:42 miss",
+			searchContains:  "redis",
+		},
+		{
+			name: "flag evaluation",
+			data: collector.FlagEvaluation{
+				Flag:     "new-checkout",
+				Value:    "true",
+				Variant:  "on",
+				Reason:   "TARGETING_MATCH",
+				Provider: "flagd",
+			},
+			expectedKind:    collector.EventKindFlagEvaluation,
+			expectedSummary: "new-checkout = on (TARGETING_MATCH)",
+			searchContains:  "flagd",
+		},
+		{
+			name: "mail",
+			data: collector.Mail{
+				From:    "shop@example.com",
+				To:      []string{"jane@example.com"},
+				Subject: "Your order",
+			},
+			expectedKind:    collector.EventKindMail,
+			expectedSummary: "Your order to jane@example.com",
+			searchContains:  "shop@example.com",
+		},
+		{
+			name:            "log",
+			data:            record,
+			expectedKind:    collector.EventKindLog,
+			expectedSummary: "WARN cache miss",
+			searchContains:  "key=todos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := collector.PayloadOf(tt.data)
+			assert.Equal(t, tt.expectedKind, payload.Kind())
+			assert.Equal(t, tt.expectedSummary, payload.Summary())
+			assert.Contains(t, payload.SearchText(), tt.searchContains)
+
+			data, err := payload.MarshalJSON()
+			require.NoError(t, err)
+			assert.True(t, json.Valid(data))
+		})
+	}
+}
+
+type cacheEvent struct {
+	Key string
+	Hit bool
+}
+
+type unregisteredEvent struct {
+	Name string
+	Fn   func()
+}
+
+func TestPayloadOf_RegisteredAndCustomKinds(t *testing.T) {
+	collector.RegisterEventKind("cache", collector.EventKindHandler[cacheEvent]{
+		Summary: func(data cacheEvent) string {
+			return "cache " + data.Key
+		},
+	})
+
+	payload := collector.PayloadOf(cacheEvent{Key: "todos", Hit: true})
+	assert.Equal(t, collector.EventKind("cache"), payload.Kind())
+	assert.Equal(t, "cache todos", payload.Summary())
+	// The search text defaults to the summary
+	assert.Equal(t, "cache todos", payload.SearchText())
+	data, err := payload.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Key":"todos","Hit":true}`, string(data))
+
+	// Unregistered types are custom events that can be serialized even if they contain functions
+	payload = collector.PayloadOf(unregisteredEvent{Name: "test", Fn: func() {}})
+	assert.Equal(t, collector.EventKindCustom, payload.Kind())
+	assert.Equal(t, "unregisteredEvent", payload.Summary())
+	assert.Contains(t, payload.SearchText(), "test")
+	data, err = payload.MarshalJSON()
+	require.NoError(t, err)
+	assert.True(t, json.Valid(data))
+}
+
+func TestEvent_MarshalJSON(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 10, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	ctx := aggregator.StartEvent(context.Background())
+	aggregator.CollectEvent(ctx, collector.DBQuery{Query: "SELECT 1", Duration: time.Millisecond})
+	aggregator.EndEvent(ctx, collector.HTTPServerRequest{
+		Method:       http.MethodGet,
+		Path:         "/",
+		StatusCode:   http.StatusOK,
+		ResponseBody: collector.NewBody(nil, 1024),
+	})
+
+	events := storage.GetEvents(1)
+	require.Len(t, events, 1)
+
+	data, err := json.Marshal(events[0])
+	require.NoError(t, err)
+
+	var decoded struct {
+		SchemaVersion int                 `json:"schemaVersion"`
+		ID            uuid.UUID           `json:"id"`
+		Kind          collector.EventKind `json:"kind"`
+		Data          struct {
+			Method     string `json:"method"`
+			StatusCode int    `json:"statusCode"`
+		} `json:"data"`
+		Children []struct {
+			Kind collector.EventKind `json:"kind"`
+			Data struct {
+				Query      string `json:"query"`
+				DurationNs int64  `json:"durationNs"`
+			} `json:"data"`
+		} `json:"children"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, collector.EventSchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, events[0].ID, decoded.ID)
+	assert.Equal(t, collector.EventKindHTTPServerRequest, decoded.Kind)
+	assert.Equal(t, http.MethodGet, decoded.Data.Method)
+	assert.Equal(t, http.StatusOK, decoded.Data.StatusCode)
+	require.Len(t, decoded.Children, 1)
+	assert.Equal(t, collector.EventKindDBQuery, decoded.Children[0].Kind)
+	assert.Equal(t, "SELECT 1", decoded.Children[0].Data.Query)
+	assert.Equal(t, int64(time.Millisecond), decoded.Children[0].Data.DurationNs)
+}
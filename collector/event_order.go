@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventOrder orders the events returned by CaptureStorage.QueryEvents
+type EventOrder string
+
+const (
+	// EventOrderNewest orders events by their sequence, newest first
+	EventOrderNewest EventOrder = "newest"
+	// EventOrderDuration orders events by their duration, slowest first
+	EventOrderDuration EventOrder = "duration"
+	// EventOrderSize orders events by the memory size of the event and its children (including captured bodies),
+	// largest first
+	EventOrderSize EventOrder = "size"
+	// EventOrderSeverity orders events by the highest severity of the event and its children, most severe first
+	EventOrderSeverity EventOrder = "severity"
+)
+
+// ParseEventOrder parses "newest", "duration", "size" or "severity", an empty string is EventOrderNewest
+func ParseEventOrder(s string) (EventOrder, error) {
+	switch order := EventOrder(s); order {
+	case "":
+		return EventOrderNewest, nil
+	case EventOrderNewest, EventOrderDuration, EventOrderSize, EventOrderSeverity:
+		return order, nil
+	}
+	return "", fmt.Errorf("invalid event order %q", s)
+}
+
+// sort sorts events given newest first by the order, events that are equal in the order keep their order
+func (o EventOrder) sort(events []*Event) {
+	var key func(event *Event) int64
+	switch o {
+	case EventOrderDuration:
+		key = func(event *Event) int64 {
+			return int64(eventDuration(event))
+		}
+	case EventOrderSize:
+		key = func(event *Event) int64 {
+			return int64(event.totalSize())
+		}
+	case EventOrderSeverity:
+		key = func(event *Event) int64 {
+			return int64(event.MaxSeverity().rank())
+		}
+	default:
+		return
+	}
+
+	// Keys are calculated once, since the size and severity visit all children of an event
+	keys := make(map[*Event]int64, len(events))
+	for _, event := range events {
+		keys[event] = key(event)
+	}
+	slices.SortStableFunc(events, func(a, b *Event) int {
+		return cmp.Compare(keys[b], keys[a])
+	})
+}
+
+// eventDuration returns the duration of an event, zero for an event in progress without an end
+func eventDuration(event *Event) time.Duration {
+	if event.End.Before(event.Start) {
+		return 0
+	}
+	return event.End.Sub(event.Start)
+}
@@ -0,0 +1,707 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+)
+
+// Ensure the built-in event data types implement EventPayload
+var (
+	_ EventPayload = HTTPServerRequest{}
+	_ EventPayload = HTTPClientRequest{}
+	_ EventPayload = DBQuery{}
+	_ EventPayload = DBPoolStats{}
+	_ EventPayload = RPCCall{}
+	_ EventPayload = CacheOp{}
+	_ EventPayload = FlagEvaluation{}
+	_ EventPayload = Mail{}
+	_ EventPayload = logPayload{}
+)
+
+// bodyJSON is the JSON representation of a captured body, binary content is base64 encoded
+type bodyJSON struct {
+	Content       string `json:"content,omitempty"`
+	ContentBase64 []byte `json:"contentBase64,omitempty"`
+	Size          uint64 `json:"size"`
+	Truncated     bool   `json:"truncated,omitempty"`
+	FullyCaptured bool   `json:"fullyCaptured,omitempty"`
+	Evicted       bool   `json:"evicted,omitempty"`
+}
+
+func newBodyJSON(body *Body) *bodyJSON {
+	if body == nil {
+		return nil
+	}
+	b := &bodyJSON{
+		Size:          body.Size(),
+		Truncated:     body.IsTruncated(),
+		FullyCaptured: body.IsFullyCaptured(),
+		Evicted:       body.IsEvicted(),
+	}
+	content := body.Bytes()
+	if utf8.Valid(content) {
+		b.Content = string(content)
+	} else {
+		b.ContentBase64 = content
+	}
+	return b
+}
+
+// errorString returns the message of an error or an empty string for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// searchText joins the non-empty parts of a search text with newlines
+func searchText(parts ...string) string {
+	return strings.Join(slices.DeleteFunc(parts, func(part string) bool { return part == "" }), "\n")
+}
+
+// bodySearchText returns the captured content of a body or an empty string if the body was not captured
+func bodySearchText(body *Body) string {
+	if body == nil {
+		return ""
+	}
+	return body.String()
+}
+
+// headersSearchText returns the headers as "Key: value" lines in a stable order
+func headersSearchText(h http.Header) string {
+	var lines []string
+	for _, key := range slices.Sorted(maps.Keys(h)) {
+		lines = append(lines, key+": "+strings.Join(h[key], ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tagsSearchText returns the tags as "key:value" lines in a stable order
+func tagsSearchText(tags map[string]string) string {
+	var lines []string
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		lines = append(lines, key+":"+tags[key])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Kind implements EventPayload
+func (r HTTPServerRequest) Kind() EventKind {
+	return EventKindHTTPServerRequest
+}
+
+// Summary implements EventPayload
+func (r HTTPServerRequest) Summary() string {
+	return fmt.Sprintf("%s %s %d", r.Method, r.Path, r.StatusCode)
+}
+
+// SearchText implements EventPayload
+func (r HTTPServerRequest) SearchText() string {
+	return searchText(
+		r.Method+" "+r.URL,
+		r.RoutePattern,
+		strconv.Itoa(r.StatusCode),
+		headersSearchText(r.RequestHeaders),
+		bodySearchText(r.RequestBody),
+		headersSearchText(r.ResponseHeaders),
+		bodySearchText(r.ResponseBody),
+		tagsSearchText(r.Tags),
+		errorString(r.Error),
+		r.Panic,
+	)
+}
+
+type httpServerRequestJSON struct {
+	ID                     uuid.UUID               `json:"id"`
+	Method                 string                  `json:"method"`
+	Path                   string                  `json:"path"`
+	RoutePattern           string                  `json:"routePattern,omitempty"`
+	URL                    string                  `json:"url"`
+	RemoteAddr             string                  `json:"remoteAddr"`
+	RequestTime            time.Time               `json:"requestTime"`
+	ResponseTime           time.Time               `json:"responseTime"`
+	StatusCode             int                     `json:"statusCode"`
+	RequestSize            uint64                  `json:"requestSize"`
+	ResponseSize           uint64                  `json:"responseSize"`
+	RequestHeaders         http.Header             `json:"requestHeaders"`
+	ResponseHeaders        http.Header             `json:"responseHeaders"`
+	RequestBody            *bodyJSON               `json:"requestBody,omitempty"`
+	ResponseBody           *bodyJSON               `json:"responseBody,omitempty"`
+	ResponseTrailers       http.Header             `json:"responseTrailers,omitempty"`
+	InformationalResponses []InformationalResponse `json:"informationalResponses,omitempty"`
+	Streaming              bool                    `json:"streaming,omitempty"`
+	ClientDisconnected     bool                    `json:"clientDisconnected,omitempty"`
+	DisconnectTime         *time.Time              `json:"disconnectTime,omitempty"`
+	Tags                   map[string]string       `json:"tags,omitempty"`
+	Error                  string                  `json:"error,omitempty"`
+	Panic                  string                  `json:"panic,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (r HTTPServerRequest) MarshalJSON() ([]byte, error) {
+	var disconnectTime *time.Time
+	if r.ClientDisconnected {
+		disconnectTime = &r.DisconnectTime
+	}
+	return json.Marshal(httpServerRequestJSON{
+		ID:                     r.ID,
+		Method:                 r.Method,
+		Path:                   r.Path,
+		RoutePattern:           r.RoutePattern,
+		URL:                    r.URL,
+		RemoteAddr:             r.RemoteAddr,
+		RequestTime:            r.RequestTime,
+		ResponseTime:           r.ResponseTime,
+		StatusCode:             r.StatusCode,
+		RequestSize:            r.RequestSize,
+		ResponseSize:           r.ResponseSize,
+		RequestHeaders:         r.RequestHeaders,
+		ResponseHeaders:        r.ResponseHeaders,
+		RequestBody:            newBodyJSON(r.RequestBody),
+		ResponseBody:           newBodyJSON(r.ResponseBody),
+		ResponseTrailers:       r.ResponseTrailers,
+		InformationalResponses: r.InformationalResponses,
+		Streaming:              r.Streaming,
+		ClientDisconnected:     r.ClientDisconnected,
+		DisconnectTime:         disconnectTime,
+		Tags:                   r.Tags,
+		Error:                  errorString(r.Error),
+		Panic:                  r.Panic,
+	})
+}
+
+// Kind implements EventPayload
+func (r HTTPClientRequest) Kind() EventKind {
+	return EventKindHTTPClientRequest
+}
+
+// Summary implements EventPayload
+func (r HTTPClientRequest) Summary() string {
+	if r.Error != nil {
+		return fmt.Sprintf("%s %s: %v", r.Method, r.URL, r.Error)
+	}
+	return fmt.Sprintf("%s %s %d", r.Method, r.URL, r.StatusCode)
+}
+
+// SearchText implements EventPayload
+func (r HTTPClientRequest) SearchText() string {
+	return searchText(
+		r.Method+" "+r.URL,
+		strconv.Itoa(r.StatusCode),
+		headersSearchText(r.RequestHeaders),
+		bodySearchText(r.RequestBody),
+		headersSearchText(r.ResponseHeaders),
+		bodySearchText(r.ResponseBody),
+		tagsSearchText(r.Tags),
+		errorString(r.Error),
+	)
+}
+
+type httpClientAttemptJSON struct {
+	RequestID  uuid.UUID `json:"requestId"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	DurationNs int64     `json:"durationNs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type httpClientRequestJSON struct {
+	ID               uuid.UUID               `json:"id"`
+	Method           string                  `json:"method"`
+	URL              string                  `json:"url"`
+	RequestTime      time.Time               `json:"requestTime"`
+	ResponseTime     time.Time               `json:"responseTime"`
+	StatusCode       int                     `json:"statusCode"`
+	RequestSize      uint64                  `json:"requestSize"`
+	ResponseSize     uint64                  `json:"responseSize"`
+	RequestHeaders   http.Header             `json:"requestHeaders"`
+	ResponseHeaders  http.Header             `json:"responseHeaders"`
+	RequestBody      *bodyJSON               `json:"requestBody,omitempty"`
+	ResponseBody     *bodyJSON               `json:"responseBody,omitempty"`
+	AttemptGroupID   *uuid.UUID              `json:"attemptGroupId,omitempty"`
+	Attempt          int                     `json:"attempt,omitempty"`
+	PreviousAttempts []httpClientAttemptJSON `json:"previousAttempts,omitempty"`
+	DerivedFrom      *uuid.UUID              `json:"derivedFrom,omitempty"`
+	Tags             map[string]string       `json:"tags,omitempty"`
+	Error            string                  `json:"error,omitempty"`
+	ErrorInfo        *HTTPClientErrorInfo    `json:"errorInfo,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (r HTTPClientRequest) MarshalJSON() ([]byte, error) {
+	var attemptGroupID *uuid.UUID
+	if !r.AttemptGroupID.IsNil() {
+		attemptGroupID = &r.AttemptGroupID
+	}
+	var derivedFrom *uuid.UUID
+	if !r.DerivedFrom.IsNil() {
+		derivedFrom = &r.DerivedFrom
+	}
+	var previousAttempts []httpClientAttemptJSON
+	for _, attempt := range r.PreviousAttempts {
+		previousAttempts = append(previousAttempts, httpClientAttemptJSON{
+			RequestID:  attempt.RequestID,
+			Attempt:    attempt.Attempt,
+			StatusCode: attempt.StatusCode,
+			DurationNs: int64(attempt.Duration),
+			Error:      errorString(attempt.Error),
+		})
+	}
+	return json.Marshal(httpClientRequestJSON{
+		ID:               r.ID,
+		Method:           r.Method,
+		URL:              r.URL,
+		RequestTime:      r.RequestTime,
+		ResponseTime:     r.ResponseTime,
+		StatusCode:       r.StatusCode,
+		RequestSize:      r.RequestSize,
+		ResponseSize:     r.ResponseSize,
+		RequestHeaders:   r.RequestHeaders,
+		ResponseHeaders:  r.ResponseHeaders,
+		RequestBody:      newBodyJSON(r.RequestBody),
+		ResponseBody:     newBodyJSON(r.ResponseBody),
+		AttemptGroupID:   attemptGroupID,
+		Attempt:          r.Attempt,
+		PreviousAttempts: previousAttempts,
+		DerivedFrom:      derivedFrom,
+		Tags:             r.Tags,
+		Error:            errorString(r.Error),
+		ErrorInfo:        r.ErrorInfo,
+	})
+}
+
+// Kind implements EventPayload
+func (q DBQuery) Kind() EventKind {
+	return EventKindDBQuery
+}
+
+// Summary implements EventPayload, it is the query on a single line
+func (q DBQuery) Summary() string {
+	return strings.Join(strings.Fields(q.Query), " ")
+}
+
+// SearchText implements EventPayload
+func (q DBQuery) SearchText() string {
+	args := make([]string, 0, len(q.Args))
+	for _, arg := range q.Args {
+		args = append(args, fmt.Sprint(arg.Value))
+	}
+	return searchText(q.Query, strings.Join(args, "\n"), errorString(q.Error), q.Database, tagsSearchText(q.Tags))
+}
+
+type dbQueryArgJSON struct {
+	Name    string `json:"name,omitempty"`
+	Ordinal int    `json:"ordinal"`
+	Value   any    `json:"value"`
+}
+
+type dbQueryJSON struct {
+	Query           string            `json:"query"`
+	Args            []dbQueryArgJSON  `json:"args,omitempty"`
+	DurationNs      int64             `json:"durationNs"`
+	Timestamp       time.Time         `json:"timestamp"`
+	Language        string            `json:"language,omitempty"`
+	Database        string            `json:"database,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+	ConnID          int64             `json:"connId,omitempty"`
+	TxID            int64             `json:"txId,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	RowsReturned    *int64            `json:"rowsReturned,omitempty"`
+	RowsAffected    *int64            `json:"rowsAffected,omitempty"`
+	Prepared        bool              `json:"prepared,omitempty"`
+	StatementReused bool              `json:"statementReused,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (q DBQuery) MarshalJSON() ([]byte, error) {
+	var args []dbQueryArgJSON
+	for _, arg := range q.Args {
+		value := arg.Value
+		// Only driver values of simple types are serialized as they are, byte slices would be base64 encoded
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		args = append(args, dbQueryArgJSON{Name: arg.Name, Ordinal: arg.Ordinal, Value: value})
+	}
+	return json.Marshal(dbQueryJSON{
+		Query:           q.Query,
+		Args:            args,
+		DurationNs:      int64(q.Duration),
+		Timestamp:       q.Timestamp,
+		Language:        q.Language,
+		Database:        q.Database,
+		Tags:            q.Tags,
+		ConnID:          q.ConnID,
+		TxID:            q.TxID,
+		Error:           errorString(q.Error),
+		RowsReturned:    q.RowsReturned,
+		RowsAffected:    q.RowsAffected,
+		Prepared:        q.Prepared,
+		StatementReused: q.StatementReused,
+	})
+}
+
+// Kind implements EventPayload
+func (s DBPoolStats) Kind() EventKind {
+	return EventKindDBPoolStats
+}
+
+// Summary implements EventPayload
+func (s DBPoolStats) Summary() string {
+	summary := fmt.Sprintf("%s: %d open, %d in use, %d idle", s.Name, s.OpenConnections, s.InUse, s.Idle)
+	if status := s.Status(); status != "" {
+		summary += " (" + status + ")"
+	}
+	return summary
+}
+
+// SearchText implements EventPayload
+func (s DBPoolStats) SearchText() string {
+	return s.Summary()
+}
+
+type dbPoolStatsJSON struct {
+	Name               string    `json:"name"`
+	Timestamp          time.Time `json:"timestamp"`
+	MaxOpenConnections int       `json:"maxOpenConnections"`
+	OpenConnections    int       `json:"openConnections"`
+	InUse              int       `json:"inUse"`
+	Idle               int       `json:"idle"`
+	WaitCount          int64     `json:"waitCount"`
+	WaitDurationNs     int64     `json:"waitDurationNs"`
+	MaxIdleClosed      int64     `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64     `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64     `json:"maxLifetimeClosed"`
+	ThresholdExceeded  string    `json:"thresholdExceeded,omitempty"`
+	Recovered          bool      `json:"recovered,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (s DBPoolStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dbPoolStatsJSON{
+		Name:               s.Name,
+		Timestamp:          s.Timestamp,
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationNs:     int64(s.WaitDuration),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		ThresholdExceeded:  s.ThresholdExceeded,
+		Recovered:          s.Recovered,
+	})
+}
+
+// Kind implements EventPayload
+func (c RPCCall) Kind() EventKind {
+	return EventKindRPCCall
+}
+
+// Summary implements EventPayload
+func (c RPCCall) Summary() string {
+	if c.Code != "" {
+		return c.Procedure + " " + c.Code
+	}
+	return c.Procedure
+}
+
+// SearchText implements EventPayload
+func (c RPCCall) SearchText() string {
+	return searchText(c.Procedure, c.Protocol, c.Peer, c.Request, c.Response, c.Code, errorString(c.Error))
+}
+
+type rpcCallJSON struct {
+	Procedure  string          `json:"procedure"`
+	Protocol   string          `json:"protocol,omitempty"`
+	Client     bool            `json:"client"`
+	Stream     bool            `json:"stream,omitempty"`
+	Peer       string          `json:"peer,omitempty"`
+	Timestamp  time.Time       `json:"timestamp"`
+	DurationNs int64           `json:"durationNs"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Code       string          `json:"code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (c RPCCall) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rpcCallJSON{
+		Procedure:  c.Procedure,
+		Protocol:   c.Protocol,
+		Client:     c.Client,
+		Stream:     c.Stream,
+		Peer:       c.Peer,
+		Timestamp:  c.Timestamp,
+		DurationNs: int64(c.Duration),
+		Request:    rawJSONMessage(c.Request),
+		Response:   rawJSONMessage(c.Response),
+		Code:       c.Code,
+		Error:      errorString(c.Error),
+	})
+}
+
+// Kind implements EventPayload
+func (o CacheOp) Kind() EventKind {
+	return EventKindCacheOp
+}
+
+// Summary implements EventPayload, e.g. "GET user:42 hit"
+func (o CacheOp) Summary() string {
+	summary := strings.ToUpper(string(o.Operation)) + " " + o.Key
+	switch {
+	case o.Error != nil:
+		summary += ": " + o.Error.Error()
+	case o.Operation == CacheOperationGet && o.Hit:
+		summary += " hit"
+	case o.Operation == CacheOperationGet:
+		summary += " miss"
+	}
+	return summary
+}
+
+// SearchText implements EventPayload
+func (o CacheOp) SearchText() string {
+	return searchText(o.Summary(), o.Cache)
+}
+
+type cacheOpJSON struct {
+	Cache      string    `json:"cache,omitempty"`
+	Operation  string    `json:"operation"`
+	Key        string    `json:"key"`
+	Hit        bool      `json:"hit,omitempty"`
+	ValueSize  int       `json:"valueSize,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	DurationNs int64     `json:"durationNs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (o CacheOp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cacheOpJSON{
+		Cache:      o.Cache,
+		Operation:  string(o.Operation),
+		Key:        o.Key,
+		Hit:        o.Hit,
+		ValueSize:  o.ValueSize,
+		Timestamp:  o.Timestamp,
+		DurationNs: int64(o.Duration),
+		Error:      errorString(o.Error),
+	})
+}
+
+// Kind implements EventPayload
+func (f FlagEvaluation) Kind() EventKind {
+	return EventKindFlagEvaluation
+}
+
+// Summary implements EventPayload, e.g. "new-checkout = on (TARGETING_MATCH)"
+func (f FlagEvaluation) Summary() string {
+	result := f.Variant
+	if result == "" {
+		result = f.Value
+	}
+	summary := f.Flag + " = " + result
+	if f.Reason != "" {
+		summary += " (" + f.Reason + ")"
+	}
+	if f.Error != nil {
+		summary += ": " + f.Error.Error()
+	}
+	return summary
+}
+
+// SearchText implements EventPayload
+func (f FlagEvaluation) SearchText() string {
+	return searchText(f.Summary(), f.Value, f.Provider)
+}
+
+type flagEvaluationJSON struct {
+	Flag      string    `json:"flag"`
+	Value     string    `json:"value"`
+	Variant   string    `json:"variant,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (f FlagEvaluation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(flagEvaluationJSON{
+		Flag:      f.Flag,
+		Value:     f.Value,
+		Variant:   f.Variant,
+		Reason:    f.Reason,
+		Provider:  f.Provider,
+		Timestamp: f.Timestamp,
+		Error:     errorString(f.Error),
+	})
+}
+
+// Kind implements EventPayload
+func (m Mail) Kind() EventKind {
+	return EventKindMail
+}
+
+// Summary implements EventPayload, e.g. "Welcome to jane@example.com"
+func (m Mail) Summary() string {
+	summary := m.Subject + " to " + strings.Join(m.Recipients(), ", ")
+	if m.Error != nil {
+		summary += ": " + m.Error.Error()
+	}
+	return summary
+}
+
+// SearchText implements EventPayload
+func (m Mail) SearchText() string {
+	return searchText(m.Summary(), m.From, m.Server)
+}
+
+type mailJSON struct {
+	From          string               `json:"from"`
+	To            []string             `json:"to,omitempty"`
+	Cc            []string             `json:"cc,omitempty"`
+	Bcc           []string             `json:"bcc,omitempty"`
+	Subject       string               `json:"subject"`
+	TextBody      string               `json:"textBody,omitempty"`
+	HTMLBody      string               `json:"htmlBody,omitempty"`
+	BodyTruncated bool                 `json:"bodyTruncated,omitempty"`
+	Attachments   []mailAttachmentJSON `json:"attachments,omitempty"`
+	Server        string               `json:"server,omitempty"`
+	Timestamp     time.Time            `json:"timestamp"`
+	DurationNs    int64                `json:"durationNs"`
+	Error         string               `json:"error,omitempty"`
+}
+
+type mailAttachmentJSON struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	Inline      bool   `json:"inline,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (m Mail) MarshalJSON() ([]byte, error) {
+	var attachments []mailAttachmentJSON
+	for _, attachment := range m.Attachments {
+		attachments = append(attachments, mailAttachmentJSON(attachment))
+	}
+	return json.Marshal(mailJSON{
+		From:          m.From,
+		To:            m.To,
+		Cc:            m.Cc,
+		Bcc:           m.Bcc,
+		Subject:       m.Subject,
+		TextBody:      m.TextBody,
+		HTMLBody:      m.HTMLBody,
+		BodyTruncated: m.BodyTruncated,
+		Attachments:   attachments,
+		Server:        m.Server,
+		Timestamp:     m.Timestamp,
+		DurationNs:    int64(m.Duration),
+		Error:         errorString(m.Error),
+	})
+}
+
+// rawJSONMessage embeds a message rendered as JSON as it is, other content is embedded as a string
+func rawJSONMessage(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
+
+// logPayload implements EventPayload for log records, which are collected as slog.Record
+type logPayload struct {
+	slog.Record
+}
+
+// Kind implements EventPayload
+func (p logPayload) Kind() EventKind {
+	return EventKindLog
+}
+
+// Summary implements EventPayload
+func (p logPayload) Summary() string {
+	return p.Level.String() + " " + p.Message
+}
+
+// SearchText implements EventPayload
+func (p logPayload) SearchText() string {
+	parts := []string{p.Message}
+	p.Attrs(func(attr slog.Attr) bool {
+		parts = append(parts, attr.String())
+		return true
+	})
+	return searchText(parts...)
+}
+
+type logJSON struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// MarshalJSON implements EventPayload
+func (p logPayload) MarshalJSON() ([]byte, error) {
+	var attrs map[string]any
+	p.Attrs(func(attr slog.Attr) bool {
+		if attrs == nil {
+			attrs = make(map[string]any)
+		}
+		attrs[attr.Key] = logAttrValue(attr.Value)
+		return true
+	})
+	return json.Marshal(logJSON{
+		Time:    p.Time,
+		Level:   p.Level.String(),
+		Message: p.Message,
+		Attrs:   attrs,
+	})
+}
+
+// logAttrValue converts a log attribute value to a value that can be serialized as JSON, groups are nested objects
+func logAttrValue(value slog.Value) any {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]any)
+		for _, attr := range value.Group() {
+			group[attr.Key] = logAttrValue(attr.Value)
+		}
+		return group
+	case slog.KindDuration:
+		return int64(value.Duration())
+	case slog.KindAny:
+		v := value.Any()
+		if err, ok := v.(error); ok {
+			return err.Error()
+		}
+		if _, err := json.Marshal(v); err != nil {
+			return fmt.Sprintf("%+v", v)
+		}
+		return v
+	default:
+		return value.Any()
+	}
+}
@@ -0,0 +1,55 @@
+package collector
+
+import "context"
+
+// EventProcessor processes every completed event of all collectors before it is added to its parent or dispatched to
+// the storages, e.g. to redact, sample, enrich or drop events in one place instead of per collector.
+// Processors are registered with EventAggregatorOptions.Processors and run in order.
+type EventProcessor interface {
+	// Process returns the event to collect, which can be evt modified in place or a copy, and false to drop it.
+	// Nested events are processed before their parent, previews of events in progress have InProgress set.
+	// Processors run synchronously while the event is collected and must not call the aggregator.
+	Process(ctx context.Context, evt *Event) (*Event, bool)
+}
+
+// EventProcessorFunc adapts a function to an EventProcessor
+type EventProcessorFunc func(ctx context.Context, evt *Event) (*Event, bool)
+
+// Process implements EventProcessor
+func (f EventProcessorFunc) Process(ctx context.Context, evt *Event) (*Event, bool) {
+	return f(ctx, evt)
+}
+
+// process runs the processors on a completed event and returns the processed event, or false if a processor dropped it.
+// A panicking processor is skipped. Must be called with lock held.
+func (a *EventAggregator) process(ctx context.Context, evt *Event) (*Event, bool) {
+	if len(a.processors) == 0 {
+		return evt, true
+	}
+
+	for _, processor := range a.processors {
+		processed, keep := a.processSafely(ctx, processor, evt)
+		if !keep {
+			a.droppedByProcessors.Add(1)
+			return nil, false
+		}
+		if processed != nil {
+			evt = processed
+		}
+	}
+	// Processors may have changed the data, e.g. by redacting it
+	evt.Size = evt.calculateSize()
+	return evt, true
+}
+
+// processSafely runs a processor on an event, a panic is recovered and the event is kept unchanged
+func (a *EventAggregator) processSafely(ctx context.Context, processor EventProcessor, evt *Event) (processed *Event, keep bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			a.countPanic(newInternalError("event_processor", v))
+			processed, keep = evt, true
+		}
+	}()
+
+	return processor.Process(ctx, evt)
+}
@@ -0,0 +1,102 @@
+package collector_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func newProcessingAggregator(t *testing.T, processors ...collector.EventProcessor) (*collector.EventAggregator, *collector.CaptureStorage) {
+	t.Helper()
+
+	aggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		Processors: processors,
+	})
+	t.Cleanup(aggregator.Close)
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+	return aggregator, storage
+}
+
+func TestEventAggregator_Processors(t *testing.T) {
+	redact := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		if s, ok := evt.Data.(string); ok {
+			evt.Data = strings.ReplaceAll(s, "secret", "[redacted]")
+		}
+		return evt, true
+	})
+	dropHealthChecks := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		return evt, evt.Data != "health check"
+	})
+	// Processors run in order, the event is already redacted
+	enrich := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		processed := *evt
+		processed.Data = evt.Data.(string) + " (enriched)"
+		return &processed, true
+	})
+	aggregator, storage := newProcessingAggregator(t, redact, dropHealthChecks, enrich)
+
+	ctx := context.Background()
+	aggregator.CollectEvent(ctx, "health check")
+
+	groupCtx := aggregator.StartEvent(ctx)
+	aggregator.CollectEvent(groupCtx, "nested secret")
+	aggregator.CollectEvent(groupCtx, "health check")
+	aggregator.EndEvent(groupCtx, "top-level secret")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "top-level [redacted] (enriched)", events[0].Data)
+	require.Len(t, events[0].Children, 1)
+	assert.Equal(t, "nested [redacted] (enriched)", events[0].Children[0].Data)
+
+	assert.Equal(t, uint64(2), aggregator.Diagnostics().DroppedByProcessors)
+}
+
+func TestEventAggregator_Processors_DroppedParent(t *testing.T) {
+	dropParent := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		return evt, evt.Data != "parent"
+	})
+	aggregator, storage := newProcessingAggregator(t, dropParent)
+
+	ctx := context.Background()
+	groupCtx := aggregator.StartEvent(ctx)
+	aggregator.CollectEvent(groupCtx, "child")
+	aggregator.EndEvent(groupCtx, "parent")
+
+	// Children are dropped with their parent
+	assert.Empty(t, storage.GetEvents(10))
+	assert.Zero(t, aggregator.Diagnostics().OpenEvents)
+}
+
+func TestEventAggregator_Processors_Panic(t *testing.T) {
+	panicking := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		panic("processor failed")
+	})
+	var processed []any
+	recording := collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+		processed = append(processed, evt.Data)
+		return evt, true
+	})
+	aggregator, storage := newProcessingAggregator(t, panicking, recording)
+
+	aggregator.CollectEvent(context.Background(), "event")
+
+	// The panicking processor is skipped, the following processors still run
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "event", events[0].Data)
+	assert.Equal(t, []any{"event"}, processed)
+
+	diagnostics := aggregator.Diagnostics()
+	assert.Equal(t, uint64(1), diagnostics.RecoveredPanics)
+	require.Len(t, diagnostics.RecentInternalErrors, 1)
+	assert.Equal(t, "event_processor", diagnostics.RecentInternalErrors[0].Component)
+}
@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ChildrenMode selects how the children of events are returned by CaptureStorage.ReadEvents
+type ChildrenMode string
+
+const (
+	// ChildrenNested returns events with their children as trees
+	ChildrenNested ChildrenMode = "nested"
+	// ChildrenExcluded returns only the top-level events without their children
+	ChildrenExcluded ChildrenMode = "exclude"
+	// ChildrenFlattened returns the children as separate events after their parent (depth first, without children).
+	// The GroupID of a child is the ID of its parent.
+	ChildrenFlattened ChildrenMode = "flatten"
+)
+
+// ParseChildrenMode parses "nested", "exclude" or "flatten", an empty string is ChildrenNested
+func ParseChildrenMode(s string) (ChildrenMode, error) {
+	switch mode := ChildrenMode(s); mode {
+	case "":
+		return ChildrenNested, nil
+	case ChildrenNested, ChildrenExcluded, ChildrenFlattened:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid children mode %q", s)
+}
+
+// ReadOptions select the events returned by CaptureStorage.ReadEvents, QueryEvents and ChangesSince and how much of
+// their trees is included. The zero value returns all events with their complete trees like GetEvents.
+type ReadOptions struct {
+	// Limit is the maximum number of top-level events (the most recent ones), 0 reads all events of the storage
+	Limit uint64
+
+	// Since skips top-level events that started before it, e.g. to read only the events of the last minutes.
+	// The zero time includes all events.
+	Since time.Time
+
+	// Children selects how children are returned, the default is ChildrenNested
+	Children ChildrenMode
+
+	// MaxDepth limits the levels of children that are included, 1 includes only the direct children of top-level
+	// events. 0 includes all levels.
+	MaxDepth int
+
+	// MaxBytes limits the summed memory size (see Event.Size) of the returned events including their included
+	// children. Top-level events are taken from the most recent one until the next would exceed the budget, trees are
+	// never split. 0 does not limit the size.
+	MaxBytes uint64
+}
+
+// ReadEvents returns the most recent events ordered by their sequence (oldest first) restricted by opts.
+// Trimmed events are copies, the events in the storage are not modified.
+func (s *CaptureStorage) ReadEvents(opts ReadOptions) []*Event {
+	limit := opts.Limit
+	if limit == 0 {
+		limit = s.buffer.Size()
+	}
+	events := s.GetEvents(limit)
+
+	stored := make([]StoredEvent, len(events))
+	for i, event := range events {
+		stored[i] = StoredEvent{Event: event}
+	}
+	read := opts.read(stored)
+
+	result := make([]*Event, len(read))
+	for i, event := range read {
+		result[i] = event.Event
+	}
+	return result
+}
+
+// read restricts events ordered from oldest to newest by the options. Flattened children get the revision of their
+// top-level event.
+func (o ReadOptions) read(events []StoredEvent) []StoredEvent {
+	if o.Limit > 0 && uint64(len(events)) > o.Limit {
+		events = events[uint64(len(events))-o.Limit:]
+	}
+
+	// Take the budget from the most recent events, they are most likely the ones of interest
+	var (
+		trees []StoredEvent
+		total uint64
+	)
+	for _, event := range slices.Backward(events) {
+		if !o.Since.IsZero() && event.Event.Start.Before(o.Since) {
+			continue
+		}
+		tree := o.trim(event.Event, 0)
+		if o.MaxBytes > 0 {
+			size := tree.totalSize()
+			if total+size > o.MaxBytes {
+				break
+			}
+			total += size
+		}
+		trees = append(trees, StoredEvent{Event: tree, Revision: event.Revision})
+	}
+	slices.Reverse(trees)
+
+	if o.Children != ChildrenFlattened {
+		return trees
+	}
+	flattened := make([]StoredEvent, 0, len(trees))
+	for _, tree := range trees {
+		for _, evt := range tree.Event.Visit() {
+			if len(evt.Children) > 0 {
+				withoutChildren := *evt
+				withoutChildren.Children = nil
+				evt = &withoutChildren
+			}
+			flattened = append(flattened, StoredEvent{Event: evt, Revision: tree.Revision})
+		}
+	}
+	return flattened
+}
+
+// trim returns the event with the children at the given depth that are included by the options. The event is copied
+// only if children are removed.
+func (o ReadOptions) trim(event *Event, depth int) *Event {
+	if len(event.Children) == 0 {
+		return event
+	}
+	if o.Children == ChildrenExcluded || (o.MaxDepth > 0 && depth >= o.MaxDepth) {
+		trimmed := *event
+		trimmed.Children = nil
+		return &trimmed
+	}
+
+	var children []*Event
+	for i, child := range event.Children {
+		trimmedChild := o.trim(child, depth+1)
+		if trimmedChild != child && children == nil {
+			children = slices.Clone(event.Children[:i])
+		}
+		if children != nil {
+			children = append(children, trimmedChild)
+		}
+	}
+	if children == nil {
+		return event
+	}
+	trimmed := *event
+	trimmed.Children = children
+	return &trimmed
+}
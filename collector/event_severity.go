@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Severity rates an event, e.g. to color, filter and sort events or to notify about them
+type Severity string
+
+const (
+	SeverityInfo  Severity = "info"
+	SeverityWarn  Severity = "warn"
+	SeverityError Severity = "error"
+)
+
+// ParseSeverity parses "info", "warn" or "error"
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(s); severity {
+	case SeverityInfo, SeverityWarn, SeverityError:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity %q", s)
+}
+
+// rank orders severities, an unknown severity ranks like SeverityInfo
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarn:
+		return 1
+	case SeverityError:
+		return 2
+	}
+	return 0
+}
+
+// AtLeast returns true if the severity is min or higher
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// Compare returns -1, 0 or +1 if the severity is lower, equal or higher than other, e.g. for slices.SortFunc
+func (s Severity) Compare(other Severity) int {
+	return s.rank() - other.rank()
+}
+
+// MaxSeverity returns the highest severity of the event and its children
+func (e *Event) MaxSeverity() Severity {
+	severity := SeverityInfo
+	for _, evt := range e.Visit() {
+		if evt.Severity.Compare(severity) > 0 {
+			severity = evt.Severity
+		}
+	}
+	return severity
+}
+
+// SeverityThresholds rate events taking longer than a threshold as SeverityWarn
+type SeverityThresholds struct {
+	// SlowRequest applies to HTTP requests and RPC calls.
+	// Default: 1s, a negative value disables it
+	SlowRequest time.Duration
+	// SlowQuery applies to DB queries.
+	// Default: 100ms, a negative value disables it
+	SlowQuery time.Duration
+}
+
+// DefaultSeverityThresholds returns the default thresholds
+func DefaultSeverityThresholds() SeverityThresholds {
+	return SeverityThresholds{
+		SlowRequest: time.Second,
+		SlowQuery:   100 * time.Millisecond,
+	}
+}
+
+// withDefaults sets the default of thresholds that are not set
+func (t SeverityThresholds) withDefaults() SeverityThresholds {
+	defaults := DefaultSeverityThresholds()
+	if t.SlowRequest == 0 {
+		t.SlowRequest = defaults.SlowRequest
+	}
+	if t.SlowQuery == 0 {
+		t.SlowQuery = defaults.SlowQuery
+	}
+	return t
+}
+
+// Rate returns the severity of event data: SeverityError for 5xx responses, failed requests, queries, calls, cache
+// operations, mails and tasks,
+// panics and logs with at least level error; SeverityWarn for 4xx responses, disconnected clients, logs with level
+// warn, failed flag evaluations and events slower than the thresholds; SeverityInfo otherwise
+func (t SeverityThresholds) Rate(data any) Severity {
+	t = t.withDefaults()
+	slow := func(d, threshold time.Duration) bool {
+		return threshold > 0 && d > threshold
+	}
+
+	switch data := data.(type) {
+	case HTTPServerRequest:
+		switch {
+		case data.StatusCode >= 500 || data.Panic != "":
+			return SeverityError
+		case data.StatusCode >= 400 || data.ClientDisconnected:
+			return SeverityWarn
+		case data.StatusCode > 0 && slow(data.Duration(), t.SlowRequest):
+			return SeverityWarn
+		}
+	case HTTPClientRequest:
+		switch {
+		case data.StatusCode >= 500 || data.Error != nil:
+			return SeverityError
+		case data.StatusCode >= 400 || slow(data.Duration(), t.SlowRequest):
+			return SeverityWarn
+		}
+	case RPCCall:
+		switch {
+		case data.Error != nil:
+			return SeverityError
+		case slow(data.Duration, t.SlowRequest):
+			return SeverityWarn
+		}
+	case DBQuery:
+		switch {
+		case data.Error != nil:
+			return SeverityError
+		case slow(data.Duration, t.SlowQuery):
+			return SeverityWarn
+		}
+	case CacheOp:
+		if data.Error != nil {
+			return SeverityError
+		}
+	case Mail:
+		if data.Error != nil {
+			return SeverityError
+		}
+	case FlagEvaluation:
+		// The default value is used if an evaluation fails
+		if data.Error != nil {
+			return SeverityWarn
+		}
+	case slog.Record:
+		switch {
+		case data.Level >= slog.LevelError:
+			return SeverityError
+		case data.Level >= slog.LevelWarn:
+			return SeverityWarn
+		}
+	case Task:
+		if data.Error != "" || data.Panic != "" {
+			return SeverityError
+		}
+	case InternalError:
+		return SeverityError
+	}
+	return SeverityInfo
+}
@@ -0,0 +1,92 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestSeverityThresholds_Rate(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name     string
+		data     any
+		expected collector.Severity
+	}{
+		{"server 200", collector.HTTPServerRequest{StatusCode: http.StatusOK, RequestTime: start, ResponseTime: start.Add(time.Millisecond)}, collector.SeverityInfo},
+		{"server 404", collector.HTTPServerRequest{StatusCode: http.StatusNotFound}, collector.SeverityWarn},
+		{"server 500", collector.HTTPServerRequest{StatusCode: http.StatusInternalServerError}, collector.SeverityError},
+		{"server panic", collector.HTTPServerRequest{StatusCode: http.StatusOK, Panic: "boom"}, collector.SeverityError},
+		{"server slow", collector.HTTPServerRequest{StatusCode: http.StatusOK, RequestTime: start, ResponseTime: start.Add(2 * time.Second)}, collector.SeverityWarn},
+		{"server pending", collector.HTTPServerRequest{RequestTime: start, ResponseTime: start.Add(2 * time.Second)}, collector.SeverityInfo},
+		{"client error", collector.HTTPClientRequest{Error: errors.New("connection refused")}, collector.SeverityError},
+		{"client 429", collector.HTTPClientRequest{StatusCode: http.StatusTooManyRequests}, collector.SeverityWarn},
+		{"failed query", collector.DBQuery{Error: errors.New("deadlock detected")}, collector.SeverityError},
+		{"slow query", collector.DBQuery{Duration: 200 * time.Millisecond}, collector.SeverityWarn},
+		{"query", collector.DBQuery{Duration: 5 * time.Millisecond}, collector.SeverityInfo},
+		{"failed call", collector.RPCCall{Error: errors.New("not_found")}, collector.SeverityError},
+		{"error log", slog.NewRecord(start, slog.LevelError, "failed", 0), collector.SeverityError},
+		{"warn log", slog.NewRecord(start, slog.LevelWarn, "cache miss", 0), collector.SeverityWarn},
+		{"info log", slog.NewRecord(start, slog.LevelInfo, "started", 0), collector.SeverityInfo},
+		{"failed task", collector.Task{Name: "fetch user", Error: "timeout"}, collector.SeverityError},
+		{"internal error", collector.InternalError{Panic: "boom"}, collector.SeverityError},
+		{"custom", "custom event", collector.SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, collector.DefaultSeverityThresholds().Rate(tt.data))
+		})
+	}
+
+	t.Run("custom thresholds", func(t *testing.T) {
+		query := collector.DBQuery{Duration: 200 * time.Millisecond}
+		assert.Equal(t, collector.SeverityInfo, collector.SeverityThresholds{SlowQuery: time.Second}.Rate(query))
+		assert.Equal(t, collector.SeverityInfo, collector.SeverityThresholds{SlowQuery: -1}.Rate(query))
+	})
+}
+
+func TestSeverity_Compare(t *testing.T) {
+	assert.True(t, collector.SeverityError.AtLeast(collector.SeverityWarn))
+	assert.True(t, collector.SeverityWarn.AtLeast(collector.SeverityWarn))
+	assert.False(t, collector.SeverityInfo.AtLeast(collector.SeverityWarn))
+	assert.Positive(t, collector.SeverityError.Compare(collector.SeverityInfo))
+
+	severity, err := collector.ParseSeverity("warn")
+	require.NoError(t, err)
+	assert.Equal(t, collector.SeverityWarn, severity)
+	_, err = collector.ParseSeverity("fatal")
+	assert.Error(t, err)
+}
+
+func TestEventAggregator_Severity(t *testing.T) {
+	aggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		SeverityThresholds: collector.SeverityThresholds{SlowQuery: 10 * time.Millisecond},
+	})
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	eventCtx := aggregator.StartEvent(context.Background())
+	aggregator.CollectEvent(eventCtx, collector.DBQuery{Query: "SELECT 1", Duration: 20 * time.Millisecond})
+	aggregator.EndEvent(eventCtx, collector.HTTPServerRequest{StatusCode: http.StatusOK})
+	aggregator.CollectEvent(context.Background(), collector.DBQuery{Query: "SELECT 1", Error: errors.New("syntax error")})
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, collector.SeverityInfo, events[0].Severity)
+	require.Len(t, events[0].Children, 1)
+	assert.Equal(t, collector.SeverityWarn, events[0].Children[0].Severity)
+	assert.Equal(t, collector.SeverityWarn, events[0].MaxSeverity())
+	assert.Equal(t, collector.SeverityError, events[1].Severity)
+}
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"cmp"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EndpointStats holds aggregated metrics for all events sharing the same key
+type EndpointStats struct {
+	// Key identifies the endpoint, e.g. "GET /api/users" or a query fingerprint
+	Key        string        `json:"key"`
+	Count      int           `json:"count"`
+	ErrorCount int           `json:"errorCount"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	// Bytes is the sum of request and response sizes (zero for DB queries)
+	Bytes uint64 `json:"bytes"`
+}
+
+// ErrorRate returns the fraction of events that resulted in an error
+func (s EndpointStats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.ErrorCount) / float64(s.Count)
+}
+
+// EventStats holds per-endpoint statistics computed over a list of events
+type EventStats struct {
+	ServerRequests []EndpointStats `json:"serverRequests"`
+	ClientRequests []EndpointStats `json:"clientRequests"`
+	DBQueries      []EndpointStats `json:"dbQueries"`
+}
+
+type endpointSamples struct {
+	durations []time.Duration
+	errors    int
+	bytes     uint64
+}
+
+func (s *endpointSamples) add(duration time.Duration, failed bool, bytes uint64) {
+	s.durations = append(s.durations, duration)
+	if failed {
+		s.errors++
+	}
+	s.bytes += bytes
+}
+
+// CalculateEventStats aggregates statistics over the given events including all their children.
+// Results are sorted by count (descending) and key.
+func CalculateEventStats(events []*Event) EventStats {
+	serverRequests := make(map[string]*endpointSamples)
+	clientRequests := make(map[string]*endpointSamples)
+	dbQueries := make(map[string]*endpointSamples)
+
+	samplesFor := func(m map[string]*endpointSamples, key string) *endpointSamples {
+		s, ok := m[key]
+		if !ok {
+			s = &endpointSamples{}
+			m[key] = s
+		}
+		return s
+	}
+
+	for _, event := range events {
+		for _, e := range event.Visit() {
+			switch data := e.Data.(type) {
+			case HTTPServerRequest:
+				failed := data.Error != nil || data.StatusCode >= 400
+				samplesFor(serverRequests, data.Method+" "+data.Route()).add(data.Duration(), failed, data.RequestSize+data.ResponseSize)
+			case HTTPClientRequest:
+				failed := data.Error != nil || data.StatusCode >= 400
+				samplesFor(clientRequests, data.Method+" "+clientRequestEndpoint(data.URL)).add(data.Duration(), failed, data.RequestSize+data.ResponseSize)
+			case DBQuery:
+				samplesFor(dbQueries, FingerprintQuery(data.Query)).add(data.Duration, data.Error != nil, 0)
+			}
+		}
+	}
+
+	return EventStats{
+		ServerRequests: summarizeSamples(serverRequests),
+		ClientRequests: summarizeSamples(clientRequests),
+		DBQueries:      summarizeSamples(dbQueries),
+	}
+}
+
+func summarizeSamples(m map[string]*endpointSamples) []EndpointStats {
+	result := make([]EndpointStats, 0, len(m))
+	for key, s := range m {
+		slices.Sort(s.durations)
+		result = append(result, EndpointStats{
+			Key:        key,
+			Count:      len(s.durations),
+			ErrorCount: s.errors,
+			P50:        percentile(s.durations, 50),
+			P95:        percentile(s.durations, 95),
+			Bytes:      s.bytes,
+		})
+	}
+	slices.SortFunc(result, func(a, b EndpointStats) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return result
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// clientRequestEndpoint strips scheme, query and fragment from an outgoing request URL
+func clientRequestEndpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host + u.Path
+}
+
+var (
+	fingerprintStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	fingerprintNumber        = regexp.MustCompile(`(^|[^\w$.])-?\d+(?:\.\d+)?\b`)
+	fingerprintValueList     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// FingerprintQuery normalizes a SQL query by replacing literals with placeholders and collapsing whitespace,
+// so that queries only differing in their values share the same fingerprint.
+func FingerprintQuery(query string) string {
+	fp := fingerprintStringLiteral.ReplaceAllString(query, "?")
+	fp = fingerprintNumber.ReplaceAllString(fp, "${1}?")
+	fp = fingerprintValueList.ReplaceAllString(fp, "(...)")
+	fp = fingerprintWhitespace.ReplaceAllString(fp, " ")
+	return strings.TrimSpace(fp)
+}
@@ -0,0 +1,123 @@
+package collector_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func serverRequestEvent(method, path string, status int, duration time.Duration, children ...*collector.Event) *collector.Event {
+	start := time.Now()
+	return &collector.Event{
+		Data: collector.HTTPServerRequest{
+			Method:       method,
+			Path:         path,
+			StatusCode:   status,
+			RequestTime:  start,
+			ResponseTime: start.Add(duration),
+			RequestSize:  10,
+			ResponseSize: 100,
+		},
+		Children: children,
+	}
+}
+
+func TestCalculateEventStats_ServerRequests(t *testing.T) {
+	events := []*collector.Event{
+		serverRequestEvent("GET", "/users", 200, 10*time.Millisecond),
+		serverRequestEvent("GET", "/users", 500, 30*time.Millisecond),
+		serverRequestEvent("GET", "/users", 200, 20*time.Millisecond),
+		serverRequestEvent("POST", "/users", 201, 5*time.Millisecond),
+	}
+
+	stats := collector.CalculateEventStats(events)
+
+	require.Len(t, stats.ServerRequests, 2)
+
+	users := stats.ServerRequests[0]
+	assert.Equal(t, "GET /users", users.Key)
+	assert.Equal(t, 3, users.Count)
+	assert.Equal(t, 1, users.ErrorCount)
+	assert.InDelta(t, 1.0/3.0, users.ErrorRate(), 0.001)
+	assert.Equal(t, 20*time.Millisecond, users.P50)
+	assert.Equal(t, 30*time.Millisecond, users.P95)
+	assert.Equal(t, uint64(330), users.Bytes)
+
+	assert.Equal(t, "POST /users", stats.ServerRequests[1].Key)
+	assert.Equal(t, 1, stats.ServerRequests[1].Count)
+}
+
+func TestCalculateEventStats_GroupsByRoutePattern(t *testing.T) {
+	withPattern := func(event *collector.Event, pattern string) *collector.Event {
+		req := event.Data.(collector.HTTPServerRequest)
+		req.RoutePattern = pattern
+		event.Data = req
+		return event
+	}
+	events := []*collector.Event{
+		withPattern(serverRequestEvent("GET", "/todos/1", 200, 10*time.Millisecond), "/todos/{id}"),
+		withPattern(serverRequestEvent("GET", "/todos/2", 200, 20*time.Millisecond), "/todos/{id}"),
+		serverRequestEvent("GET", "/health", 200, time.Millisecond),
+	}
+
+	stats := collector.CalculateEventStats(events)
+
+	require.Len(t, stats.ServerRequests, 2)
+	assert.Equal(t, "GET /todos/{id}", stats.ServerRequests[0].Key)
+	assert.Equal(t, 2, stats.ServerRequests[0].Count)
+	assert.Equal(t, "GET /health", stats.ServerRequests[1].Key)
+}
+
+func TestCalculateEventStats_IncludesChildren(t *testing.T) {
+	query := func(q string, err error) *collector.Event {
+		return &collector.Event{Data: collector.DBQuery{Query: q, Duration: time.Millisecond, Error: err}}
+	}
+	clientRequest := &collector.Event{Data: collector.HTTPClientRequest{
+		Method:     "GET",
+		URL:        "https://api.example.com/items?page=2",
+		StatusCode: 200,
+	}}
+
+	events := []*collector.Event{
+		serverRequestEvent("GET", "/items", 200, time.Millisecond,
+			query("SELECT * FROM items WHERE id = 1", nil),
+			query("SELECT * FROM items WHERE id = 2", errors.New("no rows")),
+			clientRequest,
+		),
+	}
+
+	stats := collector.CalculateEventStats(events)
+
+	require.Len(t, stats.DBQueries, 1)
+	assert.Equal(t, "SELECT * FROM items WHERE id = ?", stats.DBQueries[0].Key)
+	assert.Equal(t, 2, stats.DBQueries[0].Count)
+	assert.Equal(t, 1, stats.DBQueries[0].ErrorCount)
+
+	require.Len(t, stats.ClientRequests, 1)
+	assert.Equal(t, "GET api.example.com/items", stats.ClientRequests[0].Key)
+}
+
+func TestFingerprintQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT * FROM users WHERE name = 'O''Brien'", "SELECT * FROM users WHERE name = ?"},
+		{"SELECT * FROM users WHERE id = $1", "SELECT * FROM users WHERE id = $1"},
+		{"SELECT * FROM users WHERE id IN (1, 2, 3)", "SELECT * FROM users WHERE id IN (...)"},
+		{"SELECT *\n  FROM   users\n LIMIT 10", "SELECT * FROM users LIMIT ?"},
+		{"SELECT col1 FROM t2", "SELECT col1 FROM t2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, collector.FingerprintQuery(tt.query))
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package collector
 
 import (
+	"cmp"
 	"context"
+	"log/slog"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -16,13 +20,14 @@ type EventStorage interface {
 	// ShouldCapture returns true if this storage wants to capture events for the given context
 	ShouldCapture(ctx context.Context) bool
 
-	// Add adds an event to the storage
+	// Add adds an event to the storage, an event with the ID of a stored event replaces it
+	// (e.g. the completed event for a preview of an event in progress)
 	Add(event *Event)
 
 	// GetEvent retrieves an event by its ID
 	GetEvent(id uuid.UUID) (*Event, bool)
 
-	// GetEvents returns the most recent n events
+	// GetEvents returns the most recent n events ordered by their sequence (oldest first)
 	GetEvents(limit uint64) []*Event
 
 	// Subscribe returns a channel that receives notifications of new events
@@ -35,6 +40,17 @@ type EventStorage interface {
 	Close()
 }
 
+// childAddingStorage is implemented by storages that add nested events completed after their parent to the stored event
+type childAddingStorage interface {
+	AddChild(child *Event) bool
+}
+
+// removingStorage is implemented by storages that can remove a top-level event, e.g. the preview of an event in
+// progress that was dropped when it completed
+type removingStorage interface {
+	Remove(id uuid.UUID) bool
+}
+
 // CaptureMode defines how a CaptureStorage decides which events to capture
 type CaptureMode int
 
@@ -87,9 +103,43 @@ type CaptureStorage struct {
 	sessionID   uuid.UUID
 	captureMode CaptureMode
 	capturing   bool // whether actively capturing events
+	ambient     bool // whether ambient events are captured in session mode
 
 	buffer   *LookupRingBuffer[*Event, uuid.UUID]
-	notifier *Notifier[*Event]
+	notifier *Notifier[StoredEvent]
+
+	// mu guards the memory accounting and revisions of stored events, the capture limit, the tenant and the archive
+	mu        sync.Mutex
+	revision  uint64
+	revisions map[uuid.UUID]uint64 // revision at which each stored event was added or last replaced
+	memory    uint64
+	tracker   *memoryTracker
+	limit     CaptureLimit
+	tenant    string
+	archive   *EventArchive
+	logger    *slog.Logger
+}
+
+// StoredEvent is an event added to a CaptureStorage with the revision of the storage it was added at. The revision
+// increases with every added or replaced event, so subscribers can resume after the last revision they received (see
+// CaptureStorage.ChangesSince), even if events complete in another order than they started.
+type StoredEvent struct {
+	Event    *Event
+	Revision uint64
+}
+
+// CaptureLimit stops capturing automatically, zero fields are not limited.
+// Events of requests that are in progress when the limit is reached are still stored.
+type CaptureLimit struct {
+	// Until stops capturing at this time
+	Until time.Time
+	// Events stops capturing after this number of top-level events
+	Events int
+}
+
+// IsZero returns true if capturing is not limited
+func (l CaptureLimit) IsZero() bool {
+	return l == CaptureLimit{}
 }
 
 // NewCaptureStorage creates a new CaptureStorage for the given session ID.
@@ -100,7 +150,9 @@ func NewCaptureStorage(sessionID uuid.UUID, capacity uint64, mode CaptureMode) *
 		captureMode: mode,
 		capturing:   true,
 		buffer:      NewLookupRingBuffer[*Event, uuid.UUID](capacity),
-		notifier:    NewNotifier[*Event](),
+		notifier:    NewNotifier[StoredEvent](),
+		revisions:   make(map[uuid.UUID]uint64),
+		logger:      loggerOrDiscard(nil),
 	}
 }
 
@@ -124,28 +176,112 @@ func (s *CaptureStorage) SetCaptureMode(mode CaptureMode) {
 	s.captureMode = mode
 }
 
+// CaptureAmbient returns whether ambient events outside of requests are captured in session mode
+func (s *CaptureStorage) CaptureAmbient() bool {
+	return s.ambient
+}
+
+// SetCaptureAmbient enables or disables capturing of ambient events (logs, client calls from background goroutines)
+// in session mode. In global mode, ambient events are always captured.
+func (s *CaptureStorage) SetCaptureAmbient(captureAmbient bool) {
+	s.ambient = captureAmbient
+}
+
 // IsCapturing returns whether the storage is actively capturing events
 func (s *CaptureStorage) IsCapturing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.limit.Until.IsZero() && !time.Now().Before(s.limit.Until) {
+		s.stopByLimit()
+	}
 	return s.capturing
 }
 
-// SetCapturing enables or disables capturing of new events
+// SetCapturing enables or disables capturing of new events, a capture limit is removed
 func (s *CaptureStorage) SetCapturing(capturing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.capturing = capturing
+	s.limit = CaptureLimit{}
+}
+
+// CaptureLimit returns the remaining limit of the capture, the zero value if capturing is not limited
+func (s *CaptureStorage) CaptureLimit() CaptureLimit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limit
+}
+
+// SetCaptureLimit stops capturing automatically when the limit is reached, a zero limit removes it.
+// The limit is enforced by the storage, so it also applies when no dashboard is open.
+func (s *CaptureStorage) SetCaptureLimit(limit CaptureLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = limit
+}
+
+// Tenant returns the tenant the capture is restricted to, an empty string if events of all tenants are captured
+func (s *CaptureStorage) Tenant() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tenant
+}
+
+// SetTenant restricts capturing to events of a tenant (see WithTenant), an empty tenant removes the restriction.
+// Events without a tenant (e.g. ambient events or requests of other tenants) are not captured while it is set.
+func (s *CaptureStorage) SetTenant(tenant string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tenant = tenant
+}
+
+// Archive returns the archive of evicted events, nil if evicted events are discarded
+func (s *CaptureStorage) Archive() *EventArchive {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.archive
+}
+
+// SetArchive adds events evicted from the storage to the archive instead of discarding them, a nil archive discards
+// them again. Archived events are still found by GetEvent. The archive is cleared and closed with the storage.
+func (s *CaptureStorage) SetArchive(archive *EventArchive) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.archive = archive
+}
+
+// stopByLimit stops capturing because the limit was reached. Must be called with the lock held.
+func (s *CaptureStorage) stopByLimit() {
+	s.capturing = false
+	s.limit = CaptureLimit{}
 }
 
 // ShouldCapture returns true if this storage wants to capture events for the given context
 func (s *CaptureStorage) ShouldCapture(ctx context.Context) bool {
-	if !s.capturing {
+	if !s.IsCapturing() {
+		return false
+	}
+	if tenant := s.Tenant(); tenant != "" && TenantFromContext(ctx) != tenant {
 		return false
 	}
+	if isForcedCapture(ctx) {
+		return true
+	}
 	switch s.captureMode {
 	case CaptureModeGlobal:
 		return true
 	case CaptureModeSession:
 		sessionIDs, ok := SessionIDsFromContext(ctx)
 		if !ok {
-			return false
+			return s.ambient && IsAmbientContext(ctx)
 		}
 		return slices.Contains(sessionIDs, s.sessionID)
 	default:
@@ -153,36 +289,305 @@ func (s *CaptureStorage) ShouldCapture(ctx context.Context) bool {
 	}
 }
 
-// Add adds an event to the storage and notifies subscribers
+// Add adds or replaces an event in the storage and notifies subscribers
 func (s *CaptureStorage) Add(event *Event) {
-	s.buffer.Add(event)
-	s.notifier.Notify(event)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Subscribers are notified in the order of the revisions, so resuming after a revision does not miss events
+	s.notifier.Notify(StoredEvent{Event: event, Revision: s.store(event)})
+}
+
+// store adds an event to the buffer or replaces the stored event with its ID and returns the revision it was stored
+// at. Must be called with the lock held.
+func (s *CaptureStorage) store(event *Event) uint64 {
+	if previous, ok := s.buffer.Lookup(event.ID); ok && s.buffer.Replace(event) {
+		s.untrack(previous)
+	} else {
+		if evicted, ok := s.buffer.Add(event); ok {
+			// The archive writes the event in the background, the bodies released from their pool by untracking are
+			// kept by the queued event. An event in progress is not archived, it is added again when it completes.
+			if s.archive != nil && !evicted.InProgress {
+				s.archive.AddAsync(evicted)
+			}
+			s.untrack(evicted)
+		}
+		s.countTowardsLimit(event)
+	}
+	s.track(event)
+	return s.nextRevision(event)
 }
 
-// GetEvent retrieves an event by its ID
+// nextRevision increases the revision of the storage for an added or replaced event. Must be called with the lock held.
+func (s *CaptureStorage) nextRevision(event *Event) uint64 {
+	s.revision++
+	s.revisions[event.ID] = s.revision
+	return s.revision
+}
+
+// countTowardsLimit stops capturing once the limit of events is reached. Must be called with the lock held.
+func (s *CaptureStorage) countTowardsLimit(event *Event) {
+	if s.limit.Events == 0 || event.GroupID != nil {
+		return
+	}
+	s.limit.Events--
+	if s.limit.Events == 0 {
+		s.stopByLimit()
+	}
+}
+
+// track accounts the memory of an added event. Must be called with the lock held.
+func (s *CaptureStorage) track(event *Event) {
+	size := event.totalSize()
+	s.memory += size
+	if s.tracker != nil {
+		s.tracker.add(event, size)
+	}
+}
+
+// untrack releases the memory and the revision of a removed event. Must be called with the lock held.
+func (s *CaptureStorage) untrack(event *Event) {
+	delete(s.revisions, event.ID)
+	s.memory -= event.totalSize()
+	if s.tracker != nil {
+		s.tracker.remove(event)
+	}
+}
+
+// Stats returns the memory usage of the storage without iterating over its events
+func (s *CaptureStorage) Stats() StorageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return StorageStats{
+		StorageID:   s.id,
+		EventCount:  int(s.buffer.Size()),
+		MemoryBytes: s.memory,
+		Capacity:    s.buffer.Capacity(),
+
+		DroppedNotifications: s.notifier.Dropped(),
+	}
+}
+
+func (s *CaptureStorage) setMemoryTracker(tracker *memoryTracker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tracker != nil {
+		for event := range s.buffer.Iterate() {
+			s.tracker.remove(event)
+		}
+	}
+	s.tracker = tracker
+	if s.tracker != nil {
+		for event := range s.buffer.Iterate() {
+			s.tracker.add(event, event.totalSize())
+		}
+	}
+}
+
+func (s *CaptureStorage) setLogger(logger *slog.Logger) {
+	logger = loggerOrDiscard(logger).With("storage", s.id)
+
+	s.mu.Lock()
+	s.logger = logger
+	s.mu.Unlock()
+
+	s.notifier.setLogger(logger)
+}
+
+// AddChild adds a nested event that completed after its parent, which is stored in the storage or nested in one of its
+// events. The top-level event is replaced by a copy containing the child (so events handed out before are not
+// modified) and subscribers are notified about it. It returns false if the parent is not in the storage (anymore).
+func (s *CaptureStorage) AddChild(child *Event) bool {
+	if child.GroupID == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := s.storeChild(child)
+	if updated == nil {
+		return false
+	}
+	s.notifier.Notify(StoredEvent{Event: updated, Revision: s.nextRevision(updated)})
+	return true
+}
+
+// storeChild replaces the top-level event containing the parent of child by a copy with the child added.
+// It returns the copy, or nil if the parent is not in the storage. Must be called with the lock held.
+func (s *CaptureStorage) storeChild(child *Event) *Event {
+	if _, ok := s.buffer.Lookup(*child.GroupID); !ok {
+		return nil
+	}
+	var previous, updated *Event
+	for event := range s.buffer.Iterate() {
+		if updated = event.withChild(*child.GroupID, child); updated != nil {
+			previous = event
+			break
+		}
+	}
+	if updated == nil {
+		return nil
+	}
+	updated.LateChildren = true
+	s.buffer.Replace(updated)
+	s.untrack(previous)
+	s.track(updated)
+	return updated
+}
+
+// GetEvent retrieves an event by its ID, an evicted event is read from the archive if it is set
 func (s *CaptureStorage) GetEvent(id uuid.UUID) (*Event, bool) {
-	return s.buffer.Lookup(id)
+	if event, ok := s.buffer.Lookup(id); ok {
+		return event, true
+	}
+
+	s.mu.Lock()
+	archive, logger := s.archive, s.logger
+	s.mu.Unlock()
+	if archive == nil {
+		return nil, false
+	}
+	event, ok, err := archive.GetEvent(id)
+	if err != nil {
+		logger.Warn("Failed to read archived event", "event", id, "error", err)
+		return nil, false
+	}
+	return event, ok
 }
 
-// GetEvents returns the most recent n events
+// GetEvents returns the most recent n events ordered by their sequence (oldest first). Events are added when they
+// are completed, so an event can be added after events that started later.
 func (s *CaptureStorage) GetEvents(limit uint64) []*Event {
-	return s.buffer.GetRecords(limit)
+	events := s.buffer.GetRecords(limit)
+	slices.SortStableFunc(events, func(a, b *Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	return events
+}
+
+// QueryEvents returns the events read with opts (see ReadEvents) that match match (nil matches any event) in the given
+// order. Sorting the events of the storage instead of a rendered page keeps the order consistent when paging.
+func (s *CaptureStorage) QueryEvents(opts ReadOptions, match func(event *Event) bool, order EventOrder) []*Event {
+	events := s.ReadEvents(opts)
+	slices.Reverse(events)
+	if match != nil {
+		events = slices.DeleteFunc(events, func(event *Event) bool {
+			return !match(event)
+		})
+	}
+	order.sort(events)
+	return events
 }
 
 // Subscribe returns a channel that receives notifications of new events
 func (s *CaptureStorage) Subscribe(ctx context.Context) <-chan *Event {
+	changes := s.notifier.Subscribe(ctx)
+	ch := make(chan *Event, cap(changes))
+	go func() {
+		defer close(ch)
+		for change := range changes {
+			select {
+			case ch <- change.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// SubscribeChanges returns a channel that receives notifications of new events with the revision they were added at,
+// ordered by revision
+func (s *CaptureStorage) SubscribeChanges(ctx context.Context) <-chan StoredEvent {
 	return s.notifier.Subscribe(ctx)
 }
 
-// Clear removes all events from the storage
+// Revision returns the revision of the last added or replaced event, 0 if no event was added
+func (s *CaptureStorage) Revision() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.revision
+}
+
+// ChangesSince returns the stored events that were added or replaced after a revision ordered by revision and
+// restricted by opts, e.g. to send the events a subscriber missed while it was disconnected. The limit and budget of
+// opts keep the most recent changes. Events that were evicted in the meantime are not returned.
+func (s *CaptureStorage) ChangesSince(revision uint64, opts ReadOptions) []StoredEvent {
+	s.mu.Lock()
+	var changes []StoredEvent
+	for event := range s.buffer.Iterate() {
+		if r := s.revisions[event.ID]; r > revision {
+			changes = append(changes, StoredEvent{Event: event, Revision: r})
+		}
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(changes, func(a, b StoredEvent) int {
+		return cmp.Compare(a.Revision, b.Revision)
+	})
+	return opts.read(changes)
+}
+
+// Clear removes all events from the storage and its archive
 func (s *CaptureStorage) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for event := range s.buffer.Iterate() {
+		s.untrack(event)
+	}
 	s.buffer.Clear()
+	if s.archive != nil {
+		if err := s.archive.Clear(); err != nil {
+			s.logger.Warn("Failed to clear archive", "error", err)
+		}
+	}
+}
+
+// Remove removes a top-level event by its ID and reports whether it was found
+func (s *CaptureStorage) Remove(id uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, ok := s.buffer.Remove(id)
+	if ok {
+		s.untrack(event)
+	}
+	return ok
 }
 
-// Close releases resources used by the storage
+// RemoveWhere removes all events matching the predicate and returns the number of removed events
+func (s *CaptureStorage) RemoveWhere(match func(event *Event) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := s.buffer.RemoveWhere(match)
+	for _, event := range removed {
+		s.untrack(event)
+	}
+	return len(removed)
+}
+
+// Close releases resources used by the storage and closes its archive
 func (s *CaptureStorage) Close() {
 	s.notifier.Close()
+
+	s.mu.Lock()
+	archive, logger := s.archive, s.logger
+	s.mu.Unlock()
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			logger.Warn("Failed to close archive", "error", err)
+		}
+	}
 }
 
 // Ensure CaptureStorage implements EventStorage
 var _ EventStorage = (*CaptureStorage)(nil)
+var _ memoryTrackingStorage = (*CaptureStorage)(nil)
+var _ childAddingStorage = (*CaptureStorage)(nil)
+var _ loggingStorage = (*CaptureStorage)(nil)
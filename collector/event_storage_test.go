@@ -2,6 +2,7 @@ package collector_test
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -56,6 +57,22 @@ func TestCaptureStorage_ShouldCapture_SessionMode_MultipleSessionsInContext(t *t
 	assert.True(t, storage.ShouldCapture(ctx))
 }
 
+func TestCaptureStorage_ShouldCapture_SessionMode_Ambient(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeSession)
+	defer storage.Close()
+
+	ctx := context.Background() // no session ID, not in a request
+	otherSessionCtx := collector.WithSessionIDs(context.Background(), []uuid.UUID{uuid.Must(uuid.NewV4())})
+
+	assert.False(t, storage.ShouldCapture(ctx))
+
+	storage.SetCaptureAmbient(true)
+	assert.True(t, storage.CaptureAmbient())
+	assert.True(t, storage.ShouldCapture(ctx))
+	assert.False(t, storage.ShouldCapture(otherSessionCtx), "events of other sessions must not be captured")
+}
+
 func TestCaptureStorage_ShouldCapture_GlobalMode_AlwaysTrue(t *testing.T) {
 	sessionID := uuid.Must(uuid.NewV4())
 	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
@@ -155,6 +172,43 @@ func TestCaptureStorage_Add_StoresEvent(t *testing.T) {
 	assert.Equal(t, "test data", events[0].Data)
 }
 
+func TestCaptureStorage_Add_ReplacesInProgressEvent(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := storage.Subscribe(ctx)
+
+	eventID := uuid.Must(uuid.NewV7())
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: "other"})
+	storage.Add(&collector.Event{ID: eventID, Data: "pending", InProgress: true})
+	storage.Add(&collector.Event{ID: eventID, Data: "completed"})
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, eventID, events[1].ID)
+	assert.Equal(t, "completed", events[1].Data)
+	assert.False(t, events[1].InProgress)
+
+	event, ok := storage.GetEvent(eventID)
+	require.True(t, ok)
+	assert.Equal(t, "completed", event.Data)
+
+	// Subscribers are notified about the preview and the completed event
+	var notified []any
+	for range 3 {
+		select {
+		case event := <-ch:
+			notified = append(notified, event.Data)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	}
+	assert.Equal(t, []any{"other", "pending", "completed"}, notified)
+}
+
 func TestCaptureStorage_RingBuffer_Capacity(t *testing.T) {
 	sessionID := uuid.Must(uuid.NewV4())
 	storage := collector.NewCaptureStorage(sessionID, 5, collector.CaptureModeGlobal)
@@ -276,6 +330,391 @@ func TestCaptureStorage_Clear(t *testing.T) {
 	assert.Len(t, storage.GetEvents(10), 0)
 }
 
+func TestCaptureStorage_Stats(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 2, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	newEvent := func(size uint64, childSizes ...uint64) *collector.Event {
+		event := &collector.Event{ID: uuid.Must(uuid.NewV7()), Size: size}
+		for _, childSize := range childSizes {
+			event.Children = append(event.Children, &collector.Event{ID: uuid.Must(uuid.NewV7()), Size: childSize})
+		}
+		return event
+	}
+
+	first := newEvent(100, 10, 20)
+	storage.Add(first)
+	storage.Add(newEvent(200))
+	stats := storage.Stats()
+	assert.Equal(t, storage.ID(), stats.StorageID)
+	assert.Equal(t, 2, stats.EventCount)
+	assert.Equal(t, uint64(330), stats.MemoryBytes)
+	assert.Equal(t, uint64(2), stats.Capacity)
+
+	// Replacing an event accounts the size of the new event
+	storage.Add(&collector.Event{ID: first.ID, Size: 50})
+	assert.Equal(t, uint64(250), storage.Stats().MemoryBytes)
+
+	// Evicting the oldest event releases its size
+	storage.Add(newEvent(400))
+	stats = storage.Stats()
+	assert.Equal(t, 2, stats.EventCount)
+	assert.Equal(t, uint64(600), stats.MemoryBytes)
+
+	storage.Clear()
+	stats = storage.Stats()
+	assert.Equal(t, 0, stats.EventCount)
+	assert.Equal(t, uint64(0), stats.MemoryBytes)
+}
+
+func TestCaptureStorage_RemoveWhere(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	for i := range 4 {
+		storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: i, Size: 100})
+	}
+
+	removed := storage.RemoveWhere(func(event *collector.Event) bool {
+		return event.Data.(int)%2 == 0
+	})
+	assert.Equal(t, 2, removed)
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Data)
+	assert.Equal(t, 3, events[1].Data)
+
+	stats := storage.Stats()
+	assert.Equal(t, 2, stats.EventCount)
+	assert.Equal(t, uint64(200), stats.MemoryBytes)
+}
+
+func TestCaptureStorage_QueryEvents(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	add := func(name string, duration time.Duration, size uint64, severity collector.Severity) {
+		storage.Add(&collector.Event{
+			ID:    uuid.Must(uuid.NewV7()),
+			Data:  name,
+			Start: start,
+			End:   start.Add(duration),
+			// A nested event counts towards the size and severity of its parent
+			Children: []*collector.Event{{ID: uuid.Must(uuid.NewV7()), Size: size, Severity: severity}},
+		})
+	}
+	add("fast", 10*time.Millisecond, 100, collector.SeverityInfo)
+	add("slow", 2*time.Second, 50, collector.SeverityWarn)
+	add("large", 20*time.Millisecond, 5000, collector.SeverityInfo)
+	add("failed", 5*time.Millisecond, 100, collector.SeverityError)
+	add("in progress", -time.Second, 10, collector.SeverityInfo)
+
+	names := func(events []*collector.Event) []string {
+		var names []string
+		for _, event := range events {
+			names = append(names, event.Data.(string))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		limit    uint64
+		match    func(event *collector.Event) bool
+		order    collector.EventOrder
+		expected []string
+	}{
+		{
+			name:     "newest",
+			limit:    10,
+			order:    collector.EventOrderNewest,
+			expected: []string{"in progress", "failed", "large", "slow", "fast"},
+		},
+		{
+			name:     "duration",
+			limit:    10,
+			order:    collector.EventOrderDuration,
+			expected: []string{"slow", "large", "fast", "failed", "in progress"},
+		},
+		{
+			name:     "size",
+			limit:    10,
+			order:    collector.EventOrderSize,
+			expected: []string{"large", "failed", "fast", "slow", "in progress"},
+		},
+		{
+			name:     "severity",
+			limit:    10,
+			order:    collector.EventOrderSeverity,
+			expected: []string{"failed", "slow", "in progress", "large", "fast"},
+		},
+		{
+			name:  "match",
+			limit: 10,
+			match: func(event *collector.Event) bool {
+				return event.Data != "large"
+			},
+			order:    collector.EventOrderSize,
+			expected: []string{"failed", "fast", "slow", "in progress"},
+		},
+		{
+			name:     "limit applies before sorting",
+			limit:    2,
+			order:    collector.EventOrderDuration,
+			expected: []string{"failed", "in progress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, names(storage.QueryEvents(collector.ReadOptions{Limit: tt.limit}, tt.match, tt.order)))
+		})
+	}
+}
+
+func TestParseEventOrder(t *testing.T) {
+	order, err := collector.ParseEventOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, collector.EventOrderNewest, order)
+
+	order, err = collector.ParseEventOrder("duration")
+	require.NoError(t, err)
+	assert.Equal(t, collector.EventOrderDuration, order)
+
+	_, err = collector.ParseEventOrder("random")
+	assert.Error(t, err)
+}
+
+func TestCaptureStorage_ReadEvents(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := func(name string, size uint64, children ...*collector.Event) *collector.Event {
+		return &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: name, Size: size, Start: start, Children: children}
+	}
+	storage.Add(event("a", 10, event("a1", 20, event("a11", 40)), event("a2", 5)))
+	start = start.Add(time.Minute)
+	storage.Add(event("b", 10, event("b1", 5)))
+
+	// tree renders the names of the events with their children in parentheses
+	var tree func(events []*collector.Event) string
+	tree = func(events []*collector.Event) string {
+		var names []string
+		for _, event := range events {
+			name := event.Data.(string)
+			if len(event.Children) > 0 {
+				name += "(" + tree(event.Children) + ")"
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	tests := []struct {
+		name     string
+		opts     collector.ReadOptions
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "a(a1(a11) a2) b(b1)",
+		},
+		{
+			name:     "limit",
+			opts:     collector.ReadOptions{Limit: 1},
+			expected: "b(b1)",
+		},
+		{
+			name:     "exclude children",
+			opts:     collector.ReadOptions{Children: collector.ChildrenExcluded},
+			expected: "a b",
+		},
+		{
+			name:     "flatten children",
+			opts:     collector.ReadOptions{Children: collector.ChildrenFlattened},
+			expected: "a a1 a11 a2 b b1",
+		},
+		{
+			name:     "max depth",
+			opts:     collector.ReadOptions{MaxDepth: 1},
+			expected: "a(a1 a2) b(b1)",
+		},
+		{
+			name:     "flatten with max depth",
+			opts:     collector.ReadOptions{Children: collector.ChildrenFlattened, MaxDepth: 1},
+			expected: "a a1 a2 b b1",
+		},
+		{
+			name:     "max bytes keeps the most recent trees",
+			opts:     collector.ReadOptions{MaxBytes: 50},
+			expected: "b(b1)",
+		},
+		{
+			name:     "max bytes counts included children",
+			opts:     collector.ReadOptions{MaxBytes: 50, MaxDepth: 1},
+			expected: "a(a1 a2) b(b1)",
+		},
+		{
+			name:     "max bytes smaller than the most recent tree",
+			opts:     collector.ReadOptions{MaxBytes: 10},
+			expected: "",
+		},
+		{
+			name:     "since skips events started before",
+			opts:     collector.ReadOptions{Since: start},
+			expected: "b(b1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tree(storage.ReadEvents(tt.opts)))
+		})
+	}
+
+	// Trimming copies the events, the stored trees are complete
+	assert.Equal(t, "a(a1(a11) a2) b(b1)", tree(storage.GetEvents(10)))
+}
+
+func TestParseChildrenMode(t *testing.T) {
+	mode, err := collector.ParseChildrenMode("")
+	require.NoError(t, err)
+	assert.Equal(t, collector.ChildrenNested, mode)
+
+	mode, err = collector.ParseChildrenMode("flatten")
+	require.NoError(t, err)
+	assert.Equal(t, collector.ChildrenFlattened, mode)
+
+	_, err = collector.ParseChildrenMode("deep")
+	assert.Error(t, err)
+}
+
+func TestCaptureStorage_Remove(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	child := &collector.Event{ID: uuid.Must(uuid.NewV7()), Size: 10}
+	event := &collector.Event{ID: uuid.Must(uuid.NewV7()), Size: 100, Children: []*collector.Event{child}}
+	storage.Add(event)
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Size: 100})
+
+	// Nested events can't be removed on their own
+	assert.False(t, storage.Remove(child.ID))
+
+	assert.True(t, storage.Remove(event.ID))
+	_, exists := storage.GetEvent(event.ID)
+	assert.False(t, exists)
+	_, exists = storage.GetEvent(child.ID)
+	assert.False(t, exists)
+	assert.Equal(t, uint64(100), storage.Stats().MemoryBytes)
+
+	assert.False(t, storage.Remove(event.ID))
+}
+
+func TestCaptureStorage_ChangesSince(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 3, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := storage.SubscribeChanges(ctx)
+
+	later := &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 2, InProgress: true}
+	storage.Add(later)
+	seen := storage.Revision()
+	// Events that complete after an event that started later are returned by the revision they were added at
+	earlier := &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 1}
+	storage.Add(earlier)
+	completed := &collector.Event{ID: later.ID, Sequence: 2}
+	storage.Add(completed)
+
+	assert.Equal(t, []collector.StoredEvent{
+		{Event: earlier, Revision: 2},
+		{Event: completed, Revision: 3},
+	}, storage.ChangesSince(seen, collector.ReadOptions{}))
+	assert.Empty(t, storage.ChangesSince(storage.Revision(), collector.ReadOptions{}))
+
+	for revision := uint64(1); revision <= 3; revision++ {
+		select {
+		case change := <-changes:
+			assert.Equal(t, revision, change.Revision, "changes should be notified in the order of their revision")
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for change")
+		}
+	}
+
+	// Evicted and removed events are not returned
+	third := &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 3}
+	storage.Add(third)
+	fourth := &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 4}
+	storage.Add(fourth)
+	storage.Remove(earlier.ID)
+	assert.Equal(t, []collector.StoredEvent{
+		{Event: third, Revision: 4},
+		{Event: fourth, Revision: 5},
+	}, storage.ChangesSince(0, collector.ReadOptions{}))
+	// The limit keeps the most recent changes
+	assert.Equal(t, []collector.StoredEvent{
+		{Event: fourth, Revision: 5},
+	}, storage.ChangesSince(0, collector.ReadOptions{Limit: 1}))
+}
+
+func TestCaptureStorage_CaptureLimit_Events(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	storage.SetCaptureLimit(collector.CaptureLimit{Events: 2})
+
+	event := &collector.Event{ID: uuid.Must(uuid.NewV7())}
+	storage.Add(event)
+	// Replacing an event in progress and nested events don't count
+	storage.Add(&collector.Event{ID: event.ID})
+	groupID := event.ID
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), GroupID: &groupID})
+	assert.True(t, storage.IsCapturing())
+	assert.Equal(t, collector.CaptureLimit{Events: 1}, storage.CaptureLimit())
+
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7())})
+	assert.False(t, storage.IsCapturing())
+	assert.False(t, storage.ShouldCapture(context.Background()))
+	assert.True(t, storage.CaptureLimit().IsZero())
+}
+
+func TestCaptureStorage_CaptureLimit_Until(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	storage.SetCaptureLimit(collector.CaptureLimit{Until: time.Now().Add(time.Hour)})
+	assert.True(t, storage.ShouldCapture(context.Background()))
+
+	storage.SetCaptureLimit(collector.CaptureLimit{Until: time.Now().Add(-time.Second)})
+	assert.False(t, storage.ShouldCapture(context.Background()))
+	assert.False(t, storage.IsCapturing())
+	assert.True(t, storage.CaptureLimit().IsZero())
+}
+
+func TestCaptureStorage_SetCapturing_RemovesCaptureLimit(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+
+	storage.SetCaptureLimit(collector.CaptureLimit{Events: 5})
+	storage.SetCapturing(true)
+
+	assert.True(t, storage.CaptureLimit().IsZero())
+}
+
 func TestCaptureStorage_ID(t *testing.T) {
 	sessionID := uuid.Must(uuid.NewV4())
 	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"unsafe"
+)
+
+// FlagEvaluation represents a captured evaluation of a feature flag or lookup of a configuration value
+type FlagEvaluation struct {
+	// Flag is the key of the flag or configuration value
+	Flag string
+	// Value is the evaluated value formatted as string
+	Value string
+	// Variant is the name of the evaluated variant, e.g. "on" (optional)
+	Variant string
+	// Reason why the value was chosen, e.g. "TARGETING_MATCH", "DEFAULT" or "STATIC" in OpenFeature (optional)
+	Reason string
+	// Provider is the name of the flag provider or configuration source, e.g. "flagd" or "env" (optional)
+	Provider string
+	// Timestamp of the evaluation, defaults to the time it was collected
+	Timestamp time.Time
+	// Error if the evaluation failed and the default value was used
+	Error error
+}
+
+// Size returns the estimated memory size of this evaluation in bytes
+func (f FlagEvaluation) Size() uint64 {
+	size := uint64(unsafe.Sizeof(f))
+	size += uint64(len(f.Flag) + len(f.Value) + len(f.Variant) + len(f.Reason) + len(f.Provider))
+	size += errorSize(f.Error)
+	return size
+}
+
+// ActiveFlags returns the last evaluation of each flag in the event and its children sorted by flag, e.g. the flags
+// that were active during a request
+func ActiveFlags(event *Event) []FlagEvaluation {
+	last := make(map[string]FlagEvaluation)
+	for _, evt := range event.Visit() {
+		if evaluation, ok := evt.Data.(FlagEvaluation); ok {
+			last[evaluation.Flag] = evaluation
+		}
+	}
+	flags := make([]FlagEvaluation, 0, len(last))
+	for _, evaluation := range last {
+		flags = append(flags, evaluation)
+	}
+	slices.SortFunc(flags, func(a, b FlagEvaluation) int {
+		return cmp.Compare(a.Flag, b.Flag)
+	})
+	return flags
+}
+
+type FlagEvaluationCollector struct {
+	notifier        *Notifier[FlagEvaluation]
+	eventAggregator *EventAggregator
+}
+
+// Collect collects an evaluation of a flag
+func (c *FlagEvaluationCollector) Collect(ctx context.Context, evaluation FlagEvaluation) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
+	if evaluation.Timestamp.IsZero() {
+		evaluation.Timestamp = time.Now()
+	}
+	c.notifier.Notify(evaluation)
+	if c.eventAggregator != nil {
+		c.eventAggregator.CollectEvent(ctx, evaluation)
+	}
+}
+
+// Subscribe returns a channel that receives notifications of new flag evaluations
+func (c *FlagEvaluationCollector) Subscribe(ctx context.Context) <-chan FlagEvaluation {
+	return c.notifier.Subscribe(ctx)
+}
+
+type FlagEvaluationOptions struct {
+	// NotifierOptions are options for notification about new evaluations
+	NotifierOptions *NotifierOptions
+
+	// EventAggregator is the aggregator for collecting evaluations as grouped events
+	EventAggregator *EventAggregator
+}
+
+func DefaultFlagEvaluationOptions() FlagEvaluationOptions {
+	return FlagEvaluationOptions{}
+}
+
+func NewFlagEvaluationCollector() *FlagEvaluationCollector {
+	return NewFlagEvaluationCollectorWithOptions(DefaultFlagEvaluationOptions())
+}
+
+func NewFlagEvaluationCollectorWithOptions(options FlagEvaluationOptions) *FlagEvaluationCollector {
+	notifierOptions := DefaultNotifierOptions()
+	if options.NotifierOptions != nil {
+		notifierOptions = *options.NotifierOptions
+	}
+
+	return &FlagEvaluationCollector{
+		notifier:        NewNotifierWithOptions[FlagEvaluation](notifierOptions),
+		eventAggregator: options.EventAggregator,
+	}
+}
+
+// Close releases resources used by the collector
+func (c *FlagEvaluationCollector) Close() {
+	c.notifier.Close()
+}
@@ -0,0 +1,47 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestFlagEvaluationCollector_ActiveFlags(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 10, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	flagCollector := collector.NewFlagEvaluationCollectorWithOptions(collector.FlagEvaluationOptions{EventAggregator: aggregator})
+	defer flagCollector.Close()
+
+	ctx := aggregator.StartEvent(context.Background())
+	flagCollector.Collect(ctx, collector.FlagEvaluation{Flag: "theme", Value: "light", Reason: "DEFAULT"})
+	flagCollector.Collect(ctx, collector.FlagEvaluation{Flag: "beta", Value: "false", Error: errors.New("FLAG_NOT_FOUND")})
+
+	childCtx := aggregator.StartEvent(ctx)
+	flagCollector.Collect(childCtx, collector.FlagEvaluation{Flag: "theme", Value: "dark", Variant: "dark", Reason: "TARGETING_MATCH"})
+	aggregator.EndEvent(childCtx, collector.HTTPClientRequest{Method: http.MethodGet, URL: "https://example.com"})
+
+	aggregator.EndEvent(ctx, collector.HTTPServerRequest{Method: http.MethodGet, Path: "/", StatusCode: http.StatusOK})
+
+	events := storage.GetEvents(1)
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Children, 3)
+	assert.Equal(t, collector.EventKindFlagEvaluation, events[0].Children[0].Kind())
+	assert.Equal(t, collector.SeverityWarn, events[0].Children[1].Severity)
+
+	flags := collector.ActiveFlags(events[0])
+	require.Len(t, flags, 2)
+	assert.Equal(t, "beta", flags[0].Flag)
+	assert.Equal(t, "theme", flags[1].Flag)
+	assert.Equal(t, "dark", flags[1].Value, "the last evaluation of a flag is active")
+}
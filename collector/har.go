@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// harFile is the subset of the HTTP Archive (HAR) 1.2 format that is imported
+type harFile struct {
+	Log *struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	// Time is the total duration of the request in milliseconds
+	Time    float64 `json:"time"`
+	Request struct {
+		Method   string      `json:"method"`
+		URL      string      `json:"url"`
+		Headers  []harHeader `json:"headers"`
+		BodySize int64       `json:"bodySize"`
+		PostData *struct {
+			Text string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status   int         `json:"status"`
+		Headers  []harHeader `json:"headers"`
+		BodySize int64       `json:"bodySize"`
+		Content  struct {
+			Size     int64  `json:"size"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DecodeHAR decodes the entries of an HTTP Archive (HAR), e.g. exported from the network tab of a browser, into
+// events with HTTPClientRequest data. The events are ordered like the entries.
+func DecodeHAR(r io.Reader) ([]*Event, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("decoding HAR: %w", err)
+	}
+	if har.Log == nil {
+		return nil, fmt.Errorf("decoding HAR: missing log")
+	}
+
+	events := make([]*Event, 0, len(har.Log.Entries))
+	for i, entry := range har.Log.Entries {
+		responseBody, err := entry.responseBody()
+		if err != nil {
+			return nil, fmt.Errorf("decoding HAR entry %d: %w", i, err)
+		}
+
+		request := HTTPClientRequest{
+			ID:              generateID(),
+			Method:          entry.Request.Method,
+			URL:             entry.Request.URL,
+			RequestTime:     entry.StartedDateTime,
+			ResponseTime:    entry.StartedDateTime.Add(time.Duration(entry.Time * float64(time.Millisecond))),
+			StatusCode:      entry.Response.Status,
+			RequestHeaders:  harHeaders(entry.Request.Headers),
+			ResponseHeaders: harHeaders(entry.Response.Headers),
+			ResponseBody:    responseBody,
+			Tags:            make(map[string]string),
+		}
+		if entry.Request.PostData != nil {
+			request.RequestBody = newImportedBody([]byte(entry.Request.PostData.Text))
+		}
+		request.RequestSize = harSize(entry.Request.BodySize, request.RequestBody)
+		request.ResponseSize = harSize(entry.Response.BodySize, request.ResponseBody)
+
+		event := &Event{
+			ID:       request.ID,
+			Data:     request,
+			Start:    request.RequestTime,
+			End:      request.ResponseTime,
+			Sequence: uint64(i + 1),
+			Severity: DefaultSeverityThresholds().Rate(request),
+		}
+		event.Size = event.calculateSize()
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// responseBody returns the response content of the entry, nil if it was not recorded
+func (e harEntry) responseBody() (*Body, error) {
+	content := e.Response.Content
+	if content.Text == "" {
+		return nil, nil
+	}
+	if content.Encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("decoding response content: %w", err)
+		}
+		return newImportedBody(data), nil
+	}
+	return newImportedBody([]byte(content.Text)), nil
+}
+
+// harHeaders converts HAR headers, names are canonicalized like received headers
+func harHeaders(headers []harHeader) http.Header {
+	h := make(http.Header, len(headers))
+	for _, header := range headers {
+		h.Add(header.Name, header.Value)
+	}
+	return h
+}
+
+// harSize returns the recorded body size, HAR uses -1 if it is unknown
+func harSize(size int64, body *Body) uint64 {
+	if size >= 0 {
+		return uint64(size)
+	}
+	return body.Size()
+}
+
+// newImportedBody returns a fully captured body with content
+func newImportedBody(content []byte) *Body {
+	return newDecodedBody(&bodyJSON{ContentBase64: content, FullyCaptured: true})
+}
@@ -0,0 +1,80 @@
+package collector_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "entries": [
+      {
+        "startedDateTime": "2025-03-01T10:00:00.000Z",
+        "time": 150.5,
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/api/todos",
+          "headers": [{"name": "content-type", "value": "application/json"}],
+          "bodySize": 17,
+          "postData": {"mimeType": "application/json", "text": "{\"title\":\"Test\"}"}
+        },
+        "response": {
+          "status": 201,
+          "headers": [{"name": "Content-Type", "value": "application/json"}],
+          "bodySize": -1,
+          "content": {"size": 8, "mimeType": "application/json", "text": "eyJpZCI6MX0=", "encoding": "base64"}
+        }
+      },
+      {
+        "startedDateTime": "2025-03-01T10:00:01.000Z",
+        "time": 20,
+        "request": {"method": "GET", "url": "https://example.com/", "headers": [], "bodySize": 0},
+        "response": {"status": 304, "headers": [], "bodySize": 0, "content": {"size": 0}}
+      }
+    ]
+  }
+}`
+
+func TestDecodeHAR(t *testing.T) {
+	events, err := collector.DecodeHAR(strings.NewReader(testHAR))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	event := events[0]
+	request, ok := event.Data.(collector.HTTPClientRequest)
+	require.True(t, ok)
+	assert.Equal(t, event.ID, request.ID)
+	assert.Equal(t, uint64(1), event.Sequence)
+	assert.NotZero(t, event.Size)
+	assert.Equal(t, "POST", request.Method)
+	assert.Equal(t, "https://example.com/api/todos", request.URL)
+	assert.Equal(t, 201, request.StatusCode)
+	assert.Equal(t, 150500*time.Microsecond, request.Duration())
+	assert.Equal(t, "application/json", request.RequestHeaders.Get("Content-Type"))
+	assert.Equal(t, `{"title":"Test"}`, string(request.RequestBody.Bytes()))
+	assert.Equal(t, `{"id":1}`, string(request.ResponseBody.Bytes()))
+	assert.Equal(t, uint64(17), request.RequestSize)
+	// The size of the decoded content is used if the body size is unknown
+	assert.Equal(t, uint64(8), request.ResponseSize)
+
+	request = events[1].Data.(collector.HTTPClientRequest)
+	assert.Equal(t, uint64(2), events[1].Sequence)
+	assert.Equal(t, 304, request.StatusCode)
+	assert.Nil(t, request.RequestBody)
+	assert.Nil(t, request.ResponseBody)
+}
+
+func TestDecodeHAR_Invalid(t *testing.T) {
+	_, err := collector.DecodeHAR(strings.NewReader(`{"schemaVersion": 1}`))
+	assert.Error(t, err)
+
+	_, err = collector.DecodeHAR(strings.NewReader(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// EventHook is called with a completed top-level event (with its nested events). The event is shared with other
+// storages and must not be modified. ctx is done when the hook is unregistered.
+type EventHook func(ctx context.Context, event *Event)
+
+// HookOptions configures how events are passed to a hook
+type HookOptions struct {
+	// QueueSize is the number of events waiting for the hook, events are dropped if the queue is full.
+	// Default: 1000
+	QueueSize int
+}
+
+// DefaultHookOptions returns default options for passing events to a hook
+func DefaultHookOptions() HookOptions {
+	return HookOptions{
+		QueueSize: 1000,
+	}
+}
+
+// HookStorage is an EventStorage that calls a hook with all completed events on its own goroutine, oldest first, so a
+// slow hook does not block collecting. It is a SinkStorage exporting batches of a single event to the hook, a panic of
+// the hook is counted as failed export. Register it with EventAggregator.RegisterStorage, it captures all events like a
+// storage in CaptureModeGlobal.
+type HookStorage struct {
+	*SinkStorage
+	sink *hookSink
+}
+
+// NewHookStorage creates a storage calling hook with captured events, zero options use the defaults of
+// DefaultHookOptions
+func NewHookStorage(hook EventHook, options HookOptions) *HookStorage {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultHookOptions().QueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &hookSink{hook: hook, ctx: ctx, cancel: cancel}
+	return &HookStorage{
+		SinkStorage: NewSinkStorage(sink, SinkOptions{
+			BatchSize: 1,
+			QueueSize: options.QueueSize,
+		}),
+		sink: sink,
+	}
+}
+
+// Close cancels the context of the hook and waits until a running call returns, queued events are discarded.
+// It is safe to call Close multiple times.
+func (s *HookStorage) Close() {
+	s.sink.cancel()
+	s.SinkStorage.Close()
+}
+
+// Called returns the number of events the hook was called with
+func (s *HookStorage) Called() uint64 {
+	return s.sink.called.Load()
+}
+
+// hookSink is a Sink calling a hook with each exported event until it is canceled
+type hookSink struct {
+	hook   EventHook
+	ctx    context.Context
+	cancel context.CancelFunc
+	called atomic.Uint64
+}
+
+// Export implements Sink, the context of the export is replaced by the context of the hook, which is only canceled
+// when the storage is closed
+func (h *hookSink) Export(_ context.Context, events []*Event) error {
+	for _, event := range events {
+		// Events queued when closing are discarded
+		if h.ctx.Err() != nil {
+			return nil
+		}
+		h.hook(h.ctx, event)
+		h.called.Add(1)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (h *hookSink) Close() error {
+	h.cancel()
+	return nil
+}
@@ -0,0 +1,86 @@
+package collector_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHookStorage_CallsHook(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		called []any
+	)
+	storage := collector.NewHookStorage(func(ctx context.Context, event *collector.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		called = append(called, event.Data)
+	}, collector.HookOptions{})
+
+	aggregator := collector.NewEventAggregator()
+	aggregator.RegisterStorage(storage)
+	defer aggregator.Close()
+
+	ctx := context.Background()
+	for _, data := range []string{"first", "second", "third"} {
+		aggregator.CollectEvent(ctx, data)
+	}
+	// Previews of events in progress are not passed to the hook
+	storage.Add(&collector.Event{Data: "preview", InProgress: true})
+
+	assert.Eventually(t, func() bool {
+		return storage.Called() == 3
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []any{"first", "second", "third"}, called, "events should be passed oldest first")
+	mu.Unlock()
+
+	// Events are not captured after closing
+	storage.Close()
+	storage.Close()
+	assert.False(t, storage.ShouldCapture(ctx))
+}
+
+func TestHookStorage_Close(t *testing.T) {
+	started := make(chan struct{})
+	var canceled bool
+	storage := collector.NewHookStorage(func(ctx context.Context, event *collector.Event) {
+		close(started)
+		<-ctx.Done()
+		canceled = true
+	}, collector.HookOptions{QueueSize: 1})
+
+	storage.Add(&collector.Event{Data: "blocking"})
+	<-started
+	storage.Add(&collector.Event{Data: "queued"})
+	storage.Add(&collector.Event{Data: "dropped"})
+
+	// Closing cancels the running call and discards queued events
+	storage.Close()
+	assert.True(t, canceled)
+	assert.Equal(t, uint64(1), storage.Called())
+	assert.Equal(t, uint64(1), storage.Dropped())
+}
+
+func TestHookStorage_Panic(t *testing.T) {
+	storage := collector.NewHookStorage(func(ctx context.Context, event *collector.Event) {
+		if event.Data == "panic" {
+			panic("broken hook")
+		}
+	}, collector.DefaultHookOptions())
+	defer storage.Close()
+
+	storage.Add(&collector.Event{Data: "panic"})
+	storage.Add(&collector.Event{Data: "event"})
+
+	require.Eventually(t, func() bool {
+		return storage.Called() == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), storage.Failed())
+}
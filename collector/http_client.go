@@ -12,6 +12,12 @@ type HTTPClientOptions struct {
 	// MaxBodySize is the maximum size in bytes of a single body
 	MaxBodySize int
 
+	// MaxBodySizeByContentType overrides MaxBodySize for bodies by their Content-Type, e.g. {"application/json": 1 << 20,
+	// "application/octet-stream": 4096, "video/*": 0}. Keys are media types or patterns of all subtypes ("video/*"),
+	// exact media types take precedence. A size of 0 does not capture bodies of the type.
+	// Default: nil, MaxBodySize applies to all bodies
+	MaxBodySizeByContentType map[string]int
+
 	// CaptureRequestBody indicates whether to capture request bodies
 	CaptureRequestBody bool
 
@@ -21,11 +27,26 @@ type HTTPClientOptions struct {
 	// Transformers are functions that transform/augment the HTTPClientRequest before adding it to the collector
 	Transformers []HTTPClientRequestTransformer
 
+	// Async enables running transformers and the dispatch of collected requests on a worker pool,
+	// so expensive transformers don't add latency to requests.
+	// Default: nil, requests are processed synchronously
+	Async *AsyncOptions
+
 	// NotifierOptions are options for notification about new requests
 	NotifierOptions *NotifierOptions
 
 	// EventAggregator is the aggregator for collecting requests as grouped events
 	EventAggregator *EventAggregator
+
+	// AttemptGroupKey returns a key to group retries of one logical call (see AttemptGroupByHeader).
+	// Requests with the same non-empty key are numbered as attempts. A group set with WithAttemptGroup takes precedence.
+	// Default: nil, only requests made with WithAttemptGroup are grouped
+	AttemptGroupKey func(req *http.Request) string
+
+	// BodyBufferPool limits the memory of captured bodies across collectors that share it, the least recently written
+	// bodies are evicted if the limit is exceeded (see BodyBufferPool).
+	// Default: nil, the memory of bodies is only limited by MaxBodySize per body
+	BodyBufferPool *BodyBufferPool
 }
 
 type HTTPClientRequestTransformer func(HTTPClientRequest) HTTPClientRequest
@@ -44,6 +65,9 @@ type HTTPClientCollector struct {
 	options         HTTPClientOptions
 	notifier        *Notifier[HTTPClientRequest]
 	eventAggregator *EventAggregator
+	async           *workerPool
+	attemptGroups   *attemptGroupsByKey
+	bodySizes       bodySizeLimits
 }
 
 // NewHTTPClientCollector creates a new collector for outgoing HTTP requests
@@ -58,18 +82,29 @@ func NewHTTPClientCollectorWithOptions(options HTTPClientOptions) *HTTPClientCol
 		notifierOptions = *options.NotifierOptions
 	}
 
-	return &HTTPClientCollector{
+	c := &HTTPClientCollector{
 		options:         options,
 		notifier:        NewNotifierWithOptions[HTTPClientRequest](notifierOptions),
 		eventAggregator: options.EventAggregator,
+		attemptGroups:   newAttemptGroupsByKey(),
+		bodySizes:       newBodySizeLimits(options.MaxBodySize, options.MaxBodySizeByContentType),
+	}
+	if options.Async != nil {
+		c.async = newWorkerPool(*options.Async)
 	}
+	return c
 }
 
-// Transport returns an http.RoundTripper that captures request/response data
+// Transport returns an http.RoundTripper that captures request/response data.
+// The returned transport implements Unwrap to reach next. If next is already wrapped by this collector, it is returned
+// unchanged, so requests are not captured twice.
 func (c *HTTPClientCollector) Transport(next http.RoundTripper) http.RoundTripper {
 	if next == nil {
 		next = http.DefaultTransport
 	}
+	if wrapsTransport(next, c) {
+		return next
+	}
 
 	return &httpClientTransport{
 		next:      next,
@@ -89,6 +124,9 @@ func (c *HTTPClientCollector) Add(req HTTPClientRequest) {
 
 // Close releases resources used by the collector
 func (c *HTTPClientCollector) Close() {
+	if c.async != nil {
+		c.async.close()
+	}
 	c.notifier.Close()
 }
 
@@ -99,6 +137,11 @@ type httpClientTransport struct {
 }
 
 func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Pass through without any work if collecting is disabled at runtime
+	if !t.collector.eventAggregator.Enabled() {
+		return t.next.RoundTrip(req)
+	}
+
 	ctx := req.Context()
 
 	// Check if we should capture this request (using EventAggregator)
@@ -109,8 +152,8 @@ func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, erro
 	}
 	// Note: EventCollector (deprecated) is always-capture, so no change needed
 
-	// Early bailout if not capturing - just pass through
-	if !shouldCapture {
+	// Early bailout if not capturing or for requests of devlog itself - just pass through
+	if !shouldCapture || req.Header.Get(InternalRequestHeader) != "" {
 		return t.next.RoundTrip(req)
 	}
 
@@ -127,18 +170,27 @@ func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, erro
 		URL:            req.URL.String(),
 		RequestTime:    requestTime,
 		RequestHeaders: req.Header.Clone(),
+		DerivedFrom:    derivedFromContext(ctx),
 		Tags:           make(map[string]string),
 	}
 
+	// Number retries of one logical call
+	group := t.collector.attemptGroupFor(req)
+	if group != nil {
+		httpReq.AttemptGroupID = group.id
+		httpReq.Attempt, httpReq.PreviousAttempts = group.start()
+	}
+
 	// Track the original request body size
 	if req.ContentLength > 0 {
 		httpReq.RequestSize = uint64(req.ContentLength)
 	}
 
 	// Capture request body if present and configured to do so
-	if req.Body != nil && t.collector.options.CaptureRequestBody {
+	maxRequestBodySize := t.collector.bodySizes.forContentType(req.Header.Get("Content-Type"))
+	if req.Body != nil && t.collector.options.CaptureRequestBody && maxRequestBodySize > 0 {
 		// Wrap the body to capture it
-		body := NewBody(req.Body, t.collector.options.MaxBodySize)
+		body := t.collector.options.BodyBufferPool.NewBody(req.Body, maxRequestBodySize)
 
 		// Store the body in the request record
 		httpReq.RequestBody = body
@@ -148,15 +200,24 @@ func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, erro
 	}
 
 	// Start event tracking with EventAggregator
+	var eventCtx context.Context
+	completed := false
 	if t.collector.eventAggregator != nil {
-		newCtx := t.collector.eventAggregator.StartEvent(ctx)
-		defer func(req *HTTPClientRequest) {
-			t.collector.eventAggregator.EndEvent(newCtx, *req)
-		}(&httpReq)
+		eventCtx = t.collector.eventAggregator.StartEvent(ctx)
+		// End the event even if the transport panics
+		defer func() {
+			if !completed {
+				t.collector.eventAggregator.EndEvent(eventCtx, httpReq)
+			}
+		}()
 
-		req = req.WithContext(newCtx)
+		req = req.WithContext(eventCtx)
 	}
 
+	// Record the phases of the request to classify errors
+	var phases httpClientPhaseTracker
+	req = req.WithContext(phases.withTrace(req.Context()))
+
 	// Perform the actual request
 	resp, err := t.next.RoundTrip(req)
 
@@ -177,12 +238,13 @@ func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, erro
 		}
 
 		// Capture response body if present and configured to do so
-		if resp.Body != nil && t.collector.options.CaptureResponseBody {
+		maxResponseBodySize := t.collector.bodySizes.forContentType(resp.Header.Get("Content-Type"))
+		if resp.Body != nil && t.collector.options.CaptureResponseBody && maxResponseBodySize > 0 {
 			// Create a copy of the response to read the body even if the client doesn't
 			originalRespBody := resp.Body
 
 			// Wrap the body to capture it
-			body := NewBody(originalRespBody, t.collector.options.MaxBodySize)
+			body := t.collector.options.BodyBufferPool.NewBody(originalRespBody, maxResponseBodySize)
 
 			// Store the body in the request record
 			httpReq.ResponseBody = body
@@ -195,20 +257,88 @@ func (t *httpClientTransport) RoundTrip(req *http.Request) (*http.Response, erro
 	// Record error if present
 	if err != nil {
 		httpReq.Error = err
+		deadline, hasDeadline := req.Context().Deadline()
+		deadlineExceeded := hasDeadline && !time.Now().Before(deadline)
+		httpReq.ErrorInfo = classifyHTTPClientError(err, phases.current(), req.URL.Host, deadlineExceeded)
 	}
 
-	// Transform the request if any transformers are provided
-	for _, transformer := range t.collector.options.Transformers {
-		httpReq = transformer(httpReq)
+	if group != nil {
+		group.finish(HTTPClientAttempt{
+			RequestID:  httpReq.ID,
+			Attempt:    httpReq.Attempt,
+			StatusCode: httpReq.StatusCode,
+			Duration:   httpReq.Duration(),
+			Error:      httpReq.Error,
+		})
 	}
 
-	// Add the request to the collector
-	t.collector.Add(httpReq)
+	completed = true
+	t.collector.complete(eventCtx, httpReq)
 
 	return resp, err
 }
 
-// Unwrap returns the underlying http.RoundTripper
+// complete transforms and adds a collected request and ends its event.
+// With async processing, this happens on the worker pool and the request is dropped if the queue is full.
+func (c *HTTPClientCollector) complete(eventCtx context.Context, httpReq HTTPClientRequest) {
+	process := func() {
+		// Transform the request if any transformers are provided, a panicking transformer is skipped
+		for _, transformer := range c.options.Transformers {
+			httpReq = transformSafely(c.eventAggregator, eventCtx, "http_client_transformer", transformer, httpReq)
+		}
+
+		// Add the request to the collector
+		c.Add(httpReq)
+
+		if eventCtx != nil {
+			c.eventAggregator.endEvent(eventCtx, httpReq, httpReq.ResponseTime)
+		}
+	}
+
+	if c.async == nil {
+		process()
+		return
+	}
+	if !c.async.submit(process) {
+		c.eventAggregator.Logger().Warn("Dropped HTTP client request, async queue is full")
+		if eventCtx != nil {
+			c.eventAggregator.discardEvent(eventCtx)
+		}
+	}
+}
+
+// DroppedRequests returns the number of requests dropped because the async queue was full
+func (c *HTTPClientCollector) DroppedRequests() uint64 {
+	if c.async == nil {
+		return 0
+	}
+	return c.async.dropped.Load()
+}
+
+// Unwrap returns the underlying http.RoundTripper, so other code can reach through the transport chain
+// (e.g. to configure the *http.Transport)
 func (t *httpClientTransport) Unwrap() http.RoundTripper {
 	return t.next
 }
+
+// IsCapturingTransport checks if rt was returned by HTTPClientCollector.Transport or wraps such a transport.
+// Transports of the chain are reached with an Unwrap() http.RoundTripper method. Use it to avoid wrapping a transport
+// twice.
+func IsCapturingTransport(rt http.RoundTripper) bool {
+	return wrapsTransport(rt, nil)
+}
+
+// wrapsTransport checks if rt or a transport it wraps captures requests with the collector, or any collector if c is nil
+func wrapsTransport(rt http.RoundTripper, c *HTTPClientCollector) bool {
+	for rt != nil {
+		if transport, ok := rt.(*httpClientTransport); ok && (c == nil || transport.collector == c) {
+			return true
+		}
+		unwrapper, ok := rt.(interface{ Unwrap() http.RoundTripper })
+		if !ok {
+			return false
+		}
+		rt = unwrapper.Unwrap()
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// HTTPClientErrorCategory classifies why an outgoing request failed
+type HTTPClientErrorCategory string
+
+const (
+	// HTTPClientErrorTimeout is a request that exceeded a deadline (e.g. http.Client.Timeout or of the context)
+	HTTPClientErrorTimeout HTTPClientErrorCategory = "timeout"
+	// HTTPClientErrorCanceled is a request canceled by its context
+	HTTPClientErrorCanceled HTTPClientErrorCategory = "canceled"
+	// HTTPClientErrorDNS is a failed lookup of the host
+	HTTPClientErrorDNS HTTPClientErrorCategory = "dns"
+	// HTTPClientErrorConnectionRefused is a connection refused by the host, usually nothing listens on the port
+	HTTPClientErrorConnectionRefused HTTPClientErrorCategory = "connection_refused"
+	// HTTPClientErrorConnectionReset is a connection reset or aborted by the other side
+	HTTPClientErrorConnectionReset HTTPClientErrorCategory = "connection_reset"
+	// HTTPClientErrorConnectionClosed is a connection closed by the server before it sent a response
+	HTTPClientErrorConnectionClosed HTTPClientErrorCategory = "connection_closed"
+	// HTTPClientErrorUnreachable is a host or network without a route to it
+	HTTPClientErrorUnreachable HTTPClientErrorCategory = "unreachable"
+	// HTTPClientErrorTLS is a failed TLS handshake or certificate verification
+	HTTPClientErrorTLS HTTPClientErrorCategory = "tls"
+	// HTTPClientErrorOther is any other error
+	HTTPClientErrorOther HTTPClientErrorCategory = "other"
+)
+
+// HTTPClientPhase is a phase of an outgoing request as reported by net/http/httptrace
+type HTTPClientPhase string
+
+const (
+	HTTPClientPhaseDNS          HTTPClientPhase = "dns"
+	HTTPClientPhaseConnect      HTTPClientPhase = "connect"
+	HTTPClientPhaseTLSHandshake HTTPClientPhase = "tls_handshake"
+	HTTPClientPhaseWriteRequest HTTPClientPhase = "write_request"
+	HTTPClientPhaseWaitResponse HTTPClientPhase = "wait_response"
+	HTTPClientPhaseReadResponse HTTPClientPhase = "read_response"
+)
+
+// Reasons of HTTPClientErrorInfo for DNS and TLS errors
+const (
+	HTTPClientReasonHostNotFound     = "host_not_found"
+	HTTPClientReasonDNSTimeout       = "dns_timeout"
+	HTTPClientReasonUnknownAuthority = "unknown_authority"
+	HTTPClientReasonHostnameMismatch = "hostname_mismatch"
+	HTTPClientReasonExpired          = "expired"
+	HTTPClientReasonInvalidCert      = "invalid_certificate"
+	HTTPClientReasonHTTPResponse     = "http_response"
+)
+
+// HTTPClientErrorInfo describes why an outgoing request failed
+type HTTPClientErrorInfo struct {
+	Category HTTPClientErrorCategory `json:"category"`
+	// Phase is the last phase the request reached before it failed, empty if the transport does not report phases
+	Phase HTTPClientPhase `json:"phase,omitempty"`
+	// Errno is the name of the system error (e.g. "ECONNREFUSED"), empty if there is none
+	Errno string `json:"errno,omitempty"`
+	// Host is the looked up host or the address that was connected to, the host of the URL if it is unknown
+	Host string `json:"host,omitempty"`
+	// Reason refines DNS and TLS errors (e.g. HTTPClientReasonHostNotFound or HTTPClientReasonUnknownAuthority)
+	Reason string `json:"reason,omitempty"`
+}
+
+// size returns the estimated memory size of the info
+func (i *HTTPClientErrorInfo) size() uint64 {
+	if i == nil {
+		return 0
+	}
+	return uint64(unsafe.Sizeof(*i)) + uint64(len(i.Category)+len(i.Phase)+len(i.Errno)+len(i.Host)+len(i.Reason))
+}
+
+// errnoNames are the names of system errors of failed connections
+var errnoNames = map[syscall.Errno]string{
+	syscall.ECONNREFUSED:  "ECONNREFUSED",
+	syscall.ECONNRESET:    "ECONNRESET",
+	syscall.ECONNABORTED:  "ECONNABORTED",
+	syscall.EPIPE:         "EPIPE",
+	syscall.ETIMEDOUT:     "ETIMEDOUT",
+	syscall.EHOSTUNREACH:  "EHOSTUNREACH",
+	syscall.ENETUNREACH:   "ENETUNREACH",
+	syscall.EADDRNOTAVAIL: "EADDRNOTAVAIL",
+}
+
+// classifyHTTPClientError classifies the error of an outgoing request to host (the host of the URL) that failed in
+// phase. deadlineExceeded is set if the deadline of the request context passed, since a request of an http.Client
+// with a timeout can also fail with a plain "request canceled" error.
+func classifyHTTPClientError(err error, phase HTTPClientPhase, host string, deadlineExceeded bool) *HTTPClientErrorInfo {
+	info := &HTTPClientErrorInfo{Category: HTTPClientErrorOther, Phase: phase, Host: host}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Addr != nil {
+		info.Host = opErr.Addr.String()
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		info.Errno = errnoNames[errno]
+	}
+
+	var (
+		dnsErr           *net.DNSError
+		certErr          *tls.CertificateVerificationError
+		unknownAuthority x509.UnknownAuthorityError
+		hostnameErr      x509.HostnameError
+		invalidCert      x509.CertificateInvalidError
+		alertErr         tls.AlertError
+		recordHeaderErr  tls.RecordHeaderError
+		netErr           net.Error
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		info.Category = HTTPClientErrorCanceled
+	case errors.As(err, &dnsErr):
+		info.Category = HTTPClientErrorDNS
+		info.Host = dnsErr.Name
+		if dnsErr.IsNotFound {
+			info.Reason = HTTPClientReasonHostNotFound
+		} else if dnsErr.IsTimeout {
+			info.Reason = HTTPClientReasonDNSTimeout
+		}
+	case errors.As(err, &unknownAuthority):
+		info.Category, info.Reason = HTTPClientErrorTLS, HTTPClientReasonUnknownAuthority
+	case errors.As(err, &hostnameErr):
+		info.Category, info.Reason = HTTPClientErrorTLS, HTTPClientReasonHostnameMismatch
+	case errors.As(err, &invalidCert):
+		info.Category, info.Reason = HTTPClientErrorTLS, HTTPClientReasonInvalidCert
+		if invalidCert.Reason == x509.Expired {
+			info.Reason = HTTPClientReasonExpired
+		}
+	case errors.As(err, &certErr):
+		info.Category, info.Reason = HTTPClientErrorTLS, HTTPClientReasonInvalidCert
+	case strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") || errors.As(err, &recordHeaderErr):
+		// The transport returns a plain error if the server answered the handshake with HTTP
+		info.Category, info.Reason = HTTPClientErrorTLS, HTTPClientReasonHTTPResponse
+	case errors.As(err, &alertErr):
+		info.Category = HTTPClientErrorTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		info.Category = HTTPClientErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+		info.Category = HTTPClientErrorConnectionReset
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		info.Category = HTTPClientErrorUnreachable
+	case deadlineExceeded, errors.Is(err, context.DeadlineExceeded), errors.Is(err, syscall.ETIMEDOUT), errors.As(err, &netErr) && netErr.Timeout():
+		info.Category = HTTPClientErrorTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		info.Category = HTTPClientErrorConnectionClosed
+	case phase == HTTPClientPhaseTLSHandshake:
+		info.Category = HTTPClientErrorTLS
+	}
+	return info
+}
+
+// httpClientPhaseTracker records the last phase of an outgoing request with an httptrace.ClientTrace
+type httpClientPhaseTracker struct {
+	mu    sync.Mutex
+	phase HTTPClientPhase
+}
+
+func (t *httpClientPhaseTracker) set(phase HTTPClientPhase) {
+	t.mu.Lock()
+	t.phase = phase
+	t.mu.Unlock()
+}
+
+// current returns the last phase the request reached
+func (t *httpClientPhaseTracker) current() HTTPClientPhase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phase
+}
+
+// withTrace returns a context that reports the phases of a request to the tracker, in addition to a trace of ctx
+func (t *httpClientPhaseTracker) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.set(HTTPClientPhaseDNS) },
+		ConnectStart:         func(string, string) { t.set(HTTPClientPhaseConnect) },
+		TLSHandshakeStart:    func() { t.set(HTTPClientPhaseTLSHandshake) },
+		GotConn:              func(httptrace.GotConnInfo) { t.set(HTTPClientPhaseWriteRequest) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(HTTPClientPhaseWaitResponse) },
+		GotFirstResponseByte: func() { t.set(HTTPClientPhaseReadResponse) },
+	})
+}
@@ -3,6 +3,7 @@ package collector
 import (
 	"net/http"
 	"time"
+	"unsafe"
 
 	"github.com/gofrs/uuid"
 )
@@ -21,9 +22,20 @@ type HTTPClientRequest struct {
 	ResponseHeaders http.Header
 	RequestBody     *Body
 	ResponseBody    *Body
+	// AttemptGroupID identifies the retries of one logical call, zero if the request is not grouped
+	AttemptGroupID uuid.UUID
+	// Attempt is the number of this attempt in its attempt group starting at 1, 0 if the request is not grouped
+	Attempt int
+	// PreviousAttempts are the results of attempts in the group that completed before this attempt started
+	PreviousAttempts []HTTPClientAttempt
+	// DerivedFrom is the ID of the event this request was derived from (e.g. a request edited and replayed in the
+	// dashboard), zero if it was not derived (see WithDerivedFrom)
+	DerivedFrom uuid.UUID
 	// Tags are custom tags that can be used to categorize requests
 	Tags  map[string]string
 	Error error
+	// ErrorInfo classifies Error (e.g. a failed DNS lookup or a refused connection), nil if the request did not fail
+	ErrorInfo *HTTPClientErrorInfo
 }
 
 // Duration returns the duration of the request
@@ -31,21 +43,21 @@ func (r HTTPClientRequest) Duration() time.Duration {
 	return r.ResponseTime.Sub(r.RequestTime)
 }
 
-// Size returns the estimated memory size of this request in bytes
+// Size returns the estimated memory size of this request in bytes, including the buffers of captured bodies
 func (r HTTPClientRequest) Size() uint64 {
-	size := uint64(200) // base struct overhead
+	size := uint64(unsafe.Sizeof(r))
 	size += uint64(len(r.URL) + len(r.Method))
 	size += headersSize(r.RequestHeaders)
 	size += headersSize(r.ResponseHeaders)
-	if r.RequestBody != nil {
-		size += r.RequestBody.Size()
-	}
-	if r.ResponseBody != nil {
-		size += r.ResponseBody.Size()
-	}
-	for k, v := range r.Tags {
-		size += uint64(len(k) + len(v))
+	size += r.RequestBody.memorySize()
+	size += r.ResponseBody.memorySize()
+	size += uint64(cap(r.PreviousAttempts)) * uint64(unsafe.Sizeof(HTTPClientAttempt{}))
+	for _, attempt := range r.PreviousAttempts {
+		size += errorSize(attempt.Error)
 	}
+	size += tagsSize(r.Tags)
+	size += errorSize(r.Error)
+	size += r.ErrorInfo.size()
 	return size
 }
 
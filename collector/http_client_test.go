@@ -1,13 +1,19 @@
 package collector_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -93,3 +99,357 @@ func (b *BodyReadTracker) Read(p []byte) (int, error) {
 func (b *BodyReadTracker) Close() error {
 	return nil
 }
+
+func TestHTTPClientCollector_AttemptGroup(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpCollector := collector.NewHTTPClientCollector()
+	collect := Collect(t, httpCollector.Subscribe)
+
+	client := &http.Client{
+		Transport: httpCollector.Transport(nil),
+	}
+
+	// Retry like a retrying client would do with the same context
+	ctx := collector.WithAttemptGroup(context.Background())
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+	}
+
+	// An unrelated request is not grouped
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	requests := collect.Stop()
+	require.Len(t, requests, 4)
+
+	groupID := requests[0].AttemptGroupID
+	assert.False(t, groupID.IsNil())
+	for i, req := range requests[:3] {
+		assert.Equal(t, groupID, req.AttemptGroupID)
+		assert.Equal(t, i+1, req.Attempt)
+		assert.Len(t, req.PreviousAttempts, i)
+	}
+
+	last := requests[2]
+	assert.Equal(t, http.StatusOK, last.StatusCode)
+	require.Len(t, last.PreviousAttempts, 2)
+	assert.Equal(t, requests[0].ID, last.PreviousAttempts[0].RequestID)
+	assert.Equal(t, 1, last.PreviousAttempts[0].Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, last.PreviousAttempts[0].StatusCode)
+	assert.Equal(t, 2, last.PreviousAttempts[1].Attempt)
+
+	assert.True(t, requests[3].AttemptGroupID.IsNil())
+	assert.Equal(t, 0, requests[3].Attempt)
+}
+
+func TestHTTPClientCollector_DerivedFrom(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	httpCollector := collector.NewHTTPClientCollector()
+	collect := Collect(t, httpCollector.Subscribe)
+
+	client := &http.Client{
+		Transport: httpCollector.Transport(nil),
+	}
+
+	originalID := uuid.Must(uuid.NewV7())
+	req, err := http.NewRequestWithContext(collector.WithDerivedFrom(context.Background(), originalID), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	requests := collect.Stop()
+	require.Len(t, requests, 2)
+	assert.Equal(t, originalID, requests[0].DerivedFrom)
+	assert.True(t, requests[1].DerivedFrom.IsNil())
+}
+
+func TestHTTPClientCollector_AttemptGroupByHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	options := collector.DefaultHTTPClientOptions()
+	options.AttemptGroupKey = collector.AttemptGroupByHeader("Idempotency-Key")
+	httpCollector := collector.NewHTTPClientCollectorWithOptions(options)
+	collect := Collect(t, httpCollector.Subscribe)
+
+	client := &http.Client{
+		Transport: httpCollector.Transport(nil),
+	}
+
+	for _, key := range []string{"a", "b", "a", ""} {
+		req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	requests := collect.Stop()
+	require.Len(t, requests, 4)
+
+	assert.Equal(t, 1, requests[0].Attempt)
+	assert.Equal(t, 1, requests[1].Attempt)
+	assert.NotEqual(t, requests[0].AttemptGroupID, requests[1].AttemptGroupID)
+	assert.Equal(t, requests[0].AttemptGroupID, requests[2].AttemptGroupID)
+	assert.Equal(t, 2, requests[2].Attempt)
+	assert.True(t, requests[3].AttemptGroupID.IsNil())
+}
+
+func TestHTTPClientCollector_WrappedTwice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpCollector := collector.NewHTTPClientCollector()
+	collect := Collect(t, httpCollector.Subscribe)
+
+	assert.False(t, collector.IsCapturingTransport(http.DefaultTransport))
+	transport := httpCollector.Transport(nil)
+	assert.True(t, collector.IsCapturingTransport(transport))
+
+	// Another transport in between is reached through with Unwrap
+	other := &unwrappingTransport{next: transport}
+	assert.True(t, collector.IsCapturingTransport(other))
+	assert.Same(t, other, httpCollector.Transport(other), "wrapping the same transport again should be a no-op")
+
+	client := &http.Client{Transport: httpCollector.Transport(other)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Len(t, collect.Stop(), 1)
+}
+
+// unwrappingTransport is a transport of another library that exposes the transport it wraps
+type unwrappingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *unwrappingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req)
+}
+
+func (t *unwrappingTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+func TestHTTPClientCollector_InternalRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	httpCollector := collector.NewHTTPClientCollector()
+	collect := Collect(t, httpCollector.Subscribe)
+
+	client := &http.Client{Transport: httpCollector.Transport(nil)}
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(collector.InternalRequestHeader, "webhook")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, collect.Stop())
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPClientCollector_Disabled(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPClientOptions()
+	options.EventAggregator = aggregator
+	clientCollector := collector.NewHTTPClientCollectorWithOptions(options)
+
+	var sent *http.Request
+	transport := clientCollector.Transport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+
+	aggregator.SetEnabled(false)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/disabled", strings.NewReader("body"))
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Same(t, req, sent, "the request is passed through unchanged")
+	assert.Empty(t, storage.GetEvents(10))
+}
+
+func TestHTTPClientCollector_ErrorInfo(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slowServer.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+		timeout   time.Duration
+		url       string
+		expected  collector.HTTPClientErrorInfo
+	}{
+		{
+			name:     "connection refused",
+			url:      "http://" + closedAddr + "/",
+			expected: collector.HTTPClientErrorInfo{Category: collector.HTTPClientErrorConnectionRefused, Phase: collector.HTTPClientPhaseConnect, Errno: "ECONNREFUSED", Host: closedAddr},
+		},
+		{
+			name:     "unknown certificate authority",
+			url:      tlsServer.URL,
+			expected: collector.HTTPClientErrorInfo{Category: collector.HTTPClientErrorTLS, Phase: collector.HTTPClientPhaseTLSHandshake, Host: tlsServer.Listener.Addr().String(), Reason: collector.HTTPClientReasonUnknownAuthority},
+		},
+		{
+			name:     "timeout",
+			timeout:  50 * time.Millisecond,
+			url:      slowServer.URL,
+			expected: collector.HTTPClientErrorInfo{Category: collector.HTTPClientErrorTimeout, Phase: collector.HTTPClientPhaseWaitResponse, Host: slowServer.Listener.Addr().String()},
+		},
+		{
+			name: "host not found",
+			transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true}}
+			}),
+			url:      "http://api.invalid/users",
+			expected: collector.HTTPClientErrorInfo{Category: collector.HTTPClientErrorDNS, Host: "api.invalid", Reason: collector.HTTPClientReasonHostNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpCollector := collector.NewHTTPClientCollector()
+			collect := Collect(t, httpCollector.Subscribe)
+
+			client := &http.Client{
+				Transport: httpCollector.Transport(tt.transport),
+				Timeout:   tt.timeout,
+			}
+			_, err := client.Get(tt.url)
+			require.Error(t, err)
+
+			requests := collect.Stop()
+			require.Len(t, requests, 1)
+			require.Error(t, requests[0].Error)
+			require.NotNil(t, requests[0].ErrorInfo)
+			assert.Equal(t, tt.expected, *requests[0].ErrorInfo)
+		})
+	}
+}
+
+func TestHTTPClientCollector_ErrorInfo_RoundTrip(t *testing.T) {
+	info := &collector.HTTPClientErrorInfo{Category: collector.HTTPClientErrorConnectionRefused, Phase: collector.HTTPClientPhaseConnect, Errno: "ECONNREFUSED", Host: "127.0.0.1:8080"}
+	event := &collector.Event{
+		ID: uuid.Must(uuid.NewV7()),
+		Data: collector.HTTPClientRequest{
+			ID:        uuid.Must(uuid.NewV4()),
+			Method:    http.MethodGet,
+			URL:       "http://127.0.0.1:8080/",
+			Error:     errors.New("dial tcp 127.0.0.1:8080: connect: connection refused"),
+			ErrorInfo: info,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, collector.JSONEventCodec.NewEncoder(&buf).Encode(event))
+	decoded, err := collector.JSONEventCodec.NewDecoder(&buf).Decode()
+	require.NoError(t, err)
+
+	request, ok := decoded.Data.(collector.HTTPClientRequest)
+	require.True(t, ok, "expected HTTPClientRequest, got %T", decoded.Data)
+	assert.EqualError(t, request.Error, "dial tcp 127.0.0.1:8080: connect: connection refused")
+	assert.Equal(t, info, request.ErrorInfo)
+}
+
+func TestHTTPClientCollector_MaxBodySizeByContentType(t *testing.T) {
+	content := "0123456789abcdef"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	options := collector.DefaultHTTPClientOptions()
+	options.MaxBodySize = 8
+	options.MaxBodySizeByContentType = map[string]int{
+		"application/json": 100,
+		"image/*":          0,
+	}
+	httpCollector := collector.NewHTTPClientCollectorWithOptions(options)
+	client := &http.Client{Transport: httpCollector.Transport(nil)}
+
+	tests := []struct {
+		contentType      string
+		expectedCaptured string
+	}{
+		{contentType: "application/json", expectedCaptured: content},
+		{contentType: "image/png", expectedCaptured: ""},
+		{contentType: "text/plain", expectedCaptured: "01234567"},
+	}
+	for _, tt := range tests {
+		collect := Collect(t, httpCollector.Subscribe)
+
+		resp, err := client.Post(server.URL, tt.contentType, strings.NewReader(content))
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		requests := collect.Stop()
+		require.Len(t, requests, 1)
+		assert.Equal(t, tt.expectedCaptured, requests[0].RequestBody.String(), "request body of %q", tt.contentType)
+		assert.Equal(t, tt.expectedCaptured, requests[0].ResponseBody.String(), "response body of %q", tt.contentType)
+	}
+}
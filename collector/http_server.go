@@ -3,10 +3,15 @@ package collector
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -21,11 +26,26 @@ func parseUUID(s string) (uuid.UUID, error) {
 // Each session gets its own cookie named "devlog_session_{uuid}".
 const SessionCookiePrefix = "devlog_session_"
 
+// InternalRequestHeader marks requests sent by devlog itself (e.g. webhooks of notification rules). They are not
+// captured by the HTTP server and client collectors, so devlog never captures its own traffic, even if a webhook
+// calls the application.
+const InternalRequestHeader = "X-Devlog-Internal"
+
+// SessionHeader is the request header to tag requests of clients without cookie support (e.g. CLI tools) with capture sessions.
+// It is equivalent to a session cookie and can contain multiple comma separated session IDs.
+const SessionHeader = "X-Devlog-Session"
+
 // HTTPServerOptions configures the HTTP server collector
 type HTTPServerOptions struct {
 	// MaxBodySize is the maximum size in bytes of a single body
 	MaxBodySize int
 
+	// MaxBodySizeByContentType overrides MaxBodySize for bodies by their Content-Type, e.g. {"application/json": 1 << 20,
+	// "application/octet-stream": 4096, "video/*": 0}. Keys are media types or patterns of all subtypes like in
+	// SkipContentTypes, exact media types take precedence. A size of 0 does not capture bodies of the type.
+	// Default: nil, MaxBodySize applies to all bodies
+	MaxBodySizeByContentType map[string]int
+
 	// CaptureRequestBody indicates whether to capture request bodies
 	CaptureRequestBody bool
 
@@ -33,36 +53,96 @@ type HTTPServerOptions struct {
 	CaptureResponseBody bool
 
 	// SkipPaths is a list of path prefixes to skip for request collection
-	// Useful for excluding static files or the dashboard itself
+	// Useful for excluding static files or the dashboard itself.
+	// An entry with "*" or "?" is a glob matching the full path, "*" and "?" match within a path segment and "**" across
+	// segments (e.g. "/assets/**/*.css"). An entry starting with "regexp:" is a regular expression matched against the
+	// path (e.g. "regexp:^/api/v[0-9]+/internal/"). Invalid regular expressions are logged and ignored.
 	SkipPaths []string
 
+	// SkipContentTypes is a list of response content types to skip, e.g. "text/css" or "image/*" for all subtypes.
+	// The Content-Type header of the response is checked after the handler returned, so a request that was already
+	// shown as pending (see PendingRequests) is kept. Streaming previews are not shown for skipped content types.
+	SkipContentTypes []string
+
+	// SkipHealthChecks skips requests to common health check, readiness and liveness endpoints, i.e. the paths
+	// "/health", "/healthz", "/readyz" and "/livez".
+	// Default: false
+	SkipHealthChecks bool
+
+	// SkipStaticAssets skips requests for paths with the extension of a common static asset (styles, scripts,
+	// images and fonts, e.g. ".css", ".js", ".png" or ".woff2").
+	// Default: false
+	SkipStaticAssets bool
+
+	// SkipPreflights skips CORS preflight requests (OPTIONS with Access-Control-Request-Method). Without preflights,
+	// the CORS section of the dashboard can't check them.
+	// Default: false
+	SkipPreflights bool
+
 	// Transformers are functions that transform/augment the HTTPServerRequest before adding it to the collector
 	Transformers []HTTPServerRequestTransformer
 
+	// TenantExtractor returns the tenant of a request (e.g. from a header or the authenticated user). Events of the
+	// request are tagged with the tenant, so the dashboard can filter them and captures can be restricted to a tenant.
+	// Default: nil, requests don't belong to a tenant
+	TenantExtractor TenantExtractor
+
+	// SessionMatcher returns additional capture session IDs for a request besides the session cookie and header.
+	// This allows to map e.g. an authenticated user to capture sessions to capture all requests of that user across devices.
+	SessionMatcher SessionMatcher
+
+	// Async enables running transformers and the dispatch of collected requests on a worker pool
+	// after the response has been sent, so expensive transformers don't add latency to requests.
+	// Default: nil, requests are processed synchronously
+	Async *AsyncOptions
+
 	// NotifierOptions are options for notification about new requests
 	NotifierOptions *NotifierOptions
 
 	// EventAggregator is the aggregator for collecting requests as grouped events
 	EventAggregator *EventAggregator
+
+	// PendingRequests shows requests in the dashboard as soon as the handler starts,
+	// so long-running requests are visible before they complete. The completed request replaces the pending one.
+	// Default: false, requests are shown after the handler returned
+	PendingRequests bool
+
+	// StreamingPreviewInterval is the minimum interval between previews of streamed responses.
+	// If a handler flushes the response, the request is shown in the dashboard while it is still in progress
+	// and updated at most once per interval until it completes. Zero disables streaming previews.
+	StreamingPreviewInterval time.Duration
+
+	// BodyBufferPool limits the memory of captured bodies across collectors that share it, the least recently written
+	// bodies are evicted if the limit is exceeded (see BodyBufferPool).
+	// Default: nil, the memory of bodies is only limited by MaxBodySize per body
+	BodyBufferPool *BodyBufferPool
 }
 
 type HTTPServerRequestTransformer func(HTTPServerRequest) HTTPServerRequest
 
+// SessionMatcher returns the IDs of capture sessions a request belongs to
+type SessionMatcher func(r *http.Request) []uuid.UUID
+
 // DefaultHTTPServerOptions returns default options for the HTTP server collector
 func DefaultHTTPServerOptions() HTTPServerOptions {
 	return HTTPServerOptions{
-		MaxBodySize:         DefaultMaxBodySize,
-		CaptureRequestBody:  true,
-		CaptureResponseBody: true,
-		SkipPaths:           nil,
+		MaxBodySize:              DefaultMaxBodySize,
+		CaptureRequestBody:       true,
+		CaptureResponseBody:      true,
+		SkipPaths:                nil,
+		StreamingPreviewInterval: 500 * time.Millisecond,
 	}
 }
 
 // HTTPServerCollector collects incoming HTTP requests
 type HTTPServerCollector struct {
-	options         HTTPServerOptions
-	notifier        *Notifier[HTTPServerRequest]
-	eventAggregator *EventAggregator
+	options          HTTPServerOptions
+	skipPaths        atomic.Pointer[[]pathMatcher]
+	skipContentTypes []string
+	bodySizes        bodySizeLimits
+	notifier         *Notifier[HTTPServerRequest]
+	eventAggregator  *EventAggregator
+	async            *workerPool
 }
 
 // NewHTTPServerCollector creates a new collector for incoming HTTP requests
@@ -77,11 +157,68 @@ func NewHTTPServerCollectorWithOptions(options HTTPServerOptions) *HTTPServerCol
 		notifierOptions = *options.NotifierOptions
 	}
 
-	return &HTTPServerCollector{
-		options:         options,
-		notifier:        NewNotifierWithOptions[HTTPServerRequest](notifierOptions),
-		eventAggregator: options.EventAggregator,
+	c := &HTTPServerCollector{
+		options:          options,
+		notifier:         NewNotifierWithOptions[HTTPServerRequest](notifierOptions),
+		eventAggregator:  options.EventAggregator,
+		skipContentTypes: compileContentTypePatterns(options.SkipContentTypes),
+		bodySizes:        newBodySizeLimits(options.MaxBodySize, options.MaxBodySizeByContentType),
+	}
+	if options.Async != nil {
+		c.async = newWorkerPool(*options.Async)
 	}
+	skipPaths := make([]pathMatcher, 0, len(options.SkipPaths))
+	for _, pattern := range options.SkipPaths {
+		matcher, err := compilePathMatcher(pattern)
+		if err != nil {
+			c.eventAggregator.Logger().Warn("Ignoring skip path", "error", err)
+			continue
+		}
+		skipPaths = append(skipPaths, matcher)
+	}
+	c.skipPaths.Store(&skipPaths)
+	return c
+}
+
+// SkipPath adds a path prefix to skip for request collection, in addition to HTTPServerOptions.SkipPaths.
+// The prefix is matched literally, also if it contains glob characters.
+// The devlog instance uses it to skip requests to the dashboard if it is mounted inside the collected handler.
+func (c *HTTPServerCollector) SkipPath(prefix string) {
+	for {
+		current := c.skipPaths.Load()
+		if slices.ContainsFunc(*current, func(m pathMatcher) bool { return m.re == nil && m.prefix == prefix }) {
+			return
+		}
+		updated := append(slices.Clone(*current), pathMatcher{pattern: prefix, prefix: prefix})
+		if c.skipPaths.CompareAndSwap(current, &updated) {
+			return
+		}
+	}
+}
+
+// skipped checks if a request is not collected because of its path, a skip preset, CaptureHeader or because it was sent
+// by devlog itself. A request with CaptureForce is only skipped if it was sent by devlog.
+func (c *HTTPServerCollector) skipped(r *http.Request, override string) bool {
+	if r.Header.Get(InternalRequestHeader) != "" {
+		return true
+	}
+	switch override {
+	case CaptureOff:
+		return true
+	case CaptureForce:
+		return false
+	}
+	if c.options.SkipHealthChecks && isHealthCheckPath(r.URL.Path) ||
+		c.options.SkipStaticAssets && isStaticAssetPath(r.URL.Path) ||
+		c.options.SkipPreflights && isPreflightRequest(r) {
+		return true
+	}
+	for _, matcher := range *c.skipPaths.Load() {
+		if matcher.match(r.URL.Path) {
+			return true
+		}
+	}
+	return false
 }
 
 // Subscribe returns a channel that receives notifications of new requests
@@ -94,35 +231,52 @@ func (c *HTTPServerCollector) Add(req HTTPServerRequest) {
 	c.notifier.Notify(req)
 }
 
-// Middleware returns an http.Handler middleware that captures request/response data
+// Middleware returns an http.Handler middleware that captures request/response data.
+// The returned handler implements Unwrap to reach next. If next is already wrapped by this collector, it is returned
+// unchanged, and requests already captured by the collector further up the chain are not captured again.
 func (c *HTTPServerCollector) Middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if this path should be skipped
-		for _, prefix := range c.options.SkipPaths {
-			if len(prefix) > 0 && len(r.URL.Path) >= len(prefix) && r.URL.Path[:len(prefix)] == prefix {
-				// Skip this path
-				next.ServeHTTP(w, r)
-				return
-			}
+	if wrapsHandler(next, c) {
+		return next
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Pass through without any work if collecting is disabled at runtime
+		if !c.eventAggregator.Enabled() {
+			next.ServeHTTP(w, r)
+			return
 		}
 
-		ctx := r.Context()
+		// A request is captured only once, e.g. if the collector wraps both a mux and one of its handlers
+		if capturing, _ := r.Context().Value(httpServerCollectorKey).(*HTTPServerCollector); capturing == c {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		// Extract session IDs from cookies and add to context
-		// Each session has its own cookie named "devlog_session_{uuid}"
-		var sessionIDs []uuid.UUID
-		for _, cookie := range r.Cookies() {
-			if strings.HasPrefix(cookie.Name, SessionCookiePrefix) {
-				idStr := strings.TrimPrefix(cookie.Name, SessionCookiePrefix)
-				if id, err := parseUUID(idStr); err == nil {
-					sessionIDs = append(sessionIDs, id)
-				}
-			}
+		// Check if this request should be skipped
+		override := captureOverride(r)
+		if c.skipped(r, override) {
+			next.ServeHTTP(w, r)
+			return
 		}
+
+		ctx := r.Context()
+
+		// Extract session IDs and add to context
+		sessionIDs := c.sessionIDs(r)
 		if len(sessionIDs) > 0 {
 			ctx = WithSessionIDs(ctx, sessionIDs)
-			r = r.WithContext(ctx)
 		}
+		if c.options.TenantExtractor != nil {
+			if tenant := c.options.TenantExtractor(r); tenant != "" {
+				ctx = WithTenant(ctx, tenant)
+			}
+		}
+		if override == CaptureForce {
+			ctx = withForcedCapture(ctx)
+		}
+		// Events of requests without a session must not be captured as ambient events
+		ctx = withServerRequest(ctx)
+		r = r.WithContext(ctx)
 
 		// Check if we should capture this request (using EventAggregator)
 		// Default to true for backward compatibility when neither aggregator nor collector is set
@@ -138,6 +292,11 @@ func (c *HTTPServerCollector) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Collect tags and the route pattern set by handlers or router adapters
+		ctx, info := withRequestInfo(ctx)
+		ctx = context.WithValue(ctx, httpServerCollectorKey, c)
+		r = r.WithContext(ctx)
+
 		// Generate a unique ID for this request
 		id := generateID()
 
@@ -159,12 +318,13 @@ func (c *HTTPServerCollector) Middleware(next http.Handler) http.Handler {
 		// Capture the request body if present and configured to do so
 		// Only check if the body is the special NoBody sentinel value (empty body)
 		var requestBody *Body
-		if r.Body != nil && r.Body != http.NoBody && c.options.CaptureRequestBody {
+		maxRequestBodySize := c.bodySizes.forContentType(r.Header.Get("Content-Type"))
+		if r.Body != nil && r.Body != http.NoBody && c.options.CaptureRequestBody && maxRequestBodySize > 0 {
 			// Save the original body
 			originalBody := r.Body
 
 			// Create a body wrapper
-			requestBody = NewBody(originalBody, c.options.MaxBodySize)
+			requestBody = c.options.BodyBufferPool.NewBody(originalBody, maxRequestBodySize)
 
 			// Replace the request body with our wrapper
 			r.Body = requestBody
@@ -180,18 +340,73 @@ func (c *HTTPServerCollector) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Start event tracking
+		var eventCtx context.Context
+		completed := false
 		if c.eventAggregator != nil {
-			newCtx := c.eventAggregator.StartEvent(ctx)
-			defer func(req *HTTPServerRequest) {
-				c.eventAggregator.EndEvent(newCtx, *req)
-			}(&httpReq)
+			eventCtx = c.eventAggregator.StartEvent(ctx)
+			// End the event even if the handler panics, the panic is recorded and continues
+			// to the server or a recovering middleware
+			defer func() {
+				if !completed {
+					v := recover()
+					if v != nil && v != http.ErrAbortHandler {
+						httpReq.Panic = fmt.Sprint(v)
+						httpReq.ResponseTime = time.Now()
+					}
+					c.eventAggregator.EndEvent(eventCtx, httpReq)
+					if v != nil {
+						panic(v)
+					}
+				}
+			}()
+
+			r = r.WithContext(eventCtx)
+
+			if c.options.PendingRequests {
+				c.eventAggregator.previewEvent(eventCtx, pendingRequest(httpReq))
+			}
 
-			r = r.WithContext(newCtx)
+			// Show streamed responses in the dashboard while they are in progress
+			if c.options.StreamingPreviewInterval > 0 {
+				var lastPreview time.Time
+				crw.preview = func() {
+					if override != CaptureForce && matchContentType(c.skipContentTypes, crw.Header().Get("Content-Type")) {
+						return
+					}
+					now := time.Now()
+					if now.Sub(lastPreview) < c.options.StreamingPreviewInterval {
+						return
+					}
+					lastPreview = now
+
+					preview := crw.streamingPreview(httpReq, now)
+					preview.RoutePattern = stripPatternMethod(r.Pattern)
+					info.applyTo(&preview)
+					c.eventAggregator.previewEvent(eventCtx, preview)
+				}
+			}
 		}
 
+		// Record when the client goes away, the request context is only canceled before the handler returns
+		// if the client closed the connection or canceled the request (deadlines of outer middlewares are ignored)
+		var disconnectTime time.Time
+		disconnected := make(chan struct{})
+		stopDisconnectWatch := context.AfterFunc(ctx, func() {
+			disconnectTime = time.Now()
+			close(disconnected)
+		})
+
 		// Call the next handler
 		next.ServeHTTP(crw, r)
 
+		if !stopDisconnectWatch() {
+			<-disconnected
+			if errors.Is(ctx.Err(), context.Canceled) {
+				httpReq.ClientDisconnected = true
+				httpReq.DisconnectTime = disconnectTime
+			}
+		}
+
 		// Close the request body to make sure we capture request bodies even if they are not read
 		if requestBody != nil {
 			_ = requestBody.Close()
@@ -202,36 +417,156 @@ func (c *HTTPServerCollector) Middleware(next http.Handler) http.Handler {
 		httpReq.ResponseTime = responseTime
 
 		// Capture response data
-		httpReq.StatusCode = crw.statusCode
-		if httpReq.StatusCode == 0 {
-			httpReq.StatusCode = http.StatusOK
+		crw.applyTo(&httpReq)
+		if httpReq.ResponseHeaders == nil {
+			httpReq.ResponseHeaders = crw.Header()
 		}
-
-		httpReq.ResponseHeaders = crw.Header()
+		httpReq.ResponseTrailers = crw.trailers()
 		httpReq.ResponseBody = crw.body
 
-		// Add request size if available
+		// Discarding the event also removes its preview if the request was already shown as pending
+		if override != CaptureForce && matchContentType(c.skipContentTypes, httpReq.ResponseHeaders.Get("Content-Type")) {
+			completed = true
+			if eventCtx != nil {
+				c.eventAggregator.discardEvent(eventCtx)
+			}
+			return
+		}
+
+		// Add request size if available, fall back to the declared length if the body is not captured
 		if requestBody != nil {
 			httpReq.RequestSize = requestBody.Size()
+		} else if r.ContentLength > 0 {
+			httpReq.RequestSize = uint64(r.ContentLength)
 		}
 
-		// Add response size if available
-		if crw.body != nil {
-			httpReq.ResponseSize = crw.body.Size()
+		// Use the pattern matched by http.ServeMux, a pattern set via SetRoutePattern takes precedence
+		httpReq.RoutePattern = stripPatternMethod(r.Pattern)
+		info.applyTo(&httpReq)
+
+		completed = true
+		c.complete(eventCtx, httpReq)
+	})
+	return &httpServerHandler{Handler: handler, next: next, collector: c}
+}
+
+type httpServerCollectorKeyType struct{}
+
+// httpServerCollectorKey holds the collector that captures the request handled with a context
+var httpServerCollectorKey = httpServerCollectorKeyType{}
+
+// httpServerHandler is the handler returned by HTTPServerCollector.Middleware
+type httpServerHandler struct {
+	http.Handler
+	next      http.Handler
+	collector *HTTPServerCollector
+}
+
+// Unwrap returns the wrapped http.Handler, so other middleware can reach through the handler chain
+func (h *httpServerHandler) Unwrap() http.Handler {
+	return h.next
+}
+
+// IsCapturingHandler checks if h was returned by HTTPServerCollector.Middleware or wraps such a handler.
+// Handlers of the chain are reached with an Unwrap() http.Handler method. Use it to avoid wrapping a handler twice.
+func IsCapturingHandler(h http.Handler) bool {
+	return wrapsHandler(h, nil)
+}
+
+// wrapsHandler checks if h or a handler it wraps captures requests with the collector, or any collector if c is nil
+func wrapsHandler(h http.Handler, c *HTTPServerCollector) bool {
+	for h != nil {
+		if handler, ok := h.(*httpServerHandler); ok && (c == nil || handler.collector == c) {
+			return true
 		}
+		unwrapper, ok := h.(interface{ Unwrap() http.Handler })
+		if !ok {
+			return false
+		}
+		h = unwrapper.Unwrap()
+	}
+	return false
+}
 
-		// Transform the request if any transformers are provided
+// complete transforms and adds a collected request and ends its event.
+// With async processing, this happens on the worker pool and the request is dropped if the queue is full.
+func (c *HTTPServerCollector) complete(eventCtx context.Context, httpReq HTTPServerRequest) {
+	process := func() {
+		// Transform the request if any transformers are provided, a panicking transformer is skipped
 		for _, transformer := range c.options.Transformers {
-			httpReq = transformer(httpReq)
+			httpReq = transformSafely(c.eventAggregator, eventCtx, "http_server_transformer", transformer, httpReq)
 		}
 
 		// Add to the collector
 		c.Add(httpReq)
-	})
+
+		if eventCtx != nil {
+			c.eventAggregator.endEvent(eventCtx, httpReq, httpReq.ResponseTime)
+		}
+	}
+
+	if c.async == nil {
+		process()
+		return
+	}
+	if !c.async.submit(process) {
+		c.eventAggregator.Logger().Warn("Dropped HTTP server request, async queue is full")
+		if eventCtx != nil {
+			c.eventAggregator.discardEvent(eventCtx)
+		}
+	}
+}
+
+// DroppedRequests returns the number of requests dropped because the async queue was full
+func (c *HTTPServerCollector) DroppedRequests() uint64 {
+	if c.async == nil {
+		return 0
+	}
+	return c.async.dropped.Load()
+}
+
+// sessionIDs returns the capture session IDs of a request from session cookies, the session header and the session matcher
+func (c *HTTPServerCollector) sessionIDs(r *http.Request) []uuid.UUID {
+	var sessionIDs []uuid.UUID
+	addSessionID := func(id uuid.UUID) {
+		if !slices.Contains(sessionIDs, id) {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+
+	// Each session has its own cookie named "devlog_session_{uuid}"
+	for _, cookie := range r.Cookies() {
+		if strings.HasPrefix(cookie.Name, SessionCookiePrefix) {
+			idStr := strings.TrimPrefix(cookie.Name, SessionCookiePrefix)
+			if id, err := parseUUID(idStr); err == nil {
+				addSessionID(id)
+			}
+		}
+	}
+
+	// Clients without cookies can send the session IDs in a header instead
+	for _, value := range r.Header.Values(SessionHeader) {
+		for _, idStr := range strings.Split(value, ",") {
+			if id, err := parseUUID(strings.TrimSpace(idStr)); err == nil {
+				addSessionID(id)
+			}
+		}
+	}
+
+	if c.options.SessionMatcher != nil {
+		for _, id := range c.options.SessionMatcher(r) {
+			addSessionID(id)
+		}
+	}
+
+	return sessionIDs
 }
 
 // Close releases resources used by the collector
 func (c *HTTPServerCollector) Close() {
+	if c.async != nil {
+		c.async.close()
+	}
 	c.notifier.Close()
 }
 
@@ -240,9 +575,60 @@ type captureResponseWriter struct {
 	http.ResponseWriter
 	statusCode    int
 	body          *Body
+	maxBodySize   int
 	wroteHeader   bool
 	bodyCapturing bool
 	collector     *HTTPServerCollector
+	// sentHeader is a copy of the header when the final response header was written, later changes can only be trailers
+	sentHeader    http.Header
+	informational []InformationalResponse
+	// bytesWritten is the number of body bytes written to the client, independent of body capture
+	bytesWritten uint64
+	// preview is called after the response was flushed to show streamed responses while they are in progress
+	preview func()
+}
+
+// applyTo copies the status, headers, informational responses and the size of the response written so far to the request.
+// The response headers are nil if no header was written yet.
+func (crw *captureResponseWriter) applyTo(httpReq *HTTPServerRequest) {
+	httpReq.StatusCode = crw.statusCode
+	if httpReq.StatusCode == 0 {
+		httpReq.StatusCode = http.StatusOK
+	}
+	httpReq.ResponseHeaders = crw.sentHeader
+	httpReq.InformationalResponses = crw.informational
+	// Count all written bytes, the captured body might be truncated or disabled
+	httpReq.ResponseSize = crw.bytesWritten
+}
+
+// pendingRequest returns a snapshot of a request before the handler started, without a response
+func pendingRequest(httpReq HTTPServerRequest) HTTPServerRequest {
+	httpReq.Tags = maps.Clone(httpReq.Tags)
+	// The body is read by the handler, it is shown with the completed request
+	httpReq.RequestBody = nil
+	return httpReq
+}
+
+// streamingPreview returns a snapshot of the request with the response streamed so far,
+// the snapshot does not share mutable state with the request that is still in progress
+func (crw *captureResponseWriter) streamingPreview(httpReq HTTPServerRequest, now time.Time) HTTPServerRequest {
+	httpReq.Streaming = true
+	httpReq.ResponseTime = now
+	httpReq.Tags = maps.Clone(httpReq.Tags)
+	crw.applyTo(&httpReq)
+	if httpReq.ResponseHeaders == nil {
+		httpReq.ResponseHeaders = cloneHeader(crw.Header())
+	}
+	httpReq.InformationalResponses = slices.Clone(httpReq.InformationalResponses)
+	if httpReq.RequestBody != nil {
+		httpReq.RequestSize = httpReq.RequestBody.Size()
+	}
+	if crw.body != nil {
+		body := crw.collector.options.BodyBufferPool.NewBody(nil, crw.maxBodySize)
+		body.write(crw.body.Bytes())
+		httpReq.ResponseBody = body
+	}
+	return httpReq
 }
 
 // WriteHeader implements http.ResponseWriter
@@ -250,11 +636,50 @@ func (crw *captureResponseWriter) WriteHeader(statusCode int) {
 	if crw.wroteHeader {
 		return
 	}
+	// Informational responses (e.g. 103 Early Hints) can be sent multiple times before the final response,
+	// 101 Switching Protocols is final.
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		crw.informational = append(crw.informational, InformationalResponse{
+			StatusCode: statusCode,
+			Header:     cloneHeader(crw.Header()),
+		})
+		crw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
 	crw.wroteHeader = true
 	crw.statusCode = statusCode
+	crw.sentHeader = cloneHeader(crw.Header())
 	crw.ResponseWriter.WriteHeader(statusCode)
 }
 
+// trailers returns the trailers set by the handler, either declared with the "Trailer" header or with http.TrailerPrefix
+func (crw *captureResponseWriter) trailers() http.Header {
+	var trailers http.Header
+	add := func(key string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		if trailers == nil {
+			trailers = make(http.Header)
+		}
+		trailers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	header := crw.Header()
+	for _, declared := range header.Values("Trailer") {
+		for _, key := range strings.Split(declared, ",") {
+			key = strings.TrimSpace(key)
+			add(key, header.Values(key))
+		}
+	}
+	for key, values := range header {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			add(strings.TrimPrefix(key, http.TrailerPrefix), values)
+		}
+	}
+	return trailers
+}
+
 // Write implements http.ResponseWriter
 func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 	if !crw.wroteHeader {
@@ -263,47 +688,98 @@ func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 
 	// If we're capturing the body and haven't set up the body capture yet
 	if crw.collector.options.CaptureResponseBody && !crw.bodyCapturing {
-
-		// Create a buffer to capture the response body
-		crw.body = NewBody(nil, crw.collector.options.MaxBodySize)
+		// Create a buffer to capture the response body, unless bodies of its content type are not captured
+		crw.maxBodySize = crw.collector.bodySizes.forResponse(crw.Header(), b)
+		if crw.maxBodySize > 0 {
+			crw.body = crw.collector.options.BodyBufferPool.NewBody(nil, crw.maxBodySize)
+		}
 		crw.bodyCapturing = true
 	}
 
 	// First write to the original response writer
 	n, err := crw.ResponseWriter.Write(b)
+	crw.bytesWritten += uint64(n)
 	if err != nil {
 		return n, err
 	}
 
 	// If we're capturing the body, store a copy in our buffer
 	if crw.collector.options.CaptureResponseBody && crw.bodyCapturing && crw.body != nil {
-		crw.body.buffer.Write(b[:n])
+		crw.body.write(b[:n])
 	}
 
 	return n, nil
 }
 
-// Flush implements http.Flusher if the original response writer implements it
+// ReadFrom implements io.ReaderFrom to keep optimizations like sendfile of the original response writer
+// if the body is not captured
+func (crw *captureResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if crw.collector.options.CaptureResponseBody {
+		// Copy through Write to capture the body, hide ReadFrom to prevent a recursion in io.Copy
+		return io.Copy(writerOnly{crw}, r)
+	}
+
+	if !crw.wroteHeader {
+		crw.WriteHeader(http.StatusOK)
+	}
+	if readerFrom, ok := crw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err := readerFrom.ReadFrom(r)
+		crw.bytesWritten += uint64(n)
+		return n, err
+	}
+	return io.Copy(writerOnly{crw}, r)
+}
+
+// writerOnly hides all methods except Write of a writer
+type writerOnly struct {
+	io.Writer
+}
+
+// Unwrap returns the original response writer, so http.ResponseController can reach optional methods
+// like SetReadDeadline, SetWriteDeadline or EnableFullDuplex
+func (crw *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return crw.ResponseWriter
+}
+
+// Flush implements http.Flusher, see FlushError
 func (crw *captureResponseWriter) Flush() {
-	if flusher, ok := crw.ResponseWriter.(http.Flusher); ok {
-		flusher.Flush()
+	_ = crw.FlushError()
+}
+
+// FlushError flushes the original response writer, which is reached with Unwrap through other middlewares. It returns
+// an error wrapping http.ErrNotSupported if no writer supports flushing, so http.ResponseController reports it.
+func (crw *captureResponseWriter) FlushError() error {
+	// Flushing sends the header, so later status codes are ignored
+	if !crw.wroteHeader {
+		crw.WriteHeader(http.StatusOK)
 	}
+	err := http.NewResponseController(crw.ResponseWriter).Flush()
+	if crw.preview != nil {
+		crw.preview()
+	}
+	return err
 }
 
-// Hijack implements http.Hijacker if the original response writer implements it
+// Hijack implements http.Hijacker by hijacking the original response writer, which is reached with Unwrap through
+// other middlewares. It returns an error wrapping http.ErrNotSupported if no writer supports hijacking.
 func (crw *captureResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hijacker, ok := crw.ResponseWriter.(http.Hijacker); ok {
-		return hijacker.Hijack()
-	}
-	return nil, nil, fmt.Errorf("response writer does not implement http.Hijacker")
+	return http.NewResponseController(crw.ResponseWriter).Hijack()
 }
 
-// Push implements http.Pusher if the original response writer implements it
+// Push implements http.Pusher by pushing with the original response writer, which is reached with Unwrap through
+// other middlewares. It returns an error wrapping http.ErrNotSupported if no writer supports pushing.
 func (crw *captureResponseWriter) Push(target string, opts *http.PushOptions) error {
-	if pusher, ok := crw.ResponseWriter.(http.Pusher); ok {
-		return pusher.Push(target, opts)
+	w := crw.ResponseWriter
+	for {
+		if pusher, ok := w.(http.Pusher); ok {
+			return pusher.Push(target, opts)
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return fmt.Errorf("push: %w", http.ErrNotSupported)
+		}
+		w = unwrapper.Unwrap()
 	}
-	return fmt.Errorf("response writer does not implement http.Pusher")
 }
 
 // Helper to clone an http.Header, similar to Header.Clone() in newer Go versions
@@ -2,30 +2,54 @@ package collector
 
 import (
 	"net/http"
+	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/gofrs/uuid"
 )
 
 // HTTPServerRequest represents a captured HTTP server request/response pair
 type HTTPServerRequest struct {
-	ID              uuid.UUID
-	Method          string
-	Path            string
-	URL             string
-	RemoteAddr      string
-	RequestTime     time.Time
-	ResponseTime    time.Time
-	StatusCode      int
-	RequestSize     uint64
+	ID     uuid.UUID
+	Method string
+	Path   string
+	// RoutePattern is the route template matched by the router (e.g. "/todos/{id}"), empty if unknown
+	RoutePattern string
+	URL          string
+	RemoteAddr   string
+	RequestTime  time.Time
+	ResponseTime time.Time
+	StatusCode   int
+	// RequestSize is the size of the request body, the captured size or the declared content length if the body is not captured
+	RequestSize uint64
+	// ResponseSize is the number of response body bytes written, independent of capturing (see ResponseBody.Size for the captured size)
 	ResponseSize    uint64
 	RequestHeaders  http.Header
 	ResponseHeaders http.Header
 	RequestBody     *Body
 	ResponseBody    *Body
+	// ResponseTrailers are the trailers sent after the response body, nil if there are none
+	ResponseTrailers http.Header
+	// InformationalResponses are 1xx responses (e.g. 103 Early Hints) sent before the final response
+	InformationalResponses []InformationalResponse
+	// Streaming is true for a preview of a request with a response that is still being streamed
+	Streaming bool
+	// ClientDisconnected is true if the client closed the connection or canceled the request before the handler returned
+	ClientDisconnected bool
+	// DisconnectTime is the time the client disconnected, zero if the client did not disconnect
+	DisconnectTime time.Time
 	// Tags are custom tags that can be used to categorize requests
 	Tags  map[string]string
 	Error error
+	// Panic is the value the handler panicked with, empty if it did not panic
+	Panic string
+}
+
+// InformationalResponse is a 1xx response sent before the final response of a request
+type InformationalResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
 }
 
 // Duration returns the duration of the request
@@ -33,31 +57,45 @@ func (r HTTPServerRequest) Duration() time.Duration {
 	return r.ResponseTime.Sub(r.RequestTime)
 }
 
-// Size returns the estimated memory size of this request in bytes
-func (r HTTPServerRequest) Size() uint64 {
-	size := uint64(200) // base struct overhead
-	size += uint64(len(r.URL) + len(r.Path) + len(r.Method) + len(r.RemoteAddr))
-	size += headersSize(r.RequestHeaders)
-	size += headersSize(r.ResponseHeaders)
-	if r.RequestBody != nil {
-		size += r.RequestBody.Size()
+// DisconnectedAfter returns the time elapsed from the start of the request until the client disconnected
+func (r HTTPServerRequest) DisconnectedAfter() time.Duration {
+	if !r.ClientDisconnected {
+		return 0
 	}
-	if r.ResponseBody != nil {
-		size += r.ResponseBody.Size()
+	return r.DisconnectTime.Sub(r.RequestTime)
+}
+
+// Route returns the route pattern of the request or the path if the pattern is unknown
+func (r HTTPServerRequest) Route() string {
+	if r.RoutePattern != "" {
+		return r.RoutePattern
 	}
-	for k, v := range r.Tags {
-		size += uint64(len(k) + len(v))
+	return r.Path
+}
+
+// stripPatternMethod removes the method from a http.ServeMux pattern (e.g. "GET /todos/{id}"),
+// since the method is already part of the request.
+func stripPatternMethod(pattern string) string {
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 && !strings.Contains(pattern[:i], "/") {
+		return strings.TrimLeft(pattern[i:], " \t")
 	}
-	return size
+	return pattern
 }
 
-func headersSize(h http.Header) uint64 {
-	var size uint64
-	for k, vs := range h {
-		size += uint64(len(k))
-		for _, v := range vs {
-			size += uint64(len(v))
-		}
+// Size returns the estimated memory size of this request in bytes, including the buffers of captured bodies
+func (r HTTPServerRequest) Size() uint64 {
+	size := uint64(unsafe.Sizeof(r))
+	size += uint64(len(r.URL) + len(r.Path) + len(r.RoutePattern) + len(r.Method) + len(r.RemoteAddr) + len(r.Panic))
+	size += headersSize(r.RequestHeaders)
+	size += headersSize(r.ResponseHeaders)
+	size += headersSize(r.ResponseTrailers)
+	size += uint64(cap(r.InformationalResponses)) * uint64(unsafe.Sizeof(InformationalResponse{}))
+	for _, informational := range r.InformationalResponses {
+		size += headersSize(informational.Header)
 	}
+	size += r.RequestBody.memorySize()
+	size += r.ResponseBody.memorySize()
+	size += tagsSize(r.Tags)
+	size += errorSize(r.Error)
 	return size
 }
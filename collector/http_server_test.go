@@ -1,12 +1,17 @@
 package collector_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -75,6 +80,21 @@ func TestHTTPServerCollector_BasicRequest(t *testing.T) {
 	assert.Equal(t, uint64(13), serverReq.ResponseSize) // "Hello, World!" is 13 bytes
 }
 
+func TestHTTPServerCollector_RequestContextIsNotAmbient(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	var ambient bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ambient = collector.IsAmbientContext(r.Context())
+	})
+
+	rec := httptest.NewRecorder()
+	serverCollector.Middleware(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.False(t, ambient)
+	assert.True(t, collector.IsAmbientContext(context.Background()))
+}
+
 // Test for GET request with a body to ensure we capture it correctly
 func TestHTTPServerCollector_GetRequestWithBody(t *testing.T) {
 	// Create a server collector
@@ -429,6 +449,282 @@ func TestHTTPServerCollector_SkipPaths(t *testing.T) {
 	assert.False(t, capturedPaths["/assets/style.css"], "Should not have captured /assets/style.css")
 }
 
+func TestHTTPServerCollector_SkipPathPatterns(t *testing.T) {
+	options := collector.DefaultHTTPServerOptions()
+	options.SkipPaths = []string{
+		"/assets/**/*.css",
+		"/files/?.txt",
+		"regexp:^/api/v[0-9]+/internal/",
+		"regexp:[invalid",
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+	// Added literally, the glob characters are not interpreted
+	serverCollector.SkipPath("/literal/*/")
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	paths := map[string]bool{
+		"/assets/app.css":               false,
+		"/assets/vendor/theme/site.css": false,
+		"/assets/app.js":                true,
+		"/other/assets/app.css":         true,
+		"/files/a.txt":                  false,
+		"/files/ab.txt":                 true,
+		"/api/v2/internal/jobs":         false,
+		"/api/v2/users":                 true,
+		"/api/vx/internal/jobs":         true,
+		"/literal/*/page":               false,
+		"/literal/x/page":               true,
+	}
+	for path := range paths {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	captured := make(map[string]bool)
+	for _, req := range collect.Stop() {
+		captured[req.Path] = true
+	}
+	for path, expected := range paths {
+		assert.Equal(t, expected, captured[path], "captured %s", path)
+	}
+}
+
+func TestHTTPServerCollector_SkipContentTypes(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+	defer aggregator.Close()
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.SkipContentTypes = []string{"image/*", "Text/CSS"}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		_, _ = w.Write([]byte("content"))
+	}))
+
+	for _, contentType := range []string{"image/png", "text/css; charset=utf-8", "text/html; charset=utf-8", "application/json"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?type="+url.QueryEscape(contentType), nil))
+	}
+
+	var captured []string
+	for _, event := range storage.GetEvents(10) {
+		captured = append(captured, event.Data.(collector.HTTPServerRequest).ResponseHeaders.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{"text/html; charset=utf-8", "application/json"}, captured)
+}
+
+func TestHTTPServerCollector_MaxBodySizeByContentType(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+	defer aggregator.Close()
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.MaxBodySize = 8
+	options.MaxBodySizeByContentType = map[string]int{
+		"application/json":         100,
+		"application/octet-stream": 4,
+		"video/*":                  0,
+		"video/mp4":                2,
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	content := "0123456789abcdef"
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if contentType := r.URL.Query().Get("type"); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+
+	tests := []struct {
+		contentType      string
+		expectedCaptured string
+	}{
+		{contentType: "application/json; charset=utf-8", expectedCaptured: content},
+		{contentType: "application/octet-stream", expectedCaptured: "0123"},
+		{contentType: "video/webm", expectedCaptured: ""},
+		{contentType: "video/mp4", expectedCaptured: "01"},
+		{contentType: "text/html", expectedCaptured: "01234567"},
+		// The content type is detected like net/http does if the handler does not set it
+		{contentType: "", expectedCaptured: "01234567"},
+	}
+	for _, tt := range tests {
+		storage.Clear()
+		req := httptest.NewRequest(http.MethodPost, "/?type="+url.QueryEscape(tt.contentType), strings.NewReader(content))
+		req.Header.Set("Content-Type", tt.contentType)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		events := storage.GetEvents(1)
+		require.Len(t, events, 1)
+		captured := events[0].Data.(collector.HTTPServerRequest)
+		assert.Equal(t, tt.expectedCaptured, captured.RequestBody.String(), "request body of %q", tt.contentType)
+		assert.Equal(t, tt.expectedCaptured, captured.ResponseBody.String(), "response body of %q", tt.contentType)
+		assert.Equal(t, uint64(len(content)), captured.ResponseSize, "the size is recorded even if the body is not captured")
+		if tt.expectedCaptured == "" {
+			assert.Nil(t, captured.RequestBody)
+			assert.Nil(t, captured.ResponseBody)
+		}
+	}
+}
+
+func TestHTTPServerCollector_SkipPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  func(*collector.HTTPServerOptions)
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			options:  func(*collector.HTTPServerOptions) {},
+			expected: []string{"GET /healthz", "GET /readyz/", "GET /api/health/summary", "GET /api/users", "GET /static/app.css", "GET /logo.PNG", "OPTIONS /api/users", "OPTIONS /api/products", "GET /api/status"},
+		},
+		{
+			name: "all presets",
+			options: func(options *collector.HTTPServerOptions) {
+				options.SkipHealthChecks = true
+				options.SkipStaticAssets = true
+				options.SkipPreflights = true
+			},
+			expected: []string{"GET /api/health/summary", "GET /api/users", "OPTIONS /api/products", "GET /api/status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := collector.DefaultHTTPServerOptions()
+			tt.options(&options)
+			serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+			handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			collect := Collect(t, serverCollector.Subscribe)
+
+			requests := []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/healthz", nil),
+				httptest.NewRequest(http.MethodGet, "/readyz/", nil),
+				// Only the exact paths of health checks are skipped
+				httptest.NewRequest(http.MethodGet, "/api/health/summary", nil),
+				httptest.NewRequest(http.MethodGet, "/api/users", nil),
+				httptest.NewRequest(http.MethodGet, "/static/app.css", nil),
+				httptest.NewRequest(http.MethodGet, "/logo.PNG", nil),
+				httptest.NewRequest(http.MethodOptions, "/api/users", nil),
+				// A plain OPTIONS request is no preflight
+				httptest.NewRequest(http.MethodOptions, "/api/products", nil),
+				httptest.NewRequest(http.MethodGet, "/api/status", nil),
+			}
+			requests[6].Header.Set("Origin", "https://app.example")
+			requests[6].Header.Set("Access-Control-Request-Method", "PUT")
+			for _, req := range requests {
+				handler.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			var captured []string
+			for _, req := range collect.Stop() {
+				captured = append(captured, req.Method+" "+req.Path)
+			}
+			assert.Equal(t, tt.expected, captured)
+		})
+	}
+}
+
+func TestHTTPServerCollector_CaptureHeader(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeSession)
+	aggregator.RegisterStorage(storage)
+	defer aggregator.Close()
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.SkipPaths = []string{"/skipped/"}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		path    string
+		session bool
+		capture string
+	}{
+		{path: "/with-session", session: true},
+		{path: "/load-test", session: true, capture: "off"},
+		{path: "/webhook", capture: "Force"},
+		{path: "/skipped/webhook", capture: "force"},
+		{path: "/without-session"},
+		{path: "/unknown-value", capture: "maybe"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		if tt.session {
+			req.Header.Set(collector.SessionHeader, sessionID.String())
+		}
+		if tt.capture != "" {
+			req.Header.Set(collector.CaptureHeader, tt.capture)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Requests sent by devlog itself are never captured
+	req := httptest.NewRequest(http.MethodPost, "/internal", nil)
+	req.Header.Set(collector.InternalRequestHeader, "1")
+	req.Header.Set(collector.CaptureHeader, collector.CaptureForce)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var captured []string
+	for _, event := range storage.GetEvents(10) {
+		captured = append(captured, event.Data.(collector.HTTPServerRequest).Path)
+	}
+	assert.Equal(t, []string{"/with-session", "/webhook", "/skipped/webhook"}, captured)
+}
+
+func TestHTTPServerCollector_SkipDevlogRequests(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	// Added by the devlog instance for the dashboard
+	serverCollector.SkipPath("/_devlog/")
+	serverCollector.SkipPath("/_devlog/")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	for _, path := range []string{"/_devlog/s/123/events-sse", "/_devlogger", "/api/users"} {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// A request sent by devlog itself, e.g. a webhook calling the application
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/webhook", nil)
+	require.NoError(t, err)
+	req.Header.Set(collector.InternalRequestHeader, "webhook")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	requests := collect.Stop()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/_devlogger", requests[0].Path)
+	assert.Equal(t, "/api/users", requests[1].Path)
+}
+
 func TestHTTPServerCollector_StreamingResponse(t *testing.T) {
 	// Create a server collector
 	serverCollector := collector.NewHTTPServerCollector()
@@ -502,6 +798,220 @@ func TestHTTPServerCollector_StreamingResponse(t *testing.T) {
 	assert.Equal(t, expectedResponse, serverReq.ResponseBody.String())
 }
 
+func TestHTTPServerCollector_StreamingPreview(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.StreamingPreviewInterval = time.Millisecond
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	chunkSent := make(chan struct{})
+	previewChecked := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		close(chunkSent)
+		<-previewChecked
+		_, _ = w.Write([]byte("data: second\n\n"))
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := storage.Subscribe(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get(server.URL + "/stream")
+		if err != nil {
+			t.Errorf("request failed: %v", err)
+			return
+		}
+		_, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	<-chunkSent
+
+	// The preview is dispatched while the handler is still running
+	preview := <-events
+	assert.True(t, preview.InProgress)
+	previewReq := preview.Data.(collector.HTTPServerRequest)
+	assert.True(t, previewReq.Streaming)
+	assert.Equal(t, "/stream", previewReq.Path)
+	assert.Equal(t, "data: first\n\n", previewReq.ResponseBody.String())
+	assert.Equal(t, "text/event-stream", previewReq.ResponseHeaders.Get("Content-Type"))
+	require.Len(t, storage.GetEvents(10), 1)
+
+	close(previewChecked)
+	<-done
+
+	// The completed event replaces the preview
+	completed := <-events
+	assert.Equal(t, preview.ID, completed.ID)
+	assert.False(t, completed.InProgress)
+	completedReq := completed.Data.(collector.HTTPServerRequest)
+	assert.False(t, completedReq.Streaming)
+	assert.Equal(t, "data: first\n\ndata: second\n\n", completedReq.ResponseBody.String())
+
+	stored := storage.GetEvents(10)
+	require.Len(t, stored, 1)
+	assert.Same(t, completed, stored[0])
+
+	// The preview body is a snapshot
+	assert.Equal(t, "data: first\n\n", previewReq.ResponseBody.String())
+}
+
+func TestHTTPServerCollector_PendingRequests(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.PendingRequests = true
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handlerStarted := make(chan struct{})
+	pendingChecked := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(handlerStarted)
+		<-pendingChecked
+		_, _ = w.Write([]byte("done"))
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Post(server.URL+"/slow", "text/plain", strings.NewReader("request"))
+		if err != nil {
+			t.Errorf("request failed: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+
+	<-handlerStarted
+
+	// The pending request is stored while the handler is running
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	pending := events[0]
+	assert.True(t, pending.InProgress)
+	pendingReq := pending.Data.(collector.HTTPServerRequest)
+	assert.Equal(t, "/slow", pendingReq.Path)
+	assert.Equal(t, 0, pendingReq.StatusCode)
+	assert.Nil(t, pendingReq.RequestBody)
+
+	close(pendingChecked)
+	<-done
+
+	// The completed request replaces the pending request
+	events = storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, pending.ID, events[0].ID)
+	assert.False(t, events[0].InProgress)
+	completedReq := events[0].Data.(collector.HTTPServerRequest)
+	assert.Equal(t, http.StatusOK, completedReq.StatusCode)
+	assert.Equal(t, "request", completedReq.RequestBody.String())
+	assert.Equal(t, "done", completedReq.ResponseBody.String())
+}
+
+func TestHTTPServerCollector_PendingRequests_Dropped(t *testing.T) {
+	aggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		Processors: []collector.EventProcessor{
+			// Only the completed request is dropped, its preview was already dispatched
+			collector.EventProcessorFunc(func(ctx context.Context, evt *collector.Event) (*collector.Event, bool) {
+				return evt, evt.InProgress
+			}),
+		},
+	})
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.PendingRequests = true
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	var pending []*collector.Event
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pending = storage.GetEvents(10)
+		_, _ = w.Write([]byte("done"))
+	})
+
+	rec := httptest.NewRecorder()
+	serverCollector.Middleware(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dropped", nil))
+
+	require.Len(t, pending, 1)
+	assert.True(t, pending[0].InProgress)
+
+	// The preview of the dropped request is removed
+	assert.Empty(t, storage.GetEvents(10))
+}
+
+func TestHTTPServerCollector_TrailersAndInformationalResponses(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Hello"))
+
+		w.Header().Set("X-Checksum", "abc123")
+		w.Header().Set(http.TrailerPrefix+"X-Duration", "5ms")
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	resp, err := http.Get(server.URL + "/hints")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", string(body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "abc123", resp.Trailer.Get("X-Checksum"))
+
+	requests := collect.Stop()
+
+	serverReq := requests[0]
+	assert.Equal(t, http.StatusOK, serverReq.StatusCode)
+	require.Len(t, serverReq.InformationalResponses, 1)
+	assert.Equal(t, http.StatusEarlyHints, serverReq.InformationalResponses[0].StatusCode)
+	assert.Equal(t, "</style.css>; rel=preload; as=style", serverReq.InformationalResponses[0].Header.Get("Link"))
+
+	assert.Equal(t, http.Header{
+		"X-Checksum": {"abc123"},
+		"X-Duration": {"5ms"},
+	}, serverReq.ResponseTrailers)
+	assert.Empty(t, serverReq.ResponseHeaders.Get("X-Checksum"), "trailers should not be part of the response headers")
+}
+
 func TestHTTPServerCollector_DisabledBodyCapture(t *testing.T) {
 	// Create a server collector with body capture disabled
 	options := collector.DefaultHTTPServerOptions()
@@ -565,6 +1075,219 @@ func TestHTTPServerCollector_DisabledBodyCapture(t *testing.T) {
 	assert.Nil(t, serverReq.ResponseBody)
 }
 
+func TestHTTPServerCollector_ResponseSize(t *testing.T) {
+	content := strings.Repeat("x", 4096)
+
+	tests := []struct {
+		name                string
+		captureResponseBody bool
+		maxBodySize         int
+		expectedCaptured    uint64
+	}{
+		{
+			name:                "capture disabled",
+			captureResponseBody: false,
+			maxBodySize:         collector.DefaultMaxBodySize,
+			expectedCaptured:    0,
+		},
+		{
+			name:                "truncated capture",
+			captureResponseBody: true,
+			maxBodySize:         1024,
+			expectedCaptured:    1024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := collector.DefaultHTTPServerOptions()
+			options.CaptureRequestBody = false
+			options.CaptureResponseBody = tt.captureResponseBody
+			options.MaxBodySize = tt.maxBodySize
+			serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+			// io.Copy uses ReadFrom of the response writer
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(w, strings.NewReader(content))
+			})
+
+			server := httptest.NewServer(serverCollector.Middleware(handler))
+			defer server.Close()
+
+			collect := Collect(t, serverCollector.Subscribe)
+
+			resp, err := http.Post(server.URL, "text/plain", strings.NewReader("request"))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, content, string(body))
+
+			serverReq := collect.Stop()[0]
+			assert.Equal(t, uint64(len(content)), serverReq.ResponseSize)
+			assert.Equal(t, tt.expectedCaptured, serverReq.ResponseBody.Size())
+			assert.Equal(t, uint64(len("request")), serverReq.RequestSize)
+		})
+	}
+}
+
+func TestHTTPServerCollector_ResponseController(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		// Deadlines are only supported by the original response writer, which is reached with Unwrap
+		if err := rc.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := rc.SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+		if err := rc.Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "ok", string(body))
+
+	serverReq := collect.Stop()[0]
+	assert.Equal(t, http.StatusOK, serverReq.StatusCode)
+	assert.Equal(t, "ok", string(serverReq.ResponseBody.Bytes()))
+}
+
+func TestHTTPServerCollector_ResponseControllerThroughUnwrap(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	var flushErr, hijackErr, pushErr error
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		_, _ = w.Write([]byte("ok"))
+		flushErr = rc.Flush()
+		_, _, hijackErr = rc.Hijack()
+		pushErr = w.(http.Pusher).Push("/style.css", nil)
+	}))
+
+	t.Run("supported", func(t *testing.T) {
+		rec := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+		// Another middleware in between only exposes the original response writer with Unwrap
+		handler.ServeHTTP(&unwrappingResponseWriter{ResponseWriter: rec}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.NoError(t, flushErr)
+		assert.True(t, rec.Flushed)
+		assert.NoError(t, hijackErr)
+		assert.True(t, rec.hijacked)
+		assert.NoError(t, pushErr)
+		assert.Equal(t, []string{"/style.css"}, rec.pushed)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		w := &unwrappingResponseWriter{ResponseWriter: discardResponseWriter{header: make(http.Header)}}
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.ErrorIs(t, flushErr, http.ErrNotSupported)
+		assert.ErrorIs(t, hijackErr, http.ErrNotSupported)
+		assert.ErrorIs(t, pushErr, http.ErrNotSupported)
+	})
+}
+
+// unwrappingResponseWriter is a response writer of another middleware that only exposes the writer it wraps with Unwrap
+type unwrappingResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *unwrappingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// hijackableRecorder is a response recorder that records hijacks and pushes
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	pushed   []string
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func (r *hijackableRecorder) Push(target string, _ *http.PushOptions) error {
+	r.pushed = append(r.pushed, target)
+	return nil
+}
+
+func TestHTTPServerCollector_ClientDisconnect(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	handlerStarted := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(handlerStarted)
+		// Wait for the client to go away and respond anyway like many handlers do
+		<-r.Context().Done()
+		_, _ = w.Write([]byte("too late"))
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	go func() {
+		<-handlerStarted
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err = http.DefaultClient.Do(req)
+	require.ErrorIs(t, err, context.Canceled)
+
+	serverReq := collect.Wait(1)[0]
+	assert.True(t, serverReq.ClientDisconnected)
+	assert.False(t, serverReq.DisconnectTime.IsZero())
+	assert.GreaterOrEqual(t, serverReq.DisconnectedAfter(), 10*time.Millisecond)
+	assert.LessOrEqual(t, serverReq.DisconnectTime, serverReq.ResponseTime)
+}
+
+func TestHTTPServerCollector_NoClientDisconnect(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	collect := Collect(t, serverCollector.Subscribe)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	serverReq := collect.Stop()[0]
+	assert.False(t, serverReq.ClientDisconnected)
+	assert.True(t, serverReq.DisconnectTime.IsZero())
+	assert.Equal(t, time.Duration(0), serverReq.DisconnectedAfter())
+}
+
 func TestHTTPServerCollector_UnreadRequestBodyCapture(t *testing.T) {
 	// This test verifies that request bodies are captured even when handlers don't read them
 
@@ -794,6 +1517,37 @@ func TestHTTPServerCollector_WithEventAggregator_GlobalMode(t *testing.T) {
 	assert.Equal(t, http.StatusOK, httpReq.StatusCode)
 }
 
+func TestHTTPServerCollector_WithEventAggregator_Panic(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	}))
+
+	// The panic continues to a recovering middleware
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+	assert.Equal(t, "something went wrong", recovered)
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	httpReq, ok := events[0].Data.(collector.HTTPServerRequest)
+	require.True(t, ok, "Event data should be HTTPServerRequest")
+	assert.Equal(t, "something went wrong", httpReq.Panic)
+	assert.False(t, httpReq.ResponseTime.IsZero())
+}
+
 func TestHTTPServerCollector_WithEventAggregator_SessionMode_NoMatch(t *testing.T) {
 	// Create an EventAggregator with a SessionMode storage
 	aggregator := collector.NewEventAggregator()
@@ -896,6 +1650,77 @@ func TestHTTPServerCollector_WithEventAggregator_SessionMode_Match(t *testing.T)
 	assert.Equal(t, "/test", httpReq.Path)
 }
 
+func TestHTTPServerCollector_WithEventAggregator_SessionMode_Header(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeSession)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	var sessionIDs []uuid.UUID
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionIDs, _ = collector.SessionIDsFromContext(r.Context())
+	})
+
+	server := httptest.NewServer(serverCollector.Middleware(handler))
+	defer server.Close()
+
+	// Tag the request with the session header instead of a cookie, e.g. from a CLI tool
+	otherSessionID := uuid.Must(uuid.NewV4())
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+	require.NoError(t, err)
+	req.Header.Set(collector.SessionHeader, otherSessionID.String()+", "+sessionID.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []uuid.UUID{otherSessionID, sessionID}, sessionIDs)
+	require.Len(t, storage.GetEvents(10), 1)
+}
+
+func TestHTTPServerCollector_WithEventAggregator_SessionMode_SessionMatcher(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeSession)
+	aggregator.RegisterStorage(storage)
+
+	// Capture all requests of user "alice" in the session
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.SessionMatcher = func(r *http.Request) []uuid.UUID {
+		if r.Header.Get("X-User") == "alice" {
+			return []uuid.UUID{sessionID}
+		}
+		return nil
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	server := httptest.NewServer(serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	defer server.Close()
+
+	for _, user := range []string{"alice", "bob"} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/"+user, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-User", user)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "/alice", events[0].Data.(collector.HTTPServerRequest).Path)
+}
+
 func TestHTTPServerCollector_WithEventAggregator_NoStorage(t *testing.T) {
 	// Create an EventAggregator with NO storage registered
 	aggregator := collector.NewEventAggregator()
@@ -943,3 +1768,320 @@ func TestHTTPServerCollector_WithEventAggregator_NoStorage(t *testing.T) {
 	requests := collect.Stop()
 	assert.Len(t, requests, 0)
 }
+
+func TestHTTPServerCollector_Async(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.Async = &collector.AsyncOptions{Workers: 1, QueueSize: 1}
+	options.Transformers = []collector.HTTPServerRequestTransformer{
+		func(request collector.HTTPServerRequest) collector.HTTPServerRequest {
+			started <- struct{}{}
+			<-release
+			request.Tags["transformed"] = "true"
+			return request
+		},
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// The request completes while the transformer is still blocked
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/1", nil))
+	<-started
+
+	// The second request is queued, the third one is dropped
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/2", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/3", nil))
+	assert.Equal(t, uint64(1), serverCollector.DroppedRequests())
+	assert.Empty(t, storage.GetEvents(10))
+
+	// Close waits for queued requests to be processed
+	close(release)
+	serverCollector.Close()
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 2)
+	for i, event := range events {
+		request := event.Data.(collector.HTTPServerRequest)
+		assert.Equal(t, fmt.Sprintf("/%d", i+1), request.Path)
+		assert.Equal(t, "true", request.Tags["transformed"])
+		assert.Equal(t, request.ResponseTime, event.End)
+	}
+}
+
+func TestHTTPServerCollector_TransformerPanic(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.Async = &collector.AsyncOptions{Workers: 1, QueueSize: 10}
+	options.Transformers = []collector.HTTPServerRequestTransformer{
+		func(request collector.HTTPServerRequest) collector.HTTPServerRequest {
+			panic("broken transformer")
+		},
+		func(request collector.HTTPServerRequest) collector.HTTPServerRequest {
+			request.Tags["transformed"] = "true"
+			return request
+		},
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// The panic on the worker goroutine does not crash the application
+	serverCollector.Close()
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	request := events[0].Data.(collector.HTTPServerRequest)
+	assert.Equal(t, "true", request.Tags["transformed"], "following transformers are still applied")
+
+	require.Len(t, events[0].Children, 1)
+	internalErr, ok := events[0].Children[0].Data.(collector.InternalError)
+	require.True(t, ok)
+	assert.Equal(t, "http_server_transformer", internalErr.Component)
+	assert.Equal(t, "broken transformer", internalErr.Panic)
+	assert.Equal(t, uint64(1), aggregator.RecoveredPanics())
+}
+
+func TestHTTPServerCollector_WrappedTwice(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	assert.False(t, collector.IsCapturingHandler(handler))
+
+	wrapped := serverCollector.Middleware(handler)
+	assert.True(t, collector.IsCapturingHandler(wrapped))
+	assert.Same(t, wrapped, serverCollector.Middleware(wrapped), "wrapping the same handler again should be a no-op")
+
+	// Another middleware in between is reached through with Unwrap
+	other := &unwrappingHandler{next: wrapped}
+	assert.True(t, collector.IsCapturingHandler(other))
+	assert.Same(t, other, serverCollector.Middleware(other))
+
+	// A mux wrapped with a handler that is wrapped as well captures requests once
+	mux := http.NewServeMux()
+	mux.Handle("/inner", wrapped)
+	server := httptest.NewServer(serverCollector.Middleware(mux))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/inner")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "/inner", events[0].Data.(collector.HTTPServerRequest).Path)
+	assert.Empty(t, events[0].Children)
+}
+
+func TestHTTPServerCollector_WrappedByOtherCollectors(t *testing.T) {
+	first := collector.NewHTTPServerCollector()
+	second := collector.NewHTTPServerCollector()
+	collectFirst := Collect(t, first.Subscribe)
+	collectSecond := Collect(t, second.Subscribe)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	// Collectors of different instances capture independently
+	server := httptest.NewServer(second.Middleware(first.Middleware(handler)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Len(t, collectFirst.Stop(), 1)
+	assert.Len(t, collectSecond.Stop(), 1)
+}
+
+// unwrappingHandler is a middleware of another library that exposes the handler it wraps
+type unwrappingHandler struct {
+	next http.Handler
+}
+
+func (h *unwrappingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+func (h *unwrappingHandler) Unwrap() http.Handler {
+	return h.next
+}
+
+// discardResponseWriter is a response writer without allocations
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header         { return w.header }
+func (w discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w discardResponseWriter) WriteHeader(int)             {}
+
+func TestHTTPServerCollector_Disabled(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	var handled *http.Request
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled = r
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	aggregator.SetEnabled(false)
+
+	req := httptest.NewRequest(http.MethodPost, "/disabled", strings.NewReader("body"))
+	w := discardResponseWriter{header: make(http.Header)}
+	allocs := testing.AllocsPerRun(100, func() {
+		handler.ServeHTTP(w, req)
+	})
+
+	assert.Zero(t, allocs, "a disabled collector must not allocate")
+	assert.Same(t, req, handled, "the request is passed through unchanged")
+	assert.Empty(t, storage.GetEvents(10))
+
+	aggregator.SetEnabled(true)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/enabled", nil))
+	assert.Len(t, storage.GetEvents(10), 1)
+}
+
+func BenchmarkHTTPServerCollector_Middleware(b *testing.B) {
+	benchmarks := []struct {
+		name       string
+		middleware bool
+		capture    bool
+		bodySize   int
+	}{
+		{name: "without middleware/small body", bodySize: 256},
+		{name: "without middleware/large body", bodySize: 1024 * 1024},
+		{name: "without capture/small body", middleware: true, bodySize: 256},
+		{name: "without capture/large body", middleware: true, bodySize: 1024 * 1024},
+		{name: "with capture/small body", middleware: true, capture: true, bodySize: 256},
+		{name: "with capture/large body", middleware: true, capture: true, bodySize: 1024 * 1024},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			aggregator := collector.NewEventAggregator()
+			defer aggregator.Close()
+			if bm.capture {
+				storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+				defer storage.Close()
+				aggregator.RegisterStorage(storage)
+			}
+
+			body := bytes.Repeat([]byte("x"), bm.bodySize)
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(body)
+			})
+			if bm.middleware {
+				options := collector.DefaultHTTPServerOptions()
+				options.EventAggregator = aggregator
+				serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+				defer serverCollector.Close()
+				handler = serverCollector.Middleware(handler)
+			}
+
+			b.SetBytes(int64(2 * bm.bodySize))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				handler.ServeHTTP(discardResponseWriter{header: make(http.Header)}, req)
+			}
+		})
+	}
+}
+
+func FuzzHTTPServerCollector_Request(f *testing.F) {
+	f.Add("GET /search?q=devlog HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\nAccept: text/html\r\n\r\n")
+	f.Add("POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Type: multipart/form-data; boundary=xyz\r\nContent-Length: 98\r\n\r\n" +
+		"--xyz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\n\r\nhello\r\n--xyz--\r\n")
+	f.Add("POST /chunked HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n")
+	f.Add("PUT /h HTTP/1.1\r\nHost: example.com\r\nX-Empty:\r\nx-lower: a\r\nContent-Type: multipart/form-data\r\nContent-Length: 3\r\n\r\nabc")
+
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 1, collector.CaptureModeGlobal)
+	defer storage.Close()
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.MaxBodySize = 64
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+	defer serverCollector.Close()
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		req, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+		if err != nil {
+			return
+		}
+		requestHeaders := req.Header.Clone()
+
+		handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Parsing a malformed multipart form fails in the application, the capture must not
+			_ = r.ParseMultipartForm(1024)
+			_, _ = io.Copy(io.Discard, r.Body)
+
+			// Changes of the application after the request started are not captured
+			r.Header.Set("X-Changed", "true")
+			for key, values := range requestHeaders {
+				w.Header()["X-Echo-"+key] = values
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Header().Set("X-After-Write", "true")
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		events := storage.GetEvents(1)
+		if len(events) != 1 {
+			t.Fatalf("expected the request to be captured, got %d events", len(events))
+		}
+		captured := events[0].Data.(collector.HTTPServerRequest)
+		if len(requestHeaders) > 0 && !reflect.DeepEqual(captured.RequestHeaders, requestHeaders) {
+			t.Fatalf("expected request headers %v, got %v", requestHeaders, captured.RequestHeaders)
+		}
+		if captured.ResponseHeaders.Get("X-After-Write") != "" {
+			t.Fatal("response headers set after writing the header must not be captured")
+		}
+		if captured.RequestBody != nil && captured.RequestBody.Size() > 64 {
+			t.Fatalf("captured %d bytes of the request body over the limit", captured.RequestBody.Size())
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+	"unsafe"
+)
+
+// EventKindInternalError is the kind of events recording a panic in devlog or its extensions (see InternalError)
+const EventKindInternalError EventKind = "internal_error"
+
+// InternalError is collected when a panic in collector code (e.g. a transformer, a storage or a renderer) was recovered,
+// so a bug in devlog or its extensions does not crash the application
+type InternalError struct {
+	// Component is where the panic happened, e.g. "http_server_transformer"
+	Component string `json:"component"`
+	// Panic is the value the code panicked with
+	Panic string `json:"panic"`
+	// Stack is the stack trace of the panic
+	Stack string `json:"stack,omitempty"`
+	// Time is when the panic was recovered
+	Time time.Time `json:"time"`
+}
+
+var _ EventPayload = InternalError{}
+
+// newInternalError returns an internal error for a recovered panic with the current stack
+func newInternalError(component string, v any) InternalError {
+	return InternalError{
+		Component: component,
+		Panic:     fmt.Sprint(v),
+		Stack:     string(debug.Stack()),
+		Time:      time.Now(),
+	}
+}
+
+// Kind implements EventPayload
+func (e InternalError) Kind() EventKind {
+	return EventKindInternalError
+}
+
+// Summary implements EventPayload
+func (e InternalError) Summary() string {
+	return fmt.Sprintf("devlog internal error in %s: %s", e.Component, e.Panic)
+}
+
+// SearchText implements EventPayload
+func (e InternalError) SearchText() string {
+	return searchText(e.Summary(), e.Stack)
+}
+
+// Size returns the estimated memory size of this error in bytes
+func (e InternalError) Size() uint64 {
+	return uint64(unsafe.Sizeof(e)) + uint64(len(e.Component)+len(e.Panic)+len(e.Stack))
+}
+
+// MarshalJSON implements EventPayload
+func (e InternalError) MarshalJSON() ([]byte, error) {
+	type internalErrorJSON InternalError
+	return json.Marshal(internalErrorJSON(e))
+}
+
+// RecoveredPanics returns the number of panics in collector code that were recovered
+func (a *EventAggregator) RecoveredPanics() uint64 {
+	if a == nil {
+		return 0
+	}
+	return a.recoveredPanics.Load()
+}
+
+// RecordPanic counts a recovered panic and collects it as an InternalError event with ctx, e.g. nested under the
+// request that was collected when it happened. With a nil ctx, the panic is only counted and kept for diagnostics.
+// A nil aggregator ignores the panic.
+// Use it in extensions that recover panics themselves:
+//
+//	defer func() {
+//		if v := recover(); v != nil {
+//			aggregator.RecordPanic(ctx, "my_extension", v)
+//		}
+//	}()
+func (a *EventAggregator) RecordPanic(ctx context.Context, component string, v any) {
+	if a == nil {
+		return
+	}
+	internalErr := newInternalError(component, v)
+	a.countPanic(internalErr)
+	if ctx == nil || !a.ShouldCapture(ctx) {
+		return
+	}
+	a.CollectEvent(ctx, internalErr)
+}
+
+// recentInternalErrors is the number of recovered panics kept for diagnostics
+const recentInternalErrors = 20
+
+// countPanic counts a recovered panic and keeps it for diagnostics
+func (a *EventAggregator) countPanic(internalErr InternalError) {
+	a.recoveredPanics.Add(1)
+	a.internalErrors.Add(internalErr)
+	a.logger.Error("Recovered panic in devlog", "component", internalErr.Component, "panic", internalErr.Panic, "stack", internalErr.Stack)
+}
+
+// RecentInternalErrors returns the most recent recovered panics, oldest first, even if they were not captured
+func (a *EventAggregator) RecentInternalErrors() []InternalError {
+	return a.internalErrors.GetRecords(recentInternalErrors)
+}
+
+// transformSafely applies a transformer to a collected value. If the transformer panics, the value is returned unchanged
+// and the panic is recorded with eventCtx, which may be nil if the value is not captured.
+func transformSafely[T any](a *EventAggregator, eventCtx context.Context, component string, transform func(T) T, value T) (result T) {
+	defer func() {
+		if v := recover(); v != nil {
+			a.RecordPanic(eventCtx, component, v)
+			result = value
+		}
+	}()
+	return transform(value)
+}
@@ -24,7 +24,8 @@ func NewLimitedBuffer(limit int) *LimitedBuffer {
 // It writes data to the buffer up to the limit and marks the buffer as truncated
 // if the limit is exceeded.
 func (b *LimitedBuffer) Write(p []byte) (n int, err error) {
-	if b.truncated {
+	// An empty write does not exceed the limit, even if the buffer is full
+	if b.truncated || len(p) == 0 {
 		return len(p), nil
 	}
 
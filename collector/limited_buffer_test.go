@@ -0,0 +1,45 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func FuzzLimitedBuffer(f *testing.F) {
+	f.Add([]byte("hello world"), uint16(5), []byte{3, 0, 8})
+	f.Add([]byte("exact"), uint16(5), []byte{5, 0})
+	f.Add([]byte(""), uint16(0), []byte{0})
+	f.Add([]byte("\xff\xfe binary \x00"), uint16(1), []byte{1, 1, 1})
+
+	f.Fuzz(func(t *testing.T, data []byte, limit uint16, chunks []byte) {
+		buf := collector.NewLimitedBuffer(int(limit))
+
+		// Write data in chunks of the given sizes, the rest in one write
+		rest := data
+		for _, size := range chunks {
+			chunk := rest[:min(int(size), len(rest))]
+			n, err := buf.Write(chunk)
+			if err != nil || n != len(chunk) {
+				t.Fatalf("write of %d bytes returned %d, %v", len(chunk), n, err)
+			}
+			rest = rest[len(chunk):]
+		}
+		if n, err := buf.Write(rest); err != nil || n != len(rest) {
+			t.Fatalf("write of %d bytes returned %d, %v", len(rest), n, err)
+		}
+
+		expected := data[:min(int(limit), len(data))]
+		if string(buf.Bytes()) != string(expected) {
+			t.Fatalf("expected buffer %q, got %q", expected, buf.Bytes())
+		}
+		if truncated := len(data) > int(limit); buf.IsTruncated() != truncated {
+			t.Fatalf("expected truncated %v for %d bytes with limit %d", truncated, len(data), limit)
+		}
+
+		buf.Reset()
+		if buf.Len() != 0 || buf.IsTruncated() {
+			t.Fatal("expected empty buffer after reset")
+		}
+	})
+}
@@ -3,7 +3,11 @@ package collector
 import (
 	"context"
 	"log/slog"
+	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/samber/lo"
 )
@@ -14,6 +18,9 @@ type LogCollector struct {
 }
 
 func (c *LogCollector) Collect(ctx context.Context, record slog.Record) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
 	c.notifier.Notify(record)
 	if c.eventAggregator != nil {
 		c.eventAggregator.CollectEvent(ctx, record)
@@ -61,17 +68,76 @@ func (c *LogCollector) Close() {
 type CollectSlogLogsOptions struct {
 	// Level is the minimum level of logs to collect.
 	Level slog.Level
+
+	// ComponentLevels overrides the minimum level for records of a component, e.g. {"sql": slog.LevelWarn}.
+	// The component is read from the attribute with key ComponentKey.
+	ComponentLevels map[string]slog.Level
+
+	// ComponentKey is the attribute key identifying the component (or logger name) of a record.
+	// Default: "component"
+	ComponentKey string
+
+	// MatchAttrs only collects records having all the given attribute values (compared as strings), e.g. {"component": "http"}.
+	// Attributes added with Logger.With are matched as well, attributes inside groups are not.
+	MatchAttrs map[string]string
+
+	// MessagePattern only collects records with a message matching the pattern if set
+	MessagePattern *regexp.Regexp
+
+	// AddSource resolves the source location of the log call and adds it as a LogSource attribute with key slog.SourceKey.
+	AddSource bool
+
+	// StackLevel is the minimum level to add a short stack trace as a LogStack attribute with key StackKey.
+	// Requires AddSource, nil disables stack traces.
+	StackLevel slog.Leveler
+
+	// SourceURL is a template for links to source locations, e.g. "vscode://file/{file}:{line}".
+	// The placeholders {file} and {line} are replaced. Source locations are not linked if empty.
+	SourceURL string
 }
 
+// DefaultComponentKey is the attribute key identifying the component of a record if CollectSlogLogsOptions.ComponentKey is not set
+const DefaultComponentKey = "component"
+
+// StackKey is the key of the stack trace attribute added if CollectSlogLogsOptions.StackLevel is set
+const StackKey = "stack"
+
+// maxStackFrames limits the number of frames of a captured stack trace
+const maxStackFrames = 10
+
+// LogSource is the resolved source location of a log call or stack frame
+type LogSource struct {
+	Function string
+	File     string
+	Line     int
+	// URL to open the source location, empty if CollectSlogLogsOptions.SourceURL is not set
+	URL string
+}
+
+// String returns the location as file:line
+func (s LogSource) String() string {
+	return s.File + ":" + strconv.Itoa(s.Line)
+}
+
+// LogStack is a short stack trace starting at the log call
+type LogStack []LogSource
+
 type SlogLogCollectorHandler struct {
 	collector *LogCollector
 	options   CollectSlogLogsOptions
 
 	attrs  []slog.Attr
 	groups []string
+
+	// component is set if an attribute with the component key was added with WithAttrs
+	component string
 }
 
 func NewSlogLogCollectorHandler(collector *LogCollector, options CollectSlogLogsOptions) *SlogLogCollectorHandler {
+	if options.ComponentKey == "" {
+		options.ComponentKey = DefaultComponentKey
+	}
+
 	return &SlogLogCollectorHandler{
 		collector: collector,
 		options:   options,
@@ -82,10 +148,78 @@ func NewSlogLogCollectorHandler(collector *LogCollector, options CollectSlogLogs
 }
 
 func (h *SlogLogCollectorHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return h.options.Level <= level
+	if !h.collector.eventAggregator.Enabled() {
+		return false
+	}
+	if h.component != "" {
+		return h.minLevel(h.component) <= level
+	}
+	// The component of the record is not known yet, so the lowest level of all components applies
+	minLevel := h.options.Level
+	for _, componentLevel := range h.options.ComponentLevels {
+		minLevel = min(minLevel, componentLevel)
+	}
+	return minLevel <= level
+}
+
+func (h *SlogLogCollectorHandler) minLevel(component string) slog.Level {
+	if level, ok := h.options.ComponentLevels[component]; ok {
+		return level
+	}
+	return h.options.Level
+}
+
+// shouldCollect applies the capture rules of the options to the record
+func (h *SlogLogCollectorHandler) shouldCollect(record slog.Record) bool {
+	component := h.component
+	if value, ok := h.lookupAttr(record, h.options.ComponentKey); ok {
+		component = value
+	}
+	if record.Level < h.minLevel(component) {
+		return false
+	}
+
+	if h.options.MessagePattern != nil && !h.options.MessagePattern.MatchString(record.Message) {
+		return false
+	}
+
+	for key, expected := range h.options.MatchAttrs {
+		if value, ok := h.lookupAttr(record, key); !ok || value != expected {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookupAttr finds a top-level attribute of the record or added with WithAttrs, record attributes take precedence
+func (h *SlogLogCollectorHandler) lookupAttr(record slog.Record, key string) (value string, found bool) {
+	// Record attributes are nested in the groups of the handler
+	if len(h.groups) == 0 {
+		record.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == key {
+				value, found = attr.Value.String(), true
+				return false
+			}
+			return true
+		})
+		if found {
+			return value, true
+		}
+	}
+	for _, attr := range slices.Backward(h.attrs) {
+		if attr.Key == key {
+			return attr.Value.String(), true
+		}
+	}
+	return "", false
 }
 
 func (h *SlogLogCollectorHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.shouldCollect(record) {
+		return nil
+	}
+
 	// Clone the record and add the handlers attributes to the new record.
 	// I could not just do `record.AddAttrs(h.attrs...)` because h.Attrs must be added before record.Attrs.
 	newRecord := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
@@ -106,18 +240,36 @@ func (h *SlogLogCollectorHandler) Handle(ctx context.Context, record slog.Record
 	}
 	newRecord.AddAttrs(attrs...)
 
+	if h.options.AddSource && record.PC != 0 {
+		newRecord.AddAttrs(slog.Any(slog.SourceKey, h.resolveSource(record.PC)))
+		if h.options.StackLevel != nil && record.Level >= h.options.StackLevel.Level() {
+			newRecord.AddAttrs(slog.Any(StackKey, h.captureStack(record.PC)))
+		}
+	}
+
 	h.collector.Collect(ctx, newRecord)
 
 	return nil
 }
 
 func (h *SlogLogCollectorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	if len(h.groups) == 0 {
+		for _, attr := range attrs {
+			if attr.Key == h.options.ComponentKey {
+				component = attr.Value.String()
+			}
+		}
+	}
+
 	return &SlogLogCollectorHandler{
 		collector: h.collector,
 		options:   h.options,
 
 		attrs:  appendAttrsToGroup(h.groups, h.attrs, attrs...),
 		groups: h.groups,
+
+		component: component,
 	}
 }
 
@@ -133,7 +285,53 @@ func (h *SlogLogCollectorHandler) WithGroup(name string) slog.Handler {
 
 		attrs:  h.attrs,
 		groups: append(h.groups, name),
+
+		component: h.component,
+	}
+}
+
+func (h *SlogLogCollectorHandler) resolveSource(pc uintptr) LogSource {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return h.toLogSource(frame)
+}
+
+// captureStack returns the frames of the current goroutine starting at the log call.
+// Falls back to only the log call if it is not part of the current stack (e.g. a custom PC was set).
+func (h *SlogLogCollectorHandler) captureStack(pc uintptr) LogStack {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	i := slices.Index(pcs, pc)
+	if i == -1 {
+		return LogStack{h.resolveSource(pc)}
+	}
+	pcs = pcs[i:]
+
+	stack := make(LogStack, 0, maxStackFrames)
+	frames := runtime.CallersFrames(pcs)
+	for len(stack) < maxStackFrames {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+		stack = append(stack, h.toLogSource(frame))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func (h *SlogLogCollectorHandler) toLogSource(frame runtime.Frame) LogSource {
+	source := LogSource{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+	if h.options.SourceURL != "" {
+		source.URL = strings.NewReplacer("{file}", frame.File, "{line}", strconv.Itoa(frame.Line)).Replace(h.options.SourceURL)
 	}
+	return source
 }
 
 // Copied from github.com/samber/slog-mock
@@ -0,0 +1,151 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestSlogLogCollectorHandler_AddSource(t *testing.T) {
+	logCollector := collector.NewLogCollector()
+	defer logCollector.Close()
+
+	records := Collect(t, logCollector.Subscribe)
+
+	logger := slog.New(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{
+		Level:      slog.LevelDebug,
+		AddSource:  true,
+		StackLevel: slog.LevelError,
+		SourceURL:  "vscode://file/{file}:{line}",
+	}))
+
+	logger.Info("Info message")
+	logger.Error("Error message")
+
+	collected := records.Wait(2)
+
+	infoAttrs := recordAttrs(collected[0])
+	source, ok := infoAttrs[slog.SourceKey].(collector.LogSource)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(source.File, "log_collector_test.go"))
+	assert.Equal(t, "github.com/networkteam/devlog/collector_test.TestSlogLogCollectorHandler_AddSource", source.Function)
+	assert.Equal(t, fmt.Sprintf("vscode://file/%s:%d", source.File, source.Line), source.URL)
+	assert.NotContains(t, infoAttrs, collector.StackKey)
+
+	errorAttrs := recordAttrs(collected[1])
+	stack, ok := errorAttrs[collector.StackKey].(collector.LogStack)
+	require.True(t, ok)
+	require.NotEmpty(t, stack)
+	assert.Equal(t, errorAttrs[slog.SourceKey], stack[0])
+	assert.Equal(t, source.Line+1, stack[0].Line)
+}
+
+func TestSlogLogCollectorHandler_WithoutSource(t *testing.T) {
+	logCollector := collector.NewLogCollector()
+	defer logCollector.Close()
+
+	records := Collect(t, logCollector.Subscribe)
+
+	logger := slog.New(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{}))
+	logger.Info("Info message", "foo", "bar")
+
+	collected := records.Wait(1)
+	assert.Equal(t, map[string]any{"foo": "bar"}, recordAttrs(collected[0]))
+}
+
+func recordAttrs(record slog.Record) map[string]any {
+	attrs := make(map[string]any)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.Any()
+		return true
+	})
+	return attrs
+}
+
+func TestSlogLogCollectorHandler_CaptureRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  collector.CollectSlogLogsOptions
+		expected []string
+	}{
+		{
+			name: "component levels",
+			options: collector.CollectSlogLogsOptions{
+				Level: slog.LevelInfo,
+				ComponentLevels: map[string]slog.Level{
+					"sql":  slog.LevelWarn,
+					"http": slog.LevelDebug,
+				},
+			},
+			expected: []string{"http debug", "http info", "sql warn", "app info", "record component"},
+		},
+		{
+			name: "match attrs",
+			options: collector.CollectSlogLogsOptions{
+				Level:      slog.LevelDebug,
+				MatchAttrs: map[string]string{"component": "http"},
+			},
+			expected: []string{"http debug", "http info", "record component"},
+		},
+		{
+			name: "message pattern",
+			options: collector.CollectSlogLogsOptions{
+				Level:          slog.LevelDebug,
+				MessagePattern: regexp.MustCompile(`^sql `),
+			},
+			expected: []string{"sql info", "sql warn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logCollector := collector.NewLogCollector()
+			defer logCollector.Close()
+
+			records := Collect(t, logCollector.Subscribe)
+
+			logger := slog.New(collector.NewSlogLogCollectorHandler(logCollector, tt.options))
+			httpLogger := logger.With("component", "http")
+			sqlLogger := logger.With("component", "sql")
+
+			httpLogger.Debug("http debug")
+			httpLogger.Info("http info")
+			sqlLogger.Info("sql info")
+			sqlLogger.Warn("sql warn")
+			logger.Debug("app debug")
+			logger.Info("app info")
+			logger.Debug("record component", "component", "http")
+			// Wait for a final record that passes all rules to know that all previous records were handled
+			slog.New(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{})).Info("done")
+
+			collected := records.Wait(len(tt.expected) + 1)
+			messages := make([]string, 0, len(collected))
+			for _, record := range collected[:len(collected)-1] {
+				messages = append(messages, record.Message)
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestSlogLogCollectorHandler_Disabled(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	logCollector := collector.NewLogCollectorWithOptions(collector.LogOptions{EventAggregator: aggregator})
+	defer logCollector.Close()
+	handler := collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{Level: slog.LevelDebug})
+
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelInfo))
+
+	aggregator.SetEnabled(false)
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelError), "records are not even created while disabled")
+}
@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler is a slog.Handler that discards all records, so devlog is silent without a configured logger
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// loggerOrDiscard returns the logger or a logger that discards everything if it is nil
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(discardHandler{})
+	}
+	return logger
+}
+
+// loggingStorage is implemented by storages that log internal problems (e.g. dropped notifications)
+// with the logger of the aggregator they are registered with
+type loggingStorage interface {
+	setLogger(logger *slog.Logger)
+}
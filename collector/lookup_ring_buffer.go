@@ -2,128 +2,117 @@ package collector
 
 import (
 	"iter"
-	"sync"
 )
 
 type Visitable[S comparable, T any] interface {
 	Visit() iter.Seq2[S, T]
 }
 
+// lookupIndex indexes all entries visited of the records of a ring buffer by their keys
+type lookupIndex[T Visitable[S, T], S comparable] map[S]T
+
+func (l lookupIndex[T, S]) insert(record T) {
+	for id, entry := range record.Visit() {
+		l[id] = entry
+	}
+}
+
+func (l lookupIndex[T, S]) remove(record T) {
+	for id := range record.Visit() {
+		delete(l, id)
+	}
+}
+
+func (l lookupIndex[T, S]) reset() {
+	clear(l)
+}
+
 // LookupRingBuffer is a thread-safe ring buffer with lookup functionality
 type LookupRingBuffer[T Visitable[S, T], S comparable] struct {
-	buffer     []T
-	lookup     map[S]T
-	size       uint64
-	capacity   uint64
-	writeIndex uint64
-	mu         sync.RWMutex
+	ringBuffer[T]
+	lookup lookupIndex[T, S]
 }
 
 // NewLookupRingBuffer creates a new ring buffer with the given capacity
 func NewLookupRingBuffer[T Visitable[S, T], S comparable](capacity uint64) *LookupRingBuffer[T, S] {
-	if capacity == 0 {
-		panic("capacity must be greater than 0")
-	}
-
-	return &LookupRingBuffer[T, S]{
-		buffer:     make([]T, capacity),
-		lookup:     make(map[S]T, capacity),
-		capacity:   capacity,
-		size:       0,
-		writeIndex: 0,
+	rb := &LookupRingBuffer[T, S]{
+		lookup: make(lookupIndex[T, S], capacity),
 	}
+	rb.init(capacity, rb.lookup)
+	return rb
 }
 
-// Add adds an entry to the buffer
-func (rb *LookupRingBuffer[T, S]) Add(record T) {
-	rb.mu.Lock()
-	defer rb.mu.Unlock()
-
-	index := rb.writeIndex % rb.capacity
-
-	lostRecord := rb.buffer[index]
+// Add adds an entry to the buffer and returns the oldest entry if it was overwritten
+func (rb *LookupRingBuffer[T, S]) Add(record T) (evicted T, ok bool) {
+	return rb.add(record)
+}
 
-	// Write at current position
-	rb.buffer[index] = record
+// Replace replaces the record with the same identity (the first key visited) in place and reports whether it was found.
+// The position of the record in the buffer is kept.
+func (rb *LookupRingBuffer[T, S]) Replace(record T) bool {
+	identity, ok := firstKey[S](record)
+	if !ok {
+		return false
+	}
+	if _, exists := rb.Lookup(identity); !exists {
+		return false
+	}
 
-	// Increment write index
-	rb.writeIndex++
+	return rb.replace(func(existing T) bool {
+		key, _ := firstKey[S](existing)
+		return key == identity
+	}, record)
+}
 
-	// Update size (up to capacity)
-	if rb.size < rb.capacity {
-		rb.size++
-	} else {
-		for id := range lostRecord.Visit() {
-			// Remove the old entries from the lookup map
-			delete(rb.lookup, id)
-		}
+// firstKey returns the first key visited of a record, which is the identity of the record itself
+func firstKey[S comparable, T Visitable[S, T]](record T) (S, bool) {
+	for id := range record.Visit() {
+		return id, true
 	}
+	var empty S
+	return empty, false
+}
 
-	// Add references to the new entries to the lookup map
-	for id, entry := range record.Visit() {
-		rb.lookup[id] = entry
+// Remove removes the record with the given identity (the first key visited) and returns it.
+// Entries nested in other records can't be removed.
+func (rb *LookupRingBuffer[T, S]) Remove(identity S) (T, bool) {
+	removed := rb.RemoveWhere(func(record T) bool {
+		key, _ := firstKey[S](record)
+		return key == identity
+	})
+	if len(removed) == 0 {
+		var empty T
+		return empty, false
 	}
+	return removed[0], true
+}
+
+// RemoveWhere removes all records matching the predicate and returns them, the order of the remaining records is kept
+func (rb *LookupRingBuffer[T, S]) RemoveWhere(match func(record T) bool) []T {
+	return rb.removeWhere(match)
 }
 
 // GetRecords returns a slice of the most recent n records
 func (rb *LookupRingBuffer[T, S]) GetRecords(n uint64) []T {
-	rb.mu.RLock()
-	defer rb.mu.RUnlock()
-
-	// Get the actual number of records to return
-	count := min(n, rb.size)
-	if count == 0 {
-		return []T{}
-	}
-
-	result := make([]T, count)
-
-	// Calculate the starting index
-	startIdx := rb.writeIndex - count
-	for i := uint64(0); i < count; i++ {
-		// Use modulo to wrap around the buffer
-		result[i] = rb.buffer[(startIdx+i)%rb.capacity]
-	}
+	return rb.records(n)
+}
 
-	return result
+// Iterate iterates over the records oldest first. The buffer is locked while iterating, so the loop body must not
+// call methods of the buffer.
+func (rb *LookupRingBuffer[T, S]) Iterate() iter.Seq[T] {
+	return rb.all()
 }
 
+// Lookup returns a record or a nested entry of a record by its key
 func (rb *LookupRingBuffer[T, S]) Lookup(identity S) (T, bool) {
 	rb.mu.RLock()
 	defer rb.mu.RUnlock()
 
 	record, found := rb.lookup[identity]
-	if found {
-		return record, true
-	}
-
-	var empty T
-	return empty, false
-
-}
-
-// Size returns the current number of records in the buffer
-func (rb *LookupRingBuffer[T, S]) Size() uint64 {
-	rb.mu.RLock()
-	defer rb.mu.RUnlock()
-	return rb.size
-}
-
-// Capacity returns the maximum capacity of the buffer
-func (rb *LookupRingBuffer[T, S]) Capacity() uint64 {
-	return rb.capacity
+	return record, found
 }
 
+// Clear removes all records
 func (rb *LookupRingBuffer[T, S]) Clear() {
-	rb.mu.Lock()
-	defer rb.mu.Unlock()
-
-	// Reset the lookup map
-	rb.lookup = make(map[S]T)
-	// Set all entries in the buffer to their zero value
-	for i := range rb.buffer {
-		var item T
-		rb.buffer[i] = item
-	}
-	rb.size = 0
+	rb.clearRecords()
 }
@@ -221,3 +221,109 @@ func TestLookupRingBuffer_LargeCapacity(t *testing.T) {
 	assert.Equal(t, "1990", records[0].ID)
 	assert.Equal(t, "1999", records[9].ID)
 }
+
+func TestLookupRingBuffer_Replace(t *testing.T) {
+	rb := collector.NewLookupRingBuffer[*testRecord, string](3)
+
+	// Replacing an unknown record does nothing
+	assert.False(t, rb.Replace(&testRecord{ID: "1", Data: "preview"}))
+	assert.Equal(t, uint64(0), rb.Size())
+
+	// Wrap around the buffer, so the replaced record is not at the start
+	for i := 1; i <= 4; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i), Data: fmt.Sprintf("data%d", i)})
+	}
+
+	assert.True(t, rb.Replace(&testRecord{ID: "3", Data: "updated"}))
+	assert.Equal(t, uint64(3), rb.Size())
+
+	found, exists := rb.Lookup("3")
+	assert.True(t, exists)
+	assert.Equal(t, "updated", found.Data)
+
+	// The position of the replaced record is kept
+	records := rb.GetRecords(3)
+	assert.Equal(t, []string{"2", "3", "4"}, []string{records[0].ID, records[1].ID, records[2].ID})
+	assert.Equal(t, "updated", records[1].Data)
+
+	// Overwritten records can not be replaced
+	assert.False(t, rb.Replace(&testRecord{ID: "1", Data: "updated"}))
+}
+
+func TestLookupRingBuffer_RemoveWhere(t *testing.T) {
+	rb := collector.NewLookupRingBuffer[*testRecord, string](4)
+
+	// Wrap around the buffer, so the remaining records have to be compacted
+	for i := 1; i <= 6; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i), Data: fmt.Sprintf("data%d", i%2)})
+	}
+
+	removed := rb.RemoveWhere(func(r *testRecord) bool { return r.Data == "data1" })
+	assert.Equal(t, []string{"3", "5"}, []string{removed[0].ID, removed[1].ID})
+	assert.Equal(t, uint64(2), rb.Size())
+
+	_, exists := rb.Lookup("3")
+	assert.False(t, exists)
+
+	records := rb.GetRecords(4)
+	assert.Equal(t, []string{"4", "6"}, []string{records[0].ID, records[1].ID})
+
+	// New records are added after the remaining ones and the oldest is overwritten when full
+	for i := 7; i <= 9; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i)})
+	}
+	records = rb.GetRecords(4)
+	assert.Equal(t, []string{"6", "7", "8", "9"}, []string{records[0].ID, records[1].ID, records[2].ID, records[3].ID})
+
+	assert.Nil(t, rb.RemoveWhere(func(r *testRecord) bool { return false }))
+}
+
+func TestLookupRingBuffer_Remove(t *testing.T) {
+	rb := collector.NewLookupRingBuffer[*testRecord, string](3)
+	for i := 1; i <= 3; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i)})
+	}
+
+	removed, ok := rb.Remove("2")
+	assert.True(t, ok)
+	assert.Equal(t, "2", removed.ID)
+
+	_, exists := rb.Lookup("2")
+	assert.False(t, exists)
+	records := rb.GetRecords(3)
+	assert.Equal(t, []string{"1", "3"}, []string{records[0].ID, records[1].ID})
+
+	_, ok = rb.Remove("2")
+	assert.False(t, ok)
+}
+
+func TestLookupRingBuffer_Iterate(t *testing.T) {
+	rb := collector.NewLookupRingBuffer[*testRecord, string](3)
+	for i := 1; i <= 4; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i)})
+	}
+
+	var ids []string
+	for record := range rb.Iterate() {
+		ids = append(ids, record.ID)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, ids)
+}
+
+func TestLookupRingBuffer_Clear(t *testing.T) {
+	rb := collector.NewLookupRingBuffer[*testRecord, string](3)
+	for i := 1; i <= 4; i++ {
+		rb.Add(&testRecord{ID: fmt.Sprintf("%d", i)})
+	}
+
+	rb.Clear()
+	assert.Equal(t, uint64(0), rb.Size())
+	_, exists := rb.Lookup("4")
+	assert.False(t, exists)
+
+	// Adding after clearing starts with an empty buffer, no record is evicted
+	_, evicted := rb.Add(&testRecord{ID: "5"})
+	assert.False(t, evicted)
+	_, exists = rb.Lookup("5")
+	assert.True(t, exists)
+}
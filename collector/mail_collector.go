@@ -0,0 +1,147 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// DefaultMaxMailBodySize is the default maximum size of each captured body of a mail
+const DefaultMaxMailBodySize = 256 * 1024 // 256KB
+
+// Mail represents a captured outgoing email
+type Mail struct {
+	// From is the sender address
+	From string
+	// To, Cc and Bcc are the recipient addresses, Bcc are the envelope recipients not listed in the headers
+	To  []string
+	Cc  []string
+	Bcc []string
+	// Subject of the mail
+	Subject string
+	// TextBody is the rendered plain text body
+	TextBody string
+	// HTMLBody is the rendered HTML body
+	HTMLBody string
+	// BodyTruncated is true if a body was longer than the maximum size of the collector
+	BodyTruncated bool
+	// Attachments of the mail, their content is not captured
+	Attachments []MailAttachment
+	// Server is the address of the mail server (optional)
+	Server string
+	// Timestamp when sending was started
+	Timestamp time.Time
+	// Duration of sending
+	Duration time.Duration
+	// Error if sending failed
+	Error error
+}
+
+// MailAttachment is the metadata of an attachment or inline part of a mail
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	// Size of the decoded content in bytes
+	Size int
+	// Inline is true for parts shown in the body, e.g. images referenced by the HTML body
+	Inline bool
+}
+
+// Recipients returns all recipient addresses
+func (m Mail) Recipients() []string {
+	recipients := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	recipients = append(recipients, m.To...)
+	recipients = append(recipients, m.Cc...)
+	return append(recipients, m.Bcc...)
+}
+
+// Size returns the estimated memory size of this mail in bytes
+func (m Mail) Size() uint64 {
+	size := uint64(unsafe.Sizeof(m))
+	size += uint64(len(m.From) + len(m.Subject) + len(m.TextBody) + len(m.HTMLBody) + len(m.Server))
+	for _, addresses := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, address := range addresses {
+			size += uint64(unsafe.Sizeof(address)) + uint64(len(address))
+		}
+	}
+	for _, attachment := range m.Attachments {
+		size += uint64(unsafe.Sizeof(attachment)) + uint64(len(attachment.Filename)+len(attachment.ContentType))
+	}
+	size += errorSize(m.Error)
+	return size
+}
+
+type MailCollector struct {
+	notifier        *Notifier[Mail]
+	eventAggregator *EventAggregator
+	maxBodySize     int
+}
+
+// Collect collects a sent mail, its bodies are truncated to the maximum body size
+func (c *MailCollector) Collect(ctx context.Context, mail Mail) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
+	var truncated bool
+	mail.TextBody, truncated = truncateBody(mail.TextBody, c.maxBodySize)
+	mail.BodyTruncated = mail.BodyTruncated || truncated
+	mail.HTMLBody, truncated = truncateBody(mail.HTMLBody, c.maxBodySize)
+	mail.BodyTruncated = mail.BodyTruncated || truncated
+	c.notifier.Notify(mail)
+	if c.eventAggregator != nil {
+		c.eventAggregator.CollectEvent(ctx, mail)
+	}
+}
+
+// truncateBody cuts body to at most maxSize bytes, a character split at the end is dropped
+func truncateBody(body string, maxSize int) (string, bool) {
+	if maxSize <= 0 || len(body) <= maxSize {
+		return body, false
+	}
+	return strings.ToValidUTF8(body[:maxSize], ""), true
+}
+
+// Subscribe returns a channel that receives notifications of new mails
+func (c *MailCollector) Subscribe(ctx context.Context) <-chan Mail {
+	return c.notifier.Subscribe(ctx)
+}
+
+type MailOptions struct {
+	// MaxBodySize is the maximum size of each captured body of a mail, 0 captures complete bodies
+	MaxBodySize int
+
+	// NotifierOptions are options for notification about new mails
+	NotifierOptions *NotifierOptions
+
+	// EventAggregator is the aggregator for collecting mails as grouped events
+	EventAggregator *EventAggregator
+}
+
+func DefaultMailOptions() MailOptions {
+	return MailOptions{
+		MaxBodySize: DefaultMaxMailBodySize,
+	}
+}
+
+func NewMailCollector() *MailCollector {
+	return NewMailCollectorWithOptions(DefaultMailOptions())
+}
+
+func NewMailCollectorWithOptions(options MailOptions) *MailCollector {
+	notifierOptions := DefaultNotifierOptions()
+	if options.NotifierOptions != nil {
+		notifierOptions = *options.NotifierOptions
+	}
+
+	return &MailCollector{
+		notifier:        NewNotifierWithOptions[Mail](notifierOptions),
+		eventAggregator: options.EventAggregator,
+		maxBodySize:     options.MaxBodySize,
+	}
+}
+
+// Close releases resources used by the collector
+func (c *MailCollector) Close() {
+	c.notifier.Close()
+}
@@ -0,0 +1,64 @@
+package collector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestMailCollector_TruncatesBodies(t *testing.T) {
+	mailCollector := collector.NewMailCollectorWithOptions(collector.MailOptions{MaxBodySize: 10})
+	defer mailCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mails := mailCollector.Subscribe(ctx)
+
+	mailCollector.Collect(ctx, collector.Mail{
+		Subject:  "Welcome",
+		TextBody: "Hello",
+		HTMLBody: "<p>" + strings.Repeat("ü", 10) + "</p>",
+	})
+
+	m := <-mails
+	assert.Equal(t, "Hello", m.TextBody)
+	assert.Equal(t, "<p>üüü", m.HTMLBody, "a character split at the end is dropped")
+	assert.True(t, m.BodyTruncated)
+}
+
+func TestMail_JSON(t *testing.T) {
+	m := collector.Mail{
+		From:     "shop@example.com",
+		To:       []string{"jane@example.com"},
+		Bcc:      []string{"audit@example.com"},
+		Subject:  "Your order",
+		TextBody: "Thanks!",
+		HTMLBody: "<p>Thanks!</p>",
+		Attachments: []collector.MailAttachment{
+			{Filename: "invoice.pdf", ContentType: "application/pdf", Size: 1024},
+		},
+		Server:    "localhost:1025",
+		Timestamp: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  20 * time.Millisecond,
+		Error:     errors.New("550 mailbox unavailable"),
+	}
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	var decoded collector.Mail
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m.Attachments, decoded.Attachments)
+	assert.Equal(t, m.Bcc, decoded.Bcc)
+	assert.Equal(t, m.Duration, decoded.Duration)
+	assert.EqualError(t, decoded.Error, "550 mailbox unavailable")
+	assert.Equal(t, "Your order to jane@example.com, audit@example.com: 550 mailbox unavailable", decoded.Summary())
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"sync"
+
+	"github.com/gofrs/uuid"
+)
+
+// StorageStats holds the memory usage of a storage, it is updated incrementally when events are added or removed
+type StorageStats struct {
+	StorageID   uuid.UUID `json:"storageId"`
+	EventCount  int       `json:"eventCount"`
+	MemoryBytes uint64    `json:"memoryBytes"`
+	Capacity    uint64    `json:"capacity"`
+	// DroppedNotifications is the number of events that were not sent to subscribers (e.g. dashboards) in time
+	DroppedNotifications uint64 `json:"droppedNotifications"`
+}
+
+// memoryTrackingStorage is implemented by storages that account their memory usage incrementally
+type memoryTrackingStorage interface {
+	Stats() StorageStats
+	// setMemoryTracker adds the events of the storage to the tracker and removes them from the previous tracker
+	setMemoryTracker(tracker *memoryTracker)
+}
+
+// totalSize returns the memory size of an event including all its children
+func (e *Event) totalSize() uint64 {
+	var size uint64
+	for _, evt := range e.Visit() {
+		size += evt.Size
+	}
+	return size
+}
+
+// memoryTracker accounts the memory of events across storages, events stored in multiple storages are counted once.
+// It references the bodies of tracked events in their BodyBufferPool, so bodies are released when no storage holds them.
+type memoryTracker struct {
+	mu     sync.Mutex
+	events map[*Event]*trackedEvent
+	total  uint64
+}
+
+type trackedEvent struct {
+	refs int
+	size uint64
+}
+
+func newMemoryTracker() *memoryTracker {
+	return &memoryTracker{events: make(map[*Event]*trackedEvent)}
+}
+
+// add references an event of a storage
+func (t *memoryTracker) add(event *Event, size uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tracked, ok := t.events[event]; ok {
+		tracked.refs++
+		return
+	}
+	t.events[event] = &trackedEvent{refs: 1, size: size}
+	t.total += size
+	eventBodies(event, (*BodyBufferPool).retain)
+}
+
+// remove releases the reference of a storage to an event
+func (t *memoryTracker) remove(event *Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.events[event]
+	if !ok {
+		return
+	}
+	tracked.refs--
+	if tracked.refs == 0 {
+		delete(t.events, event)
+		t.total -= tracked.size
+		eventBodies(event, (*BodyBufferPool).release)
+	}
+}
+
+// totals returns the memory and number of all tracked events
+func (t *memoryTracker) totals() (memory uint64, count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.total, len(t.events)
+}
@@ -2,19 +2,33 @@ package collector
 
 import (
 	"context"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 )
 
-// Notifier is a generic notification system for collected data
+// Notifier is a generic notification system for collected data.
+// It is safe to notify, subscribe and unsubscribe concurrently with Close.
 type Notifier[T any] struct {
 	mu sync.RWMutex
 	// subscribers holds the channels for each subscriber while allowing to find a subscribe by its read channel
 	subscribers map[<-chan T]chan T
 	bufferSize  int
-	notifyCh    chan T
-	closeOnce   sync.Once
 	closed      bool
-	pending     sync.WaitGroup // tracks items in notification pipeline
+
+	// notifyCh is never closed, so senders cannot panic when the notifier is closed concurrently
+	notifyCh  chan notification[T]
+	closeOnce sync.Once
+	done      chan struct{} // closed to stop processing notifications
+
+	dropped atomic.Uint64
+	logger  atomic.Pointer[slog.Logger]
+}
+
+// notification is an item to distribute or a barrier that is closed when all previous items were distributed
+type notification[T any] struct {
+	item    T
+	flushed chan struct{}
 }
 
 // NotifierOptions configures a notifier
@@ -24,6 +38,10 @@ type NotifierOptions struct {
 
 	// NotificationBufferSize is the buffer size for the internal notification channel
 	NotificationBufferSize int
+
+	// Logger is used to log dropped notifications.
+	// Default: nil, nothing is logged
+	Logger *slog.Logger
 }
 
 // DefaultNotifierOptions returns default options for a notifier
@@ -44,8 +62,10 @@ func NewNotifierWithOptions[T any](options NotifierOptions) *Notifier[T] {
 	n := &Notifier[T]{
 		subscribers: make(map[<-chan T]chan T),
 		bufferSize:  options.SubscriberBufferSize,
-		notifyCh:    make(chan T, options.NotificationBufferSize),
+		notifyCh:    make(chan notification[T], options.NotificationBufferSize),
+		done:        make(chan struct{}),
 	}
+	n.setLogger(options.Logger)
 
 	// Start background goroutine to handle notifications
 	go n.processNotifications()
@@ -56,36 +76,35 @@ func NewNotifierWithOptions[T any](options NotifierOptions) *Notifier[T] {
 // Subscribe returns a channel that receives notifications
 // The context is used to automatically unsubscribe when done
 func (n *Notifier[T]) Subscribe(ctx context.Context) <-chan T {
-	n.mu.RLock()
+	// Create a new buffered channel for this subscriber
+	ch := make(chan T, n.bufferSize)
+
+	n.mu.Lock()
 	if n.closed {
-		n.mu.RUnlock()
+		n.mu.Unlock()
 		// Return a closed channel if the notifier is already closed
-		ch := make(chan T)
 		close(ch)
 		return ch
 	}
-	n.mu.RUnlock()
-
-	// Create a new buffered channel for this subscriber
-	ch := make(chan T, n.bufferSize)
-
-	n.mu.Lock()
 	n.subscribers[ch] = ch
 	n.mu.Unlock()
 
 	// Auto-unsubscribe when context is done
 	go func() {
-		<-ctx.Done()
-		n.Unsubscribe(ch)
+		select {
+		case <-ctx.Done():
+			n.Unsubscribe(ch)
+		case <-n.done:
+			// Closed with the notifier
+		}
 	}()
 
 	return ch
 }
 
-// Unsubscribe removes a subscription
+// Unsubscribe removes a subscription, notifications sent before are still delivered
 func (n *Notifier[T]) Unsubscribe(ch <-chan T) {
-	// Wait for any pending notifications to be distributed
-	n.pending.Wait()
+	n.flush()
 
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -101,28 +120,47 @@ func (n *Notifier[T]) Unsubscribe(ch <-chan T) {
 // This is non-blocking - if the internal channel is full, the notification is dropped
 func (n *Notifier[T]) Notify(item T) {
 	n.mu.RLock()
-	if n.closed {
-		n.mu.RUnlock()
+	closed := n.closed
+	n.mu.RUnlock()
+	if closed {
 		return
 	}
-	n.mu.RUnlock()
 
-	n.pending.Add(1)
 	// Non-blocking send to notification channel
 	select {
-	case n.notifyCh <- item:
+	case n.notifyCh <- notification[T]{item: item}:
 		// Successfully sent
 	default:
 		// Channel full, drop notification
-		n.pending.Done()
+		n.dropped.Add(1)
+		n.logger.Load().Debug("Dropped notification, notification buffer is full")
+	}
+}
+
+// Dropped returns the number of notifications that were dropped because the notifier or a subscriber was too slow
+func (n *Notifier[T]) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// flush waits until all notifications sent before were distributed to subscribers
+func (n *Notifier[T]) flush() {
+	flushed := make(chan struct{})
+	select {
+	case n.notifyCh <- notification[T]{flushed: flushed}:
+	case <-n.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-n.done:
 	}
 }
 
-// Close closes the notifier and all subscriber channels
+// Close closes the notifier and all subscriber channels, pending notifications are delivered before.
+// It is safe to call Close multiple times.
 func (n *Notifier[T]) Close() {
 	n.closeOnce.Do(func() {
-		// Wait for any pending notifications to be distributed
-		n.pending.Wait()
+		n.flush()
 
 		n.mu.Lock()
 		n.closed = true
@@ -133,29 +171,47 @@ func (n *Notifier[T]) Close() {
 		}
 		n.subscribers = nil
 
-		// Close the notification channel
-		close(n.notifyCh)
-
 		n.mu.Unlock()
+
+		// Stop processing notifications
+		close(n.done)
 	})
 }
 
 // processNotifications handles distributing notifications to subscribers
 func (n *Notifier[T]) processNotifications() {
-	for item := range n.notifyCh {
+	for {
+		var msg notification[T]
+		select {
+		case msg = <-n.notifyCh:
+		case <-n.done:
+			return
+		}
+
+		if msg.flushed != nil {
+			close(msg.flushed)
+			continue
+		}
+
 		n.mu.RLock()
 
 		// Send to each subscriber (non-blocking)
 		for _, ch := range n.subscribers {
 			select {
-			case ch <- item:
+			case ch <- msg.item:
 				// Successfully sent
 			default:
 				// Subscriber channel is full, drop this notification for this subscriber
+				n.dropped.Add(1)
+				n.logger.Load().Debug("Dropped notification, subscriber buffer is full")
 			}
 		}
 
 		n.mu.RUnlock()
-		n.pending.Done()
 	}
 }
+
+// setLogger sets the logger for dropped notifications, a nil logger discards them
+func (n *Notifier[T]) setLogger(logger *slog.Logger) {
+	n.logger.Store(loggerOrDiscard(logger))
+}
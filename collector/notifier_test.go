@@ -2,6 +2,7 @@ package collector_test
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -488,3 +489,81 @@ func TestNotifier_SlowConsumer(t *testing.T) {
 			"Values should be received in order even with a slow consumer")
 	}
 }
+
+func TestNotifier_ConcurrentClose(t *testing.T) {
+	t.Parallel()
+
+	notifier := collector.NewNotifier[int]()
+
+	// Notify, subscribe and unsubscribe concurrently with Close, which must not panic or race
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithCancel(context.Background())
+			ch := notifier.Subscribe(ctx)
+			for j := range 100 {
+				notifier.Notify(i*100 + j)
+			}
+			cancel()
+			notifier.Unsubscribe(ch)
+			for range ch {
+			}
+		}()
+	}
+
+	notifier.Close()
+	wg.Wait()
+
+	// Closing again is a no-op
+	notifier.Close()
+}
+
+func TestNotifier_Dropped(t *testing.T) {
+	t.Parallel()
+
+	notifier := collector.NewNotifierWithOptions[int](collector.NotifierOptions{
+		SubscriberBufferSize:   1,
+		NotificationBufferSize: 10,
+	})
+	defer notifier.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := notifier.Subscribe(ctx)
+
+	// The subscriber does not receive, so all but the first notification are dropped for it
+	for i := range 3 {
+		notifier.Notify(i)
+	}
+	notifier.Unsubscribe(ch)
+
+	assert.Equal(t, uint64(2), notifier.Dropped())
+}
+
+func BenchmarkNotifier_FanOut(b *testing.B) {
+	for _, subscribers := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("%d subscribers", subscribers), func(b *testing.B) {
+			notifier := collector.NewNotifier[int]()
+			defer notifier.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			channels := make([]<-chan int, subscribers)
+			for i := range channels {
+				channels[i] = notifier.Subscribe(ctx)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			// Each notification is received by all subscribers before the next one is sent, so none is dropped
+			for i := 0; i < b.N; i++ {
+				notifier.Notify(i)
+				for _, ch := range channels {
+					<-ch
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"context"
+	"sync"
+)
+
+type requestInfoKeyType struct{}
+
+var requestInfoKey = requestInfoKeyType{}
+
+// requestInfo holds information that is set via Tag or SetRoutePattern while a request is handled
+type requestInfo struct {
+	mu           sync.Mutex
+	tags         map[string]string
+	routePattern string
+}
+
+// withRequestInfo returns a new context that collects information set via Tag or SetRoutePattern
+func withRequestInfo(ctx context.Context) (context.Context, *requestInfo) {
+	info := &requestInfo{tags: make(map[string]string)}
+	return context.WithValue(ctx, requestInfoKey, info), info
+}
+
+// applyTo copies the collected information to the request, overwriting existing tags
+func (i *requestInfo) applyTo(req *HTTPServerRequest) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key, value := range i.tags {
+		req.Tags[key] = value
+	}
+	if i.routePattern != "" {
+		req.RoutePattern = i.routePattern
+	}
+}
+
+// Tag sets a tag on the incoming HTTP request that is handled with ctx.
+// Tags are shown in the dashboard and can be used to filter events.
+// It does nothing if the request is not collected.
+func Tag(ctx context.Context, key, value string) {
+	info, ok := ctx.Value(requestInfoKey).(*requestInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.tags[key] = value
+}
+
+// SetRoutePattern sets the route pattern (e.g. "/todos/{id}") of the incoming HTTP request that is handled with ctx.
+// Router adapters use this to show route templates in the dashboard.
+// It does nothing if the request is not collected.
+func SetRoutePattern(ctx context.Context, pattern string) {
+	info, ok := ctx.Value(requestInfoKey).(*requestInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	info.routePattern = pattern
+}
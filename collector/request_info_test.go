@@ -0,0 +1,88 @@
+package collector_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestTag(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+	defer serverCollector.Close()
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collector.Tag(r.Context(), "tenant", "acme")
+		aggregator.CollectEvent(r.Context(), "child event")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, events[0].Tags())
+	assert.True(t, events[0].HasTag("tenant", "acme"))
+	assert.True(t, events[0].HasTag("tenant", ""))
+	assert.False(t, events[0].HasTag("tenant", "other"))
+	assert.False(t, events[0].Children[0].HasTag("tenant", ""))
+}
+
+func TestTag_WithoutRequest(t *testing.T) {
+	assert.NotPanics(t, func() {
+		collector.Tag(context.Background(), "tenant", "acme")
+	})
+}
+
+func TestSetRoutePattern(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	requests := Collect(t, serverCollector.Subscribe)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collector.SetRoutePattern(r.Context(), "/todos/{id}")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+
+	collected := requests.Wait(1)
+	assert.Equal(t, "/todos/42", collected[0].Path)
+	assert.Equal(t, "/todos/{id}", collected[0].RoutePattern)
+}
+
+func TestHTTPServerCollector_ServeMuxPattern(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	requests := Collect(t, serverCollector.Subscribe)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /todos/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/override/", func(w http.ResponseWriter, r *http.Request) {
+		collector.SetRoutePattern(r.Context(), "/override/{name}")
+	})
+	handler := serverCollector.Middleware(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/override/foo", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	collected := requests.Wait(3)
+	assert.Equal(t, "/todos/{id}", collected[0].RoutePattern)
+	assert.Equal(t, "/todos/{id}", collected[0].Route())
+	assert.Equal(t, "/override/{name}", collected[1].RoutePattern)
+	assert.Equal(t, "", collected[2].RoutePattern)
+	assert.Equal(t, "/unknown", collected[2].Route())
+}
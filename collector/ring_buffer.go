@@ -1,57 +1,124 @@
 package collector
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
+
+// ringIndex is an optional index of the records in a ringBuffer, it is updated while the buffer is locked
+type ringIndex[T any] interface {
+	insert(record T)
+	remove(record T)
+	reset()
+}
 
-// RingBuffer is a thread-safe ring buffer
-type RingBuffer[T any] struct {
+// ringBuffer is the thread-safe core of RingBuffer and LookupRingBuffer. Records are kept in the order they were added,
+// the oldest record is overwritten when the buffer is full.
+type ringBuffer[T any] struct {
 	buffer     []T
+	index      ringIndex[T]
 	size       uint64
 	capacity   uint64
 	writeIndex uint64
 	mu         sync.RWMutex
 }
 
-// NewRingBuffer creates a new ring buffer with the given capacity
-func NewRingBuffer[T any](capacity uint64) *RingBuffer[T] {
+// init allocates the buffer with the given capacity, index is nil for a buffer without an index
+func (rb *ringBuffer[T]) init(capacity uint64, index ringIndex[T]) {
 	if capacity == 0 {
 		panic("capacity must be greater than 0")
 	}
-
-	return &RingBuffer[T]{
-		buffer:     make([]T, capacity),
-		capacity:   capacity,
-		size:       0,
-		writeIndex: 0,
-	}
+	rb.buffer = make([]T, capacity)
+	rb.index = index
+	rb.capacity = capacity
 }
 
-// Add adds an entry to the buffer
-func (rb *RingBuffer[T]) Add(record T) {
-	if rb == nil {
-		return
-	}
+// at returns the position in the buffer of the i-th oldest record, the buffer must be locked
+func (rb *ringBuffer[T]) at(i uint64) uint64 {
+	return (rb.writeIndex - rb.size + i) % rb.capacity
+}
 
+// add adds a record and returns the oldest record if it was overwritten
+func (rb *ringBuffer[T]) add(record T) (evicted T, ok bool) {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
 
-	// Write at current position
-	rb.buffer[rb.writeIndex%rb.capacity] = record
-
-	// Increment write index
-	rb.writeIndex++
+	index := rb.writeIndex % rb.capacity
 
-	// Update size (up to capacity)
+	// Update size (up to capacity), a full buffer overwrites the oldest record
 	if rb.size < rb.capacity {
 		rb.size++
+	} else {
+		evicted, ok = rb.buffer[index], true
+		if rb.index != nil {
+			rb.index.remove(evicted)
+		}
 	}
+
+	rb.buffer[index] = record
+	rb.writeIndex++
+	if rb.index != nil {
+		rb.index.insert(record)
+	}
+
+	return evicted, ok
 }
 
-// GetRecords returns a slice of the most recent n records
-func (rb *RingBuffer[T]) GetRecords(n uint64) []T {
-	if rb == nil {
+// replace replaces the first record matching the predicate in place and reports whether it was found
+func (rb *ringBuffer[T]) replace(match func(record T) bool, record T) bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	for i := uint64(0); i < rb.size; i++ {
+		index := rb.at(i)
+		if !match(rb.buffer[index]) {
+			continue
+		}
+
+		if rb.index != nil {
+			rb.index.remove(rb.buffer[index])
+			rb.index.insert(record)
+		}
+		rb.buffer[index] = record
+		return true
+	}
+
+	return false
+}
+
+// removeWhere removes all records matching the predicate and returns them, the order of the remaining records is kept
+func (rb *ringBuffer[T]) removeWhere(match func(record T) bool) []T {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	var removed []T
+	kept := make([]T, 0, rb.size)
+	for i := uint64(0); i < rb.size; i++ {
+		record := rb.buffer[rb.at(i)]
+		if !match(record) {
+			kept = append(kept, record)
+			continue
+		}
+		removed = append(removed, record)
+		if rb.index != nil {
+			rb.index.remove(record)
+		}
+	}
+	if len(removed) == 0 {
 		return nil
 	}
 
+	// Compact the remaining records to the start of the buffer
+	clear(rb.buffer)
+	copy(rb.buffer, kept)
+	rb.size = uint64(len(kept))
+	rb.writeIndex = rb.size
+
+	return removed
+}
+
+// records returns a slice of the most recent n records, oldest first
+func (rb *ringBuffer[T]) records(n uint64) []T {
 	rb.mu.RLock()
 	defer rb.mu.RUnlock()
 
@@ -62,37 +129,110 @@ func (rb *RingBuffer[T]) GetRecords(n uint64) []T {
 	}
 
 	result := make([]T, count)
-
-	// Calculate the starting index
-	startIdx := rb.writeIndex - count
 	for i := uint64(0); i < count; i++ {
-		// Use modulo to wrap around the buffer
-		result[i] = rb.buffer[(startIdx+i)%rb.capacity]
+		result[i] = rb.buffer[rb.at(rb.size-count+i)]
 	}
 
 	return result
 }
 
+// all iterates over the records oldest first while the buffer is read locked
+func (rb *ringBuffer[T]) all() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		rb.mu.RLock()
+		defer rb.mu.RUnlock()
+
+		for i := uint64(0); i < rb.size; i++ {
+			if !yield(rb.buffer[rb.at(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// clearRecords removes all records
+func (rb *ringBuffer[T]) clearRecords() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	// Set all entries in the buffer to their zero value, so the records can be garbage collected
+	clear(rb.buffer)
+	if rb.index != nil {
+		rb.index.reset()
+	}
+	rb.size = 0
+	rb.writeIndex = 0
+}
+
 // Size returns the current number of records in the buffer
-func (rb *RingBuffer[T]) Size() uint64 {
+func (rb *ringBuffer[T]) Size() uint64 {
 	rb.mu.RLock()
 	defer rb.mu.RUnlock()
 	return rb.size
 }
 
 // Capacity returns the maximum capacity of the buffer
-func (rb *RingBuffer[T]) Capacity() uint64 {
+func (rb *ringBuffer[T]) Capacity() uint64 {
 	return rb.capacity
 }
 
-func (rb *RingBuffer[T]) Clear() {
-	rb.mu.Lock()
-	defer rb.mu.Unlock()
+// RingBuffer is a thread-safe ring buffer
+type RingBuffer[T any] struct {
+	ringBuffer[T]
+}
+
+// NewRingBuffer creates a new ring buffer with the given capacity
+func NewRingBuffer[T any](capacity uint64) *RingBuffer[T] {
+	rb := &RingBuffer[T]{}
+	rb.init(capacity, nil)
+	return rb
+}
 
-	// Set all entries in the buffer to their zero value
-	for i := range rb.buffer {
-		var item T
-		rb.buffer[i] = item
+// Add adds an entry to the buffer
+func (rb *RingBuffer[T]) Add(record T) {
+	if rb == nil {
+		return
 	}
-	rb.size = 0
+	rb.add(record)
+}
+
+// GetRecords returns a slice of the most recent n records
+func (rb *RingBuffer[T]) GetRecords(n uint64) []T {
+	if rb == nil {
+		return nil
+	}
+	return rb.records(n)
+}
+
+// Remove removes the oldest record matching the predicate and returns it
+func (rb *RingBuffer[T]) Remove(match func(record T) bool) (T, bool) {
+	var found bool
+	removed := rb.removeWhere(func(record T) bool {
+		if found || !match(record) {
+			return false
+		}
+		found = true
+		return true
+	})
+	if len(removed) == 0 {
+		var empty T
+		return empty, false
+	}
+	return removed[0], true
+}
+
+// RemoveWhere removes all records matching the predicate and returns them, the order of the remaining records is kept
+func (rb *RingBuffer[T]) RemoveWhere(match func(record T) bool) []T {
+	return rb.removeWhere(match)
+}
+
+// Iterate iterates over the records oldest first. The buffer is locked while iterating, so the loop body must not
+// call methods of the buffer.
+func (rb *RingBuffer[T]) Iterate() iter.Seq[T] {
+	return rb.all()
+}
+
+// Clear removes all records
+func (rb *RingBuffer[T]) Clear() {
+	rb.clearRecords()
 }
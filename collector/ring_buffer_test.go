@@ -234,3 +234,55 @@ func TestRingBuffer_Wraparound(t *testing.T) {
 	assert.Equal(t, "data7", records[1])
 	assert.Equal(t, "data8", records[2])
 }
+
+func TestRingBuffer_Clear(t *testing.T) {
+	rb := collector.NewRingBuffer[string](3)
+	for _, record := range []string{"data1", "data2", "data3", "data4"} {
+		rb.Add(record)
+	}
+
+	rb.Clear()
+	assert.Equal(t, uint64(0), rb.Size())
+	assert.Empty(t, rb.GetRecords(3))
+
+	rb.Add("data5")
+	assert.Equal(t, []string{"data5"}, rb.GetRecords(3))
+}
+
+func TestRingBuffer_Remove(t *testing.T) {
+	rb := collector.NewRingBuffer[int](4)
+	for i := 1; i <= 6; i++ {
+		rb.Add(i)
+	}
+
+	removed, ok := rb.Remove(func(record int) bool { return record%2 == 0 })
+	assert.True(t, ok)
+	assert.Equal(t, 4, removed, "the oldest matching record is removed")
+	assert.Equal(t, []int{3, 5, 6}, rb.GetRecords(4))
+
+	assert.Equal(t, []int{3, 5}, rb.RemoveWhere(func(record int) bool { return record%2 == 1 }))
+	assert.Equal(t, []int{6}, rb.GetRecords(4))
+
+	_, ok = rb.Remove(func(record int) bool { return record == 1 })
+	assert.False(t, ok)
+}
+
+func TestRingBuffer_Iterate(t *testing.T) {
+	rb := collector.NewRingBuffer[string](3)
+	for _, record := range []string{"data1", "data2", "data3", "data4"} {
+		rb.Add(record)
+	}
+
+	var records []string
+	for record := range rb.Iterate() {
+		records = append(records, record)
+		if record == "data3" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"data2", "data3"}, records)
+
+	// The buffer is unlocked after breaking out of the loop
+	rb.Add("data5")
+	assert.Equal(t, uint64(3), rb.Size())
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"context"
+	"time"
+	"unsafe"
+)
+
+// RPCCall represents a captured RPC (e.g. connect-go or gRPC) call
+type RPCCall struct {
+	// Procedure is the full name of the called procedure, e.g. "/todo.v1.TodoService/GetTodo"
+	Procedure string
+	// Protocol of the call, e.g. "connect", "grpc" or "grpcweb"
+	Protocol string
+	// Client is true if the call was made by a client, false if it was handled by a server
+	Client bool
+	// Stream is true for streaming calls, messages are not collected for them
+	Stream bool
+	// Peer is the address of the other side of the call
+	Peer string
+	// Timestamp when the call was started
+	Timestamp time.Time
+	// Duration of the call
+	Duration time.Duration
+	// Request is the request message rendered as JSON
+	Request string
+	// Response is the response message rendered as JSON
+	Response string
+	// Code is the error code of a failed call (e.g. "not_found"), empty on success
+	Code string
+	// Error if the call failed
+	Error error
+}
+
+// Size returns the estimated memory size of this call in bytes
+func (c RPCCall) Size() uint64 {
+	size := uint64(unsafe.Sizeof(c))
+	size += uint64(len(c.Procedure) + len(c.Protocol) + len(c.Peer) + len(c.Code))
+	size += uint64(len(c.Request) + len(c.Response))
+	size += errorSize(c.Error)
+	return size
+}
+
+type RPCCallCollector struct {
+	notifier        *Notifier[RPCCall]
+	eventAggregator *EventAggregator
+}
+
+// Collect collects a finished RPC call
+func (c *RPCCallCollector) Collect(ctx context.Context, call RPCCall) {
+	if !c.eventAggregator.Enabled() {
+		return
+	}
+	c.notifier.Notify(call)
+	if c.eventAggregator != nil {
+		c.eventAggregator.CollectEvent(ctx, call)
+	}
+}
+
+// Subscribe returns a channel that receives notifications of new RPC calls
+func (c *RPCCallCollector) Subscribe(ctx context.Context) <-chan RPCCall {
+	return c.notifier.Subscribe(ctx)
+}
+
+type RPCCallOptions struct {
+	// NotifierOptions are options for notification about new calls
+	NotifierOptions *NotifierOptions
+
+	// EventAggregator is the aggregator for collecting calls as grouped events
+	EventAggregator *EventAggregator
+}
+
+func DefaultRPCCallOptions() RPCCallOptions {
+	return RPCCallOptions{}
+}
+
+func NewRPCCallCollector() *RPCCallCollector {
+	return NewRPCCallCollectorWithOptions(DefaultRPCCallOptions())
+}
+
+func NewRPCCallCollectorWithOptions(options RPCCallOptions) *RPCCallCollector {
+	notifierOptions := DefaultNotifierOptions()
+	if options.NotifierOptions != nil {
+		notifierOptions = *options.NotifierOptions
+	}
+
+	return &RPCCallCollector{
+		notifier:        NewNotifierWithOptions[RPCCall](notifierOptions),
+		eventAggregator: options.EventAggregator,
+	}
+}
+
+// Close releases resources used by the collector
+func (c *RPCCallCollector) Close() {
+	c.notifier.Close()
+}
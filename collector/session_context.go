@@ -24,3 +24,43 @@ func SessionIDsFromContext(ctx context.Context) ([]uuid.UUID, bool) {
 	}
 	return nil, false
 }
+
+type serverRequestKeyType struct{}
+
+var serverRequestKey = serverRequestKeyType{}
+
+// withServerRequest marks the context as belonging to an incoming HTTP request
+func withServerRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serverRequestKey, true)
+}
+
+// IsAmbientContext returns true if the context is neither bound to a capture session nor to an incoming HTTP request,
+// e.g. for logs or client calls from background goroutines.
+func IsAmbientContext(ctx context.Context) bool {
+	if _, ok := SessionIDsFromContext(ctx); ok {
+		return false
+	}
+	_, inRequest := ctx.Value(serverRequestKey).(bool)
+	return !inRequest
+}
+
+// DetachedContext returns a new context that only carries the devlog values of ctx (capture sessions, the current
+// event group, the request marker and a forced capture). It is not canceled together with ctx, so it can be passed to goroutines that
+// outlive a request. Events collected with it are nested under the originating event, even after it was completed (or
+// collected as detached top-level events if it is not stored anymore).
+func DetachedContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	if sessionIDs, ok := SessionIDsFromContext(ctx); ok {
+		detached = WithSessionIDs(detached, sessionIDs)
+	}
+	if groupID, ok := groupIDFromContext(ctx); ok {
+		detached = withGroupID(detached, groupID)
+	}
+	if _, inRequest := ctx.Value(serverRequestKey).(bool); inRequest {
+		detached = withServerRequest(detached)
+	}
+	if isForcedCapture(ctx) {
+		detached = withForcedCapture(detached)
+	}
+	return detached
+}
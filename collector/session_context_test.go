@@ -53,3 +53,21 @@ func TestSessionIDsFromContext_Set(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, []uuid.UUID{sessionID}, retrievedIDs)
 }
+
+func TestDetachedContext(t *testing.T) {
+	type otherKey struct{}
+
+	sessionID := uuid.Must(uuid.NewV4())
+	ctx := collector.WithSessionIDs(context.Background(), []uuid.UUID{sessionID})
+	ctx = context.WithValue(ctx, otherKey{}, "value")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	detached := collector.DetachedContext(ctx)
+
+	retrievedIDs, ok := collector.SessionIDsFromContext(detached)
+	assert.True(t, ok)
+	assert.Equal(t, []uuid.UUID{sessionID}, retrievedIDs)
+	assert.NoError(t, detached.Err())
+	assert.Nil(t, detached.Value(otherKey{}))
+}
@@ -0,0 +1,256 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Sink receives captured events to forward them to an external tool, e.g. a file, an HTTP endpoint or an OTLP collector.
+// Register it with a SinkStorage to feed it in addition to the dashboard.
+type Sink interface {
+	// Export sends a batch of completed top-level events (with their nested events), oldest first. It is called from a
+	// single goroutine and must not keep the slice after returning. ctx is done after SinkOptions.ExportTimeout.
+	Export(ctx context.Context, events []*Event) error
+	// Close releases the resources of the sink, it is called once after the last export
+	Close() error
+}
+
+// SinkOptions configures how events are forwarded to a sink
+type SinkOptions struct {
+	// BatchSize is the maximum number of events exported at once.
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is how long events are collected into a batch before it is exported.
+	// Default: 1s
+	FlushInterval time.Duration
+
+	// QueueSize is the number of events waiting to be exported, events are dropped if the queue is full.
+	// Default: 1000
+	QueueSize int
+
+	// ExportTimeout limits the duration of a single export.
+	// Default: 10s
+	ExportTimeout time.Duration
+}
+
+// DefaultSinkOptions returns default options for forwarding events to a sink
+func DefaultSinkOptions() SinkOptions {
+	return SinkOptions{
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		QueueSize:     1000,
+		ExportTimeout: 10 * time.Second,
+	}
+}
+
+// SinkStorage is an EventStorage that forwards all completed events to a sink in batches on its own goroutine, so a
+// slow sink does not block collecting. Events are not kept, previews of events in progress are skipped.
+// Register it with EventAggregator.RegisterStorage, it captures all events like a storage in CaptureModeGlobal.
+type SinkStorage struct {
+	id      uuid.UUID
+	sink    Sink
+	options SinkOptions
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *Event
+	closing chan struct{}
+	done    chan struct{}
+
+	exported atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	logger   atomic.Pointer[slog.Logger]
+}
+
+var _ EventStorage = (*SinkStorage)(nil)
+var _ loggingStorage = (*SinkStorage)(nil)
+
+// NewSinkStorage creates a storage forwarding events to sink, zero options use the defaults of DefaultSinkOptions
+func NewSinkStorage(sink Sink, options SinkOptions) *SinkStorage {
+	defaults := DefaultSinkOptions()
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaults.BatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaults.FlushInterval
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaults.QueueSize
+	}
+	if options.ExportTimeout <= 0 {
+		options.ExportTimeout = defaults.ExportTimeout
+	}
+
+	s := &SinkStorage{
+		id:      uuid.Must(uuid.NewV4()),
+		sink:    sink,
+		options: options,
+		queue:   make(chan *Event, options.QueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.setLogger(nil)
+
+	go s.run()
+
+	return s
+}
+
+// ID implements EventStorage
+func (s *SinkStorage) ID() uuid.UUID {
+	return s.id
+}
+
+// ShouldCapture implements EventStorage, all events are forwarded until the storage is closed
+func (s *SinkStorage) ShouldCapture(ctx context.Context) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.closed
+}
+
+// Add queues a completed event for export, it is dropped if the queue is full
+func (s *SinkStorage) Add(event *Event) {
+	if event.InProgress {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		s.dropped.Add(1)
+		s.logger.Load().Debug("Dropped event for sink, queue is full", "event", event.ID)
+	}
+}
+
+// GetEvent implements EventStorage, events are not kept
+func (s *SinkStorage) GetEvent(id uuid.UUID) (*Event, bool) {
+	return nil, false
+}
+
+// GetEvents implements EventStorage, events are not kept
+func (s *SinkStorage) GetEvents(limit uint64) []*Event {
+	return nil
+}
+
+// Subscribe implements EventStorage, the returned channel is closed since events are only forwarded to the sink
+func (s *SinkStorage) Subscribe(ctx context.Context) <-chan *Event {
+	ch := make(chan *Event)
+	close(ch)
+	return ch
+}
+
+// Clear implements EventStorage, events that were not exported yet are still exported
+func (s *SinkStorage) Clear() {}
+
+// Close exports the queued events and closes the sink, it is safe to call Close multiple times
+func (s *SinkStorage) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.closing)
+	s.mu.Unlock()
+
+	<-s.done
+	if err := s.sink.Close(); err != nil {
+		s.logger.Load().Warn("Failed to close sink", "error", err)
+	}
+}
+
+// Exported returns the number of events that were exported successfully
+func (s *SinkStorage) Exported() uint64 {
+	return s.exported.Load()
+}
+
+// Dropped returns the number of events that were dropped because the queue was full
+func (s *SinkStorage) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns the number of events that were not exported because the sink returned an error
+func (s *SinkStorage) Failed() uint64 {
+	return s.failed.Load()
+}
+
+func (s *SinkStorage) setLogger(logger *slog.Logger) {
+	s.logger.Store(loggerOrDiscard(logger).With("sink", s.id))
+}
+
+// run collects queued events into batches and exports them until the storage is closed
+func (s *SinkStorage) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []*Event
+	flush := func() {
+		if len(batch) > 0 {
+			s.export(batch)
+			batch = nil
+		}
+	}
+
+	for {
+		select {
+		case event := <-s.queue:
+			batch = append(batch, event)
+			if len(batch) >= s.options.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.closing:
+			// No events are queued after closing, export the remaining ones
+			for {
+				select {
+				case event := <-s.queue:
+					batch = append(batch, event)
+					if len(batch) >= s.options.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// export sends a batch to the sink, a panic of the sink is recovered and counted as failed export
+func (s *SinkStorage) export(batch []*Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.ExportTimeout)
+	defer cancel()
+
+	err := func() (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("sink panicked: %v", v)
+			}
+		}()
+		return s.sink.Export(ctx, batch)
+	}()
+	if err != nil {
+		s.failed.Add(uint64(len(batch)))
+		s.logger.Load().Warn("Failed to export events to sink", "events", len(batch), "error", err)
+		return
+	}
+	s.exported.Add(uint64(len(batch)))
+}
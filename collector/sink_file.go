@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// FileSink appends events as newline delimited JSON (see JSONEventCodec) to a file, e.g. to analyze them with jq or to
+// import them later
+type FileSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	encoder EventEncoder
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink opens the file at path for appending events, it is created if it does not exist
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	return &FileSink{
+		file:    file,
+		writer:  writer,
+		encoder: JSONEventCodec.NewEncoder(writer),
+	}, nil
+}
+
+// Export implements Sink, the events are written to the file before it returns
+func (s *FileSink) Export(ctx context.Context, events []*Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		if err := s.encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return s.writer.Flush()
+}
+
+// Close implements Sink
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return errors.Join(s.writer.Flush(), s.file.Close())
+}
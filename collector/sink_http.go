@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSink posts batches of events as newline delimited JSON (see JSONEventCodec) to an HTTP endpoint.
+// The requests are marked with InternalRequestHeader, so they are not captured if the endpoint is the application itself.
+type HTTPSink struct {
+	// URL receives a POST request for each batch
+	URL string
+	// Header is added to each request, e.g. for authorization
+	Header http.Header
+	// Client sends the requests.
+	// Default: nil, will use a client with a timeout of 10s
+	Client *http.Client
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// sinkClient is used by sinks without a client. Requests are marked with InternalRequestHeader, so they are not
+// captured even if http.DefaultTransport is wrapped by the HTTP client collector.
+var sinkClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewHTTPSink returns a sink posting events to url
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url}
+}
+
+// Export implements Sink, it fails if the endpoint does not respond with a 2xx status code
+func (s *HTTPSink) Export(ctx context.Context, events []*Event) error {
+	var body bytes.Buffer
+	encoder := JSONEventCodec.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return postSinkRequest(ctx, s.Client, s.URL, s.Header, "application/x-ndjson", body.Bytes())
+}
+
+// Close implements Sink
+func (s *HTTPSink) Close() error {
+	return nil
+}
+
+// postSinkRequest posts body to url and returns an error for a status code other than 2xx
+func postSinkRequest(ctx context.Context, client *http.Client, url string, header http.Header, contentType string, body []byte) error {
+	if client == nil {
+		client = sinkClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(InternalRequestHeader, "sink")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package collector
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLPSink pushes events to an OpenTelemetry collector with OTLP over HTTP in the JSON encoding, e.g. to view them in
+// Jaeger or Grafana. Each top-level event is a trace: requests, queries, RPC calls and other events are exported as spans
+// with their nested events as child spans, logs are exported as log records of the span they were collected in.
+type OTLPSink struct {
+	// Endpoint is the base URL of the collector, e.g. "http://localhost:4318".
+	// Spans are posted to Endpoint + "/v1/traces", logs to Endpoint + "/v1/logs".
+	Endpoint string
+	// ServiceName is exported as "service.name" resource attribute.
+	// Default: "devlog"
+	ServiceName string
+	// Header is added to each request, e.g. for authorization
+	Header http.Header
+	// Client sends the requests.
+	// Default: nil, will use a client with a timeout of 10s
+	Client *http.Client
+}
+
+var _ Sink = (*OTLPSink)(nil)
+
+// NewOTLPSink returns a sink pushing events to the OTLP/HTTP endpoint of a collector, e.g. "http://localhost:4318"
+func NewOTLPSink(endpoint string) *OTLPSink {
+	return &OTLPSink{Endpoint: endpoint}
+}
+
+// Export implements Sink
+func (s *OTLPSink) Export(ctx context.Context, events []*Event) error {
+	var batch otlpBatch
+	for _, event := range events {
+		batch.add(event, hex.EncodeToString(event.ID.Bytes()), "")
+	}
+
+	serviceName := s.ServiceName
+	if serviceName == "" {
+		serviceName = "devlog"
+	}
+	resource := otlpResource{Attributes: []otlpKeyValue{otlpString("service.name", serviceName)}}
+	scope := otlpScope{Name: "github.com/networkteam/devlog"}
+	endpoint := strings.TrimSuffix(s.Endpoint, "/")
+
+	var errs []error
+	if len(batch.spans) > 0 {
+		errs = append(errs, s.post(ctx, endpoint+"/v1/traces", otlpTraces{
+			ResourceSpans: []otlpResourceSpans{{
+				Resource:   resource,
+				ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: batch.spans}},
+			}},
+		}))
+	}
+	if len(batch.logs) > 0 {
+		errs = append(errs, s.post(ctx, endpoint+"/v1/logs", otlpLogs{
+			ResourceLogs: []otlpResourceLogs{{
+				Resource:  resource,
+				ScopeLogs: []otlpScopeLogs{{Scope: scope, LogRecords: batch.logs}},
+			}},
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// Close implements Sink
+func (s *OTLPSink) Close() error {
+	return nil
+}
+
+func (s *OTLPSink) post(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return postSinkRequest(ctx, s.Client, url, s.Header, "application/json", body)
+}
+
+// otlpBatch collects the spans and log records of exported events
+type otlpBatch struct {
+	spans []otlpSpan
+	logs  []otlpLogRecord
+}
+
+// add converts an event and its nested events to spans and log records of the trace. parentSpanID is empty for
+// top-level events.
+func (b *otlpBatch) add(event *Event, traceID string, parentSpanID string) {
+	if record, ok := event.Data.(slog.Record); ok {
+		logRecord := otlpLogRecord{
+			TimeUnixNano:   otlpTime(record.Time),
+			SeverityNumber: otlpSeverity(record.Level),
+			SeverityText:   record.Level.String(),
+			Body:           otlpValue(slog.StringValue(record.Message)),
+			Attributes:     otlpTenant(nil, event.Tenant),
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			logRecord.Attributes = otlpAttrs(logRecord.Attributes, "", attr)
+			return true
+		})
+		// Logs collected outside of a span don't belong to a trace
+		if parentSpanID != "" {
+			logRecord.TraceID = traceID
+			logRecord.SpanID = parentSpanID
+		}
+		b.logs = append(b.logs, logRecord)
+		return
+	}
+
+	id := event.ID.Bytes()
+	span := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            hex.EncodeToString(id[8:]), // the random bits of the UUIDv7
+		ParentSpanID:      parentSpanID,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: otlpTime(event.Start),
+		EndTimeUnixNano:   otlpTime(event.End),
+	}
+	span.describe(event.Data)
+	span.Attributes = otlpTenant(span.Attributes, event.Tenant)
+	tags := event.Tags()
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		span.Attributes = append(span.Attributes, otlpString("devlog.tag."+key, tags[key]))
+	}
+	b.spans = append(b.spans, span)
+
+	for _, child := range event.Children {
+		b.add(child, traceID, span.SpanID)
+	}
+}
+
+// describe sets the name, kind, attributes and status of the span from the event data, mostly following the semantic
+// conventions of OpenTelemetry
+func (s *otlpSpan) describe(data any) {
+	switch data := data.(type) {
+	case HTTPServerRequest:
+		s.Name = data.Method + " " + data.Route()
+		s.Kind = otlpSpanKindServer
+		s.Attributes = append(s.Attributes,
+			otlpString("http.request.method", data.Method),
+			otlpString("url.path", data.Path),
+			otlpString("client.address", data.RemoteAddr),
+			otlpInt("http.response.status_code", int64(data.StatusCode)),
+		)
+		if data.RoutePattern != "" {
+			s.Attributes = append(s.Attributes, otlpString("http.route", data.RoutePattern))
+		}
+		switch {
+		case data.Panic != "":
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Panic}
+		case data.Error != nil:
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		case data.StatusCode >= 500:
+			s.Status = otlpStatus{Code: otlpStatusError}
+		}
+	case HTTPClientRequest:
+		s.Name = data.Method
+		s.Kind = otlpSpanKindClient
+		s.Attributes = append(s.Attributes,
+			otlpString("http.request.method", data.Method),
+			otlpString("url.full", data.URL),
+		)
+		if data.StatusCode != 0 {
+			s.Attributes = append(s.Attributes, otlpInt("http.response.status_code", int64(data.StatusCode)))
+		}
+		switch {
+		case data.Error != nil:
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		case data.StatusCode >= 400:
+			s.Status = otlpStatus{Code: otlpStatusError}
+		}
+	case DBQuery:
+		s.Name = "query"
+		if operation, _, _ := strings.Cut(strings.TrimSpace(data.Query), " "); operation != "" {
+			s.Name = strings.ToUpper(operation)
+		}
+		s.Kind = otlpSpanKindClient
+		s.Attributes = append(s.Attributes, otlpString("db.query.text", data.Query))
+		if data.Language != "" {
+			s.Attributes = append(s.Attributes, otlpString("db.system", data.Language))
+		}
+		if data.Error != nil {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		}
+	case RPCCall:
+		s.Name = strings.TrimPrefix(data.Procedure, "/")
+		s.Kind = otlpSpanKindServer
+		if data.Client {
+			s.Kind = otlpSpanKindClient
+		}
+		s.Attributes = append(s.Attributes, otlpString("rpc.system", data.Protocol))
+		if data.Code != "" {
+			s.Attributes = append(s.Attributes, otlpString("rpc.response.status_code", data.Code))
+		}
+		if data.Error != nil {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		}
+	case CacheOp:
+		s.Name = strings.ToUpper(string(data.Operation))
+		s.Kind = otlpSpanKindClient
+		s.Attributes = append(s.Attributes, otlpString("db.operation.name", s.Name), otlpString("devlog.cache.key", data.Key))
+		if data.Cache != "" {
+			s.Attributes = append(s.Attributes, otlpString("db.system", data.Cache))
+		}
+		if data.Operation == CacheOperationGet && data.Error == nil {
+			s.Attributes = append(s.Attributes, otlpBool("devlog.cache.hit", data.Hit))
+		}
+		if data.Error != nil {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		}
+	case Mail:
+		s.Name = "send mail"
+		s.Kind = otlpSpanKindClient
+		s.Attributes = append(s.Attributes, otlpString("devlog.mail.subject", data.Subject), otlpInt("devlog.mail.recipients", int64(len(data.Recipients()))))
+		if data.Server != "" {
+			s.Attributes = append(s.Attributes, otlpString("server.address", data.Server))
+		}
+		if data.Error != nil {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		}
+	case FlagEvaluation:
+		s.Name = "feature_flag.evaluation"
+		s.Attributes = append(s.Attributes, otlpString("feature_flag.key", data.Flag), otlpString("feature_flag.result.value", data.Value))
+		if data.Variant != "" {
+			s.Attributes = append(s.Attributes, otlpString("feature_flag.result.variant", data.Variant))
+		}
+		if data.Reason != "" {
+			s.Attributes = append(s.Attributes, otlpString("feature_flag.result.reason", data.Reason))
+		}
+		if data.Provider != "" {
+			s.Attributes = append(s.Attributes, otlpString("feature_flag.provider.name", data.Provider))
+		}
+		if data.Error != nil {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: data.Error.Error()}
+		}
+	default:
+		payload := PayloadOf(data)
+		s.Name = payload.Summary()
+		s.Attributes = append(s.Attributes, otlpString("devlog.event.kind", string(payload.Kind())))
+		if _, ok := data.(InternalError); ok {
+			s.Status = otlpStatus{Code: otlpStatusError}
+		}
+	}
+}
+
+// Span kinds and status codes of OTLP
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+
+	otlpStatusError = 2
+)
+
+// otlpTime formats t as nanoseconds since the epoch, 64 bit integers are strings in the JSON encoding of OTLP
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// otlpSeverity maps a log level to the OTLP severity number, slog.LevelInfo is INFO (9) and each level step is one
+// severity step (e.g. slog.LevelWarn is WARN (13))
+func otlpSeverity(level slog.Level) int {
+	return min(max(int(level)+9, 1), 24)
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpValue(slog.StringValue(value))}
+}
+
+func otlpInt(key string, value int64) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpValue(slog.Int64Value(value))}
+}
+
+func otlpBool(key string, value bool) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpValue(slog.BoolValue(value))}
+}
+
+// otlpTenant adds the tenant of an event as attribute, if it has one
+func otlpTenant(attrs []otlpKeyValue, tenant string) []otlpKeyValue {
+	if tenant == "" {
+		return attrs
+	}
+	return append(attrs, otlpString("devlog.tenant", tenant))
+}
+
+// otlpAttrs adds a log attribute to attrs, attributes of groups are flattened with dotted keys
+func otlpAttrs(attrs []otlpKeyValue, prefix string, attr slog.Attr) []otlpKeyValue {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			attrs = otlpAttrs(attrs, prefix, groupAttr)
+		}
+		return attrs
+	}
+	return append(attrs, otlpKeyValue{Key: prefix + attr.Key, Value: otlpValue(value)})
+}
+
+// otlpValue converts a value to an AnyValue of OTLP
+func otlpValue(value slog.Value) map[string]any {
+	switch value.Kind() {
+	case slog.KindBool:
+		return map[string]any{"boolValue": value.Bool()}
+	case slog.KindInt64:
+		return map[string]any{"intValue": strconv.FormatInt(value.Int64(), 10)}
+	case slog.KindUint64:
+		return map[string]any{"intValue": strconv.FormatUint(value.Uint64(), 10)}
+	case slog.KindFloat64:
+		return map[string]any{"doubleValue": value.Float64()}
+	default:
+		return map[string]any{"stringValue": value.String()}
+	}
+}
+
+// Types of the JSON encoding of OTLP, see https://github.com/open-telemetry/opentelemetry-proto
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpLogs struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           map[string]any `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID        string         `json:"traceId,omitempty"`
+	SpanID         string         `json:"spanId,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
@@ -0,0 +1,202 @@
+package collector_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// recordingSink records the batches it exports
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]*collector.Event
+	err     error
+	closed  bool
+}
+
+func (s *recordingSink) Export(ctx context.Context, events []*collector.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, events)
+	return s.err
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestSinkStorage_ExportsBatches(t *testing.T) {
+	sink := &recordingSink{}
+	storage := collector.NewSinkStorage(sink, collector.SinkOptions{BatchSize: 2, FlushInterval: time.Hour})
+
+	aggregator := collector.NewEventAggregator()
+	aggregator.RegisterStorage(storage)
+
+	ctx := context.Background()
+	for _, data := range []string{"first", "second", "third"} {
+		aggregator.CollectEvent(ctx, data)
+	}
+	// Previews of events in progress are not exported
+	storage.Add(&collector.Event{Data: "preview", InProgress: true})
+
+	// Closing exports the incomplete batch and closes the sink
+	aggregator.Close()
+
+	require.Len(t, sink.batches, 2)
+	assert.Len(t, sink.batches[0], 2)
+	require.Len(t, sink.batches[1], 1)
+	assert.Equal(t, "third", sink.batches[1][0].Data)
+	assert.True(t, sink.closed)
+	assert.Equal(t, uint64(3), storage.Exported())
+
+	// Events are not captured after closing
+	assert.False(t, storage.ShouldCapture(ctx))
+}
+
+func TestSinkStorage_FlushInterval(t *testing.T) {
+	sink := &recordingSink{}
+	storage := collector.NewSinkStorage(sink, collector.SinkOptions{FlushInterval: 10 * time.Millisecond})
+	defer storage.Close()
+
+	storage.Add(&collector.Event{Data: "event"})
+
+	assert.Eventually(t, func() bool {
+		return storage.Exported() == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSinkStorage_FailedExport(t *testing.T) {
+	sink := &recordingSink{err: errors.New("unavailable")}
+	storage := collector.NewSinkStorage(sink, collector.DefaultSinkOptions())
+
+	storage.Add(&collector.Event{Data: "event"})
+	storage.Close()
+
+	assert.Equal(t, uint64(1), storage.Failed())
+	assert.Zero(t, storage.Exported())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	for _, data := range []string{"first", "second"} {
+		sink, err := collector.NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Export(context.Background(), []*collector.Event{newSinkEvent(data)}))
+		require.NoError(t, sink.Close())
+	}
+
+	// Events are appended
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	decoder := collector.JSONEventCodec.NewDecoder(file)
+	for _, data := range []string{"first", "second"} {
+		event, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, data, event.Data)
+	}
+	_, err = decoder.Decode()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received []*collector.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NotEmpty(t, r.Header.Get(collector.InternalRequestHeader))
+
+		decoder := collector.JSONEventCodec.NewDecoder(r.Body)
+		for {
+			event, err := decoder.Decode()
+			if err != nil {
+				break
+			}
+			received = append(received, event)
+		}
+	}))
+	defer server.Close()
+
+	sink := collector.NewHTTPSink(server.URL + "/events")
+	sink.Header = http.Header{"Authorization": {"Bearer token"}}
+	require.NoError(t, sink.Export(context.Background(), []*collector.Event{newSinkEvent("first"), newSinkEvent("second")}))
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "second", received[1].Data)
+
+	sink.URL = server.URL + "/missing"
+	assert.Error(t, sink.Export(context.Background(), []*collector.Event{newSinkEvent("third")}))
+}
+
+func TestOTLPSink(t *testing.T) {
+	requests := make(map[string]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+	sink := &recordingSink{}
+	storage := collector.NewSinkStorage(sink, collector.DefaultSinkOptions())
+	aggregator.RegisterStorage(storage)
+
+	ctx := aggregator.StartEvent(context.Background())
+	aggregator.CollectEvent(ctx, collector.DBQuery{Query: "select 1", Language: "postgresql"})
+	aggregator.CollectEvent(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "slow query", 0))
+	aggregator.EndEvent(ctx, collector.HTTPServerRequest{Method: "GET", Path: "/todos/1", RoutePattern: "/todos/{id}", StatusCode: 500})
+	storage.Close()
+	require.Len(t, sink.batches, 1)
+
+	otlpSink := collector.NewOTLPSink(server.URL + "/")
+	require.NoError(t, otlpSink.Export(context.Background(), sink.batches[0]))
+
+	spans := requests["/v1/traces"]["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	require.Len(t, spans, 2)
+	request, query := spans[0].(map[string]any), spans[1].(map[string]any)
+	assert.Equal(t, "GET /todos/{id}", request["name"])
+	assert.Equal(t, float64(2), request["kind"])
+	assert.Equal(t, map[string]any{"code": float64(2)}, request["status"])
+	assert.Nil(t, request["parentSpanId"])
+	assert.Equal(t, "SELECT", query["name"])
+	assert.Equal(t, request["traceId"], query["traceId"])
+	assert.Equal(t, request["spanId"], query["parentSpanId"])
+
+	logs := requests["/v1/logs"]["resourceLogs"].([]any)[0].(map[string]any)["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any)
+	require.Len(t, logs, 1)
+	log := logs[0].(map[string]any)
+	assert.Equal(t, float64(13), log["severityNumber"])
+	assert.Equal(t, map[string]any{"stringValue": "slow query"}, log["body"])
+	assert.Equal(t, request["spanId"], log["spanId"])
+}
+
+func newSinkEvent(data string) *collector.Event {
+	now := time.Now()
+	return &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: data, Start: now, End: now}
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"log/slog"
+	"net/http"
+	"unsafe"
+
+	"github.com/networkteam/devlog/internal/utils"
+)
+
+// The size functions estimate the memory referenced by event data (strings, slices, maps and bodies), the size of the
+// data itself is added with unsafe.Sizeof. They follow the memory layout of the Go runtime on 64-bit platforms and
+// ignore the rounding of allocations to size classes.
+
+const (
+	stringHeaderSize = uint64(unsafe.Sizeof(""))
+	sliceHeaderSize  = uint64(unsafe.Sizeof([]string(nil)))
+)
+
+// headersSize estimates the memory of header names, values and the map
+func headersSize(h http.Header) uint64 {
+	size := uint64(utils.MapSize(len(h), int(stringHeaderSize+sliceHeaderSize)))
+	for k, vs := range h {
+		size += uint64(len(k))
+		size += uint64(cap(vs)) * stringHeaderSize
+		for _, v := range vs {
+			size += uint64(len(v))
+		}
+	}
+	return size
+}
+
+// tagsSize estimates the memory of tags
+func tagsSize(tags map[string]string) uint64 {
+	size := uint64(utils.MapSize(len(tags), int(2*stringHeaderSize)))
+	for k, v := range tags {
+		size += uint64(len(k) + len(v))
+	}
+	return size
+}
+
+// errorSize estimates the memory of an error, which is usually a pointer to a struct with its message
+func errorSize(err error) uint64 {
+	if err == nil {
+		return 0
+	}
+	return stringHeaderSize + uint64(len(err.Error()))
+}
+
+// memorySize returns the memory of a captured body including its buffer, which can be larger than the captured
+// content (see Size) since the buffer grows by doubling
+func (b *Body) memorySize() uint64 {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	size := uint64(unsafe.Sizeof(*b))
+	if b.buffer != nil {
+		size += uint64(unsafe.Sizeof(*b.buffer)) + uint64(unsafe.Sizeof(*b.buffer.Buffer))
+		size += uint64(b.buffer.Cap())
+	}
+	return size
+}
+
+// recordSize estimates the memory of a log record. The first attributes are stored in the record itself, further
+// attributes in a slice.
+func recordSize(r slog.Record) uint64 {
+	const inlineAttrs = 5
+	size := uint64(unsafe.Sizeof(r)) + uint64(len(r.Message))
+	if r.NumAttrs() > inlineAttrs {
+		size += uint64(r.NumAttrs()-inlineAttrs) * uint64(unsafe.Sizeof(slog.Attr{}))
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		size += attrSize(attr)
+		return true
+	})
+	return size
+}
+
+// attrSize estimates the memory referenced by a log attribute (excluding the attribute itself)
+func attrSize(attr slog.Attr) uint64 {
+	size := uint64(len(attr.Key))
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		size += uint64(len(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		size += uint64(len(group)) * uint64(unsafe.Sizeof(slog.Attr{}))
+		for _, groupAttr := range group {
+			size += attrSize(groupAttr)
+		}
+	case slog.KindAny, slog.KindLogValuer:
+		if valueSize := utils.SizeOf(attr.Value.Any()); valueSize > 0 {
+			size += uint64(valueSize)
+		}
+	}
+	return size
+}
@@ -0,0 +1,160 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxSizeOverhead is the allowed overhead of the reported size over the content of a value, it covers the headers of
+// strings, slices and maps, struct fields and unused capacity of buffers, which grow by doubling
+const maxSizeOverhead = 1.0
+
+// text returns a string of the given length
+func text(i int, length int) string {
+	return strings.Repeat(string(rune('a'+i%26)), length)
+}
+
+// header returns n headers and the number of bytes of their names and values
+func header(n int) (http.Header, uint64) {
+	h := make(http.Header)
+	var content uint64
+	for j := range n {
+		name := fmt.Sprintf("X-Header-%d", j)
+		h[name] = []string{text(j, 40)}
+		content += uint64(len(name) + 40)
+	}
+	return h, content
+}
+
+// assertSize checks that the reported size of a value covers its content (the bytes of strings and bodies) plus a
+// reasonable overhead. Measuring the heap would be more precise, but depends on the garbage collector.
+func assertSize(t *testing.T, content uint64, reported uint64) {
+	t.Helper()
+
+	overhead := float64(reported)/float64(content) - 1
+	assert.GreaterOrEqual(t, reported, content, "reported %d bytes for %d bytes of content", reported, content)
+	assert.LessOrEqual(t, overhead, maxSizeOverhead, "reported %d bytes for %d bytes of content", reported, content)
+}
+
+func TestSize_Payloads(t *testing.T) {
+	requestHeaders, requestHeadersContent := header(12)
+	responseHeaders, responseHeadersContent := header(4)
+	clientResponseHeaders, clientResponseHeadersContent := header(20)
+
+	tests := []struct {
+		name    string
+		value   collector.Sizer
+		content uint64
+	}{
+		{
+			name: "server request",
+			value: collector.HTTPServerRequest{
+				Method:          http.MethodPost,
+				Path:            text(0, 30),
+				URL:             text(0, 60),
+				RequestHeaders:  requestHeaders,
+				ResponseHeaders: responseHeaders,
+				RequestBody:     newCapturedBody(t, nil, text(0, 3000), collector.DefaultMaxBodySize),
+				ResponseBody:    newCapturedBody(t, nil, text(0, 12000), collector.DefaultMaxBodySize),
+				Tags:            map[string]string{text(0, 6): text(0, 10)},
+			},
+			content: 4 + 30 + 60 + requestHeadersContent + responseHeadersContent + 3000 + 12000 + 16,
+		},
+		{
+			name: "server request with truncated body",
+			value: collector.HTTPServerRequest{
+				Path:         text(0, 30),
+				URL:          text(0, 30),
+				ResponseBody: newCapturedBody(t, nil, text(0, 30000), 10000),
+			},
+			content: 30 + 30 + 10000,
+		},
+		{
+			name: "client request",
+			value: collector.HTTPClientRequest{
+				Method:          http.MethodGet,
+				URL:             text(0, 80),
+				ResponseHeaders: clientResponseHeaders,
+				ResponseBody:    newCapturedBody(t, nil, text(0, 8000), collector.DefaultMaxBodySize),
+				Error:           errors.New(text(0, 50)),
+			},
+			content: 3 + 80 + clientResponseHeadersContent + 8000 + 50,
+		},
+		{
+			name: "db query",
+			value: collector.DBQuery{
+				Query:    text(0, 500),
+				Language: "postgresql",
+				Error:    errors.New(text(0, 40)),
+			},
+			content: 500 + 10 + 40,
+		},
+		{
+			name: "rpc call",
+			value: collector.RPCCall{
+				Procedure: text(0, 40),
+				Request:   text(0, 300),
+				Response:  text(0, 2000),
+			},
+			content: 40 + 300 + 2000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSize(t, tt.content, tt.value.Size())
+		})
+	}
+}
+
+func TestSize_Events(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    func() any
+		content uint64
+	}{
+		{
+			name: "log record",
+			data: func() any {
+				record := slog.NewRecord(time.Now(), slog.LevelInfo, text(0, 200), 0)
+				for j := range 8 {
+					record.AddAttrs(slog.String(text(j, 8), text(j, 100)))
+				}
+				return record
+			},
+			content: 200 + 8*(8+100),
+		},
+		{
+			name: "custom data",
+			data: func() any {
+				return map[string]string{text(0, 10): text(0, 500), text(1, 10): text(0, 300)}
+			},
+			content: 10 + 500 + 10 + 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := collector.NewEventAggregator()
+			defer aggregator.Close()
+
+			storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 10, collector.CaptureModeGlobal)
+			aggregator.RegisterStorage(storage)
+			aggregator.CollectEvent(context.Background(), tt.data())
+			events := storage.GetEvents(1)
+			require.Len(t, events, 1)
+
+			assertSize(t, tt.content, events[0].Size)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// skipPathRegexpPrefix marks an entry of HTTPServerOptions.SkipPaths as regular expression
+const skipPathRegexpPrefix = "regexp:"
+
+// pathMatcher is a compiled entry of HTTPServerOptions.SkipPaths
+type pathMatcher struct {
+	pattern string
+	// prefix is matched if the pattern is neither a glob nor a regular expression
+	prefix string
+	re     *regexp.Regexp
+}
+
+// compilePathMatcher compiles a path prefix, a glob (if it contains "*" or "?") or a regular expression (if it starts
+// with "regexp:")
+func compilePathMatcher(pattern string) (pathMatcher, error) {
+	m := pathMatcher{pattern: pattern}
+	switch {
+	case strings.HasPrefix(pattern, skipPathRegexpPrefix):
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, skipPathRegexpPrefix))
+		if err != nil {
+			return m, fmt.Errorf("invalid skip path %q: %w", pattern, err)
+		}
+		m.re = re
+	case strings.ContainsAny(pattern, "*?"):
+		m.re = regexp.MustCompile(globToRegexp(pattern))
+	default:
+		m.prefix = pattern
+	}
+	return m, nil
+}
+
+// match checks if a path is matched by the prefix, glob or regular expression
+func (m pathMatcher) match(p string) bool {
+	if m.re != nil {
+		return m.re.MatchString(p)
+	}
+	return m.prefix != "" && strings.HasPrefix(p, m.prefix)
+}
+
+// globToRegexp converts a glob matching a full path to a regular expression. "*" and "?" match any characters and
+// a single character within a segment, "**" matches across segments and "/**/" also matches a single slash.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "/**/"):
+			sb.WriteString("/(?:.*/)?")
+			i += 3
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		case glob[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// compileContentTypePatterns normalizes the patterns of HTTPServerOptions.SkipContentTypes for matchContentType
+func compileContentTypePatterns(patterns []string) []string {
+	compiled := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" {
+			compiled = append(compiled, strings.TrimSuffix(pattern, "*"))
+		}
+	}
+	return compiled
+}
+
+// matchContentType checks if the media type of a content type matches one of the compiled patterns. A pattern ending
+// with "/" (compiled from e.g. "image/*") matches all subtypes.
+func matchContentType(patterns []string, contentType string) bool {
+	if contentType == "" || len(patterns) == 0 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchContentTypePattern(pattern, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchContentTypePattern checks if a media type matches a compiled pattern
+func matchContentTypePattern(pattern, mediaType string) bool {
+	return mediaType == pattern || strings.HasSuffix(pattern, "/") && strings.HasPrefix(mediaType, pattern)
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// healthCheckPaths are the paths of common health check, readiness and liveness endpoints
+var healthCheckPaths = map[string]bool{
+	"/health":  true,
+	"/healthz": true,
+	"/readyz":  true,
+	"/livez":   true,
+}
+
+// staticAssetExtensions are file extensions of common static assets (styles, scripts, images and fonts)
+var staticAssetExtensions = map[string]bool{
+	".css":   true,
+	".js":    true,
+	".mjs":   true,
+	".map":   true,
+	".png":   true,
+	".jpg":   true,
+	".jpeg":  true,
+	".gif":   true,
+	".svg":   true,
+	".ico":   true,
+	".webp":  true,
+	".avif":  true,
+	".woff":  true,
+	".woff2": true,
+	".ttf":   true,
+	".otf":   true,
+	".eot":   true,
+}
+
+// isHealthCheckPath checks if a path is a common health check endpoint, only exact paths match so application routes
+// like "/api/health/summary" are kept
+func isHealthCheckPath(p string) bool {
+	return healthCheckPaths[strings.TrimSuffix(p, "/")]
+}
+
+// isStaticAssetPath checks if a path has the file extension of a common static asset
+func isStaticAssetPath(p string) bool {
+	return staticAssetExtensions[strings.ToLower(path.Ext(p))]
+}
+
+// isPreflightRequest checks if a request is a CORS preflight request sent by a browser
+func isPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// EventKindTask is the kind of events of concurrent operations started with TaskGroup.Go
+const EventKindTask EventKind = "task"
+
+// Task is the data of an event for a concurrent operation started with TaskGroup.Go
+type Task struct {
+	// Name describes the operation, e.g. "fetch user"
+	Name string `json:"name"`
+	// Error is the message of the error returned by the operation
+	Error string `json:"error,omitempty"`
+	// Panic is the value the operation panicked with
+	Panic string `json:"panic,omitempty"`
+}
+
+var _ EventPayload = Task{}
+
+// Kind implements EventPayload
+func (t Task) Kind() EventKind {
+	return EventKindTask
+}
+
+// Summary implements EventPayload
+func (t Task) Summary() string {
+	switch {
+	case t.Panic != "":
+		return fmt.Sprintf("%s panicked: %s", t.Name, t.Panic)
+	case t.Error != "":
+		return fmt.Sprintf("%s failed: %s", t.Name, t.Error)
+	}
+	return t.Name
+}
+
+// SearchText implements EventPayload
+func (t Task) SearchText() string {
+	return searchText(t.Name, t.Error, t.Panic)
+}
+
+// Size returns the estimated memory size of this task in bytes
+func (t Task) Size() uint64 {
+	return uint64(unsafe.Sizeof(t)) + uint64(len(t.Name)+len(t.Error)+len(t.Panic))
+}
+
+// MarshalJSON implements EventPayload
+func (t Task) MarshalJSON() ([]byte, error) {
+	type taskJSON Task
+	return json.Marshal(taskJSON(t))
+}
+
+// TaskGroup runs concurrent operations of an event (e.g. a request fanning out to several backends) in goroutines,
+// like errgroup.Group. Each operation is collected as a child event of the event of the context with its own time
+// range, so overlapping operations can be compared in the dashboard. Operations that outlive the event are added to
+// it when they complete.
+type TaskGroup struct {
+	aggregator *EventAggregator
+	ctx        context.Context
+	cancel     context.CancelCauseFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// NewTaskGroup returns a group for operations nested under the event of ctx and a derived context that is canceled
+// when an operation fails or Wait returns (like errgroup.WithContext). A nil aggregator only runs the operations.
+func NewTaskGroup(ctx context.Context, aggregator *EventAggregator) (*TaskGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &TaskGroup{
+		aggregator: aggregator,
+		ctx:        ctx,
+		cancel:     cancel,
+	}, ctx
+}
+
+// Go runs fn in a new goroutine. Its event is started before the goroutine, so operations are ordered by the calls of
+// Go. The first error returned by an operation cancels the context of the group.
+func (g *TaskGroup) Go(name string, fn func(ctx context.Context) error) {
+	ctx := g.ctx
+	capture := g.aggregator != nil && g.aggregator.ShouldCapture(ctx)
+	if capture {
+		ctx = g.aggregator.StartEvent(ctx)
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		task := Task{Name: name}
+		completed := false
+		if capture {
+			// End the event even if the operation panics, the panic continues like without a group
+			defer func() {
+				if !completed {
+					v := recover()
+					task.Panic = fmt.Sprint(v)
+					g.aggregator.EndEvent(ctx, task)
+					panic(v)
+				}
+			}()
+		}
+
+		err := fn(ctx)
+		completed = true
+		if err != nil {
+			task.Error = err.Error()
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel(err)
+			})
+		}
+		if capture {
+			g.aggregator.EndEvent(ctx, task)
+		}
+	}()
+}
+
+// Wait waits for all operations to complete and returns the first error returned by them
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
@@ -0,0 +1,204 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestTaskGroup_ChildrenOverlap(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	group, ctx := collector.NewTaskGroup(parentCtx, aggregator)
+
+	// Both tasks are running at the same time before any of them ends
+	var started sync.WaitGroup
+	started.Add(2)
+	for _, name := range []string{"first", "second"} {
+		group.Go(name, func(ctx context.Context) error {
+			started.Done()
+			started.Wait()
+			aggregator.CollectEvent(ctx, "log of "+name)
+			return nil
+		})
+	}
+	require.NoError(t, group.Wait())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	aggregator.EndEvent(parentCtx, "parent")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	parent := events[0]
+	assert.False(t, parent.LateChildren)
+	require.Len(t, parent.Children, 2)
+
+	first, second := parent.Children[0], parent.Children[1]
+	assert.Equal(t, collector.Task{Name: "first"}, first.Data)
+	assert.Equal(t, collector.Task{Name: "second"}, second.Data)
+	for _, child := range parent.Children {
+		assert.Equal(t, parent.ID, *child.GroupID)
+		require.Len(t, child.Children, 1)
+		assert.Equal(t, "log of "+child.Data.(collector.Task).Name, child.Children[0].Data)
+		assert.False(t, child.Start.Before(parent.Start))
+		assert.False(t, child.End.After(parent.End))
+	}
+	assert.True(t, first.Start.Before(second.End) && second.Start.Before(first.End), "time ranges of the tasks should overlap")
+}
+
+func TestTaskGroup_ChildrenEndAfterParent(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	group, _ := collector.NewTaskGroup(parentCtx, aggregator)
+
+	release := make(chan struct{})
+	group.Go("fast", func(ctx context.Context) error {
+		return nil
+	})
+	group.Go("slow", func(ctx context.Context) error {
+		<-release
+		aggregator.CollectEvent(ctx, "log of slow")
+		return nil
+	})
+
+	// The parent ends without waiting for the group (e.g. a handler responding early)
+	aggregator.EndEvent(parentCtx, "parent")
+	close(release)
+	require.NoError(t, group.Wait())
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	parent := events[0]
+	require.Len(t, parent.Children, 2)
+	assert.True(t, parent.LateChildren)
+	assert.Equal(t, "fast", parent.Children[0].Data.(collector.Task).Name)
+	assert.Equal(t, "slow", parent.Children[1].Data.(collector.Task).Name)
+	assert.True(t, parent.Children[1].End.After(parent.End))
+	require.Len(t, parent.Children[1].Children, 1)
+	assert.Equal(t, "log of slow", parent.Children[1].Children[0].Data)
+	assert.Zero(t, aggregator.Diagnostics().OrphanedEvents)
+}
+
+func TestTaskGroup_Error(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	parentCtx := aggregator.StartEvent(context.Background())
+	group, ctx := collector.NewTaskGroup(parentCtx, aggregator)
+
+	errFailed := errors.New("connection refused")
+	group.Go("failing", func(ctx context.Context) error {
+		return errFailed
+	})
+	group.Go("canceled", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, group.Wait(), errFailed)
+	assert.ErrorIs(t, context.Cause(ctx), errFailed)
+	aggregator.EndEvent(parentCtx, "parent")
+
+	events := storage.GetEvents(10)
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Children, 2)
+	failing := events[0].Children[0].Data.(collector.Task)
+	assert.Equal(t, "connection refused", failing.Error)
+	assert.Equal(t, "failing failed: connection refused", failing.Summary())
+	assert.Equal(t, context.Canceled.Error(), events[0].Children[1].Data.(collector.Task).Error)
+}
+
+func TestTaskGroup_NoCapture(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	group, _ := collector.NewTaskGroup(context.Background(), aggregator)
+	ran := false
+	group.Go("task", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	require.NoError(t, group.Wait())
+	assert.True(t, ran)
+
+	// Without an aggregator the operations only run
+	group, _ = collector.NewTaskGroup(context.Background(), nil)
+	group.Go("task", func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	assert.EqualError(t, group.Wait(), "failed")
+}
+
+func TestTaskGroup_ConcurrentGroups(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(storage)
+
+	numGroups := 20
+	numTasks := 5
+
+	var wg sync.WaitGroup
+	wg.Add(numGroups)
+	for i := range numGroups {
+		go func() {
+			defer wg.Done()
+
+			parentCtx := aggregator.StartEvent(context.Background())
+			group, _ := collector.NewTaskGroup(parentCtx, aggregator)
+			for j := range numTasks {
+				group.Go(fmt.Sprintf("task %d", j), func(ctx context.Context) error {
+					time.Sleep(time.Duration(j) * time.Millisecond)
+					aggregator.CollectEvent(ctx, "log")
+					return nil
+				})
+			}
+			// Half of the parents end while their tasks are still running
+			if i%2 == 0 {
+				assert.NoError(t, group.Wait())
+				aggregator.EndEvent(parentCtx, "parent")
+			} else {
+				aggregator.EndEvent(parentCtx, "parent")
+				assert.NoError(t, group.Wait())
+			}
+		}()
+	}
+	wg.Wait()
+
+	events := storage.GetEvents(uint64(numGroups))
+	require.Len(t, events, numGroups)
+	for _, evt := range events {
+		require.Len(t, evt.Children, numTasks)
+		for j, child := range evt.Children {
+			// Tasks are ordered by their start, not their end
+			assert.Equal(t, fmt.Sprintf("task %d", j), child.Data.(collector.Task).Name)
+			require.Len(t, child.Children, 1)
+		}
+	}
+	assert.Zero(t, aggregator.Diagnostics().OrphanedEvents)
+}
@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+)
+
+type tenantKeyType struct{}
+
+var tenantKey = tenantKeyType{}
+
+// TenantExtractor returns the tenant (e.g. the customer of a SaaS application) an incoming request belongs to,
+// an empty string if the request does not belong to a tenant
+type TenantExtractor func(r *http.Request) string
+
+// WithTenant returns a new context for events of a tenant. Events started or collected with the context are tagged with
+// the tenant, and captures restricted to another tenant do not capture them (see CaptureStorage.SetTenant).
+// The HTTP server collector sets the tenant of incoming requests with HTTPServerOptions.TenantExtractor, use WithTenant
+// for work outside of requests, e.g. background jobs of a tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the tenant of ctx, an empty string if ctx does not belong to a tenant
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
@@ -0,0 +1,60 @@
+package collector_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHTTPServerCollector_TenantExtractor(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	allTenants := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	aggregator.RegisterStorage(allTenants)
+	acmeOnly := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeGlobal)
+	acmeOnly.SetTenant("acme")
+	aggregator.RegisterStorage(acmeOnly)
+
+	options := collector.DefaultHTTPServerOptions()
+	options.EventAggregator = aggregator
+	options.TenantExtractor = func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}
+	serverCollector := collector.NewHTTPServerCollectorWithOptions(options)
+
+	handler := serverCollector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("X-Tenant"), collector.TenantFromContext(r.Context()))
+		aggregator.CollectEvent(r.Context(), "nested")
+	}))
+	for _, tenant := range []string{"acme", "globex", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/"+tenant, nil)
+		req.Header.Set("X-Tenant", tenant)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// Ambient events don't belong to a tenant unless collected with WithTenant
+	aggregator.CollectEvent(context.Background(), "ambient")
+	aggregator.CollectEvent(collector.WithTenant(context.Background(), "acme"), "job")
+
+	events := allTenants.GetEvents(10)
+	require.Len(t, events, 5)
+	assert.Equal(t, []string{"acme", "globex", "", "", "acme"}, []string{events[0].Tenant, events[1].Tenant, events[2].Tenant, events[3].Tenant, events[4].Tenant})
+	require.Len(t, events[0].Children, 1)
+	assert.Equal(t, "acme", events[0].Children[0].Tenant)
+
+	events = acmeOnly.GetEvents(10)
+	require.Len(t, events, 2)
+	assert.Equal(t, "/acme", events[0].Data.(collector.HTTPServerRequest).Path)
+	assert.Equal(t, "job", events[1].Data)
+
+	acmeOnly.SetTenant("")
+	aggregator.CollectEvent(context.Background(), "after restriction removed")
+	assert.Len(t, acmeOnly.GetEvents(10), 3)
+}
@@ -0,0 +1,20 @@
+package devlog
+
+import (
+	"context"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// GoWithContext runs fn in a new goroutine with a context detached from ctx (see collector.DetachedContext).
+// Events collected in fn are nested under the event of ctx (e.g. the incoming request) and captured for the same sessions,
+// but fn is not canceled when ctx is done.
+func GoWithContext(ctx context.Context, fn func(ctx context.Context)) {
+	go fn(collector.DetachedContext(ctx))
+}
+
+// Tag sets a tag on the incoming HTTP request that is handled with ctx (see collector.Tag).
+// Tags are shown in the dashboard and events can be filtered by them.
+func Tag(ctx context.Context, key, value string) {
+	collector.Tag(ctx, key, value)
+}
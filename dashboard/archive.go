@@ -0,0 +1,45 @@
+package dashboard
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// archivedEventCount returns the number of events of a storage in its archive, 0 if archival is disabled
+func archivedEventCount(storage *collector.CaptureStorage) int {
+	archive := storage.Archive()
+	if archive == nil {
+		return 0
+	}
+	return archive.Stats().Events
+}
+
+// archiveSegmentBefore returns the newest segment with an ID lower than before and the number of events in older
+// segments. Segments are ordered oldest first, ok is false if there is no segment before.
+func archiveSegmentBefore(segments []collector.EventArchiveSegment, before uint64) (segment collector.EventArchiveSegment, remaining int, ok bool) {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].ID >= before {
+			continue
+		}
+		for _, older := range segments[:i] {
+			remaining += older.Events
+		}
+		return segments[i], remaining, true
+	}
+	return collector.EventArchiveSegment{}, 0, false
+}
+
+// filterArchivedEvents returns the archived events of a segment matching the filter, newest first like the event list
+func filterArchivedEvents(events []*collector.Event, filter eventFilter) []*collector.Event {
+	if !filter.isEmpty() {
+		events = slices.DeleteFunc(events, func(event *collector.Event) bool {
+			return !filter.matches(event)
+		})
+	}
+	slices.SortStableFunc(events, func(a, b *collector.Event) int {
+		return cmp.Compare(b.Sequence, a.Sequence)
+	})
+	return events
+}
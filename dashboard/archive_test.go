@@ -0,0 +1,83 @@
+package dashboard
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestArchiveSegmentBefore(t *testing.T) {
+	segments := []collector.EventArchiveSegment{
+		{ID: 3, Events: 10},
+		{ID: 4, Events: 20},
+		{ID: 6, Events: 5},
+	}
+
+	segment, remaining, ok := archiveSegmentBefore(segments, ^uint64(0))
+	require.True(t, ok)
+	assert.Equal(t, uint64(6), segment.ID)
+	assert.Equal(t, 30, remaining)
+
+	// Segment 5 was deleted, the next older segment is used
+	segment, remaining, ok = archiveSegmentBefore(segments, 5)
+	require.True(t, ok)
+	assert.Equal(t, uint64(4), segment.ID)
+	assert.Equal(t, 10, remaining)
+
+	segment, remaining, ok = archiveSegmentBefore(segments, 4)
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), segment.ID)
+	assert.Equal(t, 0, remaining)
+
+	_, _, ok = archiveSegmentBefore(segments, 3)
+	assert.False(t, ok)
+	_, _, ok = archiveSegmentBefore(nil, ^uint64(0))
+	assert.False(t, ok)
+}
+
+func TestFilterArchivedEvents(t *testing.T) {
+	var events []*collector.Event
+	for i, data := range []any{collector.DBQuery{Query: "SELECT 1"}, "custom", collector.DBQuery{Query: "SELECT 2"}} {
+		events = append(events, &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: data, Sequence: uint64(i + 1)})
+	}
+
+	filtered := filterArchivedEvents(append([]*collector.Event(nil), events...), eventFilter{})
+	assert.Equal(t, []*collector.Event{events[2], events[1], events[0]}, filtered, "archived events should be newest first")
+
+	filtered = filterArchivedEvents(append([]*collector.Event(nil), events...), eventFilter{kind: collector.EventKindDBQuery})
+	assert.Equal(t, []*collector.Event{events[2], events[0]}, filtered)
+}
+
+func TestSessionManager_ArchivesEvictedEvents(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	dir := t.TempDir()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 1,
+		IdleTimeout:     time.Minute,
+		ArchiveDir:      dir,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := sm.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	require.NoError(t, err)
+	archive := storage.Archive()
+	require.NotNil(t, archive)
+	assert.Equal(t, filepath.Join(dir, storage.ID().String()), archive.Dir())
+
+	for i := range 3 {
+		storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.DBQuery{Query: "SELECT 1"}, Sequence: uint64(i + 1)})
+	}
+	archive.Flush()
+	assert.Equal(t, 2, archivedEventCount(storage))
+
+	sm.Delete(sessionID)
+	assert.NoDirExists(t, archive.Dir(), "the archive should be deleted with the session")
+}
@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+var staticURLPattern = regexp.MustCompile(`(?:href|src)="(/static/[^"]+)"`)
+
+// TestHandler_StaticAssets checks that all assets referenced by the dashboard are embedded and cached
+func TestHandler_StaticAssets(t *testing.T) {
+	handler := NewHandler(collector.NewEventAggregator())
+	defer handler.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+uuid.Must(uuid.NewV4()).String()+"/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d for dashboard, got %d", http.StatusOK, rec.Code)
+	}
+
+	matches := staticURLPattern.FindAllStringSubmatch(rec.Body.String(), -1)
+	if len(matches) == 0 {
+		t.Fatal("expected static assets in dashboard")
+	}
+	for _, match := range matches {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, match[1], nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d for %s, got %d", http.StatusOK, match[1], rec.Code)
+			continue
+		}
+		if cacheControl := rec.Header().Get("Cache-Control"); cacheControl != "public, max-age=31536000, immutable" {
+			t.Errorf("expected fingerprinted %s to be immutable, got Cache-Control %q", match[1], cacheControl)
+		}
+	}
+}
+
+func TestHandler_StaticAssets_ETag(t *testing.T) {
+	handler := NewHandler(collector.NewEventAggregator())
+	defer handler.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/main.css", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status %d with ETag, got %d and %q", http.StatusOK, rec.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/main.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for matching ETag, got %d", http.StatusNotModified, rec.Code)
+	}
+}
+
+func TestWithETag(t *testing.T) {
+	body := "<div>fragment</div>"
+	handler := withETag(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != body || etag == "" {
+		t.Fatalf("expected fragment with ETag, got %d %q and ETag %q", rec.Code, rec.Body.String(), etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected status %d without body, got %d %q", http.StatusNotModified, rec.Code, rec.Body.String())
+	}
+
+	body = "<div>changed</div>"
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != body {
+		t.Errorf("expected changed fragment, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxBinRequestSize limits the size of request bodies read by the request bin, larger bodies are cut off
+const maxBinRequestSize = 10 << 20
+
+// binResponse is the response body of the request bin
+const binResponse = "OK\n"
+
+// binResponseHeaders are sent with every response of the request bin
+var binResponseHeaders = http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+
+// receiveBinRequest is a request bin to inspect webhooks of third-party services: any request to /bin/{token}/... is
+// recorded as incoming request in the session of the bin token (see SessionManager.BinToken) and answered with 200 OK.
+// It is only recorded while the session captures.
+func (h *Handler) receiveBinRequest(w http.ResponseWriter, r *http.Request) {
+	if sessionID, ok := h.sessions.BinSession(r.PathValue("token")); ok && h.sessions.Get(sessionID) != nil {
+		h.recordBinRequest(w, r, sessionID)
+	}
+
+	for name, values := range binResponseHeaders {
+		w.Header()[name] = values
+	}
+	_, _ = io.WriteString(w, binResponse)
+}
+
+// recordBinRequest collects a request to the request bin in the session, the path of the request is relative to the bin
+func (h *Handler) recordBinRequest(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := collector.WithSessionIDs(context.WithoutCancel(r.Context()), []uuid.UUID{sessionID})
+	if !h.eventAggregator.Enabled() || !h.eventAggregator.ShouldCapture(ctx) {
+		return
+	}
+	eventCtx := h.eventAggregator.StartEvent(ctx)
+
+	request := collector.HTTPServerRequest{
+		ID:             uuid.Must(uuid.NewV7()),
+		Method:         r.Method,
+		Path:           "/" + r.PathValue("path"),
+		RemoteAddr:     r.RemoteAddr,
+		RequestTime:    time.Now(),
+		RequestHeaders: r.Header.Clone(),
+		Tags:           map[string]string{"source": "bin"},
+	}
+	request.URL = request.Path
+	if r.URL.RawQuery != "" {
+		request.URL += "?" + r.URL.RawQuery
+	}
+
+	body := collector.NewBody(http.MaxBytesReader(w, r.Body, maxBinRequestSize), collector.DefaultMaxBodySize)
+	size, _ := io.Copy(io.Discard, body)
+	_ = body.Close()
+	if size > 0 {
+		request.RequestBody = body
+		request.RequestSize = uint64(size)
+	}
+
+	request.ResponseTime = time.Now()
+	request.StatusCode = http.StatusOK
+	request.ResponseHeaders = binResponseHeaders.Clone()
+	request.ResponseSize = uint64(len(binResponse))
+	h.eventAggregator.EndEvent(eventCtx, request)
+}
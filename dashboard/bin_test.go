@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHandler_ReceiveBinRequest(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/capture/start", nil))
+	if rec.Code >= 400 {
+		t.Fatalf("failed to start capture: %d %s", rec.Code, rec.Body.String())
+	}
+
+	token := handler.sessions.BinToken(sessionID)
+	if token == "" || strings.Contains(token, sessionID.String()) {
+		t.Fatalf("expected a bin token independent of the session ID, got %q", token)
+	}
+	if other, ok := handler.sessions.BinSession(token); !ok || other != sessionID {
+		t.Fatalf("expected the bin token to map to session %s, got %s", sessionID, other)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/bin/"+token+"/stripe/webhook?attempt=1", strings.NewReader(`{"type":"charge.succeeded"}`))
+	req.Header.Set("Stripe-Signature", "t=1,v1=abc")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != binResponse {
+		t.Fatalf("expected status %d with %q, got %d: %q", http.StatusOK, binResponse, rec.Code, rec.Body.String())
+	}
+
+	events := handler.sessions.Get(sessionID).GetEvents(10)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 recorded request, got %d", len(events))
+	}
+	request, ok := events[0].Data.(collector.HTTPServerRequest)
+	if !ok {
+		t.Fatalf("expected an incoming request, got %#v", events[0].Data)
+	}
+	if request.Method != http.MethodPost || request.Path != "/stripe/webhook" || request.URL != "/stripe/webhook?attempt=1" {
+		t.Errorf("unexpected request %s %s (path %s)", request.Method, request.URL, request.Path)
+	}
+	if request.RequestHeaders.Get("Stripe-Signature") != "t=1,v1=abc" || request.RequestBody.String() != `{"type":"charge.succeeded"}` {
+		t.Errorf("expected headers and body to be recorded, got %v %q", request.RequestHeaders, request.RequestBody.String())
+	}
+	if request.Tags["source"] != "bin" || request.StatusCode != http.StatusOK {
+		t.Errorf("expected a bin request with status 200, got %v %d", request.Tags, request.StatusCode)
+	}
+
+	// Requests to the bin root and of unknown tokens are answered as well, the session ID is no bin token
+	for _, path := range []string{"/bin/" + token, "/bin/" + sessionID.String() + "/hook", "/bin/invalid/hook"} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d for %s, got %d", http.StatusOK, path, rec.Code)
+		}
+	}
+	if events := handler.sessions.Get(sessionID).GetEvents(10); len(events) != 2 {
+		t.Errorf("expected 2 recorded requests, got %d", len(events))
+	}
+
+	// The token is removed with the session
+	handler.sessions.Delete(sessionID)
+	if _, ok := handler.sessions.BinSession(token); ok {
+		t.Errorf("expected the bin token to be removed with the session")
+	}
+}
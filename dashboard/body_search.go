@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+const (
+	// bodySearchMaxMatches limits the number of matches returned by a body search
+	bodySearchMaxMatches = 100
+	// bodySearchContext is the number of bytes shown before and after a match
+	bodySearchContext = 40
+)
+
+// searchBody finds the occurrences of query in a captured body (ignoring ASCII case) and returns up to
+// bodySearchMaxMatches matches with their offsets and surrounding content, as well as the total number of matches.
+// Bodies may be binary, so non-printable content is replaced in the snippets.
+func searchBody(content []byte, query string) (matches []views.BodySearchMatch, total int) {
+	ranges := bodyMatchRanges(content, query)
+	for _, r := range ranges[:min(len(ranges), bodySearchMaxMatches)] {
+		start, end := r[0], r[1]
+		before := max(0, start-bodySearchContext)
+		after := min(len(content), end+bodySearchContext)
+		// Do not split UTF-8 sequences at the edges of the snippet
+		for before > 0 && !utf8.RuneStart(content[before]) {
+			before--
+		}
+		for after < len(content) && !utf8.RuneStart(content[after]) {
+			after++
+		}
+		matches = append(matches, views.BodySearchMatch{
+			Offset: start,
+			Before: printableSnippet(content[before:start]),
+			Match:  printableSnippet(content[start:end]),
+			After:  printableSnippet(content[end:after]),
+		})
+	}
+
+	return matches, len(ranges)
+}
+
+// bodyMatchRanges returns the start and end offsets of the occurrences of query in content (ignoring ASCII case)
+func bodyMatchRanges(content []byte, query string) [][2]int {
+	if query == "" {
+		return nil
+	}
+
+	var ranges [][2]int
+	haystack := asciiLower(content)
+	needle := asciiLower([]byte(query))
+	for offset := 0; offset <= len(haystack)-len(needle); {
+		index := bytes.Index(haystack[offset:], needle)
+		if index < 0 {
+			break
+		}
+		start := offset + index
+		ranges = append(ranges, [2]int{start, start + len(needle)})
+		offset = start + len(needle)
+	}
+	return ranges
+}
+
+// markBody splits the text of a body into segments with the occurrences of query marked, e.g. to highlight the matches
+// of a body search in the body preview. Like the preview, only the first views.BodyPreviewLength bytes are shown.
+func markBody(content []byte, query string) []views.BodySegment {
+	shown := content[:min(len(content), views.BodyPreviewLength)]
+
+	var (
+		segments []views.BodySegment
+		offset   int
+	)
+	for _, r := range bodyMatchRanges(shown, query) {
+		if r[0] > offset {
+			segments = append(segments, views.BodySegment{Text: string(shown[offset:r[0]])})
+		}
+		segments = append(segments, views.BodySegment{Text: string(shown[r[0]:r[1]]), Match: true})
+		offset = r[1]
+	}
+	if offset < len(shown) {
+		segments = append(segments, views.BodySegment{Text: string(shown[offset:])})
+	}
+	if len(shown) < len(content) {
+		segments = append(segments, views.BodySegment{Text: "..."})
+	}
+	return segments
+}
+
+func asciiLower(b []byte) []byte {
+	lower := make([]byte, len(b))
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		lower[i] = c
+	}
+	return lower
+}
+
+// printableSnippet converts content to a single line of text, invalid UTF-8 and control characters are shown as "."
+func printableSnippet(content []byte) string {
+	var sb strings.Builder
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		content = content[size:]
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			sb.WriteRune(' ')
+		case r == utf8.RuneError && size == 1, !unicode.IsPrint(r):
+			sb.WriteRune('.')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+func TestSearchBody(t *testing.T) {
+	content := []byte("{\"name\": \"Alice\",\n\"friends\": [\"alice2\", \"Bob\"]}")
+
+	matches, total := searchBody(content, "ALICE")
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []views.BodySearchMatch{
+		{Offset: 10, Before: `{"name": "`, Match: "Alice", After: `", "friends": ["alice2", "Bob"]}`},
+		{Offset: 31, Before: `{"name": "Alice", "friends": ["`, Match: "alice", After: `2", "Bob"]}`},
+	}, matches)
+
+	matches, total = searchBody(content, "")
+	assert.Empty(t, matches)
+	assert.Zero(t, total)
+}
+
+func TestSearchBody_Binary(t *testing.T) {
+	content := append([]byte{0x89, 'P', 'N', 'G', 0x00, 0xff}, []byte("IHDR")...)
+
+	matches, total := searchBody(content, "ihdr")
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []views.BodySearchMatch{{Offset: 6, Before: ".PNG..", Match: "IHDR"}}, matches)
+}
+
+func TestSearchBody_Limit(t *testing.T) {
+	content := []byte(strings.Repeat("ab", 500))
+
+	matches, total := searchBody(content, "b")
+	assert.Equal(t, 500, total)
+	assert.Len(t, matches, bodySearchMaxMatches)
+	assert.Equal(t, 3, matches[1].Offset)
+	assert.Len(t, matches[1].After, bodySearchContext)
+}
+
+func TestMarkBody(t *testing.T) {
+	segments := markBody([]byte(`{"name": "Alice", "friend": "alice2"}`), "ALICE")
+	assert.Equal(t, []views.BodySegment{
+		{Text: `{"name": "`},
+		{Text: "Alice", Match: true},
+		{Text: `", "friend": "`},
+		{Text: "alice", Match: true},
+		{Text: `2"}`},
+	}, segments)
+
+	assert.Equal(t, []views.BodySegment{{Text: "body"}}, markBody([]byte("body"), ""))
+
+	// Matches are only marked in the previewed part of the body
+	content := []byte(strings.Repeat("a", views.BodyPreviewLength-2) + "bbb")
+	segments = markBody(content, "b")
+	assert.Len(t, segments, 4)
+	assert.Equal(t, views.BodySegment{Text: "b", Match: true}, segments[2])
+	assert.Equal(t, views.BodySegment{Text: "..."}, segments[3])
+}
+
+func TestHandler_SearchEventBody(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	require.NoError(t, err)
+	event := &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.HTTPClientRequest{
+		Method:          http.MethodGet,
+		URL:             "https://example.com/",
+		ResponseHeaders: http.Header{"Content-Type": {"text/plain"}},
+		ResponseBody:    capturedBody(t, []byte("Hello <world>, hello!")),
+	}}
+	storage.Add(event)
+	searchURL := "/s/" + sessionID.String() + "/event/" + event.ID.String() + "/body-search?body=response&q="
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, searchURL+"hello", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `id="response-body-preview" hx-swap-oob="true"`)
+	assert.Contains(t, rec.Body.String(), "<mark>Hello</mark> &lt;world&gt;, <mark>hello</mark>!")
+
+	// Clearing the query restores the preview without marks
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, searchURL, nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `id="response-body-preview" hx-swap-oob="true"`)
+	assert.NotContains(t, rec.Body.String(), "<mark>")
+}
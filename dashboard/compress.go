@@ -0,0 +1,181 @@
+package dashboard
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// minCompressSize is the minimum size of a response with a known length to be compressed
+const minCompressSize = 1024
+
+var (
+	gzipWriters = sync.Pool{New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}}
+	brotliWriters = sync.Pool{New: func() any {
+		// A low level compresses HTML and JSON well without slowing down responses
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}}
+)
+
+// withCompression compresses responses with Brotli or gzip if the client accepts it, e.g. for remote access over slow links.
+// Only textual content is compressed, event streams are not compressed to send events immediately.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the preferred supported encoding of an Accept-Encoding header, an empty string for none
+func negotiateEncoding(acceptEncoding string) string {
+	var gzipAccepted bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			return "br"
+		case "gzip":
+			gzipAccepted = true
+		}
+	}
+	if gzipAccepted {
+		return "gzip"
+	}
+	return ""
+}
+
+// isCompressible returns true for textual content types that benefit from compression
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// compressResponseWriter decides on the first write whether to compress a response based on its headers
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	decided    bool
+	compressor io.WriteCloser // nil if the response is not compressed
+}
+
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.decide(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends compressed data written so far to the client
+func (w *compressResponseWriter) Flush() {
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to access the underlying response writer
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressResponseWriter) decide(status int) {
+	w.decided = true
+
+	header := w.Header()
+	if status != http.StatusOK && status != http.StatusCreated {
+		return
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" || !isCompressible(header.Get("Content-Type")) {
+		return
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < minCompressSize {
+		return
+	}
+
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	// The compressed representation is equivalent, but not byte-identical
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	switch w.encoding {
+	case "br":
+		bw := brotliWriters.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		w.compressor = bw
+	case "gzip":
+		gw := gzipWriters.Get().(*gzip.Writer)
+		gw.Reset(w.ResponseWriter)
+		w.compressor = gw
+	}
+}
+
+// close finishes the compressed stream and returns the compressor to its pool
+func (w *compressResponseWriter) close() {
+	if w.compressor == nil {
+		return
+	}
+	_ = w.compressor.Close()
+	switch c := w.compressor.(type) {
+	case *brotli.Writer:
+		c.Reset(io.Discard)
+		brotliWriters.Put(c)
+	case *gzip.Writer:
+		c.Reset(io.Discard)
+		gzipWriters.Put(c)
+	}
+	w.compressor = nil
+}
@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"identity", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	html := strings.Repeat("<div>event</div>", 200)
+	handler := withCompression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fragment":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, html)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, html)
+		case "/sse":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "event: keepalive\ndata: connected\n\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/fragment", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	gr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip response: %v", err)
+	}
+	if body, _ := io.ReadAll(gr); string(body) != html {
+		t.Error("expected decompressed gzip body to match")
+	}
+
+	rec = get("/fragment", "gzip, br")
+	if rec.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("expected br encoding, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if body, _ := io.ReadAll(brotli.NewReader(rec.Body)); string(body) != html {
+		t.Error("expected decompressed brotli body to match")
+	}
+
+	if rec := get("/fragment", ""); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != html {
+		t.Error("expected uncompressed response without Accept-Encoding")
+	}
+	if rec := get("/image", "br"); rec.Header().Get("Content-Encoding") != "" {
+		t.Error("expected images not to be compressed")
+	}
+	if rec := get("/sse", "br"); rec.Header().Get("Content-Encoding") != "" || !strings.Contains(rec.Body.String(), "keepalive") {
+		t.Errorf("expected event stream not to be compressed, got %q", rec.Body.String())
+	}
+}
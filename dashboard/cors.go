@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/internal/insights"
+)
+
+// corsWindow is the time after a preflight request in which the actual request is expected
+const corsWindow = 10 * time.Second
+
+// corsRequests returns the preflight and actual request of a CORS request given by one of them, the other one is nil
+// if it was not captured. A preflight request belongs to the first following request with the same path and origin
+// using the requested method. An actual request belongs to the latest preflight request that the browser might still
+// have cached.
+func corsRequests(events []*collector.Event, event *collector.Event) (preflight *collector.Event, actual *collector.Event) {
+	request, ok := event.Data.(collector.HTTPServerRequest)
+	if !ok || request.RequestHeaders.Get("Origin") == "" {
+		return nil, nil
+	}
+
+	isPreflight := insights.IsPreflight(request.Method, request.RequestHeaders)
+	if isPreflight {
+		preflight = event
+	} else {
+		actual = event
+	}
+
+	for _, e := range events {
+		for _, candidate := range e.Visit() {
+			data, ok := candidate.Data.(collector.HTTPServerRequest)
+			if !ok || data.Path != request.Path || data.RequestHeaders.Get("Origin") != request.RequestHeaders.Get("Origin") {
+				continue
+			}
+			candidateIsPreflight := insights.IsPreflight(data.Method, data.RequestHeaders)
+
+			if isPreflight {
+				if candidateIsPreflight || data.Method != request.RequestHeaders.Get("Access-Control-Request-Method") {
+					continue
+				}
+				if elapsed := candidate.Start.Sub(event.Start); elapsed < 0 || elapsed > corsWindow {
+					continue
+				}
+				if actual == nil || candidate.Start.Before(actual.Start) {
+					actual = candidate
+				}
+			} else {
+				if !candidateIsPreflight || data.RequestHeaders.Get("Access-Control-Request-Method") != request.Method {
+					continue
+				}
+				window := max(corsWindow, time.Duration(insights.PreflightMaxAge(data.ResponseHeaders))*time.Second)
+				if elapsed := event.Start.Sub(candidate.Start); elapsed < 0 || elapsed > window {
+					continue
+				}
+				if preflight == nil || candidate.Start.After(preflight.Start) {
+					preflight = candidate
+				}
+			}
+		}
+	}
+	return preflight, actual
+}
+
+// corsExchange returns the headers of a captured server request for the CORS diagnostics
+func corsExchange(event *collector.Event) *insights.Exchange {
+	if event == nil {
+		return nil
+	}
+	request := event.Data.(collector.HTTPServerRequest)
+	return &insights.Exchange{
+		Method:         request.Method,
+		StatusCode:     request.StatusCode,
+		RequestHeader:  request.RequestHeaders,
+		ResponseHeader: request.ResponseHeaders,
+	}
+}
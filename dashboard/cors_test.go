@@ -0,0 +1,59 @@
+package dashboard
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func corsTestEvent(start time.Time, method string, requestHeader http.Header, responseHeader http.Header) *collector.Event {
+	return &collector.Event{
+		ID:    uuid.Must(uuid.NewV4()),
+		Start: start,
+		Data: collector.HTTPServerRequest{
+			Method:          method,
+			Path:            "/api/todos",
+			StatusCode:      http.StatusOK,
+			RequestHeaders:  requestHeader,
+			ResponseHeaders: responseHeader,
+		},
+	}
+}
+
+func TestCORSRequests(t *testing.T) {
+	now := time.Now()
+	origin := http.Header{"Origin": {"https://app.example"}}
+	preflightHeader := http.Header{"Origin": {"https://app.example"}, "Access-Control-Request-Method": {"PUT"}}
+
+	oldPreflight := corsTestEvent(now.Add(-time.Minute), http.MethodOptions, preflightHeader, nil)
+	preflight := corsTestEvent(now, http.MethodOptions, preflightHeader, http.Header{"Access-Control-Max-Age": {"600"}})
+	actual := corsTestEvent(now.Add(10*time.Millisecond), http.MethodPut, origin, nil)
+	otherMethod := corsTestEvent(now.Add(5*time.Millisecond), http.MethodDelete, origin, nil)
+	later := corsTestEvent(now.Add(5*time.Minute), http.MethodPut, origin, nil)
+	otherOrigin := corsTestEvent(now.Add(20*time.Millisecond), http.MethodPut, http.Header{"Origin": {"https://evil.example"}}, nil)
+	events := []*collector.Event{later, otherOrigin, actual, otherMethod, preflight, oldPreflight}
+
+	p, a := corsRequests(events, preflight)
+	assert.Equal(t, preflight, p)
+	assert.Equal(t, actual, a)
+
+	p, a = corsRequests(events, actual)
+	assert.Equal(t, preflight, p)
+	assert.Equal(t, actual, a)
+
+	// The preflight result is cached for 10 minutes
+	p, _ = corsRequests(events, later)
+	assert.Equal(t, preflight, p)
+
+	p, _ = corsRequests(events, otherOrigin)
+	assert.Nil(t, p)
+
+	p, a = corsRequests(events, corsTestEvent(now, http.MethodGet, nil, nil))
+	assert.Nil(t, p)
+	assert.Nil(t, a)
+}
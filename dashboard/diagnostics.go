@@ -0,0 +1,113 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+// DiagnosticsResponse is the response for GET /s/{sid}/debug
+type DiagnosticsResponse struct {
+	collector.Diagnostics
+	// SSEConnections is the number of connected dashboards over all sessions
+	SSEConnections int `json:"sseConnections"`
+	// Storages are all storages of the aggregator, including the flight recorder and programmatic captures
+	Storages []DiagnosticsStorage `json:"storages"`
+}
+
+// DiagnosticsStorage describes a storage of the aggregator
+type DiagnosticsStorage struct {
+	collector.StorageStats
+	// Session is set if the storage belongs to a capture session of the dashboard
+	Session *SessionInfo `json:"session,omitempty"`
+	// FlightRecorder is true for the storage of the flight recorder
+	FlightRecorder bool `json:"flightRecorder,omitempty"`
+}
+
+// diagnostics collects the health of the aggregator and its storages
+func (h *Handler) diagnostics() DiagnosticsResponse {
+	sessions := make(map[uuid.UUID]SessionInfo)
+	for _, info := range h.sessions.Sessions() {
+		if storage := h.sessions.Get(info.SessionID); storage != nil {
+			sessions[storage.ID()] = info
+		}
+	}
+
+	response := DiagnosticsResponse{
+		Diagnostics: h.eventAggregator.Diagnostics(),
+	}
+	for _, stats := range h.eventAggregator.CalculateStats().Storages {
+		storage := DiagnosticsStorage{StorageStats: stats}
+		if info, ok := sessions[stats.StorageID]; ok {
+			storage.Session = &info
+			response.SSEConnections += info.SSEConnections
+		} else if h.flightRecorder != nil && stats.StorageID == h.flightRecorder.storage.ID() {
+			storage.FlightRecorder = true
+		}
+		response.Storages = append(response.Storages, storage)
+	}
+	return response
+}
+
+// getDiagnostics renders the health of devlog itself as HTML for HTMX or JSON for API, to debug why events don't appear
+func (h *Handler) getDiagnostics(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	response := h.diagnostics()
+
+	if r.Header.Get("HX-Request") == "true" {
+		storage := h.sessions.Get(sessionID)
+		captureActive := false
+		captureMode := "session"
+		captureAmbient := false
+		if storage != nil {
+			captureActive = true
+			captureMode = storage.CaptureMode().String()
+			captureAmbient = storage.CaptureAmbient()
+		}
+
+		storages := make([]views.DiagnosticsStorage, len(response.Storages))
+		for i, s := range response.Storages {
+			storages[i] = diagnosticsStorageView(s, sessionID)
+		}
+
+		r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode, captureAmbient)
+		templ.Handler(
+			views.DiagnosticsContainer(response.Diagnostics, storages),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	// JSON response for API
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// diagnosticsStorageView converts a storage for the diagnostics view of the given session
+func diagnosticsStorageView(s DiagnosticsStorage, sessionID uuid.UUID) views.DiagnosticsStorage {
+	storage := views.DiagnosticsStorage{
+		Capturing: true,
+		Mode:      collector.CaptureModeGlobal.String(),
+		Stats:     s.StorageStats,
+	}
+	switch {
+	case s.Session != nil:
+		storage.Label = s.Session.SessionID.String()
+		if s.Session.Name != "" {
+			storage.Label = s.Session.Name
+		}
+		storage.Current = s.Session.SessionID == sessionID
+		storage.Capturing = s.Session.Capturing
+		storage.Mode = s.Session.Mode
+		storage.SSEConnections = s.Session.SSEConnections
+	case s.FlightRecorder:
+		storage.Label = "Flight recorder"
+	default:
+		storage.Label = "Programmatic capture " + s.StorageID.String()
+	}
+	return storage
+}
@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHandler_GetDiagnostics(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator, WithFlightRecorder(10))
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if _, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeGlobal); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer handler.sessions.TrackSSEConnection(sessionID)()
+
+	req := httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/debug", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var response DiagnosticsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.SSEConnections != 1 {
+		t.Errorf("expected 1 SSE connection, got %d", response.SSEConnections)
+	}
+	if len(response.Storages) != 2 {
+		t.Fatalf("expected storages of the session and the flight recorder, got %d", len(response.Storages))
+	}
+
+	var session, flightRecorder int
+	for _, storage := range response.Storages {
+		if storage.Session != nil && storage.Session.SessionID == sessionID {
+			session++
+		}
+		if storage.FlightRecorder {
+			flightRecorder++
+		}
+	}
+	if session != 1 || flightRecorder != 1 {
+		t.Errorf("expected one storage of the session and one of the flight recorder, got %+v", response.Storages)
+	}
+}
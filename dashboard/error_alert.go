@@ -0,0 +1,50 @@
+package dashboard
+
+import (
+	"log/slog"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// ErrorAlert is sent to the dashboard of a session with error alerts enabled when a captured event contains an error
+type ErrorAlert struct {
+	Title   string    `json:"title"`
+	EventID uuid.UUID `json:"eventId"` // ID of the top-level event to show in the dashboard
+}
+
+// isErrorEvent returns true for 5xx responses, handler panics, logs with at least level error and internal errors
+func isErrorEvent(event *collector.Event) bool {
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		return data.StatusCode >= 500 || data.Panic != ""
+	case collector.HTTPClientRequest:
+		return data.StatusCode >= 500
+	case slog.Record:
+		return data.Level >= slog.LevelError
+	case collector.InternalError:
+		return true
+	default:
+		return false
+	}
+}
+
+// errorAlert returns an alert for the first error in the tree of a completed top-level event, false if it has none.
+// An event updated with late children was alerted before.
+func errorAlert(event *collector.Event) (ErrorAlert, bool) {
+	if event.InProgress || event.LateChildren {
+		return ErrorAlert{}, false
+	}
+	for _, e := range event.Visit() {
+		if !isErrorEvent(e) {
+			continue
+		}
+		title := collector.PayloadOf(e.Data).Summary()
+		if request, ok := e.Data.(collector.HTTPServerRequest); ok && request.Panic != "" {
+			title = "panic: " + request.Panic
+		}
+		return ErrorAlert{Title: title, EventID: event.ID}, true
+	}
+	return ErrorAlert{}, false
+}
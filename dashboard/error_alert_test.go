@@ -0,0 +1,117 @@
+package dashboard
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestErrorAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     *collector.Event
+		wantAlert bool
+		wantTitle string
+	}{
+		{
+			name:  "successful request",
+			event: &collector.Event{Data: collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 200}},
+		},
+		{
+			name:      "server error",
+			event:     &collector.Event{Data: collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 503}},
+			wantAlert: true,
+		},
+		{
+			name:      "panic",
+			event:     &collector.Event{Data: collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 500, Panic: "boom"}},
+			wantAlert: true,
+			wantTitle: "panic: boom",
+		},
+		{
+			name: "request in progress",
+			event: &collector.Event{
+				Data:       collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 500},
+				InProgress: true,
+			},
+		},
+		{
+			name: "update with late children",
+			event: &collector.Event{
+				Data:         collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 500},
+				LateChildren: true,
+			},
+		},
+		{
+			name: "nested error log",
+			event: &collector.Event{
+				Data: collector.HTTPServerRequest{Method: "GET", Path: "/", StatusCode: 200},
+				Children: []*collector.Event{
+					{Data: slog.NewRecord(time.Now(), slog.LevelInfo, "loading", 0)},
+					{Data: slog.NewRecord(time.Now(), slog.LevelError, "query failed", 0)},
+				},
+			},
+			wantAlert: true,
+			wantTitle: "ERROR query failed",
+		},
+		{
+			name:  "warning log",
+			event: &collector.Event{Data: slog.NewRecord(time.Now(), slog.LevelWarn, "slow query", 0)},
+		},
+		{
+			name:      "client error response",
+			event:     &collector.Event{Data: collector.HTTPClientRequest{Method: "GET", URL: "https://example.com", StatusCode: 502}},
+			wantAlert: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.ID = uuid.Must(uuid.NewV7())
+			alert, ok := errorAlert(tt.event)
+			if ok != tt.wantAlert {
+				t.Fatalf("expected alert %v, got %v", tt.wantAlert, ok)
+			}
+			if !ok {
+				return
+			}
+			if alert.EventID != tt.event.ID {
+				t.Errorf("expected event ID %s, got %s", tt.event.ID, alert.EventID)
+			}
+			if tt.wantTitle != "" && alert.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, alert.Title)
+			}
+		})
+	}
+}
+
+func TestSessionManager_ErrorAlerts(t *testing.T) {
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: collector.NewEventAggregator(),
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if sm.SetErrorAlerts(sessionID, true) {
+		t.Error("expected SetErrorAlerts to return false for non-existent session")
+	}
+
+	if _, _, err := sm.GetOrCreate(sessionID, collector.CaptureModeGlobal); err != nil {
+		t.Fatal(err)
+	}
+	if sm.ErrorAlerts(sessionID) {
+		t.Error("expected error alerts to be disabled by default")
+	}
+	if !sm.SetErrorAlerts(sessionID, true) {
+		t.Fatal("expected SetErrorAlerts to return true for existing session")
+	}
+	if !sm.ErrorAlerts(sessionID) {
+		t.Error("expected error alerts to be enabled")
+	}
+}
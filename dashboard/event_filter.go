@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// eventFilter selects top-level events by tag, tenant, kind, path, severity and time range, zero values match any event
+type eventFilter struct {
+	// tag is given as "key" or "key:value"
+	tag    string
+	tenant string
+	kind   collector.EventKind
+	// path is a prefix of the path of HTTP requests
+	path string
+	// severity is the minimum severity of the event or one of its children
+	severity collector.Severity
+	since    time.Time
+	until    time.Time
+}
+
+// parseEventFilter reads a filter from the query parameters "tag", "tenant", "kind", "path", "severity" (e.g. "warn"),
+// "since" and "until" (RFC 3339). The parameter "last" (e.g. "5m") filters events of the last duration, it takes
+// precedence over "since".
+func parseEventFilter(query url.Values) (eventFilter, error) {
+	f := eventFilter{
+		tag:    query.Get("tag"),
+		tenant: query.Get("tenant"),
+		kind:   collector.EventKind(query.Get("kind")),
+		path:   query.Get("path"),
+	}
+	if value := query.Get("severity"); value != "" {
+		severity, err := collector.ParseSeverity(value)
+		if err != nil {
+			return eventFilter{}, err
+		}
+		f.severity = severity
+	}
+	for name, t := range map[string]*time.Time{"since": &f.since, "until": &f.until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return eventFilter{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*t = parsed
+	}
+	if value := query.Get("last"); value != "" {
+		last, err := time.ParseDuration(value)
+		if err != nil || last <= 0 {
+			return eventFilter{}, fmt.Errorf("invalid last: %q", value)
+		}
+		f.since = time.Now().Add(-last)
+	}
+	return f, nil
+}
+
+// isEmpty returns true if the filter matches any event
+func (f eventFilter) isEmpty() bool {
+	return f == eventFilter{}
+}
+
+func (f eventFilter) matches(event *collector.Event) bool {
+	if f.tag != "" && !matchesTagFilter(event, f.tag) {
+		return false
+	}
+	if f.tenant != "" && event.Tenant != f.tenant {
+		return false
+	}
+	if f.kind != "" && event.Kind() != f.kind {
+		return false
+	}
+	if f.path != "" && !strings.HasPrefix(eventPath(event), f.path) {
+		return false
+	}
+	if f.severity != "" && !event.MaxSeverity().AtLeast(f.severity) {
+		return false
+	}
+	if !f.since.IsZero() && event.Start.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !event.Start.Before(f.until) {
+		return false
+	}
+	return true
+}
+
+// eventPath returns the path of an HTTP request event, or an empty string for other events
+func eventPath(event *collector.Event) string {
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		return data.Path
+	case collector.HTTPClientRequest:
+		if u, err := url.Parse(data.URL); err == nil {
+			return u.Path
+		}
+	}
+	return ""
+}
+
+// firstEventAfter returns the earliest event started at or after the given time, nil if there is none
+func firstEventAfter(events []*collector.Event, at time.Time) *collector.Event {
+	var first *collector.Event
+	for _, event := range events {
+		if event.Start.Before(at) {
+			continue
+		}
+		if first == nil || event.Start.Before(first.Start) {
+			first = event
+		}
+	}
+	return first
+}
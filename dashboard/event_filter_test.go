@@ -0,0 +1,175 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestEventFilter_Matches(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	serverRequest := &collector.Event{
+		ID:    uuid.Must(uuid.NewV7()),
+		Start: start,
+		Data: collector.HTTPServerRequest{
+			Method: http.MethodGet,
+			Path:   "/api/todos/1",
+			Tags:   map[string]string{"tenant": "acme"},
+		},
+		Children: []*collector.Event{{
+			ID:       uuid.Must(uuid.NewV7()),
+			Data:     collector.DBQuery{Query: "SELECT * FROM todos", Duration: 250 * time.Millisecond},
+			Severity: collector.SeverityWarn,
+		}},
+	}
+	clientRequest := &collector.Event{
+		ID:     uuid.Must(uuid.NewV7()),
+		Start:  start.Add(time.Minute),
+		Tenant: "globex",
+		Data: collector.HTTPClientRequest{
+			Method: http.MethodGet,
+			URL:    "https://example.com/api/users?page=2",
+		},
+	}
+	query := &collector.Event{
+		ID:       uuid.Must(uuid.NewV7()),
+		Start:    start.Add(2 * time.Minute),
+		Data:     collector.DBQuery{Query: "SELECT 1"},
+		Severity: collector.SeverityError,
+	}
+	events := []*collector.Event{serverRequest, clientRequest, query}
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected []*collector.Event
+	}{
+		{
+			name:     "empty",
+			query:    url.Values{},
+			expected: events,
+		},
+		{
+			name:     "tag",
+			query:    url.Values{"tag": {"tenant:acme"}},
+			expected: []*collector.Event{serverRequest},
+		},
+		{
+			name:     "tenant",
+			query:    url.Values{"tenant": {"globex"}},
+			expected: []*collector.Event{clientRequest},
+		},
+		{
+			name:     "kind",
+			query:    url.Values{"kind": {string(collector.EventKindDBQuery)}},
+			expected: []*collector.Event{query},
+		},
+		{
+			name:     "path prefix of server and client requests",
+			query:    url.Values{"path": {"/api/"}},
+			expected: []*collector.Event{serverRequest, clientRequest},
+		},
+		{
+			name:     "time range",
+			query:    url.Values{"since": {start.Add(time.Minute).Format(time.RFC3339)}, "until": {start.Add(2 * time.Minute).Format(time.RFC3339)}},
+			expected: []*collector.Event{clientRequest},
+		},
+		{
+			name:     "severity of event or its children",
+			query:    url.Values{"severity": {string(collector.SeverityWarn)}},
+			expected: []*collector.Event{serverRequest, query},
+		},
+		{
+			name:     "severity",
+			query:    url.Values{"severity": {string(collector.SeverityError)}},
+			expected: []*collector.Event{query},
+		},
+		{
+			name:     "combined",
+			query:    url.Values{"path": {"/api/"}, "kind": {string(collector.EventKindHTTPClientRequest)}},
+			expected: []*collector.Event{clientRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseEventFilter(tt.query)
+			if err != nil {
+				t.Fatalf("failed to parse filter: %v", err)
+			}
+			if filter.isEmpty() != (len(tt.query) == 0) {
+				t.Errorf("expected isEmpty to be %v", len(tt.query) == 0)
+			}
+
+			var matched []*collector.Event
+			for _, event := range events {
+				if filter.matches(event) {
+					matched = append(matched, event)
+				}
+			}
+			if len(matched) != len(tt.expected) {
+				t.Fatalf("expected %d matching events, got %d", len(tt.expected), len(matched))
+			}
+			for i := range matched {
+				if matched[i] != tt.expected[i] {
+					t.Errorf("expected event %d to be %s, got %s", i, tt.expected[i].ID, matched[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEventFilter_InvalidTime(t *testing.T) {
+	_, err := parseEventFilter(url.Values{"since": {"yesterday"}})
+	if err == nil {
+		t.Fatal("expected an error for an invalid time")
+	}
+}
+
+func TestParseEventFilter_InvalidSeverity(t *testing.T) {
+	_, err := parseEventFilter(url.Values{"severity": {"fatal"}})
+	if err == nil {
+		t.Fatal("expected an error for an invalid severity")
+	}
+}
+
+func TestParseEventFilter_Last(t *testing.T) {
+	before := time.Now()
+	filter, err := parseEventFilter(url.Values{"last": {"5m"}, "since": {"2025-06-01T12:00:00Z"}})
+	if err != nil {
+		t.Fatalf("failed to parse filter: %v", err)
+	}
+	if filter.since.Before(before.Add(-5*time.Minute)) || filter.since.After(time.Now().Add(-5*time.Minute)) {
+		t.Errorf("expected since to be 5 minutes ago, got %s", filter.since)
+	}
+
+	for _, last := range []string{"soon", "-5m", "0s"} {
+		if _, err := parseEventFilter(url.Values{"last": {last}}); err == nil {
+			t.Errorf("expected an error for last %q", last)
+		}
+	}
+}
+
+func TestFirstEventAfter(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(offset time.Duration) *collector.Event {
+		return &collector.Event{ID: uuid.Must(uuid.NewV7()), Start: start.Add(offset)}
+	}
+	// Events are stored newest first
+	events := []*collector.Event{newEvent(2 * time.Minute), newEvent(time.Minute), newEvent(0)}
+
+	if first := firstEventAfter(events, start.Add(30*time.Second)); first != events[1] {
+		t.Errorf("expected the event after 1 minute, got %v", first)
+	}
+	if first := firstEventAfter(events, start); first != events[2] {
+		t.Errorf("expected the event started at the time, got %v", first)
+	}
+	if first := firstEventAfter(events, start.Add(3*time.Minute)); first != nil {
+		t.Errorf("expected no event, got %v", first)
+	}
+}
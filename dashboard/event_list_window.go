@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+// eventListPageSize is the number of events rendered at once in the event list, spacers stand in for the events before
+// and after the window and the window at the scroll position is fetched when scrolling
+const eventListPageSize = 100
+
+// eventListWindowAt returns the window of the event list starting at the given offset and the offset of the window.
+// The offset is clamped, so the window is a full page if there are enough events.
+func eventListWindowAt(events []*collector.Event, offset int) (window []*collector.Event, start int) {
+	start = max(0, min(offset, len(events)-eventListPageSize))
+	return events[start:min(start+eventListPageSize, len(events))], start
+}
+
+// firstEventListWindow returns the first window of the event list and its offset. The window starts a bit before the
+// selected event if it is not on the first page, so it is visible when opening the dashboard with a selected event.
+func firstEventListWindow(events []*collector.Event, selectedEventID *uuid.UUID) ([]*collector.Event, int) {
+	offset := 0
+	if selectedEventID != nil {
+		for i, event := range events {
+			if event.ID == *selectedEventID {
+				if i >= eventListPageSize {
+					offset = i - eventListPageSize/4
+				}
+				break
+			}
+		}
+	}
+	return eventListWindowAt(events, offset)
+}
+
+// newEventListProps returns the props of the event list rendering the first window of the events. Ambient events are
+// shown in a separate section that is not windowed if captureAmbient is set, only the newest page of them is rendered.
+func newEventListProps(events []*collector.Event, selectedEventID *uuid.UUID, captureAmbient bool) views.EventListProps {
+	var ambientEvents []*collector.Event
+	if captureAmbient {
+		events, ambientEvents = splitAmbientEvents(events)
+		ambientEvents = ambientEvents[:min(eventListPageSize, len(ambientEvents))]
+	}
+	window, offset := firstEventListWindow(events, selectedEventID)
+	return views.EventListProps{
+		SelectedEventID: selectedEventID,
+		Events:          window,
+		Offset:          offset,
+		TotalEvents:     len(events),
+		AmbientEvents:   ambientEvents,
+		CaptureAmbient:  captureAmbient,
+	}
+}
+
+// splitAmbientEvents separates events outside of requests from the other events, keeping their order
+func splitAmbientEvents(events []*collector.Event) (requestEvents, ambientEvents []*collector.Event) {
+	for _, event := range events {
+		if event.Ambient {
+			ambientEvents = append(ambientEvents, event)
+		} else {
+			requestEvents = append(requestEvents, event)
+		}
+	}
+	return requestEvents, ambientEvents
+}
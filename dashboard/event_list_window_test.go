@@ -0,0 +1,162 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+func TestFirstEventListWindow(t *testing.T) {
+	events := make([]*collector.Event, 250)
+	for i := range events {
+		events[i] = &collector.Event{ID: uuid.Must(uuid.NewV7())}
+	}
+
+	window, offset := firstEventListWindow(events, nil)
+	assert.Equal(t, events[:eventListPageSize], window)
+	assert.Equal(t, 0, offset)
+
+	window, _ = firstEventListWindow(events[:10], nil)
+	assert.Len(t, window, 10)
+
+	window, offset = firstEventListWindow(events, &events[42].ID)
+	assert.Equal(t, events[:eventListPageSize], window)
+	assert.Equal(t, 0, offset)
+
+	window, offset = firstEventListWindow(events, &events[130].ID)
+	assert.Equal(t, 105, offset, "window should start a quarter page before the selected event")
+	assert.Len(t, window, eventListPageSize)
+
+	window, offset = firstEventListWindow(events, &events[240].ID)
+	assert.Equal(t, events[150:], window, "window at the end should be a full page")
+	assert.Equal(t, 150, offset)
+
+	unknownID := uuid.Must(uuid.NewV7())
+	window, offset = firstEventListWindow(events, &unknownID)
+	assert.Len(t, window, eventListPageSize)
+	assert.Equal(t, 0, offset)
+}
+
+func TestEventListWindowAt(t *testing.T) {
+	events := make([]*collector.Event, 250)
+	for i := range events {
+		events[i] = &collector.Event{ID: uuid.Must(uuid.NewV7())}
+	}
+
+	window, offset := eventListWindowAt(events, 100)
+	assert.Equal(t, events[100:200], window)
+	assert.Equal(t, 100, offset)
+
+	window, offset = eventListWindowAt(events, 200)
+	assert.Equal(t, events[150:], window, "offset should be clamped to a full page")
+	assert.Equal(t, 150, offset)
+
+	window, offset = eventListWindowAt(events[:10], 5)
+	assert.Equal(t, events[:10], window)
+	assert.Equal(t, 0, offset)
+
+	window, offset = eventListWindowAt(nil, 5)
+	assert.Empty(t, window)
+	assert.Equal(t, 0, offset)
+}
+
+func TestNewEventListProps(t *testing.T) {
+	events := make([]*collector.Event, 250)
+	for i := range events {
+		events[i] = &collector.Event{ID: uuid.Must(uuid.NewV7()), Ambient: i%2 == 0}
+	}
+
+	props := newEventListProps(events, nil, false)
+	assert.Equal(t, events[:eventListPageSize], props.Events)
+	assert.Equal(t, 250, props.TotalEvents)
+	assert.Empty(t, props.AmbientEvents)
+
+	props = newEventListProps(events, &events[201].ID, true)
+	assert.Equal(t, 125, props.TotalEvents, "ambient events should not be windowed")
+	assert.Len(t, props.AmbientEvents, eventListPageSize)
+	assert.Equal(t, 25, props.Offset)
+	assert.Contains(t, props.Events, events[201])
+	assert.Equal(t, &events[201].ID, props.SelectedEventID)
+}
+
+func TestHandler_GetEventListPage(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	require.NoError(t, err)
+	for i := range 250 {
+		storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.DBQuery{Query: "SELECT 1"}, Sequence: uint64(i + 1)})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/event-list/page?offset=120", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := rec.Body.String()
+	assert.Contains(t, body, `id="event-list-before" class="event-list-spacer hidden" data-count="120"`)
+	assert.Contains(t, body, `id="event-list-after" class="event-list-spacer hidden" data-count="30"`)
+	assert.Equal(t, eventListPageSize, strings.Count(body, "data-sequence="))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/event-list/page?offset=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func BenchmarkEventList_Render(b *testing.B) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]*collector.Event, 500)
+	for i := range events {
+		requestStart := start.Add(time.Duration(i) * time.Second)
+		events[i] = &collector.Event{
+			ID:    uuid.Must(uuid.NewV7()),
+			Start: requestStart,
+			End:   requestStart.Add(20 * time.Millisecond),
+			Data: collector.HTTPServerRequest{
+				Method:          http.MethodGet,
+				Path:            fmt.Sprintf("/api/orders/%d", i),
+				StatusCode:      http.StatusOK,
+				RequestTime:     requestStart,
+				ResponseTime:    requestStart.Add(20 * time.Millisecond),
+				ResponseHeaders: http.Header{"Content-Type": []string{"application/json"}},
+			},
+			Children: []*collector.Event{
+				{
+					ID:    uuid.Must(uuid.NewV7()),
+					Start: requestStart,
+					End:   requestStart.Add(2 * time.Millisecond),
+					Data:  collector.DBQuery{Query: "SELECT * FROM orders WHERE id = $1", Duration: 2 * time.Millisecond, Timestamp: requestStart},
+				},
+			},
+		}
+	}
+	window, _ := firstEventListWindow(events, nil)
+	ctx := views.WithHandlerOptions(context.Background(), views.HandlerOptions{SessionID: "bench", CaptureActive: true, CaptureMode: "session"})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := views.EventList(views.EventListProps{
+			Events:        window,
+			TotalEvents:   len(events),
+			CaptureActive: true,
+			CaptureMode:   "session",
+		}).Render(ctx, io.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package dashboard
+
+import (
+	"reflect"
+
+	"github.com/a-h/templ"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+// EventRenderer renders events with custom data of type T in the dashboard.
+// Events with an unregistered data type are shown with their type name and fields as key/value pairs.
+type EventRenderer[T any] struct {
+	// Name is shown as a badge in the event list.
+	// Default: name of the type
+	Name string
+	// Summary returns the text shown in the event list (optional)
+	Summary func(data T) string
+	// Details renders the detail view of an event (optional).
+	// Default: fields of the data as key/value pairs
+	Details func(event *collector.Event, data T) templ.Component
+}
+
+// RegisterEventRenderer registers a renderer for events with custom data of type T (e.g. collected with devlog.Instance.CollectEvent).
+// It should be called during initialization, before events are rendered.
+func RegisterEventRenderer[T any](renderer EventRenderer[T]) {
+	r := views.EventRenderer{
+		Name: renderer.Name,
+	}
+	if renderer.Summary != nil {
+		r.Summary = func(event *collector.Event) string {
+			return renderer.Summary(event.Data.(T))
+		}
+	}
+	if renderer.Details != nil {
+		r.Details = func(event *collector.Event) templ.Component {
+			return renderer.Details(event, event.Data.(T))
+		}
+	}
+	views.RegisterEventRenderer(reflect.TypeFor[T](), r)
+}
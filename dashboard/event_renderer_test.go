@@ -0,0 +1,90 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+type cacheHit struct {
+	Key  string
+	Size int
+}
+
+type featureFlag struct {
+	Name    string
+	Enabled bool
+}
+
+func renderToString(t *testing.T, component templ.Component) string {
+	t.Helper()
+
+	ctx := views.WithHandlerOptions(context.Background(), views.HandlerOptions{SessionID: "test"})
+	var sb strings.Builder
+	if err := component.Render(ctx, &sb); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return sb.String()
+}
+
+func TestRegisterEventRenderer(t *testing.T) {
+	RegisterEventRenderer(EventRenderer[cacheHit]{
+		Name: "Cache",
+		Summary: func(data cacheHit) string {
+			return "hit " + data.Key
+		},
+		Details: func(event *collector.Event, data cacheHit) templ.Component {
+			return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				_, err := io.WriteString(w, "<p>custom details for "+data.Key+"</p>")
+				return err
+			})
+		},
+	})
+
+	event := &collector.Event{
+		ID:    uuid.Must(uuid.NewV7()),
+		Data:  cacheHit{Key: "user:42", Size: 128},
+		Start: time.Now(),
+	}
+
+	listItem := renderToString(t, views.EventListItem(event, nil))
+	if !strings.Contains(listItem, "Cache") {
+		t.Errorf("expected list item to contain renderer name, got %s", listItem)
+	}
+	if !strings.Contains(listItem, "hit user:42") {
+		t.Errorf("expected list item to contain summary, got %s", listItem)
+	}
+
+	details := renderToString(t, views.EventDetails(event))
+	if !strings.Contains(details, "custom details for user:42") {
+		t.Errorf("expected custom details, got %s", details)
+	}
+}
+
+func TestEventDetails_UnregisteredType(t *testing.T) {
+	event := &collector.Event{
+		ID:    uuid.Must(uuid.NewV7()),
+		Data:  featureFlag{Name: "new-checkout", Enabled: true},
+		Start: time.Now(),
+	}
+
+	listItem := renderToString(t, views.EventListItem(event, nil))
+	if !strings.Contains(listItem, "featureFlag") {
+		t.Errorf("expected list item to contain type name, got %s", listItem)
+	}
+
+	details := renderToString(t, views.EventDetails(event))
+	for _, expected := range []string{"featureFlag", "Name", "new-checkout", "Enabled", "true"} {
+		if !strings.Contains(details, expected) {
+			t.Errorf("expected details to contain %q, got %s", expected, details)
+		}
+	}
+}
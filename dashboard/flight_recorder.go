@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// flightRecorder always captures the most recent events globally, independent of capture sessions.
+// Like a black box recorder, the events can be dumped into a new session when something went wrong.
+type flightRecorder struct {
+	storage    *collector.CaptureStorage
+	aggregator *collector.EventAggregator
+}
+
+// newFlightRecorder starts recording the most recent capacity top-level events of the aggregator
+func newFlightRecorder(aggregator *collector.EventAggregator, capacity uint64) *flightRecorder {
+	storage := collector.NewCaptureStorage(uuid.Nil, capacity, collector.CaptureModeGlobal)
+	storage.SetCaptureAmbient(true)
+	aggregator.RegisterStorage(storage)
+
+	return &flightRecorder{
+		storage:    storage,
+		aggregator: aggregator,
+	}
+}
+
+// Events returns the recorded top-level events that started at or after since, oldest first.
+// A zero since returns all recorded events.
+func (f *flightRecorder) Events(since time.Time) []*collector.Event {
+	var events []*collector.Event
+	for _, event := range f.storage.GetEvents(f.storage.Stats().Capacity) {
+		if !event.Start.Before(since) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// close stops recording
+func (f *flightRecorder) close() {
+	f.aggregator.UnregisterStorage(f.storage.ID())
+	f.storage.Close()
+}
@@ -0,0 +1,65 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestFlightRecorder_Events(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	recorder := newFlightRecorder(aggregator, 10)
+	defer recorder.close()
+
+	now := time.Now()
+	old := &collector.Event{ID: uuid.Must(uuid.NewV7()), Start: now.Add(-time.Minute)}
+	recent := &collector.Event{ID: uuid.Must(uuid.NewV7()), Start: now.Add(-time.Second)}
+	recorder.storage.Add(old)
+	recorder.storage.Add(recent)
+
+	if events := recorder.Events(time.Time{}); len(events) != 2 {
+		t.Errorf("expected all 2 events, got %d", len(events))
+	}
+	if events := recorder.Events(now.Add(-10 * time.Second)); len(events) != 1 || events[0] != recent {
+		t.Errorf("expected only the recent event, got %v", events)
+	}
+}
+
+func TestHandler_DumpFlightRecorder(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator, WithFlightRecorder(10))
+	defer handler.Close()
+
+	// Recorded without any capture session
+	aggregator.CollectEvent(context.Background(), "something went wrong")
+
+	sessionID := uuid.Must(uuid.NewV4())
+	form := url.Values{"last": {"1m"}}
+	req := httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/flight-recorder/dump", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var info SessionInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Capturing {
+		t.Error("expected dumped session not to capture")
+	}
+	if info.EventCount != 1 {
+		t.Errorf("expected 1 dumped event, got %d", info.EventCount)
+	}
+}
@@ -1,11 +1,17 @@
 package dashboard
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
-	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/a-h/templ"
@@ -14,6 +20,8 @@ import (
 	"github.com/networkteam/devlog/collector"
 	"github.com/networkteam/devlog/dashboard/static"
 	"github.com/networkteam/devlog/dashboard/views"
+	"github.com/networkteam/devlog/internal/insights"
+	"github.com/networkteam/devlog/internal/protodecode"
 )
 
 // DefaultStorageCapacity is the default number of events per storage
@@ -22,14 +30,31 @@ const DefaultStorageCapacity uint64 = 1000
 // DefaultSessionIdleTimeout is the default time before an inactive session is cleaned up
 const DefaultSessionIdleTimeout = 30 * time.Second
 
+// DefaultPinnedSessionMaxAge is the default time a pinned session is kept at most without a connected dashboard
+const DefaultPinnedSessionMaxAge = 24 * time.Hour
+
+// DefaultPreferencesRetention is the default time the preferences of a cleaned up session are kept for its recreation
+const DefaultPreferencesRetention = time.Hour
+
 type Handler struct {
 	sessions        *SessionManager
 	eventAggregator *collector.EventAggregator
 
+	dbPoolSampler  *collector.DBPoolSampler
+	flightRecorder *flightRecorder // nil if disabled
+	replayClient   *http.Client    // nil if replay is disabled
+	protobuf       *protodecode.Registry
+
 	pathPrefix    string
 	truncateAfter uint64
 
+	logger *slog.Logger
+
 	mux http.Handler
+
+	// streamsCtx is canceled to end open SSE streams
+	streamsCtx   context.Context
+	closeStreams context.CancelFunc
 }
 
 // NewHandler creates a new dashboard handler.
@@ -59,57 +84,155 @@ func NewHandler(eventAggregator *collector.EventAggregator, opts ...HandlerOptio
 		sessionIdleTimeout = DefaultSessionIdleTimeout
 	}
 
+	logger := options.Logger
+	if logger == nil {
+		logger = eventAggregator.Logger()
+	}
+
 	sessions := NewSessionManager(SessionManagerOptions{
-		EventAggregator: eventAggregator,
-		StorageCapacity: storageCapacity,
-		IdleTimeout:     sessionIdleTimeout,
-		MaxSessions:     options.MaxSessions,
+		EventAggregator:      eventAggregator,
+		StorageCapacity:      storageCapacity,
+		IdleTimeout:          sessionIdleTimeout,
+		PinnedMaxAge:         options.PinnedSessionMaxAge,
+		PreferencesRetention: options.PreferencesRetention,
+		MaxSessions:          options.MaxSessions,
+		ArchiveDir:           options.ArchiveDir,
+		ArchiveMaxBytes:      options.ArchiveMaxBytes,
+		Logger:               logger,
 	})
 
 	handler := &Handler{
 		sessions:        sessions,
 		eventAggregator: eventAggregator,
+		dbPoolSampler:   options.DBPoolSampler,
 		truncateAfter:   truncateAfter,
 		pathPrefix:      options.PathPrefix,
-		mux:             mux,
+		logger:          logger,
+		mux:             withCompression(mux),
+		protobuf:        protodecode.NewRegistry(),
+	}
+	handler.streamsCtx, handler.closeStreams = context.WithCancel(context.Background())
+	if options.FlightRecorderCapacity > 0 {
+		handler.flightRecorder = newFlightRecorder(eventAggregator, options.FlightRecorderCapacity)
+	}
+	if options.ReplayTransport != nil {
+		handler.replayClient = &http.Client{Transport: options.ReplayTransport, Timeout: replayTimeout}
+	}
+	for _, descriptorSet := range options.ProtobufDescriptorSets {
+		if _, err := handler.protobuf.AddFileDescriptorSet(descriptorSet); err != nil {
+			logger.Warn("Invalid protobuf descriptor set", "error", err)
+		}
 	}
 
 	// Static assets (no session required)
-	mux.Handle("GET /static/", http.StripPrefix("/static", http.FileServerFS(static.Assets)))
+	mux.Handle("GET /static/", http.StripPrefix("/static", static.Handler()))
 
 	// Global stats endpoint (no session required)
 	mux.HandleFunc("GET /stats", handler.getStats)
 
+	// Request bin of a session, accepts any method and path
+	mux.HandleFunc("/bin/{token}", handler.receiveBinRequest)
+	mux.HandleFunc("/bin/{token}/{path...}", handler.receiveBinRequest)
+
 	// Root redirect - creates new session and redirects
 	mux.HandleFunc("GET /{$}", handler.rootRedirect)
 
+	// Handoff link - resumes the embedded dashboard state of a session in another browser
+	mux.HandleFunc("GET /handoff/{token}", handler.openHandoff)
+
 	// Session-scoped routes under /s/{sid}/ (the /s/ prefix avoids conflicts with /static/)
 	mux.HandleFunc("GET /s/{sid}/{$}", handler.root)
-	mux.HandleFunc("GET /s/{sid}/event-list", handler.getEventList)
+	mux.HandleFunc("GET /s/{sid}/event-list", withETag(handler.getEventList))
 	mux.HandleFunc("DELETE /s/{sid}/event-list", handler.clearEventList)
-	mux.HandleFunc("GET /s/{sid}/event/{eventId}", handler.getEventDetails)
+	mux.HandleFunc("GET /s/{sid}/event-list/page", withETag(handler.getEventListPage))
+	mux.HandleFunc("GET /s/{sid}/event-list/jump", handler.jumpToTime)
+	mux.HandleFunc("GET /s/{sid}/event-list/archived", handler.getArchivedEventList)
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}", withETag(handler.getEventDetails))
+	mux.HandleFunc("DELETE /s/{sid}/event/{eventId}", handler.deleteEvent)
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/raw", withETag(handler.getRawEvent))
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/body-search", handler.searchEventBody)
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/hex", withETag(handler.getHexView))
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/protobuf", handler.getProtobufView)
+	mux.HandleFunc("POST /s/{sid}/event/{eventId}/protobuf", handler.uploadProtobufDescriptors)
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/cors", handler.getCORSDiagnostics)
+	mux.HandleFunc("GET /s/{sid}/event/{eventId}/replay", handler.getReplayEditor)
+	mux.HandleFunc("POST /s/{sid}/event/{eventId}/replay", handler.replayRequest)
+	mux.HandleFunc("POST /s/{sid}/event/{eventId}/replay/import", handler.importRequest)
 	mux.HandleFunc("GET /s/{sid}/events-sse", handler.getEventsSSE)
+	mux.HandleFunc("GET /s/{sid}/events-ws", handler.getEventsWebSocket)
+	mux.HandleFunc("POST /s/{sid}/display", handler.setDisplayPreferences)
+	mux.HandleFunc("POST /s/{sid}/error-alerts", handler.setErrorAlerts)
+	mux.HandleFunc("POST /s/{sid}/preferences", handler.setUIPreferences)
+	mux.HandleFunc("GET /s/{sid}/statistics", withETag(handler.getStatistics))
+	mux.HandleFunc("GET /s/{sid}/debug", handler.getDiagnostics)
+	mux.HandleFunc("GET /s/{sid}/notification-rules", handler.getNotificationRules)
+	mux.HandleFunc("POST /s/{sid}/notification-rules", handler.addNotificationRule)
+	mux.HandleFunc("DELETE /s/{sid}/notification-rules/{ruleId}", handler.removeNotificationRule)
+	mux.HandleFunc("GET /s/{sid}/sessions", handler.getSessions)
+	mux.HandleFunc("POST /s/{sid}/sessions", handler.createSession)
+	mux.HandleFunc("GET /s/{sid}/session-switcher", handler.getSessionSwitcher)
+	mux.HandleFunc("POST /s/{sid}/sessions/{targetSid}/name", handler.renameSession)
+	mux.HandleFunc("POST /s/{sid}/flight-recorder/dump", handler.dumpFlightRecorder)
+	mux.HandleFunc("POST /s/{sid}/sessions/import", handler.importSession)
+	mux.HandleFunc("POST /s/{sid}/sessions/{targetSid}/clear", handler.clearSession)
+	mux.HandleFunc("POST /s/{sid}/sessions/{targetSid}/pin", handler.pinSession)
+	mux.HandleFunc("POST /s/{sid}/keep-alive", handler.keepAlive)
+	mux.HandleFunc("POST /s/{sid}/handoff", handler.createHandoffLink)
+	mux.HandleFunc("DELETE /s/{sid}/sessions/{targetSid}", handler.terminateSession)
 	mux.HandleFunc("GET /s/{sid}/download/request-body/{eventId}", handler.downloadRequestBody)
 	mux.HandleFunc("GET /s/{sid}/download/response-body/{eventId}", handler.downloadResponseBody)
+	mux.HandleFunc("GET /s/{sid}/download/test/{eventId}", handler.downloadTest)
+	mux.HandleFunc("GET /s/{sid}/download/schema/{eventId}", handler.downloadSchema)
+	mux.HandleFunc("GET /s/{sid}/snippet/{snippet}/{eventId}", withETag(handler.getSnippet))
 
 	// Capture control endpoints
 	mux.HandleFunc("POST /s/{sid}/capture/start", handler.captureStart)
 	mux.HandleFunc("POST /s/{sid}/capture/stop", handler.captureStop)
 	mux.HandleFunc("POST /s/{sid}/capture/mode", handler.setCaptureMode)
+	mux.HandleFunc("POST /s/{sid}/capture/ambient", handler.setCaptureAmbient)
 	mux.HandleFunc("GET /s/{sid}/capture/status", handler.captureStatus)
+	mux.HandleFunc("GET /s/{sid}/capture/limit", handler.captureLimit)
 	mux.HandleFunc("POST /s/{sid}/capture/cleanup", handler.captureCleanup)
 
 	return handler
 }
 
 // withHandlerOptions is a helper to set HandlerOptions in context before rendering
-func (h *Handler) withHandlerOptions(r *http.Request, sessionID string, captureActive bool, captureMode string) *http.Request {
+func (h *Handler) withHandlerOptions(r *http.Request, sessionID string, captureActive bool, captureMode string, captureAmbient bool) *http.Request {
+	var (
+		prefs    SessionPreferences
+		pinned   bool
+		binToken string
+	)
+	if sid, err := uuid.FromString(sessionID); err == nil {
+		prefs = h.sessions.Preferences(sid)
+		pinned = h.sessions.Pinned(sid)
+		binToken = h.sessions.BinToken(sid)
+	}
 	ctx := views.WithHandlerOptions(r.Context(), views.HandlerOptions{
-		PathPrefix:    h.pathPrefix,
-		TruncateAfter: h.truncateAfter,
-		SessionID:     sessionID,
-		CaptureActive: captureActive,
-		CaptureMode:   captureMode,
+		PathPrefix:     h.pathPrefix,
+		TruncateAfter:  h.truncateAfter,
+		PageSize:       eventListPageSize,
+		SessionID:      sessionID,
+		CaptureActive:  captureActive,
+		CaptureMode:    captureMode,
+		CaptureAmbient: captureAmbient,
+		TagFilter:      r.URL.Query().Get("tag"),
+		TenantFilter:   r.URL.Query().Get("tenant"),
+		LastFilter:     r.URL.Query().Get("last"),
+		SinceFilter:    r.URL.Query().Get("since"),
+		UntilFilter:    r.URL.Query().Get("until"),
+		SeverityFilter: r.URL.Query().Get("severity"),
+		SortOrder:      r.URL.Query().Get("sort"),
+		AbsoluteTimes:  prefs.Display.AbsoluteTimes,
+		TimeZone:       prefs.Display.TimeZone,
+		ErrorAlerts:    prefs.ErrorAlerts,
+		UIPreferences:  prefs.UI,
+		FlightRecorder: h.flightRecorder != nil,
+		Replay:         h.replayClient != nil,
+		SessionPinned:  pinned,
+		IdleTimeout:    h.sessions.IdleTimeout(),
+		BinToken:       binToken,
 	})
 	return r.WithContext(ctx)
 }
@@ -118,8 +241,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.mux.ServeHTTP(w, r)
 }
 
-// Close shuts down the handler and releases resources
+// CloseStreams ends the open SSE streams of dashboards and rejects new ones, e.g. so http.Server.Shutdown does not
+// wait for them. Sessions keep capturing until Close is called.
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
+}
+
+// Close shuts down the handler and releases resources, it is safe to call Close multiple times
 func (h *Handler) Close() {
+	h.closeStreams()
+	if h.flightRecorder != nil {
+		h.flightRecorder.close()
+	}
 	h.sessions.Close()
 }
 
@@ -136,6 +269,9 @@ func (h *Handler) getSessionID(r *http.Request) (uuid.UUID, bool) {
 	return sessionID, true
 }
 
+// browserCookieName is the name of the cookie that identifies the browser of a dashboard user
+const browserCookieName = "devlog_browser"
+
 // setSessionCookie sets the session cookie for event filtering
 func (h *Handler) setSessionCookie(w http.ResponseWriter, sessionID uuid.UUID) {
 	http.SetCookie(w, &http.Cookie{
@@ -147,6 +283,36 @@ func (h *Handler) setSessionCookie(w http.ResponseWriter, sessionID uuid.UUID) {
 	})
 }
 
+// browserID returns the ID of the browser from its cookie, setting a new one if it has none.
+// Sessions started by the browser are assigned to the ID, so it can switch between them.
+func (h *Handler) browserID(w http.ResponseWriter, r *http.Request) uuid.UUID {
+	if id, ok := readBrowserID(r); ok {
+		return id
+	}
+	id := uuid.Must(uuid.NewV4())
+	http.SetCookie(w, &http.Cookie{
+		Name:     browserCookieName,
+		Value:    id.String(),
+		Path:     h.pathPrefix + "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// readBrowserID reads the ID of the browser from its cookie
+func readBrowserID(r *http.Request) (uuid.UUID, bool) {
+	cookie, err := r.Cookie(browserCookieName)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(cookie.Value)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // clearSessionCookie clears the session cookie
 func (h *Handler) clearSessionCookie(w http.ResponseWriter, sessionID uuid.UUID) {
 	http.SetCookie(w, &http.Cookie{
@@ -181,18 +347,31 @@ func (h *Handler) root(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Recreate session from URL params if needed
+	var captureError string
 	if storage == nil && captureParam == "true" {
 		mode := collector.ParseCaptureModeOrDefault(modeParam)
 		var created bool
 		var err error
 		storage, created, err = h.sessions.GetOrCreate(sessionID, mode)
-		if err != nil {
+		if errors.Is(err, ErrMaxSessionsReached) {
+			// Render the dashboard without capturing so the user sees why capture is not active
+			captureError = h.captureErrorMessage(err)
+		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusServiceUnavailable)
 			return
 		}
 		if created && mode == collector.CaptureModeSession {
 			h.setSessionCookie(w, sessionID)
 		}
+		if created {
+			h.sessions.SetBrowser(sessionID, h.browserID(w, r))
+			storage.SetCaptureAmbient(r.URL.Query().Get("ambient") == "true")
+		}
+	}
+
+	// Re-set the session cookie that was cleared when leaving the dashboard of the session
+	if storage != nil && storage.CaptureMode() == collector.CaptureModeSession {
+		h.setSessionCookie(w, sessionID)
 	}
 
 	var selectedEvent *collector.Event
@@ -211,108 +390,1558 @@ func (h *Handler) root(w http.ResponseWriter, r *http.Request) {
 		selectedEvent = event
 	}
 
-	var recentEvents []*collector.Event
+	var eventList views.EventListProps
 	captureActive := false
 	captureMode := modeParam
+	captureAmbient := false
 	if storage != nil {
 		h.sessions.UpdateActivity(sessionID)
-		recentEvents = h.loadRecentEvents(storage)
+		// An invalid filter or order in a bookmarked URL shows all events newest first
+		filter, _ := parseEventFilter(r.URL.Query())
+		order, _ := collector.ParseEventOrder(r.URL.Query().Get("sort"))
+		var selectedEventID *uuid.UUID
+		if selectedEvent != nil {
+			selectedEventID = &selectedEvent.ID
+		}
+		eventList = newEventListProps(h.loadRecentEvents(storage, filter, order), selectedEventID, storage.CaptureAmbient())
+		eventList.ArchivedEvents = archivedEventCount(storage)
 		captureActive = true
 		captureMode = storage.CaptureMode().String()
+		captureAmbient = storage.CaptureAmbient()
 		if storage.CaptureMode() == collector.CaptureModeSession {
 			// Re-set session cookie for session mode (cleared on beforeunload)
 			h.setSessionCookie(w, sessionID)
 		}
 	}
 
-	r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode)
-	templ.Handler(
-		views.Dashboard(views.DashboardProps{
-			SelectedEvent: selectedEvent,
-			Events:        recentEvents,
-			CaptureActive: captureActive,
-			CaptureMode:   captureMode,
-		}),
-	).ServeHTTP(w, r)
-}
-
-func (h *Handler) getEventList(w http.ResponseWriter, r *http.Request) {
-	sessionID, _ := h.getSessionID(r)
-	storage := h.sessions.Get(sessionID)
+	r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode, captureAmbient)
+	templ.Handler(
+		views.Dashboard(views.DashboardProps{
+			SelectedEvent:  selectedEvent,
+			Events:         eventList.Events,
+			Offset:         eventList.Offset,
+			TotalEvents:    eventList.TotalEvents,
+			ArchivedEvents: eventList.ArchivedEvents,
+			AmbientEvents:  eventList.AmbientEvents,
+			CaptureActive:  captureActive,
+			CaptureMode:    captureMode,
+			CaptureAmbient: captureAmbient,
+			CaptureError:   captureError,
+		}),
+	).ServeHTTP(w, r)
+}
+
+func (h *Handler) getEventList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	order, err := collector.ParseEventOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+
+	selectedStr := r.URL.Query().Get("selected")
+	var selectedEventID *uuid.UUID
+	if selectedStr != "" {
+		eventID, err := uuid.FromString(selectedStr)
+		if err == nil {
+			selectedEventID = &eventID
+		}
+	}
+
+	eventList := views.EventListProps{
+		SelectedEventID: selectedEventID,
+		CaptureMode:     "session",
+	}
+	if storage != nil {
+		eventList = newEventListProps(h.loadRecentEvents(storage, filter, order), selectedEventID, storage.CaptureAmbient())
+		eventList.ArchivedEvents = archivedEventCount(storage)
+		eventList.CaptureActive = true
+		eventList.CaptureMode = storage.CaptureMode().String()
+	}
+
+	r = h.withHandlerOptions(r, sessionID.String(), eventList.CaptureActive, eventList.CaptureMode, eventList.CaptureAmbient)
+
+	templ.Handler(views.EventList(eventList)).ServeHTTP(w, r)
+}
+
+// getEventListPage renders the window of the filtered event list at the index given as "offset", it is fetched when
+// scrolling to events outside of the rendered window. The event given as "selected" is marked.
+func (h *Handler) getEventListPage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	order, err := collector.ParseEventOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+	var selectedEventID *uuid.UUID
+	if eventID, err := uuid.FromString(r.URL.Query().Get("selected")); err == nil {
+		selectedEventID = &eventID
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	events := h.loadRecentEvents(storage, filter, order)
+	// Ambient events are not part of the windowed list (see newEventListProps)
+	if storage.CaptureAmbient() {
+		events, _ = splitAmbientEvents(events)
+	}
+	window, offset := eventListWindowAt(events, offset)
+
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+	templ.Handler(
+		views.EventListPage(views.EventListPageProps{
+			SelectedEventID: selectedEventID,
+			Events:          window,
+			Offset:          offset,
+			TotalEvents:     len(events),
+			ArchivedEvents:  archivedEventCount(storage),
+		}),
+	).ServeHTTP(w, r)
+}
+
+// getArchivedEventList renders the filtered events of the newest archive segment before the segment given as "before"
+// (the newest segment without it). Archived events are read from disk, so they are loaded on demand after the last
+// window of the event list.
+func (h *Handler) getArchivedEventList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	before := uint64(math.MaxUint64)
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		before, err = strconv.ParseUint(beforeStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid segment", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+	archive := storage.Archive()
+	if archive == nil {
+		http.Error(w, "Event archive is disabled", http.StatusNotFound)
+		return
+	}
+
+	segment, remaining, ok := archiveSegmentBefore(archive.Segments(), before)
+	var events []*collector.Event
+	if ok {
+		events, err = archive.ReadSegment(segment.ID)
+		// The segment was deleted since it was listed, the older segments are still offered
+		if err != nil && !errors.Is(err, collector.ErrArchiveSegmentNotFound) {
+			h.logger.Warn("Failed to read archive segment", "session", sessionID, "segment", segment.ID, "error", err)
+			http.Error(w, "Failed to read archived events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+	templ.Handler(
+		views.ArchivedEventListPage(views.ArchivedEventListPageProps{
+			Events:    filterArchivedEvents(events, filter),
+			SegmentID: segment.ID,
+			Remaining: remaining,
+		}),
+	).ServeHTTP(w, r)
+}
+
+// jumpToTime finds the first event of the filtered event list started at or after the time given as "at" (RFC 3339)
+func (h *Handler) jumpToTime(w http.ResponseWriter, r *http.Request) {
+	at, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("at"))
+	if err != nil {
+		http.Error(w, "Invalid time", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	event := firstEventAfter(h.loadRecentEvents(storage, filter, collector.EventOrderNewest), at)
+
+	if r.Header.Get("HX-Request") == "true" {
+		templ.Handler(views.JumpResult(event)).ServeHTTP(w, r)
+		return
+	}
+
+	if event == nil {
+		http.Error(w, "No events after this time", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"eventId": event.ID.String()}); err != nil {
+		h.logger.Error("Failed to encode jump result", "error", err)
+	}
+}
+
+// clearEventList handles DELETE /event-list, with filter query parameters only the matching events are removed
+func (h *Handler) clearEventList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	order, err := collector.ParseEventOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	var remainingEvents []*collector.Event
+	if storage != nil {
+		if filter.isEmpty() {
+			storage.Clear()
+		} else {
+			storage.RemoveWhere(filter.matches)
+			// Show the remaining events with the tag and time range of the list
+			listFilter := filter
+			listFilter.kind, listFilter.path = "", ""
+			remainingEvents = h.loadRecentEvents(storage, listFilter, order)
+		}
+	}
+
+	// Keep capture active if storage exists
+	eventList := views.EventListProps{CaptureMode: "session"}
+	if storage != nil {
+		eventList = newEventListProps(remainingEvents, nil, storage.CaptureAmbient())
+		eventList.ArchivedEvents = archivedEventCount(storage)
+		eventList.CaptureActive = true
+		eventList.CaptureMode = storage.CaptureMode().String()
+	}
+
+	r = h.withHandlerOptions(r, sessionID.String(), eventList.CaptureActive, eventList.CaptureMode, eventList.CaptureAmbient)
+	opts := views.MustGetHandlerOptions(r.Context())
+
+	// Update URL to remove id parameter but preserve capture state
+	w.Header().Set("HX-Push-Url", opts.BuildEventDetailURL(""))
+
+	templ.Handler(
+		views.SplitLayout(views.EventList(eventList), views.EventDetailContainer(nil)),
+	).ServeHTTP(w, r)
+}
+
+func (h *Handler) getEventDetails(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	captureMode := storage.CaptureMode().String()
+	r = h.withHandlerOptions(r, sessionID.String(), true, captureMode, storage.CaptureAmbient())
+
+	idStr := r.PathValue("eventId")
+	eventID, err := uuid.FromString(idStr)
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	templ.Handler(
+		views.EventDetailContainer(event),
+	).ServeHTTP(w, r)
+}
+
+// deleteEvent removes a top-level event (e.g. a noisy or sensitive request) from the storage of the session
+func (h *Handler) deleteEvent(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	if !storage.Remove(eventID) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+	opts := views.MustGetHandlerOptions(r.Context())
+	w.Header().Set("HX-Push-Url", opts.BuildEventDetailURL(""))
+
+	templ.Handler(
+		views.EventDetailContainer(nil),
+	).ServeHTTP(w, r)
+}
+
+// setDisplayPreferences stores how timestamps are displayed for a session and reloads the dashboard for HTMX.
+// The form value "times" is "relative" or "absolute", "tz" is an IANA time zone name (empty for the time zone of the server).
+func (h *Handler) setDisplayPreferences(w http.ResponseWriter, r *http.Request) {
+	sessionID, hasSession := h.getSessionID(r)
+	if !hasSession {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	var prefs DisplayPreferences
+	switch times := r.FormValue("times"); times {
+	case "", "relative":
+	case "absolute":
+		prefs.AbsoluteTimes = true
+	default:
+		http.Error(w, fmt.Sprintf("Invalid times: %q", times), http.StatusBadRequest)
+		return
+	}
+	if tz := r.FormValue("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid time zone: %v", err), http.StatusBadRequest)
+			return
+		}
+		prefs.TimeZone = loc
+	}
+
+	if !h.sessions.SetDisplayPreferences(sessionID, prefs) {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	// Timestamps are rendered on the server, so the whole dashboard has to be rendered again
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setErrorAlerts handles POST /error-alerts - enables or disables alerts about errors with the form value "enabled" ("true")
+func (h *Handler) setErrorAlerts(w http.ResponseWriter, r *http.Request) {
+	sessionID, hasSession := h.getSessionID(r)
+	if !hasSession {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	enabled := r.FormValue("enabled") == "true"
+	if !h.sessions.SetErrorAlerts(sessionID, enabled) {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		r = h.withHandlerOptions(r, sessionID.String(), true, "", false)
+		templ.Handler(views.ErrorAlertsToggle()).ServeHTTP(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxUIPreferencesSize limits the size of the UI state stored for a session
+const maxUIPreferencesSize = 4096
+
+// setUIPreferences handles POST /preferences - stores the UI state of the dashboard (the form value "ui", a JSON
+// object), so it is restored when the dashboard is rendered again
+func (h *Handler) setUIPreferences(w http.ResponseWriter, r *http.Request) {
+	sessionID, hasSession := h.getSessionID(r)
+	if !hasSession {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	ui := r.FormValue("ui")
+	if len(ui) > maxUIPreferencesSize {
+		http.Error(w, fmt.Sprintf("Preferences exceed %d bytes", maxUIPreferencesSize), http.StatusBadRequest)
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(ui), &fields); err != nil || fields == nil {
+		http.Error(w, "Preferences must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.SetUIPreferences(sessionID, ui) {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getRawEvent renders the event serialized as JSON as HTML for HTMX or the JSON itself for API
+func (h *Handler) getRawEvent(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	rawJSON, err := views.RawEventJSON(event)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to serialize event: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+		templ.Handler(
+			views.RawEventDetailContainer(event, string(rawJSON)),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(rawJSON)
+}
+
+// getEventsSSE handles SSE connections for real-time log updates
+func (h *Handler) getEventsSSE(w http.ResponseWriter, r *http.Request) {
+	r, sessionID, storage, filter, ok := h.prepareEventStream(w, r)
+	if !ok {
+		return
+	}
+
+	// Set SSE headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // For NGINX proxy
+
+	h.streamEvents(r.Context(), sessionID, storage, filter, lastEventID(r), func(event string, id string, data []byte) error {
+		if id != "" {
+			fmt.Fprintf(w, "id: %s\n", id)
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		w.(http.Flusher).Flush()
+		return nil
+	})
+}
+
+// lastEventID returns the ID of the last event the client received before it reconnected, missed events are replayed.
+// The browser sends it in the Last-Event-ID header when it reconnects an SSE stream, the dashboard sends it as
+// "lastEventId" query parameter when it opens a new stream (e.g. over WebSocket).
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// streamEventID returns the ID of an event sent by streamEvents, it is the revision of the storage the event was added
+// at (see collector.CaptureStorage.ChangesSince)
+func streamEventID(storage *collector.CaptureStorage, revision uint64) string {
+	return fmt.Sprintf("%s:%d", storage.ID(), revision)
+}
+
+// parseStreamEventID returns the revision of an ID created by streamEventID. It returns false if the ID is invalid or
+// of another storage, e.g. because the session was recreated.
+func parseStreamEventID(storage *collector.CaptureStorage, id string) (uint64, bool) {
+	storageID, revision, ok := strings.Cut(id, ":")
+	if !ok || storageID != storage.ID().String() {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(revision, 10, 64)
+	if err != nil || r > storage.Revision() {
+		return 0, false
+	}
+	return r, true
+}
+
+// prepareEventStream checks the request for an event stream and returns the storage of the session,
+// recreating it if it was cleaned up. It responds with an error and returns false if the stream cannot be opened.
+func (h *Handler) prepareEventStream(w http.ResponseWriter, r *http.Request) (_ *http.Request, sessionID uuid.UUID, storage *collector.CaptureStorage, filter eventFilter, ok bool) {
+	sessionID, hasSession := h.getSessionID(r)
+	if !hasSession {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return r, sessionID, nil, filter, false
+	}
+
+	if h.streamsCtx.Err() != nil {
+		http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+		return r, sessionID, nil, filter, false
+	}
+
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return r, sessionID, nil, filter, false
+	}
+
+	storage = h.sessions.Get(sessionID)
+	if storage == nil {
+		// Session was cleaned up - recreate it (fresh and empty)
+		// Use mode from query param, default to session mode
+		mode := collector.ParseCaptureModeOrDefault(r.URL.Query().Get("mode"))
+
+		var created bool
+		storage, created, err = h.sessions.GetOrCreate(sessionID, mode)
+		if err != nil {
+			http.Error(w, err.Error(), sessionErrorStatus(err))
+			return r, sessionID, nil, filter, false
+		}
+
+		// Set cookie if session mode and newly created
+		if created && mode == collector.CaptureModeSession {
+			h.setSessionCookie(w, sessionID)
+		}
+		if created {
+			storage.SetCaptureAmbient(r.URL.Query().Get("ambient") == "true")
+		}
+	}
+
+	// Set handler options in context for template rendering
+	captureMode := storage.CaptureMode().String()
+	r = h.withHandlerOptions(r, sessionID.String(), true, captureMode, storage.CaptureAmbient())
+
+	return r, sessionID, storage, filter, true
+}
+
+// streamEvents sends new events of the storage and notifications of the session with send until ctx is done,
+// the streams of the handler are closed or send fails. It is shared by the SSE and WebSocket transports.
+// Events are rendered with the handler options of ctx (see prepareEventStream). Events are sent with an ID (see
+// streamEventID), so the client can resume from the last one: if lastEventID is set, the events added or updated after
+// it are sent first. A "resync" event tells the client to load the events again if it can't be resumed from.
+func (h *Handler) streamEvents(ctx context.Context, sessionID uuid.UUID, storage *collector.CaptureStorage, filter eventFilter, lastEventID string, send func(event string, id string, data []byte) error) {
+	// Update activity for this session
+	h.sessions.UpdateActivity(sessionID)
+	defer h.sessions.TrackSSEConnection(sessionID)()
+
+	h.logger.Debug("Dashboard connected", "session", sessionID)
+	defer h.logger.Debug("Dashboard disconnected", "session", sessionID)
+
+	// Create a context that gets canceled when the connection is closed or the streams are closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(h.streamsCtx, cancel)()
+
+	// Create a notification channel for new events from the user's storage
+	eventCh := storage.SubscribeChanges(ctx)
+	// Browser notifications of matching notification rules
+	notificationCh := h.sessions.SubscribeNotifications(ctx, sessionID)
+
+	// Send a keep-alive message initially to ensure the connection is established
+	if send("keepalive", "", []byte("connected")) != nil {
+		return
+	}
+
+	// Events that were sent in progress, newer previews and the completed event are sent as updates
+	sentInProgress := make(map[uuid.UUID]struct{})
+
+	// Revision of the last event that was sent, events notified while replaying might have been sent already
+	var sentRevision uint64
+
+	// sendEvent sends an event as list item, it returns false if the stream should end. A child update only replaces
+	// the list item if it is shown, since the event might be older than the rendered events.
+	sendEvent := func(change collector.StoredEvent, childUpdate bool) bool {
+		event := change.Event
+		sentRevision = change.Revision
+		streamEvent := "new-event"
+		if _, ok := sentInProgress[event.ID]; ok {
+			streamEvent = "update-event"
+		} else if childUpdate {
+			streamEvent = "child-update"
+		}
+		if event.InProgress {
+			sentInProgress[event.ID] = struct{}{}
+		} else {
+			delete(sentInProgress, event.ID)
+		}
+
+		var item templ.Component
+		if event.Ambient && storage.CaptureAmbient() {
+			item = views.AmbientEventListItem(event)
+		} else {
+			item = views.EventListItem(event, nil)
+		}
+		html, ok := h.renderSafely(ctx, item)
+		if !ok {
+			return true
+		}
+		return send(streamEvent, streamEventID(storage, change.Revision), html) == nil
+	}
+
+	// Replay the events the client missed while it was disconnected (e.g. during sleep or a network change). The events
+	// are replayed in the order they were added or updated, so events that completed after the last received event are
+	// sent although they started before it.
+	if lastEventID != "" {
+		revision, ok := parseStreamEventID(storage, lastEventID)
+		if !ok {
+			// The session was recreated, the missed events can't be determined
+			if send("resync", "", []byte(lastEventID)) != nil {
+				return
+			}
+		}
+		var missed []collector.StoredEvent
+		if ok {
+			sentRevision = revision
+			// Events the list doesn't show because of the time filter or the truncation are not replayed
+			missed = storage.ChangesSince(revision, collector.ReadOptions{Limit: h.truncateAfter, Since: filter.since})
+		}
+		for _, change := range missed {
+			if !filter.matches(change.Event) {
+				sentRevision = change.Revision
+				continue
+			}
+			if !sendEvent(change, false) {
+				return
+			}
+		}
+	}
+
+	// Create a ticker to keep the session alive and send keepalive messages
+	// This prevents idle timeout while SSE connection is open
+	keepaliveTicker := time.NewTicker(h.sessions.IdleTimeout() / 2)
+	defer keepaliveTicker.Stop()
+
+	// Listen for new events and send them
+	for {
+		select {
+		case <-ctx.Done():
+			return // Client disconnected
+		case <-keepaliveTicker.C:
+			// Keep session alive while SSE is connected
+			h.sessions.UpdateActivity(sessionID)
+			// Send keepalive to client
+			if send("keepalive", "", []byte("ping")) != nil {
+				return
+			}
+		case notification, ok := <-notificationCh:
+			if !ok {
+				notificationCh = nil
+				continue
+			}
+			data, err := json.Marshal(notification)
+			if err != nil {
+				h.logger.Error("Failed to encode notification", "session", sessionID, "error", err)
+				continue
+			}
+			if send("notification", "", data) != nil {
+				return
+			}
+		case change, ok := <-eventCh:
+			if !ok {
+				return // Channel closed
+			}
+			// The event was replayed already, it was added after subscribing but before replaying
+			if change.Revision <= sentRevision {
+				continue
+			}
+			event := change.Event
+
+			// Update activity on each event
+			h.sessions.UpdateActivity(sessionID)
+
+			// Errors are alerted regardless of the filter of the list, once per revision. Replayed events are not
+			// alerted, they happened while the dashboard was disconnected and are shown in the list.
+			if h.sessions.ErrorAlerts(sessionID) {
+				if alert, ok := errorAlert(event); ok {
+					if data, err := json.Marshal(alert); err == nil {
+						if send("error-event", "", data) != nil {
+							return
+						}
+					} else {
+						h.logger.Error("Failed to encode error alert", "session", sessionID, "error", err)
+					}
+				}
+			}
+
+			if !filter.matches(event) {
+				sentRevision = change.Revision
+				continue
+			}
+
+			// Send as event, updates replace the list item of the event if it exists
+			if !sendEvent(change, event.LateChildren) {
+				return
+			}
+		}
+	}
+}
+
+// renderSafely renders a component, a panic (e.g. in a registered event renderer) is recovered and recorded
+// as an internal error instead of ending the SSE stream
+func (h *Handler) renderSafely(ctx context.Context, component templ.Component) (html []byte, ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			h.eventAggregator.RecordPanic(context.Background(), "dashboard_renderer", v)
+			html, ok = nil, false
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		if ctx.Err() == nil {
+			h.logger.Error("Failed to render event", "error", err)
+		}
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// getNotificationRules renders the notification rules of the session as HTML for HTMX or JSON for API
+func (h *Handler) getNotificationRules(w http.ResponseWriter, r *http.Request) {
+	h.respondWithNotificationRules(w, r, "", http.StatusOK)
+}
+
+// addNotificationRule handles POST /notification-rules - adds a rule from the form values "name", "minStatus", "path",
+// "minLevel" (e.g. "ERROR"), "browser" ("true") and "webhookUrl"
+func (h *Handler) addNotificationRule(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule, err := parseNotificationRule(r.PostForm)
+	if err != nil {
+		if r.Header.Get("HX-Request") == "true" {
+			h.respondWithNotificationRules(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.AddNotificationRule(sessionID, rule) {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.respondWithNotificationRules(w, r, "", http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// removeNotificationRule handles DELETE /notification-rules/{ruleId}
+func (h *Handler) removeNotificationRule(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+
+	ruleID, err := uuid.FromString(r.PathValue("ruleId"))
+	if err != nil {
+		http.Error(w, "Invalid rule id", http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.RemoveNotificationRule(sessionID, ruleID) {
+		http.Error(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.respondWithNotificationRules(w, r, "", http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithNotificationRules renders the notification rules of the session with an optional error of the rule form
+func (h *Handler) respondWithNotificationRules(w http.ResponseWriter, r *http.Request, formError string, status int) {
+	sessionID, _ := h.getSessionID(r)
+	rules, _ := h.sessions.NotificationRules(sessionID)
+
+	if r.Header.Get("HX-Request") == "true" {
+		storage := h.sessions.Get(sessionID)
+		captureActive := false
+		captureMode := "session"
+		captureAmbient := false
+		if storage != nil {
+			captureActive = true
+			captureMode = storage.CaptureMode().String()
+			captureAmbient = storage.CaptureAmbient()
+		}
+
+		ruleViews := make([]views.NotificationRule, len(rules))
+		for i, rule := range rules {
+			ruleViews[i] = views.NotificationRule(rule)
+		}
+
+		r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode, captureAmbient)
+		templ.Handler(
+			views.NotificationRulesContainer(ruleViews, formError),
+			templ.WithStatus(status),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	if rules == nil {
+		rules = []NotificationRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// getStatistics renders aggregated per-endpoint statistics over the session's events as HTML for HTMX or JSON for API
+func (h *Handler) getStatistics(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+
+	var stats collector.EventStats
+	captureActive := false
+	captureMode := "session"
+	captureAmbient := false
+	if storage != nil {
+		// Statistics cover all events of the storage, not only the events rendered in the list
+		stats = collector.CalculateEventStats(storage.GetEvents(storage.Stats().Capacity))
+		captureActive = true
+		captureMode = storage.CaptureMode().String()
+		captureAmbient = storage.CaptureAmbient()
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode, captureAmbient)
+		templ.Handler(
+			views.StatisticsContainer(stats),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	// JSON response for API
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// getSessions handles GET /sessions - lists all active capture sessions
+func (h *Handler) getSessions(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+
+	sessions := h.sessions.Sessions()
+
+	if r.Header.Get("HX-Request") == "true" {
+		storage := h.sessions.Get(sessionID)
+		captureActive := false
+		captureMode := "session"
+		captureAmbient := false
+		if storage != nil {
+			captureActive = true
+			captureMode = storage.CaptureMode().String()
+			captureAmbient = storage.CaptureAmbient()
+		}
+
+		sessionInfos := make([]views.SessionInfo, len(sessions))
+		for i, info := range sessions {
+			sessionInfos[i] = views.SessionInfo(info)
+		}
+
+		r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode, captureAmbient)
+		templ.Handler(
+			views.SessionsContainer(sessionInfos, requestBaseURL(r)),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	// JSON response for API
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// createSession handles POST /sessions - starts capturing in a new named session of the browser.
+// HTMX requests are redirected to the dashboard of the new session, API requests get its info as JSON.
+func (h *Handler) createSession(w http.ResponseWriter, r *http.Request) {
+	name, err := sessionName(r.FormValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mode := collector.ParseCaptureModeOrDefault(r.FormValue("mode"))
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if _, _, err := h.sessions.GetOrCreate(sessionID, mode); err != nil {
+		http.Error(w, h.captureErrorMessage(err), sessionErrorStatus(err))
+		return
+	}
+	h.sessions.SetName(sessionID, name)
+	h.sessions.SetBrowser(sessionID, h.browserID(w, r))
+	if mode == collector.CaptureModeSession {
+		h.setSessionCookie(w, sessionID)
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		opts := views.HandlerOptions{
+			PathPrefix:    h.pathPrefix,
+			SessionID:     sessionID.String(),
+			CaptureActive: true,
+			CaptureMode:   mode.String(),
+		}
+		w.Header().Set("HX-Redirect", opts.BuildEventDetailURL(""))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	info, _ := h.sessions.Info(sessionID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+// dumpFlightRecorder handles POST /flight-recorder/dump - copies the events of the flight recorder into a new session
+// that is not capturing. The form value "last" (e.g. "30s") limits the events to the ones started in this duration.
+func (h *Handler) dumpFlightRecorder(w http.ResponseWriter, r *http.Request) {
+	if h.flightRecorder == nil {
+		http.Error(w, "Flight recorder is not enabled", http.StatusNotFound)
+		return
+	}
+
+	var since time.Time
+	if last := r.FormValue("last"); last != "" {
+		d, err := time.ParseDuration(last)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid duration: %q", last), http.StatusBadRequest)
+			return
+		}
+		since = time.Now().Add(-d)
+	}
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := h.sessions.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	if err != nil {
+		http.Error(w, h.captureErrorMessage(err), sessionErrorStatus(err))
+		return
+	}
+	storage.SetCapturing(false)
+	for _, event := range h.flightRecorder.Events(since) {
+		storage.Add(event)
+	}
+	h.sessions.SetName(sessionID, "Flight recorder "+time.Now().Format("15:04:05"))
+	h.sessions.SetBrowser(sessionID, h.browserID(w, r))
+
+	if r.Header.Get("HX-Request") == "true" {
+		opts := views.HandlerOptions{
+			PathPrefix:    h.pathPrefix,
+			SessionID:     sessionID.String(),
+			CaptureActive: true,
+			CaptureMode:   collector.CaptureModeGlobal.String(),
+		}
+		w.Header().Set("HX-Redirect", opts.BuildEventDetailURL(""))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	info, _ := h.sessions.Info(sessionID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+// importSession handles POST /sessions/import - loads events exported as newline delimited JSON (see
+// collector.JSONEventCodec) or a HAR file into a new read-only session for offline analysis. The file is given as form
+// file "file" or as request body, the form value "name" names the session (default: the file name).
+func (h *Handler) importSession(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSessionImportSize)
+
+	var (
+		reader   io.Reader = r.Body
+		filename string
+	)
+	if file, header, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		reader, filename = file, header.Filename
+	} else if !errors.Is(err, http.ErrNotMultipart) {
+		http.Error(w, fmt.Sprintf("Failed to read import file: %v", err), importErrorStatus(err))
+		return
+	}
+	name, err := sessionName(r.FormValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if name == "" {
+		name = importSessionName(filename)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read import file: %v", err), importErrorStatus(err))
+		return
+	}
+	events, err := decodeSessionImport(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid import file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := h.sessions.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	if err != nil {
+		http.Error(w, h.captureErrorMessage(err), sessionErrorStatus(err))
+		return
+	}
+	storage.SetCapturing(false)
+	h.sessions.SetReadOnly(sessionID, true)
+	for _, event := range events {
+		storage.Add(event)
+	}
+	h.sessions.SetName(sessionID, name)
+	h.sessions.SetBrowser(sessionID, h.browserID(w, r))
+
+	if r.Header.Get("HX-Request") == "true" {
+		opts := views.HandlerOptions{
+			PathPrefix:    h.pathPrefix,
+			SessionID:     sessionID.String(),
+			CaptureActive: true,
+			CaptureMode:   collector.CaptureModeGlobal.String(),
+		}
+		w.Header().Set("HX-Redirect", opts.BuildEventDetailURL(""))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	info, _ := h.sessions.Info(sessionID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+// renameSession handles POST /sessions/{targetSid}/name - names or renames a session
+func (h *Handler) renameSession(w http.ResponseWriter, r *http.Request) {
+	targetSessionID, err := uuid.FromString(r.PathValue("targetSid"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+	name, err := sessionName(r.FormValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.SetName(targetSessionID, name) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.getSessionSwitcher(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxSessionNameLength limits the length of session names in bytes
+const maxSessionNameLength = 64
+
+// sessionName validates a session name, surrounding whitespace is removed
+func sessionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) > maxSessionNameLength {
+		return "", fmt.Errorf("session name must not be longer than %d bytes", maxSessionNameLength)
+	}
+	return name, nil
+}
+
+// getSessionSwitcher renders the sessions of the browser to switch between them
+func (h *Handler) getSessionSwitcher(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	browserID, _ := readBrowserID(r)
+
+	sessions := h.sessions.BrowserSessions(browserID)
+	sessionInfos := make([]views.SessionInfo, len(sessions))
+	for i, info := range sessions {
+		sessionInfos[i] = views.SessionInfo(info)
+	}
+
+	captureMode := "session"
+	if storage := h.sessions.Get(sessionID); storage != nil {
+		captureMode = storage.CaptureMode().String()
+	}
+
+	r = h.withHandlerOptions(r, sessionID.String(), false, captureMode, false)
+	templ.Handler(
+		views.SessionSwitcher(sessionInfos),
+	).ServeHTTP(w, r)
+}
+
+// requestBaseURL returns the scheme and host of the application as seen by the client
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// clearSession handles POST /sessions/{targetSid}/clear - removes all events of another session
+func (h *Handler) clearSession(w http.ResponseWriter, r *http.Request) {
+	targetSessionID, err := uuid.FromString(r.PathValue("targetSid"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.Clear(targetSessionID) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	h.getSessions(w, r)
+}
+
+// pinSession handles POST /sessions/{targetSid}/pin - pins a session with the form value "pinned" ("true"), so it is
+// kept while no dashboard is connected, or unpins it
+func (h *Handler) pinSession(w http.ResponseWriter, r *http.Request) {
+	targetSessionID, err := uuid.FromString(r.PathValue("targetSid"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.SetPinned(targetSessionID, r.FormValue("pinned") == "true") {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	h.getSessions(w, r)
+}
+
+// keepAlive handles POST /keep-alive - starts the idle timeout of the session again, e.g. while the connection of the
+// dashboard is lost. The form value "pin" ("true") also pins the session.
+func (h *Handler) keepAlive(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	if h.sessions.Get(sessionID) == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	h.sessions.UpdateActivity(sessionID)
+	if r.FormValue("pin") == "true" {
+		h.sessions.SetPinned(sessionID, true)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// createHandoffLink handles POST /s/{sid}/handoff - returns a link resuming the dashboard of the session with the
+// filter, order and selected event of the query in another browser. A capturing session is pinned, so it is kept until
+// the link is opened even if no dashboard is connected.
+func (h *Handler) createHandoffLink(w http.ResponseWriter, r *http.Request) {
+	sessionID, ok := h.getSessionID(r)
+	if !ok {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	storage := h.sessions.Get(sessionID)
+	if storage != nil {
+		h.sessions.SetPinned(sessionID, true)
+	}
+	state := newHandoffState(sessionID, storage, r.URL.Query())
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "%s%s/handoff/%s", requestBaseURL(r), h.pathPrefix, state.encode())
+}
+
+// openHandoff handles GET /handoff/{token} - redirects to the dashboard of the session embedded in a handoff link.
+// The dashboard sets the session cookie for this browser and recreates the session if it was cleaned up.
+func (h *Handler) openHandoff(w http.ResponseWriter, r *http.Request) {
+	state, err := decodeHandoffState(r.PathValue("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target := fmt.Sprintf("%s/s/%s/", h.pathPrefix, state.SessionID)
+	if query := state.dashboardQuery(); len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+}
+
+// terminateSession handles DELETE /sessions/{targetSid} - stops capturing and removes a session with all its events
+func (h *Handler) terminateSession(w http.ResponseWriter, r *http.Request) {
+	targetSessionID, err := uuid.FromString(r.PathValue("targetSid"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	h.sessions.Delete(targetSessionID)
+
+	h.getSessions(w, r)
+}
+
+// loadRecentEvents returns the recent events of the storage matching the filter in the given order
+func (h *Handler) loadRecentEvents(storage *collector.CaptureStorage, filter eventFilter, order collector.EventOrder) []*collector.Event {
+	var match func(event *collector.Event) bool
+	if !filter.isEmpty() {
+		match = filter.matches
+	}
+	// Events outside of the time filter are skipped while reading, so they are not copied and matched
+	return storage.QueryEvents(collector.ReadOptions{Limit: h.truncateAfter, Since: filter.since}, match, order)
+}
+
+// matchesTagFilter checks if an event or one of its children has the tag given as "key" or "key:value"
+func matchesTagFilter(event *collector.Event, tagFilter string) bool {
+	key, value, _ := strings.Cut(tagFilter, ":")
+	return event.HasTag(key, value)
+}
+
+// getSnippet renders headers, bodies or a httptest request of an event as Go code for copying into tests
+func (h *Handler) getSnippet(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	var req snippetRequest
+	switch data := event.Data.(type) {
+	case collector.HTTPClientRequest:
+		req = snippetRequest{
+			Method:          data.Method,
+			Target:          data.URL,
+			RequestHeaders:  data.RequestHeaders,
+			ResponseHeaders: data.ResponseHeaders,
+			RequestBody:     data.RequestBody,
+			ResponseBody:    data.ResponseBody,
+		}
+	case collector.HTTPServerRequest:
+		req = snippetRequest{
+			Method:          data.Method,
+			Target:          data.URL,
+			RequestHeaders:  data.RequestHeaders,
+			ResponseHeaders: data.ResponseHeaders,
+			RequestBody:     data.RequestBody,
+			ResponseBody:    data.ResponseBody,
+		}
+	default:
+		http.Error(w, "Event type does not support snippets", http.StatusBadRequest)
+		return
+	}
+
+	snippet, ok := buildSnippet(r.PathValue("snippet"), req)
+	if !ok {
+		http.Error(w, "Unknown snippet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, snippet)
+}
+
+// downloadTest handles downloading a generated Go test for an incoming request
+func (h *Handler) downloadTest(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	request, ok := event.Data.(collector.HTTPServerRequest)
+	if !ok {
+		http.Error(w, "Tests can only be generated for incoming requests", http.StatusBadRequest)
+		return
+	}
+
+	source, err := generateTest(request)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to generate test: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-go; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_test.go", strings.ToLower(strings.TrimPrefix(testFunctionName(request), "Test"))))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(source)))
+	w.Write(source)
+}
+
+// downloadSchema infers a JSON Schema or Go type from the JSON request or response bodies ("body" is "request" or
+// "response") of all captured requests of the endpoint of an event, "format" is "json-schema" (default) or "go". Only
+// requests with a response of the status class given as "status" (e.g. "4xx") are used, by default the status class
+// of the event.
+func (h *Handler) downloadSchema(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	key, ok := endpointKey(event)
+	if !ok {
+		http.Error(w, "Schemas can only be inferred for HTTP requests", http.StatusBadRequest)
+		return
+	}
+
+	response := r.URL.Query().Get("body") != "request"
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		_, _, statusCode := requestBody(event, response)
+		status = statusClass(statusCode)
+	}
+	bodies := endpointBodies(h.loadRecentEvents(storage, eventFilter{}, collector.EventOrderNewest), key, response)[status]
+	if len(bodies) == 0 {
+		http.Error(w, fmt.Sprintf("No complete JSON bodies captured for this endpoint with status %s", status), http.StatusNotFound)
+		return
+	}
+
+	root, skipped, err := inferSchema(bodies)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if skipped > 0 {
+		h.logger.Debug("Skipped invalid JSON bodies when inferring a schema", "endpoint", key, "skipped", skipped)
+	}
+
+	typeName := schemaTypeName(event, response, status)
+	var (
+		content     []byte
+		contentType string
+		filename    string
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case schemaFormatGo:
+		content, err = goTypeSource(root, typeName)
+		contentType, filename = "text/x-go; charset=utf-8", strings.ToLower(typeName)+".go"
+	case schemaFormatJSONSchema, "":
+		content, err = jsonSchemaDocument(root, strings.TrimPrefix(strings.TrimPrefix(key, "server "), "client ")+" ("+status+")")
+		contentType, filename = "application/schema+json", strings.ToLower(typeName)+".schema.json"
+	default:
+		http.Error(w, fmt.Sprintf("Unknown format %q", format), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to render schema: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	w.Write(content)
+}
+
+// searchEventBody finds a text ("q") in the request or response body ("body" is "request" or "response") of an event
+// and renders the matches with their offsets. The body preview of text bodies is replaced with the matches marked.
+func (h *Handler) searchEventBody(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
 
-	var recentEvents []*collector.Event
-	captureActive := false
-	captureMode := "session"
-	if storage != nil {
-		recentEvents = h.loadRecentEvents(storage)
-		captureActive = true
-		captureMode = storage.CaptureMode().String()
+	bodyName := "response"
+	if r.URL.Query().Get("body") == "request" {
+		bodyName = "request"
+	}
+	body, contentType, _ := requestBody(event, bodyName == "response")
+	if body == nil {
+		http.Error(w, "No body captured", http.StatusNotFound)
+		return
 	}
 
-	r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode)
+	query := r.URL.Query().Get("q")
+	matches, total := searchBody(body.Bytes(), query)
 
-	selectedStr := r.URL.Query().Get("selected")
-	var selectedEventID *uuid.UUID
-	if selectedStr != "" {
-		eventID, err := uuid.FromString(selectedStr)
-		if err == nil {
-			selectedEventID = &eventID
+	// The preview with highlighting is restored if the query was cleared
+	var preview templ.Component
+	if views.IsTextBody(body.Bytes(), contentType) {
+		if query != "" {
+			preview = views.MarkedBodyContent(markBody(body.Bytes(), query))
+		} else {
+			preview = views.BodyContent(body.String(), contentType, views.BodyPreviewLength)
 		}
 	}
 
 	templ.Handler(
-		views.EventList(views.EventListProps{
-			Events:          recentEvents,
-			SelectedEventID: selectedEventID,
-			CaptureActive:   captureActive,
-			CaptureMode:     captureMode,
+		views.BodySearchResults(views.BodySearchResultsProps{
+			Query:     query,
+			Matches:   matches,
+			Total:     total,
+			Truncated: body.IsTruncated(),
+			Body:      bodyName,
+			Preview:   preview,
 		}),
 	).ServeHTTP(w, r)
 }
 
-func (h *Handler) clearEventList(w http.ResponseWriter, r *http.Request) {
+// getHexView renders a page of the request or response body ("body" is "request" or "response") of an event as hex and
+// ASCII, "offset" selects the page
+func (h *Handler) getHexView(w http.ResponseWriter, r *http.Request) {
 	sessionID, _ := h.getSessionID(r)
 	storage := h.sessions.Get(sessionID)
-	if storage != nil {
-		storage.Clear()
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
 	}
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
 
-	// Keep capture active if storage exists
-	captureActive := storage != nil
-	captureMode := "session"
-	if storage != nil {
-		captureMode = storage.CaptureMode().String()
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
 	}
 
-	r = h.withHandlerOptions(r, sessionID.String(), captureActive, captureMode)
-	opts := views.HandlerOptions{
-		PathPrefix:    h.pathPrefix,
-		SessionID:     sessionID.String(),
-		CaptureActive: captureActive,
-		CaptureMode:   captureMode,
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
 	}
 
-	// Update URL to remove id parameter but preserve capture state
-	w.Header().Set("HX-Push-Url", opts.BuildEventDetailURL(""))
+	bodyName := "response"
+	if r.URL.Query().Get("body") == "request" {
+		bodyName = "request"
+	}
+	body, _, _ := requestBody(event, bodyName == "response")
+	if body == nil {
+		http.Error(w, "No body captured", http.StatusNotFound)
+		return
+	}
+
+	offset := 0
+	if s := r.URL.Query().Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	content := body.Bytes()
+	start, page := hexViewPage(content, offset)
 
 	templ.Handler(
-		views.SplitLayout(views.EventList(views.EventListProps{CaptureActive: captureActive, CaptureMode: captureMode}), views.EventDetailContainer(nil)),
+		views.HexView(views.HexViewProps{
+			EventID:   eventID.String(),
+			Body:      bodyName,
+			Offset:    start,
+			PageSize:  hexViewPageSize,
+			Size:      len(content),
+			Dump:      hexDump(page, start),
+			Truncated: body.IsTruncated(),
+		}),
 	).ServeHTTP(w, r)
 }
 
-func (h *Handler) getEventDetails(w http.ResponseWriter, r *http.Request) {
+// maxDescriptorSetSize limits the size of uploaded protobuf descriptor sets
+const maxDescriptorSetSize = 10 << 20
+
+// getProtobufView renders the protobuf request or response body ("body" is "request" or "response") of an event
+// decoded as JSON
+func (h *Handler) getProtobufView(w http.ResponseWriter, r *http.Request) {
+	h.respondWithProtobufView(w, r, "")
+}
+
+// getCORSDiagnostics renders the CORS diagnostics of a server request combined from the correlated preflight and actual
+// request
+func (h *Handler) getCORSDiagnostics(w http.ResponseWriter, r *http.Request) {
 	sessionID, _ := h.getSessionID(r)
 	storage := h.sessions.Get(sessionID)
 	if storage == nil {
 		http.Error(w, "No capture session active", http.StatusNotFound)
 		return
 	}
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
 
-	captureMode := storage.CaptureMode().String()
-	r = h.withHandlerOptions(r, sessionID.String(), true, captureMode)
-
-	idStr := r.PathValue("eventId")
-	eventID, err := uuid.FromString(idStr)
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
 	if err != nil {
 		http.Error(w, "Invalid event id", http.StatusBadRequest)
 		return
@@ -324,105 +1953,193 @@ func (h *Handler) getEventDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	templ.Handler(
-		views.EventDetailContainer(event),
-	).ServeHTTP(w, r)
+	preflight, actual := corsRequests(h.loadRecentEvents(storage, eventFilter{}, collector.EventOrderNewest), event)
+	if preflight == nil && actual == nil {
+		http.Error(w, "Event is not a CORS request", http.StatusBadRequest)
+		return
+	}
+
+	templ.Handler(views.CORSDiagnostics(views.CORSDiagnosticsProps{
+		EventID:   eventID.String(),
+		Preflight: preflight,
+		Actual:    actual,
+		Insights:  insights.CORS(corsExchange(preflight), corsExchange(actual)),
+	})).ServeHTTP(w, r)
 }
 
-// getEventsSSE handles SSE connections for real-time log updates
-func (h *Handler) getEventsSSE(w http.ResponseWriter, r *http.Request) {
-	sessionID, hasSession := h.getSessionID(r)
-	if !hasSession {
-		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+// getReplayEditor renders a form to edit a captured request and send it again
+func (h *Handler) getReplayEditor(w http.ResponseWriter, r *http.Request) {
+	r, _, _, event, ok := h.replayEvent(w, r)
+	if !ok {
 		return
 	}
 
-	storage := h.sessions.Get(sessionID)
-	if storage == nil {
-		// Session was cleaned up - recreate it (fresh and empty)
-		// Use mode from query param, default to session mode
-		mode := collector.ParseCaptureModeOrDefault(r.URL.Query().Get("mode"))
-
-		var created bool
-		var err error
-		storage, created, err = h.sessions.GetOrCreate(sessionID, mode)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusServiceUnavailable)
-			return
-		}
+	props, ok := replayEditorProps(event, requestBaseURL(r))
+	if !ok {
+		http.Error(w, "Only HTTP requests can be replayed", http.StatusBadRequest)
+		return
+	}
+	templ.Handler(views.ReplayEditor(props)).ServeHTTP(w, r)
+}
 
-		// Set cookie if session mode and newly created
-		if created && mode == collector.CaptureModeSession {
-			h.setSessionCookie(w, sessionID)
-		}
+// replayRequest sends a captured request edited with the form values "method", "url", "headers" (one "Name: value" per
+// line) and "body". The details of the replayed request are rendered, it links to the original request.
+func (h *Handler) replayRequest(w http.ResponseWriter, r *http.Request) {
+	r, sessionID, storage, event, ok := h.replayEvent(w, r)
+	if !ok {
+		return
 	}
 
-	// Set handler options in context for template rendering
-	captureMode := storage.CaptureMode().String()
-	r = h.withHandlerOptions(r, sessionID.String(), true, captureMode)
+	props := views.ReplayEditorProps{
+		EventID: event.ID.String(),
+		Method:  r.FormValue("method"),
+		URL:     r.FormValue("url"),
+		Headers: r.FormValue("headers"),
+		Body:    r.FormValue("body"),
+	}
+	if h.sessions.ReadOnly(sessionID) || !storage.IsCapturing() {
+		props.Error = "Start capturing to replay requests, the replayed request is captured in this session"
+		templ.Handler(views.ReplayEditor(props), templ.WithStatus(http.StatusConflict)).ServeHTTP(w, r)
+		return
+	}
 
-	// Update activity for this session
-	h.sessions.UpdateActivity(sessionID)
+	replayed, err := h.replay(r.Context(), sessionID, storage, event, props)
+	if err != nil {
+		props.Error = fmt.Sprintf("Failed to replay request: %v", err)
+		templ.Handler(views.ReplayEditor(props), templ.WithStatus(http.StatusBadRequest)).ServeHTTP(w, r)
+		return
+	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("X-Accel-Buffering", "no") // For NGINX proxy
+	w.Header().Set("HX-Push-Url", views.MustGetHandlerOptions(r.Context()).BuildEventDetailURL(replayed.ID.String()))
+	templ.Handler(views.EventDetailContainer(replayed)).ServeHTTP(w, r)
+}
 
-	// Create a context that gets canceled when the connection is closed
-	ctx, cancel := context.WithCancel(r.Context())
-	defer cancel()
+// importRequest fills the replay editor with a curl or HTTPie command given as form value "command", e.g. copied from
+// API docs or "Copy as cURL" of the browser devtools. The imported request is sent like an edited request.
+func (h *Handler) importRequest(w http.ResponseWriter, r *http.Request) {
+	r, _, _, event, ok := h.replayEvent(w, r)
+	if !ok {
+		return
+	}
 
-	// Create a notification channel for new events from the user's storage
-	eventCh := storage.Subscribe(ctx)
+	props := views.ReplayEditorProps{
+		EventID: event.ID.String(),
+		Method:  r.FormValue("method"),
+		URL:     r.FormValue("url"),
+		Headers: r.FormValue("headers"),
+		Body:    r.FormValue("body"),
+		Command: r.FormValue("command"),
+	}
+	req, err := parseRequestCommand(props.Command)
+	if err != nil {
+		props.Error = fmt.Sprintf("Failed to import command: %v", err)
+		templ.Handler(views.ReplayEditor(props), templ.WithStatus(http.StatusBadRequest)).ServeHTTP(w, r)
+		return
+	}
 
-	// Send a keep-alive message initially to ensure the connection is established
-	fmt.Fprintf(w, "event: keepalive\ndata: connected\n\n")
-	w.(http.Flusher).Flush()
+	templ.Handler(views.ReplayEditor(views.ReplayEditorProps{
+		EventID: props.EventID,
+		Method:  req.Method,
+		URL:     req.URL,
+		Headers: formatReplayHeaders(req.Header),
+		Body:    req.Body,
+	})).ServeHTTP(w, r)
+}
 
-	// Create a ticker to keep the session alive and send keepalive messages
-	// This prevents idle timeout while SSE connection is open
-	keepaliveTicker := time.NewTicker(h.sessions.IdleTimeout() / 2)
-	defer keepaliveTicker.Stop()
+// replayEvent looks up the event to replay and responds with an error if it is not found or replay is disabled
+func (h *Handler) replayEvent(w http.ResponseWriter, r *http.Request) (*http.Request, uuid.UUID, *collector.CaptureStorage, *collector.Event, bool) {
+	if h.replayClient == nil {
+		http.Error(w, "Replay is not enabled", http.StatusNotFound)
+		return r, uuid.Nil, nil, nil, false
+	}
 
-	// Listen for new events and send them as SSE events
-	for {
-		select {
-		case <-ctx.Done():
-			return // Client disconnected
-		case <-keepaliveTicker.C:
-			// Keep session alive while SSE is connected
-			h.sessions.UpdateActivity(sessionID)
-			// Send keepalive to client
-			fmt.Fprintf(w, "event: keepalive\ndata: ping\n\n")
-			w.(http.Flusher).Flush()
-		case event, ok := <-eventCh:
-			if !ok {
-				return // Channel closed
-			}
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return r, uuid.Nil, nil, nil, false
+	}
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
 
-			// Update activity on each event
-			h.sessions.UpdateActivity(sessionID)
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return r, uuid.Nil, nil, nil, false
+	}
 
-			// Send as SSE event
-			fmt.Fprintf(w, "event: new-event\n")
-			fmt.Fprintf(w, "data: ")
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return r, uuid.Nil, nil, nil, false
+	}
+	return r, sessionID, storage, event, true
+}
 
-			views.EventListItem(event, nil).Render(ctx, w)
+// uploadProtobufDescriptors registers the message types of an uploaded FileDescriptorSet (form file "descriptors") and
+// renders the body of the event decoded with them
+func (h *Handler) uploadProtobufDescriptors(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("descriptors")
+	if err != nil {
+		http.Error(w, "Missing descriptor set", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
 
-			fmt.Fprintf(w, "\n\n")
+	data, err := io.ReadAll(io.LimitReader(file, maxDescriptorSetSize))
+	if err != nil {
+		http.Error(w, "Failed to read descriptor set", http.StatusBadRequest)
+		return
+	}
 
-			w.(http.Flusher).Flush()
-		}
+	n, err := h.protobuf.AddFileDescriptorSet(data)
+	notice := fmt.Sprintf("Registered %d message types", n)
+	if err != nil {
+		notice = fmt.Sprintf("Invalid descriptor set: %v", err)
 	}
+	h.respondWithProtobufView(w, r, notice)
 }
 
-func (h *Handler) loadRecentEvents(storage *collector.CaptureStorage) []*collector.Event {
-	recentEvents := storage.GetEvents(h.truncateAfter)
-	slices.Reverse(recentEvents)
+func (h *Handler) respondWithProtobufView(w http.ResponseWriter, r *http.Request, notice string) {
+	sessionID, _ := h.getSessionID(r)
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		http.Error(w, "No capture session active", http.StatusNotFound)
+		return
+	}
+	r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+
+	eventID, err := uuid.FromString(r.PathValue("eventId"))
+	if err != nil {
+		http.Error(w, "Invalid event id", http.StatusBadRequest)
+		return
+	}
+
+	event, exists := storage.GetEvent(eventID)
+	if !exists {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	bodyName := "response"
+	if r.URL.Query().Get("body") == "request" {
+		bodyName = "request"
+	}
+
+	messageType, decoded, err := decodeProtobufBody(h.protobuf, event, bodyName == "response")
+	props := views.ProtobufViewProps{
+		EventID:     eventID.String(),
+		Body:        bodyName,
+		MessageType: messageType,
+		JSON:        string(decoded),
+		Notice:      notice,
+	}
+	if err != nil {
+		props.Error = err.Error()
+	}
 
-	return recentEvents
+	templ.Handler(
+		views.ProtobufView(props),
+	).ServeHTTP(w, r)
 }
 
 // downloadRequestBody handles downloading the request body for an event
@@ -539,8 +2256,15 @@ func (h *Handler) downloadResponseBody(w http.ResponseWriter, r *http.Request) {
 
 // CaptureStatusResponse is the response for GET /capture/status
 type CaptureStatusResponse struct {
-	Active bool   `json:"active"`
-	Mode   string `json:"mode,omitempty"` // "session" or "global"
+	Active  bool   `json:"active"`
+	Mode    string `json:"mode,omitempty"` // "session" or "global"
+	Ambient bool   `json:"ambient,omitempty"`
+	// StopAt is the time capturing stops automatically
+	StopAt *time.Time `json:"stopAt,omitempty"`
+	// RemainingEvents is the number of events until capturing stops automatically
+	RemainingEvents int `json:"remainingEvents,omitempty"`
+	// Tenant is the tenant capturing is restricted to
+	Tenant string `json:"tenant,omitempty"`
 }
 
 // captureStart handles POST /capture/start - creates or resumes a capture session
@@ -554,10 +2278,20 @@ func (h *Handler) captureStart(w http.ResponseWriter, r *http.Request) {
 	// Parse mode from request body (default to session mode)
 	mode := collector.ParseCaptureModeOrDefault(r.FormValue("mode"))
 
+	limit, err := parseCaptureLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.sessions.ReadOnly(sessionID) {
+		http.Error(w, "Imported sessions are read-only", http.StatusConflict)
+		return
+	}
+
 	// Get or create session
 	storage, created, err := h.sessions.GetOrCreate(sessionID, mode)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		h.respondWithCaptureError(w, r, sessionID, mode, err)
 		return
 	}
 
@@ -578,22 +2312,46 @@ func (h *Handler) captureStart(w http.ResponseWriter, r *http.Request) {
 		if mode == collector.CaptureModeSession {
 			h.setSessionCookie(w, sessionID)
 		}
+		h.sessions.SetBrowser(sessionID, h.browserID(w, r))
 	}
+	storage.SetCaptureLimit(limit)
+	storage.SetTenant(r.FormValue("tenant"))
+
+	h.respondWithCaptureState(w, r, sessionID, true, mode, storage.CaptureAmbient())
+}
 
-	h.respondWithCaptureState(w, r, sessionID, true, mode)
+// parseCaptureLimit reads the form values "stopAfter" (a duration, e.g. "5m") and "stopAfterEvents" (a number of events)
+// to stop capturing automatically
+func parseCaptureLimit(r *http.Request) (collector.CaptureLimit, error) {
+	var limit collector.CaptureLimit
+	if value := r.FormValue("stopAfter"); value != "" {
+		stopAfter, err := time.ParseDuration(value)
+		if err != nil || stopAfter <= 0 {
+			return collector.CaptureLimit{}, fmt.Errorf("invalid stopAfter: %q", value)
+		}
+		limit.Until = time.Now().Add(stopAfter)
+	}
+	if value := r.FormValue("stopAfterEvents"); value != "" {
+		events, err := strconv.Atoi(value)
+		if err != nil || events <= 0 {
+			return collector.CaptureLimit{}, fmt.Errorf("invalid stopAfterEvents: %q", value)
+		}
+		limit.Events = events
+	}
+	return limit, nil
 }
 
 // captureStop handles POST /capture/stop - pauses capture but keeps session and events
 func (h *Handler) captureStop(w http.ResponseWriter, r *http.Request) {
 	sessionID, hasSession := h.getSessionID(r)
 	if !hasSession {
-		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession)
+		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession, false)
 		return
 	}
 
 	storage := h.sessions.Get(sessionID)
 	if storage == nil {
-		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession)
+		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession, false)
 		return
 	}
 
@@ -602,7 +2360,7 @@ func (h *Handler) captureStop(w http.ResponseWriter, r *http.Request) {
 
 	// Keep session cookie so user can resume
 	// Respond with active=false but preserve the mode
-	h.respondWithCaptureState(w, r, sessionID, false, storage.CaptureMode())
+	h.respondWithCaptureState(w, r, sessionID, false, storage.CaptureMode(), storage.CaptureAmbient())
 }
 
 // setCaptureMode handles POST /capture/mode - changes capture mode
@@ -638,39 +2396,86 @@ func (h *Handler) setCaptureMode(w http.ResponseWriter, r *http.Request) {
 		h.clearSessionCookie(w, sessionID)
 	}
 
-	h.respondWithCaptureState(w, r, sessionID, true, mode)
+	h.respondWithCaptureState(w, r, sessionID, true, mode, storage.CaptureAmbient())
 }
 
 // captureStatus handles GET /capture/status - returns current capture state
 func (h *Handler) captureStatus(w http.ResponseWriter, r *http.Request) {
 	sessionID, hasSession := h.getSessionID(r)
 	if !hasSession {
-		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession)
+		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession, false)
+		return
+	}
+
+	storage := h.sessions.Get(sessionID)
+	if storage == nil {
+		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession, false)
+		return
+	}
+
+	h.respondWithCaptureState(w, r, sessionID, storage.IsCapturing(), storage.CaptureMode(), storage.CaptureAmbient())
+}
+
+// captureLimit handles GET /capture/limit - polled by the dashboard while capturing is limited.
+// Renders the remaining limit, or the capture controls once capturing was stopped by the limit.
+func (h *Handler) captureLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") != "true" {
+		h.captureStatus(w, r)
+		return
+	}
+
+	sessionID, _ := h.getSessionID(r)
+	if storage := h.sessions.Get(sessionID); storage != nil && storage.IsCapturing() {
+		if limit := storage.CaptureLimit(); !limit.IsZero() {
+			r = h.withHandlerOptions(r, sessionID.String(), true, storage.CaptureMode().String(), storage.CaptureAmbient())
+			templ.Handler(
+				views.CaptureLimitIndicator(limit),
+			).ServeHTTP(w, r)
+			return
+		}
+	}
+
+	w.Header().Set("HX-Retarget", "#capture-controls")
+	w.Header().Set("HX-Reswap", "outerHTML")
+	h.captureStatus(w, r)
+}
+
+// setCaptureAmbient handles POST /capture/ambient - toggles capturing of events outside of requests
+func (h *Handler) setCaptureAmbient(w http.ResponseWriter, r *http.Request) {
+	sessionID, hasSession := h.getSessionID(r)
+	if !hasSession {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
 
 	storage := h.sessions.Get(sessionID)
 	if storage == nil {
-		h.respondWithCaptureState(w, r, sessionID, false, collector.CaptureModeSession)
+		http.Error(w, "No capture session active", http.StatusNotFound)
 		return
 	}
 
-	h.respondWithCaptureState(w, r, sessionID, storage.IsCapturing(), storage.CaptureMode())
+	storage.SetCaptureAmbient(r.FormValue("ambient") == "true")
+
+	h.respondWithCaptureState(w, r, sessionID, storage.IsCapturing(), storage.CaptureMode(), storage.CaptureAmbient())
 }
 
 // respondWithCaptureState responds with capture state as HTML for HTMX or JSON for API
-func (h *Handler) respondWithCaptureState(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, active bool, mode collector.CaptureMode) {
+func (h *Handler) respondWithCaptureState(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, active bool, mode collector.CaptureMode, ambient bool) {
 	modeStr := mode.String()
 
+	var (
+		limit  collector.CaptureLimit
+		tenant string
+	)
+	if storage := h.sessions.Get(sessionID); storage != nil && active {
+		limit = storage.CaptureLimit()
+		tenant = storage.Tenant()
+	}
+
 	// Check if this is an HTMX request
 	if r.Header.Get("HX-Request") == "true" {
-		r = h.withHandlerOptions(r, sessionID.String(), active, modeStr)
-		opts := views.HandlerOptions{
-			PathPrefix:    h.pathPrefix,
-			SessionID:     sessionID.String(),
-			CaptureActive: active,
-			CaptureMode:   modeStr,
-		}
+		r = h.withHandlerOptions(r, sessionID.String(), active, modeStr, ambient)
+		opts := views.MustGetHandlerOptions(r.Context())
 
 		// Trigger event list refresh via HTMX response header
 		w.Header().Set("HX-Trigger", "capture-state-changed")
@@ -680,16 +2485,57 @@ func (h *Handler) respondWithCaptureState(w http.ResponseWriter, r *http.Request
 
 		templ.Handler(
 			views.CaptureControls(views.CaptureState{
-				Active: active,
-				Mode:   modeStr,
+				Active:  active,
+				Mode:    modeStr,
+				Ambient: ambient,
+				Limit:   limit,
 			}),
 		).ServeHTTP(w, r)
 		return
 	}
 
 	// Return JSON for API compatibility
+	response := CaptureStatusResponse{Active: active, Mode: modeStr, Ambient: ambient, RemainingEvents: limit.Events, Tenant: tenant}
+	if !limit.Until.IsZero() {
+		response.StopAt = &limit.Until
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(CaptureStatusResponse{Active: active, Mode: modeStr})
+	json.NewEncoder(w).Encode(response)
+}
+
+// respondWithCaptureError responds with an error for a failed capture start as HTML for HTMX or plain text for API
+func (h *Handler) respondWithCaptureError(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, mode collector.CaptureMode, err error) {
+	status := sessionErrorStatus(err)
+
+	if r.Header.Get("HX-Request") == "true" {
+		r = h.withHandlerOptions(r, sessionID.String(), false, mode.String(), false)
+		templ.Handler(
+			views.CaptureControls(views.CaptureState{
+				Mode:  mode.String(),
+				Error: h.captureErrorMessage(err),
+			}),
+			templ.WithStatus(status),
+		).ServeHTTP(w, r)
+		return
+	}
+
+	http.Error(w, err.Error(), status)
+}
+
+// captureErrorMessage returns a user facing message for an error when creating a capture session
+func (h *Handler) captureErrorMessage(err error) string {
+	if errors.Is(err, ErrMaxSessionsReached) {
+		return fmt.Sprintf("All %d capture sessions are in use, try again later", h.sessions.MaxSessions())
+	}
+	return err.Error()
+}
+
+// sessionErrorStatus returns the HTTP status code for an error when creating a capture session
+func sessionErrorStatus(err error) int {
+	if errors.Is(err, ErrMaxSessionsReached) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusServiceUnavailable
 }
 
 // captureCleanup handles POST /capture/cleanup - called via sendBeacon on tab close/reload
@@ -704,7 +2550,10 @@ func (h *Handler) captureCleanup(w http.ResponseWriter, r *http.Request) {
 	// Only clear session cookie - don't delete storage.
 	// Storage will be cleaned up by idle timeout or explicit stop.
 	// Cookie will be re-set on page load if session is still active.
-	h.clearSessionCookie(w, sessionID)
+	// Named sessions keep capturing while the browser switches to another session.
+	if h.sessions.Name(sessionID) == "" {
+		h.clearSessionCookie(w, sessionID)
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -716,6 +2565,12 @@ type StatsResponse struct {
 	SessionCount    int    `json:"sessionCount"`
 	MaxSessions     int    `json:"maxSessions,omitempty"`
 	EventCount      int    `json:"eventCount"`
+	// RecoveredPanics is the number of panics in collector code or renderers that were recovered
+	RecoveredPanics uint64 `json:"recoveredPanics"`
+	// Sessions breaks down the memory usage by session, most recently active first
+	Sessions []SessionInfo `json:"sessions"`
+
+	DBPools []collector.DBPoolStats `json:"dbPools,omitempty"`
 }
 
 func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
@@ -727,12 +2582,21 @@ func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
 		SessionCount:    h.sessions.SessionCount(),
 		MaxSessions:     h.sessions.MaxSessions(),
 		EventCount:      stats.EventCount,
+		RecoveredPanics: stats.RecoveredPanics,
+		Sessions:        h.sessions.Sessions(),
+	}
+	if h.dbPoolSampler != nil {
+		response.DBPools = h.dbPoolSampler.Stats()
 	}
 
 	// Check if HTMX request
 	if r.Header.Get("HX-Request") == "true" {
+		breakdown := make([]views.SessionInfo, len(response.Sessions))
+		for i, info := range response.Sessions {
+			breakdown[i] = views.SessionInfo(info)
+		}
 		templ.Handler(
-			views.UsagePanelContent(response.MemoryFormatted, response.SessionCount, response.MaxSessions),
+			views.UsagePanelContent(response.MemoryFormatted, response.SessionCount, response.MaxSessions, breakdown, response.DBPools),
 		).ServeHTTP(w, r)
 		return
 	}
@@ -0,0 +1,98 @@
+package dashboard
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// handoffParams are the query parameters of the dashboard URL that are kept in a handoff link: the filter, the order
+// and the selected event
+var handoffParams = []string{"tag", "tenant", "kind", "path", "severity", "since", "until", "last", "sort", "id"}
+
+// handoffState is the state of a dashboard embedded in a handoff link, so the dashboard can be resumed in another
+// browser
+type handoffState struct {
+	SessionID uuid.UUID `json:"sid"`
+	// Capture is true if the session captured when the link was created, the session is recreated if it was cleaned up
+	Capture bool   `json:"capture,omitempty"`
+	Mode    string `json:"mode"`
+	Ambient bool   `json:"ambient,omitempty"`
+	// Query holds the handoff params of the dashboard URL
+	Query string `json:"query,omitempty"`
+}
+
+// newHandoffState returns the state of the dashboard of a session, storage is nil if the session does not capture.
+// Only the handoff params of the query are kept.
+func newHandoffState(sessionID uuid.UUID, storage *collector.CaptureStorage, query url.Values) handoffState {
+	state := handoffState{
+		SessionID: sessionID,
+		Mode:      collector.CaptureModeSession.String(),
+	}
+	if storage != nil {
+		state.Capture = true
+		state.Mode = storage.CaptureMode().String()
+		state.Ambient = storage.CaptureAmbient()
+	}
+
+	kept := url.Values{}
+	for _, param := range handoffParams {
+		if value := query.Get(param); value != "" {
+			kept.Set(param, value)
+		}
+	}
+	state.Query = kept.Encode()
+	return state
+}
+
+// encode returns the state as URL safe token
+func (s handoffState) encode() string {
+	data, _ := json.Marshal(s)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeHandoffState reads the state of a token created by handoffState.encode
+func decodeHandoffState(token string) (handoffState, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return handoffState{}, fmt.Errorf("invalid handoff token: %w", err)
+	}
+	var state handoffState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return handoffState{}, fmt.Errorf("invalid handoff token: %w", err)
+	}
+	if state.SessionID == uuid.Nil {
+		return handoffState{}, errors.New("invalid handoff token: missing session ID")
+	}
+	if _, ok := collector.ParseCaptureMode(state.Mode); !ok {
+		return handoffState{}, fmt.Errorf("invalid handoff token: unknown mode %q", state.Mode)
+	}
+	return state, nil
+}
+
+// dashboardQuery returns the query of the dashboard URL resuming the state. The capture params recreate the session if
+// it was cleaned up in the meantime (see Handler.root).
+func (s handoffState) dashboardQuery() url.Values {
+	query, _ := url.ParseQuery(s.Query)
+	// Only the handoff params are taken from the token, the capture state is set below
+	for param := range query {
+		if !slices.Contains(handoffParams, param) {
+			query.Del(param)
+		}
+	}
+	if s.Capture {
+		query.Set("capture", "true")
+		query.Set("mode", s.Mode)
+		if s.Ambient {
+			query.Set("ambient", "true")
+		}
+	}
+	return query
+}
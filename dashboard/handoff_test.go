@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHandoffState(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	storage := collector.NewCaptureStorage(sessionID, 10, collector.CaptureModeGlobal)
+	defer storage.Close()
+	storage.SetCaptureAmbient(true)
+
+	query := url.Values{"tag": {"tenant:acme"}, "sort": {"oldest"}, "id": {"0195a0f4-1c2b-7000-8000-000000000000"}, "other": {"x"}}
+	state := newHandoffState(sessionID, storage, query)
+
+	decoded, err := decodeHandoffState(state.encode())
+	require.NoError(t, err)
+	assert.Equal(t, state, decoded)
+
+	dashboardQuery := decoded.dashboardQuery()
+	assert.Equal(t, url.Values{
+		"tag":     {"tenant:acme"},
+		"sort":    {"oldest"},
+		"id":      {"0195a0f4-1c2b-7000-8000-000000000000"},
+		"capture": {"true"},
+		"mode":    {"global"},
+		"ambient": {"true"},
+	}, dashboardQuery, "only the handoff params and the capture state should be kept")
+
+	// A session that doesn't capture is not recreated
+	state = newHandoffState(sessionID, nil, url.Values{"kind": {"log"}})
+	assert.Equal(t, url.Values{"kind": {"log"}}, state.dashboardQuery())
+
+	for _, token := range []string{"", "not base64!", "e30", "eyJzaWQiOiIwMTk1YTBmNC0xYzJiLTcwMDAtODAwMC0wMDAwMDAwMDAwMDAiLCJtb2RlIjoieCJ9"} {
+		_, err := decodeHandoffState(token)
+		assert.Error(t, err, "token %q should be invalid", token)
+	}
+}
+
+func TestHandler_Handoff(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	_, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	require.NoError(t, err)
+
+	// Creating a link pins the session, so a GET request (e.g. a prefetch) must not create it
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/handoff?severity=warn", nil))
+	assert.NotEqual(t, http.StatusOK, rec.Code)
+	assert.False(t, handler.sessions.Pinned(sessionID), "a GET request should not pin the session")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/handoff?severity=warn", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	link := rec.Body.String()
+	assert.True(t, strings.HasPrefix(link, "http://example.com/handoff/"), "unexpected link %q", link)
+	assert.True(t, handler.sessions.Pinned(sessionID), "the session should be kept until the link is opened")
+
+	// The session was cleaned up before the link is opened in another browser
+	handler.sessions.Delete(sessionID)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(link, "http://example.com"), nil))
+	require.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/s/"+sessionID.String()+"/", location.Path)
+	assert.Equal(t, url.Values{"severity": {"warn"}, "capture": {"true"}, "mode": {"session"}}, location.Query())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, location.String(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	storage := handler.sessions.Get(sessionID)
+	require.NotNil(t, storage, "the session should be recreated")
+	assert.Equal(t, collector.CaptureModeSession, storage.CaptureMode())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/handoff/invalid", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hexViewPageSize is the number of bytes shown on a page of the hex viewer
+const hexViewPageSize = 1024
+
+// hexDump formats content like `hexdump -C` with offsets starting at offset (which should be a multiple of 16)
+func hexDump(content []byte, offset int) string {
+	var sb strings.Builder
+	for start := 0; start < len(content); start += 16 {
+		line := content[start:min(start+16, len(content))]
+
+		fmt.Fprintf(&sb, "%08x ", offset+start)
+		for i := range 16 {
+			if i == 8 {
+				sb.WriteByte(' ')
+			}
+			if i < len(line) {
+				fmt.Fprintf(&sb, " %02x", line[i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+
+		sb.WriteString("  |")
+		for _, b := range line {
+			if b < 32 || b > 126 {
+				b = '.'
+			}
+			sb.WriteByte(b)
+		}
+		sb.WriteString("|\n")
+	}
+	return sb.String()
+}
+
+// hexViewPage returns the start of the page of content containing offset and the bytes of that page
+func hexViewPage(content []byte, offset int) (start int, page []byte) {
+	offset = max(0, min(offset, len(content)-1))
+	start = offset - offset%hexViewPageSize
+	return start, content[start:min(start+hexViewPageSize, len(content))]
+}
@@ -0,0 +1,37 @@
+package dashboard
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexDump(t *testing.T) {
+	content := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x01\x00")
+
+	// Same format as hex.Dump for content at the start of a body
+	assert.Equal(t, hex.Dump(content), hexDump(content, 0))
+
+	assert.Equal(t, "00000400  61 62 63                                          |abc|\n", hexDump([]byte("abc"), 1024))
+}
+
+func TestHexViewPage(t *testing.T) {
+	content := make([]byte, 2500)
+
+	start, page := hexViewPage(content, 0)
+	assert.Equal(t, 0, start)
+	assert.Len(t, page, hexViewPageSize)
+
+	start, page = hexViewPage(content, 2100)
+	assert.Equal(t, 2048, start)
+	assert.Len(t, page, 452)
+
+	start, page = hexViewPage(content, 99999)
+	assert.Equal(t, 2048, start)
+	assert.Len(t, page, 452)
+
+	start, page = hexViewPage(nil, 0)
+	assert.Equal(t, 0, start)
+	assert.Empty(t, page)
+}
@@ -0,0 +1,339 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// NotificationRule fires a notification when a captured event or one of its nested events matches all of its conditions
+type NotificationRule struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name,omitempty"`
+
+	// MinStatus matches HTTP requests with at least this status code (e.g. 500)
+	MinStatus int `json:"minStatus,omitempty"`
+	// Path matches HTTP requests with a path starting with it
+	Path string `json:"path,omitempty"`
+	// MinLevel matches logs with at least this level (e.g. slog.LevelError)
+	MinLevel *slog.Level `json:"minLevel,omitempty"`
+	// MinSeverity matches events with at least this severity (e.g. collector.SeverityError)
+	MinSeverity collector.Severity `json:"minSeverity,omitempty"`
+
+	// Browser shows a browser notification in the dashboard of the session
+	Browser bool `json:"browser,omitempty"`
+	// WebhookURL receives a POST request with the matching event as JSON
+	WebhookURL string `json:"webhookUrl,omitempty"`
+
+	// LastWebhookError is the error of the last failed webhook request, empty if it succeeded
+	LastWebhookError string `json:"lastWebhookError,omitempty"`
+}
+
+// validate checks that the rule has a condition and sends a notification
+func (r NotificationRule) validate() error {
+	if r.MinStatus == 0 && r.Path == "" && r.MinLevel == nil && r.MinSeverity == "" {
+		return errors.New("a rule needs at least one condition")
+	}
+	if r.MinStatus != 0 && (r.MinStatus < 100 || r.MinStatus > 599) {
+		return fmt.Errorf("invalid minimum status %d", r.MinStatus)
+	}
+	if !r.Browser && r.WebhookURL == "" {
+		return errors.New("a rule needs a browser notification or a webhook URL")
+	}
+	if r.WebhookURL != "" {
+		u, err := url.Parse(r.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", r.WebhookURL)
+		}
+	}
+	return nil
+}
+
+// parseNotificationRule reads a rule from the form values "name", "minStatus", "path", "minLevel" (e.g. "ERROR"),
+// "minSeverity" (e.g. "error"), "browser" ("true") and "webhookUrl"
+func parseNotificationRule(form url.Values) (NotificationRule, error) {
+	rule := NotificationRule{
+		ID:         uuid.Must(uuid.NewV7()),
+		Name:       strings.TrimSpace(form.Get("name")),
+		Path:       form.Get("path"),
+		Browser:    form.Get("browser") == "true",
+		WebhookURL: strings.TrimSpace(form.Get("webhookUrl")),
+	}
+	if value := form.Get("minStatus"); value != "" {
+		minStatus, err := strconv.Atoi(value)
+		if err != nil {
+			return NotificationRule{}, fmt.Errorf("invalid minimum status %q", value)
+		}
+		rule.MinStatus = minStatus
+	}
+	if value := form.Get("minLevel"); value != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return NotificationRule{}, fmt.Errorf("invalid minimum level %q", value)
+		}
+		rule.MinLevel = &level
+	}
+	if value := form.Get("minSeverity"); value != "" {
+		severity, err := collector.ParseSeverity(value)
+		if err != nil {
+			return NotificationRule{}, fmt.Errorf("invalid minimum severity %q", value)
+		}
+		rule.MinSeverity = severity
+	}
+	if err := rule.validate(); err != nil {
+		return NotificationRule{}, err
+	}
+	return rule, nil
+}
+
+// matches returns true if the event itself matches all conditions of the rule
+func (r NotificationRule) matches(event *collector.Event) bool {
+	if r.MinStatus != 0 || r.Path != "" {
+		var status int
+		switch data := event.Data.(type) {
+		case collector.HTTPServerRequest:
+			status = data.StatusCode
+		case collector.HTTPClientRequest:
+			status = data.StatusCode
+		default:
+			return false
+		}
+		if status < r.MinStatus || !strings.HasPrefix(eventPath(event), r.Path) {
+			return false
+		}
+	}
+	if r.MinLevel != nil {
+		record, ok := event.Data.(slog.Record)
+		if !ok || record.Level < *r.MinLevel {
+			return false
+		}
+	}
+	if r.MinSeverity != "" && !event.Severity.AtLeast(r.MinSeverity) {
+		return false
+	}
+	return true
+}
+
+// firstMatch returns the first event in the tree of a top-level event matching the rule, nil if none matches
+func (r NotificationRule) firstMatch(event *collector.Event) *collector.Event {
+	for _, e := range event.Visit() {
+		if r.matches(e) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Notification is sent to the dashboard of a session when a captured event matches a rule with browser notifications
+type Notification struct {
+	RuleID  uuid.UUID `json:"ruleId"`
+	Rule    string    `json:"rule,omitempty"`
+	Title   string    `json:"title"`
+	EventID uuid.UUID `json:"eventId"` // ID of the top-level event to show in the dashboard
+}
+
+// webhookPayload is the JSON body sent to the webhook URL of a rule
+type webhookPayload struct {
+	RuleID    uuid.UUID        `json:"ruleId"`
+	Rule      string           `json:"rule,omitempty"`
+	SessionID uuid.UUID        `json:"sessionId"`
+	Title     string           `json:"title"`
+	Event     *collector.Event `json:"event"`
+}
+
+// webhookClient sends webhook requests, it is not instrumented so webhooks are not captured themselves. Requests are
+// marked with collector.InternalRequestHeader, so a webhook calling the application is not captured either.
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookQueueSize is the number of webhook requests of a session waiting to be sent, further requests are dropped
+const webhookQueueSize = 100
+
+// webhookRequest is a webhook of a rule waiting to be sent for a matching event
+type webhookRequest struct {
+	rule  NotificationRule
+	title string
+	event *collector.Event
+}
+
+// notificationWatcher evaluates the notification rules of a session against its captured events.
+// It runs in the background, so webhooks are also sent without an open dashboard.
+type notificationWatcher struct {
+	sessionID uuid.UUID
+	logger    *slog.Logger
+
+	mu    sync.RWMutex
+	rules []NotificationRule
+
+	notifier *collector.Notifier[Notification]
+	cancel   context.CancelFunc
+
+	// webhooks are sent one after another, so a slow webhook URL does not pile up requests
+	webhooks        chan webhookRequest
+	droppedWebhooks atomic.Uint64
+}
+
+// newNotificationWatcher starts watching the captured events of a storage until it is closed
+func newNotificationWatcher(sessionID uuid.UUID, storage collector.EventStorage, logger *slog.Logger) *notificationWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &notificationWatcher{
+		sessionID: sessionID,
+		logger:    logger,
+		notifier:  collector.NewNotifier[Notification](),
+		cancel:    cancel,
+		webhooks:  make(chan webhookRequest, webhookQueueSize),
+	}
+	go w.watch(storage.Subscribe(ctx))
+	go w.sendWebhooks()
+	return w
+}
+
+// Rules returns the notification rules
+func (w *notificationWatcher) Rules() []NotificationRule {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return slices.Clone(w.rules)
+}
+
+// addRule adds a rule
+func (w *notificationWatcher) addRule(rule NotificationRule) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rules = append(w.rules, rule)
+}
+
+// removeRule removes a rule and reports whether it was found
+func (w *notificationWatcher) removeRule(ruleID uuid.UUID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(w.rules)
+	w.rules = slices.DeleteFunc(w.rules, func(rule NotificationRule) bool { return rule.ID == ruleID })
+	return len(w.rules) < n
+}
+
+// Subscribe returns a channel that receives browser notifications
+func (w *notificationWatcher) Subscribe(ctx context.Context) <-chan Notification {
+	return w.notifier.Subscribe(ctx)
+}
+
+// close stops watching, pending webhooks are still sent
+func (w *notificationWatcher) close() {
+	w.cancel()
+	w.notifier.Close()
+}
+
+// DroppedWebhooks returns the number of webhook requests that were dropped because the queue was full
+func (w *notificationWatcher) DroppedWebhooks() uint64 {
+	return w.droppedWebhooks.Load()
+}
+
+func (w *notificationWatcher) watch(events <-chan *collector.Event) {
+	// Queued webhooks are still sent after the watcher was closed
+	defer close(w.webhooks)
+
+	for event := range events {
+		// Previews of events in progress are evaluated when they complete, updates with late children were evaluated
+		// before, so rules do not notify twice about the same event
+		if event.InProgress || event.LateChildren {
+			continue
+		}
+		for _, rule := range w.Rules() {
+			match := rule.firstMatch(event)
+			if match == nil {
+				continue
+			}
+			title := collector.PayloadOf(match.Data).Summary()
+			if rule.Browser {
+				w.notifier.Notify(Notification{RuleID: rule.ID, Rule: rule.Name, Title: title, EventID: event.ID})
+			}
+			if rule.WebhookURL != "" {
+				w.queueWebhook(webhookRequest{rule: rule, title: title, event: match})
+			}
+		}
+	}
+}
+
+// queueWebhook queues a webhook request, it is dropped if the queue is full
+func (w *notificationWatcher) queueWebhook(req webhookRequest) {
+	select {
+	case w.webhooks <- req:
+	default:
+		w.droppedWebhooks.Add(1)
+		w.logger.Warn("Dropped notification webhook, queue is full", "session", w.sessionID, "rule", req.rule.Name)
+	}
+}
+
+// sendWebhooks sends queued webhook requests until the watcher is closed and the queue is drained
+func (w *notificationWatcher) sendWebhooks() {
+	for req := range w.webhooks {
+		w.sendWebhook(req.rule, req.title, req.event)
+	}
+}
+
+// sendWebhook posts a matching event to the webhook URL of a rule and records an error on the rule
+func (w *notificationWatcher) sendWebhook(rule NotificationRule, title string, event *collector.Event) {
+	err := postWebhook(rule.WebhookURL, webhookPayload{
+		RuleID:    rule.ID,
+		Rule:      rule.Name,
+		SessionID: w.sessionID,
+		Title:     title,
+		Event:     event,
+	})
+
+	var lastError string
+	if err != nil {
+		lastError = err.Error()
+		w.logger.Warn("Failed to send notification webhook", "session", w.sessionID, "rule", rule.Name, "error", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := range w.rules {
+		if w.rules[i].ID == rule.ID {
+			w.rules[i].LastWebhookError = lastError
+		}
+	}
+}
+
+func postWebhook(webhookURL string, payload webhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(collector.InternalRequestHeader, "webhook")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
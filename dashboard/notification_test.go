@@ -0,0 +1,236 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestParseNotificationRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    url.Values
+		wantErr bool
+	}{
+		{
+			name: "status and browser",
+			form: url.Values{"minStatus": {"500"}, "browser": {"true"}},
+		},
+		{
+			name: "level and webhook",
+			form: url.Values{"minLevel": {"ERROR"}, "webhookUrl": {"https://example.com/hook"}},
+		},
+		{
+			name: "severity and browser",
+			form: url.Values{"minSeverity": {"warn"}, "browser": {"true"}},
+		},
+		{
+			name:    "no condition",
+			form:    url.Values{"browser": {"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "no notification",
+			form:    url.Values{"minStatus": {"500"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status",
+			form:    url.Values{"minStatus": {"1000"}, "browser": {"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			form:    url.Values{"minLevel": {"LOUD"}, "browser": {"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid severity",
+			form:    url.Values{"minSeverity": {"fatal"}, "browser": {"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid webhook URL",
+			form:    url.Values{"path": {"/api/"}, "webhookUrl": {"ftp://example.com"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseNotificationRule(tt.form)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && rule.ID == uuid.Nil {
+				t.Error("expected rule to have an ID")
+			}
+		})
+	}
+}
+
+func TestNotificationRule_FirstMatch(t *testing.T) {
+	errorLevel := slog.LevelError
+	logEvent := &collector.Event{
+		ID:       uuid.Must(uuid.NewV7()),
+		Data:     slog.NewRecord(time.Now(), slog.LevelError, "payment failed", 0),
+		Severity: collector.SeverityError,
+	}
+	request := &collector.Event{
+		ID:       uuid.Must(uuid.NewV7()),
+		Data:     collector.HTTPServerRequest{Method: http.MethodPost, Path: "/api/checkout", StatusCode: 502},
+		Severity: collector.SeverityError,
+		Children: []*collector.Event{logEvent},
+	}
+
+	tests := []struct {
+		name     string
+		rule     NotificationRule
+		expected *collector.Event
+	}{
+		{
+			name:     "status",
+			rule:     NotificationRule{MinStatus: 500},
+			expected: request,
+		},
+		{
+			name:     "status and path",
+			rule:     NotificationRule{MinStatus: 500, Path: "/api/"},
+			expected: request,
+		},
+		{
+			name: "other path",
+			rule: NotificationRule{MinStatus: 500, Path: "/admin/"},
+		},
+		{
+			name:     "nested log",
+			rule:     NotificationRule{MinLevel: &errorLevel},
+			expected: logEvent,
+		},
+		{
+			name:     "severity",
+			rule:     NotificationRule{MinSeverity: collector.SeverityError},
+			expected: request,
+		},
+		{
+			name:     "level and severity",
+			rule:     NotificationRule{MinLevel: &errorLevel, MinSeverity: collector.SeverityError},
+			expected: logEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if match := tt.rule.firstMatch(request); match != tt.expected {
+				t.Errorf("expected match %v, got %v", tt.expected, match)
+			}
+		})
+	}
+}
+
+func TestSessionManager_NotificationRules(t *testing.T) {
+	webhooks := make(chan webhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(collector.InternalRequestHeader) == "" {
+			t.Error("expected webhook to be marked as internal request")
+		}
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode webhook: %v", err)
+		}
+		webhooks <- payload
+	}))
+	defer server.Close()
+
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if sm.AddNotificationRule(sessionID, NotificationRule{ID: uuid.Must(uuid.NewV7())}) {
+		t.Error("expected AddNotificationRule to return false for non-existent session")
+	}
+
+	storage, _, _ := sm.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	rule := NotificationRule{
+		ID:         uuid.Must(uuid.NewV7()),
+		Name:       "Server errors",
+		MinStatus:  500,
+		Browser:    true,
+		WebhookURL: server.URL,
+	}
+	if !sm.AddNotificationRule(sessionID, rule) {
+		t.Fatal("expected AddNotificationRule to return true for existing session")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifications := sm.SubscribeNotifications(ctx, sessionID)
+
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.HTTPServerRequest{Path: "/", StatusCode: 200}})
+	event := &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.HTTPServerRequest{Path: "/fail", StatusCode: 500}}
+	storage.Add(event)
+
+	select {
+	case notification := <-notifications:
+		if notification.EventID != event.ID || notification.Rule != "Server errors" {
+			t.Errorf("unexpected notification %+v", notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a browser notification")
+	}
+
+	select {
+	case payload := <-webhooks:
+		if payload.RuleID != rule.ID || payload.SessionID != sessionID || payload.Event.ID != event.ID {
+			t.Errorf("unexpected webhook payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a webhook request")
+	}
+
+	if !sm.RemoveNotificationRule(sessionID, rule.ID) {
+		t.Error("expected RemoveNotificationRule to return true for existing rule")
+	}
+	if rules, _ := sm.NotificationRules(sessionID); len(rules) != 0 {
+		t.Errorf("expected no rules, got %d", len(rules))
+	}
+}
+
+func TestNotificationWatcher_WebhookQueue(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), webhookQueueSize*2, collector.CaptureModeGlobal)
+	defer storage.Close()
+	watcher := newNotificationWatcher(uuid.Must(uuid.NewV4()), storage, collector.NewEventAggregator().Logger())
+	defer watcher.close()
+	watcher.addRule(NotificationRule{ID: uuid.Must(uuid.NewV7()), MinStatus: 500, WebhookURL: server.URL})
+
+	// The first webhook blocks, so the following requests fill the queue instead of starting a request each
+	for i := 0; i < webhookQueueSize*2 && watcher.DroppedWebhooks() == 0; i++ {
+		storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.HTTPServerRequest{Path: "/fail", StatusCode: 500}})
+		time.Sleep(time.Millisecond)
+	}
+
+	if watcher.DroppedWebhooks() == 0 {
+		t.Error("expected webhooks to be dropped while the queue is full")
+	}
+}
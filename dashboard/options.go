@@ -1,6 +1,12 @@
 package dashboard
 
-import "time"
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+)
 
 // handlerOptions holds configuration for a dashboard Handler.
 // This is unexported; use HandlerOption functions to configure.
@@ -13,8 +19,26 @@ type handlerOptions struct {
 	StorageCapacity uint64
 	// SessionIdleTimeout is how long to wait after SSE disconnect before cleanup.
 	SessionIdleTimeout time.Duration
+	// PinnedSessionMaxAge is how long a pinned session is kept at most while no dashboard is connected.
+	PinnedSessionMaxAge time.Duration
+	// PreferencesRetention is how long the preferences of a cleaned up session are kept for its recreation.
+	PreferencesRetention time.Duration
 	// MaxSessions is the maximum number of concurrent sessions (0 = unlimited).
 	MaxSessions int
+	// DBPoolSampler provides connection pool stats for the usage panel (optional).
+	DBPoolSampler *collector.DBPoolSampler
+	// FlightRecorderCapacity is the number of events the flight recorder keeps (0 = disabled).
+	FlightRecorderCapacity uint64
+	// Logger is used for internal logging (optional, defaults to the logger of the aggregator).
+	Logger *slog.Logger
+	// ProtobufDescriptorSets are serialized FileDescriptorSets to decode protobuf bodies.
+	ProtobufDescriptorSets [][]byte
+	// ReplayTransport sends requests that are edited and replayed in the dashboard (nil = replay disabled).
+	ReplayTransport http.RoundTripper
+	// ArchiveDir is the directory for archives of events evicted from session storages (empty = archival disabled).
+	ArchiveDir string
+	// ArchiveMaxBytes limits the compressed size of the archive of each session.
+	ArchiveMaxBytes int64
 }
 
 // HandlerOption configures a dashboard Handler.
@@ -45,6 +69,24 @@ func WithSessionIdleTimeout(timeout time.Duration) HandlerOption {
 	}
 }
 
+// WithPinnedSessionMaxAge sets how long a pinned session is kept at most while no dashboard is connected.
+// Sessions are pinned in the session list or when the connection of the dashboard is lost.
+// Default is 24 hours if not specified.
+func WithPinnedSessionMaxAge(maxAge time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.PinnedSessionMaxAge = maxAge
+	}
+}
+
+// WithPreferencesRetention sets how long the preferences of an idle session (e.g. display preferences and error alerts)
+// are kept after it was cleaned up, so they are restored when the dashboard reconnects.
+// Default is 1 hour if not specified.
+func WithPreferencesRetention(retention time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.PreferencesRetention = retention
+	}
+}
+
 // WithTruncateAfter limits the number of events shown in the event list.
 // Default uses StorageCapacity if not specified.
 func WithTruncateAfter(limit uint64) HandlerOption {
@@ -60,3 +102,57 @@ func WithMaxSessions(limit int) HandlerOption {
 		o.MaxSessions = limit
 	}
 }
+
+// WithDBPoolSampler shows the latest connection pool stats of the sampler in the usage panel.
+func WithDBPoolSampler(sampler *collector.DBPoolSampler) HandlerOption {
+	return func(o *handlerOptions) {
+		o.DBPoolSampler = sampler
+	}
+}
+
+// WithFlightRecorder always captures the most recent capacity events globally, even without a capture session.
+// The recorded events can be dumped into a new session from the dashboard when something went wrong.
+// Default is disabled, since all events are collected while it is enabled.
+func WithFlightRecorder(capacity uint64) HandlerOption {
+	return func(o *handlerOptions) {
+		o.FlightRecorderCapacity = capacity
+	}
+}
+
+// WithLogger sets the logger for internal logging of the dashboard, e.g. session cleanup and failed webhooks.
+// Default is the logger of the event aggregator, which discards everything unless configured.
+func WithLogger(logger *slog.Logger) HandlerOption {
+	return func(o *handlerOptions) {
+		o.Logger = logger
+	}
+}
+
+// WithProtobufDescriptorSet registers the message types of a serialized FileDescriptorSet (e.g. generated with
+// `buf build -o descriptors.binpb` or `protoc --include_imports --descriptor_set_out`) to decode captured protobuf
+// and gRPC bodies into JSON. Descriptor sets can also be uploaded in the dashboard.
+func WithProtobufDescriptorSet(data []byte) HandlerOption {
+	return func(o *handlerOptions) {
+		o.ProtobufDescriptorSets = append(o.ProtobufDescriptorSets, data)
+	}
+}
+
+// WithReplayTransport enables editing and replaying captured requests in the dashboard. Replayed requests are sent with
+// the transport, it should be wrapped by the HTTP client collector (see collector.HTTPClientCollector.Transport), so
+// they are captured and linked to the original request. Default is disabled.
+func WithReplayTransport(transport http.RoundTripper) HandlerOption {
+	return func(o *handlerOptions) {
+		o.ReplayTransport = transport
+	}
+}
+
+// WithEventArchive keeps events evicted from the storage of a session (see WithStorageCapacity) in compressed segments
+// on disk instead of discarding them, e.g. for long capture sessions. Each session archives to its own subdirectory of
+// dir, which is deleted with the session. The oldest segments are deleted when an archive exceeds maxBytes (0 uses
+// collector.DefaultArchiveMaxBytes). Archived events are loaded on demand after the last event in memory and marked as
+// archived in the dashboard. Default is disabled.
+func WithEventArchive(dir string, maxBytes int64) HandlerOption {
+	return func(o *handlerOptions) {
+		o.ArchiveDir = dir
+		o.ArchiveMaxBytes = maxBytes
+	}
+}
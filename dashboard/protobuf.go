@@ -0,0 +1,89 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/internal/protodecode"
+)
+
+// protobufMessageType determines the message type of a protobuf body by a "proto" or "messageType" parameter of the
+// content type or by the method of a gRPC or Connect call. It returns an empty string if the type is unknown.
+func protobufMessageType(registry *protodecode.Registry, event *collector.Event, response bool, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		for _, name := range []string{"proto", "messagetype"} {
+			if messageType := params[name]; messageType != "" && registry.HasMessage(messageType) {
+				return messageType
+			}
+		}
+	}
+
+	var path string
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		path = data.Path
+	case collector.HTTPClientRequest:
+		if u, err := url.Parse(data.URL); err == nil {
+			path = u.Path
+		}
+	}
+	// Services might be mounted with a prefix, the method is always the last two segments
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	inputType, outputType, ok := registry.MethodTypes("/" + strings.Join(segments[len(segments)-2:], "/"))
+	if !ok {
+		return ""
+	}
+	if response {
+		return outputType
+	}
+	return inputType
+}
+
+// isEnvelopedContentType checks if messages in a body are prefixed with flags and length (gRPC, gRPC-Web and Connect streaming)
+func isEnvelopedContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return strings.HasPrefix(mediaType, "application/grpc") || mediaType == "application/connect+proto"
+}
+
+// decodeProtobufBody decodes the protobuf request or response body of an event to JSON. Enveloped bodies with
+// multiple messages are decoded to an array.
+func decodeProtobufBody(registry *protodecode.Registry, event *collector.Event, response bool) (messageType string, decoded []byte, err error) {
+	body, contentType, _ := requestBody(event, response)
+	if body == nil {
+		return "", nil, fmt.Errorf("no body captured")
+	}
+	if body.IsTruncated() {
+		return "", nil, fmt.Errorf("body was truncated when captured")
+	}
+
+	messageType = protobufMessageType(registry, event, response, contentType)
+
+	if !isEnvelopedContentType(contentType) {
+		decoded, err = registry.Decode(body.Bytes(), messageType)
+		return messageType, decoded, err
+	}
+
+	messages, err := protodecode.Unframe(body.Bytes())
+	if err != nil {
+		return messageType, nil, err
+	}
+	values := make([]json.RawMessage, len(messages))
+	for i, message := range messages {
+		if values[i], err = registry.Decode(message, messageType); err != nil {
+			return messageType, nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+	if len(values) == 1 {
+		return messageType, values[0], nil
+	}
+	decoded, err = json.MarshalIndent(values, "", "  ")
+	return messageType, decoded, err
+}
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/internal/protodecode"
+)
+
+func TestDecodeProtobufBody(t *testing.T) {
+	registry := protodecode.NewRegistry()
+
+	// Two messages {1: "hi"} and {2: 1} of a server streaming gRPC response
+	body := []byte{
+		0, 0, 0, 0, 4, 0x0a, 0x02, 'h', 'i',
+		0, 0, 0, 0, 2, 0x10, 0x01,
+	}
+	event := &collector.Event{
+		Data: collector.HTTPServerRequest{
+			Method:          http.MethodPost,
+			Path:            "/acme.v1.UserService/ListUsers",
+			ResponseHeaders: http.Header{"Content-Type": {"application/grpc+proto"}},
+			ResponseBody:    capturedBody(t, body),
+		},
+	}
+
+	messageType, decoded, err := decodeProtobufBody(registry, event, true)
+	require.NoError(t, err)
+	assert.Empty(t, messageType)
+	assert.JSONEq(t, `[{"1": "hi"}, {"2": 1}]`, string(decoded))
+
+	_, _, err = decodeProtobufBody(registry, event, false)
+	assert.Error(t, err)
+}
+
+func TestIsEnvelopedContentType(t *testing.T) {
+	assert.True(t, isEnvelopedContentType("application/grpc"))
+	assert.True(t, isEnvelopedContentType("application/grpc-web+proto"))
+	assert.True(t, isEnvelopedContentType("application/connect+proto"))
+	assert.False(t, isEnvelopedContentType("application/proto"))
+	assert.False(t, isEnvelopedContentType("application/x-protobuf; messageType=acme.v1.User"))
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+func TestRawEventJSON_HTTPServerRequest(t *testing.T) {
+	body := collector.NewBody(io.NopCloser(strings.NewReader(`{"name":"devlog"}`)), 1024)
+	_, _ = io.ReadAll(body)
+	_ = body.Close()
+
+	child := &collector.Event{ID: uuid.Must(uuid.NewV7())}
+	event := &collector.Event{
+		ID: uuid.Must(uuid.NewV7()),
+		Data: collector.HTTPServerRequest{
+			Method:         http.MethodPost,
+			Path:           "/todos",
+			RequestHeaders: http.Header{"Content-Type": []string{"application/json"}},
+			RequestBody:    body,
+			Error:          errors.New("boom"),
+		},
+		Start:    time.Now(),
+		End:      time.Now(),
+		Children: []*collector.Event{child},
+	}
+
+	rawJSON, err := views.RawEventJSON(event)
+	if err != nil {
+		t.Fatalf("failed to serialize event: %v", err)
+	}
+
+	var raw struct {
+		Type     string   `json:"type"`
+		Children []string `json:"children"`
+		Data     struct {
+			Method         string
+			RequestHeaders map[string][]string
+			RequestBody    struct {
+				Content   string `json:"content"`
+				Truncated bool   `json:"truncated"`
+			}
+			ResponseBody *struct{}
+			Error        string
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rawJSON, &raw); err != nil {
+		t.Fatalf("failed to parse raw JSON: %v\n%s", err, rawJSON)
+	}
+
+	if raw.Type != "collector.HTTPServerRequest" {
+		t.Errorf("expected type collector.HTTPServerRequest, got %s", raw.Type)
+	}
+	if raw.Data.Method != http.MethodPost {
+		t.Errorf("expected method POST, got %s", raw.Data.Method)
+	}
+	if raw.Data.RequestHeaders["Content-Type"][0] != "application/json" {
+		t.Errorf("expected request headers, got %v", raw.Data.RequestHeaders)
+	}
+	if raw.Data.RequestBody.Content != `{"name":"devlog"}` || raw.Data.RequestBody.Truncated {
+		t.Errorf("expected request body content, got %+v", raw.Data.RequestBody)
+	}
+	if raw.Data.ResponseBody != nil {
+		t.Errorf("expected no response body, got %+v", raw.Data.ResponseBody)
+	}
+	if raw.Data.Error != "boom" {
+		t.Errorf("expected error message, got %q", raw.Data.Error)
+	}
+	if len(raw.Children) != 1 || raw.Children[0] != child.ID.String() {
+		t.Errorf("expected child ID, got %v", raw.Children)
+	}
+}
+
+func TestRawEventJSON_SlogRecord(t *testing.T) {
+	record := slog.NewRecord(time.Now(), slog.LevelWarn, "Slow query", 0)
+	record.AddAttrs(
+		slog.Duration("duration", 2*time.Second),
+		slog.Group("db", slog.String("table", "todos")),
+	)
+	event := &collector.Event{
+		ID:    uuid.Must(uuid.NewV7()),
+		Data:  record,
+		Start: time.Now(),
+	}
+
+	rawJSON, err := views.RawEventJSON(event)
+	if err != nil {
+		t.Fatalf("failed to serialize event: %v", err)
+	}
+
+	var raw struct {
+		Data struct {
+			Level   string
+			Message string
+			Attrs   map[string]any
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rawJSON, &raw); err != nil {
+		t.Fatalf("failed to parse raw JSON: %v\n%s", err, rawJSON)
+	}
+
+	if raw.Data.Level != "WARN" || raw.Data.Message != "Slow query" {
+		t.Errorf("unexpected record: %+v", raw.Data)
+	}
+	if raw.Data.Attrs["duration"] != "2s" {
+		t.Errorf("expected duration attribute, got %v", raw.Data.Attrs["duration"])
+	}
+	if group, ok := raw.Data.Attrs["db"].(map[string]any); !ok || group["table"] != "todos" {
+		t.Errorf("expected db group, got %v", raw.Data.Attrs["db"])
+	}
+}
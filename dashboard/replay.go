@@ -0,0 +1,146 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard/views"
+)
+
+const (
+	// replayTimeout limits the duration of a replayed request
+	replayTimeout = 30 * time.Second
+	// replayCaptureTimeout is how long to wait until a replayed request is stored, e.g. if the HTTP client collector
+	// processes requests asynchronously
+	replayCaptureTimeout = 2 * time.Second
+)
+
+// errReplayNotCaptured is returned if a replayed request was sent but not captured in the session
+var errReplayNotCaptured = errors.New("the request was sent but not captured, is the replay transport wrapped by the HTTP client collector?")
+
+// replayEditorProps pre-fills the replay editor with a captured request, false is returned for other events.
+// Incoming requests are sent to baseURL (the host of the dashboard), since their URL has no host.
+func replayEditorProps(event *collector.Event, baseURL string) (views.ReplayEditorProps, bool) {
+	props := views.ReplayEditorProps{EventID: event.ID.String()}
+	var body *collector.Body
+	switch request := event.Data.(type) {
+	case collector.HTTPClientRequest:
+		props.Method, props.URL = request.Method, request.URL
+		props.Headers = formatReplayHeaders(request.RequestHeaders)
+		body = request.RequestBody
+	case collector.HTTPServerRequest:
+		props.Method, props.URL = request.Method, baseURL+request.URL
+		props.Headers = formatReplayHeaders(request.RequestHeaders)
+		body = request.RequestBody
+	default:
+		return props, false
+	}
+	props.Body = string(body.Bytes())
+	if body.IsTruncated() || body.IsEvicted() {
+		props.Error = "The captured body is incomplete, check it before sending"
+	}
+	return props, true
+}
+
+// formatReplayHeaders formats headers with one "Name: value" line per value, ordered by name
+func formatReplayHeaders(header http.Header) string {
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	return sb.String()
+}
+
+// parseReplayHeaders parses headers formatted by formatReplayHeaders, empty lines are ignored
+func parseReplayHeaders(text string) (http.Header, error) {
+	header := make(http.Header)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header in line %d, expected \"Name: value\"", i+1)
+		}
+		header.Add(name, strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// replay sends an edited request with the replay client and returns it as captured in the session. It is linked to the
+// original event (see collector.WithDerivedFrom) and keeps its tenant.
+func (h *Handler) replay(ctx context.Context, sessionID uuid.UUID, storage *collector.CaptureStorage, original *collector.Event, props views.ReplayEditorProps) (*collector.Event, error) {
+	header, err := parseReplayHeaders(props.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = collector.WithSessionIDs(ctx, []uuid.UUID{sessionID})
+	ctx = collector.WithTenant(ctx, original.Tenant)
+	ctx = collector.WithDerivedFrom(ctx, original.ID)
+
+	var body io.Reader
+	if props.Body != "" {
+		body = strings.NewReader(props.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(strings.TrimSpace(props.Method)), strings.TrimSpace(props.URL), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+		header.Del("Host")
+	}
+
+	sent := time.Now()
+	resp, sendErr := h.replayClient.Do(req)
+	if sendErr == nil {
+		// Read the response, so its body is captured
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	// A failed request is captured with its error as well
+	deadline := time.Now().Add(replayCaptureTimeout)
+	for {
+		if event := findReplayedEvent(storage, original.ID, sent); event != nil {
+			return event, nil
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if sendErr != nil {
+		return nil, sendErr
+	}
+	return nil, errReplayNotCaptured
+}
+
+// findReplayedEvent returns the most recent completed request derived from the original event that was started after
+// since, nil if there is none
+func findReplayedEvent(storage *collector.CaptureStorage, originalID uuid.UUID, since time.Time) *collector.Event {
+	events := storage.GetEvents(100)
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if request, ok := event.Data.(collector.HTTPClientRequest); ok && !event.InProgress && request.DerivedFrom == originalID && !event.Start.Before(since) {
+			return event
+		}
+	}
+	return nil
+}
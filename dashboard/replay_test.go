@@ -0,0 +1,168 @@
+package dashboard
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestReplayHeaders(t *testing.T) {
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"text/plain", "application/json"},
+	}
+	text := formatReplayHeaders(header)
+	if expected := "Accept: text/plain\nAccept: application/json\nContent-Type: application/json\n"; text != expected {
+		t.Errorf("expected formatted headers %q, got %q", expected, text)
+	}
+
+	parsed, err := parseReplayHeaders(text + "\n  x-custom:  value: with colon  \n")
+	if err != nil {
+		t.Fatalf("failed to parse headers: %v", err)
+	}
+	if len(parsed["Accept"]) != 2 || parsed.Get("Content-Type") != "application/json" || parsed.Get("X-Custom") != "value: with colon" {
+		t.Errorf("unexpected parsed headers %v", parsed)
+	}
+
+	for _, text := range []string{"no colon", ": empty name", "Invalid Name: value"} {
+		if _, err := parseReplayHeaders(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+}
+
+func TestHandler_ReplayRequest(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.Header.Get("X-Test")+" "+string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	aggregator := collector.NewEventAggregator()
+	options := collector.DefaultHTTPClientOptions()
+	options.EventAggregator = aggregator
+	transport := collector.NewHTTPClientCollectorWithOptions(options).Transport(http.DefaultTransport)
+	handler := NewHandler(aggregator, WithReplayTransport(transport))
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/capture/start", nil))
+	if rec.Code >= 400 {
+		t.Fatalf("failed to start capture: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Capture the original request
+	ctx := collector.WithSessionIDs(context.Background(), []uuid.UUID{sessionID})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/todos", strings.NewReader(`{"title":"original"}`))
+	req.Header.Set("X-Test", "original")
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		t.Fatalf("failed to send original request: %v", err)
+	}
+	resp.Body.Close()
+
+	events := handler.sessions.Get(sessionID).GetEvents(10)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 captured event, got %d", len(events))
+	}
+	original := events[0]
+	replayURL := "/s/" + sessionID.String() + "/event/" + original.ID.String() + "/replay"
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, replayURL, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d for the editor, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "X-Test: original") {
+		t.Errorf("expected editor to be pre-filled with the headers, got %s", rec.Body.String())
+	}
+
+	form := url.Values{
+		"method":  {"put"},
+		"url":     {server.URL + "/todos/1"},
+		"headers": {"X-Test: edited\nContent-Type: application/json"},
+		"body":    {`{"title":"edited"}`},
+	}
+	req = httptest.NewRequest(http.MethodPost, replayURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d for the replay, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if len(received) != 2 || received[1] != `PUT edited {"title":"edited"}` {
+		t.Errorf("expected the edited request to be sent, got %v", received)
+	}
+
+	events = handler.sessions.Get(sessionID).GetEvents(10)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 captured events, got %d", len(events))
+	}
+	replayed, ok := events[1].Data.(collector.HTTPClientRequest)
+	if !ok || replayed.DerivedFrom != original.ID || replayed.StatusCode != http.StatusAccepted {
+		t.Errorf("expected replayed request derived from the original, got %#v", events[1].Data)
+	}
+	if location := rec.Header().Get("HX-Push-Url"); !strings.Contains(location, events[1].ID.String()) {
+		t.Errorf("expected URL of the replayed event to be pushed, got %q", location)
+	}
+
+	// Invalid headers re-render the editor
+	form.Set("headers", "not a header")
+	req = httptest.NewRequest(http.MethodPost, replayURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid header in line 1") {
+		t.Errorf("expected status %d with the error, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandler_ImportRequest(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator, WithReplayTransport(http.DefaultTransport))
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	original := &collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.HTTPClientRequest{Method: http.MethodGet, URL: "https://example.com/"}}
+	storage.Add(original)
+	importURL := "/s/" + sessionID.String() + "/event/" + original.ID.String() + "/replay/import"
+
+	form := url.Values{"command": {`curl -X POST https://example.com/api/todos -H 'X-Test: imported' -d '{"title":"imported"}'`}}
+	req := httptest.NewRequest(http.MethodPost, importURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d for the import, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	for _, expected := range []string{`value="POST"`, `value="https://example.com/api/todos"`, "X-Test: imported", "{&#34;title&#34;:&#34;imported&#34;}"} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("expected editor to be filled with %s, got %s", expected, rec.Body.String())
+		}
+	}
+
+	// An invalid command re-renders the editor with the error, keeping the command
+	form = url.Values{"command": {"wget https://example.com/"}, "method": {"GET"}}
+	req = httptest.NewRequest(http.MethodPost, importURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "unsupported command") || !strings.Contains(rec.Body.String(), "wget https://example.com/") {
+		t.Errorf("expected status %d with the error, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,461 @@
+package dashboard
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// importedRequest is a request parsed from a curl or HTTPie command line
+type importedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+}
+
+// parseRequestCommand parses a curl or HTTPie (http / https) command line, e.g. copied from API docs or "Copy as cURL"
+// of the browser devtools, into a request
+func parseRequestCommand(command string) (importedRequest, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return importedRequest{}, err
+	}
+	if len(args) == 0 {
+		return importedRequest{}, errors.New("empty command")
+	}
+
+	switch args[0] {
+	case "curl":
+		return parseCurlArgs(args[1:])
+	case "http", "https":
+		return parseHTTPieArgs(args[0], args[1:])
+	}
+	return importedRequest{}, fmt.Errorf("unsupported command %q, expected curl, http or https", args[0])
+}
+
+// curlValueOptions are options of curl that take a value which is not needed for the request
+var curlValueOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true, "--retry": true,
+	"-w": true, "--write-out": true, "-x": true, "--proxy": true, "--cacert": true, "--cert": true, "--key": true,
+	"-c": true, "--cookie-jar": true, "-r": true, "--range": true, "--resolve": true, "--max-redirs": true,
+}
+
+// curlShortValueOptions are short curl options that take a value, it may be attached (e.g. -XPOST)
+const curlShortValueOptions = "XHdubAeFomwxcrT"
+
+func parseCurlArgs(args []string) (importedRequest, error) {
+	req := importedRequest{Header: http.Header{}}
+	var (
+		data   []string
+		method string
+		get    bool
+		head   bool
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := arg, "", false
+
+		// Split attached values of short options (-XPOST, -H'Accept: text/html') and long options (--request=POST)
+		if strings.HasPrefix(arg, "--") {
+			if n, v, ok := strings.Cut(arg, "="); ok {
+				name, value, hasValue = n, v, true
+			}
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 2 {
+			if strings.ContainsRune(curlShortValueOptions, rune(arg[1])) {
+				name, value, hasValue = arg[:2], arg[2:], true
+			} else {
+				// Combined flags without values, e.g. -sSL, the last one may take a value (-sXPOST is not supported)
+				name = "-" + arg[len(arg)-1:]
+			}
+		}
+
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("missing value for option %s", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "-X", "--request":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			key, headerValue, ok := strings.Cut(v, ":")
+			if !ok {
+				return req, fmt.Errorf("invalid header %q", v)
+			}
+			req.Header.Add(strings.TrimSpace(key), strings.TrimSpace(headerValue))
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			data = append(data, v)
+		case "--data-urlencode":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			if key, content, ok := strings.Cut(v, "="); ok {
+				data = append(data, key+"="+url.QueryEscape(content))
+			} else {
+				data = append(data, url.QueryEscape(v))
+			}
+		case "--json":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			data = append(data, v)
+			setDefaultHeader(req.Header, "Content-Type", "application/json")
+			setDefaultHeader(req.Header, "Accept", "application/json")
+		case "-u", "--user":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(v)))
+		case "-b", "--cookie":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			req.Header.Add("Cookie", v)
+		case "-A", "--user-agent":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			req.Header.Set("User-Agent", v)
+		case "-e", "--referer":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			req.Header.Set("Referer", v)
+		case "--url":
+			v, err := nextValue()
+			if err != nil {
+				return req, err
+			}
+			req.URL = v
+		case "-F", "--form", "-T", "--upload-file":
+			return req, fmt.Errorf("file uploads with %s are not supported", name)
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			head = true
+		default:
+			if curlValueOptions[name] {
+				if _, err := nextValue(); err != nil {
+					return req, err
+				}
+				continue
+			}
+			if strings.HasPrefix(arg, "-") {
+				// Flags without effect on the request, e.g. --compressed, -k or -L
+				continue
+			}
+			if req.URL != "" {
+				return req, fmt.Errorf("unexpected argument %q", arg)
+			}
+			req.URL = arg
+		}
+	}
+
+	if req.URL == "" {
+		return req, errors.New("missing URL")
+	}
+	if !strings.Contains(req.URL, "://") {
+		req.URL = "http://" + req.URL
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + body
+	case body != "":
+		req.Body = body
+		setDefaultHeader(req.Header, "Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	switch {
+	case method != "":
+		req.Method = method
+	case head:
+		req.Method = http.MethodHead
+	case req.Body != "":
+		req.Method = http.MethodPost
+	default:
+		req.Method = http.MethodGet
+	}
+
+	return req, nil
+}
+
+func parseHTTPieArgs(command string, args []string) (importedRequest, error) {
+	req := importedRequest{Header: http.Header{}}
+	var (
+		form        bool
+		fields      []string // JSON object members or form values in order
+		query       = url.Values{}
+		positionals []string
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-f" || arg == "--form":
+			form = true
+		case arg == "-j" || arg == "--json":
+			form = false
+		case arg == "-a" || arg == "--auth":
+			if i+1 >= len(args) {
+				return req, fmt.Errorf("missing value for option %s", arg)
+			}
+			i++
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(args[i])))
+		case strings.HasPrefix(arg, "--auth="):
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(strings.TrimPrefix(arg, "--auth="))))
+		case strings.HasPrefix(arg, "-"):
+			// Output and other options without effect on the request, e.g. --verbose or --follow
+		default:
+			positionals = append(positionals, arg)
+		}
+	}
+
+	if len(positionals) == 0 {
+		return req, errors.New("missing URL")
+	}
+	if isHTTPMethod(positionals[0]) && len(positionals) > 1 {
+		req.Method = positionals[0]
+		positionals = positionals[1:]
+	}
+	req.URL = httpieURL(command, positionals[0])
+
+	for _, item := range positionals[1:] {
+		sep, index := httpieItemSeparator(item)
+		if index < 0 {
+			return req, fmt.Errorf("invalid request item %q", item)
+		}
+		key, value := item[:index], item[index+len(sep):]
+		switch sep {
+		case "==":
+			query.Add(key, value)
+		case ":=":
+			if form {
+				return req, fmt.Errorf("raw JSON field %q cannot be sent as form", key)
+			}
+			if !json.Valid([]byte(value)) {
+				return req, fmt.Errorf("invalid JSON in field %q", key)
+			}
+			fields = append(fields, jsonString(key)+":"+value)
+		case "=":
+			if form {
+				fields = append(fields, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			} else {
+				fields = append(fields, jsonString(key)+":"+jsonString(value))
+			}
+		case ":":
+			req.Header.Add(key, value)
+		case "@":
+			return req, fmt.Errorf("file upload of %q is not supported", key)
+		}
+	}
+
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + query.Encode()
+	}
+
+	if len(fields) > 0 {
+		if form {
+			req.Body = strings.Join(fields, "&")
+			setDefaultHeader(req.Header, "Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		} else {
+			req.Body = "{" + strings.Join(fields, ",") + "}"
+			setDefaultHeader(req.Header, "Content-Type", "application/json")
+			setDefaultHeader(req.Header, "Accept", "application/json, */*;q=0.5")
+		}
+	}
+
+	if req.Method == "" {
+		req.Method = http.MethodGet
+		if req.Body != "" {
+			req.Method = http.MethodPost
+		}
+	}
+
+	return req, nil
+}
+
+// httpieURL completes the URL shorthands of HTTPie, e.g. ":3000/api" for localhost or a missing scheme
+func httpieURL(command string, rawURL string) string {
+	if strings.HasPrefix(rawURL, ":") {
+		rawURL = "localhost" + rawURL
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = command + "://" + rawURL
+	}
+	return rawURL
+}
+
+// httpieItemSeparator finds the first separator of a HTTPie request item, longer separators win at the same position
+func httpieItemSeparator(item string) (string, int) {
+	for i := range len(item) {
+		for _, sep := range []string{"==", ":=", "=", ":", "@"} {
+			if strings.HasPrefix(item[i:], sep) {
+				return sep, i
+			}
+		}
+	}
+	return "", -1
+}
+
+func isHTTPMethod(s string) bool {
+	switch s {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func setDefaultHeader(header http.Header, key, value string) {
+	if header.Get(key) == "" {
+		header.Set(key, value)
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// splitShellWords splits a command line into arguments like a POSIX shell, supporting single, double and ANSI-C ($'...')
+// quotes and line continuations
+func splitShellWords(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+	)
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\' && i+1 < len(command):
+			i++
+			if command[i] != '\n' {
+				current.WriteByte(command[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			n, err := readANSICQuoted(command[i+2:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 2
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("\"\\$`\n", command[i+1]) >= 0 {
+					i++
+					if command[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(command[i])
+			}
+			if i >= len(command) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// readANSICQuoted reads the content of a $'...' string up to the closing quote and returns the number of bytes read
+// including the quote
+func readANSICQuoted(s string, sb *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'u':
+			var r rune
+			if i+4 < len(s) {
+				if _, err := fmt.Sscanf(s[i+1:i+5], "%04x", &r); err == nil {
+					sb.WriteRune(r)
+					i += 4
+					continue
+				}
+			}
+			sb.WriteString(`\u`)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return 0, errors.New("unterminated $' quote")
+}
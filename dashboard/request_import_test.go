@@ -0,0 +1,124 @@
+package dashboard
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected importedRequest
+	}{
+		{
+			name:    "curl get",
+			command: "curl https://example.com/api/todos",
+			expected: importedRequest{
+				Method: http.MethodGet,
+				URL:    "https://example.com/api/todos",
+				Header: http.Header{},
+			},
+		},
+		{
+			name: "curl copied from devtools",
+			command: `curl 'https://example.com/api/todos' \
+  -H 'accept: application/json' \
+  -H 'content-type: application/json' \
+  --data-raw $'{"title":"Buy milk\'s"}' \
+  --compressed`,
+			expected: importedRequest{
+				Method: http.MethodPost,
+				URL:    "https://example.com/api/todos",
+				Header: http.Header{"Accept": {"application/json"}, "Content-Type": {"application/json"}},
+				Body:   `{"title":"Buy milk's"}`,
+			},
+		},
+		{
+			name:    "curl with attached method and form data",
+			command: `curl -sSL -XPUT -d name=Alice -d "age=42" localhost:8080/users/1`,
+			expected: importedRequest{
+				Method: http.MethodPut,
+				URL:    "http://localhost:8080/users/1",
+				Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+				Body:   "name=Alice&age=42",
+			},
+		},
+		{
+			name:    "curl get with data and basic auth",
+			command: `curl -G --data-urlencode "q=hello world" -u admin:secret https://example.com/search`,
+			expected: importedRequest{
+				Method: http.MethodGet,
+				URL:    "https://example.com/search?q=hello+world",
+				Header: http.Header{"Authorization": {"Basic YWRtaW46c2VjcmV0"}},
+			},
+		},
+		{
+			name:    "curl json",
+			command: `curl --json '{"done":true}' --request=PATCH https://example.com/api/todos/1`,
+			expected: importedRequest{
+				Method: http.MethodPatch,
+				URL:    "https://example.com/api/todos/1",
+				Header: http.Header{"Content-Type": {"application/json"}, "Accept": {"application/json"}},
+				Body:   `{"done":true}`,
+			},
+		},
+		{
+			name:    "httpie json",
+			command: `http POST :3000/api/todos title="Buy milk" done:=false X-Request-Id:abc page==2`,
+			expected: importedRequest{
+				Method: http.MethodPost,
+				URL:    "http://localhost:3000/api/todos?page=2",
+				Header: http.Header{"X-Request-Id": {"abc"}, "Content-Type": {"application/json"}, "Accept": {"application/json, */*;q=0.5"}},
+				Body:   `{"title":"Buy milk","done":false}`,
+			},
+		},
+		{
+			name:    "httpie form with default method",
+			command: `https --form example.com/login user=alice password=s3cret`,
+			expected: importedRequest{
+				Method: http.MethodPost,
+				URL:    "https://example.com/login",
+				Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}},
+				Body:   "user=alice&password=s3cret",
+			},
+		},
+		{
+			name:    "httpie get",
+			command: `http -v example.com/api/todos Authorization:"Bearer token"`,
+			expected: importedRequest{
+				Method: http.MethodGet,
+				URL:    "http://example.com/api/todos",
+				Header: http.Header{"Authorization": {"Bearer token"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseRequestCommand(tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+}
+
+func TestParseRequestCommand_Errors(t *testing.T) {
+	for _, command := range []string{
+		"",
+		"wget https://example.com",
+		"curl",
+		"curl -H",
+		"curl 'https://example.com",
+		"curl -F file=@upload.txt https://example.com",
+		"http example.com data@file.json",
+		"http example.com invalid",
+		"http example.com field:={invalid",
+	} {
+		_, err := parseRequestCommand(command)
+		assert.Error(t, err, command)
+	}
+}
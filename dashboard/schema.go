@@ -0,0 +1,388 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Formats of a schema inferred from captured bodies
+const (
+	schemaFormatJSONSchema = "json-schema"
+	schemaFormatGo         = "go"
+)
+
+// schemaNode is the inferred shape of JSON values at one position of the documents
+type schemaNode struct {
+	// types are the JSON types seen ("object", "array", "string", "integer", "number", "boolean", "null")
+	types []string
+	// properties of objects in order of appearance
+	properties     []string
+	propertyNodes  map[string]*schemaNode
+	propertyCounts map[string]int
+	// objects is the number of objects seen to determine required properties
+	objects int
+	// items is the shape of array elements, nil if only empty arrays were seen
+	items *schemaNode
+}
+
+// inferSchema infers the shape of one or more JSON documents, e.g. bodies of the same endpoint. Documents that are not
+// valid JSON are skipped and counted, an error is returned if no document is valid.
+func inferSchema(documents [][]byte) (root *schemaNode, skipped int, err error) {
+	root = &schemaNode{}
+	for _, document := range documents {
+		decoder := json.NewDecoder(bytes.NewReader(document))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			skipped++
+			continue
+		}
+		root.add(value)
+	}
+	if skipped == len(documents) {
+		return nil, skipped, errors.New("no captured body is valid JSON")
+	}
+	return root, skipped, nil
+}
+
+func (n *schemaNode) addType(t string) {
+	if !slices.Contains(n.types, t) {
+		n.types = append(n.types, t)
+	}
+}
+
+func (n *schemaNode) add(value any) {
+	switch v := value.(type) {
+	case nil:
+		n.addType("null")
+	case bool:
+		n.addType("boolean")
+	case string:
+		n.addType("string")
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			n.addType("integer")
+		} else {
+			n.addType("number")
+		}
+	case []any:
+		n.addType("array")
+		for _, item := range v {
+			if n.items == nil {
+				n.items = &schemaNode{}
+			}
+			n.items.add(item)
+		}
+	case map[string]any:
+		n.addType("object")
+		n.objects++
+		if n.propertyNodes == nil {
+			n.propertyNodes = make(map[string]*schemaNode)
+			n.propertyCounts = make(map[string]int)
+		}
+		for _, key := range sortedKeys(v) {
+			property, exists := n.propertyNodes[key]
+			if !exists {
+				property = &schemaNode{}
+				n.propertyNodes[key] = property
+				n.properties = append(n.properties, key)
+			}
+			n.propertyCounts[key]++
+			property.add(v[key])
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// required returns true if the property was present in all objects
+func (n *schemaNode) required(property string) bool {
+	return n.propertyCounts[property] == n.objects
+}
+
+// nonNullTypes returns the types without null, integer is merged into number if both were seen
+func (n *schemaNode) nonNullTypes() []string {
+	types := slices.DeleteFunc(slices.Clone(n.types), func(t string) bool { return t == "null" })
+	if slices.Contains(types, "number") {
+		types = slices.DeleteFunc(types, func(t string) bool { return t == "integer" })
+	}
+	return types
+}
+
+func (n *schemaNode) nullable() bool {
+	return slices.Contains(n.types, "null")
+}
+
+// jsonSchema renders the node as a JSON Schema (draft 2020-12) object
+func (n *schemaNode) jsonSchema() map[string]any {
+	schema := map[string]any{}
+
+	types := n.nonNullTypes()
+	if n.nullable() {
+		types = append(types, "null")
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+
+	if slices.Contains(types, "object") {
+		properties := make(map[string]any, len(n.properties))
+		var required []string
+		for _, key := range n.properties {
+			properties[key] = n.propertyNodes[key].jsonSchema()
+			if n.required(key) {
+				required = append(required, key)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	if slices.Contains(types, "array") && n.items != nil {
+		schema["items"] = n.items.jsonSchema()
+	}
+
+	return schema
+}
+
+// jsonSchemaDocument renders the inferred schema as an indented JSON Schema document
+func jsonSchemaDocument(root *schemaNode, title string) ([]byte, error) {
+	schema := root.jsonSchema()
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = title
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// goTypeSource renders the inferred schema as a Go type definition with nested anonymous structs
+func goTypeSource(root *schemaNode, typeName string) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("package types\n\n")
+	fmt.Fprintf(&sb, "type %s %s\n", typeName, root.goType(false))
+	return format.Source([]byte(sb.String()))
+}
+
+func (n *schemaNode) goType(optional bool) string {
+	types := n.nonNullTypes()
+	if len(types) != 1 {
+		return "any"
+	}
+
+	var goType string
+	switch types[0] {
+	case "string":
+		goType = "string"
+	case "integer":
+		goType = "int64"
+	case "number":
+		goType = "float64"
+	case "boolean":
+		goType = "bool"
+	case "array":
+		if n.items == nil {
+			return "[]any"
+		}
+		return "[]" + n.items.goType(false)
+	case "object":
+		if len(n.properties) == 0 {
+			return "map[string]any"
+		}
+		var sb strings.Builder
+		sb.WriteString("struct {\n")
+		usedNames := make(map[string]bool)
+		for _, key := range n.properties {
+			name := goFieldName(key, usedNames)
+			property := n.propertyNodes[key]
+			tag := key
+			if !n.required(key) {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&sb, "%s %s `json:%q`\n", name, property.goType(!n.required(key)), tag)
+		}
+		sb.WriteString("}")
+		goType = sb.String()
+		if optional || n.nullable() {
+			return "*" + goType
+		}
+		return goType
+	}
+
+	if optional || n.nullable() {
+		return "*" + goType
+	}
+	return goType
+}
+
+// goFieldName turns a JSON property name into an exported Go identifier, e.g. "created_at" into "CreatedAt"
+func goFieldName(key string, usedNames map[string]bool) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Field" + name
+	}
+	for _, initialism := range []string{"Id", "Url", "Uri", "Api", "Http", "Json", "Uuid"} {
+		if strings.HasSuffix(name, initialism) {
+			name = strings.TrimSuffix(name, initialism) + strings.ToUpper(initialism)
+		}
+	}
+
+	unique := name
+	for i := 2; usedNames[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	usedNames[unique] = true
+	return unique
+}
+
+// endpointKey identifies the endpoint of a captured request to collect the bodies of all its requests, it uses the
+// route pattern of incoming requests if known and the URL without query of outgoing requests
+func endpointKey(event *collector.Event) (string, bool) {
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		path := data.RoutePattern
+		if path == "" {
+			path = data.Path
+		}
+		return "server " + data.Method + " " + path, true
+	case collector.HTTPClientRequest:
+		u, err := url.Parse(data.URL)
+		if err != nil {
+			return "", false
+		}
+		u.RawQuery, u.Fragment = "", ""
+		return "client " + data.Method + " " + u.String(), true
+	}
+	return "", false
+}
+
+// endpointBodies returns the non-empty JSON request or response bodies of all captured requests (including nested
+// events) of the endpoint grouped by the status class of the response (see statusClass), since e.g. error responses
+// have a different shape. Bodies that were not captured completely (truncated, evicted or not read to the end) are
+// skipped, they would not be valid JSON.
+func endpointBodies(events []*collector.Event, key string, response bool) map[string][][]byte {
+	bodies := make(map[string][][]byte)
+	for _, event := range events {
+		for _, e := range event.Visit() {
+			if k, ok := endpointKey(e); !ok || k != key {
+				continue
+			}
+			body, contentType, statusCode := requestBody(e, response)
+			if body == nil || body.Size() == 0 || !isJSONContentType(contentType) {
+				continue
+			}
+			if !body.IsFullyCaptured() || body.IsTruncated() || body.IsEvicted() {
+				continue
+			}
+			class := statusClass(statusCode)
+			bodies[class] = append(bodies[class], body.Bytes())
+		}
+	}
+	return bodies
+}
+
+// requestBody returns the request or response body of a captured request with its content type and the status code
+// of the response
+func requestBody(event *collector.Event, response bool) (*collector.Body, string, int) {
+	var (
+		requestHeaders, responseHeaders http.Header
+		requestBody, responseBody       *collector.Body
+		statusCode                      int
+	)
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		requestHeaders, responseHeaders, requestBody, responseBody = data.RequestHeaders, data.ResponseHeaders, data.RequestBody, data.ResponseBody
+		statusCode = data.StatusCode
+	case collector.HTTPClientRequest:
+		requestHeaders, responseHeaders, requestBody, responseBody = data.RequestHeaders, data.ResponseHeaders, data.RequestBody, data.ResponseBody
+		statusCode = data.StatusCode
+	default:
+		return nil, "", 0
+	}
+	if response {
+		return responseBody, responseHeaders.Get("Content-Type"), statusCode
+	}
+	return requestBody, requestHeaders.Get("Content-Type"), statusCode
+}
+
+// statusClass returns the class of a status code, e.g. "2xx" for 201, or "none" if there was no response
+func statusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return "none"
+	}
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// schemaTypeName derives a type name from the path of an endpoint, e.g. "TodosResponse" for GET /api/todos/{id}. The
+// status class is added if it is not "2xx", e.g. "Todos4xxResponse".
+func schemaTypeName(event *collector.Event, response bool, status string) string {
+	var path string
+	switch data := event.Data.(type) {
+	case collector.HTTPServerRequest:
+		path = data.Path
+		if data.RoutePattern != "" {
+			path = data.RoutePattern
+		}
+	case collector.HTTPClientRequest:
+		if u, err := url.Parse(data.URL); err == nil {
+			path = u.Path
+		}
+	}
+
+	name := ""
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if segment == "" || strings.HasPrefix(segment, "{") || strings.HasPrefix(segment, ":") || strings.IndexFunc(segment, unicode.IsLetter) < 0 {
+			continue
+		}
+		name = goFieldName(segment, map[string]bool{})
+		break
+	}
+	if status != "2xx" {
+		name += status
+	}
+
+	if response {
+		return name + "Response"
+	}
+	return name + "Request"
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInferSchema_JSONSchema(t *testing.T) {
+	root, _, err := inferSchema([][]byte{
+		[]byte(`{"id": 1, "title": "Buy milk", "tags": ["shopping"], "owner": {"name": "Alice"}}`),
+		[]byte(`{"id": 2, "title": "Write tests", "tags": [], "done_at": null, "owner": {"name": "Bob"}, "score": 1.5}`),
+	})
+	require.NoError(t, err)
+
+	document, err := jsonSchemaDocument(root, "GET /api/todos/{id}")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "GET /api/todos/{id}",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"title": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"owner": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]},
+			"done_at": {"type": "null"},
+			"score": {"type": "number"}
+		},
+		"required": ["id", "owner", "tags", "title"]
+	}`, string(document))
+}
+
+func TestInferSchema_GoType(t *testing.T) {
+	root, _, err := inferSchema([][]byte{
+		[]byte(`[{"id": 1, "user_id": "a", "price": 3, "meta": {"source": "web"}}]`),
+		[]byte(`[{"id": 2, "user_id": null, "price": 4.5}]`),
+	})
+	require.NoError(t, err)
+
+	source, err := goTypeSource(root, "OrdersResponse")
+	require.NoError(t, err)
+
+	assert.Equal(t, "package types\n\n"+
+		"type OrdersResponse []struct {\n"+
+		"\tID   int64 `json:\"id\"`\n"+
+		"\tMeta *struct {\n"+
+		"\t\tSource string `json:\"source\"`\n"+
+		"\t} `json:\"meta,omitempty\"`\n"+
+		"\tPrice  float64 `json:\"price\"`\n"+
+		"\tUserID *string `json:\"user_id\"`\n"+
+		"}\n", string(source))
+}
+
+func TestInferSchema_InvalidJSON(t *testing.T) {
+	root, skipped, err := inferSchema([][]byte{[]byte(`{"id": 1}`), []byte(`<html>`)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, []string{"id"}, root.properties, "invalid bodies should be skipped")
+
+	_, skipped, err = inferSchema([][]byte{[]byte(`<html>`)})
+	assert.ErrorContains(t, err, "no captured body is valid JSON")
+	assert.Equal(t, 1, skipped)
+}
+
+func TestEndpointBodies(t *testing.T) {
+	serverRequest := func(path string, statusCode int, contentType string, body *collector.Body) *collector.Event {
+		t.Helper()
+
+		return &collector.Event{
+			ID: uuid.Must(uuid.NewV7()),
+			Data: collector.HTTPServerRequest{
+				Method:          http.MethodGet,
+				Path:            path,
+				RoutePattern:    "/api/todos/{id}",
+				StatusCode:      statusCode,
+				ResponseHeaders: http.Header{"Content-Type": {contentType}},
+				ResponseBody:    body,
+			},
+		}
+	}
+	first := serverRequest("/api/todos/1", http.StatusOK, "application/json", capturedBody(t, []byte(`{"id":1}`)))
+	second := serverRequest("/api/todos/2", http.StatusOK, "application/json; charset=utf-8", capturedBody(t, []byte(`{"id":2}`)))
+	notFound := serverRequest("/api/todos/3", http.StatusNotFound, "application/problem+json", capturedBody(t, []byte(`{"title":"not found"}`)))
+	text := serverRequest("/api/todos/4", http.StatusOK, "text/plain", capturedBody(t, []byte(`text`)))
+	// Only the first 4 bytes are captured
+	truncated := serverRequest("/api/todos/5", http.StatusOK, "application/json", collector.NewBody(io.NopCloser(strings.NewReader(`{"id":5}`)), 4))
+	_, _ = io.ReadAll(truncated.Data.(collector.HTTPServerRequest).ResponseBody)
+	other := &collector.Event{
+		ID: uuid.Must(uuid.NewV7()),
+		Data: collector.HTTPServerRequest{
+			Method:          http.MethodGet,
+			Path:            "/api/users",
+			StatusCode:      http.StatusOK,
+			ResponseHeaders: http.Header{"Content-Type": {"application/json"}},
+			ResponseBody:    capturedBody(t, []byte(`[]`)),
+		},
+	}
+
+	key, ok := endpointKey(first)
+	require.True(t, ok)
+	assert.Equal(t, "server GET /api/todos/{id}", key)
+
+	bodies := endpointBodies([]*collector.Event{first, second, notFound, text, truncated, other}, key, true)
+	assert.Equal(t, map[string][][]byte{
+		"2xx": {[]byte(`{"id":1}`), []byte(`{"id":2}`)},
+		"4xx": {[]byte(`{"title":"not found"}`)},
+	}, bodies, "bodies should be grouped by status class without incomplete bodies")
+	assert.Empty(t, endpointBodies([]*collector.Event{first}, key, false))
+
+	assert.Equal(t, "TodosResponse", schemaTypeName(first, true, "2xx"))
+	assert.Equal(t, "Todos4xxResponse", schemaTypeName(notFound, true, "4xx"))
+	assert.Equal(t, "UsersRequest", schemaTypeName(other, false, "2xx"))
+}
@@ -0,0 +1,65 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxSessionImportSize limits the size of files imported into a session
+const maxSessionImportSize = 50 << 20
+
+// decodeSessionImport decodes the events of an imported file. A JSON object with a "log" is decoded as HTTP Archive
+// (HAR), anything else as newline delimited JSON exported with collector.JSONEventCodec (e.g. by a FileSink).
+func decodeSessionImport(data []byte) ([]*collector.Event, error) {
+	var har struct {
+		Log json.RawMessage `json:"log"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&har); err == nil && har.Log != nil {
+		return collector.DecodeHAR(bytes.NewReader(data))
+	}
+
+	var events []*collector.Event
+	decoder := collector.JSONEventCodec.NewDecoder(bytes.NewReader(data))
+	for {
+		event, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil, errors.New("no events found, expected a devlog NDJSON export or a HAR file")
+	}
+	return events, nil
+}
+
+// importSessionName returns the name of a session imported from filename, it falls back to the time of the import
+// if there is no usable file name
+func importSessionName(filename string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if name == "" || name == "." || len(name) > maxSessionNameLength {
+		return "Import " + time.Now().Format("15:04:05")
+	}
+	return name
+}
+
+// importErrorStatus returns the status code for an error reading an imported file
+func importErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
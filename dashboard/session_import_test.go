@@ -0,0 +1,103 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestDecodeSessionImport(t *testing.T) {
+	var ndjson bytes.Buffer
+	encoder := collector.JSONEventCodec.NewEncoder(&ndjson)
+	for _, data := range []string{"first", "second"} {
+		now := time.Now()
+		if err := encoder.Encode(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: data, Start: now, End: now}); err != nil {
+			t.Fatalf("failed to encode event: %v", err)
+		}
+	}
+
+	events, err := decodeSessionImport(ndjson.Bytes())
+	if err != nil {
+		t.Fatalf("failed to decode NDJSON: %v", err)
+	}
+	if len(events) != 2 || events[1].Data != "second" {
+		t.Errorf("expected 2 decoded events, got %v", events)
+	}
+
+	events, err = decodeSessionImport([]byte(`{"log": {"entries": [{"startedDateTime": "2025-03-01T10:00:00Z", "request": {"method": "GET", "url": "https://example.com/"}, "response": {"status": 200}}]}}`))
+	if err != nil {
+		t.Fatalf("failed to decode HAR: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event from HAR, got %d", len(events))
+	}
+	if request, ok := events[0].Data.(collector.HTTPClientRequest); !ok || request.URL != "https://example.com/" {
+		t.Errorf("expected an outgoing request from HAR, got %#v", events[0].Data)
+	}
+
+	for _, data := range []string{"", "not json", `{"log": "invalid"}`} {
+		if _, err := decodeSessionImport([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestHandler_ImportSession(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "checkout-bug.har")
+	_, _ = file.Write([]byte(`{"log": {"entries": [{"startedDateTime": "2025-03-01T10:00:00Z", "time": 12, "request": {"method": "GET", "url": "https://example.com/"}, "response": {"status": 200}}]}}`))
+	form.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	req := httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/sessions/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var info SessionInfo
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Name != "checkout-bug" {
+		t.Errorf("expected session to be named after the file, got %q", info.Name)
+	}
+	if info.Capturing || !info.ReadOnly {
+		t.Errorf("expected a read-only session that is not capturing, got %+v", info)
+	}
+	if info.EventCount != 1 {
+		t.Errorf("expected 1 imported event, got %d", info.EventCount)
+	}
+
+	// Capturing can't be started in an imported session
+	req = httptest.NewRequest(http.MethodPost, "/s/"+info.SessionID.String()+"/capture/start", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status %d when starting capture, got %d", http.StatusConflict, rec.Code)
+	}
+
+	// Invalid files are rejected
+	req = httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/sessions/import", strings.NewReader("not json"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an invalid file, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
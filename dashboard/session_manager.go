@@ -1,8 +1,14 @@
 package dashboard
 
 import (
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,10 +20,62 @@ import (
 // ErrMaxSessionsReached is returned when the maximum number of sessions has been reached
 var ErrMaxSessionsReached = errors.New("maximum number of sessions reached")
 
+// ErrSessionManagerClosed is returned when a session is created after the session manager was closed
+var ErrSessionManagerClosed = errors.New("session manager closed")
+
 // sessionState tracks a user's capture session
 type sessionState struct {
-	storageID  uuid.UUID
-	lastActive time.Time
+	storageID      uuid.UUID
+	name           string
+	browserID      uuid.UUID // browser that started the session, uuid.Nil if unknown
+	lastActive     time.Time
+	pinnedUntil    time.Time // the session is not cleaned up while idle until then, zero if not pinned
+	readOnly       bool      // the events were imported, capturing can't be started
+	binToken       string    // public token of the request bin of the session, it does not reveal the session ID
+	sseConnections int
+	prefs          SessionPreferences
+	notifications  *notificationWatcher
+}
+
+// SessionPreferences are the settings of the dashboard of a session. They are kept for a while after an idle session
+// was cleaned up, so they are restored when the dashboard reconnects and recreates the session.
+type SessionPreferences struct {
+	Display DisplayPreferences
+	// ErrorAlerts alerts about errors (5xx responses, panics, error logs) in the dashboard
+	ErrorAlerts bool
+	// UI is a JSON object with the state of the dashboard in the browser (e.g. the filter of the event list), it is
+	// stored as is and passed back when rendering the dashboard
+	UI string
+}
+
+// retainedPreferences are the preferences of a cleaned up session until they expire
+type retainedPreferences struct {
+	prefs   SessionPreferences
+	expires time.Time
+}
+
+// DisplayPreferences control how the dashboard of a session renders events
+type DisplayPreferences struct {
+	// AbsoluteTimes shows absolute timestamps instead of timestamps relative to now (e.g. "2 seconds ago")
+	AbsoluteTimes bool
+	// TimeZone for absolute timestamps, nil uses the time zone of the server
+	TimeZone *time.Location
+}
+
+// SessionInfo describes an active capture session for the session list
+type SessionInfo struct {
+	SessionID      uuid.UUID `json:"sessionId"`
+	Name           string    `json:"name,omitempty"`
+	Mode           string    `json:"mode"`
+	Capturing      bool      `json:"capturing"`
+	EventCount     int       `json:"eventCount"`
+	MemoryBytes    uint64    `json:"memoryBytes"`
+	LastActive     time.Time `json:"lastActive"`
+	SSEConnections int       `json:"sseConnections"`
+	// PinnedUntil is the time until the session is kept while no dashboard is connected, nil if it is not pinned
+	PinnedUntil *time.Time `json:"pinnedUntil,omitempty"`
+	// ReadOnly is true for a session with imported events, it can't capture
+	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
 // SessionManager manages capture sessions and their associated storages.
@@ -27,10 +85,20 @@ type SessionManager struct {
 
 	sessions   map[uuid.UUID]*sessionState
 	sessionsMu sync.RWMutex
-
-	storageCapacity uint64
-	idleTimeout     time.Duration
-	maxSessions     int
+	// retained holds the preferences of cleaned up sessions, guarded by sessionsMu
+	retained map[uuid.UUID]retainedPreferences
+	// binTokens maps the tokens of request bins to their sessions, guarded by sessionsMu
+	binTokens map[string]uuid.UUID
+
+	storageCapacity      uint64
+	idleTimeout          time.Duration
+	pinnedMaxAge         time.Duration
+	preferencesRetention time.Duration
+	maxSessions          int
+	archiveDir           string
+	archiveMaxBytes      int64
+	closed               bool
+	logger               *slog.Logger
 
 	cleanupCtx       context.Context
 	cleanupCtxCancel context.CancelFunc
@@ -41,7 +109,17 @@ type SessionManagerOptions struct {
 	EventAggregator *collector.EventAggregator
 	StorageCapacity uint64
 	IdleTimeout     time.Duration
-	MaxSessions     int // 0 means unlimited
+	// PinnedMaxAge is how long a pinned session is kept at most while no dashboard is connected
+	PinnedMaxAge time.Duration
+	// PreferencesRetention is how long the preferences of an idle session are kept after it was cleaned up
+	PreferencesRetention time.Duration
+	MaxSessions          int // 0 means unlimited
+	// ArchiveDir is the directory for the archives of evicted events of the sessions, empty disables archival
+	ArchiveDir string
+	// ArchiveMaxBytes limits the compressed size of the archive of each session (0 = collector.DefaultArchiveMaxBytes)
+	ArchiveMaxBytes int64
+	// Logger is used for internal logging (optional, defaults to the logger of the aggregator)
+	Logger *slog.Logger
 }
 
 // NewSessionManager creates a new SessionManager and starts the cleanup goroutine
@@ -56,16 +134,38 @@ func NewSessionManager(opts SessionManagerOptions) *SessionManager {
 		idleTimeout = DefaultSessionIdleTimeout
 	}
 
+	pinnedMaxAge := opts.PinnedMaxAge
+	if pinnedMaxAge == 0 {
+		pinnedMaxAge = DefaultPinnedSessionMaxAge
+	}
+
+	preferencesRetention := opts.PreferencesRetention
+	if preferencesRetention == 0 {
+		preferencesRetention = DefaultPreferencesRetention
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = opts.EventAggregator.Logger()
+	}
+
 	cleanupCtx, cleanupCtxCancel := context.WithCancel(context.Background())
 
 	sm := &SessionManager{
-		eventAggregator:  opts.EventAggregator,
-		sessions:         make(map[uuid.UUID]*sessionState),
-		storageCapacity:  storageCapacity,
-		idleTimeout:      idleTimeout,
-		maxSessions:      opts.MaxSessions,
-		cleanupCtx:       cleanupCtx,
-		cleanupCtxCancel: cleanupCtxCancel,
+		eventAggregator:      opts.EventAggregator,
+		sessions:             make(map[uuid.UUID]*sessionState),
+		retained:             make(map[uuid.UUID]retainedPreferences),
+		binTokens:            make(map[string]uuid.UUID),
+		storageCapacity:      storageCapacity,
+		idleTimeout:          idleTimeout,
+		pinnedMaxAge:         pinnedMaxAge,
+		preferencesRetention: preferencesRetention,
+		maxSessions:          opts.MaxSessions,
+		archiveDir:           opts.ArchiveDir,
+		archiveMaxBytes:      opts.ArchiveMaxBytes,
+		logger:               logger,
+		cleanupCtx:           cleanupCtx,
+		cleanupCtxCancel:     cleanupCtxCancel,
 	}
 
 	go sm.cleanupLoop()
@@ -97,32 +197,66 @@ func (sm *SessionManager) GetOrCreate(sessionID uuid.UUID, mode collector.Captur
 	sm.sessionsMu.Lock()
 	defer sm.sessionsMu.Unlock()
 
+	if sm.closed {
+		return nil, false, ErrSessionManagerClosed
+	}
+
 	// Check if already exists
 	if state, exists := sm.sessions[sessionID]; exists {
 		if storage := sm.eventAggregator.GetStorage(state.storageID); storage != nil {
 			return storage.(*collector.CaptureStorage), false, nil
 		}
 		// Storage was removed but session state remains - clean it up
-		delete(sm.sessions, sessionID)
+		sm.retainPreferences(sessionID, state)
+		sm.deleteSession(sessionID, state)
 	}
 
-	// Check max sessions limit
-	if sm.maxSessions > 0 && len(sm.sessions) >= sm.maxSessions {
+	// Check max sessions limit, making room by evicting an idle session if possible
+	if sm.maxSessions > 0 && len(sm.sessions) >= sm.maxSessions && !sm.evictIdleSession() {
 		return nil, false, ErrMaxSessionsReached
 	}
 
 	// Create new storage
 	storage := collector.NewCaptureStorage(sessionID, sm.storageCapacity, mode)
+	sm.setArchive(storage)
 	sm.eventAggregator.RegisterStorage(storage)
 
-	sm.sessions[sessionID] = &sessionState{
-		storageID:  storage.ID(),
-		lastActive: time.Now(),
+	state := &sessionState{
+		storageID:     storage.ID(),
+		lastActive:    time.Now(),
+		binToken:      newBinToken(),
+		notifications: newNotificationWatcher(sessionID, storage, sm.logger),
 	}
+	sm.binTokens[state.binToken] = sessionID
+	if retained, exists := sm.retained[sessionID]; exists {
+		if time.Now().Before(retained.expires) {
+			state.prefs = retained.prefs
+		}
+		delete(sm.retained, sessionID)
+	}
+	sm.sessions[sessionID] = state
+	sm.logger.Debug("Created session", "session", sessionID, "mode", mode)
 
 	return storage, true, nil
 }
 
+// setArchive archives the evicted events of a new storage if archival is enabled. The directory of the archive is named
+// after the storage, so a recreated session does not share the archive of its previous storage.
+func (sm *SessionManager) setArchive(storage *collector.CaptureStorage) {
+	if sm.archiveDir == "" {
+		return
+	}
+	archive, err := collector.NewEventArchive(filepath.Join(sm.archiveDir, storage.ID().String()), collector.EventArchiveOptions{
+		MaxBytes: sm.archiveMaxBytes,
+		Logger:   sm.logger.With("storage", storage.ID()),
+	})
+	if err != nil {
+		sm.logger.Warn("Failed to create archive, evicted events are discarded", "storage", storage.ID(), "error", err)
+		return
+	}
+	storage.SetArchive(archive)
+}
+
 // Delete removes a session and its storage
 func (sm *SessionManager) Delete(sessionID uuid.UUID) {
 	sm.sessionsMu.Lock()
@@ -133,13 +267,384 @@ func (sm *SessionManager) Delete(sessionID uuid.UUID) {
 		return
 	}
 
-	if storage := sm.eventAggregator.GetStorage(state.storageID); storage != nil {
-		storage.Close()
+	sm.deleteSession(sessionID, state)
+}
+
+// evictIdleSession deletes the least recently active session without a connected dashboard that is not pinned.
+// Returns false if all sessions are connected or pinned. Must be called with the lock held.
+func (sm *SessionManager) evictIdleSession() bool {
+	var (
+		evictID    uuid.UUID
+		evictState *sessionState
+	)
+	now := time.Now()
+	for sessionID, state := range sm.sessions {
+		if state.sseConnections > 0 || state.pinned(now) {
+			continue
+		}
+		if evictState == nil || state.lastActive.Before(evictState.lastActive) {
+			evictID, evictState = sessionID, state
+		}
+	}
+	if evictState == nil {
+		return false
 	}
+
+	sm.logger.Info("Evicted idle session, maximum number of sessions reached", "session", evictID, "maxSessions", sm.maxSessions)
+	sm.retainPreferences(evictID, evictState)
+	sm.deleteSession(evictID, evictState)
+	return true
+}
+
+// deleteSession closes the storage of a session and removes it. Must be called with the lock held.
+func (sm *SessionManager) deleteSession(sessionID uuid.UUID, state *sessionState) {
+	state.notifications.close()
+	// Unregister first, so no more events are added to the closed storage
+	storage := sm.eventAggregator.GetStorage(state.storageID)
 	sm.eventAggregator.UnregisterStorage(state.storageID)
+	if storage != nil {
+		storage.Close()
+	}
+	delete(sm.binTokens, state.binToken)
 	delete(sm.sessions, sessionID)
 }
 
+// retainPreferences keeps the preferences of a session that is cleaned up, so they are restored if the session is
+// recreated within the retention. Must be called with the lock held.
+func (sm *SessionManager) retainPreferences(sessionID uuid.UUID, state *sessionState) {
+	if state.prefs == (SessionPreferences{}) {
+		return
+	}
+	sm.retained[sessionID] = retainedPreferences{
+		prefs:   state.prefs,
+		expires: time.Now().Add(sm.preferencesRetention),
+	}
+}
+
+// Clear removes all events of a session but keeps it capturing.
+// Returns false if the session does not exist.
+func (sm *SessionManager) Clear(sessionID uuid.UUID) bool {
+	storage := sm.Get(sessionID)
+	if storage == nil {
+		return false
+	}
+	storage.Clear()
+	return true
+}
+
+// TrackSSEConnection registers an open SSE connection for a session.
+// The returned function must be called when the connection is closed.
+func (sm *SessionManager) TrackSSEConnection(sessionID uuid.UUID) (done func()) {
+	sm.sessionsMu.Lock()
+	state, exists := sm.sessions[sessionID]
+	if exists {
+		state.sseConnections++
+	}
+	sm.sessionsMu.Unlock()
+
+	return func() {
+		if !exists {
+			return
+		}
+		sm.sessionsMu.Lock()
+		state.sseConnections--
+		sm.sessionsMu.Unlock()
+	}
+}
+
+// Sessions returns information about all active sessions, most recently active first
+func (sm *SessionManager) Sessions() []SessionInfo {
+	return sm.sessionInfos(func(uuid.UUID, *sessionState) bool { return true })
+}
+
+// Info returns information about a session, false if it does not exist
+func (sm *SessionManager) Info(sessionID uuid.UUID) (SessionInfo, bool) {
+	infos := sm.sessionInfos(func(id uuid.UUID, _ *sessionState) bool { return id == sessionID })
+	if len(infos) == 0 {
+		return SessionInfo{}, false
+	}
+	return infos[0], true
+}
+
+// BrowserSessions returns information about the sessions started by a browser, most recently active first
+func (sm *SessionManager) BrowserSessions(browserID uuid.UUID) []SessionInfo {
+	if browserID == uuid.Nil {
+		return nil
+	}
+	return sm.sessionInfos(func(_ uuid.UUID, state *sessionState) bool { return state.browserID == browserID })
+}
+
+func (sm *SessionManager) sessionInfos(include func(sessionID uuid.UUID, state *sessionState) bool) []SessionInfo {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(sm.sessions))
+	for sessionID, state := range sm.sessions {
+		if !include(sessionID, state) {
+			continue
+		}
+		storage, ok := sm.eventAggregator.GetStorage(state.storageID).(*collector.CaptureStorage)
+		if !ok {
+			continue
+		}
+
+		stats := storage.Stats()
+		var pinnedUntil *time.Time
+		if state.pinned(time.Now()) {
+			until := state.pinnedUntil
+			pinnedUntil = &until
+		}
+		infos = append(infos, SessionInfo{
+			SessionID:      sessionID,
+			Name:           state.name,
+			Mode:           storage.CaptureMode().String(),
+			Capturing:      storage.IsCapturing(),
+			EventCount:     stats.EventCount,
+			MemoryBytes:    stats.MemoryBytes,
+			LastActive:     state.lastActive,
+			SSEConnections: state.sseConnections,
+			PinnedUntil:    pinnedUntil,
+			ReadOnly:       state.readOnly,
+		})
+	}
+
+	slices.SortFunc(infos, func(a, b SessionInfo) int {
+		return cmp.Compare(b.LastActive.UnixNano(), a.LastActive.UnixNano())
+	})
+
+	return infos
+}
+
+// Name returns the name of a session, an empty string if it is unnamed or does not exist
+func (sm *SessionManager) Name(sessionID uuid.UUID) string {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.name
+	}
+	return ""
+}
+
+// SetName names a session (e.g. after the bug it captures), an empty name removes it.
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetName(sessionID uuid.UUID, name string) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.name = name
+	return true
+}
+
+// SetBrowser assigns a session to the browser that started it, so the browser can switch between its sessions.
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetBrowser(sessionID uuid.UUID, browserID uuid.UUID) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.browserID = browserID
+	return true
+}
+
+// BinToken returns the token of the request bin of a session, an empty string if the session does not exist
+func (sm *SessionManager) BinToken(sessionID uuid.UUID) string {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.binToken
+	}
+	return ""
+}
+
+// BinSession returns the session of a request bin token, false if no session has the token
+func (sm *SessionManager) BinSession(token string) (uuid.UUID, bool) {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	sessionID, exists := sm.binTokens[token]
+	return sessionID, exists
+}
+
+// Preferences returns the preferences of a session. The retained preferences are returned for a session that was
+// cleaned up and the defaults if the session does not exist.
+func (sm *SessionManager) Preferences(sessionID uuid.UUID) SessionPreferences {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.prefs
+	}
+	if retained, exists := sm.retained[sessionID]; exists && time.Now().Before(retained.expires) {
+		return retained.prefs
+	}
+	return SessionPreferences{}
+}
+
+// Pinned returns whether a session is pinned, false if the session does not exist
+func (sm *SessionManager) Pinned(sessionID uuid.UUID) bool {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.pinned(time.Now())
+	}
+	return false
+}
+
+// SetPinned pins a session, so it is not cleaned up or evicted while no dashboard is connected (e.g. while the tab
+// sleeps) for at most the pinned max age, or unpins it. Returns false if the session does not exist.
+func (sm *SessionManager) SetPinned(sessionID uuid.UUID, pinned bool) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	if pinned {
+		state.pinnedUntil = time.Now().Add(sm.pinnedMaxAge)
+	} else {
+		state.pinnedUntil = time.Time{}
+	}
+	// The idle timeout starts again when the session is unpinned or the pin expires
+	state.lastActive = time.Now()
+	return true
+}
+
+// ReadOnly returns whether a session holds imported events and can't capture, false if the session does not exist
+func (sm *SessionManager) ReadOnly(sessionID uuid.UUID) bool {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.readOnly
+	}
+	return false
+}
+
+// SetReadOnly marks a session as read-only, so capturing can't be started (e.g. for imported events).
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetReadOnly(sessionID uuid.UUID, readOnly bool) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.readOnly = readOnly
+	return true
+}
+
+// DisplayPreferences returns the display preferences of a session, the defaults if the session does not exist
+func (sm *SessionManager) DisplayPreferences(sessionID uuid.UUID) DisplayPreferences {
+	return sm.Preferences(sessionID).Display
+}
+
+// SetDisplayPreferences stores the display preferences of a session.
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetDisplayPreferences(sessionID uuid.UUID, prefs DisplayPreferences) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.prefs.Display = prefs
+	return true
+}
+
+// ErrorAlerts returns whether the dashboard of a session alerts about errors, false if the session does not exist
+func (sm *SessionManager) ErrorAlerts(sessionID uuid.UUID) bool {
+	return sm.Preferences(sessionID).ErrorAlerts
+}
+
+// SetErrorAlerts enables or disables alerts about errors (5xx responses, panics, error logs) in the dashboard of a session.
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetErrorAlerts(sessionID uuid.UUID, enabled bool) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.prefs.ErrorAlerts = enabled
+	return true
+}
+
+// SetUIPreferences stores the UI state of the dashboard of a session, a JSON object.
+// Returns false if the session does not exist.
+func (sm *SessionManager) SetUIPreferences(sessionID uuid.UUID, ui string) bool {
+	sm.sessionsMu.Lock()
+	defer sm.sessionsMu.Unlock()
+
+	state, exists := sm.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	state.prefs.UI = ui
+	return true
+}
+
+// NotificationRules returns the notification rules of a session, false if the session does not exist
+func (sm *SessionManager) NotificationRules(sessionID uuid.UUID) ([]NotificationRule, bool) {
+	watcher := sm.notificationWatcher(sessionID)
+	if watcher == nil {
+		return nil, false
+	}
+	return watcher.Rules(), true
+}
+
+// AddNotificationRule adds a rule to notify about matching events of a session.
+// Returns false if the session does not exist.
+func (sm *SessionManager) AddNotificationRule(sessionID uuid.UUID, rule NotificationRule) bool {
+	watcher := sm.notificationWatcher(sessionID)
+	if watcher == nil {
+		return false
+	}
+	watcher.addRule(rule)
+	return true
+}
+
+// RemoveNotificationRule removes a notification rule of a session and reports whether it was found
+func (sm *SessionManager) RemoveNotificationRule(sessionID uuid.UUID, ruleID uuid.UUID) bool {
+	watcher := sm.notificationWatcher(sessionID)
+	if watcher == nil {
+		return false
+	}
+	return watcher.removeRule(ruleID)
+}
+
+// SubscribeNotifications returns a channel that receives browser notifications of a session, nil if the session does not exist
+func (sm *SessionManager) SubscribeNotifications(ctx context.Context, sessionID uuid.UUID) <-chan Notification {
+	watcher := sm.notificationWatcher(sessionID)
+	if watcher == nil {
+		return nil
+	}
+	return watcher.Subscribe(ctx)
+}
+
+func (sm *SessionManager) notificationWatcher(sessionID uuid.UUID) *notificationWatcher {
+	sm.sessionsMu.RLock()
+	defer sm.sessionsMu.RUnlock()
+
+	if state, exists := sm.sessions[sessionID]; exists {
+		return state.notifications
+	}
+	return nil
+}
+
 // UpdateActivity updates the last active time for a session
 func (sm *SessionManager) UpdateActivity(sessionID uuid.UUID) {
 	sm.sessionsMu.Lock()
@@ -166,20 +671,20 @@ func (sm *SessionManager) MaxSessions() int {
 	return sm.maxSessions
 }
 
-// Close shuts down the session manager and cleans up all sessions
+// Close shuts down the session manager and cleans up all sessions.
+// Open SSE streams end because the storages are closed, new sessions cannot be created afterwards.
+// It is safe to call Close multiple times.
 func (sm *SessionManager) Close() {
 	sm.cleanupCtxCancel()
 
 	sm.sessionsMu.Lock()
 	defer sm.sessionsMu.Unlock()
 
+	sm.closed = true
 	for sessionID, state := range sm.sessions {
-		if storage := sm.eventAggregator.GetStorage(state.storageID); storage != nil {
-			storage.Close()
-		}
-		sm.eventAggregator.UnregisterStorage(state.storageID)
-		delete(sm.sessions, sessionID)
+		sm.deleteSession(sessionID, state)
 	}
+	clear(sm.retained)
 }
 
 // cleanupLoop periodically checks for idle sessions and cleans them up
@@ -204,12 +709,36 @@ func (sm *SessionManager) cleanupIdleSessions() {
 	defer sm.sessionsMu.Unlock()
 
 	for sessionID, state := range sm.sessions {
-		if now.Sub(state.lastActive) > sm.idleTimeout {
-			if storage := sm.eventAggregator.GetStorage(state.storageID); storage != nil {
-				storage.Close()
-			}
-			sm.eventAggregator.UnregisterStorage(state.storageID)
-			delete(sm.sessions, sessionID)
+		if state.pinned(now) {
+			continue
+		}
+		// A pin counts as activity, so the session is kept for the idle timeout after the pin expired
+		lastActive := state.lastActive
+		if state.pinnedUntil.After(lastActive) {
+			lastActive = state.pinnedUntil
+		}
+		if now.Sub(lastActive) > sm.idleTimeout {
+			sm.logger.Debug("Cleaned up idle session", "session", sessionID, "idle", now.Sub(lastActive))
+			sm.retainPreferences(sessionID, state)
+			sm.deleteSession(sessionID, state)
 		}
 	}
+
+	for sessionID, retained := range sm.retained {
+		if now.After(retained.expires) {
+			delete(sm.retained, sessionID)
+		}
+	}
+}
+
+func (s *sessionState) pinned(now time.Time) bool {
+	return now.Before(s.pinnedUntil)
+}
+
+// newBinToken returns a random token for the request bin of a session. The URL of a bin is handed to third-party
+// services, so it must not contain the session ID, which grants access to the dashboard of the session.
+func newBinToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
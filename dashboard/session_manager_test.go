@@ -1,6 +1,12 @@
 package dashboard
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -236,6 +242,40 @@ func TestSessionManager_Close(t *testing.T) {
 	if sm.Get(sessionID2) != nil {
 		t.Error("expected session 2 to be cleaned up")
 	}
+
+	// No sessions can be created after closing
+	if _, _, err := sm.GetOrCreate(sessionID1, collector.CaptureModeSession); !errors.Is(err, ErrSessionManagerClosed) {
+		t.Errorf("expected ErrSessionManagerClosed, got %v", err)
+	}
+
+	// Closing again is a no-op
+	sm.Close()
+}
+
+func TestSessionManager_Close_ConcurrentEvents(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+
+	storage, _, _ := sm.GetOrCreate(uuid.Must(uuid.NewV4()), collector.CaptureModeGlobal)
+	events := storage.Subscribe(context.Background())
+
+	// Events are collected while closing, the subscription must end without a panic
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 1000 {
+			aggregator.CollectEvent(context.Background(), "event")
+		}
+	}()
+
+	sm.Close()
+	for range events {
+	}
+	<-done
 }
 
 func TestSessionManager_IdleCleanup(t *testing.T) {
@@ -335,3 +375,373 @@ func TestSessionManager_MultipleSessions(t *testing.T) {
 		t.Errorf("expected 4 sessions, got %d", existing)
 	}
 }
+
+func TestSessionManager_Sessions(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	sessionID1 := uuid.Must(uuid.NewV4())
+	sessionID2 := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(sessionID1, collector.CaptureModeSession)
+	_, _, _ = sm.GetOrCreate(sessionID2, collector.CaptureModeGlobal)
+	time.Sleep(time.Millisecond)
+	sm.UpdateActivity(sessionID2)
+
+	aggregator.CollectEvent(context.Background(), "global event")
+	done := sm.TrackSSEConnection(sessionID2)
+
+	sessions := sm.Sessions()
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	// Most recently active session first
+	if sessions[0].SessionID != sessionID2 {
+		t.Errorf("expected session %s first, got %s", sessionID2, sessions[0].SessionID)
+	}
+	if sessions[0].Mode != "global" || sessions[0].EventCount != 1 || sessions[0].MemoryBytes == 0 || sessions[0].SSEConnections != 1 {
+		t.Errorf("unexpected info for global session: %+v", sessions[0])
+	}
+	if sessions[1].Mode != "session" || sessions[1].EventCount != 0 || sessions[1].SSEConnections != 0 {
+		t.Errorf("unexpected info for session mode session: %+v", sessions[1])
+	}
+
+	done()
+	if sm.Sessions()[0].SSEConnections != 0 {
+		t.Error("expected SSE connection to be released")
+	}
+}
+
+func TestSessionManager_Clear(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, _ := sm.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	aggregator.CollectEvent(context.Background(), "global event")
+
+	if !sm.Clear(sessionID) {
+		t.Fatal("expected clear to succeed")
+	}
+	if len(storage.GetEvents(10)) != 0 {
+		t.Error("expected events to be cleared")
+	}
+	if sm.Get(sessionID) == nil {
+		t.Error("expected session to be kept")
+	}
+	if sm.Clear(uuid.Must(uuid.NewV4())) {
+		t.Error("expected clear of non-existent session to fail")
+	}
+}
+
+func TestSessionManager_BrowserSessions(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	browserID := uuid.Must(uuid.NewV4())
+	checkoutID := uuid.Must(uuid.NewV4())
+	loginID := uuid.Must(uuid.NewV4())
+	otherID := uuid.Must(uuid.NewV4())
+	for _, sessionID := range []uuid.UUID{checkoutID, loginID, otherID} {
+		_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	}
+
+	if !sm.SetName(checkoutID, "checkout-bug") || !sm.SetBrowser(checkoutID, browserID) {
+		t.Fatal("expected SetName and SetBrowser to return true for existing session")
+	}
+	sm.SetName(loginID, "login-flow")
+	sm.SetBrowser(loginID, browserID)
+	sm.SetBrowser(otherID, uuid.Must(uuid.NewV4()))
+	sm.UpdateActivity(loginID)
+
+	sessions := sm.BrowserSessions(browserID)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions of the browser, got %d", len(sessions))
+	}
+	if sessions[0].SessionID != loginID || sessions[0].Name != "login-flow" {
+		t.Errorf("expected most recently active session login-flow first, got %+v", sessions[0])
+	}
+	if sessions[1].SessionID != checkoutID || sessions[1].Name != "checkout-bug" {
+		t.Errorf("expected session checkout-bug second, got %+v", sessions[1])
+	}
+
+	if sessions := sm.BrowserSessions(uuid.Nil); len(sessions) != 0 {
+		t.Errorf("expected no sessions for unknown browser, got %d", len(sessions))
+	}
+
+	info, ok := sm.Info(checkoutID)
+	if !ok || info.Name != "checkout-bug" {
+		t.Errorf("expected info of session checkout-bug, got %+v", info)
+	}
+	if sm.Name(otherID) != "" {
+		t.Errorf("expected unnamed session, got %q", sm.Name(otherID))
+	}
+
+	unknownID := uuid.Must(uuid.NewV4())
+	if sm.SetName(unknownID, "x") || sm.SetBrowser(unknownID, browserID) {
+		t.Error("expected SetName and SetBrowser to return false for non-existent session")
+	}
+	if _, ok := sm.Info(unknownID); ok {
+		t.Error("expected no info for non-existent session")
+	}
+}
+
+func TestSessionManager_DisplayPreferences(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if sm.SetDisplayPreferences(sessionID, DisplayPreferences{AbsoluteTimes: true}) {
+		t.Error("expected SetDisplayPreferences to return false for non-existent session")
+	}
+
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if prefs := sm.DisplayPreferences(sessionID); prefs.AbsoluteTimes || prefs.TimeZone != nil {
+		t.Errorf("expected default display preferences, got %+v", prefs)
+	}
+
+	if !sm.SetDisplayPreferences(sessionID, DisplayPreferences{AbsoluteTimes: true, TimeZone: time.UTC}) {
+		t.Fatal("expected SetDisplayPreferences to return true for existing session")
+	}
+	prefs := sm.DisplayPreferences(sessionID)
+	if !prefs.AbsoluteTimes || prefs.TimeZone != time.UTC {
+		t.Errorf("expected absolute times in UTC, got %+v", prefs)
+	}
+}
+
+func TestSessionManager_PreferencesRestoredAfterIdleCleanup(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     50 * time.Millisecond,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	sm.SetDisplayPreferences(sessionID, DisplayPreferences{AbsoluteTimes: true, TimeZone: time.UTC})
+	sm.SetErrorAlerts(sessionID, true)
+	sm.SetUIPreferences(sessionID, `{"filter":"severity=warn"}`)
+
+	// Wait for idle timeout + cleanup interval
+	time.Sleep(100 * time.Millisecond)
+
+	if sm.Get(sessionID) != nil {
+		t.Fatal("expected session to be cleaned up after idle timeout")
+	}
+	expected := SessionPreferences{Display: DisplayPreferences{AbsoluteTimes: true, TimeZone: time.UTC}, ErrorAlerts: true, UI: `{"filter":"severity=warn"}`}
+	if prefs := sm.Preferences(sessionID); prefs != expected {
+		t.Errorf("expected retained preferences %+v, got %+v", expected, prefs)
+	}
+
+	_, created, _ := sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if !created {
+		t.Fatal("expected session to be recreated")
+	}
+	if prefs := sm.Preferences(sessionID); prefs != expected {
+		t.Errorf("expected restored preferences %+v, got %+v", expected, prefs)
+	}
+
+	// Preferences of a deleted session are not retained
+	sm.Delete(sessionID)
+	if prefs := sm.Preferences(sessionID); prefs != (SessionPreferences{}) {
+		t.Errorf("expected default preferences after delete, got %+v", prefs)
+	}
+}
+
+func TestHandler_SetUIPreferences(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	if _, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	post := func(ui string) int {
+		req := httptest.NewRequest(http.MethodPost, "/s/"+sessionID.String()+"/preferences", strings.NewReader(url.Values{"ui": {ui}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post(`{"filter":"tag=tenant%3Aacme"}`); code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, code)
+	}
+	if prefs := handler.sessions.Preferences(sessionID); prefs.UI != `{"filter":"tag=tenant%3Aacme"}` {
+		t.Errorf("expected UI preferences to be stored, got %q", prefs.UI)
+	}
+
+	for _, ui := range []string{"", "null", "[]", "{", `{"filter":"` + strings.Repeat("x", maxUIPreferencesSize) + `"}`} {
+		if code := post(ui); code != http.StatusBadRequest {
+			t.Errorf("expected status %d for %.20q, got %d", http.StatusBadRequest, ui, code)
+		}
+	}
+
+	// The dashboard is rendered with the stored preferences
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/", nil))
+	if !strings.Contains(rec.Body.String(), `data-ui-preferences="{&#34;filter&#34;:&#34;tag=tenant%3Aacme&#34;}"`) {
+		t.Errorf("expected dashboard to contain the UI preferences, got %s", rec.Body.String())
+	}
+}
+
+func TestSessionManager_PreferencesRetentionExpires(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator:      aggregator,
+		StorageCapacity:      100,
+		IdleTimeout:          20 * time.Millisecond,
+		PreferencesRetention: 10 * time.Millisecond,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	sm.SetErrorAlerts(sessionID, true)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if sm.ErrorAlerts(sessionID) {
+		t.Error("expected preferences to expire after the retention")
+	}
+}
+
+func TestSessionManager_MaxSessions_EvictsIdleSession(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+		MaxSessions:     2,
+	})
+	defer sm.Close()
+
+	connectedID := uuid.Must(uuid.NewV4())
+	idleID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(connectedID, collector.CaptureModeSession)
+	_, _, _ = sm.GetOrCreate(idleID, collector.CaptureModeSession)
+	done := sm.TrackSSEConnection(connectedID)
+	defer done()
+
+	newID := uuid.Must(uuid.NewV4())
+	_, created, err := sm.GetOrCreate(newID, collector.CaptureModeSession)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created {
+		t.Error("expected new session to be created")
+	}
+	if sm.Get(idleID) != nil {
+		t.Error("expected idle session to be evicted")
+	}
+	if sm.Get(connectedID) == nil {
+		t.Error("expected connected session to be kept")
+	}
+}
+
+func TestSessionManager_MaxSessions_AllConnected(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     time.Minute,
+		MaxSessions:     1,
+	})
+	defer sm.Close()
+
+	connectedID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(connectedID, collector.CaptureModeSession)
+	done := sm.TrackSSEConnection(connectedID)
+	defer done()
+
+	_, _, err := sm.GetOrCreate(uuid.Must(uuid.NewV4()), collector.CaptureModeSession)
+	if !errors.Is(err, ErrMaxSessionsReached) {
+		t.Errorf("expected ErrMaxSessionsReached, got %v", err)
+	}
+}
+
+func TestSessionManager_PinnedSession(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     20 * time.Millisecond,
+		MaxSessions:     1,
+	})
+	defer sm.Close()
+
+	if sm.SetPinned(uuid.Must(uuid.NewV4()), true) {
+		t.Error("expected SetPinned to fail for a non-existent session")
+	}
+
+	sessionID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if !sm.SetPinned(sessionID, true) {
+		t.Fatal("expected SetPinned to succeed")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if sm.Get(sessionID) == nil {
+		t.Fatal("expected pinned session to survive idle cleanup")
+	}
+	if !sm.Pinned(sessionID) {
+		t.Error("expected session to be pinned")
+	}
+
+	_, _, err := sm.GetOrCreate(uuid.Must(uuid.NewV4()), collector.CaptureModeSession)
+	if !errors.Is(err, ErrMaxSessionsReached) {
+		t.Errorf("expected pinned session not to be evicted, got %v", err)
+	}
+
+	sm.SetPinned(sessionID, false)
+	time.Sleep(100 * time.Millisecond)
+
+	if sm.Get(sessionID) != nil {
+		t.Error("expected unpinned session to be cleaned up")
+	}
+}
+
+func TestSessionManager_PinnedSession_MaxAge(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	sm := NewSessionManager(SessionManagerOptions{
+		EventAggregator: aggregator,
+		StorageCapacity: 100,
+		IdleTimeout:     20 * time.Millisecond,
+		PinnedMaxAge:    10 * time.Millisecond,
+	})
+	defer sm.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	_, _, _ = sm.GetOrCreate(sessionID, collector.CaptureModeSession)
+	sm.SetPinned(sessionID, true)
+
+	time.Sleep(150 * time.Millisecond)
+
+	if sm.Get(sessionID) != nil {
+		t.Error("expected session to be cleaned up after the pinned max age")
+	}
+}
@@ -0,0 +1,112 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Snippet kinds for copying parts of captured requests as Go code
+const (
+	snippetRequestHeaders  = "request-headers"
+	snippetResponseHeaders = "response-headers"
+	snippetRequestBody     = "request-body"
+	snippetResponseBody    = "response-body"
+	snippetHTTPTest        = "httptest"
+)
+
+// snippetRequest holds the parts of client and server requests used for snippets
+type snippetRequest struct {
+	Method          string
+	Target          string
+	RequestHeaders  http.Header
+	ResponseHeaders http.Header
+	RequestBody     *collector.Body
+	ResponseBody    *collector.Body
+}
+
+// buildSnippet generates Go code for a part of a request, returns false if the kind is unknown
+func buildSnippet(kind string, req snippetRequest) (string, bool) {
+	switch kind {
+	case snippetRequestHeaders:
+		return goHeaderLiteral(req.RequestHeaders), true
+	case snippetResponseHeaders:
+		return goHeaderLiteral(req.ResponseHeaders), true
+	case snippetRequestBody:
+		return goBodyLiteral(req.RequestBody), true
+	case snippetResponseBody:
+		return goBodyLiteral(req.ResponseBody), true
+	case snippetHTTPTest:
+		return httpTestSnippet(req), true
+	}
+	return "", false
+}
+
+// goHeaderLiteral renders headers as a http.Header composite literal with sorted keys
+func goHeaderLiteral(header http.Header) string {
+	if len(header) == 0 {
+		return "http.Header{}"
+	}
+
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	sb.WriteString("http.Header{\n")
+	for _, key := range keys {
+		values := make([]string, 0, len(header[key]))
+		for _, value := range header[key] {
+			values = append(values, strconv.Quote(value))
+		}
+		fmt.Fprintf(&sb, "\t%s: {%s},\n", strconv.Quote(key), strings.Join(values, ", "))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// goBodyLiteral renders a body as a Go string literal, or a []byte conversion if it is not valid UTF-8.
+// Raw string literals are used if possible to keep JSON and other text readable.
+func goBodyLiteral(body *collector.Body) string {
+	if body == nil {
+		return `""`
+	}
+	content := body.Bytes()
+	if !utf8.Valid(content) {
+		return fmt.Sprintf("[]byte(%q)", content)
+	}
+	if strconv.CanBackquote(strings.ReplaceAll(string(content), "\n", "")) {
+		return "`" + string(content) + "`"
+	}
+	return strconv.Quote(string(content))
+}
+
+// httpTestSnippet renders a request construction with httptest for a regression test of a handler
+func httpTestSnippet(req snippetRequest) string {
+	body := "nil"
+	if req.RequestBody != nil && req.RequestBody.Size() > 0 {
+		literal := goBodyLiteral(req.RequestBody)
+		if strings.HasPrefix(literal, "[]byte(") {
+			body = "bytes.NewReader(" + literal + ")"
+		} else {
+			body = "strings.NewReader(" + literal + ")"
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "req := httptest.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), strconv.Quote(req.Target), body)
+	if len(req.RequestHeaders) > 0 {
+		fmt.Fprintf(&sb, "req.Header = %s\n", goHeaderLiteral(req.RequestHeaders))
+	}
+	sb.WriteString("rec := httptest.NewRecorder()\n")
+	sb.WriteString("\n")
+	sb.WriteString("handler.ServeHTTP(rec, req)\n")
+	return sb.String()
+}
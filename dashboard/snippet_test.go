@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func capturedBody(t *testing.T, content []byte) *collector.Body {
+	t.Helper()
+
+	body := collector.NewBody(io.NopCloser(bytes.NewReader(content)), 1024)
+	_, _ = io.ReadAll(body)
+	_ = body.Close()
+	return body
+}
+
+func TestGoHeaderLiteral(t *testing.T) {
+	header := http.Header{
+		"X-Request-Id": {"abc"},
+		"Accept":       {"text/html", "application/json"},
+	}
+
+	expected := "http.Header{\n" +
+		"\t\"Accept\": {\"text/html\", \"application/json\"},\n" +
+		"\t\"X-Request-Id\": {\"abc\"},\n" +
+		"}"
+	if got := goHeaderLiteral(header); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestGoBodyLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		expected string
+	}{
+		{
+			name:     "multi-line text",
+			content:  []byte("{\n  \"name\": \"devlog\"\n}"),
+			expected: "`{\n  \"name\": \"devlog\"\n}`",
+		},
+		{
+			name:     "backquote",
+			content:  []byte("a `quoted` value"),
+			expected: "\"a `quoted` value\"",
+		},
+		{
+			name:     "binary",
+			content:  []byte{0xff, 0x00},
+			expected: `[]byte("\xff\x00")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goBodyLiteral(capturedBody(t, tt.content)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildSnippet_HTTPTest(t *testing.T) {
+	snippet, ok := buildSnippet(snippetHTTPTest, snippetRequest{
+		Method:         http.MethodPost,
+		Target:         "/todos?draft=1",
+		RequestHeaders: http.Header{"Content-Type": {"application/json"}},
+		RequestBody:    capturedBody(t, []byte(`{"title":"Test"}`)),
+	})
+	if !ok {
+		t.Fatal("expected httptest snippet")
+	}
+
+	expected := "req := httptest.NewRequest(\"POST\", \"/todos?draft=1\", strings.NewReader(`{\"title\":\"Test\"}`))\n" +
+		"req.Header = http.Header{\n" +
+		"\t\"Content-Type\": {\"application/json\"},\n" +
+		"}\n" +
+		"rec := httptest.NewRecorder()\n" +
+		"\n" +
+		"handler.ServeHTTP(rec, req)\n"
+	if snippet != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, snippet)
+	}
+
+	if _, ok := buildSnippet("unknown", snippetRequest{}); ok {
+		t.Error("expected unknown snippet to fail")
+	}
+}
@@ -0,0 +1,80 @@
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// immutableCacheControl lets browsers cache fingerprinted assets forever, a changed asset gets a new name
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+var (
+	// hashes maps asset names to a hash of their content
+	hashes = make(map[string]string)
+	// fingerprinted maps asset names to names with the hash of their content, e.g. "main.css" to "main.1a2b3c4d5e6f7a8b.css"
+	fingerprinted = make(map[string]string)
+	// originals maps fingerprinted names back to the asset names
+	originals = make(map[string]string)
+)
+
+func init() {
+	// The assets are embedded at build time, so the fingerprints only need to be computed once
+	entries, err := fs.ReadDir(Assets, ".")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		content, err := fs.ReadFile(Assets, name)
+		if err != nil {
+			panic(err)
+		}
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:8])
+
+		ext := path.Ext(name)
+		fingerprint := strings.TrimSuffix(name, ext) + "." + hash + ext
+
+		hashes[name] = hash
+		fingerprinted[name] = fingerprint
+		originals[fingerprint] = name
+	}
+}
+
+// Path returns the fingerprinted name of an asset, e.g. "main.1a2b3c4d5e6f7a8b.css" for "main.css".
+// Unknown names are returned unchanged.
+func Path(name string) string {
+	if fingerprint, ok := fingerprinted[name]; ok {
+		return fingerprint
+	}
+	return name
+}
+
+// Handler serves the embedded assets. Fingerprinted names (see Path) are cached by browsers forever,
+// assets requested by their plain name are revalidated with an ETag of their content.
+func Handler() http.Handler {
+	fileServer := http.FileServerFS(Assets)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if original, ok := originals[name]; ok {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+			r = r.Clone(r.Context())
+			r.URL.Path = "/" + original
+			name = original
+		} else if _, ok := hashes[name]; ok {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		if hash, ok := hashes[name]; ok {
+			// The file server responds with 304 Not Modified if the ETag matches If-None-Match
+			w.Header().Set("ETag", `"`+hash+`"`)
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
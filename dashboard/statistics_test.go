@@ -0,0 +1,36 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHandler_GetStatistics_AllEvents(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator, WithTruncateAfter(2))
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeGlobal)
+	require.NoError(t, err)
+	for i := range 5 {
+		storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Data: collector.DBQuery{Query: "SELECT 1"}, Sequence: uint64(i + 1)})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/s/"+sessionID.String()+"/statistics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stats collector.EventStats
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	require.Len(t, stats.DBQueries, 1)
+	assert.Equal(t, 5, stats.DBQueries[0].Count, "statistics should not be limited to the events shown in the list")
+}
@@ -0,0 +1,150 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxAssertedFields limits the number of top-level JSON fields asserted by a generated test
+const maxAssertedFields = 10
+
+// maxAssertedTextBody is the maximum size of a text body that is compared as a whole by a generated test
+const maxAssertedTextBody = 1024
+
+// generateTest turns a captured server request into a Go test function that replays the request against a handler
+// and asserts the status code, content type and key fields of the response
+func generateTest(request collector.HTTPServerRequest) ([]byte, error) {
+	req := snippetRequest{
+		Method:         request.Method,
+		Target:         request.URL,
+		RequestHeaders: request.RequestHeaders,
+		RequestBody:    request.RequestBody,
+	}
+	requestSnippet := httpTestSnippet(req)
+
+	imports := []string{"net/http", "net/http/httptest", "testing"}
+	if strings.Contains(requestSnippet, "strings.NewReader") {
+		imports = append(imports, "strings")
+	}
+	if strings.Contains(requestSnippet, "bytes.NewReader") {
+		imports = append(imports, "bytes")
+	}
+
+	var assertions strings.Builder
+	fmt.Fprintf(&assertions, "if rec.Code != %d {\n", request.StatusCode)
+	fmt.Fprintf(&assertions, "t.Errorf(\"expected status %%d, got %%d\", %d, rec.Code)\n", request.StatusCode)
+	assertions.WriteString("}\n")
+
+	contentType := request.ResponseHeaders.Get("Content-Type")
+	if contentType != "" {
+		fmt.Fprintf(&assertions, "if got := rec.Header().Get(\"Content-Type\"); got != %s {\n", strconv.Quote(contentType))
+		fmt.Fprintf(&assertions, "t.Errorf(\"expected content type %%q, got %%q\", %s, got)\n", strconv.Quote(contentType))
+		assertions.WriteString("}\n")
+	}
+
+	if request.ResponseBody != nil && request.ResponseBody.Size() > 0 && !request.ResponseBody.IsTruncated() {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		content := request.ResponseBody.Bytes()
+		if bodyAssertions, ok := jsonFieldAssertions(mediaType, content); ok {
+			imports = append(imports, "encoding/json")
+			assertions.WriteString(bodyAssertions)
+		} else if strings.HasPrefix(mediaType, "text/") && len(content) <= maxAssertedTextBody {
+			fmt.Fprintf(&assertions, "if got := rec.Body.String(); got != %s {\n", goBodyLiteral(request.ResponseBody))
+			assertions.WriteString("t.Errorf(\"unexpected body: %s\", got)\n")
+			assertions.WriteString("}\n")
+		}
+	}
+
+	slices.Sort(imports)
+
+	var sb strings.Builder
+	sb.WriteString("package handler_test\n\n")
+	sb.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&sb, "%s\n", strconv.Quote(imp))
+	}
+	sb.WriteString(")\n\n")
+	fmt.Fprintf(&sb, "// %s replays %s %s captured by devlog at %s\n", testFunctionName(request), request.Method, request.URL, request.RequestTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "func %s(t *testing.T) {\n", testFunctionName(request))
+	sb.WriteString("var handler http.Handler // TODO: set up the handler under test\n\n")
+	sb.WriteString(requestSnippet)
+	sb.WriteString("\n")
+	sb.WriteString(assertions.String())
+	sb.WriteString("}\n")
+
+	return format.Source([]byte(sb.String()))
+}
+
+// jsonFieldAssertions asserts scalar top-level fields of a JSON object response
+func jsonFieldAssertions(mediaType string, content []byte) (string, bool) {
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return "", false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return "", false
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key, value := range fields {
+		switch value.(type) {
+		case string, float64, bool:
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	if len(keys) > maxAssertedFields {
+		keys = keys[:maxAssertedFields]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nvar body map[string]any\n")
+	sb.WriteString("if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {\n")
+	sb.WriteString("t.Fatalf(\"failed to decode response body: %v\", err)\n")
+	sb.WriteString("}\n")
+	for _, key := range keys {
+		var expected string
+		switch value := fields[key].(type) {
+		case string:
+			expected = strconv.Quote(value)
+		case float64:
+			expected = fmt.Sprintf("float64(%s)", strconv.FormatFloat(value, 'g', -1, 64))
+		case bool:
+			expected = strconv.FormatBool(value)
+		}
+		fmt.Fprintf(&sb, "if body[%s] != %s {\n", strconv.Quote(key), expected)
+		fmt.Fprintf(&sb, "t.Errorf(\"expected %%s to be %%v, got %%v\", %s, %s, body[%s])\n", strconv.Quote(key), expected, strconv.Quote(key))
+		sb.WriteString("}\n")
+	}
+	return sb.String(), true
+}
+
+// testFunctionName derives a test name from the method and route, e.g. "TestGetTodosId" for "GET /todos/{id}"
+func testFunctionName(request collector.HTTPServerRequest) string {
+	var sb strings.Builder
+	sb.WriteString("Test")
+	sb.WriteString(capitalize(strings.ToLower(request.Method)))
+	for _, part := range strings.FieldsFunc(request.Route(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		sb.WriteString(capitalize(part))
+	}
+	return sb.String()
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"go/parser"
+	"go/token"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestGenerateTest(t *testing.T) {
+	request := collector.HTTPServerRequest{
+		Method:          http.MethodPost,
+		Path:            "/todos/42",
+		RoutePattern:    "/todos/{id}",
+		URL:             "/todos/42",
+		RequestTime:     time.Now(),
+		StatusCode:      http.StatusCreated,
+		RequestHeaders:  http.Header{"Content-Type": {"application/json"}},
+		RequestBody:     capturedBody(t, []byte(`{"title":"Test"}`)),
+		ResponseHeaders: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		ResponseBody:    capturedBody(t, []byte(`{"id":42,"title":"Test","done":false,"tags":["a"]}`)),
+	}
+
+	source, err := generateTest(request)
+	if err != nil {
+		t.Fatalf("failed to generate test: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "generated_test.go", source, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("generated test does not parse: %v\n%s", err, source)
+	}
+	var imports []string
+	for _, imp := range file.Imports {
+		imports = append(imports, imp.Path.Value)
+	}
+	if got := strings.Join(imports, " "); got != `"encoding/json" "net/http" "net/http/httptest" "strings" "testing"` {
+		t.Errorf("unexpected imports: %s", got)
+	}
+
+	for _, expected := range []string{
+		"func TestPostTodosId(t *testing.T) {",
+		"httptest.NewRequest(\"POST\", \"/todos/42\", strings.NewReader(`{\"title\":\"Test\"}`))",
+		"if rec.Code != 201 {",
+		`if got := rec.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {`,
+		`if body["id"] != float64(42) {`,
+		`if body["title"] != "Test" {`,
+		`if body["done"] != false {`,
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("expected generated test to contain %s, got:\n%s", expected, source)
+		}
+	}
+	if strings.Contains(string(source), `body["tags"]`) {
+		t.Errorf("expected non-scalar fields to be skipped, got:\n%s", source)
+	}
+}
@@ -0,0 +1,124 @@
+package views
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/a-h/templ"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// EventRenderer renders events with custom data types in the dashboard
+type EventRenderer struct {
+	// Name is shown as a badge in the event list
+	Name string
+	// Summary returns the text shown in the event list (optional)
+	Summary func(event *collector.Event) string
+	// Details renders the detail view (optional)
+	Details func(event *collector.Event) templ.Component
+}
+
+var (
+	eventRenderersMu sync.RWMutex
+	eventRenderers   = make(map[reflect.Type]EventRenderer)
+)
+
+// RegisterEventRenderer registers a renderer for events with data of the given type
+func RegisterEventRenderer(t reflect.Type, renderer EventRenderer) {
+	eventRenderersMu.Lock()
+	defer eventRenderersMu.Unlock()
+
+	eventRenderers[t] = renderer
+}
+
+// lookupEventRenderer returns the registered renderer for the event data or a default renderer showing the type name.
+// Data of a registered event kind (see collector.RegisterEventKind) falls back to the kind and summary of its payload.
+func lookupEventRenderer(data any) EventRenderer {
+	eventRenderersMu.RLock()
+	renderer := eventRenderers[reflect.TypeOf(data)]
+	eventRenderersMu.RUnlock()
+
+	payload := collector.PayloadOf(data)
+	if payload.Kind() != collector.EventKindCustom {
+		if renderer.Name == "" {
+			renderer.Name = string(payload.Kind())
+		}
+		if renderer.Summary == nil {
+			renderer.Summary = func(event *collector.Event) string {
+				return event.Payload().Summary()
+			}
+		}
+	}
+	if renderer.Name == "" {
+		renderer.Name = customEventTypeName(data)
+	}
+	return renderer
+}
+
+func customEventTypeName(data any) string {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return "nil"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+type customEventField struct {
+	Key   string
+	Value string
+}
+
+// customEventFields returns the exported fields of a struct or the entries of a map for the key/value fallback view
+func customEventFields(data any) []customEventField {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var fields []customEventField
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			fields = append(fields, customEventField{
+				Key:   t.Field(i).Name,
+				Value: fmt.Sprintf("%+v", v.Field(i).Interface()),
+			})
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			fields = append(fields, customEventField{
+				Key:   fmt.Sprint(iter.Key().Interface()),
+				Value: fmt.Sprintf("%+v", iter.Value().Interface()),
+			})
+		}
+		slices.SortFunc(fields, func(a, b customEventField) int {
+			return cmp.Compare(a.Key, b.Key)
+		})
+	case reflect.Invalid:
+		return nil
+	default:
+		fields = append(fields, customEventField{
+			Key:   "Value",
+			Value: fmt.Sprintf("%+v", v.Interface()),
+		})
+	}
+	return fields
+}
@@ -14,10 +14,16 @@ import (
 )
 
 type DashboardProps struct {
-	SelectedEvent *collector.Event
-	Events        []*collector.Event
-	CaptureActive bool
-	CaptureMode   string // "session" or "global"
+	SelectedEvent  *collector.Event
+	Events         []*collector.Event
+	Offset         int
+	TotalEvents    int
+	ArchivedEvents int
+	AmbientEvents  []*collector.Event
+	CaptureActive  bool
+	CaptureMode    string // "session" or "global"
+	CaptureAmbient bool
+	CaptureError   string
 }
 
 func Dashboard(props DashboardProps) templ.Component {
@@ -41,11 +47,11 @@ func Dashboard(props DashboardProps) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		eventListProps := EventListProps{Events: props.Events, CaptureActive: props.CaptureActive, CaptureMode: props.CaptureMode}
+		eventListProps := EventListProps{Events: props.Events, Offset: props.Offset, TotalEvents: props.TotalEvents, ArchivedEvents: props.ArchivedEvents, AmbientEvents: props.AmbientEvents, CaptureActive: props.CaptureActive, CaptureMode: props.CaptureMode, CaptureAmbient: props.CaptureAmbient}
 		if props.SelectedEvent != nil {
 			eventListProps.SelectedEventID = &props.SelectedEvent.ID
 		}
-		capture := CaptureState{Active: props.CaptureActive, Mode: props.CaptureMode}
+		capture := CaptureState{Active: props.CaptureActive, Mode: props.CaptureMode, Ambient: props.CaptureAmbient, Error: props.CaptureError}
 		templ_7745c5c3_Var2 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
@@ -101,7 +107,7 @@ func EventListContainer(props EventListProps) templ.Component {
 		var templ_7745c5c3_Var4 string
 		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/s/%s/event-list", opts.PathPrefix, opts.SessionID))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/dashboard.templ`, Line: 30, Col: 77}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/dashboard.templ`, Line: 36, Col: 77}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 		if templ_7745c5c3_Err != nil {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/a-h/templ"
 	"github.com/alecthomas/chroma/v2"
@@ -37,6 +40,124 @@ func highlightContent(content string, contentType string) templ.Component {
 	})
 }
 
+// BodyPreviewLength is the number of bytes of a text body shown in the body preview
+const BodyPreviewLength = 10000
+
+// BodySegment is a part of the text of a body, Match is true for an occurrence of a body search
+type BodySegment struct {
+	Text  string
+	Match bool
+}
+
+// markedText renders the text of segments, matches of a body search are marked
+func markedText(segments []BodySegment) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		for _, segment := range segments {
+			text := templ.EscapeString(segment.Text)
+			if segment.Match {
+				text = "<mark>" + text + "</mark>"
+			}
+			if _, err := io.WriteString(w, text); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// highlightSQL applies syntax highlighting to a query of the language (a dialect of sql-formatter, e.g. "postgresql"
+// or "mysql"), generic SQL is used for languages without a matching lexer
+func highlightSQL(query string, language string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		lexer := lexers.Get(language)
+		if lexer == nil || !strings.Contains(strings.ToLower(lexer.Config().Name), "sql") {
+			lexer = lexers.Get("sql")
+		}
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+
+		formatter, style := chromaFormatterAndStyle()
+
+		iterator, err := lexer.Tokenise(nil, query)
+		if err != nil {
+			return err
+		}
+
+		return formatter.Format(w, style, iterator)
+	})
+}
+
+// isJSONContentType checks if a content type is JSON, including structured syntax suffixes like application/problem+json
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isImageContentType checks if a body can be previewed as an image, SVG is excluded since it is shown as XML
+func isImageContentType(contentType string) bool {
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "image/png", "image/jpeg", "image/gif", "image/webp", "image/avif", "image/bmp", "image/x-icon", "image/vnd.microsoft.icon":
+		return true
+	}
+	return false
+}
+
+// isBinaryBody sniffs if a body should be shown in the hex viewer instead of as text. Bodies with a textual content
+// type are binary if they are not valid UTF-8 (e.g. gzip compressed), otherwise the content type is detected from the
+// content.
+func isBinaryBody(content []byte, contentType string) bool {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType = strings.Split(http.DetectContentType(content), ";")[0]
+	}
+	if !isTextMediaType(mediaType) {
+		return true
+	}
+
+	// Only check the beginning, the end of a truncated body might be a partial UTF-8 sequence
+	sample := content[:min(len(content), 1024)]
+	for len(sample) > 0 {
+		r, size := utf8.DecodeRune(sample)
+		if r == utf8.RuneError && size == 1 && len(sample) >= utf8.UTFMax {
+			return true
+		}
+		if r < 32 && r != '\n' && r != '\r' && r != '\t' && r != '\f' {
+			return true
+		}
+		sample = sample[size:]
+	}
+	return false
+}
+
+// IsTextBody checks if a body is shown as text in the body preview, e.g. not as image or in the hex viewer
+func IsTextBody(content []byte, contentType string) bool {
+	return !isImageContentType(contentType) && !isProtobufContentType(contentType) && !isBinaryBody(content, contentType)
+}
+
+// isProtobufContentType checks if a body is a protobuf message, including enveloped gRPC and Connect messages
+func isProtobufContentType(contentType string) bool {
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "application/x-protobuf", "application/protobuf", "application/x-google-protobuf", "application/vnd.google.protobuf",
+		"application/proto", "application/connect+proto", "application/grpc", "application/grpc+proto",
+		"application/grpc-web", "application/grpc-web+proto":
+		return true
+	}
+	return false
+}
+
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-www-form-urlencoded",
+		"application/graphql", "application/x-ndjson", "application/yaml", "application/x-yaml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
 func chromaFormatterAndStyle() (*html.Formatter, *chroma.Style) {
 	formatter := html.New(
 		html.Standalone(false),
@@ -65,11 +186,67 @@ func chromaStyles() templ.Component {
 }
 
 type HandlerOptions struct {
-	PathPrefix    string
-	TruncateAfter uint64
-	SessionID     string
-	CaptureActive bool
-	CaptureMode   string // "session" or "global"
+	PathPrefix     string
+	TruncateAfter  uint64
+	PageSize       int // number of events rendered at once in the event list
+	SessionID      string
+	CaptureActive  bool
+	CaptureMode    string         // "session" or "global"
+	CaptureAmbient bool           // whether events outside of requests are captured
+	TagFilter      string         // only show events with this tag ("key" or "key:value")
+	TenantFilter   string         // only show events of this tenant
+	LastFilter     string         // only show events of the last duration (e.g. "5m")
+	SinceFilter    string         // only show events started at or after this time (RFC 3339)
+	UntilFilter    string         // only show events started before this time (RFC 3339)
+	SeverityFilter string         // only show events with at least this severity in the event or its children (e.g. "warn")
+	SortOrder      string         // "duration", "size" or "severity" (see collector.EventOrder), empty for the newest first
+	AbsoluteTimes  bool           // show absolute timestamps instead of relative ones
+	TimeZone       *time.Location // time zone of absolute timestamps, nil for the time zone of the server
+	ErrorAlerts    bool           // alert about errors with a sound and browser notification while the dashboard is in the background
+	UIPreferences  string         // JSON object with the state of the dashboard in the browser, e.g. the filter of the event list
+	FlightRecorder bool           // whether the flight recorder is enabled and can be dumped into a new session
+	Replay         bool           // whether captured requests can be edited and replayed
+	SessionPinned  bool           // whether the session is kept while no dashboard is connected
+	IdleTimeout    time.Duration  // time without a connected dashboard until a session is cleaned up
+	BinToken       string         // token of the request bin of the session, empty if the session does not capture
+}
+
+// formatTime formats a timestamp in the time zone of the display preferences
+func (opts HandlerOptions) formatTime(t time.Time, layout string) string {
+	if opts.TimeZone == nil {
+		return t.Local().Format(layout)
+	}
+	return t.In(opts.TimeZone).Format(layout)
+}
+
+// timeZoneName returns the name of the configured time zone, an empty string for the time zone of the server
+func (opts HandlerOptions) timeZoneName() string {
+	if opts.TimeZone == nil {
+		return ""
+	}
+	return opts.TimeZone.String()
+}
+
+// HasTimeFilter returns true if the event list is filtered by a time range
+func (opts HandlerOptions) HasTimeFilter() bool {
+	return opts.LastFilter != "" || opts.SinceFilter != "" || opts.UntilFilter != ""
+}
+
+// setFilterParams adds the query parameters of the event list filters
+func (opts HandlerOptions) setFilterParams(params url.Values) {
+	for name, value := range map[string]string{
+		"tag":      opts.TagFilter,
+		"tenant":   opts.TenantFilter,
+		"last":     opts.LastFilter,
+		"since":    opts.SinceFilter,
+		"until":    opts.UntilFilter,
+		"severity": opts.SeverityFilter,
+		"sort":     opts.SortOrder,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
 }
 
 // BuildDownloadRequestBodyURL builds a URL for downloading the request body of an event
@@ -82,6 +259,51 @@ func (opts HandlerOptions) BuildDownloadResponseBodyURL(eventID string) string {
 	return fmt.Sprintf("%s/s/%s/download/response-body/%s", opts.PathPrefix, opts.SessionID, eventID)
 }
 
+// BuildDownloadTestURL builds a URL for downloading a generated Go test for an incoming request
+func (opts HandlerOptions) BuildDownloadTestURL(eventID string) string {
+	return fmt.Sprintf("%s/s/%s/download/test/%s", opts.PathPrefix, opts.SessionID, eventID)
+}
+
+// BuildDownloadSchemaURL builds a URL for downloading a JSON Schema or Go type inferred from the request or response
+// bodies of the endpoint of an event
+func (opts HandlerOptions) BuildDownloadSchemaURL(eventID string, body string, format string) string {
+	return fmt.Sprintf("%s/s/%s/download/schema/%s?%s", opts.PathPrefix, opts.SessionID, eventID, url.Values{"body": {body}, "format": {format}}.Encode())
+}
+
+// BuildBodySearchURL builds a URL for searching the request or response body of an event
+func (opts HandlerOptions) BuildBodySearchURL(eventID string, body string) string {
+	return fmt.Sprintf("%s/s/%s/event/%s/body-search?%s", opts.PathPrefix, opts.SessionID, eventID, url.Values{"body": {body}}.Encode())
+}
+
+// BuildHexViewURL builds a URL for the page of the hex viewer containing offset of the request or response body of an
+// event
+func (opts HandlerOptions) BuildHexViewURL(eventID string, body string, offset int) string {
+	return fmt.Sprintf("%s/s/%s/event/%s/hex?%s", opts.PathPrefix, opts.SessionID, eventID, url.Values{"body": {body}, "offset": {fmt.Sprint(offset)}}.Encode())
+}
+
+// BuildProtobufViewURL builds a URL for the request or response body of an event decoded as protobuf message
+func (opts HandlerOptions) BuildProtobufViewURL(eventID string, body string) string {
+	return fmt.Sprintf("%s/s/%s/event/%s/protobuf?%s", opts.PathPrefix, opts.SessionID, eventID, url.Values{"body": {body}}.Encode())
+}
+
+// BuildCORSDiagnosticsURL builds a URL for the CORS diagnostics of a server request and its preflight or actual request
+func (opts HandlerOptions) BuildCORSDiagnosticsURL(eventID string) string {
+	return fmt.Sprintf("%s/s/%s/event/%s/cors", opts.PathPrefix, opts.SessionID, eventID)
+}
+
+// buildDownloadBodyURL builds the download URL of the request or response body of an event
+func (opts HandlerOptions) buildDownloadBodyURL(eventID string, body string) string {
+	if body == "request" {
+		return opts.BuildDownloadRequestBodyURL(eventID)
+	}
+	return opts.BuildDownloadResponseBodyURL(eventID)
+}
+
+// BuildSnippetURL builds a URL for copying a part of an event as Go code
+func (opts HandlerOptions) BuildSnippetURL(snippet string, eventID string) string {
+	return fmt.Sprintf("%s/s/%s/snippet/%s/%s", opts.PathPrefix, opts.SessionID, snippet, eventID)
+}
+
 // BuildEventDetailURL builds a URL for event detail view, preserving capture state
 func (opts HandlerOptions) BuildEventDetailURL(eventID string) string {
 	base := fmt.Sprintf("%s/s/%s/", opts.PathPrefix, opts.SessionID)
@@ -92,13 +314,49 @@ func (opts HandlerOptions) BuildEventDetailURL(eventID string) string {
 	if opts.CaptureActive {
 		params.Set("capture", "true")
 		params.Set("mode", opts.CaptureMode)
+		if opts.CaptureAmbient {
+			params.Set("ambient", "true")
+		}
 	}
+	opts.setFilterParams(params)
 	if len(params) > 0 {
 		return base + "?" + params.Encode()
 	}
 	return base
 }
 
+// BuildEventListURL builds a URL for the event list filtered by a tag ("key" or "key:value"), an empty tag removes the filter.
+// The time range and tenant filters are kept.
+func (opts HandlerOptions) BuildEventListURL(tagFilter string) string {
+	base := fmt.Sprintf("%s/s/%s/event-list", opts.PathPrefix, opts.SessionID)
+	opts.TagFilter = tagFilter
+	params := url.Values{}
+	opts.setFilterParams(params)
+	if len(params) == 0 {
+		return base
+	}
+	return base + "?" + params.Encode()
+}
+
+// BuildEventListPageURL builds a URL for a window of the filtered event list, the offset of the window (and the
+// selected event) are added when scrolling
+func (opts HandlerOptions) BuildEventListPageURL() string {
+	params := url.Values{}
+	opts.setFilterParams(params)
+	return fmt.Sprintf("%s/s/%s/event-list/page?%s", opts.PathPrefix, opts.SessionID, params.Encode())
+}
+
+// BuildArchivedEventListURL builds a URL for the filtered archived events before the given archive segment, the newest
+// segment if it is empty
+func (opts HandlerOptions) BuildArchivedEventListURL(beforeSegment string) string {
+	params := url.Values{}
+	if beforeSegment != "" {
+		params.Set("before", beforeSegment)
+	}
+	opts.setFilterParams(params)
+	return fmt.Sprintf("%s/s/%s/event-list/archived?%s", opts.PathPrefix, opts.SessionID, params.Encode())
+}
+
 // Context key for HandlerOptions
 type handlerOptionsKey struct{}
 
@@ -12,13 +12,16 @@ import (
 	"fmt"
 	"os"
 	"time"
+
+	"github.com/networkteam/devlog/dashboard/static"
 )
 
-func cachebuster(path string) string {
+// assetURL returns the URL of an embedded asset with a fingerprinted name, so browsers can cache it forever
+func assetURL(pathPrefix string, name string) string {
 	if os.Getenv("BACKEND_ENV") == "development" {
-		return fmt.Sprintf("%s?v=%d", path, time.Now().Unix())
+		return fmt.Sprintf("%s/static/%s?v=%d", pathPrefix, name, time.Now().Unix())
 	}
-	return path
+	return fmt.Sprintf("%s/static/%s", pathPrefix, static.Path(name))
 }
 
 func Layout(capture CaptureState) templ.Component {
@@ -48,9 +51,9 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var2 string
-		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/static/favicon.ico", opts.PathPrefix))
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "favicon.ico"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 22, Col: 100}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 25, Col: 87}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
@@ -61,9 +64,9 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var3 string
-		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/static/favicon-32x32.png", opts.PathPrefix))
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "favicon-32x32.png"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 23, Col: 117}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 26, Col: 104}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
@@ -74,9 +77,9 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var4 string
-		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/static/favicon-16x16.png", opts.PathPrefix))
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "favicon-16x16.png"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 24, Col: 117}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 27, Col: 104}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 		if templ_7745c5c3_Err != nil {
@@ -87,9 +90,9 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var5 string
-		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/static/apple-touch-icon.png", opts.PathPrefix))
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "apple-touch-icon.png"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 25, Col: 117}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 28, Col: 104}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 		if templ_7745c5c3_Err != nil {
@@ -100,9 +103,9 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var6 string
-		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/static/site.webmanifest", opts.PathPrefix))
+		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "site.webmanifest"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 26, Col: 89}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 29, Col: 76}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 		if templ_7745c5c3_Err != nil {
@@ -113,15 +116,15 @@ func Layout(capture CaptureState) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var7 string
-		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(cachebuster(fmt.Sprintf("%s/static/main.css", opts.PathPrefix)))
+		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(assetURL(opts.PathPrefix, "main.css"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 28, Col: 96}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 31, Col: 70}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\" type=\"text/css\"><script src=\"https://unpkg.com/htmx.org@2.0.4\" integrity=\"sha384-HGfztofotfshcF7+8n44JQL2oJmowVChPTg48S+jvZoztPfvwD79OC/LTtG6dMp+\" crossorigin=\"anonymous\"></script><script src=\"https://unpkg.com/htmx-ext-sse@2.2.3\" integrity=\"sha384-Y4gc0CK6Kg+hmulDc6rZPJu0tqvk7EWlih0Oh+2OkAi1ZDlCbBDCQEE2uVk472Ky\" crossorigin=\"anonymous\"></script><script type=\"module\" src=\"https://unpkg.com/@github/relative-time-element@4.0.0/dist/bundle.js\" integrity=\"sha256-J5aATie9Dn4Vmv9U813wYrEhB556Z/38n0r4UuFA5e0=\" crossorigin=\"anonymous\"></script><script src=\"https://unpkg.com/sql-formatter@15.6.1/dist/sql-formatter.min.js\" integrity=\"sha256-bt1CvtyHaFfuer5u6LdPGliv9Z9QY8VWhZwW3P8upPM=\" crossorigin=\"anonymous\"></script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\" type=\"text/css\"><script src=\"https://unpkg.com/htmx.org@2.0.4\" integrity=\"sha384-HGfztofotfshcF7+8n44JQL2oJmowVChPTg48S+jvZoztPfvwD79OC/LTtG6dMp+\" crossorigin=\"anonymous\"></script><script src=\"https://unpkg.com/htmx-ext-sse@2.2.3\" integrity=\"sha384-Y4gc0CK6Kg+hmulDc6rZPJu0tqvk7EWlih0Oh+2OkAi1ZDlCbBDCQEE2uVk472Ky\" crossorigin=\"anonymous\"></script><script type=\"module\" src=\"https://unpkg.com/@github/relative-time-element@4.0.0/dist/bundle.js\" integrity=\"sha256-J5aATie9Dn4Vmv9U813wYrEhB556Z/38n0r4UuFA5e0=\" crossorigin=\"anonymous\"></script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -136,13 +139,52 @@ func Layout(capture CaptureState) templ.Component {
 		var templ_7745c5c3_Var8 string
 		templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/s/%s/capture/cleanup", opts.PathPrefix, opts.SessionID))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 38, Col: 93}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 40, Col: 93}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "\" data-import-url=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var9 string
+		templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/s/%s/sessions/import", opts.PathPrefix, opts.SessionID))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 41, Col: 92}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\" data-preferences-url=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var10 string
+		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%s/s/%s/preferences", opts.PathPrefix, opts.SessionID))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 42, Col: 93}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "\" data-ui-preferences=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var11 string
+		templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(opts.UIPreferences)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 43, Col: 43}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -150,7 +192,13 @@ func Layout(capture CaptureState) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<main class=\"flex-1 min-h-0 flex flex-col\">")
+		if capture.Active {
+			templ_7745c5c3_Err = connectionLostBanner().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<main class=\"flex-1 min-h-0 flex flex-col\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -158,30 +206,30 @@ func Layout(capture CaptureState) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</main><script>\n\t\t\t\twindow.addEventListener('beforeunload', function() {\n\t\t\t\t\tnavigator.sendBeacon(document.body.dataset.cleanupUrl);\n\t\t\t\t});\n\t\t\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</main><script>\n\t\t\t\t// ID of the last event of the stream, the events added or updated after it are replayed when the stream is\n\t\t\t\t// opened again (e.g. after sleep or a network change)\n\t\t\t\tlet devlogLastEventID = '';\n\n\t\t\t\t// Event source for the SSE extension that falls back to a WebSocket if the stream does not arrive,\n\t\t\t\t// e.g. because a proxy buffers SSE. The WebSocket sends the same events, the choice is kept for the tab.\n\t\t\t\tclass DevlogEventSource {\n\t\t\t\t\tconstructor(url) {\n\t\t\t\t\t\tthis.url = url;\n\t\t\t\t\t\tthis.listeners = new Map();\n\t\t\t\t\t\tthis.readyState = EventSource.CONNECTING;\n\t\t\t\t\t\tthis.onopen = null;\n\t\t\t\t\t\tthis.onerror = null;\n\t\t\t\t\t\tif (sessionStorage.getItem('devlog-transport') === 'websocket') {\n\t\t\t\t\t\t\tthis.connectWebSocket();\n\t\t\t\t\t\t} else {\n\t\t\t\t\t\t\tthis.connectSSE();\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\n\t\t\t\t\taddEventListener(type, listener) {\n\t\t\t\t\t\tif (!this.listeners.has(type)) {\n\t\t\t\t\t\t\tthis.listeners.set(type, new Set());\n\t\t\t\t\t\t}\n\t\t\t\t\t\tthis.listeners.get(type).add(listener);\n\t\t\t\t\t\tthis.eventSource?.addEventListener(type, listener);\n\t\t\t\t\t}\n\n\t\t\t\t\tremoveEventListener(type, listener) {\n\t\t\t\t\t\tthis.listeners.get(type)?.delete(listener);\n\t\t\t\t\t\tthis.eventSource?.removeEventListener(type, listener);\n\t\t\t\t\t}\n\n\t\t\t\t\tstreamURL() {\n\t\t\t\t\t\tconst url = new URL(this.url, window.location.href);\n\t\t\t\t\t\tif (devlogLastEventID) {\n\t\t\t\t\t\t\turl.searchParams.set('lastEventId', devlogLastEventID);\n\t\t\t\t\t\t}\n\t\t\t\t\t\treturn url;\n\t\t\t\t\t}\n\n\t\t\t\t\tconnectSSE() {\n\t\t\t\t\t\tconst source = new EventSource(this.streamURL(), { withCredentials: true });\n\t\t\t\t\t\tthis.eventSource = source;\n\t\t\t\t\t\t['new-event', 'update-event', 'child-update'].forEach((type) => source.addEventListener(type, (e) => devlogLastEventID = e.lastEventId));\n\t\t\t\t\t\t// The last event is not stored anymore, the missed events can only be shown by loading the dashboard again\n\t\t\t\t\t\tsource.addEventListener('resync', () => window.location.reload());\n\t\t\t\t\t\t// The server sends a keepalive right away, if it does not arrive the stream is buffered\n\t\t\t\t\t\tconst fallback = setTimeout(() => this.fallbackToWebSocket(), 5000);\n\t\t\t\t\t\tsource.addEventListener('keepalive', () => clearTimeout(fallback));\n\t\t\t\t\t\tsource.onopen = (e) => {\n\t\t\t\t\t\t\tthis.readyState = source.readyState;\n\t\t\t\t\t\t\tshowDevlogConnectionLost(false);\n\t\t\t\t\t\t\tthis.onopen?.(e);\n\t\t\t\t\t\t};\n\t\t\t\t\t\tsource.onerror = (e) => {\n\t\t\t\t\t\t\tthis.readyState = source.readyState;\n\t\t\t\t\t\t\tif (source.readyState === EventSource.CLOSED) {\n\t\t\t\t\t\t\t\tclearTimeout(fallback);\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tshowDevlogConnectionLost(true);\n\t\t\t\t\t\t\tthis.onerror?.(e);\n\t\t\t\t\t\t};\n\t\t\t\t\t}\n\n\t\t\t\t\tfallbackToWebSocket() {\n\t\t\t\t\t\tif (this.readyState === EventSource.CLOSED) {\n\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t}\n\t\t\t\t\t\tthis.eventSource.close();\n\t\t\t\t\t\tthis.eventSource = null;\n\t\t\t\t\t\tsessionStorage.setItem('devlog-transport', 'websocket');\n\t\t\t\t\t\tthis.connectWebSocket();\n\t\t\t\t\t}\n\n\t\t\t\t\tconnectWebSocket() {\n\t\t\t\t\t\tconst url = this.streamURL();\n\t\t\t\t\t\turl.pathname = url.pathname.replace('/events-sse', '/events-ws');\n\t\t\t\t\t\turl.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';\n\t\t\t\t\t\tconst socket = new WebSocket(url);\n\t\t\t\t\t\tthis.socket = socket;\n\t\t\t\t\t\tlet opened = false;\n\t\t\t\t\t\tsocket.onopen = (e) => {\n\t\t\t\t\t\t\topened = true;\n\t\t\t\t\t\t\tthis.readyState = EventSource.OPEN;\n\t\t\t\t\t\t\tshowDevlogConnectionLost(false);\n\t\t\t\t\t\t\tthis.onopen?.(e);\n\t\t\t\t\t\t};\n\t\t\t\t\t\tsocket.onmessage = (e) => {\n\t\t\t\t\t\t\tconst message = JSON.parse(e.data);\n\t\t\t\t\t\t\tif (message.id) {\n\t\t\t\t\t\t\t\tdevlogLastEventID = message.id;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (message.event === 'resync') {\n\t\t\t\t\t\t\t\twindow.location.reload();\n\t\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tconst event = new MessageEvent(message.event, { data: message.data, lastEventId: message.id || '' });\n\t\t\t\t\t\t\tthis.listeners.get(message.event)?.forEach((listener) => listener(event));\n\t\t\t\t\t\t};\n\t\t\t\t\t\tsocket.onclose = (e) => {\n\t\t\t\t\t\t\tif (this.readyState === EventSource.CLOSED) {\n\t\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t// Use SSE again on the next connection if WebSockets do not work either\n\t\t\t\t\t\t\tif (!opened) {\n\t\t\t\t\t\t\t\tsessionStorage.removeItem('devlog-transport');\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tthis.readyState = EventSource.CLOSED;\n\t\t\t\t\t\t\tshowDevlogConnectionLost(true);\n\t\t\t\t\t\t\tthis.onerror?.(e);\n\t\t\t\t\t\t};\n\t\t\t\t\t}\n\n\t\t\t\t\tclose() {\n\t\t\t\t\t\tthis.readyState = EventSource.CLOSED;\n\t\t\t\t\t\tthis.eventSource?.close();\n\t\t\t\t\t\tthis.socket?.close();\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\thtmx.createEventSource = (url) => new DevlogEventSource(url);\n\n\t\t\t\t// Warn that the session will be cleaned up while the connection is lost, unless it is pinned\n\t\t\t\tfunction showDevlogConnectionLost(lost) {\n\t\t\t\t\tconst banner = document.getElementById('connection-lost-banner');\n\t\t\t\t\tbanner?.classList.toggle('hidden', !lost || banner.dataset.pinned === 'true');\n\t\t\t\t}\n\n\t\t\t\twindow.addEventListener('beforeunload', function() {\n\t\t\t\t\tnavigator.sendBeacon(document.body.dataset.cleanupUrl);\n\t\t\t\t});\n\n\t\t\t\t// Show a browser notification of a notification rule, clicking it selects the event\n\t\t\t\tfunction showDevlogNotification(notification) {\n\t\t\t\t\tif (!('Notification' in window) || Notification.permission !== 'granted') {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tconst n = new Notification(notification.title, { body: notification.rule || 'devlog', tag: notification.ruleId + notification.eventId });\n\t\t\t\t\tn.onclick = function() {\n\t\t\t\t\t\twindow.focus();\n\t\t\t\t\t\tdocument.getElementById('event-' + notification.eventId + '-item')?.click();\n\t\t\t\t\t};\n\t\t\t\t}\n\n\t\t\t\t// Audio for error alerts, browsers only allow to start it after a user interaction\n\t\t\t\tlet devlogAlertAudio = null;\n\n\t\t\t\t// Enable error alerts with a click, asking for the permission to show notifications\n\t\t\t\tfunction enableDevlogErrorAlerts() {\n\t\t\t\t\tif ('Notification' in window && Notification.permission === 'default') {\n\t\t\t\t\t\tNotification.requestPermission();\n\t\t\t\t\t}\n\t\t\t\t\tif (!devlogAlertAudio && 'AudioContext' in window) {\n\t\t\t\t\t\tdevlogAlertAudio = new AudioContext();\n\t\t\t\t\t}\n\t\t\t\t\tdevlogAlertAudio?.resume();\n\t\t\t\t}\n\n\t\t\t\t// Alert about an error with a short beep and a notification, only while the dashboard is in the background\n\t\t\t\tfunction alertDevlogError(alert) {\n\t\t\t\t\tif (!document.hidden) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tif (devlogAlertAudio) {\n\t\t\t\t\t\tconst oscillator = devlogAlertAudio.createOscillator();\n\t\t\t\t\t\tconst gain = devlogAlertAudio.createGain();\n\t\t\t\t\t\toscillator.frequency.value = 880;\n\t\t\t\t\t\tgain.gain.setValueAtTime(0.2, devlogAlertAudio.currentTime);\n\t\t\t\t\t\tgain.gain.exponentialRampToValueAtTime(0.001, devlogAlertAudio.currentTime + 0.3);\n\t\t\t\t\t\toscillator.connect(gain).connect(devlogAlertAudio.destination);\n\t\t\t\t\t\toscillator.start();\n\t\t\t\t\t\toscillator.stop(devlogAlertAudio.currentTime + 0.3);\n\t\t\t\t\t}\n\t\t\t\t\tshowDevlogNotification({ title: alert.title, rule: 'Error', ruleId: 'error-', eventId: alert.eventId });\n\t\t\t\t}\n\n\t\t\t\t// The event list renders a window of events, spacers stand in for the events before and after it. The\n\t\t\t\t// window at the scroll position is loaded when a spacer is scrolled into view.\n\t\t\t\tfunction devlogEventListRows(list) {\n\t\t\t\t\tconst rows = [];\n\t\t\t\t\tfor (let li = list.querySelector(':scope > #event-list-before')?.nextElementSibling; li && li.id !== 'event-list-after'; li = li.nextElementSibling) {\n\t\t\t\t\t\trows.push(li);\n\t\t\t\t\t}\n\t\t\t\t\treturn rows;\n\t\t\t\t}\n\n\t\t\t\t// Size the spacers of the event list by the number of events they stand in for, the height of a row is\n\t\t\t\t// measured from the rendered rows\n\t\t\t\tfunction devlogSizeEventListSpacers(list) {\n\t\t\t\t\tconst rows = devlogEventListRows(list);\n\t\t\t\t\tif (rows.length > 1) {\n\t\t\t\t\t\tconst height = (rows[rows.length - 1].getBoundingClientRect().top - rows[0].getBoundingClientRect().top) / (rows.length - 1);\n\t\t\t\t\t\tif (height > 0) {\n\t\t\t\t\t\t\tlist.dataset.rowHeight = height;\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\tconst rowHeight = Number(list.dataset.rowHeight) || 80;\n\t\t\t\t\tlist.querySelectorAll(':scope > .event-list-spacer').forEach(function(spacer) {\n\t\t\t\t\t\tconst count = Number(spacer.dataset.count);\n\t\t\t\t\t\tspacer.classList.toggle('hidden', count === 0);\n\t\t\t\t\t\tspacer.style.height = (count * rowHeight) + 'px';\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\t// Keep the rendered window at a page of events and the list at the limit of events after events were\n\t\t\t\t// added, events beyond the limit are dropped from the end of the list\n\t\t\t\tfunction devlogTrimEventList(list) {\n\t\t\t\t\tconst before = list.querySelector(':scope > #event-list-before');\n\t\t\t\t\tconst after = list.querySelector(':scope > #event-list-after');\n\t\t\t\t\tconst rows = devlogEventListRows(list);\n\t\t\t\t\tlet afterCount = Number(after.dataset.count);\n\t\t\t\t\twhile (rows.length > Number(list.dataset.pageSize)) {\n\t\t\t\t\t\trows.pop().remove();\n\t\t\t\t\t\tafterCount++;\n\t\t\t\t\t}\n\t\t\t\t\tlet excess = Number(before.dataset.count) + rows.length + afterCount - Number(list.dataset.truncateAfter);\n\t\t\t\t\tif (excess > 0) {\n\t\t\t\t\t\tconst dropped = Math.min(excess, afterCount);\n\t\t\t\t\t\tafterCount -= dropped;\n\t\t\t\t\t\texcess -= dropped;\n\t\t\t\t\t\twhile (excess > 0 && rows.length > 0) {\n\t\t\t\t\t\t\trows.pop().remove();\n\t\t\t\t\t\t\texcess--;\n\t\t\t\t\t\t}\n\t\t\t\t\t\tlist.querySelector(':scope > #event-list-archived')?.remove();\n\t\t\t\t\t}\n\t\t\t\t\tafter.dataset.count = afterCount;\n\t\t\t\t\tdevlogSizeEventListSpacers(list);\n\t\t\t\t}\n\n\t\t\t\t// Load the window of the event list at the scroll position if a spacer is in view. The event at the top\n\t\t\t\t// of the view is kept in place, so the list does not jump if the height of the spacers was off.\n\t\t\t\tasync function devlogLoadEventListWindow(list, container) {\n\t\t\t\t\tconst before = list.querySelector(':scope > #event-list-before');\n\t\t\t\t\tconst after = list.querySelector(':scope > #event-list-after');\n\t\t\t\t\tif (!before || list.dataset.loading) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tconst view = container.getBoundingClientRect();\n\t\t\t\t\tconst rowHeight = Number(list.dataset.rowHeight) || 80;\n\t\t\t\t\tconst beforeBox = before.getBoundingClientRect();\n\t\t\t\t\tconst afterBox = after.getBoundingClientRect();\n\t\t\t\t\tlet index;\n\t\t\t\t\tif (Number(before.dataset.count) > 0 && beforeBox.bottom > view.top) {\n\t\t\t\t\t\tindex = Math.floor(Math.max(0, view.top - beforeBox.top) / rowHeight);\n\t\t\t\t\t} else if (Number(after.dataset.count) > 0 && afterBox.top < view.bottom) {\n\t\t\t\t\t\tindex = Number(before.dataset.count) + devlogEventListRows(list).length + Math.floor(Math.max(0, view.top - afterBox.top) / rowHeight);\n\t\t\t\t\t} else {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tconst url = new URL(list.dataset.pageUrl, window.location.href);\n\t\t\t\t\turl.searchParams.set('offset', Math.max(0, index - Math.floor(Number(list.dataset.pageSize) / 4)));\n\t\t\t\t\tconst selected = new URLSearchParams(window.location.search).get('id');\n\t\t\t\t\tif (selected) {\n\t\t\t\t\t\turl.searchParams.set('selected', selected);\n\t\t\t\t\t}\n\t\t\t\t\tlist.dataset.loading = 'true';\n\t\t\t\t\ttry {\n\t\t\t\t\t\tconst response = await fetch(url, { headers: { 'HX-Request': 'true' } });\n\t\t\t\t\t\tif (!response.ok) {\n\t\t\t\t\t\t\treturn;\n\t\t\t\t\t\t}\n\t\t\t\t\t\tconst html = await response.text();\n\t\t\t\t\t\tlist.querySelectorAll(':scope > li').forEach((li) => li.remove());\n\t\t\t\t\t\tlist.insertAdjacentHTML('beforeend', html);\n\t\t\t\t\t\tfor (const li of list.children) {\n\t\t\t\t\t\t\thtmx.process(li);\n\t\t\t\t\t\t}\n\t\t\t\t\t\tdevlogSizeEventListSpacers(list);\n\t\t\t\t\t\tconst offset = Number(list.querySelector(':scope > #event-list-before').dataset.count);\n\t\t\t\t\t\tconst row = devlogEventListRows(list)[index - offset];\n\t\t\t\t\t\tif (row) {\n\t\t\t\t\t\t\tcontainer.scrollTop += row.getBoundingClientRect().top - view.top;\n\t\t\t\t\t\t}\n\t\t\t\t\t} finally {\n\t\t\t\t\t\tdelete list.dataset.loading;\n\t\t\t\t\t}\n\t\t\t\t}\n\n\t\t\t\tlet devlogEventListTimer = null;\n\t\t\t\tdocument.addEventListener('scroll', function(e) {\n\t\t\t\t\tconst list = document.getElementById('event-list');\n\t\t\t\t\tif (!list?.dataset.pageUrl || !(e.target instanceof Element) || !e.target.contains(list)) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tclearTimeout(devlogEventListTimer);\n\t\t\t\t\tdevlogEventListTimer = setTimeout(() => devlogLoadEventListWindow(list, e.target), 100);\n\t\t\t\t}, true);\n\n\t\t\t\t// Size the spacers when the event list is rendered, the selected event is scrolled into view if the\n\t\t\t\t// window does not start at the newest event\n\t\t\t\tdocument.addEventListener('htmx:load', function(e) {\n\t\t\t\t\tconst list = document.getElementById('event-list');\n\t\t\t\t\tif (!list?.dataset.pageUrl || !e.detail.elt.contains(list)) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tdevlogSizeEventListSpacers(list);\n\t\t\t\t\tif (Number(list.querySelector(':scope > #event-list-before').dataset.count) > 0) {\n\t\t\t\t\t\tlist.querySelector('.selected')?.scrollIntoView({ block: 'center' });\n\t\t\t\t\t}\n\t\t\t\t});\n\n\t\t\t\t// The UI state of the dashboard is stored with the session on the server, so it is restored when the\n\t\t\t\t// dashboard is opened again, e.g. after the session was recreated\n\t\t\t\tconst devlogFilterParams = ['tag', 'tenant', 'kind', 'path', 'severity', 'since', 'until', 'last', 'sort'];\n\t\t\t\tfunction devlogUIPreferences() {\n\t\t\t\t\ttry {\n\t\t\t\t\t\treturn JSON.parse(document.body.dataset.uiPreferences || '{}');\n\t\t\t\t\t} catch (e) {\n\t\t\t\t\t\treturn {};\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\tfunction devlogSaveUIPreferences(changes) {\n\t\t\t\t\tconst ui = JSON.stringify(Object.assign(devlogUIPreferences(), changes));\n\t\t\t\t\tdocument.body.dataset.uiPreferences = ui;\n\t\t\t\t\tfetch(document.body.dataset.preferencesUrl, { method: 'POST', body: new URLSearchParams({ ui: ui }) });\n\t\t\t\t}\n\t\t\t\tfunction devlogFilterQuery() {\n\t\t\t\t\tconst params = new URLSearchParams(window.location.search);\n\t\t\t\t\tconst filter = new URLSearchParams();\n\t\t\t\t\tdevlogFilterParams.forEach(name => params.has(name) && filter.set(name, params.get(name)));\n\t\t\t\t\treturn filter.toString();\n\t\t\t\t}\n\t\t\t\tfunction devlogStoreFilter() {\n\t\t\t\t\tconst filter = devlogFilterQuery();\n\t\t\t\t\tif ((devlogUIPreferences().filter || '') !== filter) {\n\t\t\t\t\t\tdevlogSaveUIPreferences({ filter: filter });\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\tdocument.addEventListener('htmx:pushedIntoHistory', devlogStoreFilter);\n\t\t\t\t(function() {\n\t\t\t\t\tif (!document.getElementById('event-list')) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\t// A dashboard opened without a filter shows the events with the stored filter\n\t\t\t\t\tconst filter = devlogUIPreferences().filter;\n\t\t\t\t\tif (filter && devlogFilterQuery() === '') {\n\t\t\t\t\t\tconst params = new URLSearchParams(window.location.search);\n\t\t\t\t\t\tnew URLSearchParams(filter).forEach((value, name) => params.set(name, value));\n\t\t\t\t\t\twindow.location.replace(window.location.pathname + '?' + params.toString());\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tdevlogStoreFilter();\n\t\t\t\t})();\n\n\t\t\t\t// Import a dropped devlog NDJSON export or HAR file into a new read-only session\n\t\t\t\tdocument.addEventListener('dragover', function(e) {\n\t\t\t\t\tif (e.dataTransfer?.types.includes('Files')) {\n\t\t\t\t\t\te.preventDefault();\n\t\t\t\t\t}\n\t\t\t\t});\n\t\t\t\tdocument.addEventListener('drop', async function(e) {\n\t\t\t\t\tconst file = e.dataTransfer?.files[0];\n\t\t\t\t\t// File inputs (e.g. for protobuf descriptors) handle dropped files themselves\n\t\t\t\t\tif (!file || e.target.closest('input[type=file]')) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\te.preventDefault();\n\t\t\t\t\tconst form = new FormData();\n\t\t\t\t\tform.append('file', file);\n\t\t\t\t\tconst response = await fetch(document.body.dataset.importUrl, { method: 'POST', body: form, headers: { 'HX-Request': 'true' } });\n\t\t\t\t\tif (!response.ok) {\n\t\t\t\t\t\talert('Import failed: ' + await response.text());\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\twindow.location.href = response.headers.get('HX-Redirect');\n\t\t\t\t});\n\t\t\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if url := os.Getenv("REFRESH_LIVE_RELOAD_SCRIPT_URL"); url != "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<script src=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<script src=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var9 string
-			templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(url)
+			var templ_7745c5c3_Var12 string
+			templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(url)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 50, Col: 21}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `dashboard/views/layout.templ`, Line: 419, Col: 21}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "\"></script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\"></script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</body></html>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
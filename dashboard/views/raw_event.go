@@ -0,0 +1,202 @@
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxRawDepth limits the nesting of raw values to guard against cyclic data
+const maxRawDepth = 32
+
+// RawEventJSON serializes an event with its data as indented JSON.
+// Values that don't serialize well (errors, bodies, slog records, durations) are converted to readable values,
+// children are referenced by their ID.
+func RawEventJSON(event *collector.Event) ([]byte, error) {
+	childIDs := make([]string, 0, len(event.Children))
+	for _, child := range event.Children {
+		childIDs = append(childIDs, child.ID.String())
+	}
+
+	raw := orderedObject{
+		{"id", event.ID.String()},
+		{"type", fmt.Sprintf("%T", event.Data)},
+		{"kind", event.Kind()},
+		{"start", event.Start.Format(time.RFC3339Nano)},
+		{"end", event.End.Format(time.RFC3339Nano)},
+		{"ambient", event.Ambient},
+		{"size", event.Size},
+	}
+	if event.GroupID != nil {
+		raw = append(raw, orderedField{"groupId", event.GroupID.String()})
+	}
+	if event.Detached {
+		raw = append(raw, orderedField{"detached", true})
+	}
+	if event.Tenant != "" {
+		raw = append(raw, orderedField{"tenant", event.Tenant})
+	}
+	raw = append(raw,
+		orderedField{"data", rawValue(reflect.ValueOf(event.Data), 0)},
+		orderedField{"children", childIDs},
+	)
+
+	return json.MarshalIndent(raw, "", "  ")
+}
+
+type orderedField struct {
+	Key   string
+	Value any
+}
+
+// orderedObject is a JSON object that keeps the order of its fields
+type orderedObject []orderedField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+var (
+	errorType      = reflect.TypeFor[error]()
+	bodyType       = reflect.TypeFor[*collector.Body]()
+	slogRecordType = reflect.TypeFor[slog.Record]()
+	timeType       = reflect.TypeFor[time.Time]()
+	durationType   = reflect.TypeFor[time.Duration]()
+	uuidType       = reflect.TypeFor[uuid.UUID]()
+)
+
+// rawValue converts a value to a value that is safe to serialize as JSON
+func rawValue(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxRawDepth {
+		return "(max depth exceeded)"
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	switch v.Type() {
+	case bodyType:
+		body := v.Interface().(*collector.Body)
+		return orderedObject{
+			{"content", body.String()},
+			{"size", body.Size()},
+			{"truncated", body.IsTruncated()},
+			{"evicted", body.IsEvicted()},
+		}
+	case slogRecordType:
+		return rawSlogRecord(v.Interface().(slog.Record), depth)
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case durationType:
+		return v.Interface().(time.Duration).String()
+	case uuidType:
+		return v.Interface().(uuid.UUID).String()
+	}
+
+	if v.Type().Implements(errorType) {
+		return v.Interface().(error).Error()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return rawValue(v.Elem(), depth+1)
+	case reflect.Struct:
+		t := v.Type()
+		obj := make(orderedObject, 0, t.NumField())
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			obj = append(obj, orderedField{t.Field(i).Name, rawValue(v.Field(i), depth+1)})
+		}
+		return obj
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = rawValue(iter.Value(), depth+1)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%s", v.Interface())
+		}
+		list := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			list = append(list, rawValue(v.Index(i), depth+1))
+		}
+		return list
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return v.Type().String()
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Interface())
+	case reflect.Float32, reflect.Float64:
+		// JSON has no representation for NaN and infinity
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+	}
+
+	return v.Interface()
+}
+
+// rawSlogRecord converts a log record with its attributes, groups are nested objects
+func rawSlogRecord(record slog.Record, depth int) orderedObject {
+	attrs := orderedObject{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, rawSlogAttr(attr, depth+1))
+		return true
+	})
+	return orderedObject{
+		{"Time", record.Time.Format(time.RFC3339Nano)},
+		{"Level", record.Level.String()},
+		{"Message", record.Message},
+		{"Attrs", attrs},
+	}
+}
+
+func rawSlogAttr(attr slog.Attr, depth int) orderedField {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		group := orderedObject{}
+		for _, groupAttr := range value.Group() {
+			group = append(group, rawSlogAttr(groupAttr, depth+1))
+		}
+		return orderedField{attr.Key, group}
+	}
+	return orderedField{attr.Key, rawValue(reflect.ValueOf(value.Any()), depth+1)}
+}
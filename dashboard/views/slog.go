@@ -3,11 +3,17 @@ package views
 import (
 	"iter"
 	"log/slog"
+
+	"github.com/networkteam/devlog/collector"
 )
 
+// iterSlogAttrs iterates over the attributes of a record except source and stack, which are rendered separately
 func iterSlogAttrs(record slog.Record) iter.Seq[slog.Attr] {
 	return func(yield func(attr slog.Attr) bool) {
 		record.Attrs(func(attr slog.Attr) bool {
+			if isSourceAttr(attr) || isStackAttr(attr) {
+				return true
+			}
 			if !yield(attr) {
 				return false
 			}
@@ -15,3 +21,43 @@ func iterSlogAttrs(record slog.Record) iter.Seq[slog.Attr] {
 		})
 	}
 }
+
+// slogRecordSource returns the source location added by the log collector handler
+func slogRecordSource(record slog.Record) (source collector.LogSource, ok bool) {
+	record.Attrs(func(attr slog.Attr) bool {
+		if isSourceAttr(attr) {
+			source, ok = attr.Value.Any().(collector.LogSource)
+			return false
+		}
+		return true
+	})
+	return source, ok
+}
+
+// slogRecordStack returns the stack trace added by the log collector handler
+func slogRecordStack(record slog.Record) (stack collector.LogStack) {
+	record.Attrs(func(attr slog.Attr) bool {
+		if isStackAttr(attr) {
+			stack, _ = attr.Value.Any().(collector.LogStack)
+			return false
+		}
+		return true
+	})
+	return stack
+}
+
+func isSourceAttr(attr slog.Attr) bool {
+	if attr.Key != slog.SourceKey || attr.Value.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := attr.Value.Any().(collector.LogSource)
+	return ok
+}
+
+func isStackAttr(attr slog.Attr) bool {
+	if attr.Key != collector.StackKey || attr.Value.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := attr.Value.Any().(collector.LogStack)
+	return ok
+}
@@ -0,0 +1,277 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is appended to the key of the client to compute the accept header (RFC 6455, section 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of WebSocket frames used by the event stream
+const (
+	websocketOpText  = 0x1
+	websocketOpClose = 0x8
+	websocketOpPing  = 0x9
+	websocketOpPong  = 0xA
+)
+
+// websocketMaxClientPayload limits frames of the client, which only sends control frames
+const websocketMaxClientPayload = 4096
+
+// websocketWriteTimeout is how long writing a frame may take before the connection is considered dead
+const websocketWriteTimeout = 10 * time.Second
+
+// errWebSocketClosed is returned when writing to a connection that already sent a close frame
+var errWebSocketClosed = errors.New("websocket close frame already sent")
+
+// websocketMessage is a message of the event stream over WebSocket, it has the same event names and data as the SSE stream
+type websocketMessage struct {
+	Event string `json:"event"`
+	// ID is the ID of a new event, it is sent as "lastEventId" when the client opens a new stream
+	ID   string `json:"id,omitempty"`
+	Data string `json:"data"`
+}
+
+// getEventsWebSocket streams events like getEventsSSE over a WebSocket, for proxies that buffer SSE
+func (h *Handler) getEventsWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !isWebSocketUpgrade(r) {
+		http.Error(w, "Expected WebSocket upgrade", http.StatusUpgradeRequired)
+		return
+	}
+	if !isSameOrigin(r) {
+		http.Error(w, "Cross-origin WebSocket upgrade", http.StatusForbidden)
+		return
+	}
+
+	r, sessionID, storage, filter, ok := h.prepareEventStream(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		h.logger.Debug("WebSocket upgrade failed", "session", sessionID, "error", err)
+		return
+	}
+	defer conn.close()
+
+	// The client only sends control frames, reading them detects a closed connection
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		conn.readLoop()
+	}()
+
+	h.streamEvents(ctx, sessionID, storage, filter, lastEventID(r), func(event string, id string, data []byte) error {
+		message, err := json.Marshal(websocketMessage{Event: event, ID: id, Data: string(data)})
+		if err != nil {
+			return err
+		}
+		return conn.writeFrame(websocketOpText, message)
+	})
+
+	// Status 1001 (going away), the client reconnects like after an ended SSE stream. If the client closed the
+	// connection, readLoop already answered with a close frame and this one is not sent.
+	_ = conn.writeFrame(websocketOpClose, binary.BigEndian.AppendUint16(nil, 1001))
+}
+
+// isWebSocketUpgrade checks if a request asks for a WebSocket connection
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket") &&
+		r.Header.Get("Sec-WebSocket-Version") == "13" &&
+		r.Header.Get("Sec-WebSocket-Key") != ""
+}
+
+// isSameOrigin checks that a request of a browser comes from a page of the same host. Browsers don't apply the same-origin
+// policy to WebSocket connections, so another site could read the events of a session with the cookies of the user.
+// Requests without Origin (e.g. of a CLI client) are allowed.
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// headerContainsToken checks if a comma-separated header contains a token, ignoring case
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// websocketConn is a minimal server side WebSocket connection that sends messages and answers control frames
+type websocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	writeMu sync.Mutex
+	// closeSent is set when a close frame was written, no frames may follow it (RFC 6455, section 5.5.1)
+	closeSent bool
+}
+
+// upgradeWebSocket completes the opening handshake and takes over the connection.
+// Headers already set on w (e.g. a session cookie) are sent with the handshake response.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijacking connection: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + websocketGUID))
+
+	header := w.Header().Clone()
+	header.Del("Content-Type")
+	header.Set("Upgrade", "websocket")
+	header.Set("Connection", "Upgrade")
+	header.Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(sum[:]))
+
+	_ = conn.SetDeadline(time.Time{})
+	if _, err := rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := header.Write(rw); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := rw.WriteString("\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &websocketConn{conn: conn, rw: rw}, nil
+}
+
+// writeFrame writes an unfragmented frame, server frames are not masked.
+// It returns errWebSocketClosed after a close frame was written.
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return errWebSocketClosed
+	}
+	if opcode == websocketOpClose {
+		c.closeSent = true
+	}
+
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readLoop reads frames of the client until the connection is closed, answering pings and close frames
+func (c *websocketConn) readLoop() {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case websocketOpClose:
+			_ = c.writeFrame(websocketOpClose, payload)
+			return
+		case websocketOpPing:
+			if c.writeFrame(websocketOpPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+// readFrame reads a single frame of the client and unmasks its payload
+func (c *websocketConn) readFrame() (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if !masked {
+		return 0, nil, errors.New("unmasked client frame")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.rw, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.rw, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > websocketMaxClientPayload {
+		return 0, nil, errors.New("client frame too large")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+// close closes the underlying connection
+func (c *websocketConn) close() {
+	c.conn.Close()
+}
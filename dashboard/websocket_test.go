@@ -0,0 +1,304 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestHandler_GetEventsWebSocket(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/s/"+sessionID.String()+"/events-ws?mode=global&ambient=true", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("failed to send handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+	// Example key and accept value of RFC 6455
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected Sec-WebSocket-Accept %q", accept)
+	}
+
+	readMessage := func() websocketMessage {
+		t.Helper()
+		var header [2]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			t.Fatalf("failed to read frame: %v", err)
+		}
+		if opcode := header[0] & 0x0F; opcode != websocketOpText {
+			t.Fatalf("expected text frame, got opcode %d", opcode)
+		}
+		length := uint64(header[1] & 0x7F)
+		switch length {
+		case 126:
+			var extended [2]byte
+			io.ReadFull(reader, extended[:])
+			length = uint64(binary.BigEndian.Uint16(extended[:]))
+		case 127:
+			var extended [8]byte
+			io.ReadFull(reader, extended[:])
+			length = binary.BigEndian.Uint64(extended[:])
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			t.Fatalf("failed to read payload: %v", err)
+		}
+		var message websocketMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			t.Fatalf("invalid message %q: %v", payload, err)
+		}
+		return message
+	}
+
+	if message := readMessage(); message.Event != "keepalive" {
+		t.Fatalf("expected keepalive first, got %q", message.Event)
+	}
+
+	aggregator.CollectEvent(context.Background(), "hello")
+	message := readMessage()
+	if message.Event != "new-event" || !strings.Contains(message.Data, "-item") {
+		t.Errorf("expected new event with rendered item, got %q: %q", message.Event, message.Data)
+	}
+	if _, ok := parseStreamEventID(handler.sessions.Get(sessionID), message.ID); !ok {
+		t.Errorf("expected new event with its ID to resume the stream, got %q", message.ID)
+	}
+}
+
+func TestHandler_StreamEvents_Replay(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	// The client received an event in progress. While it was disconnected, the event completed and an event that started
+	// before it was added.
+	later := &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 2, Data: "later", InProgress: true}
+	storage.Add(later)
+	lastEventID := streamEventID(storage, storage.Revision())
+	storage.Add(&collector.Event{ID: later.ID, Sequence: 2, Data: "later"})
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 1, Data: "earlier"})
+
+	// replay returns the events sent when the stream is opened, it ends after n events
+	replay := func(lastEventID string, n int) []string {
+		r := handler.withHandlerOptions(httptest.NewRequest(http.MethodGet, "/", nil), sessionID.String(), true, "session", false)
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		var sent []string
+		handler.streamEvents(ctx, sessionID, storage, eventFilter{}, lastEventID, func(event string, id string, data []byte) error {
+			if event != "keepalive" {
+				sent = append(sent, event+" "+id)
+			}
+			if len(sent) == n {
+				cancel()
+			}
+			return nil
+		})
+		return sent
+	}
+
+	expected := []string{"new-event " + streamEventID(storage, 2), "new-event " + streamEventID(storage, 3)}
+	if sent := replay(lastEventID, len(expected)); !slices.Equal(sent, expected) {
+		t.Errorf("expected replay %v, got %v", expected, sent)
+	}
+
+	// The stream of a recreated session can't be resumed
+	expected = []string{"resync "}
+	if sent := replay(uuid.Must(uuid.NewV7()).String()+":1", len(expected)); !slices.Equal(sent, expected) {
+		t.Errorf("expected replay %v, got %v", expected, sent)
+	}
+}
+
+func TestHandler_StreamEvents_ReplayAlertsOnce(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	storage, _, err := handler.sessions.GetOrCreate(sessionID, collector.CaptureModeSession)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	handler.sessions.SetErrorAlerts(sessionID, true)
+	storage.Add(&collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: 1, Data: "received"})
+	lastEventID := streamEventID(storage, storage.Revision())
+
+	failed := func(sequence uint64) *collector.Event {
+		return &collector.Event{ID: uuid.Must(uuid.NewV7()), Sequence: sequence, Data: collector.HTTPServerRequest{Method: http.MethodGet, Path: "/", StatusCode: http.StatusInternalServerError}}
+	}
+
+	r := handler.withHandlerOptions(httptest.NewRequest(http.MethodGet, "/", nil), sessionID.String(), true, "session", false)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	var sent []string
+	handler.streamEvents(ctx, sessionID, storage, eventFilter{}, lastEventID, func(event string, id string, data []byte) error {
+		switch event {
+		case "keepalive":
+			// The event is added after subscribing, so it is replayed and notified
+			storage.Add(failed(2))
+			return nil
+		case "new-event":
+			if len(sent) == 0 {
+				// Added after the replay, it is alerted once
+				storage.Add(failed(3))
+			}
+		}
+		sent = append(sent, event+" "+id)
+		if len(sent) == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	expected := []string{"new-event " + streamEventID(storage, 2), "error-event ", "new-event " + streamEventID(storage, 3)}
+	if !slices.Equal(sent, expected) {
+		t.Errorf("expected %v, got %v", expected, sent)
+	}
+}
+
+func TestHandler_GetEventsWebSocket_ClientClose(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/s/"+sessionID.String()+"/events-ws?mode=global", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("failed to send handshake: %v", err)
+	}
+	reader := bufio.NewReader(conn)
+	if _, err := http.ReadResponse(reader, req); err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+
+	// Masked close frame with status 1000 (normal closure), a zero mask keeps the payload as is
+	closeFrame := []byte{0x80 | websocketOpClose, 0x80 | 2, 0, 0, 0, 0}
+	closeFrame = binary.BigEndian.AppendUint16(closeFrame, 1000)
+	if _, err := conn.Write(closeFrame); err != nil {
+		t.Fatalf("failed to send close frame: %v", err)
+	}
+
+	// Read all frames until the server closes the connection
+	var closeFrames []uint16
+	for {
+		var header [2]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			break
+		}
+		// Frames of the stream are small enough for a 16 bit length
+		length := uint64(header[1] & 0x7F)
+		if length == 126 {
+			var extended [2]byte
+			io.ReadFull(reader, extended[:])
+			length = uint64(binary.BigEndian.Uint16(extended[:]))
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			t.Fatalf("failed to read payload: %v", err)
+		}
+		if opcode := header[0] & 0x0F; opcode == websocketOpClose {
+			closeFrames = append(closeFrames, binary.BigEndian.Uint16(payload))
+		} else if len(closeFrames) > 0 {
+			t.Errorf("expected no frames after close, got opcode %d", opcode)
+		}
+	}
+
+	if !slices.Equal(closeFrames, []uint16{1000}) {
+		t.Errorf("expected a single close frame echoing status 1000, got %v", closeFrames)
+	}
+}
+
+func TestHandler_GetEventsWebSocket_CrossOrigin(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	handler := NewHandler(aggregator)
+	defer handler.Close()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	req := httptest.NewRequest(http.MethodGet, "http://devlog.test/s/"+sessionID.String()+"/events-ws?mode=global", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "https://attacker.test")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if handler.sessions.Get(sessionID) != nil {
+		t.Error("expected no session to be created for a cross-origin upgrade")
+	}
+
+	for origin, expected := range map[string]bool{
+		"":                     true,
+		"http://devlog.test":   true,
+		"https://DEVLOG.test":  true,
+		"http://devlog.test:8": false,
+		"null":                 false,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "http://devlog.test/", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := isSameOrigin(r); got != expected {
+			t.Errorf("isSameOrigin with origin %q: expected %v, got %v", origin, expected, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/networkteam/devlog/dashboard"
+)
+
+// DashboardServer returns an http.Server that serves the dashboard at the root path of addr (e.g. "localhost:6060"),
+// separate from the server of the application. The dashboard does not have to be routed through the public mux
+// or proxies, and it is not reachable from the outside if addr is bound to localhost.
+//
+// The server is shut down with the instance (see Instance.Shutdown and Instance.Close).
+// Start it with ListenAndServe, or use ServeDashboard.
+func (i *Instance) DashboardServer(addr string, opts ...dashboard.HandlerOption) *http.Server {
+	handler := i.DashboardHandler("", opts...)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		// No write timeout, the event stream of the dashboard is long-lived
+	}
+	// Open event streams would block a graceful shutdown of the server
+	server.RegisterOnShutdown(i.dashboardHandler.CloseStreams)
+	i.dashboardServer = server
+	return server
+}
+
+// ServeDashboard serves the dashboard on addr (e.g. "localhost:6060") until the instance is shut down or closed.
+// It returns nil after the instance was shut down, or the error of listening otherwise:
+//
+//	go func() {
+//		if err := dlog.ServeDashboard("localhost:6060"); err != nil {
+//			log.Printf("devlog dashboard: %v", err)
+//		}
+//	}()
+func (i *Instance) ServeDashboard(addr string, opts ...dashboard.HandlerOption) error {
+	err := i.DashboardServer(addr, opts...).ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// shutdownDashboardServer gracefully shuts down the dashboard server if it was created
+func (i *Instance) shutdownDashboardServer(ctx context.Context) error {
+	if i.dashboardServer == nil {
+		return nil
+	}
+	return i.dashboardServer.Shutdown(ctx)
+}
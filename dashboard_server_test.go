@@ -0,0 +1,51 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkteam/devlog"
+)
+
+func TestInstance_DashboardServer(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := dlog.DashboardServer(listener.Addr().String())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if location := resp.Header.Get("Location"); !strings.HasPrefix(location, "/s/") {
+		t.Errorf("expected redirect to a session at the root path, got %q", location)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := dlog.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expected the dashboard server to be shut down, got %v", err)
+	}
+}
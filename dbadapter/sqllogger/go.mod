@@ -7,10 +7,14 @@ replace github.com/networkteam/devlog => ../../
 require (
 	github.com/networkteam/devlog v0.0.0-00010101000000-000000000000
 	github.com/networkteam/go-sqllogger v0.4.0
+	github.com/stretchr/testify v1.10.0
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gofrs/uuid v4.4.0+incompatible // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/samber/lo v1.50.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,447 @@
+package sqlloggeradapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/networkteam/go-sqllogger"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// LoggingConnector wraps the connector with sqllogger.LoggingConnector using the devlog adapter.
+// In addition to New, it records rows returned / affected and errors of queries, which are not exposed through
+// sqllogger.SQLLogger. Queries are collected after the result is known (rows of a query are closed).
+func LoggingConnector(collect func(ctx context.Context, dbQuery collector.DBQuery), connector driver.Connector, options Options) driver.Connector {
+	a := newAdapter(collect, options)
+	a.recordsResults = true
+	return &resultConnector{
+		adapter:   a,
+		connector: sqllogger.LoggingConnector(a, connector),
+	}
+}
+
+// pendingQuery receives the query logged by the adapter while the result is recorded
+type pendingQuery struct {
+	query *collector.DBQuery
+}
+
+type pendingQueryKey struct{}
+
+func withPendingQuery(ctx context.Context) (context.Context, *pendingQuery) {
+	p := &pendingQuery{}
+	return context.WithValue(ctx, pendingQueryKey{}, p), p
+}
+
+func pendingQueryFromContext(ctx context.Context) (*pendingQuery, bool) {
+	p, ok := ctx.Value(pendingQueryKey{}).(*pendingQuery)
+	return p, ok
+}
+
+// pendingConn receives the ID of a connection logged by the adapter while connecting
+type pendingConn struct {
+	connID int64
+}
+
+type pendingConnKey struct{}
+
+func withPendingConn(ctx context.Context) (context.Context, *pendingConn) {
+	pc := &pendingConn{}
+	return context.WithValue(ctx, pendingConnKey{}, pc), pc
+}
+
+func pendingConnFromContext(ctx context.Context) (*pendingConn, bool) {
+	pc, ok := ctx.Value(pendingConnKey{}).(*pendingConn)
+	return pc, ok
+}
+
+// resolve returns the logged query or builds one from the known values if the query was not logged (on error)
+func (p *pendingQuery) resolve(a *adapter, connID int64, query string, args []driver.NamedValue, start time.Time) collector.DBQuery {
+	if p.query != nil {
+		dbQuery := *p.query
+		if dbQuery.Duration == 0 {
+			// Not every call is timed by go-sqllogger
+			dbQuery.Timestamp = start
+			dbQuery.Duration = time.Since(start)
+		}
+		return dbQuery
+	}
+	dbQuery := a.newQuery(connID, query, args)
+	dbQuery.Timestamp = start
+	dbQuery.Duration = time.Since(start)
+	return dbQuery
+}
+
+type resultConnector struct {
+	adapter   *adapter
+	connector driver.Connector
+}
+
+func (c *resultConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	ctx, pc := withPendingConn(ctx)
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resultConn{adapter: c.adapter, conn: conn, connID: pc.connID}, nil
+}
+
+func (c *resultConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// resultConn wraps a connection of sqllogger.LoggingConnector. Optional interfaces the wrapped connection does not
+// implement fall back to the behavior of database/sql without them.
+type resultConn struct {
+	adapter *adapter
+	conn    driver.Conn
+	// connID is the ID of the connection logged by the adapter (0 if unknown)
+	connID int64
+}
+
+var (
+	_ driver.Conn               = &resultConn{}
+	_ driver.ExecerContext      = &resultConn{}
+	_ driver.QueryerContext     = &resultConn{}
+	_ driver.ConnPrepareContext = &resultConn{}
+	_ driver.ConnBeginTx        = &resultConn{}
+	_ driver.SessionResetter    = &resultConn{}
+	_ driver.NamedValueChecker  = &resultConn{}
+	_ driver.Pinger             = &resultConn{}
+	_ driver.Validator          = &resultConn{}
+)
+
+func (c *resultConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *resultConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := prepareContext(ctx, c.conn, query)
+	if err != nil {
+		return nil, err
+	}
+	return &resultStmt{adapter: c.adapter, stmt: stmt, query: query, connID: c.connID}, nil
+}
+
+func (c *resultConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *resultConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *resultConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	// Like database/sql, options are only supported by drivers implementing driver.ConnBeginTx
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.conn.Begin()
+}
+
+func (c *resultConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql falls back to a prepared statement, which is collected by resultStmt
+		return nil, driver.ErrSkip
+	}
+	ctx, p := withPendingQuery(ctx)
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		// database/sql falls back to a prepared statement
+		return nil, err
+	}
+	c.adapter.collectExec(ctx, p.resolve(c.adapter, c.connID, query, args, start), res, err)
+	return res, err
+}
+
+func (c *resultConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, p := withPendingQuery(ctx)
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	return c.adapter.collectQuery(ctx, p.resolve(c.adapter, c.connID, query, args, start), rows, err)
+}
+
+func (c *resultConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *resultConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	// database/sql converts the value with the default converter
+	return driver.ErrSkip
+}
+
+func (c *resultConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *resultConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// prepareContext prepares a statement with driver.ConnPrepareContext or falls back to driver.Conn.Prepare
+func prepareContext(ctx context.Context, conn driver.Conn, query string) (driver.Stmt, error) {
+	if preparer, ok := conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return conn.Prepare(query)
+}
+
+type resultStmt struct {
+	adapter  *adapter
+	stmt     driver.Stmt
+	query    string
+	connID   int64
+	executed atomic.Bool
+}
+
+var (
+	_ driver.Stmt              = &resultStmt{}
+	_ driver.StmtExecContext   = &resultStmt{}
+	_ driver.StmtQueryContext  = &resultStmt{}
+	_ driver.NamedValueChecker = &resultStmt{}
+)
+
+func (s *resultStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *resultStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *resultStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), toNamedValues(args))
+}
+
+func (s *resultStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, p := withPendingQuery(ctx)
+	start := time.Now()
+	res, err := stmtExecContext(ctx, s.stmt, args)
+	dbQuery := p.resolve(s.adapter, s.connID, s.query, args, start)
+	dbQuery.Prepared = true
+	dbQuery.StatementReused = s.executed.Swap(true)
+	s.adapter.collectExec(ctx, dbQuery, res, err)
+	return res, err
+}
+
+func (s *resultStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), toNamedValues(args))
+}
+
+func (s *resultStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, p := withPendingQuery(ctx)
+	start := time.Now()
+	rows, err := stmtQueryContext(ctx, s.stmt, args)
+	dbQuery := p.resolve(s.adapter, s.connID, s.query, args, start)
+	dbQuery.Prepared = true
+	dbQuery.StatementReused = s.executed.Swap(true)
+	return s.adapter.collectQuery(ctx, dbQuery, rows, err)
+}
+
+func (s *resultStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmtExecContext executes a statement with driver.StmtExecContext or falls back to driver.Stmt.Exec
+func stmtExecContext(ctx context.Context, stmt driver.Stmt, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := toValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return stmt.Exec(values)
+}
+
+// stmtQueryContext queries a statement with driver.StmtQueryContext or falls back to driver.Stmt.Query
+func stmtQueryContext(ctx context.Context, stmt driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := toValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return stmt.Query(values)
+}
+
+// toValues converts arguments for drivers without context support, which don't support named arguments
+func toValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// collectExec collects a statement with the number of affected rows or the error
+func (a *adapter) collectExec(ctx context.Context, dbQuery collector.DBQuery, res driver.Result, err error) {
+	if err != nil {
+		dbQuery.Error = err
+	} else if rowsAffected, err := res.RowsAffected(); err == nil {
+		dbQuery.RowsAffected = &rowsAffected
+	}
+	a.collect(ctx, dbQuery)
+}
+
+// collectQuery collects a failed query immediately, otherwise the query is collected when the rows are closed
+func (a *adapter) collectQuery(ctx context.Context, dbQuery collector.DBQuery, rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		dbQuery.Error = err
+		a.collect(ctx, dbQuery)
+		return nil, err
+	}
+	return &resultRows{
+		rows: rows,
+		onClose: func(rowsReturned int64, err error) {
+			dbQuery.RowsReturned = &rowsReturned
+			dbQuery.Error = err
+			a.collect(ctx, dbQuery)
+		},
+	}, nil
+}
+
+// resultRows counts rows read from the wrapped rows of sqllogger.LoggingConnector
+type resultRows struct {
+	rows    driver.Rows
+	count   int64
+	err     error
+	onClose func(rowsReturned int64, err error)
+	once    sync.Once
+}
+
+var (
+	_ driver.Rows                           = &resultRows{}
+	_ driver.RowsNextResultSet              = &resultRows{}
+	_ driver.RowsColumnTypeDatabaseTypeName = &resultRows{}
+	_ driver.RowsColumnTypeLength           = &resultRows{}
+	_ driver.RowsColumnTypeNullable         = &resultRows{}
+	_ driver.RowsColumnTypePrecisionScale   = &resultRows{}
+	_ driver.RowsColumnTypeScanType         = &resultRows{}
+)
+
+func (r *resultRows) Columns() []string {
+	return r.rows.Columns()
+}
+
+func (r *resultRows) Close() error {
+	err := r.rows.Close()
+	r.once.Do(func() {
+		r.onClose(r.count, r.err)
+	})
+	return err
+}
+
+func (r *resultRows) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	if err == nil {
+		r.count++
+	} else if !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return err
+}
+
+// The column types and result sets fall back to the defaults of database/sql if the wrapped rows don't implement them
+
+func (r *resultRows) HasNextResultSet() bool {
+	if next, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return next.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *resultRows) NextResultSet() error {
+	if next, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return next.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *resultRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rows, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rows.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *resultRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if rows, ok := r.rows.(driver.RowsColumnTypeLength); ok {
+		return rows.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *resultRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if rows, ok := r.rows.(driver.RowsColumnTypeNullable); ok {
+		return rows.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *resultRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if rows, ok := r.rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rows.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+func (r *resultRows) ColumnTypeScanType(index int) reflect.Type {
+	if rows, ok := r.rows.(driver.RowsColumnTypeScanType); ok {
+		return rows.ColumnTypeScanType(index)
+	}
+	return reflect.TypeFor[any]()
+}
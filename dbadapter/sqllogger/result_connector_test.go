@@ -0,0 +1,244 @@
+package sqlloggeradapter_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	sqlloggeradapter "github.com/networkteam/devlog/dbadapter/sqllogger"
+)
+
+func TestLoggingConnector(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []collector.DBQuery
+	)
+	collect := func(ctx context.Context, dbQuery collector.DBQuery) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, dbQuery)
+	}
+
+	options := sqlloggeradapter.Options{Language: "stub", Database: "app", Tags: map[string]string{"replica": "primary"}}
+	db := sql.OpenDB(sqlloggeradapter.LoggingConnector(collect, stubConnector{}, options))
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "UPDATE todos SET completed = 1")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM todos")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+
+	_, err = db.ExecContext(ctx, "FAIL")
+	require.Error(t, err)
+
+	stmt, err := db.PrepareContext(ctx, "SELECT id FROM todos WHERE id = ?")
+	require.NoError(t, err)
+	for range 2 {
+		rows, err := stmt.QueryContext(ctx, 1)
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Close())
+	}
+	require.NoError(t, stmt.Close())
+
+	require.Len(t, queries, 5)
+
+	assert.Equal(t, "UPDATE todos SET completed = 1", queries[0].Query)
+	assert.Equal(t, "stub", queries[0].Language)
+	assert.Equal(t, "app", queries[0].Database)
+	assert.Equal(t, map[string]string{"replica": "primary"}, queries[0].Tags)
+	assert.NotZero(t, queries[0].ConnID)
+	assert.Zero(t, queries[0].TxID)
+	require.NotNil(t, queries[0].RowsAffected)
+	assert.Equal(t, int64(3), *queries[0].RowsAffected)
+	assert.Nil(t, queries[0].RowsReturned)
+
+	assert.Equal(t, "SELECT id FROM todos", queries[1].Query)
+	require.NotNil(t, queries[1].RowsReturned)
+	assert.Equal(t, int64(3), *queries[1].RowsReturned)
+	assert.False(t, queries[1].Prepared)
+
+	assert.Equal(t, "FAIL", queries[2].Query)
+	assert.EqualError(t, queries[2].Error, "stub error")
+
+	assert.True(t, queries[3].Prepared)
+	assert.False(t, queries[3].StatementReused)
+	require.NotNil(t, queries[3].RowsReturned)
+	assert.Equal(t, int64(3), *queries[3].RowsReturned)
+
+	assert.True(t, queries[4].Prepared)
+	assert.True(t, queries[4].StatementReused)
+}
+
+func TestLoggingConnector_Transaction(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []collector.DBQuery
+	)
+	collect := func(ctx context.Context, dbQuery collector.DBQuery) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, dbQuery)
+	}
+
+	db := sql.OpenDB(sqlloggeradapter.LoggingConnector(collect, stubConnector{}, sqlloggeradapter.Options{}))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "UPDATE todos SET completed = 1")
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "DELETE FROM todos")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	_, err = db.ExecContext(ctx, "UPDATE todos SET completed = 0")
+	require.NoError(t, err)
+
+	require.Len(t, queries, 3)
+
+	assert.NotZero(t, queries[0].TxID)
+	assert.Equal(t, queries[0].TxID, queries[1].TxID)
+	assert.Equal(t, queries[0].ConnID, queries[1].ConnID)
+
+	assert.Zero(t, queries[2].TxID, "query after commit is not in the transaction")
+	assert.Equal(t, queries[0].ConnID, queries[2].ConnID)
+}
+
+type stubConnector struct{}
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn{}, nil }
+func (c stubConnector) Driver() driver.Driver                        { return nil }
+
+// stubConn affects and returns 3 rows for every statement and fails for the query "FAIL"
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt{}, nil }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return stubTx{}, nil }
+
+func (stubConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "FAIL" {
+		return nil, errors.New("stub error")
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (stubConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "FAIL" {
+		return nil, errors.New("stub error")
+	}
+	return &stubRows{}, nil
+}
+
+type stubTx struct{}
+
+func (stubTx) Commit() error   { return nil }
+func (stubTx) Rollback() error { return nil }
+
+type stubStmt struct{}
+
+func (stubStmt) Close() error                               { return nil }
+func (stubStmt) NumInput() int                              { return -1 }
+func (stubStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(3), nil }
+func (stubStmt) Query([]driver.Value) (driver.Rows, error)  { return &stubRows{}, nil }
+
+type stubRows struct {
+	n int
+}
+
+func (r *stubRows) Columns() []string { return []string{"id"} }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.n == 3 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = int64(r.n)
+	return nil
+}
+
+func TestLoggingConnector_MinimalDriver(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []collector.DBQuery
+	)
+	collect := func(ctx context.Context, dbQuery collector.DBQuery) {
+		mu.Lock()
+		defer mu.Unlock()
+		queries = append(queries, dbQuery)
+	}
+
+	db := sql.OpenDB(sqlloggeradapter.LoggingConnector(collect, minimalConnector{}, sqlloggeradapter.Options{}))
+	defer db.Close()
+	ctx := context.Background()
+
+	// Without the optional interfaces, database/sql falls back to prepared statements and the default behavior
+	require.NoError(t, db.PingContext(ctx))
+
+	_, err := db.ExecContext(ctx, "UPDATE todos SET completed = 1")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM todos", 1)
+	require.NoError(t, err)
+	columnTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Len(t, columnTypes, 1)
+	assert.Empty(t, columnTypes[0].DatabaseTypeName())
+	for rows.Next() {
+	}
+	assert.False(t, rows.NextResultSet())
+	require.NoError(t, rows.Close())
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	_, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	assert.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, queries, 2)
+	assert.True(t, queries[0].Prepared)
+	require.NotNil(t, queries[0].RowsAffected)
+	assert.Equal(t, int64(3), *queries[0].RowsAffected)
+	require.NotNil(t, queries[1].RowsReturned)
+	assert.Equal(t, int64(3), *queries[1].RowsReturned)
+}
+
+type minimalConnector struct{}
+
+func (c minimalConnector) Connect(context.Context) (driver.Conn, error) { return minimalConn{}, nil }
+func (c minimalConnector) Driver() driver.Driver                        { return nil }
+
+// minimalConn implements only driver.Conn without any optional interface
+type minimalConn struct{}
+
+func (minimalConn) Prepare(query string) (driver.Stmt, error) { return minimalStmt{}, nil }
+func (minimalConn) Close() error                              { return nil }
+func (minimalConn) Begin() (driver.Tx, error)                 { return stubTx{}, nil }
+
+// minimalStmt implements only driver.Stmt, its rows only driver.Rows
+type minimalStmt struct{}
+
+func (minimalStmt) Close() error                               { return nil }
+func (minimalStmt) NumInput() int                              { return -1 }
+func (minimalStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(3), nil }
+func (minimalStmt) Query([]driver.Value) (driver.Rows, error)  { return &stubRows{}, nil }
@@ -3,6 +3,7 @@ package sqlloggeradapter
 import (
 	"context"
 	"database/sql/driver"
+	"sync"
 	"time"
 
 	"github.com/networkteam/go-sqllogger"
@@ -13,91 +14,175 @@ import (
 type Options struct {
 	// SQL language / dialect for highlighting and formatting
 	Language string
+	// Database is the name of the database set on every query, to tell apart queries of multiple databases
+	Database string
+	// Tags are set on every query, e.g. to filter queries of a replica in the dashboard
+	Tags map[string]string
 }
 
+// New creates a sqllogger.SQLLogger that collects queries with the given collect function.
+// Use LoggingConnector instead to also collect result metadata and errors of queries.
 func New(collect func(ctx context.Context, dbQuery collector.DBQuery), options Options) sqllogger.SQLLogger {
+	return newAdapter(collect, options)
+}
+
+func newAdapter(collect func(ctx context.Context, dbQuery collector.DBQuery), options Options) *adapter {
 	return &adapter{
-		collect: collect,
-		options: options,
+		collect:  collect,
+		options:  options,
+		stmtUses: make(map[int64]int),
+		stmtConn: make(map[int64]int64),
+		connTx:   make(map[int64]int64),
+		txConn:   make(map[int64]int64),
 	}
 }
 
 type adapter struct {
 	collect func(ctx context.Context, dbQuery collector.DBQuery)
 	options Options
+
+	// recordsResults is set if queries are collected by LoggingConnector after the result is known
+	recordsResults bool
+
+	// stmtUses counts executions of prepared statements by statement ID
+	stmtUses map[int64]int
+	// stmtConn is the connection of prepared statements by statement ID
+	stmtConn map[int64]int64
+	// connTx and txConn are the open transactions by connection ID and their connections by transaction ID
+	connTx map[int64]int64
+	txConn map[int64]int64
+	mu     sync.Mutex
+}
+
+// newQuery returns a query with the options of the adapter and the connection and its open transaction
+func (a *adapter) newQuery(connID int64, query string, args []driver.NamedValue) collector.DBQuery {
+	a.mu.Lock()
+	txID := a.connTx[connID]
+	a.mu.Unlock()
+
+	return collector.DBQuery{
+		Query:    query,
+		Language: a.options.Language,
+		Database: a.options.Database,
+		Tags:     a.options.Tags,
+		Args:     args,
+		ConnID:   connID,
+		TxID:     txID,
+	}
+}
+
+// newStmtQuery returns a query of a prepared statement on the connection it was prepared on
+func (a *adapter) newStmtQuery(stmtID int64, query string, args []driver.NamedValue) collector.DBQuery {
+	a.mu.Lock()
+	connID := a.stmtConn[stmtID]
+	a.mu.Unlock()
+
+	dbQuery := a.newQuery(connID, query, args)
+	dbQuery.Prepared = true
+	dbQuery.StatementReused = a.useStmt(stmtID)
+	return dbQuery
+}
+
+// endTx forgets a committed or rolled back transaction
+func (a *adapter) endTx(txID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if connID, ok := a.txConn[txID]; ok && a.connTx[connID] == txID {
+		delete(a.connTx, connID)
+	}
+	delete(a.txConn, txID)
+}
+
+// emit hands over the query to a pending result recorder if present, otherwise collects it directly
+func (a *adapter) emit(ctx context.Context, dbQuery collector.DBQuery) {
+	if p, ok := pendingQueryFromContext(ctx); ok {
+		p.query = &dbQuery
+		return
+	}
+	// Fallbacks of go-sqllogger for drivers without context support log without the original context,
+	// the result recorder collects these queries itself
+	if a.recordsResults {
+		return
+	}
+	a.collect(ctx, dbQuery)
+}
+
+// useStmt records an execution of a prepared statement and returns whether it was executed before
+func (a *adapter) useStmt(stmtID int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stmtUses[stmtID]++
+	return a.stmtUses[stmtID] > 1
 }
 
 // ConnBegin implements sqllogger.SQLLogger.
 func (a *adapter) ConnBegin(ctx context.Context, connID int64, txID int64, opts driver.TxOptions) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connTx[connID] = txID
+	a.txConn[txID] = connID
 }
 
 // ConnClose implements sqllogger.SQLLogger.
 func (a *adapter) ConnClose(ctx context.Context, connID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if txID, ok := a.connTx[connID]; ok {
+		delete(a.txConn, txID)
+		delete(a.connTx, connID)
+	}
 }
 
 // ConnExec implements sqllogger.SQLLogger.
 func (a *adapter) ConnExec(ctx context.Context, connID int64, query string, args []driver.Value) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      toNamedValues(args),
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newQuery(connID, query, toNamedValues(args))
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // ConnExecContext implements sqllogger.SQLLogger.
 func (a *adapter) ConnExecContext(ctx context.Context, connID int64, query string, args []driver.NamedValue) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      args,
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newQuery(connID, query, args)
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // ConnPrepare implements sqllogger.SQLLogger.
 func (a *adapter) ConnPrepare(ctx context.Context, connID int64, stmtID int64, query string) {
+	a.prepareStmt(connID, stmtID)
 }
 
 // ConnPrepareContext implements sqllogger.SQLLogger.
 func (a *adapter) ConnPrepareContext(ctx context.Context, connID int64, stmtID int64, query string) {
+	a.prepareStmt(connID, stmtID)
+}
 
+// prepareStmt records the connection of a prepared statement
+func (a *adapter) prepareStmt(connID int64, stmtID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stmtConn[stmtID] = connID
 }
 
 // ConnQuery implements sqllogger.SQLLogger.
 func (a *adapter) ConnQuery(ctx context.Context, connID int64, rowsID int64, query string, args []driver.Value) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      toNamedValues(args),
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newQuery(connID, query, toNamedValues(args))
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // ConnQueryContext implements sqllogger.SQLLogger.
 func (a *adapter) ConnQueryContext(ctx context.Context, connID int64, rowsID int64, query string, args []driver.NamedValue) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      args,
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newQuery(connID, query, args)
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // Connect implements sqllogger.SQLLogger.
 func (a *adapter) Connect(ctx context.Context, connID int64) {
+	if pc, ok := pendingConnFromContext(ctx); ok {
+		pc.connID = connID
+	}
 }
 
 // RowsClose implements sqllogger.SQLLogger.
@@ -106,61 +191,48 @@ func (a *adapter) RowsClose(ctx context.Context, rowsID int64) {
 
 // StmtClose implements sqllogger.SQLLogger.
 func (a *adapter) StmtClose(ctx context.Context, stmtID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.stmtUses, stmtID)
+	delete(a.stmtConn, stmtID)
 }
 
 // StmtExec implements sqllogger.SQLLogger.
 func (a *adapter) StmtExec(ctx context.Context, stmtID int64, query string, args []driver.Value) {
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      toNamedValues(args),
-		Timestamp: time.Now(),
-	})
-
+	dbQuery := a.newStmtQuery(stmtID, query, toNamedValues(args))
+	dbQuery.Timestamp = time.Now()
+	a.emit(ctx, dbQuery)
 }
 
 // StmtExecContext implements sqllogger.SQLLogger.
 func (a *adapter) StmtExecContext(ctx context.Context, stmtID int64, query string, args []driver.NamedValue) {
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      args,
-		Timestamp: time.Now(),
-	})
+	dbQuery := a.newStmtQuery(stmtID, query, args)
+	dbQuery.Timestamp = time.Now()
+	a.emit(ctx, dbQuery)
 }
 
 // StmtQuery implements sqllogger.SQLLogger.
 func (a *adapter) StmtQuery(ctx context.Context, stmtID int64, rowsID int64, query string, args []driver.Value) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      toNamedValues(args),
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newStmtQuery(stmtID, query, toNamedValues(args))
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // StmtQueryContext implements sqllogger.SQLLogger.
 func (a *adapter) StmtQueryContext(ctx context.Context, stmtID int64, rowsID int64, query string, args []driver.NamedValue) {
-	timestamp, duration := timingFromContext(ctx)
-
-	a.collect(ctx, collector.DBQuery{
-		Query:     query,
-		Language:  a.options.Language,
-		Args:      args,
-		Timestamp: timestamp,
-		Duration:  duration,
-	})
+	dbQuery := a.newStmtQuery(stmtID, query, args)
+	dbQuery.Timestamp, dbQuery.Duration = timingFromContext(ctx)
+	a.emit(ctx, dbQuery)
 }
 
 // TxCommit implements sqllogger.SQLLogger.
 func (a *adapter) TxCommit(ctx context.Context, txID int64) {
+	a.endTx(txID)
 }
 
 // TxRollback implements sqllogger.SQLLogger.
 func (a *adapter) TxRollback(ctx context.Context, txID int64) {
+	a.endTx(txID)
 }
 
 var _ sqllogger.SQLLogger = &adapter{}
@@ -1,9 +1,14 @@
+//go:build !devlog_off
+
 package devlog
 
 import (
 	"context"
+	"database/sql"
 	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/networkteam/devlog/collector"
 	"github.com/networkteam/devlog/dashboard"
@@ -14,45 +19,62 @@ type Instance struct {
 	httpClientCollector *collector.HTTPClientCollector
 	httpServerCollector *collector.HTTPServerCollector
 	dbQueryCollector    *collector.DBQueryCollector
+	rpcCallCollector    *collector.RPCCallCollector
+	cacheOpCollector    *collector.CacheOpCollector
+	flagCollector       *collector.FlagEvaluationCollector
+	mailCollector       *collector.MailCollector
+	dbPoolSampler       *collector.DBPoolSampler
 	eventAggregator     *collector.EventAggregator
 
 	dashboardHandler *dashboard.Handler
+	dashboardServer  *http.Server // nil unless the dashboard is served on its own listener
+
+	control   captureControl
+	closeOnce sync.Once
+}
+
+// Shutdown ends open dashboard streams, waits until requests in progress are collected and closes the instance.
+// A dashboard server created with DashboardServer is shut down gracefully as well.
+// If ctx is done before all requests are collected, the instance is closed anyway and the error of ctx is returned.
+// Shut down the HTTP server in parallel, so no new requests are started (see http.Server.RegisterOnShutdown).
+func (i *Instance) Shutdown(ctx context.Context) error {
+	if i.dashboardHandler != nil {
+		i.dashboardHandler.CloseStreams()
+	}
+	err := i.eventAggregator.Drain(ctx)
+	if serverErr := i.shutdownDashboardServer(ctx); err == nil {
+		err = serverErr
+	}
+	i.Close()
+	return err
 }
 
+// Close stops collecting and closes the dashboard, events of requests in progress are discarded.
+// It is safe to call Close multiple times.
 func (i *Instance) Close() {
+	i.closeOnce.Do(i.close)
+}
+
+func (i *Instance) close() {
+	i.stopControlledCapture()
 	i.logCollector.Close()
 	i.httpClientCollector.Close()
 	i.httpServerCollector.Close()
 	i.dbQueryCollector.Close()
+	i.rpcCallCollector.Close()
+	i.cacheOpCollector.Close()
+	i.flagCollector.Close()
+	i.mailCollector.Close()
+	i.dbPoolSampler.Close()
+	if i.dashboardServer != nil {
+		i.dashboardServer.Close()
+	}
 	if i.dashboardHandler != nil {
 		i.dashboardHandler.Close()
 	}
 	i.eventAggregator.Close()
 }
 
-type Options struct {
-	// LogOptions are the options for the log collector.
-	// Default: nil, will use collector.DefaultLogOptions()
-	LogOptions *collector.LogOptions
-
-	// HTTPClientOptions are the options for the HTTP client collector.
-	// Default: nil, will use collector.DefaultHTTPClientOptions()
-	HTTPClientOptions *collector.HTTPClientOptions
-
-	// HTTPServerOptions are the options for the HTTP server collector.
-	// Default: nil, will use collector.DefaultHTTPServerOptions()
-	HTTPServerOptions *collector.HTTPServerOptions
-
-	// DBQueryOptions are the options for the database query collector.
-	// Default: nil, will use collector.DefaultDBQueryOptions()
-	DBQueryOptions *collector.DBQueryOptions
-}
-
-// New creates a new devlog dashboard with default options.
-func New() *Instance {
-	return NewWithOptions(Options{})
-}
-
 // NewWithOptions creates a new devlog dashboard with the specified options.
 // Default options are the zero value of Options.
 //
@@ -60,7 +82,21 @@ func New() *Instance {
 // through the dashboard. Events are collected per-user with isolation.
 func NewWithOptions(options Options) *Instance {
 	// Create the central EventAggregator (no storage by default)
-	eventAggregator := collector.NewEventAggregator()
+	eventAggregator := collector.NewEventAggregatorWithOptions(collector.EventAggregatorOptions{
+		Logger:             options.Logger,
+		Processors:         options.EventProcessors,
+		SeverityThresholds: options.SeverityThresholds,
+	})
+	if options.Disabled {
+		eventAggregator.SetEnabled(false)
+	}
+
+	// Bodies of both HTTP collectors share one pool, so their memory is limited in total
+	bodyBufferPoolSize := options.BodyBufferPoolSize
+	if bodyBufferPoolSize == 0 {
+		bodyBufferPoolSize = collector.DefaultBodyBufferPoolSize
+	}
+	bodyBufferPool := collector.NewBodyBufferPool(bodyBufferPoolSize)
 
 	logOptions := collector.DefaultLogOptions()
 	if options.LogOptions != nil {
@@ -73,12 +109,18 @@ func NewWithOptions(options Options) *Instance {
 		httpClientOptions = *options.HTTPClientOptions
 	}
 	httpClientOptions.EventAggregator = eventAggregator
+	if httpClientOptions.BodyBufferPool == nil {
+		httpClientOptions.BodyBufferPool = bodyBufferPool
+	}
 
 	httpServerOptions := collector.DefaultHTTPServerOptions()
 	if options.HTTPServerOptions != nil {
 		httpServerOptions = *options.HTTPServerOptions
 	}
 	httpServerOptions.EventAggregator = eventAggregator
+	if httpServerOptions.BodyBufferPool == nil {
+		httpServerOptions.BodyBufferPool = bodyBufferPool
+	}
 
 	dbQueryOptions := collector.DefaultDBQueryOptions()
 	if options.DBQueryOptions != nil {
@@ -86,16 +128,67 @@ func NewWithOptions(options Options) *Instance {
 	}
 	dbQueryOptions.EventAggregator = eventAggregator
 
+	rpcCallOptions := collector.DefaultRPCCallOptions()
+	if options.RPCCallOptions != nil {
+		rpcCallOptions = *options.RPCCallOptions
+	}
+	rpcCallOptions.EventAggregator = eventAggregator
+
+	cacheOpOptions := collector.DefaultCacheOpOptions()
+	if options.CacheOpOptions != nil {
+		cacheOpOptions = *options.CacheOpOptions
+	}
+	cacheOpOptions.EventAggregator = eventAggregator
+
+	flagEvaluationOptions := collector.DefaultFlagEvaluationOptions()
+	if options.FlagEvaluationOptions != nil {
+		flagEvaluationOptions = *options.FlagEvaluationOptions
+	}
+	flagEvaluationOptions.EventAggregator = eventAggregator
+
+	mailOptions := collector.DefaultMailOptions()
+	if options.MailOptions != nil {
+		mailOptions = *options.MailOptions
+	}
+	mailOptions.EventAggregator = eventAggregator
+
+	dbPoolSamplerOptions := collector.DefaultDBPoolSamplerOptions()
+	if options.DBPoolSamplerOptions != nil {
+		dbPoolSamplerOptions = *options.DBPoolSamplerOptions
+	}
+	dbPoolSamplerOptions.EventAggregator = eventAggregator
+
 	instance := &Instance{
 		logCollector:        collector.NewLogCollectorWithOptions(logOptions),
 		httpClientCollector: collector.NewHTTPClientCollectorWithOptions(httpClientOptions),
 		httpServerCollector: collector.NewHTTPServerCollectorWithOptions(httpServerOptions),
 		dbQueryCollector:    collector.NewDBQueryCollectorWithOptions(dbQueryOptions),
+		rpcCallCollector:    collector.NewRPCCallCollectorWithOptions(rpcCallOptions),
+		cacheOpCollector:    collector.NewCacheOpCollectorWithOptions(cacheOpOptions),
+		flagCollector:       collector.NewFlagEvaluationCollectorWithOptions(flagEvaluationOptions),
+		mailCollector:       collector.NewMailCollectorWithOptions(mailOptions),
+		dbPoolSampler:       collector.NewDBPoolSamplerWithOptions(dbPoolSamplerOptions),
 		eventAggregator:     eventAggregator,
 	}
 	return instance
 }
 
+// Enable resumes collecting after Disable.
+func (i *Instance) Enable() {
+	i.eventAggregator.SetEnabled(true)
+}
+
+// Disable turns all collectors into a pass-through at runtime: requests, logs and queries are not inspected, copied or
+// captured until Enable is called, so a dormant instance adds almost no overhead. Sessions and their events are kept.
+func (i *Instance) Disable() {
+	i.eventAggregator.SetEnabled(false)
+}
+
+// Enabled returns false while collecting is disabled (see Disable)
+func (i *Instance) Enabled() bool {
+	return i.eventAggregator.Enabled()
+}
+
 // CollectSlogLogs returns a slog.Handler that collects logs into devlog.
 //
 // You can use this handler with slog.New(slogmulti.Fanout(...)) to collect logs into devlog in addition to another slog handler.
@@ -104,11 +197,13 @@ func (i *Instance) CollectSlogLogs(options collector.CollectSlogLogsOptions) slo
 }
 
 // CollectHTTPClient wraps an http.RoundTripper to collect outgoing HTTP requests.
+// A transport that is already wrapped by the instance is returned unchanged (see collector.IsCapturingTransport).
 func (i *Instance) CollectHTTPClient(transport http.RoundTripper) http.RoundTripper {
 	return i.httpClientCollector.Transport(transport)
 }
 
 // CollectHTTPServer wraps an http.Handler to collect incoming HTTP requests.
+// A handler that is already wrapped by the instance is returned unchanged (see collector.IsCapturingHandler).
 func (i *Instance) CollectHTTPServer(handler http.Handler) http.Handler {
 	return i.httpServerCollector.Middleware(handler)
 }
@@ -118,6 +213,48 @@ func (i *Instance) CollectDBQuery() func(ctx context.Context, dbQuery collector.
 	return i.dbQueryCollector.Collect
 }
 
+// CollectRPCCall allows to integrate an adapter to collect RPC calls
+func (i *Instance) CollectRPCCall() func(ctx context.Context, call collector.RPCCall) {
+	return i.rpcCallCollector.Collect
+}
+
+// CollectCacheOp collects an operation on a cache (e.g. Redis, memcached or an in-memory cache), which is grouped under
+// the current request of ctx
+func (i *Instance) CollectCacheOp(ctx context.Context, op collector.CacheOp) {
+	i.cacheOpCollector.Collect(ctx, op)
+}
+
+// CollectFlagEvaluation collects an evaluation of a feature flag or a lookup of a configuration value, which is grouped
+// under the current request of ctx. Use flagadapter.NewHook to collect the evaluations of a feature flag SDK.
+func (i *Instance) CollectFlagEvaluation(ctx context.Context, evaluation collector.FlagEvaluation) {
+	i.flagCollector.Collect(ctx, evaluation)
+}
+
+// CollectMail collects a sent email, which is grouped under the current request of ctx. Use mailadapter to collect
+// mails sent with net/smtp or a mail library.
+func (i *Instance) CollectMail(ctx context.Context, mail collector.Mail) {
+	i.mailCollector.Collect(ctx, mail)
+}
+
+// CollectEvent collects an event with custom data, which is grouped under the current request of ctx.
+// Use dashboard.RegisterEventRenderer to customize how the data is shown in the dashboard.
+func (i *Instance) CollectEvent(ctx context.Context, data any) {
+	i.eventAggregator.CollectEvent(ctx, data)
+}
+
+// CollectDBPoolStats periodically samples the connection pool statistics of db under the given name.
+// The latest stats are shown in the dashboard and an event is collected if a threshold is exceeded.
+func (i *Instance) CollectDBPoolStats(name string, db *sql.DB) {
+	i.dbPoolSampler.Register(name, db)
+}
+
+// NewTaskGroup returns a group to run concurrent operations of the event of ctx (e.g. the incoming request) in goroutines
+// and a context that is canceled when one of them fails (see collector.TaskGroup). Each operation is collected as a child
+// event with its own duration.
+func (i *Instance) NewTaskGroup(ctx context.Context) (*collector.TaskGroup, context.Context) {
+	return collector.NewTaskGroup(ctx, i.eventAggregator)
+}
+
 // DashboardHandler creates a dashboard handler mounted at the given path prefix.
 // Use functional options from the dashboard package to customize behavior:
 //
@@ -126,8 +263,16 @@ func (i *Instance) CollectDBQuery() func(ctx context.Context, dbQuery collector.
 //	    dashboard.WithSessionIdleTimeout(time.Minute),
 //	)
 func (i *Instance) DashboardHandler(pathPrefix string, opts ...dashboard.HandlerOption) http.Handler {
-	// Prepend WithPathPrefix to user-provided options
-	allOpts := append([]dashboard.HandlerOption{dashboard.WithPathPrefix(pathPrefix)}, opts...)
+	// Requests of the dashboard are not captured if it is mounted inside a handler wrapped by CollectHTTPServer
+	if pathPrefix != "" {
+		i.httpServerCollector.SkipPath(strings.TrimSuffix(pathPrefix, "/") + "/")
+	}
+	// Prepend WithPathPrefix to user-provided options, replayed requests are captured by the instance
+	allOpts := append([]dashboard.HandlerOption{
+		dashboard.WithPathPrefix(pathPrefix),
+		dashboard.WithDBPoolSampler(i.dbPoolSampler),
+		dashboard.WithReplayTransport(i.CollectHTTPClient(http.DefaultTransport)),
+	}, opts...)
 	handler := dashboard.NewHandler(i.eventAggregator, allOpts...)
 	i.dashboardHandler = handler
 	return handler
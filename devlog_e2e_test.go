@@ -1,3 +1,5 @@
+//go:build !devlog_off
+
 package devlog_test
 
 import (
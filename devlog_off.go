@@ -0,0 +1,198 @@
+//go:build devlog_off
+
+package devlog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/dashboard"
+)
+
+// This file replaces the instance with no-op stubs if built with the devlog_off build tag (go build -tags devlog_off).
+// Call sites compile unchanged, but nothing is collected and the dashboard and admin handlers are not served.
+
+// Instance is a no-op, devlog is compiled out with the devlog_off build tag
+type Instance struct{}
+
+// NewWithOptions returns a no-op instance, the options are ignored
+func NewWithOptions(options Options) *Instance {
+	return &Instance{}
+}
+
+// Shutdown does nothing
+func (i *Instance) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Close does nothing
+func (i *Instance) Close() {}
+
+// Enable does nothing, collecting is never enabled
+func (i *Instance) Enable() {}
+
+// Disable does nothing
+func (i *Instance) Disable() {}
+
+// Enabled always returns false
+func (i *Instance) Enabled() bool {
+	return false
+}
+
+// CollectSlogLogs returns a handler that discards all records
+func (i *Instance) CollectSlogLogs(options collector.CollectSlogLogsOptions) slog.Handler {
+	return discardHandler{}
+}
+
+// CollectHTTPClient returns transport unchanged
+func (i *Instance) CollectHTTPClient(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		return http.DefaultTransport
+	}
+	return transport
+}
+
+// CollectHTTPServer returns handler unchanged
+func (i *Instance) CollectHTTPServer(handler http.Handler) http.Handler {
+	return handler
+}
+
+// ReverseProxy returns a reverse proxy to upstream that does not capture requests
+func (i *Instance) ReverseProxy(upstream *url.URL) http.Handler {
+	return httputil.NewSingleHostReverseProxy(upstream)
+}
+
+// CollectReverseProxy returns proxy unchanged
+func (i *Instance) CollectReverseProxy(proxy *httputil.ReverseProxy) http.Handler {
+	return proxy
+}
+
+// CollectDBQuery returns a function that discards queries
+func (i *Instance) CollectDBQuery() func(ctx context.Context, dbQuery collector.DBQuery) {
+	return func(ctx context.Context, dbQuery collector.DBQuery) {}
+}
+
+// CollectRPCCall returns a function that discards calls
+func (i *Instance) CollectRPCCall() func(ctx context.Context, call collector.RPCCall) {
+	return func(ctx context.Context, call collector.RPCCall) {}
+}
+
+// CollectCacheOp does nothing
+func (i *Instance) CollectCacheOp(ctx context.Context, op collector.CacheOp) {}
+
+// CollectFlagEvaluation does nothing
+func (i *Instance) CollectFlagEvaluation(ctx context.Context, evaluation collector.FlagEvaluation) {}
+
+// CollectMail does nothing
+func (i *Instance) CollectMail(ctx context.Context, mail collector.Mail) {}
+
+// CollectEvent does nothing
+func (i *Instance) CollectEvent(ctx context.Context, data any) {}
+
+// CollectDBPoolStats does nothing
+func (i *Instance) CollectDBPoolStats(name string, db *sql.DB) {}
+
+// NewTaskGroup returns a group that runs the operations without collecting them (see collector.TaskGroup)
+func (i *Instance) NewTaskGroup(ctx context.Context) (*collector.TaskGroup, context.Context) {
+	return collector.NewTaskGroup(ctx, nil)
+}
+
+// RegisterSink does not forward events, unregister closes the sink
+func (i *Instance) RegisterSink(sink collector.Sink, options collector.SinkOptions) (unregister func()) {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			_ = sink.Close()
+		})
+	}
+}
+
+// OnEvent does not call fn, no events are captured
+func (i *Instance) OnEvent(fn func(ctx context.Context, event *collector.Event)) (unregister func()) {
+	return func() {}
+}
+
+// DashboardHandler returns a handler that responds with 404 Not Found
+func (i *Instance) DashboardHandler(pathPrefix string, opts ...dashboard.HandlerOption) http.Handler {
+	return http.NotFoundHandler()
+}
+
+// DashboardServer returns a server that responds with 404 Not Found, it is not started
+func (i *Instance) DashboardServer(addr string, opts ...dashboard.HandlerOption) *http.Server {
+	return &http.Server{Addr: addr, Handler: http.NotFoundHandler()}
+}
+
+// ServeDashboard does not listen and returns nil immediately
+func (i *Instance) ServeDashboard(addr string, opts ...dashboard.HandlerOption) error {
+	return nil
+}
+
+// AdminHandler returns a handler that responds with 404 Not Found
+func (i *Instance) AdminHandler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+// ToggleCaptureOnSignal does not handle the signals
+func (i *Instance) ToggleCaptureOnSignal(signals ...os.Signal) (stop func()) {
+	return func() {}
+}
+
+// CaptureHandle of a capture that never captures any events
+type CaptureHandle struct {
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartGlobalCapture returns a capture that never captures any events
+func (i *Instance) StartGlobalCapture(capacity uint64) (*CaptureHandle, error) {
+	if capacity == 0 {
+		return nil, errors.New("capacity must be greater than zero")
+	}
+	return &CaptureHandle{done: make(chan struct{})}, nil
+}
+
+// Events always returns no events
+func (h *CaptureHandle) Events() []*collector.Event {
+	return nil
+}
+
+// ReadEvents always returns no events
+func (h *CaptureHandle) ReadEvents(opts collector.ReadOptions) []*collector.Event {
+	return nil
+}
+
+// Subscribe returns a channel that is closed when ctx is done or the capture is stopped
+func (h *CaptureHandle) Subscribe(ctx context.Context) <-chan *collector.Event {
+	ch := make(chan *collector.Event)
+	go func() {
+		defer close(ch)
+		select {
+		case <-ctx.Done():
+		case <-h.done:
+		}
+	}()
+	return ch
+}
+
+// Stop closes all subscriptions, it is safe to call multiple times
+func (h *CaptureHandle) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.done)
+	})
+}
+
+// discardHandler is a slog.Handler that is never enabled
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
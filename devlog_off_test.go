@@ -0,0 +1,71 @@
+//go:build devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/networkteam/devlog"
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInstance_DevlogOff(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	if wrapped := dlog.CollectHTTPServer(handler); wrapped == nil || collector.IsCapturingHandler(wrapped) {
+		t.Error("expected handler to be returned unchanged")
+	}
+	if transport := dlog.CollectHTTPClient(nil); transport != http.DefaultTransport {
+		t.Error("expected default transport to be returned")
+	}
+	if dlog.CollectSlogLogs(collector.CollectSlogLogsOptions{}).Enabled(context.Background(), 100) {
+		t.Error("expected log handler to be disabled")
+	}
+	if dlog.Enabled() {
+		t.Error("expected instance to be disabled")
+	}
+
+	for name, h := range map[string]http.Handler{
+		"dashboard": dlog.DashboardHandler("/_devlog"),
+		"admin":     dlog.AdminHandler(),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_devlog/", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected %s to respond with status %d, got %d", name, http.StatusNotFound, rec.Code)
+		}
+	}
+
+	// Operations of task groups and goroutines still run
+	group, ctx := dlog.NewTaskGroup(context.Background())
+	ran := make(chan struct{})
+	group.Go("task", func(ctx context.Context) error {
+		close(ran)
+		return nil
+	})
+	if err := group.Wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	<-ran
+
+	done := make(chan struct{})
+	devlog.GoWithContext(ctx, func(ctx context.Context) {
+		close(done)
+	})
+	<-done
+
+	capture, err := dlog.StartGlobalCapture(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events := capture.Subscribe(context.Background())
+	capture.Stop()
+	if _, ok := <-events; ok {
+		t.Error("expected subscription to be closed without events")
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
-	"github.com/networkteam/go-sqllogger"
 	slogmulti "github.com/samber/slog-multi"
 
 	"github.com/networkteam/devlog"
@@ -47,7 +46,10 @@ func main() {
 		slogmulti.Fanout(
 			// Collect debug logs with devlog
 			dlog.CollectSlogLogs(collector.CollectSlogLogsOptions{
-				Level: slog.LevelDebug,
+				Level:      slog.LevelDebug,
+				AddSource:  true,
+				StackLevel: slog.LevelError,
+				SourceURL:  "vscode://file/{file}:{line}",
 			}),
 			// Log info to stderr
 			slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
@@ -59,9 +61,10 @@ func main() {
 
 	// Initialize SQLite database
 	connector := newSQLiteConnector(":memory:")
-	loggingConnector := sqllogger.LoggingConnector(sqlloggeradapter.New(dlog.CollectDBQuery(), sqlloggeradapter.Options{
+	loggingConnector := sqlloggeradapter.LoggingConnector(dlog.CollectDBQuery(), connector, sqlloggeradapter.Options{
 		Language: "sqlite",
-	}), connector)
+		Database: "todos",
+	})
 
 	db := sql.OpenDB(loggingConnector)
 	defer db.Close()
@@ -0,0 +1,77 @@
+// Package flagadapter collects evaluations of feature flag SDKs that follow the hook lifecycle of OpenFeature. It does
+// not depend on an SDK: a hook registered with the SDK converts its evaluation details and calls Hook.Finally.
+package flagadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// EvaluationDetails are the details of a finished flag evaluation, modelled after the evaluation details of OpenFeature
+type EvaluationDetails struct {
+	// FlagKey is the key of the evaluated flag
+	FlagKey string
+	// Value is the evaluated value, or the default value if the evaluation failed
+	Value any
+	// Variant is the name of the evaluated variant (optional)
+	Variant string
+	// Reason why the value was chosen, e.g. "TARGETING_MATCH" (optional)
+	Reason string
+	// ErrorCode and ErrorMessage are set if the evaluation failed, e.g. "FLAG_NOT_FOUND"
+	ErrorCode    string
+	ErrorMessage string
+	// ProviderName is the name of the provider that evaluated the flag (optional)
+	ProviderName string
+}
+
+// Hook collects flag evaluations with the given collect function
+type Hook struct {
+	collect func(ctx context.Context, evaluation collector.FlagEvaluation)
+}
+
+// NewHook creates a hook that collects evaluations with collect, e.g. devlog.Instance.CollectFlagEvaluation.
+func NewHook(collect func(ctx context.Context, evaluation collector.FlagEvaluation)) *Hook {
+	return &Hook{collect: collect}
+}
+
+// Finally collects a finished evaluation, call it in the finally stage of a hook of the SDK. The context must be the
+// context of the evaluation to group it under the current request.
+func (h *Hook) Finally(ctx context.Context, details EvaluationDetails) {
+	h.collect(ctx, Evaluation(details))
+}
+
+// Evaluation converts the details of an evaluation to a collector.FlagEvaluation. String values are kept as they are,
+// other values are formatted as JSON.
+func Evaluation(details EvaluationDetails) collector.FlagEvaluation {
+	evaluation := collector.FlagEvaluation{
+		Flag:     details.FlagKey,
+		Value:    formatValue(details.Value),
+		Variant:  details.Variant,
+		Reason:   details.Reason,
+		Provider: details.ProviderName,
+	}
+	switch {
+	case details.ErrorCode != "" && details.ErrorMessage != "":
+		evaluation.Error = fmt.Errorf("%s: %s", details.ErrorCode, details.ErrorMessage)
+	case details.ErrorCode != "":
+		evaluation.Error = errors.New(details.ErrorCode)
+	case details.ErrorMessage != "":
+		evaluation.Error = errors.New(details.ErrorMessage)
+	}
+	return evaluation
+}
+
+func formatValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,79 @@
+package flagadapter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/flagadapter"
+)
+
+func TestHook_Finally(t *testing.T) {
+	flagCollector := collector.NewFlagEvaluationCollector()
+	defer flagCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evaluations := flagCollector.Subscribe(ctx)
+
+	hook := flagadapter.NewHook(flagCollector.Collect)
+
+	hook.Finally(ctx, flagadapter.EvaluationDetails{
+		FlagKey:      "new-checkout",
+		Value:        true,
+		Variant:      "on",
+		Reason:       "TARGETING_MATCH",
+		ProviderName: "flagd",
+	})
+
+	evaluation := <-evaluations
+	assert.Equal(t, "new-checkout", evaluation.Flag)
+	assert.Equal(t, "true", evaluation.Value)
+	assert.Equal(t, "on", evaluation.Variant)
+	assert.Equal(t, "TARGETING_MATCH", evaluation.Reason)
+	assert.Equal(t, "flagd", evaluation.Provider)
+	assert.False(t, evaluation.Timestamp.IsZero())
+	assert.NoError(t, evaluation.Error)
+}
+
+func TestEvaluation(t *testing.T) {
+	tests := []struct {
+		name          string
+		details       flagadapter.EvaluationDetails
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "string value",
+			details:       flagadapter.EvaluationDetails{FlagKey: "theme", Value: "dark"},
+			expectedValue: "dark",
+		},
+		{
+			name:          "object value",
+			details:       flagadapter.EvaluationDetails{FlagKey: "limits", Value: map[string]int{"items": 10}},
+			expectedValue: `{"items":10}`,
+		},
+		{
+			name:          "error",
+			details:       flagadapter.EvaluationDetails{FlagKey: "beta", Value: false, Reason: "ERROR", ErrorCode: "FLAG_NOT_FOUND", ErrorMessage: "flag beta not found"},
+			expectedValue: "false",
+			expectedError: "FLAG_NOT_FOUND: flag beta not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation := flagadapter.Evaluation(tt.details)
+			assert.Equal(t, tt.expectedValue, evaluation.Value)
+			if tt.expectedError == "" {
+				assert.NoError(t, evaluation.Error)
+			} else {
+				require.Error(t, evaluation.Error)
+				assert.Equal(t, tt.expectedError, evaluation.Error.Error())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ go 1.23.8
 require (
 	github.com/a-h/templ v0.3.865
 	github.com/alecthomas/chroma/v2 v2.17.2
+	github.com/andybalholm/brotli v1.1.1
 	github.com/gofrs/uuid v4.4.0+incompatible
 	github.com/networkteam/refresh v1.15.0
 	github.com/samber/lo v1.50.0
@@ -13,7 +14,6 @@ require (
 
 require (
 	github.com/a-h/parse v0.0.0-20250122154542-74294addb73e // indirect
-	github.com/andybalholm/brotli v1.1.1 // indirect
 	github.com/apex/log v1.9.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/cli/browser v1.3.0 // indirect
@@ -0,0 +1,33 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"context"
+	"sync"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// OnEvent calls fn with every completed top-level event captured by the instance, independent of dashboard sessions,
+// e.g. for an editor plugin or a TUI embedded in the same process. fn is called on a separate goroutine in the order
+// events are collected (see collector.HookStorage), it must not modify the event. Events are dropped while too many
+// are waiting for fn. Call unregister to stop, which cancels ctx of a running call and waits until it returns.
+// Hooks that are still registered are unregistered when the instance is closed.
+//
+//	unregister := dlog.OnEvent(func(ctx context.Context, event *collector.Event) {
+//	    fmt.Println(event.Kind(), event.End.Sub(event.Start))
+//	})
+//	defer unregister()
+func (i *Instance) OnEvent(fn func(ctx context.Context, event *collector.Event)) (unregister func()) {
+	storage := collector.NewHookStorage(fn, collector.HookOptions{})
+	i.eventAggregator.RegisterStorage(storage)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			i.eventAggregator.UnregisterStorage(storage.ID())
+			storage.Close()
+		})
+	}
+}
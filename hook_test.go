@@ -0,0 +1,56 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkteam/devlog"
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInstance_OnEvent(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	events := make(chan *collector.Event, 10)
+	unregister := dlog.OnEvent(func(ctx context.Context, event *collector.Event) {
+		events <- event
+	})
+
+	server := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/tea")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Events are passed to the hook without a capture session of the dashboard
+	select {
+	case event := <-events:
+		request, ok := event.Data.(collector.HTTPServerRequest)
+		if !ok || request.Path != "/tea" {
+			t.Errorf("expected request to /tea, got %#v", event.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected hook to be called")
+	}
+
+	unregister()
+	unregister()
+	dlog.CollectEvent(context.Background(), "after unregister")
+
+	select {
+	case event := <-events:
+		t.Errorf("expected no event after unregister, got %#v", event.Data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
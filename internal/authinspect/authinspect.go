@@ -0,0 +1,137 @@
+// Package authinspect decodes credentials of captured requests for inspection: JWTs in the Authorization header or
+// in cookies, and cookie attributes. Signatures are never verified.
+package authinspect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWT is a decoded, unverified JSON Web Token
+type JWT struct {
+	// Source describes where the token was found (e.g. "Authorization header" or "Cookie session")
+	Source string
+	// Header is the JOSE header as indented JSON
+	Header string
+	// Claims is the payload as indented JSON
+	Claims string
+	// Algorithm is the "alg" of the header
+	Algorithm string
+
+	// IssuedAt, NotBefore and ExpiresAt are the registered time claims, zero if not set
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiresAt time.Time
+}
+
+// DecodeJWT decodes the header and claims of a JWT in compact serialization without verifying the signature
+func DecodeJWT(token string) (JWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return JWT{}, errors.New("token must have three parts")
+	}
+
+	var jwt JWT
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return JWT{}, fmt.Errorf("decoding header: %w", err)
+	}
+	var headerFields struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &headerFields); err != nil || headerFields.Alg == "" {
+		return JWT{}, errors.New("header is not a JOSE header")
+	}
+	jwt.Algorithm = headerFields.Alg
+	jwt.Header = indent(header)
+
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return JWT{}, fmt.Errorf("decoding claims: %w", err)
+	}
+	var timeClaims struct {
+		Iat *json.Number `json:"iat"`
+		Nbf *json.Number `json:"nbf"`
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(claims, &timeClaims); err != nil {
+		return JWT{}, errors.New("claims are not a JSON object")
+	}
+	jwt.Claims = indent(claims)
+	jwt.IssuedAt = numericDate(timeClaims.Iat)
+	jwt.NotBefore = numericDate(timeClaims.Nbf)
+	jwt.ExpiresAt = numericDate(timeClaims.Exp)
+
+	return jwt, nil
+}
+
+// Expired returns true if the token was expired at the given time
+func (j JWT) Expired(at time.Time) bool {
+	return !j.ExpiresAt.IsZero() && !at.Before(j.ExpiresAt)
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+func indent(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// numericDate converts seconds since the epoch (possibly with fractions) to a time
+func numericDate(n *json.Number) time.Time {
+	if n == nil {
+		return time.Time{}
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// FindJWTs returns the JWTs of a bearer token in the Authorization header and of cookie values
+func FindJWTs(requestHeader http.Header) []JWT {
+	var tokens []JWT
+	for _, authorization := range requestHeader.Values("Authorization") {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			continue
+		}
+		if jwt, err := DecodeJWT(strings.TrimSpace(token)); err == nil {
+			jwt.Source = "Authorization header"
+			tokens = append(tokens, jwt)
+		}
+	}
+	for _, cookie := range RequestCookies(requestHeader) {
+		if strings.Count(cookie.Value, ".") != 2 {
+			continue
+		}
+		if jwt, err := DecodeJWT(cookie.Value); err == nil {
+			jwt.Source = "Cookie " + cookie.Name
+			tokens = append(tokens, jwt)
+		}
+	}
+	return tokens
+}
+
+// RequestCookies parses the Cookie headers of a request
+func RequestCookies(requestHeader http.Header) []*http.Cookie {
+	return (&http.Request{Header: requestHeader}).Cookies()
+}
+
+// ResponseCookies parses the Set-Cookie headers of a response including their attributes
+func ResponseCookies(responseHeader http.Header) []*http.Cookie {
+	return (&http.Response{Header: responseHeader}).Cookies()
+}
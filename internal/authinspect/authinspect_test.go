@@ -0,0 +1,76 @@
+package authinspect_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/internal/authinspect"
+)
+
+func testToken(header, claims string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2lnbmF0dXJl"
+}
+
+func TestDecodeJWT(t *testing.T) {
+	token := testToken(`{"alg":"HS256","typ":"JWT"}`, `{"sub":"1234","name":"Alice","iat":1700000000,"exp":1700003600.5}`)
+
+	jwt, err := authinspect.DecodeJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "HS256", jwt.Algorithm)
+	assert.Equal(t, "{\n  \"alg\": \"HS256\",\n  \"typ\": \"JWT\"\n}", jwt.Header)
+	assert.Contains(t, jwt.Claims, "\"name\": \"Alice\"")
+	assert.Equal(t, time.Unix(1700000000, 0), jwt.IssuedAt)
+	assert.Equal(t, time.Unix(1700003600, 500_000_000), jwt.ExpiresAt)
+	assert.True(t, jwt.NotBefore.IsZero())
+
+	assert.False(t, jwt.Expired(time.Unix(1700000100, 0)))
+	assert.True(t, jwt.Expired(time.Unix(1700003601, 0)))
+
+	for _, invalid := range []string{
+		"abc",
+		"a.b.c",
+		testToken(`{"typ":"JWT"}`, `{}`),
+		testToken(`{"alg":"none"}`, `[1]`),
+	} {
+		_, err := authinspect.DecodeJWT(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFindJWTs(t *testing.T) {
+	bearer := testToken(`{"alg":"RS256"}`, `{"sub":"bearer"}`)
+	cookie := testToken(`{"alg":"HS256"}`, `{"sub":"cookie"}`)
+
+	header := http.Header{
+		"Authorization": {"Bearer " + bearer},
+		"Cookie":        {"theme=dark; session=" + cookie},
+	}
+
+	tokens := authinspect.FindJWTs(header)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "Authorization header", tokens[0].Source)
+	assert.Equal(t, "RS256", tokens[0].Algorithm)
+	assert.Equal(t, "Cookie session", tokens[1].Source)
+
+	assert.Empty(t, authinspect.FindJWTs(http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}))
+}
+
+func TestResponseCookies(t *testing.T) {
+	header := http.Header{
+		"Set-Cookie": {"session=abc; Path=/; Max-Age=3600; HttpOnly; Secure; SameSite=Lax"},
+	}
+
+	cookies := authinspect.ResponseCookies(header)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.Equal(t, 3600, cookies[0].MaxAge)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+}
@@ -0,0 +1,193 @@
+// Command benchcheck compares the output of "go test -bench -benchmem" with a baseline and fails if a benchmark got
+// slower (ns/op) or allocates more (allocs/op) than the threshold allows.
+//
+//	go run ./internal/benchcheck -threshold 20 bench_baseline.txt bench_output.txt
+//
+// Benchmarks that ran multiple times (-count) are compared by their median. Benchmarks missing in the baseline or the
+// current output are reported but do not fail the check.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// result is a single run of a benchmark
+type result struct {
+	nsPerOp     float64
+	allocsPerOp float64
+}
+
+// gomaxprocsSuffix is the "-8" appended to benchmark names if GOMAXPROCS is not 1
+var gomaxprocsSuffix = regexp.MustCompile(`-\d+$`)
+
+// parse reads the results of benchmarks by their name, prefixed with their package
+func parse(r io.Reader) (map[string][]result, error) {
+	results := make(map[string][]result)
+	var pkg string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimSpace(name)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		var res result
+		// Values are followed by their unit, starting after the name and the number of iterations
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q of %s: %w", fields[i], fields[0], err)
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				res.nsPerOp = value
+			case "allocs/op":
+				res.allocsPerOp = value
+			}
+		}
+		name := pkg + "." + gomaxprocsSuffix.ReplaceAllString(fields[0], "")
+		results[name] = append(results[name], res)
+	}
+	return results, scanner.Err()
+}
+
+// median returns the median of a metric of the runs of a benchmark
+func median(runs []result, metric func(result) float64) float64 {
+	values := make([]float64, len(runs))
+	for i, run := range runs {
+		values[i] = metric(run)
+	}
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// comparison is the change of a metric of a benchmark
+type comparison struct {
+	name      string
+	metric    string
+	baseline  float64
+	current   float64
+	regressed bool
+}
+
+// delta returns the change in percent
+func (c comparison) delta() float64 {
+	if c.baseline == 0 {
+		if c.current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (c.current - c.baseline) / c.baseline * 100
+}
+
+// compare compares the medians of the benchmarks found in both results, a metric regressed if it increased by more
+// than threshold percent
+func compare(baseline, current map[string][]result, threshold float64) []comparison {
+	var names []string
+	for name := range current {
+		if _, ok := baseline[name]; ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	metrics := []struct {
+		name  string
+		value func(result) float64
+	}{
+		{"ns/op", func(r result) float64 { return r.nsPerOp }},
+		{"allocs/op", func(r result) float64 { return r.allocsPerOp }},
+	}
+
+	var comparisons []comparison
+	for _, name := range names {
+		for _, metric := range metrics {
+			c := comparison{
+				name:     name,
+				metric:   metric.name,
+				baseline: median(baseline[name], metric.value),
+				current:  median(current[name], metric.value),
+			}
+			c.regressed = c.current > c.baseline && c.delta() > threshold
+			comparisons = append(comparisons, c)
+		}
+	}
+	return comparisons
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 20, "maximum allowed increase of ns/op and allocs/op in percent")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: benchcheck [-threshold percent] baseline.txt current.txt")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	baseline, err := parseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading baseline: %v\n", err)
+		os.Exit(2)
+	}
+	current, err := parseFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading current results: %v\n", err)
+		os.Exit(2)
+	}
+
+	var regressions int
+	for _, c := range compare(baseline, current, *threshold) {
+		status := "ok"
+		if c.regressed {
+			status = "REGRESSED"
+			regressions++
+		}
+		fmt.Printf("%-100s %-9s %14.1f %14.1f %+8.1f%%  %s\n", c.name, c.metric, c.baseline, c.current, c.delta(), status)
+	}
+	for name := range current {
+		if _, ok := baseline[name]; !ok {
+			fmt.Printf("%-100s not in baseline\n", name)
+		}
+	}
+	for name := range baseline {
+		if _, ok := current[name]; !ok {
+			fmt.Printf("%-100s not in current results\n", name)
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d metrics regressed by more than %.0f%%\n", regressions, *threshold)
+		os.Exit(1)
+	}
+}
+
+func parseFile(path string) (map[string][]result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f)
+}
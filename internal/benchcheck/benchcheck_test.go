@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baselineOutput = `goos: linux
+goarch: amd64
+pkg: github.com/networkteam/devlog/collector
+BenchmarkNotifier_FanOut/10_subscribers-8     	  500000	      2200 ns/op	       1 B/op	       0 allocs/op
+BenchmarkNotifier_FanOut/10_subscribers-8     	  500000	      2000 ns/op	       1 B/op	       0 allocs/op
+BenchmarkNotifier_FanOut/10_subscribers-8     	  500000	      9000 ns/op	       1 B/op	       0 allocs/op
+BenchmarkHTTPServerCollector_Middleware/with_capture/small_body-8 	  100000	      9000 ns/op	  54.96 MB/s	   10888 B/op	      63 allocs/op
+PASS
+ok  	github.com/networkteam/devlog/collector	4.2s
+`
+
+func TestParse(t *testing.T) {
+	results, err := parse(strings.NewReader(baselineOutput))
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	runs := results["github.com/networkteam/devlog/collector.BenchmarkNotifier_FanOut/10_subscribers"]
+	require.Len(t, runs, 3)
+	assert.Equal(t, 2200.0, runs[0].nsPerOp)
+
+	runs = results["github.com/networkteam/devlog/collector.BenchmarkHTTPServerCollector_Middleware/with_capture/small_body"]
+	require.Len(t, runs, 1)
+	assert.Equal(t, result{nsPerOp: 9000, allocsPerOp: 63}, runs[0])
+}
+
+func TestCompare(t *testing.T) {
+	baseline, err := parse(strings.NewReader(baselineOutput))
+	require.NoError(t, err)
+
+	current, err := parse(strings.NewReader(`pkg: github.com/networkteam/devlog/collector
+BenchmarkNotifier_FanOut/10_subscribers-8     	  500000	      2300 ns/op	       1 B/op	       1 allocs/op
+BenchmarkHTTPServerCollector_Middleware/with_capture/small_body-8 	  100000	     12000 ns/op	  54.96 MB/s	   10888 B/op	      64 allocs/op
+`))
+	require.NoError(t, err)
+
+	regressed := make(map[string]bool)
+	for _, c := range compare(baseline, current, 20) {
+		regressed[c.name+" "+c.metric] = c.regressed
+	}
+	assert.Equal(t, map[string]bool{
+		// The median of the baseline ignores the outlier
+		"github.com/networkteam/devlog/collector.BenchmarkNotifier_FanOut/10_subscribers ns/op": false,
+		// An allocation where there was none is a regression
+		"github.com/networkteam/devlog/collector.BenchmarkNotifier_FanOut/10_subscribers allocs/op":                         true,
+		"github.com/networkteam/devlog/collector.BenchmarkHTTPServerCollector_Middleware/with_capture/small_body ns/op":     true,
+		"github.com/networkteam/devlog/collector.BenchmarkHTTPServerCollector_Middleware/with_capture/small_body allocs/op": false,
+	}, regressed)
+}
@@ -0,0 +1,196 @@
+package insights
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const topicCaching = "Caching"
+
+// cacheableStatusCodes are heuristically cacheable by default (RFC 9110, section 15.1)
+var cacheableStatusCodes = []int{200, 203, 204, 206, 300, 301, 304, 308, 404, 405, 410, 414, 501}
+
+// cacheControl holds the parsed directives of a Cache-Control header
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	directives := make(cacheControl)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+	return directives
+}
+
+func (c cacheControl) has(name string) bool {
+	_, exists := c[name]
+	return exists
+}
+
+// seconds returns the argument of a delta-seconds directive like max-age
+func (c cacheControl) seconds(name string) (time.Duration, bool) {
+	argument, exists := c[name]
+	if !exists {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(argument, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// Caching analyzes the cacheability of a response by its Cache-Control, Expires, ETag, Last-Modified and Vary headers
+// and the conditional headers of the request
+func Caching(method string, statusCode int, requestHeader http.Header, responseHeader http.Header) []Insight {
+	responseDirectives := parseCacheControl(responseHeader)
+	if method != http.MethodGet && method != http.MethodHead && len(responseDirectives) == 0 {
+		// Responses to other methods are only cached with explicit freshness, which is rare
+		return nil
+	}
+
+	var insights []Insight
+
+	insights = append(insights, freshness(statusCode, responseDirectives, responseHeader)...)
+	insights = append(insights, validators(statusCode, requestHeader, responseHeader)...)
+	insights = append(insights, vary(responseHeader)...)
+
+	shared := responseDirectives.has("public") || responseDirectives.has("s-maxage")
+	if shared && !responseDirectives.has("no-store") && len(responseHeader.Values("Set-Cookie")) > 0 {
+		insights = append(insights, warning(topicCaching, "Set-Cookie on a response cacheable by shared caches",
+			"A proxy or CDN could serve the cookie to other users. Use Cache-Control: private or no-store for responses that set cookies."))
+	}
+	if shared && requestHeader.Get("Authorization") != "" {
+		insights = append(insights, warning(topicCaching, "Response to an authorized request is cacheable by shared caches",
+			"public or s-maxage allow shared caches to serve the response to other users without authorization."))
+	}
+	if responseDirectives.has("no-store") {
+		var conflicting []string
+		for _, name := range []string{"public", "max-age", "s-maxage", "immutable"} {
+			if responseDirectives.has(name) {
+				conflicting = append(conflicting, name)
+			}
+		}
+		if len(conflicting) > 0 {
+			insights = append(insights, warning(topicCaching, "no-store conflicts with "+strings.Join(conflicting, ", "),
+				"no-store takes precedence, the other directives have no effect."))
+		}
+	}
+
+	return insights
+}
+
+func freshness(statusCode int, directives cacheControl, responseHeader http.Header) []Insight {
+	maxAge, hasMaxAge := directives.seconds("max-age")
+	sharedMaxAge, hasSharedMaxAge := directives.seconds("s-maxage")
+
+	var insights []Insight
+	switch {
+	case directives.has("no-store"):
+		insights = append(insights, info(topicCaching, "Not stored by caches", "Cache-Control: no-store"))
+	case directives.has("no-cache"):
+		insights = append(insights, info(topicCaching, "Stored but revalidated before every use", "Cache-Control: no-cache"))
+	case hasMaxAge || hasSharedMaxAge:
+		title := fmt.Sprintf("Fresh for %s", maxAge)
+		if hasSharedMaxAge {
+			title = fmt.Sprintf("Fresh for %s in shared caches", sharedMaxAge)
+			if hasMaxAge {
+				title += fmt.Sprintf(", %s in the browser", maxAge)
+			}
+		}
+		if directives.has("private") {
+			title += " (browser only)"
+		}
+		insights = append(insights, info(topicCaching, title, "Cache-Control: "+strings.Join(responseHeader.Values("Cache-Control"), ", ")))
+	case responseHeader.Get("Expires") != "":
+		expires, err := http.ParseTime(responseHeader.Get("Expires"))
+		if err != nil {
+			insights = append(insights, warning(topicCaching, "Invalid Expires date",
+				"Caches treat an invalid Expires as already expired. Use Cache-Control: max-age instead."))
+			break
+		}
+		title := "Fresh until " + expires.UTC().Format(time.RFC1123)
+		if date, err := http.ParseTime(responseHeader.Get("Date")); err == nil {
+			title = fmt.Sprintf("Fresh for %s", expires.Sub(date))
+		}
+		insights = append(insights, info(topicCaching, title, "Expires is only used without Cache-Control: max-age, which is preferred."))
+	case slices.Contains(cacheableStatusCodes, statusCode) && statusCode != http.StatusNotModified:
+		detail := "Caches may store the response and choose a freshness lifetime themselves."
+		if responseHeader.Get("Last-Modified") != "" {
+			detail = "Caches may store the response for a fraction of the time since Last-Modified."
+		}
+		insights = append(insights, warning(topicCaching, "No explicit caching policy", detail+" Set Cache-Control to make the behavior predictable."))
+	}
+	return insights
+}
+
+func validators(statusCode int, requestHeader http.Header, responseHeader http.Header) []Insight {
+	etag := responseHeader.Get("ETag")
+	lastModified := responseHeader.Get("Last-Modified")
+	ifNoneMatch := requestHeader.Get("If-None-Match")
+	ifModifiedSince := requestHeader.Get("If-Modified-Since")
+
+	var insights []Insight
+	switch {
+	case statusCode == http.StatusNotModified:
+		insights = append(insights, info(topicCaching, "Revalidated, the cached response is still valid", "304 Not Modified was sent without a body."))
+	case ifNoneMatch != "" && etag != "" && etagMatches(ifNoneMatch, etag):
+		insights = append(insights, warning(topicCaching, "Full response although If-None-Match matches the ETag",
+			"The server could respond with 304 Not Modified and save sending the body."))
+	case ifNoneMatch != "" || ifModifiedSince != "":
+		insights = append(insights, info(topicCaching, "Conditional request, the resource was changed", "The cached response was replaced with the new one."))
+	}
+
+	switch {
+	case etag != "" && lastModified != "":
+		insights = append(insights, info(topicCaching, "Can be revalidated by ETag and Last-Modified", ""))
+	case etag != "":
+		insights = append(insights, info(topicCaching, "Can be revalidated by ETag", ""))
+	case lastModified != "":
+		insights = append(insights, info(topicCaching, "Can be revalidated by Last-Modified", ""))
+	}
+	return insights
+}
+
+// etagMatches compares an If-None-Match header with an ETag using weak comparison
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func vary(responseHeader http.Header) []Insight {
+	var insights []Insight
+	for _, value := range responseHeader.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(field)) {
+			case "*":
+				insights = append(insights, warning(topicCaching, "Vary: * prevents reuse of the cached response",
+					"Every request is considered different, so caches have to forward it."))
+			case "user-agent":
+				insights = append(insights, warning(topicCaching, "Vary: User-Agent fragments caches",
+					"A separate response is stored for each browser version."))
+			case "cookie":
+				insights = append(insights, info(topicCaching, "Vary: Cookie stores a response per cookie value",
+					"Shared caches will rarely reuse it, consider Cache-Control: private."))
+			}
+		}
+	}
+	return insights
+}
@@ -0,0 +1,104 @@
+package insights_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkteam/devlog/internal/insights"
+)
+
+func titles(items []insights.Insight, severity insights.Severity) []string {
+	var result []string
+	for _, item := range items {
+		if item.Severity == severity {
+			result = append(result, item.Title)
+		}
+	}
+	return result
+}
+
+func TestCaching(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		statusCode     int
+		requestHeader  http.Header
+		responseHeader http.Header
+		infos          []string
+		warnings       []string
+	}{
+		{
+			name:           "max-age with validator",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			responseHeader: http.Header{"Cache-Control": {"public, max-age=3600"}, "Etag": {`"v1"`}},
+			infos:          []string{"Fresh for 1h0m0s", "Can be revalidated by ETag"},
+		},
+		{
+			name:           "shared caching with cookie",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			requestHeader:  http.Header{"Authorization": {"Bearer abc"}},
+			responseHeader: http.Header{"Cache-Control": {"s-maxage=60, max-age=0"}, "Set-Cookie": {"session=abc"}},
+			infos:          []string{"Fresh for 1m0s in shared caches, 0s in the browser"},
+			warnings: []string{
+				"Set-Cookie on a response cacheable by shared caches",
+				"Response to an authorized request is cacheable by shared caches",
+			},
+		},
+		{
+			name:           "no-store with conflicting directives",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			responseHeader: http.Header{"Cache-Control": {"no-store", "max-age=60"}},
+			infos:          []string{"Not stored by caches"},
+			warnings:       []string{"no-store conflicts with max-age"},
+		},
+		{
+			name:           "no policy",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			responseHeader: http.Header{"Vary": {"Accept-Encoding, User-Agent"}},
+			warnings:       []string{"No explicit caching policy", "Vary: User-Agent fragments caches"},
+		},
+		{
+			name:           "expires",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			responseHeader: http.Header{"Date": {"Mon, 02 Jan 2006 15:04:05 GMT"}, "Expires": {"Mon, 02 Jan 2006 16:04:05 GMT"}},
+			infos:          []string{"Fresh for 1h0m0s"},
+		},
+		{
+			name:           "matching etag without 304",
+			method:         http.MethodGet,
+			statusCode:     http.StatusOK,
+			requestHeader:  http.Header{"If-None-Match": {`W/"v1"`}},
+			responseHeader: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}},
+			infos:          []string{"Stored but revalidated before every use", "Can be revalidated by ETag"},
+			warnings:       []string{"Full response although If-None-Match matches the ETag"},
+		},
+		{
+			name:           "not modified",
+			method:         http.MethodGet,
+			statusCode:     http.StatusNotModified,
+			requestHeader:  http.Header{"If-None-Match": {`"v1"`}},
+			responseHeader: http.Header{"Etag": {`"v1"`}},
+			infos:          []string{"Revalidated, the cached response is still valid", "Can be revalidated by ETag"},
+		},
+		{
+			name:           "post without cache control",
+			method:         http.MethodPost,
+			statusCode:     http.StatusOK,
+			responseHeader: http.Header{"Set-Cookie": {"session=abc"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := insights.Caching(tt.method, tt.statusCode, tt.requestHeader, tt.responseHeader)
+			assert.Equal(t, tt.infos, titles(result, insights.SeverityInfo))
+			assert.Equal(t, tt.warnings, titles(result, insights.SeverityWarning))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package insights
+
+import "net"
+
+const topicConnection = "Connection"
+
+// ClientFailure is a classified error of an outgoing request (see collector.HTTPClientErrorInfo)
+type ClientFailure struct {
+	Category string
+	Phase    string
+	Reason   string
+	Errno    string
+	// Host is the looked up host or the address that was connected to
+	Host string
+}
+
+// ClientError explains why an outgoing request failed and how to fix it
+func ClientError(failure ClientFailure) []Insight {
+	host := failure.Host
+	if host == "" {
+		host = "the host"
+	}
+
+	switch failure.Category {
+	case "dns":
+		if failure.Reason == "dns_timeout" {
+			return []Insight{warning(topicConnection, "DNS lookup of "+host+" timed out",
+				"The resolver did not answer in time. Check the DNS servers of the system or container network.")}
+		}
+		return []Insight{warning(topicConnection, "Host "+host+" could not be resolved",
+			"Check the host name for typos. Service names of Docker Compose or Kubernetes only resolve inside their network.")}
+	case "connection_refused":
+		detail := "Nothing listens on the port. Check that the service is running and the port is correct."
+		if isLoopback(failure.Host) {
+			detail += " Inside a container, localhost is the container itself and not the host machine."
+		}
+		return []Insight{warning(topicConnection, "Connection to "+host+" was refused", detail)}
+	case "connection_reset":
+		return []Insight{warning(topicConnection, "Connection was reset by "+host,
+			"The server or a proxy in between aborted the connection. This often happens when a keep-alive connection is reused after the server closed it, or the server crashed while handling the request.")}
+	case "connection_closed":
+		return []Insight{warning(topicConnection, "Connection was closed before a response was received",
+			"The server closed the connection without a response. Check the logs of the server and timeouts of proxies in between.")}
+	case "unreachable":
+		return []Insight{warning(topicConnection, host+" is unreachable",
+			"There is no route to the host. Check the network, VPN and firewall settings.")}
+	case "tls":
+		return []Insight{tlsInsight(failure, host)}
+	case "timeout":
+		return []Insight{timeoutInsight(failure, host)}
+	case "canceled":
+		return []Insight{info(topicConnection, "Request was canceled",
+			"The context of the request was canceled, e.g. because the incoming request finished or the client disconnected.")}
+	}
+	return nil
+}
+
+func tlsInsight(failure ClientFailure, host string) Insight {
+	switch failure.Reason {
+	case "unknown_authority":
+		return warning(topicConnection, "Certificate of "+host+" is signed by an unknown authority",
+			"The certificate is self-signed or the CA is not trusted. Add the CA to the system trust store or the RootCAs of the TLS config.")
+	case "hostname_mismatch":
+		return warning(topicConnection, "Certificate is not valid for "+host,
+			"The certificate does not list the host name. Use the host name the certificate was issued for.")
+	case "expired":
+		return warning(topicConnection, "Certificate of "+host+" is expired or not yet valid",
+			"Renew the certificate or check the clock of the system.")
+	case "invalid_certificate":
+		return warning(topicConnection, "Certificate of "+host+" is invalid",
+			"The certificate could not be verified.")
+	case "http_response":
+		return warning(topicConnection, host+" does not speak TLS",
+			"The server answered the TLS handshake with plain HTTP. Use an http:// URL or the TLS port of the server.")
+	}
+	return warning(topicConnection, "TLS handshake with "+host+" failed",
+		"Check the TLS versions and cipher suites supported by the server.")
+}
+
+func timeoutInsight(failure ClientFailure, host string) Insight {
+	switch failure.Phase {
+	case "dns":
+		return warning(topicConnection, "Timed out looking up "+host,
+			"The resolver did not answer in time. Check the DNS servers of the system or container network.")
+	case "connect":
+		return warning(topicConnection, "Timed out connecting to "+host,
+			"The host did not accept the connection in time. A firewall might drop the packets.")
+	case "tls_handshake":
+		return warning(topicConnection, "Timed out in the TLS handshake with "+host,
+			"The server did not complete the handshake in time. Check that the port expects TLS.")
+	case "wait_response":
+		return warning(topicConnection, "Timed out waiting for a response",
+			"The request was sent, but the server did not respond in time. Increase the timeout of the client or check why the server is slow.")
+	case "read_response":
+		return warning(topicConnection, "Timed out reading the response",
+			"The server started responding, but did not finish in time. The timeout of http.Client also covers reading the body.")
+	}
+	return warning(topicConnection, "Request to "+host+" timed out",
+		"The request exceeded the timeout of the client or the deadline of its context.")
+}
+
+// isLoopback checks if host (optionally with a port) is a loopback address
+func isLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,72 @@
+package insights_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkteam/devlog/internal/insights"
+)
+
+func TestClientError(t *testing.T) {
+	tests := []struct {
+		name     string
+		failure  insights.ClientFailure
+		warnings []string
+		infos    []string
+	}{
+		{
+			name:     "host not found",
+			failure:  insights.ClientFailure{Category: "dns", Reason: "host_not_found", Host: "api.invalid"},
+			warnings: []string{"Host api.invalid could not be resolved"},
+		},
+		{
+			name:     "connection refused",
+			failure:  insights.ClientFailure{Category: "connection_refused", Phase: "connect", Errno: "ECONNREFUSED", Host: "127.0.0.1:8080"},
+			warnings: []string{"Connection to 127.0.0.1:8080 was refused"},
+		},
+		{
+			name:     "unknown certificate authority",
+			failure:  insights.ClientFailure{Category: "tls", Phase: "tls_handshake", Reason: "unknown_authority", Host: "api.example.com:443"},
+			warnings: []string{"Certificate of api.example.com:443 is signed by an unknown authority"},
+		},
+		{
+			name:     "timeout waiting for response",
+			failure:  insights.ClientFailure{Category: "timeout", Phase: "wait_response", Host: "api.example.com:443"},
+			warnings: []string{"Timed out waiting for a response"},
+		},
+		{
+			name:     "timeout without phase",
+			failure:  insights.ClientFailure{Category: "timeout"},
+			warnings: []string{"Request to the host timed out"},
+		},
+		{
+			name:    "canceled",
+			failure: insights.ClientFailure{Category: "canceled", Phase: "wait_response"},
+			infos:   []string{"Request was canceled"},
+		},
+		{
+			name:    "other",
+			failure: insights.ClientFailure{Category: "other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := insights.ClientError(tt.failure)
+			assert.Equal(t, tt.warnings, titles(result, insights.SeverityWarning))
+			assert.Equal(t, tt.infos, titles(result, insights.SeverityInfo))
+		})
+	}
+}
+
+func TestClientError_RefusedOnLoopback(t *testing.T) {
+	result := insights.ClientError(insights.ClientFailure{Category: "connection_refused", Host: "localhost:5432"})
+	if assert.Len(t, result, 1) {
+		assert.Contains(t, result[0].Detail, "Inside a container")
+	}
+	result = insights.ClientError(insights.ClientFailure{Category: "connection_refused", Host: "db:5432"})
+	if assert.Len(t, result, 1) {
+		assert.NotContains(t, result[0].Detail, "Inside a container")
+	}
+}
@@ -0,0 +1,145 @@
+package insights
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const topicCORS = "CORS"
+
+// Exchange is a captured request with its response
+type Exchange struct {
+	Method         string
+	StatusCode     int
+	RequestHeader  http.Header
+	ResponseHeader http.Header
+}
+
+// IsPreflight checks if a request is a CORS preflight request of a browser
+func IsPreflight(method string, requestHeader http.Header) bool {
+	return method == http.MethodOptions && requestHeader.Get("Origin") != "" && requestHeader.Get("Access-Control-Request-Method") != ""
+}
+
+// PreflightMaxAge returns how long a browser may cache the result of a preflight request in seconds (5 if not set)
+func PreflightMaxAge(responseHeader http.Header) int {
+	maxAge, err := strconv.Atoi(responseHeader.Get("Access-Control-Max-Age"))
+	if err != nil {
+		return 5
+	}
+	return maxAge
+}
+
+// CORS diagnoses the CORS headers of a preflight request and the actual request, each of them might be nil if it was
+// not captured
+func CORS(preflight *Exchange, actual *Exchange) []Insight {
+	var insights []Insight
+	if preflight != nil {
+		insights = append(insights, preflightInsights(preflight)...)
+	}
+	if actual != nil {
+		insights = append(insights, actualInsights(actual, preflight != nil)...)
+	}
+	return insights
+}
+
+func preflightInsights(preflight *Exchange) []Insight {
+	origin := preflight.RequestHeader.Get("Origin")
+	requestMethod := preflight.RequestHeader.Get("Access-Control-Request-Method")
+	credentials := preflight.ResponseHeader.Get("Access-Control-Allow-Credentials") == "true"
+
+	var insights []Insight
+	if preflight.StatusCode < 200 || preflight.StatusCode > 299 {
+		insights = append(insights, warning(topicCORS, fmt.Sprintf("Preflight responded with status %d", preflight.StatusCode),
+			"Browsers require a successful status (2xx) for preflight requests, otherwise the actual request is not sent."))
+	}
+	insights = append(insights, allowOriginInsights(origin, preflight.ResponseHeader, "Preflight")...)
+
+	allowedMethods := headerList(preflight.ResponseHeader, "Access-Control-Allow-Methods")
+	methodAllowed := isSafelistedMethod(requestMethod) ||
+		slices.Contains(allowedMethods, strings.ToLower(requestMethod)) ||
+		(!credentials && slices.Contains(allowedMethods, "*"))
+	if !methodAllowed {
+		insights = append(insights, warning(topicCORS, fmt.Sprintf("Method %s is not allowed", requestMethod),
+			"Add it to Access-Control-Allow-Methods of the preflight response."))
+	}
+
+	allowedHeaders := headerList(preflight.ResponseHeader, "Access-Control-Allow-Headers")
+	var missingHeaders []string
+	for _, name := range headerList(preflight.RequestHeader, "Access-Control-Request-Headers") {
+		// The wildcard does not cover Authorization and is not supported with credentials
+		wildcard := !credentials && name != "authorization" && slices.Contains(allowedHeaders, "*")
+		if !wildcard && !slices.Contains(allowedHeaders, name) {
+			missingHeaders = append(missingHeaders, name)
+		}
+	}
+	if len(missingHeaders) > 0 {
+		insights = append(insights, warning(topicCORS, "Request headers are not allowed: "+strings.Join(missingHeaders, ", "),
+			"Add them to Access-Control-Allow-Headers of the preflight response."))
+	}
+
+	if preflight.ResponseHeader.Get("Access-Control-Max-Age") == "" {
+		insights = append(insights, info(topicCORS, "Preflight result is cached for 5 seconds",
+			"Set Access-Control-Max-Age to avoid a preflight before most requests."))
+	}
+	return insights
+}
+
+func actualInsights(actual *Exchange, hasPreflight bool) []Insight {
+	origin := actual.RequestHeader.Get("Origin")
+	if actual.RequestHeader.Get("Sec-Fetch-Site") == "same-origin" {
+		return []Insight{info(topicCORS, "Same-origin request", "CORS headers are not required for requests from "+origin+".")}
+	}
+
+	insights := allowOriginInsights(origin, actual.ResponseHeader, "Response")
+
+	allowOrigin := actual.ResponseHeader.Get("Access-Control-Allow-Origin")
+	if allowOrigin != "" && allowOrigin != "*" && !slices.Contains(headerList(actual.ResponseHeader, "Vary"), "origin") {
+		insights = append(insights, warning(topicCORS, "Vary: Origin is missing",
+			"Access-Control-Allow-Origin depends on the origin, caches could serve the response with the wrong origin."))
+	}
+
+	if !hasPreflight && !isSafelistedMethod(actual.Method) {
+		insights = append(insights, info(topicCORS, "No preflight request was captured",
+			fmt.Sprintf("Browsers send a preflight before %s requests, it might have been cached or not captured.", actual.Method)))
+	}
+	return insights
+}
+
+// allowOriginInsights checks Access-Control-Allow-Origin and Access-Control-Allow-Credentials of a response
+func allowOriginInsights(origin string, responseHeader http.Header, subject string) []Insight {
+	allowOrigin := responseHeader.Get("Access-Control-Allow-Origin")
+	credentials := responseHeader.Get("Access-Control-Allow-Credentials") == "true"
+
+	switch {
+	case allowOrigin == "":
+		return []Insight{warning(topicCORS, subject+" has no Access-Control-Allow-Origin",
+			fmt.Sprintf("The browser blocks the request from %s.", origin))}
+	case allowOrigin == "*" && credentials:
+		return []Insight{warning(topicCORS, subject+" allows any origin with credentials",
+			"Browsers reject Access-Control-Allow-Origin: * for requests with credentials, respond with the origin of the request instead.")}
+	case allowOrigin != "*" && allowOrigin != origin:
+		return []Insight{warning(topicCORS, fmt.Sprintf("%s allows origin %s instead of %s", subject, allowOrigin, origin),
+			"Access-Control-Allow-Origin must be * or match the Origin of the request exactly (including scheme and port).")}
+	}
+	return []Insight{info(topicCORS, fmt.Sprintf("%s allows origin %s", subject, origin), "")}
+}
+
+func isSafelistedMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodPost
+}
+
+// headerList returns the lower case elements of comma-separated header values
+func headerList(header http.Header, name string) []string {
+	var list []string
+	for _, value := range header.Values(name) {
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.ToLower(strings.TrimSpace(element)); element != "" {
+				list = append(list, element)
+			}
+		}
+	}
+	return list
+}
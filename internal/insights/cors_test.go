@@ -0,0 +1,80 @@
+package insights_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkteam/devlog/internal/insights"
+)
+
+func TestIsPreflight(t *testing.T) {
+	assert.True(t, insights.IsPreflight(http.MethodOptions, http.Header{"Origin": {"https://app.example"}, "Access-Control-Request-Method": {"PUT"}}))
+	assert.False(t, insights.IsPreflight(http.MethodOptions, http.Header{"Origin": {"https://app.example"}}))
+	assert.False(t, insights.IsPreflight(http.MethodPut, http.Header{"Origin": {"https://app.example"}}))
+}
+
+func TestCORS(t *testing.T) {
+	preflight := &insights.Exchange{
+		Method:     http.MethodOptions,
+		StatusCode: http.StatusNoContent,
+		RequestHeader: http.Header{
+			"Origin":                         {"https://app.example"},
+			"Access-Control-Request-Method":  {"PUT"},
+			"Access-Control-Request-Headers": {"content-type, authorization, x-trace"},
+		},
+		ResponseHeader: http.Header{
+			"Access-Control-Allow-Origin":  {"https://app.example"},
+			"Access-Control-Allow-Methods": {"GET, POST"},
+			"Access-Control-Allow-Headers": {"*"},
+		},
+	}
+	actual := &insights.Exchange{
+		Method:         http.MethodPut,
+		StatusCode:     http.StatusOK,
+		RequestHeader:  http.Header{"Origin": {"https://app.example"}},
+		ResponseHeader: http.Header{"Access-Control-Allow-Origin": {"http://app.example"}},
+	}
+
+	result := insights.CORS(preflight, actual)
+	assert.Equal(t, []string{
+		"Preflight allows origin https://app.example",
+		"Preflight result is cached for 5 seconds",
+	}, titles(result, insights.SeverityInfo))
+	assert.Equal(t, []string{
+		"Method PUT is not allowed",
+		"Request headers are not allowed: authorization",
+		"Response allows origin http://app.example instead of https://app.example",
+		"Vary: Origin is missing",
+	}, titles(result, insights.SeverityWarning))
+}
+
+func TestCORS_WithoutPreflight(t *testing.T) {
+	actual := &insights.Exchange{
+		Method:        http.MethodDelete,
+		StatusCode:    http.StatusOK,
+		RequestHeader: http.Header{"Origin": {"https://app.example"}, "Cookie": {"session=abc"}},
+		ResponseHeader: http.Header{
+			"Access-Control-Allow-Origin":      {"*"},
+			"Access-Control-Allow-Credentials": {"true"},
+		},
+	}
+
+	result := insights.CORS(nil, actual)
+	assert.Equal(t, []string{"No preflight request was captured"}, titles(result, insights.SeverityInfo))
+	assert.Equal(t, []string{"Response allows any origin with credentials"}, titles(result, insights.SeverityWarning))
+}
+
+func TestCORS_SameOrigin(t *testing.T) {
+	actual := &insights.Exchange{
+		Method:         http.MethodPost,
+		StatusCode:     http.StatusOK,
+		RequestHeader:  http.Header{"Origin": {"https://app.example"}, "Sec-Fetch-Site": {"same-origin"}},
+		ResponseHeader: http.Header{},
+	}
+
+	result := insights.CORS(nil, actual)
+	assert.Equal(t, []string{"Same-origin request"}, titles(result, insights.SeverityInfo))
+	assert.Empty(t, titles(result, insights.SeverityWarning))
+}
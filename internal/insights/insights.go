@@ -0,0 +1,31 @@
+// Package insights analyzes captured HTTP requests and responses for hints and common mistakes, e.g. in caching headers.
+package insights
+
+// Severity of an insight
+type Severity string
+
+const (
+	// SeverityInfo explains the behavior of a request
+	SeverityInfo Severity = "info"
+	// SeverityWarning points out a likely mistake
+	SeverityWarning Severity = "warning"
+)
+
+// Insight is a hint about a request or response
+type Insight struct {
+	Severity Severity
+	// Topic groups insights (e.g. "Caching")
+	Topic string
+	// Title is a short summary
+	Title string
+	// Detail explains the insight (optional)
+	Detail string
+}
+
+func info(topic, title, detail string) Insight {
+	return Insight{Severity: SeverityInfo, Topic: topic, Title: title, Detail: detail}
+}
+
+func warning(topic, title, detail string) Insight {
+	return Insight{Severity: SeverityWarning, Topic: topic, Title: title, Detail: detail}
+}
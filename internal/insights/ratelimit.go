@@ -0,0 +1,104 @@
+package insights
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateLimitHeaderPrefixes are the prefixes of common rate limit headers, e.g. X-RateLimit-Remaining of GitHub,
+// RateLimit-Remaining of the IETF draft and X-Rate-Limit-Remaining of Twitter
+var rateLimitHeaderPrefixes = []string{"X-RateLimit-", "RateLimit-", "X-Rate-Limit-"}
+
+// minUnixReset distinguishes a reset given as Unix timestamp from a reset given as delta seconds
+const minUnixReset = 1_000_000_000
+
+// RateLimitInfo is the rate limit of a response parsed from its Retry-After and rate limit headers
+type RateLimitInfo struct {
+	// Limited is set if the request was rejected because of the rate limit: a 429 response, a 503 response with
+	// Retry-After or a 403 response without remaining requests (e.g. GitHub)
+	Limited bool
+	// RetryAt is the time from Retry-After, zero if not set
+	RetryAt time.Time
+	// Limit is the maximum number of requests in the window, nil if not set
+	Limit *int
+	// Remaining is the number of requests left in the window, nil if not set
+	Remaining *int
+	// ResetAt is the time the window resets, zero if not set
+	ResetAt time.Time
+}
+
+// RateLimit parses the rate limit of a response received at responseTime, it returns nil if the response was neither
+// rate limited nor has rate limit headers
+func RateLimit(statusCode int, header http.Header, responseTime time.Time) *RateLimitInfo {
+	rl := &RateLimitInfo{
+		RetryAt:   parseRetryAfter(header.Get("Retry-After"), responseTime),
+		Limit:     parseRateLimitInt(rateLimitHeader(header, "Limit")),
+		Remaining: parseRateLimitInt(rateLimitHeader(header, "Remaining")),
+		ResetAt:   parseRateLimitReset(rateLimitHeader(header, "Reset"), responseTime),
+	}
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		rl.Limited = true
+	case http.StatusServiceUnavailable:
+		rl.Limited = !rl.RetryAt.IsZero()
+	case http.StatusForbidden:
+		rl.Limited = rl.Remaining != nil && *rl.Remaining == 0
+	}
+
+	if !rl.Limited && rl.Limit == nil && rl.Remaining == nil && rl.ResetAt.IsZero() {
+		return nil
+	}
+	return rl
+}
+
+// rateLimitHeader returns the first value of a rate limit header with a known prefix
+func rateLimitHeader(header http.Header, name string) string {
+	for _, prefix := range rateLimitHeaderPrefixes {
+		if value := header.Get(prefix + name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseRetryAfter parses delta seconds or an HTTP date of a Retry-After header
+func parseRetryAfter(value string, responseTime time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return responseTime.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// parseRateLimitInt parses the number of a limit or remaining header, the IETF draft allows a policy after the number
+// (e.g. "100, 100;w=60")
+func parseRateLimitInt(value string) *int {
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return nil
+	}
+	return &n
+}
+
+// parseRateLimitReset parses a reset given as Unix timestamp (e.g. GitHub, optionally with fractional seconds) or as
+// delta seconds (IETF draft)
+func parseRateLimitReset(value string, responseTime time.Time) time.Time {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}
+	}
+	if seconds >= minUnixReset {
+		return time.UnixMilli(int64(seconds * 1000))
+	}
+	return responseTime.Add(time.Duration(seconds * float64(time.Second)))
+}
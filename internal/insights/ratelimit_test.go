@@ -0,0 +1,66 @@
+package insights_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/internal/insights"
+)
+
+func TestRateLimit(t *testing.T) {
+	responseTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("429 with Retry-After seconds", func(t *testing.T) {
+		rl := insights.RateLimit(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, responseTime)
+		require.NotNil(t, rl)
+		assert.True(t, rl.Limited)
+		assert.Equal(t, responseTime.Add(30*time.Second), rl.RetryAt)
+		assert.Nil(t, rl.Limit)
+	})
+
+	t.Run("503 with Retry-After date", func(t *testing.T) {
+		rl := insights.RateLimit(http.StatusServiceUnavailable, http.Header{"Retry-After": {"Sat, 01 Mar 2025 12:05:00 GMT"}}, responseTime)
+		require.NotNil(t, rl)
+		assert.True(t, rl.Limited)
+		assert.True(t, rl.RetryAt.Equal(responseTime.Add(5*time.Minute)))
+	})
+
+	t.Run("503 without Retry-After", func(t *testing.T) {
+		assert.Nil(t, insights.RateLimit(http.StatusServiceUnavailable, http.Header{}, responseTime))
+	})
+
+	t.Run("GitHub headers with exhausted limit", func(t *testing.T) {
+		rl := insights.RateLimit(http.StatusForbidden, http.Header{
+			"X-Ratelimit-Limit":     {"5000"},
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {"1740831000"},
+		}, responseTime)
+		require.NotNil(t, rl)
+		assert.True(t, rl.Limited)
+		assert.Equal(t, 5000, *rl.Limit)
+		assert.Equal(t, 0, *rl.Remaining)
+		assert.True(t, rl.ResetAt.Equal(responseTime.Add(10*time.Minute)))
+	})
+
+	t.Run("IETF draft headers on a successful response", func(t *testing.T) {
+		rl := insights.RateLimit(http.StatusOK, http.Header{
+			"Ratelimit-Limit":     {"100, 100;w=60"},
+			"Ratelimit-Remaining": {"42"},
+			"Ratelimit-Reset":     {"50"},
+		}, responseTime)
+		require.NotNil(t, rl)
+		assert.False(t, rl.Limited)
+		assert.Equal(t, 100, *rl.Limit)
+		assert.Equal(t, 42, *rl.Remaining)
+		assert.Equal(t, responseTime.Add(50*time.Second), rl.ResetAt)
+		assert.True(t, rl.RetryAt.IsZero())
+	})
+
+	t.Run("no rate limit", func(t *testing.T) {
+		assert.Nil(t, insights.RateLimit(http.StatusOK, http.Header{"Retry-After": {"invalid"}}, responseTime))
+	})
+}
@@ -0,0 +1,353 @@
+package protodecode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDepth limits the nesting of decoded messages
+const maxDepth = 64
+
+// Decode decodes an encoded message of the fully-qualified type messageType to indented JSON.
+// Field names, 64 bit integers as strings, bytes as base64 and enums as names follow the protobuf JSON mapping.
+// If messageType is empty, the message is decoded by its wire format with field numbers as keys.
+func (r *Registry) Decode(data []byte, messageType string) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		value object
+		err   error
+	)
+	if messageType == "" {
+		value, err = decodeWireFormat(data, 0)
+	} else {
+		m, exists := r.messages[messageType]
+		if !exists {
+			return nil, fmt.Errorf("unknown message type %s", messageType)
+		}
+		value, err = r.decodeMessage(data, m, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
+
+// object is a JSON object that keeps the order of its members
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldValues collects the values of fields by number in order of first appearance
+type fieldValues struct {
+	numbers []int32
+	values  map[int32][]any
+}
+
+func (v *fieldValues) add(number int32, value ...any) {
+	if v.values == nil {
+		v.values = make(map[int32][]any)
+	}
+	if _, exists := v.values[number]; !exists {
+		v.numbers = append(v.numbers, number)
+	}
+	v.values[number] = append(v.values[number], value...)
+}
+
+// unknownMembers returns the members for fields without descriptor, repeated fields become arrays
+func (v *fieldValues) unknownMembers(known func(number int32) bool) object {
+	var o object
+	for _, number := range v.numbers {
+		if known(number) {
+			continue
+		}
+		values := v.values[number]
+		if len(values) == 1 {
+			o = append(o, member{key: strconv.Itoa(int(number)), value: values[0]})
+		} else {
+			o = append(o, member{key: strconv.Itoa(int(number)), value: values})
+		}
+	}
+	return o
+}
+
+func (r *Registry) decodeMessage(data []byte, m *messageDescriptor, depth int) (object, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("message %s is nested too deeply", m.name)
+	}
+
+	var values fieldValues
+	err := readFields(data, func(f wireField) error {
+		field := m.byNumber[f.number]
+		if field == nil {
+			values.add(f.number, decodeUnknown(f, depth))
+			return nil
+		}
+
+		if field.repeated && f.wireType == wireBytes && packedWireType(field.typ) >= 0 {
+			packed, err := r.decodePacked(f.bytes, field)
+			if err != nil {
+				return err
+			}
+			values.add(f.number, packed...)
+			return nil
+		}
+
+		value, err := r.decodeValue(field, f, depth)
+		if err != nil {
+			return err
+		}
+		values.add(f.number, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var o object
+	for _, field := range m.fields {
+		fieldValues, exists := values.values[field.number]
+		if !exists {
+			continue
+		}
+		switch {
+		case field.repeated && r.isMapEntry(field.typeName):
+			entries := object{}
+			for _, entry := range fieldValues {
+				key, value := mapEntry(entry.(object))
+				entries = append(entries, member{key: key, value: value})
+			}
+			o = append(o, member{key: field.jsonName, value: entries})
+		case field.repeated:
+			o = append(o, member{key: field.jsonName, value: fieldValues})
+		default:
+			o = append(o, member{key: field.jsonName, value: fieldValues[len(fieldValues)-1]})
+		}
+	}
+	o = append(o, values.unknownMembers(func(number int32) bool { return m.byNumber[number] != nil })...)
+	return o, nil
+}
+
+func (r *Registry) isMapEntry(typeName string) bool {
+	m, exists := r.messages[typeName]
+	return exists && m.mapEntry
+}
+
+// mapEntry returns the key and value of a decoded map entry message
+func mapEntry(entry object) (key string, value any) {
+	for _, m := range entry {
+		switch m.key {
+		case "key":
+			key = fmt.Sprint(m.value)
+		case "value":
+			value = m.value
+		}
+	}
+	return key, value
+}
+
+func (r *Registry) decodeValue(field *fieldDescriptor, f wireField, depth int) (any, error) {
+	if want := expectedWireType(field.typ); want != f.wireType {
+		return nil, fmt.Errorf("field %s has wire type %d, expected %d", field.name, f.wireType, want)
+	}
+
+	switch field.typ {
+	case typeMessage:
+		m, exists := r.messages[field.typeName]
+		if !exists {
+			return decodeWireFormat(f.bytes, depth+1)
+		}
+		return r.decodeMessage(f.bytes, m, depth+1)
+	case typeString:
+		return string(f.bytes), nil
+	case typeBytes:
+		return base64.StdEncoding.EncodeToString(f.bytes), nil
+	case typeEnum:
+		if name, exists := r.enums[field.typeName][int32(f.value)]; exists {
+			return name, nil
+		}
+		return json.Number(strconv.Itoa(int(int32(f.value)))), nil
+	}
+	return scalarValue(field.typ, f.value)
+}
+
+// decodePacked decodes the elements of a packed repeated scalar field
+func (r *Registry) decodePacked(data []byte, field *fieldDescriptor) ([]any, error) {
+	var values []any
+	for len(data) > 0 {
+		var v uint64
+		switch packedWireType(field.typ) {
+		case wireVarint:
+			var n int
+			v, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return nil, errTruncated
+			}
+			v = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return nil, errTruncated
+			}
+			v = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		}
+
+		value, err := r.decodeValue(field, wireField{number: field.number, wireType: expectedWireType(field.typ), value: v}, 0)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// scalarValue converts the value of a numeric or bool field to JSON
+func scalarValue(typ int32, v uint64) (any, error) {
+	switch typ {
+	case typeDouble:
+		return floatValue(math.Float64frombits(v)), nil
+	case typeFloat:
+		return floatValue(float64(math.Float32frombits(uint32(v)))), nil
+	case typeInt64, typeSfixed64:
+		return strconv.FormatInt(int64(v), 10), nil
+	case typeUint64, typeFixed64:
+		return strconv.FormatUint(v, 10), nil
+	case typeSint64:
+		return strconv.FormatInt(zigzag(v), 10), nil
+	case typeInt32, typeSfixed32:
+		return json.Number(strconv.Itoa(int(int32(v)))), nil
+	case typeUint32, typeFixed32:
+		return json.Number(strconv.FormatUint(uint64(uint32(v)), 10)), nil
+	case typeSint32:
+		return json.Number(strconv.Itoa(int(int32(zigzag(v))))), nil
+	case typeBool:
+		return v != 0, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %d", typ)
+}
+
+// floatValue returns special values as strings since they cannot be represented in JSON
+func floatValue(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
+
+func expectedWireType(typ int32) int {
+	switch typ {
+	case typeString, typeBytes, typeMessage:
+		return wireBytes
+	case typeGroup:
+		return -1
+	}
+	return packedWireType(typ)
+}
+
+// packedWireType returns the wire type of elements of packed repeated fields, -1 if the type cannot be packed
+func packedWireType(typ int32) int {
+	switch typ {
+	case typeDouble, typeFixed64, typeSfixed64:
+		return wireFixed64
+	case typeFloat, typeFixed32, typeSfixed32:
+		return wireFixed32
+	case typeInt64, typeUint64, typeInt32, typeBool, typeUint32, typeEnum, typeSint32, typeSint64:
+		return wireVarint
+	}
+	return -1
+}
+
+// decodeWireFormat decodes a message without descriptor, field numbers are used as keys
+func decodeWireFormat(data []byte, depth int) (object, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("message is nested too deeply")
+	}
+
+	var values fieldValues
+	err := readFields(data, func(f wireField) error {
+		values.add(f.number, decodeUnknown(f, depth))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	o := values.unknownMembers(func(int32) bool { return false })
+	if o == nil {
+		o = object{}
+	}
+	return o, nil
+}
+
+// decodeUnknown decodes a field without descriptor. Length-delimited fields are shown as text if printable, as
+// message if they can be parsed as one or as base64 otherwise.
+func decodeUnknown(f wireField, depth int) any {
+	switch f.wireType {
+	case wireVarint, wireFixed64, wireFixed32:
+		return json.Number(strconv.FormatUint(f.value, 10))
+	}
+
+	if isPrintable(f.bytes) {
+		return string(f.bytes)
+	}
+	if nested, err := decodeWireFormat(f.bytes, depth+1); err == nil && len(nested) > 0 {
+		return nested
+	}
+	return base64.StdEncoding.EncodeToString(f.bytes)
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
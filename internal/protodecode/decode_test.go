@@ -0,0 +1,199 @@
+package protodecode_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/internal/protodecode"
+)
+
+// Helpers to encode messages in the wire format
+
+func varintField(number int32, v uint64) []byte {
+	b := binary.AppendUvarint(nil, uint64(number)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func bytesField(number int32, parts ...[]byte) []byte {
+	var content []byte
+	for _, p := range parts {
+		content = append(content, p...)
+	}
+	b := binary.AppendUvarint(nil, uint64(number)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(content)))
+	return append(b, content...)
+}
+
+func stringField(number int32, s string) []byte {
+	return bytesField(number, []byte(s))
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// fieldDescriptor encodes a FieldDescriptorProto
+func fieldDescriptor(name string, number int32, label int, typ int, typeName string) []byte {
+	b := concat(stringField(1, name), varintField(3, uint64(number)), varintField(4, uint64(label)), varintField(5, uint64(typ)))
+	if typeName != "" {
+		b = append(b, stringField(6, typeName)...)
+	}
+	return bytesField(2, b)
+}
+
+// testDescriptorSet encodes a FileDescriptorSet of:
+//
+//	package acme.v1;
+//
+//	message User {
+//	  enum Status { STATUS_UNKNOWN = 0; STATUS_ACTIVE = 1; }
+//	  message Address { string city = 1; }
+//	  string user_name = 1;
+//	  int64 id = 2;
+//	  repeated int32 scores = 3;
+//	  Status status = 4;
+//	  map<string, int32> counts = 5;
+//	  Address address = 6;
+//	  bytes avatar = 7;
+//	}
+//
+//	message GetUserRequest { int64 id = 1; }
+//
+//	service UserService { rpc GetUser(GetUserRequest) returns (User); }
+func testDescriptorSet() []byte {
+	const optional, repeated = 1, 3
+	user := concat(
+		stringField(1, "User"),
+		fieldDescriptor("user_name", 1, optional, 9, ""),
+		fieldDescriptor("id", 2, optional, 3, ""),
+		fieldDescriptor("scores", 3, repeated, 5, ""),
+		fieldDescriptor("status", 4, optional, 14, ".acme.v1.User.Status"),
+		fieldDescriptor("counts", 5, repeated, 11, ".acme.v1.User.CountsEntry"),
+		fieldDescriptor("address", 6, optional, 11, ".acme.v1.User.Address"),
+		fieldDescriptor("avatar", 7, optional, 12, ""),
+		bytesField(3, stringField(1, "Address"), fieldDescriptor("city", 1, optional, 9, "")),
+		bytesField(3,
+			stringField(1, "CountsEntry"),
+			fieldDescriptor("key", 1, optional, 9, ""),
+			fieldDescriptor("value", 2, optional, 5, ""),
+			bytesField(7, varintField(7, 1)),
+		),
+		bytesField(4,
+			stringField(1, "Status"),
+			bytesField(2, stringField(1, "STATUS_UNKNOWN"), varintField(2, 0)),
+			bytesField(2, stringField(1, "STATUS_ACTIVE"), varintField(2, 1)),
+		),
+	)
+	request := concat(stringField(1, "GetUserRequest"), fieldDescriptor("id", 1, optional, 3, ""))
+	service := concat(
+		stringField(1, "UserService"),
+		bytesField(2, stringField(1, "GetUser"), stringField(2, ".acme.v1.GetUserRequest"), stringField(3, ".acme.v1.User")),
+	)
+	file := concat(stringField(1, "acme/v1/user.proto"), stringField(2, "acme.v1"), bytesField(4, user), bytesField(4, request), bytesField(6, service))
+	return bytesField(1, file)
+}
+
+func TestRegistry_AddFileDescriptorSet(t *testing.T) {
+	registry := protodecode.NewRegistry()
+
+	n, err := registry.AddFileDescriptorSet(testDescriptorSet())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.True(t, registry.HasMessage("acme.v1.User.Address"))
+
+	input, output, ok := registry.MethodTypes("/acme.v1.UserService/GetUser")
+	assert.True(t, ok)
+	assert.Equal(t, "acme.v1.GetUserRequest", input)
+	assert.Equal(t, "acme.v1.User", output)
+
+	_, err = registry.AddFileDescriptorSet([]byte{0x0a, 0x05})
+	assert.Error(t, err)
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	registry := protodecode.NewRegistry()
+	_, err := registry.AddFileDescriptorSet(testDescriptorSet())
+	require.NoError(t, err)
+
+	message := concat(
+		stringField(1, "alice"),
+		varintField(2, 42),
+		bytesField(3, []byte{1, 2, 3}),
+		varintField(4, 1),
+		bytesField(5, stringField(1, "logins"), varintField(2, 7)),
+		bytesField(6, stringField(1, "Hamburg")),
+		bytesField(7, []byte{0xff, 0x00}),
+		varintField(99, 5),
+	)
+
+	decoded, err := registry.Decode(message, "acme.v1.User")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"userName": "alice",
+		"id": "42",
+		"scores": [1, 2, 3],
+		"status": "STATUS_ACTIVE",
+		"counts": {"logins": 7},
+		"address": {"city": "Hamburg"},
+		"avatar": "/wA=",
+		"99": 5
+	}`, string(decoded))
+
+	_, err = registry.Decode(message, "acme.v1.Unknown")
+	assert.Error(t, err)
+
+	_, err = registry.Decode(concat(stringField(1, "alice"), []byte{0x10}), "acme.v1.User")
+	assert.Error(t, err)
+}
+
+func TestRegistry_Decode_WithoutDescriptor(t *testing.T) {
+	registry := protodecode.NewRegistry()
+
+	message := concat(
+		stringField(1, "alice"),
+		varintField(2, 42),
+		bytesField(3, stringField(1, "Hamburg"), varintField(2, 20095)),
+		varintField(4, 1),
+		varintField(4, 2),
+	)
+
+	decoded, err := registry.Decode(message, "")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "1": "alice",
+  "2": 42,
+  "3": {
+    "1": "Hamburg",
+    "2": 20095
+  },
+  "4": [
+    1,
+    2
+  ]
+}`, string(decoded))
+}
+
+func TestUnframe(t *testing.T) {
+	body := concat(
+		[]byte{0, 0, 0, 0, 2}, varintField(1, 1),
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0x80, 0, 0, 0, 3}, []byte("a:b"),
+	)
+
+	messages, err := protodecode.Unframe(body)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{varintField(1, 1), {}}, messages)
+
+	_, err = protodecode.Unframe([]byte{0, 0, 0, 0, 9, 1})
+	assert.Error(t, err)
+
+	_, err = protodecode.Unframe([]byte{1, 0, 0, 0, 0})
+	assert.Error(t, err)
+}
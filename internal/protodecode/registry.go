@@ -0,0 +1,374 @@
+package protodecode
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Types of fields (FieldDescriptorProto.Type)
+const (
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+)
+
+const labelRepeated = 3
+
+type messageDescriptor struct {
+	name string
+	// fields in order of declaration
+	fields   []*fieldDescriptor
+	byNumber map[int32]*fieldDescriptor
+	mapEntry bool
+}
+
+type fieldDescriptor struct {
+	name     string
+	jsonName string
+	number   int32
+	repeated bool
+	typ      int32
+	// typeName is the fully-qualified name of message and enum types without leading dot
+	typeName string
+}
+
+type methodDescriptor struct {
+	inputType  string
+	outputType string
+}
+
+// Registry holds message, enum and method descriptors.
+// It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	messages map[string]*messageDescriptor
+	enums    map[string]map[int32]string
+	// methods by path of the RPC (e.g. "/acme.v1.UserService/GetUser")
+	methods map[string]methodDescriptor
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		messages: make(map[string]*messageDescriptor),
+		enums:    make(map[string]map[int32]string),
+		methods:  make(map[string]methodDescriptor),
+	}
+}
+
+// AddFileDescriptorSet registers all message types, enums and services of a serialized FileDescriptorSet.
+// Existing types with the same name are replaced. It returns the number of message types that were added.
+func (r *Registry) AddFileDescriptorSet(data []byte) (int, error) {
+	p := &descriptorParser{
+		messages: make(map[string]*messageDescriptor),
+		enums:    make(map[string]map[int32]string),
+		methods:  make(map[string]methodDescriptor),
+	}
+	err := readFields(data, func(f wireField) error {
+		// FileDescriptorSet.file
+		if f.number == 1 && f.wireType == wireBytes {
+			return p.parseFile(f.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("parsing file descriptor set: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, m := range p.messages {
+		r.messages[name] = m
+	}
+	for name, e := range p.enums {
+		r.enums[name] = e
+	}
+	for path, m := range p.methods {
+		r.methods[path] = m
+	}
+	return len(p.messages), nil
+}
+
+// HasMessage returns true if a message type with the fully-qualified name is registered
+func (r *Registry) HasMessage(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.messages[name]
+	return exists
+}
+
+// MethodTypes returns the input and output message types of the RPC with the given path (e.g. "/acme.v1.UserService/GetUser")
+func (r *Registry) MethodTypes(path string) (inputType string, outputType string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.methods[path]
+	return m.inputType, m.outputType, ok
+}
+
+// Len returns the number of registered message types
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.messages)
+}
+
+type descriptorParser struct {
+	messages map[string]*messageDescriptor
+	enums    map[string]map[int32]string
+	methods  map[string]methodDescriptor
+}
+
+// parseFile parses a FileDescriptorProto
+func (p *descriptorParser) parseFile(data []byte) error {
+	var (
+		pkg      string
+		messages [][]byte
+		enums    [][]byte
+		services [][]byte
+	)
+	err := readFields(data, func(f wireField) error {
+		if f.wireType != wireBytes {
+			return nil
+		}
+		switch f.number {
+		case 2:
+			pkg = string(f.bytes)
+		case 4:
+			messages = append(messages, f.bytes)
+		case 5:
+			enums = append(enums, f.bytes)
+		case 6:
+			services = append(services, f.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range messages {
+		if err := p.parseMessage(m, pkg); err != nil {
+			return err
+		}
+	}
+	for _, e := range enums {
+		if err := p.parseEnum(e, pkg); err != nil {
+			return err
+		}
+	}
+	for _, s := range services {
+		if err := p.parseService(s, pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a DescriptorProto including nested types
+func (p *descriptorParser) parseMessage(data []byte, scope string) error {
+	m := &messageDescriptor{byNumber: make(map[int32]*fieldDescriptor)}
+	var nested, enums [][]byte
+	err := readFields(data, func(f wireField) error {
+		if f.wireType != wireBytes {
+			return nil
+		}
+		switch f.number {
+		case 1:
+			m.name = string(f.bytes)
+		case 2:
+			field, err := parseField(f.bytes)
+			if err != nil {
+				return err
+			}
+			m.fields = append(m.fields, field)
+			m.byNumber[field.number] = field
+		case 3:
+			nested = append(nested, f.bytes)
+		case 4:
+			enums = append(enums, f.bytes)
+		case 7:
+			// MessageOptions.map_entry
+			return readFields(f.bytes, func(o wireField) error {
+				if o.number == 7 && o.wireType == wireVarint {
+					m.mapEntry = o.value != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fullName := qualify(scope, m.name)
+	p.messages[fullName] = m
+	m.name = fullName
+	for _, n := range nested {
+		if err := p.parseMessage(n, fullName); err != nil {
+			return err
+		}
+	}
+	for _, e := range enums {
+		if err := p.parseEnum(e, fullName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseField parses a FieldDescriptorProto
+func parseField(data []byte) (*fieldDescriptor, error) {
+	field := &fieldDescriptor{}
+	err := readFields(data, func(f wireField) error {
+		switch {
+		case f.number == 1 && f.wireType == wireBytes:
+			field.name = string(f.bytes)
+		case f.number == 3 && f.wireType == wireVarint:
+			field.number = int32(f.value)
+		case f.number == 4 && f.wireType == wireVarint:
+			field.repeated = f.value == labelRepeated
+		case f.number == 5 && f.wireType == wireVarint:
+			field.typ = int32(f.value)
+		case f.number == 6 && f.wireType == wireBytes:
+			field.typeName = strings.TrimPrefix(string(f.bytes), ".")
+		case f.number == 10 && f.wireType == wireBytes:
+			field.jsonName = string(f.bytes)
+		}
+		return nil
+	})
+	if field.jsonName == "" {
+		field.jsonName = jsonName(field.name)
+	}
+	return field, err
+}
+
+// parseEnum parses an EnumDescriptorProto
+func (p *descriptorParser) parseEnum(data []byte, scope string) error {
+	var name string
+	values := make(map[int32]string)
+	err := readFields(data, func(f wireField) error {
+		switch {
+		case f.number == 1 && f.wireType == wireBytes:
+			name = string(f.bytes)
+		case f.number == 2 && f.wireType == wireBytes:
+			// EnumValueDescriptorProto
+			var (
+				valueName string
+				number    int32
+			)
+			err := readFields(f.bytes, func(v wireField) error {
+				switch {
+				case v.number == 1 && v.wireType == wireBytes:
+					valueName = string(v.bytes)
+				case v.number == 2 && v.wireType == wireVarint:
+					number = int32(v.value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if _, exists := values[number]; !exists {
+				values[number] = valueName
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.enums[qualify(scope, name)] = values
+	return nil
+}
+
+// parseService parses a ServiceDescriptorProto
+func (p *descriptorParser) parseService(data []byte, pkg string) error {
+	var (
+		name    string
+		methods [][]byte
+	)
+	err := readFields(data, func(f wireField) error {
+		if f.wireType != wireBytes {
+			return nil
+		}
+		switch f.number {
+		case 1:
+			name = string(f.bytes)
+		case 2:
+			methods = append(methods, f.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, data := range methods {
+		var (
+			methodName string
+			method     methodDescriptor
+		)
+		err := readFields(data, func(f wireField) error {
+			if f.wireType != wireBytes {
+				return nil
+			}
+			switch f.number {
+			case 1:
+				methodName = string(f.bytes)
+			case 2:
+				method.inputType = strings.TrimPrefix(string(f.bytes), ".")
+			case 3:
+				method.outputType = strings.TrimPrefix(string(f.bytes), ".")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		p.methods["/"+qualify(pkg, name)+"/"+methodName] = method
+	}
+	return nil
+}
+
+func qualify(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// jsonName converts a field name to lowerCamelCase like protoc does for json_name
+func jsonName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
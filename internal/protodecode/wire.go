@@ -0,0 +1,115 @@
+// Package protodecode decodes protobuf messages into readable JSON without generated code.
+//
+// Message types are looked up in descriptors registered from a serialized FileDescriptorSet (as produced by
+// `protoc --descriptor_set_out` or `buf build -o`). Messages without a known type are decoded by their wire format
+// with field numbers as keys.
+package protodecode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Wire types of the protobuf encoding
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("unexpected end of message")
+
+// wireField is a single field read from an encoded message
+type wireField struct {
+	number   int32
+	wireType int
+	// value of varint and fixed fields
+	value uint64
+	// bytes of length-delimited fields
+	bytes []byte
+}
+
+// readFields calls fn for each field of an encoded message
+func readFields(data []byte, fn func(f wireField) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+		data = data[n:]
+
+		f := wireField{number: int32(tag >> 3), wireType: int(tag & 7)}
+		if f.number <= 0 {
+			return fmt.Errorf("invalid field number %d", f.number)
+		}
+		switch f.wireType {
+		case wireVarint:
+			f.value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errTruncated
+			}
+			f.value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return errTruncated
+			}
+			f.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errTruncated
+			}
+			f.value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			// Groups are deprecated and not supported
+			return fmt.Errorf("unsupported wire type %d of field %d", f.wireType, f.number)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unframe splits a body of enveloped messages (gRPC, gRPC-Web and Connect streaming) into the messages.
+// Each message is prefixed with a flags byte and a 4 byte big-endian length.
+func Unframe(data []byte) ([][]byte, error) {
+	var messages [][]byte
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, errTruncated
+		}
+		flags := data[0]
+		length := binary.BigEndian.Uint32(data[1:5])
+		if uint64(length) > uint64(len(data)-5) {
+			return nil, errTruncated
+		}
+		message := data[5 : 5+length]
+		data = data[5+length:]
+
+		switch {
+		case flags&0x80 != 0:
+			// Trailers of gRPC-Web or end of stream message of Connect
+			continue
+		case flags&0x01 != 0:
+			return nil, errors.New("compressed messages are not supported")
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
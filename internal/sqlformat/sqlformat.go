@@ -0,0 +1,348 @@
+// Package sqlformat pretty-prints SQL queries for display: clauses start on a new line, their lists and conditions are
+// indented one item per line and nested queries are indented further. Only whitespace between tokens is changed, so
+// literals, quoted identifiers and comments are kept as they are.
+package sqlformat
+
+import (
+	"strings"
+)
+
+// indent is the indentation of one level
+const indent = "  "
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenLineComment
+	tokenBlockComment
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// space is true if the token was preceded by whitespace
+	space bool
+}
+
+// upper returns the upper-cased text of a word, an empty string for other tokens
+func (t token) upper() string {
+	if t.kind != tokenWord {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// clauses start a new line at the indentation of the query, their content is indented on the following lines. Longer
+// keywords come first, so they match before their prefixes.
+var clauses = [][]string{
+	{"WITH", "RECURSIVE"}, {"WITH"},
+	{"SELECT", "DISTINCT"}, {"SELECT"},
+	{"INSERT", "INTO"}, {"INSERT"}, {"REPLACE", "INTO"}, {"VALUES"},
+	{"UPDATE"}, {"SET"}, {"DELETE", "FROM"}, {"DELETE"},
+	{"FROM"}, {"WHERE"}, {"GROUP", "BY"}, {"HAVING"}, {"WINDOW"}, {"ORDER", "BY"},
+	{"LIMIT"}, {"OFFSET"}, {"FETCH"}, {"FOR", "UPDATE"}, {"FOR", "SHARE"},
+	{"UNION", "ALL"}, {"UNION"}, {"INTERSECT"}, {"EXCEPT"},
+	{"ON", "CONFLICT"}, {"ON", "DUPLICATE", "KEY", "UPDATE"}, {"RETURNING"},
+}
+
+// joins start a new line at the indentation of the content of the FROM clause
+var joins = [][]string{
+	{"INNER", "JOIN"}, {"CROSS", "JOIN"}, {"NATURAL", "JOIN"}, {"STRAIGHT_JOIN"}, {"JOIN"},
+	{"LEFT", "OUTER", "JOIN"}, {"LEFT", "JOIN"}, {"RIGHT", "OUTER", "JOIN"}, {"RIGHT", "JOIN"},
+	{"FULL", "OUTER", "JOIN"}, {"FULL", "JOIN"},
+}
+
+// conditionClauses are clauses with conditions, an AND or OR in them starts a new line
+var conditionClauses = map[string]bool{"WHERE": true, "HAVING": true, "FROM": true, "DELETE FROM": true}
+
+// Format pretty-prints a query of the language (a dialect of sql-formatter, e.g. "postgresql", "mysql" or "sqlite")
+func Format(query string, language string) string {
+	tokens := tokenize(query, language)
+	f := &formatter{}
+	for i := 0; i < len(tokens); {
+		i += f.write(tokens, i)
+	}
+	return f.sb.String()
+}
+
+// frame is an open parenthesis
+type frame struct {
+	// subquery is true for a parenthesis around a nested query
+	subquery bool
+	// level and clause are the indentation and clause outside of the parenthesis
+	level  int
+	clause string
+}
+
+type formatter struct {
+	sb     strings.Builder
+	frames []frame
+	// level is the indentation of the clauses of the current query
+	level int
+	// clause is the current clause (e.g. "WHERE"), empty before the first clause
+	clause string
+	// newline is set if the next token starts a new line at the indentation newlineLevel
+	newline      bool
+	newlineLevel int
+	// between is set after BETWEEN until its AND
+	between bool
+}
+
+// write writes the token at i (or the keyword of multiple tokens starting at it) and returns the number of written
+// tokens
+func (f *formatter) write(tokens []token, i int) int {
+	t := tokens[i]
+	topLevel := len(f.frames) == 0 || f.frames[len(f.frames)-1].subquery
+
+	if topLevel && t.kind == tokenWord {
+		if keyword := match(tokens, i, clauses); keyword != nil && f.isClause(tokens, i, keyword) {
+			f.clause = strings.Join(keyword, " ")
+			f.between = false
+			f.writeKeyword(tokens, i, len(keyword), f.level)
+			f.breakLine(f.level + 1)
+			return len(keyword)
+		}
+		if keyword := match(tokens, i, joins); keyword != nil {
+			f.writeKeyword(tokens, i, len(keyword), f.level+1)
+			return len(keyword)
+		}
+		switch t.upper() {
+		case "BETWEEN":
+			f.between = true
+		case "AND", "OR":
+			if t.upper() == "AND" && f.between {
+				f.between = false
+			} else if conditionClauses[f.clause] {
+				f.newline, f.newlineLevel = true, f.level+1
+			}
+		}
+	}
+
+	switch {
+	case t.kind == tokenPunct && t.text == "(":
+		f.writeToken(t)
+		subquery := false
+		if next := nextSignificant(tokens, i+1); next != nil {
+			subquery = next.upper() == "SELECT" || next.upper() == "WITH"
+		}
+		f.frames = append(f.frames, frame{subquery: subquery, level: f.level, clause: f.clause})
+		if subquery {
+			f.level += 2
+			f.clause = ""
+		}
+	case t.kind == tokenPunct && t.text == ")" && len(f.frames) > 0:
+		fr := f.frames[len(f.frames)-1]
+		f.frames = f.frames[:len(f.frames)-1]
+		if fr.subquery {
+			f.level, f.clause = fr.level, fr.clause
+			f.newline, f.newlineLevel = true, f.level+1
+		}
+		f.writeToken(t)
+	case t.kind == tokenPunct && t.text == "," && topLevel && f.clause != "":
+		f.writeToken(t)
+		f.breakLine(f.level + 1)
+	case t.kind == tokenPunct && t.text == ";" && len(f.frames) == 0:
+		f.writeToken(t)
+		f.level, f.clause = 0, ""
+		f.breakLine(0)
+	case t.kind == tokenLineComment:
+		f.writeToken(t)
+		if f.clause != "" {
+			f.breakLine(f.level + 1)
+		} else {
+			f.breakLine(f.level)
+		}
+	default:
+		f.writeToken(t)
+	}
+	return 1
+}
+
+// isClause checks if a matched clause keyword starts a clause, e.g. UPDATE in "ON CONFLICT DO UPDATE" does not
+func (f *formatter) isClause(tokens []token, i int, keyword []string) bool {
+	previous := previousWord(tokens, i)
+	switch keyword[0] {
+	case "UPDATE":
+		return previous != "DO"
+	case "FROM":
+		// IS DISTINCT FROM
+		return previous != "DISTINCT"
+	case "SET":
+		// UPDATE ... SET starts a clause, but not SET in DDL (e.g. CHARACTER SET)
+		return previous != "CHARACTER"
+	}
+	return true
+}
+
+// writeKeyword writes the n tokens of a keyword starting at i on a new line at the given level
+func (f *formatter) writeKeyword(tokens []token, i, n, level int) {
+	if f.sb.Len() > 0 {
+		f.newline, f.newlineLevel = true, level
+	}
+	for j := i; j < i+n; j++ {
+		f.writeToken(tokens[j])
+	}
+}
+
+// breakLine starts a new line at the level before the next token
+func (f *formatter) breakLine(level int) {
+	f.newline, f.newlineLevel = true, level
+}
+
+func (f *formatter) writeToken(t token) {
+	switch {
+	case f.sb.Len() == 0:
+	case f.newline:
+		f.sb.WriteString("\n")
+		f.sb.WriteString(strings.Repeat(indent, f.newlineLevel))
+	case t.space && !(t.kind == tokenPunct && (t.text == "," || t.text == ";")):
+		f.sb.WriteString(" ")
+	}
+	f.newline = false
+	f.sb.WriteString(t.text)
+}
+
+// match returns the keyword of keywords that the words starting at i match, nil if none matches
+func match(tokens []token, i int, keywords [][]string) []string {
+	for _, keyword := range keywords {
+		if i+len(keyword) > len(tokens) {
+			continue
+		}
+		matches := true
+		for j, word := range keyword {
+			if tokens[i+j].upper() != word {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return keyword
+		}
+	}
+	return nil
+}
+
+// nextSignificant returns the next token starting at i that is not a comment
+func nextSignificant(tokens []token, i int) *token {
+	for ; i < len(tokens); i++ {
+		if tokens[i].kind != tokenLineComment && tokens[i].kind != tokenBlockComment {
+			return &tokens[i]
+		}
+	}
+	return nil
+}
+
+// previousWord returns the upper-cased word before i, an empty string if the previous token is not a word
+func previousWord(tokens []token, i int) string {
+	if i == 0 {
+		return ""
+	}
+	return tokens[i-1].upper()
+}
+
+// tokenize splits a query into words, quoted literals and identifiers, comments and punctuation
+func tokenize(query string, language string) []token {
+	mysql := language == "mysql" || language == "mariadb"
+
+	var (
+		tokens []token
+		space  bool
+	)
+	add := func(kind tokenKind, start, end int) int {
+		tokens = append(tokens, token{kind: kind, text: query[start:end], space: space})
+		space = false
+		return end
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			i++
+		case strings.HasPrefix(query[i:], "--") || (mysql && c == '#'):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			i = add(tokenLineComment, i, i+end)
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				i = add(tokenBlockComment, i, len(query))
+			} else {
+				i = add(tokenBlockComment, i, i+2+end+2)
+			}
+		case c == '\'' || c == '"' || (mysql && c == '`'):
+			// MySQL and escape strings of PostgreSQL (E'...') treat a backslash as escape character
+			backslash := mysql || (c == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && !space && len(tokens) > 0 && tokens[len(tokens)-1].text == query[i-1:i])
+			i = add(tokenQuoted, i, closingQuote(query, i, backslash))
+		case c == '[' && language == "tsql":
+			end := strings.IndexByte(query[i:], ']')
+			if end == -1 {
+				i = add(tokenQuoted, i, len(query))
+			} else {
+				i = add(tokenQuoted, i, i+end+1)
+			}
+		case c == '$' && language == "postgresql" && dollarQuoteTag(query, i) != "":
+			tag := dollarQuoteTag(query, i)
+			end := strings.Index(query[i+len(tag):], tag)
+			if end == -1 {
+				i = add(tokenQuoted, i, len(query))
+			} else {
+				i = add(tokenQuoted, i, i+len(tag)+end+len(tag))
+			}
+		case isWordChar(c):
+			end := i + 1
+			for end < len(query) && isWordChar(query[end]) {
+				end++
+			}
+			i = add(tokenWord, i, end)
+		default:
+			i = add(tokenPunct, i, i+1)
+		}
+	}
+	return tokens
+}
+
+// closingQuote returns the index after the quote closing a literal or identifier starting at i, a doubled quote (or
+// an escaped quote with backslash set) is part of it
+func closingQuote(query string, i int, backslash bool) int {
+	q := query[i]
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if backslash {
+				j++
+			}
+		case q:
+			if j+1 < len(query) && query[j+1] == q {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarQuoteTag returns the tag of a PostgreSQL dollar-quoted string starting at i (e.g. "$$" or "$body$"), an empty
+// string if there is none
+func dollarQuoteTag(query string, i int) string {
+	j := i + 1
+	for j < len(query) && isWordChar(query[j]) && query[j] != '$' && (j > i+1 || query[j] < '0' || query[j] > '9') {
+		j++
+	}
+	if j < len(query) && query[j] == '$' {
+		return query[i : j+1]
+	}
+	return ""
+}
+
+// isWordChar checks if c is part of a keyword, identifier, number or placeholder (e.g. "$1")
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
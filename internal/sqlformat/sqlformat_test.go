@@ -0,0 +1,117 @@
+package sqlformat_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkteam/devlog/internal/sqlformat"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		language string
+		expected string
+	}{
+		{
+			name:     "select",
+			query:    "select id, name from users where active = true and (age > ? or admin) order by name limit 10",
+			language: "sqlite",
+			expected: `select
+  id,
+  name
+from
+  users
+where
+  active = true
+  and (age > ? or admin)
+order by
+  name
+limit
+  10`,
+		},
+		{
+			name:     "joins, functions and between",
+			query:    "SELECT u.id, count(o.id) AS orders FROM users u LEFT JOIN orders o ON o.user_id = u.id AND o.deleted_at IS NULL WHERE o.created_at BETWEEN $1 AND $2::timestamptz GROUP BY u.id",
+			language: "postgresql",
+			expected: `SELECT
+  u.id,
+  count(o.id) AS orders
+FROM
+  users u
+  LEFT JOIN orders o ON o.user_id = u.id
+  AND o.deleted_at IS NULL
+WHERE
+  o.created_at BETWEEN $1 AND $2::timestamptz
+GROUP BY
+  u.id`,
+		},
+		{
+			name:     "subquery and common table expression",
+			query:    "WITH recent AS (SELECT * FROM orders WHERE created_at > now() - interval '1 day') SELECT * FROM users WHERE id IN (SELECT user_id FROM recent)",
+			language: "postgresql",
+			expected: `WITH
+  recent AS (
+    SELECT
+      *
+    FROM
+      orders
+    WHERE
+      created_at > now() - interval '1 day'
+  )
+SELECT
+  *
+FROM
+  users
+WHERE
+  id IN (
+    SELECT
+      user_id
+    FROM
+      recent
+  )`,
+		},
+		{
+			name:     "insert with upsert",
+			query:    "INSERT INTO users (id, name) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET name = excluded.name RETURNING id",
+			language: "postgresql",
+			expected: `INSERT INTO
+  users (id, name)
+VALUES
+  ($1, $2),
+  ($3, $4)
+ON CONFLICT
+  (id) DO UPDATE
+SET
+  name = excluded.name
+RETURNING
+  id`,
+		},
+		{
+			name:     "literals, identifiers and comments are kept",
+			query:    "SELECT 'a,  from' , \"select\", $$ x , y $$ -- where\nFROM t /* order by */",
+			language: "postgresql",
+			expected: "SELECT\n  'a,  from',\n  \"select\",\n  $$ x , y $$ -- where\nFROM\n  t /* order by */",
+		},
+		{
+			name:     "mysql escapes and backticks",
+			query:    "UPDATE `order` SET note = 'it\\'s, done' WHERE id = 1 # comment",
+			language: "mysql",
+			expected: "UPDATE\n  `order`\nSET\n  note = 'it\\'s, done'\nWHERE\n  id = 1 # comment",
+		},
+		{
+			name:     "multiple statements",
+			query:    "BEGIN; DELETE FROM sessions WHERE expires_at < ?; COMMIT;",
+			language: "sqlite",
+			expected: "BEGIN;\nDELETE FROM\n  sessions\nWHERE\n  expires_at < ?;\nCOMMIT;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sqlformat.Format(tt.query, tt.language))
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package sqlinterpolate substitutes the arguments of a captured query into its placeholders, so the query can be run
+// manually (e.g. in psql or sqlite3). The result is approximate: arguments are formatted as SQL literals of the dialect,
+// which can differ from how the driver sends them to the database.
+package sqlinterpolate
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate replaces the placeholders of a query by its arguments formatted as SQL literals of the language (a
+// dialect of sql-formatter, e.g. "postgresql", "mysql" or "sqlite"). Positional placeholders ("?", "?2", "$1" and
+// "@p1") refer to the ordinal of an argument, named placeholders (":name", "@name" and "$name") to its name. Named
+// placeholders without an argument (e.g. MySQL user variables) are kept. Placeholders in string literals, quoted
+// identifiers and comments are kept. An error is returned if a positional placeholder has no argument.
+func Interpolate(query string, args []driver.NamedValue, language string) (string, error) {
+	byOrdinal := make(map[int]driver.NamedValue, len(args))
+	byName := make(map[string]driver.NamedValue, len(args))
+	for _, arg := range args {
+		byOrdinal[arg.Ordinal] = arg
+		if arg.Name != "" {
+			byName[arg.Name] = arg
+		}
+	}
+
+	// In PostgreSQL "?" is a JSON operator, placeholders are numbered
+	questionMarks := language != "postgresql"
+
+	var (
+		sb   strings.Builder
+		next = 1
+	)
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(query, i+1, c)
+			sb.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				end = len(query) - i - 4
+			}
+			sb.WriteString(query[i : i+end+4])
+			i += end + 4
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			// PostgreSQL type cast
+			sb.WriteString("::")
+			i += 2
+		case c == '?' && questionMarks:
+			digits := scanDigits(query, i+1)
+			ordinal := next
+			if digits > 0 {
+				ordinal, _ = strconv.Atoi(query[i+1 : i+1+digits])
+			}
+			arg, ok := byOrdinal[ordinal]
+			if !ok {
+				return "", fmt.Errorf("no argument for placeholder %s", query[i:i+1+digits])
+			}
+			sb.WriteString(literal(arg.Value, language))
+			next = ordinal + 1
+			i += 1 + digits
+		case c == '$' && (i == 0 || !isIdentChar(query[i-1])):
+			if tag, ok := dollarQuoteTag(query, i); ok {
+				// PostgreSQL dollar-quoted string
+				end := strings.Index(query[i+len(tag):], tag)
+				if end == -1 {
+					end = len(query) - i - 2*len(tag)
+				}
+				sb.WriteString(query[i : i+2*len(tag)+end])
+				i += 2*len(tag) + end
+				continue
+			}
+			if digits := scanDigits(query, i+1); digits > 0 {
+				ordinal, _ := strconv.Atoi(query[i+1 : i+1+digits])
+				arg, ok := byOrdinal[ordinal]
+				if !ok {
+					return "", fmt.Errorf("no argument for placeholder %s", query[i:i+1+digits])
+				}
+				sb.WriteString(literal(arg.Value, language))
+				i += 1 + digits
+				continue
+			}
+			n, err := writeNamed(&sb, query, i, byOrdinal, byName, language)
+			if err != nil {
+				return "", err
+			}
+			i += n
+		case (c == ':' || c == '@') && (i == 0 || !isIdentChar(query[i-1])):
+			n, err := writeNamed(&sb, query, i, byOrdinal, byName, language)
+			if err != nil {
+				return "", err
+			}
+			i += n
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String(), nil
+}
+
+// writeNamed writes the argument of the named placeholder at i or the placeholder itself if there is no argument with
+// its name, it returns the length of the placeholder. SQL Server placeholders ("@p1") refer to the ordinal of an
+// argument, unless an argument is named like them.
+func writeNamed(sb *strings.Builder, query string, i int, byOrdinal map[int]driver.NamedValue, byName map[string]driver.NamedValue, language string) (int, error) {
+	n := 1
+	for i+n < len(query) && isIdentChar(query[i+n]) {
+		n++
+	}
+	name := query[i+1 : i+n]
+	if arg, ok := byName[name]; ok && name != "" {
+		sb.WriteString(literal(arg.Value, language))
+		return n, nil
+	}
+	if query[i] == '@' && len(name) > 1 && name[0] == 'p' && scanDigits(name, 1) == len(name)-1 {
+		ordinal, _ := strconv.Atoi(name[1:])
+		arg, ok := byOrdinal[ordinal]
+		if !ok {
+			return 0, fmt.Errorf("no argument for placeholder %s", query[i:i+n])
+		}
+		sb.WriteString(literal(arg.Value, language))
+		return n, nil
+	}
+	sb.WriteString(query[i : i+n])
+	return n, nil
+}
+
+// literal formats a driver value as SQL literal of the language
+func literal(value driver.Value, language string) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quote(v, language)
+	case []byte:
+		switch language {
+		case "postgresql":
+			return `'\x` + hex.EncodeToString(v) + "'"
+		case "tsql":
+			return "0x" + strings.ToUpper(hex.EncodeToString(v))
+		}
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	case bool:
+		if language == "tsql" {
+			if v {
+				return "1"
+			}
+			return "0"
+		}
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return quote(s, language)
+		}
+		return s
+	case time.Time:
+		if language == "mysql" || language == "mariadb" {
+			return quote(v.Format("2006-01-02 15:04:05.999999"), language)
+		}
+		return quote(v.Format("2006-01-02 15:04:05.999999999-07:00"), language)
+	}
+	return quote(fmt.Sprint(value), language)
+}
+
+// quote formats a string literal, MySQL also treats a backslash as escape character
+func quote(s string, language string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if language == "mysql" || language == "mariadb" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
+
+// closingQuote returns the index after the quote closing a literal or identifier started before i, a doubled quote is
+// part of it
+func closingQuote(query string, i int, q byte) int {
+	for i < len(query) {
+		if query[i] == q {
+			if i+1 < len(query) && query[i+1] == q {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return len(query)
+}
+
+// dollarQuoteTag returns the tag of a PostgreSQL dollar-quoted string starting at i (e.g. "$$" or "$body$")
+func dollarQuoteTag(query string, i int) (string, bool) {
+	j := i + 1
+	for j < len(query) && isIdentChar(query[j]) && (j > i+1 || !isDigit(query[j])) {
+		j++
+	}
+	if j < len(query) && query[j] == '$' {
+		return query[i : j+1], true
+	}
+	return "", false
+}
+
+func scanDigits(query string, i int) int {
+	n := 0
+	for i+n < len(query) && isDigit(query[i+n]) {
+		n++
+	}
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
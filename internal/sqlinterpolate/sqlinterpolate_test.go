@@ -0,0 +1,104 @@
+package sqlinterpolate_test
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/internal/sqlinterpolate"
+)
+
+func args(values ...driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(values))
+	for i, value := range values {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+	}
+	return named
+}
+
+func TestInterpolate(t *testing.T) {
+	createdAt := time.Date(2025, 6, 1, 12, 30, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		args     []driver.NamedValue
+		language string
+		expected string
+	}{
+		{
+			name:     "question marks",
+			query:    "SELECT * FROM users WHERE name = ? AND age > ? AND active = ?",
+			args:     args("O'Brien", int64(30), true),
+			language: "sqlite",
+			expected: "SELECT * FROM users WHERE name = 'O''Brien' AND age > 30 AND active = TRUE",
+		},
+		{
+			name:     "numbered question marks",
+			query:    "UPDATE users SET name = ?2 WHERE id = ?1",
+			args:     args(int64(7), "Alice"),
+			language: "sqlite",
+			expected: "UPDATE users SET name = 'Alice' WHERE id = 7",
+		},
+		{
+			name:     "dollar placeholders with casts",
+			query:    "SELECT $1::jsonb ? 'key', $2::timestamptz, $1",
+			args:     args(`{"key":1}`, createdAt),
+			language: "postgresql",
+			expected: `SELECT '{"key":1}'::jsonb ? 'key', '2025-06-01 12:30:00.5+00:00'::timestamptz, '{"key":1}'`,
+		},
+		{
+			name:     "named placeholders",
+			query:    "INSERT INTO files (name, data) VALUES (:name, @data) -- :ignored",
+			args:     []driver.NamedValue{{Name: "name", Ordinal: 1, Value: "a.txt"}, {Name: "data", Ordinal: 2, Value: []byte{0xca, 0xfe}}},
+			language: "sqlite",
+			expected: "INSERT INTO files (name, data) VALUES ('a.txt', X'CAFE') -- :ignored",
+		},
+		{
+			name:     "placeholders in literals, identifiers and comments are kept",
+			query:    `SELECT '?', "col?", /* ? */ $$ $1 $$, ? FROM t`,
+			args:     args(nil),
+			language: "sql",
+			expected: `SELECT '?', "col?", /* ? */ $$ $1 $$, NULL FROM t`,
+		},
+		{
+			name:     "mysql escapes backslashes and has no time zone",
+			query:    "SELECT ?, ?, @user_var",
+			args:     args(`C:\tmp`, createdAt),
+			language: "mysql",
+			expected: `SELECT 'C:\\tmp', '2025-06-01 12:30:00.5', @user_var`,
+		},
+		{
+			name:     "sql server",
+			query:    "SELECT * FROM t WHERE a = @p1 AND b = @p2",
+			args:     args(false, []byte{0x01}),
+			language: "tsql",
+			expected: "SELECT * FROM t WHERE a = 0 AND b = 0x01",
+		},
+		{
+			name:     "floats",
+			query:    "SELECT ?, ?",
+			args:     args(1.5, 1e21),
+			language: "sqlite",
+			expected: "SELECT 1.5, 1e+21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interpolated, err := sqlinterpolate.Interpolate(tt.query, tt.args, tt.language)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, interpolated)
+		})
+	}
+}
+
+func TestInterpolate_MissingArgument(t *testing.T) {
+	for _, query := range []string{"SELECT ?, ?", "SELECT $2", "SELECT @p3"} {
+		_, err := sqlinterpolate.Interpolate(query, args(int64(1)), "")
+		assert.Error(t, err, query)
+	}
+}
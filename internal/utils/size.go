@@ -155,9 +155,9 @@ func sizeOf(v reflect.Value, cache map[uintptr]bool) int {
 			}
 			sum += sk
 		}
-		// Include overhead due to unused map buckets.  10.79 comes
-		// from https://golang.org/src/runtime/map.go.
-		return sum + int(v.Type().Size()) + int(float64(len(keys))*10.79)
+		// Include the map header, bucket metadata and unused slots, the keys and values were already summed up
+		slotSize := int(v.Type().Key().Size() + v.Type().Elem().Size())
+		return sum + int(v.Type().Size()) + MapSize(len(keys), slotSize) - len(keys)*slotSize
 
 	case reflect.Interface:
 		return sizeOf(v.Elem(), cache) + int(v.Type().Size())
@@ -168,3 +168,23 @@ func sizeOf(v reflect.Value, cache map[uintptr]bool) int {
 
 	return -1
 }
+
+// MapSize estimates the memory of a map with n entries besides the memory referenced by its keys and values, slotSize
+// is the size of a key and its value. Entries are stored in buckets of 8 slots with 16 bytes of metadata (tophash and
+// overflow pointer, or control word and table bookkeeping since Go 1.24) and the buckets double when they are filled
+// to 6.5 entries on average. This doesn't follow one map implementation exactly, it is close for both.
+func MapSize(n int, slotSize int) int {
+	const (
+		headerSize   = 48
+		bucketSlots  = 8
+		bucketHeader = 16
+	)
+	if n == 0 {
+		return 0
+	}
+	buckets := 1
+	for n > bucketSlots && buckets*bucketSlots*13/16 < n {
+		buckets *= 2
+	}
+	return headerSize + buckets*(bucketHeader+bucketSlots*slotSize)
+}
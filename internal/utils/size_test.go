@@ -30,9 +30,9 @@ func TestSizeOf(t *testing.T) {
 		{
 			name: "Map",
 			// (8 + 3 + 16) + (8 + 4 + 16) = 55
-			// 55 + 8 + 10.79 * 2 = 84
+			// 55 + 8 + 48 (header) + 16 + 8 * 24 (bucket) - 2 * 24 (used slots) = 271
 			v:    map[int64]string{0: "ABC", 1: "DEFG"},
-			want: 84,
+			want: 271,
 		},
 		{
 			name: "Struct",
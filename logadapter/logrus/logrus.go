@@ -0,0 +1,86 @@
+package logrusadapter
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook that passes log entries as slog records to a handler.
+// Use it with the handler returned by devlog.Instance.CollectSlogLogs to collect logrus logs.
+type Hook struct {
+	handler slog.Handler
+}
+
+var _ logrus.Hook = &Hook{}
+
+// NewHook creates a hook that passes entries to the given handler
+func NewHook(handler slog.Handler) *Hook {
+	return &Hook{
+		handler: handler,
+	}
+}
+
+// Levels implements logrus.Hook, filtering of levels is done by the handler
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	level := toSlogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+
+	record := slog.NewRecord(entry.Time, level, entry.Message, pc)
+	record.AddAttrs(toAttrs(entry.Data)...)
+
+	return h.handler.Handle(ctx, record)
+}
+
+func toSlogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return slog.LevelDebug - 4
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	case logrus.FatalLevel:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError + 8
+	}
+}
+
+// toAttrs converts fields to attributes ordered by key, since fields are not ordered
+func toAttrs(fields logrus.Fields) []slog.Attr {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return attrs
+}
@@ -0,0 +1,52 @@
+package logrusadapter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	logrusadapter "github.com/networkteam/devlog/logadapter/logrus"
+)
+
+func TestHook(t *testing.T) {
+	logCollector := collector.NewLogCollector()
+	defer logCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records := logCollector.Subscribe(ctx)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.TraceLevel)
+	logger.AddHook(logrusadapter.NewHook(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{
+		Level: slog.LevelInfo,
+	})))
+
+	logger.Debug("Not collected")
+	logger.WithFields(logrus.Fields{
+		"user": "alice",
+		"id":   42,
+	}).WithError(errors.New("failed")).Warn("Something happened")
+
+	record := <-records
+	assert.Equal(t, "Something happened", record.Message)
+	assert.Equal(t, slog.LevelWarn, record.Level)
+
+	attrs := map[string]any{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.Any()
+		return true
+	})
+	require.Len(t, attrs, 3)
+	assert.Equal(t, "alice", attrs["user"])
+	assert.Equal(t, int64(42), attrs["id"])
+	assert.EqualError(t, attrs["error"].(error), "failed")
+}
@@ -0,0 +1,21 @@
+module github.com/networkteam/devlog/logadapter/zap
+
+go 1.23.8
+
+replace github.com/networkteam/devlog => ../../
+
+require (
+	github.com/gofrs/uuid v4.4.0+incompatible
+	github.com/networkteam/devlog v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/samber/lo v1.50.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,194 @@
+package zapadapter
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// contextFieldKey is the key of the field created by Context
+const contextFieldKey = "devlog.context"
+
+// Context returns a field that passes the context to the core, which is needed to collect logs in session capture mode.
+// The field is skipped by other cores.
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextFieldKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// Core is a zapcore.Core that passes log entries as slog records to a handler.
+// Use it with the handler returned by devlog.Instance.CollectSlogLogs and zapcore.NewTee to collect zap logs.
+type Core struct {
+	handler slog.Handler
+	ctx     context.Context
+	fields  []zapcore.Field
+}
+
+var _ zapcore.Core = &Core{}
+
+// NewCore creates a core that passes entries to the given handler
+func NewCore(handler slog.Handler) *Core {
+	return &Core{
+		handler: handler,
+		ctx:     context.Background(),
+	}
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(c.ctx, toSlogLevel(level))
+}
+
+// With implements zapcore.Core.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	ctx, fields := extractContext(c.ctx, fields)
+	return &Core{
+		handler: c.handler,
+		ctx:     ctx,
+		fields:  append(slices.Clone(c.fields), fields...),
+	}
+}
+
+// Check implements zapcore.Core.
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core.
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	ctx, fields := extractContext(c.ctx, fields)
+
+	var pc uintptr
+	if entry.Caller.Defined {
+		pc = entry.Caller.PC
+	}
+
+	record := slog.NewRecord(entry.Time, toSlogLevel(entry.Level), entry.Message, pc)
+	if entry.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+	record.AddAttrs(toAttrs(append(slices.Clone(c.fields), fields...))...)
+	if entry.Stack != "" {
+		record.AddAttrs(slog.String("stacktrace", entry.Stack))
+	}
+
+	return c.handler.Handle(ctx, record)
+}
+
+// Sync implements zapcore.Core.
+func (c *Core) Sync() error {
+	return nil
+}
+
+func extractContext(ctx context.Context, fields []zapcore.Field) (context.Context, []zapcore.Field) {
+	if !slices.ContainsFunc(fields, isContextField) {
+		return ctx, fields
+	}
+
+	result := make([]zapcore.Field, 0, len(fields)-1)
+	for _, f := range fields {
+		if isContextField(f) {
+			ctx = f.Interface.(context.Context)
+			continue
+		}
+		result = append(result, f)
+	}
+	return ctx, result
+}
+
+func isContextField(f zapcore.Field) bool {
+	if f.Type != zapcore.SkipType || f.Key != contextFieldKey {
+		return false
+	}
+	_, ok := f.Interface.(context.Context)
+	return ok
+}
+
+func toSlogLevel(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	case zapcore.ErrorLevel:
+		return slog.LevelError
+	case zapcore.DPanicLevel:
+		return slog.LevelError + 2
+	case zapcore.PanicLevel:
+		return slog.LevelError + 4
+	case zapcore.FatalLevel:
+		return slog.LevelError + 8
+	default:
+		return slog.Level(level) * 4
+	}
+}
+
+// toAttrs converts fields to attributes, fields after a namespace are nested in a group
+func toAttrs(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+			continue
+		case zapcore.NamespaceType:
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(toAttrs(fields[i+1:])...)})
+		}
+		attrs = append(attrs, toAttr(f))
+	}
+	return attrs
+}
+
+func toAttr(f zapcore.Field) slog.Attr {
+	switch f.Type {
+	case zapcore.BoolType:
+		return slog.Bool(f.Key, f.Integer == 1)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return slog.Int64(f.Key, f.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return slog.Uint64(f.Key, uint64(f.Integer))
+	case zapcore.Float64Type:
+		return slog.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))
+	case zapcore.Float32Type:
+		return slog.Float64(f.Key, float64(math.Float32frombits(uint32(f.Integer))))
+	case zapcore.StringType:
+		return slog.String(f.Key, f.String)
+	case zapcore.DurationType:
+		return slog.Duration(f.Key, time.Duration(f.Integer))
+	case zapcore.ErrorType:
+		return slog.Any(f.Key, f.Interface)
+	}
+
+	// Let zap encode all other types (times, objects, arrays, stringers, reflected values)
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return toAttrFromValue(f.Key, enc.Fields[f.Key])
+}
+
+// toAttrFromValue converts encoded objects to groups ordered by key
+func toAttrFromValue(key string, value any) slog.Attr {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return slog.Any(key, value)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, toAttrFromValue(k, m[k]))
+	}
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
@@ -0,0 +1,83 @@
+package zapadapter_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/networkteam/devlog/collector"
+	zapadapter "github.com/networkteam/devlog/logadapter/zap"
+)
+
+func TestCore(t *testing.T) {
+	logCollector := collector.NewLogCollector()
+	defer logCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records := logCollector.Subscribe(ctx)
+
+	logger := zap.New(zapadapter.NewCore(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{
+		Level: slog.LevelInfo,
+	}))).Named("app")
+
+	logger.Debug("Not collected")
+	logger.With(zap.String("user", "alice")).Info("Something happened",
+		zap.Int("id", 42),
+		zap.Duration("took", time.Second),
+		zap.Namespace("details"),
+		zap.Bool("cached", true),
+	)
+
+	record := <-records
+	assert.Equal(t, "Something happened", record.Message)
+	assert.Equal(t, slog.LevelInfo, record.Level)
+
+	var attrs []slog.Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	require.Len(t, attrs, 5)
+	assert.Equal(t, "logger", attrs[0].Key)
+	assert.Equal(t, "app", attrs[0].Value.String())
+	assert.Equal(t, "user", attrs[1].Key)
+	assert.Equal(t, "alice", attrs[1].Value.String())
+	assert.Equal(t, int64(42), attrs[2].Value.Int64())
+	assert.Equal(t, time.Second, attrs[3].Value.Duration())
+	assert.Equal(t, "details", attrs[4].Key)
+	require.Equal(t, slog.KindGroup, attrs[4].Value.Kind())
+	assert.Equal(t, "cached", attrs[4].Value.Group()[0].Key)
+}
+
+func TestCore_Context(t *testing.T) {
+	aggregator := collector.NewEventAggregator()
+	defer aggregator.Close()
+
+	storage := collector.NewCaptureStorage(uuid.Must(uuid.NewV4()), 100, collector.CaptureModeSession)
+	aggregator.RegisterStorage(storage)
+
+	logCollector := collector.NewLogCollectorWithOptions(collector.LogOptions{
+		EventAggregator: aggregator,
+	})
+	defer logCollector.Close()
+
+	logger := zap.New(zapadapter.NewCore(collector.NewSlogLogCollectorHandler(logCollector, collector.CollectSlogLogsOptions{})))
+
+	ctx := collector.WithSessionIDs(context.Background(), []uuid.UUID{storage.SessionID()})
+	logger.Info("Without context")
+	logger.Info("With context", zapadapter.Context(ctx))
+
+	require.Eventually(t, func() bool {
+		return len(storage.GetEvents(10)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	record := storage.GetEvents(10)[0].Data.(slog.Record)
+	assert.Equal(t, "With context", record.Message)
+}
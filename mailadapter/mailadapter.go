@@ -0,0 +1,192 @@
+// Package mailadapter collects emails sent with net/smtp or a mail library. Messages are parsed from their RFC 5322
+// format, so any library that can write a message works: the headers, the plain text and HTML bodies and the metadata
+// of attachments are collected.
+package mailadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// maxPartDepth limits the nesting of multipart messages
+const maxPartDepth = 10
+
+// SendMailFunc has the signature of smtp.SendMail
+type SendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// ContextSendMailFunc is a SendMailFunc with the context of the request that triggered the mail
+type ContextSendMailFunc func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// WrapSendMail returns a function that sends mails with send (e.g. smtp.SendMail) and collects them with collect, e.g.
+// devlog.Instance.CollectMail. Recipients of the envelope that are not listed in the headers are collected as Bcc.
+func WrapSendMail(collect func(ctx context.Context, mail collector.Mail), send SendMailFunc) ContextSendMailFunc {
+	return func(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		start := time.Now()
+		err := send(addr, a, from, to, msg)
+
+		m := ParseMessage(msg)
+		if m.From == "" {
+			m.From = from
+		}
+		m.Bcc = append(m.Bcc, envelopeOnly(to, m)...)
+		m.Server = addr
+		m.Timestamp = start
+		m.Duration = time.Since(start)
+		m.Error = err
+		collect(ctx, m)
+
+		return err
+	}
+}
+
+// Message is implemented by messages of mail libraries that write themselves in RFC 5322 format, e.g. *mail.Msg of
+// github.com/wneessen/go-mail or *gomail.Message of gopkg.in/gomail.v2
+type Message interface {
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// CollectMessage collects a message of a mail library with collect, e.g. devlog.Instance.CollectMail. Call it after
+// sending with the start time and the error of sending.
+func CollectMessage(ctx context.Context, collect func(ctx context.Context, mail collector.Mail), msg Message, start time.Time, err error) {
+	var buf bytes.Buffer
+	// A message that cannot be written completely is collected as far as it was written
+	_, _ = msg.WriteTo(&buf)
+
+	m := ParseMessage(buf.Bytes())
+	m.Timestamp = start
+	m.Duration = time.Since(start)
+	m.Error = err
+	collect(ctx, m)
+}
+
+// ParseMessage parses a message in RFC 5322 format. A message that cannot be parsed is returned as plain text body.
+func ParseMessage(msg []byte) collector.Mail {
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return collector.Mail{TextBody: string(msg)}
+	}
+
+	m := collector.Mail{
+		To:      addressList(parsed.Header, "To"),
+		Cc:      addressList(parsed.Header, "Cc"),
+		Bcc:     addressList(parsed.Header, "Bcc"),
+		Subject: decodeHeader(parsed.Header.Get("Subject")),
+	}
+	if from := addressList(parsed.Header, "From"); len(from) > 0 {
+		m.From = from[0]
+	}
+	readPart(&m, textproto.MIMEHeader(parsed.Header), parsed.Body, 0)
+	return m
+}
+
+// readPart adds the bodies and attachments of a part of a message to m
+func readPart(m *collector.Mail, header textproto.MIMEHeader, body io.Reader, depth int) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth {
+			return
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err != nil {
+				return
+			}
+			readPart(m, part.Header, part, depth+1)
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	content := decodeTransferEncoding(header, body)
+
+	isBody := disposition != "attachment" && filename == "" && (mediaType == "text/plain" || mediaType == "text/html")
+	if !isBody {
+		size, _ := io.Copy(io.Discard, content)
+		m.Attachments = append(m.Attachments, collector.MailAttachment{
+			Filename:    decodeHeader(filename),
+			ContentType: mediaType,
+			Size:        int(size),
+			Inline:      disposition == "inline" || (disposition == "" && header.Get("Content-ID") != ""),
+		})
+		return
+	}
+
+	data, _ := io.ReadAll(content)
+	// Only the first body of each type is collected, later ones are alternatives of other parts
+	switch {
+	case mediaType == "text/html" && m.HTMLBody == "":
+		m.HTMLBody = string(data)
+	case mediaType == "text/plain" && m.TextBody == "":
+		m.TextBody = string(data)
+	}
+}
+
+func decodeTransferEncoding(header textproto.MIMEHeader, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// addressList returns the addresses of a header, the raw value if it cannot be parsed
+func addressList(header mail.Header, key string) []string {
+	value := header.Get(key)
+	if value == "" {
+		return nil
+	}
+	list, err := header.AddressList(key)
+	if err != nil {
+		return []string{decodeHeader(value)}
+	}
+	addresses := make([]string, 0, len(list))
+	for _, address := range list {
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
+
+// decodeHeader decodes encoded words (e.g. "=?UTF-8?q?...?=") of a header value
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// envelopeOnly returns the recipients of the envelope that are not listed in the headers of m
+func envelopeOnly(to []string, m collector.Mail) []string {
+	var only []string
+	for _, recipient := range to {
+		if !slices.ContainsFunc(m.Recipients(), func(address string) bool {
+			return strings.EqualFold(address, recipient)
+		}) {
+			only = append(only, recipient)
+		}
+	}
+	return only
+}
@@ -0,0 +1,141 @@
+package mailadapter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	"github.com/networkteam/devlog/mailadapter"
+)
+
+const multipartMessage = "From: Shop <shop@example.com>\r\n" +
+	"To: Jane <jane@example.com>\r\n" +
+	"Cc: support@example.com\r\n" +
+	"Subject: =?UTF-8?q?Your_order_=E2=9C=94?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"\r\n" +
+	"Thanks for your order!\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=UTF-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p style=3D\"color: red\">Thanks for your order!</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename=\"invoice.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-ID: <logo>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--outer--\r\n"
+
+func TestParseMessage(t *testing.T) {
+	m := mailadapter.ParseMessage([]byte(multipartMessage))
+
+	assert.Equal(t, "shop@example.com", m.From)
+	assert.Equal(t, []string{"jane@example.com"}, m.To)
+	assert.Equal(t, []string{"support@example.com"}, m.Cc)
+	assert.Equal(t, "Your order ✔", m.Subject)
+	assert.Equal(t, "Thanks for your order!", m.TextBody)
+	assert.Equal(t, `<p style="color: red">Thanks for your order!</p>`, m.HTMLBody)
+	assert.Equal(t, []collector.MailAttachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf", Size: 9},
+		{ContentType: "image/png", Size: 8, Inline: true},
+	}, m.Attachments)
+}
+
+func TestParseMessage_PlainText(t *testing.T) {
+	m := mailadapter.ParseMessage([]byte("To: jane@example.com\r\nSubject: Hi\r\n\r\nHello Jane"))
+
+	assert.Equal(t, "Hi", m.Subject)
+	assert.Equal(t, "Hello Jane", m.TextBody)
+	assert.Empty(t, m.HTMLBody)
+	assert.Empty(t, m.Attachments)
+}
+
+func TestWrapSendMail(t *testing.T) {
+	mailCollector := collector.NewMailCollector()
+	defer mailCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mails := mailCollector.Subscribe(ctx)
+
+	sendErr := errors.New("550 mailbox unavailable")
+	var sentTo []string
+	sendMail := mailadapter.WrapSendMail(mailCollector.Collect, func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentTo = to
+		return sendErr
+	})
+
+	err := sendMail(ctx, "localhost:1025", nil, "shop@example.com", []string{"jane@example.com", "support@example.com", "audit@example.com"}, []byte(multipartMessage))
+	assert.ErrorIs(t, err, sendErr)
+	assert.Len(t, sentTo, 3)
+
+	m := <-mails
+	assert.Equal(t, "Your order ✔", m.Subject)
+	assert.Equal(t, []string{"audit@example.com"}, m.Bcc, "envelope recipients not in the headers are Bcc")
+	assert.Equal(t, "localhost:1025", m.Server)
+	assert.False(t, m.Timestamp.IsZero())
+	assert.Equal(t, sendErr, m.Error)
+}
+
+type libraryMessage string
+
+func (m libraryMessage) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.Copy(w, strings.NewReader(string(m)))
+	return n, err
+}
+
+func TestCollectMessage(t *testing.T) {
+	var collected []collector.Mail
+	collect := func(ctx context.Context, m collector.Mail) {
+		collected = append(collected, m)
+	}
+
+	start := time.Now()
+	mailadapter.CollectMessage(context.Background(), collect, libraryMessage(multipartMessage), start, nil)
+
+	require.Len(t, collected, 1)
+	assert.Equal(t, []string{"jane@example.com"}, collected[0].To)
+	assert.Equal(t, start, collected[0].Timestamp)
+	assert.Contains(t, collected[0].HTMLBody, "color: red")
+}
+
+func FuzzParseMessage(f *testing.F) {
+	f.Add([]byte(multipartMessage))
+	f.Add([]byte("To: jane@example.com\r\nSubject: Hi\r\n\r\nHello Jane"))
+	f.Add([]byte("Content-Type: multipart/mixed; boundary=a\r\n\r\n--a\r\nContent-Type: multipart/mixed; boundary=a\r\n\r\n--a--\r\n"))
+	f.Add([]byte("Subject: =?UTF-8?b?invalid?=\r\nContent-Type: text/html; charset=\"\r\nContent-Transfer-Encoding: base64\r\n\r\n!!!"))
+
+	f.Fuzz(func(t *testing.T, msg []byte) {
+		m := mailadapter.ParseMessage(msg)
+		for _, attachment := range m.Attachments {
+			if attachment.Size < 0 {
+				t.Fatalf("negative attachment size %d", attachment.Size)
+			}
+		}
+		_ = m.Size()
+	})
+}
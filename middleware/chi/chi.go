@@ -0,0 +1,24 @@
+package chimiddleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Middleware returns a chi middleware that collects requests with collect and adds the matched route pattern (e.g. "/todos/{id}").
+// Pass devlog.Instance.CollectHTTPServer as collect and register the middleware with chi.Router.Use.
+func Middleware(collect func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return collect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			// The pattern is complete after chi routed the request through all sub-routers
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				collector.SetRoutePattern(r.Context(), rctx.RoutePattern())
+			}
+		}))
+	}
+}
@@ -0,0 +1,45 @@
+package chimiddleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	chimiddleware "github.com/networkteam/devlog/middleware/chi"
+)
+
+func TestMiddleware(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := serverCollector.Subscribe(ctx)
+
+	r := chi.NewRouter()
+	r.Use(chimiddleware.Middleware(serverCollector.Middleware))
+	r.Route("/todos", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("todo " + chi.URLParam(r, "id")))
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "todo 42", rec.Body.String())
+
+	req := <-requests
+	assert.Equal(t, "/todos/42", req.Path)
+	assert.Equal(t, "/todos/{id}", req.RoutePattern)
+	assert.Equal(t, http.StatusTeapot, req.StatusCode)
+	require.NotNil(t, req.ResponseBody)
+	assert.Equal(t, "todo 42", req.ResponseBody.String())
+}
@@ -0,0 +1,38 @@
+package echomiddleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Middleware returns an Echo middleware that collects requests with collect and adds the matched route pattern (e.g. "/todos/:id").
+// Pass devlog.Instance.CollectHTTPServer as collect and register the middleware with echo.Echo.Use.
+func Middleware(collect func(http.Handler) http.Handler) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			origReq := c.Request()
+			origWriter := c.Response().Writer
+			defer func() {
+				c.SetRequest(origReq)
+				c.Response().Writer = origWriter
+			}()
+
+			collect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				c.Response().Writer = w
+
+				// Handle errors here, so the error response is written to the collected response
+				if err := next(c); err != nil {
+					c.Error(err)
+				}
+
+				collector.SetRoutePattern(r.Context(), c.Path())
+			})).ServeHTTP(origWriter, origReq)
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package echomiddleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	echomiddleware "github.com/networkteam/devlog/middleware/echo"
+)
+
+func TestMiddleware(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := serverCollector.Subscribe(ctx)
+
+	e := echo.New()
+	e.Use(echomiddleware.Middleware(serverCollector.Middleware))
+	e.GET("/todos/:id", func(c echo.Context) error {
+		return c.String(http.StatusTeapot, "todo "+c.Param("id"))
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "todo 42", rec.Body.String())
+
+	req := <-requests
+	assert.Equal(t, "/todos/42", req.Path)
+	assert.Equal(t, "/todos/:id", req.RoutePattern)
+	assert.Equal(t, http.StatusTeapot, req.StatusCode)
+	require.NotNil(t, req.ResponseBody)
+	assert.Equal(t, "todo 42", req.ResponseBody.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = <-requests
+	assert.Equal(t, "/fail", req.RoutePattern)
+	assert.Equal(t, http.StatusBadRequest, req.StatusCode)
+	require.NotNil(t, req.ResponseBody)
+	assert.Contains(t, req.ResponseBody.String(), "invalid")
+}
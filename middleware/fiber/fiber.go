@@ -0,0 +1,60 @@
+package fibermiddleware
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Middleware returns a Fiber middleware that collects requests with collect and adds the matched route pattern (e.g. "/todos/:id").
+// Pass devlog.Instance.CollectHTTPServer as collect and register the middleware with fiber.App.Use.
+// Handlers must use fiber.Ctx.UserContext to pass the context to other collectors.
+func Middleware(collect func(http.Handler) http.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req, err := adaptor.ConvertRequest(c, true)
+		if err != nil {
+			return err
+		}
+
+		var handlerErr error
+		collect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.SetUserContext(r.Context())
+
+			// Handle errors here, so the error response is written to the collected response
+			if err := c.Next(); err != nil {
+				if err := c.App().ErrorHandler(c, err); err != nil {
+					handlerErr = err
+				}
+			}
+
+			collector.SetRoutePattern(r.Context(), c.Route().Path)
+
+			// Fiber writes the response to fasthttp, copy it to the writer of the collector
+			c.Response().Header.VisitAll(func(key, value []byte) {
+				w.Header().Add(string(key), string(value))
+			})
+			w.WriteHeader(c.Response().StatusCode())
+			_, _ = w.Write(c.Response().Body())
+		})).ServeHTTP(discardResponseWriter{header: make(http.Header)}, req)
+
+		return handlerErr
+	}
+}
+
+// discardResponseWriter is the underlying writer of the collector, since Fiber already wrote the response
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header {
+	return d.header
+}
+
+func (d discardResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (d discardResponseWriter) WriteHeader(int) {}
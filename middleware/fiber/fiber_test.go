@@ -0,0 +1,59 @@
+package fibermiddleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	fibermiddleware "github.com/networkteam/devlog/middleware/fiber"
+)
+
+func TestMiddleware(t *testing.T) {
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := serverCollector.Subscribe(ctx)
+
+	app := fiber.New()
+	app.Use(fibermiddleware.Middleware(serverCollector.Middleware))
+	app.Get("/todos/:id", func(c *fiber.Ctx) error {
+		c.Set("X-Todo", c.Params("id"))
+		return c.Status(http.StatusTeapot).SendString("todo " + c.Params("id"))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(http.StatusBadRequest, "invalid")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	assert.Equal(t, "todo 42", string(body))
+
+	req := <-requests
+	assert.Equal(t, "/todos/42", req.Path)
+	assert.Equal(t, "/todos/:id", req.RoutePattern)
+	assert.Equal(t, http.StatusTeapot, req.StatusCode)
+	assert.Equal(t, "42", req.ResponseHeaders.Get("X-Todo"))
+	require.NotNil(t, req.ResponseBody)
+	assert.Equal(t, "todo 42", req.ResponseBody.String())
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req = <-requests
+	assert.Equal(t, "/fail", req.RoutePattern)
+	assert.Equal(t, http.StatusBadRequest, req.StatusCode)
+	require.NotNil(t, req.ResponseBody)
+	assert.Equal(t, "invalid", req.ResponseBody.String())
+}
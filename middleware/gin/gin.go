@@ -0,0 +1,54 @@
+package ginmiddleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Middleware returns a Gin middleware that collects requests with collect and adds the matched route pattern (e.g. "/todos/:id").
+// Pass devlog.Instance.CollectHTTPServer as collect and register the middleware with gin.Engine.Use.
+func Middleware(collect func(http.Handler) http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origReq := c.Request
+		origWriter := c.Writer
+		defer func() {
+			c.Request = origReq
+			c.Writer = origWriter
+		}()
+
+		collect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.Request = r
+			c.Writer = &responseWriter{ResponseWriter: origWriter, w: w}
+
+			c.Next()
+
+			collector.SetRoutePattern(r.Context(), c.FullPath())
+		})).ServeHTTP(origWriter, origReq)
+	}
+}
+
+// responseWriter passes writes through the writer of the collector, which forwards them to the Gin response writer
+type responseWriter struct {
+	gin.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (rw *responseWriter) Header() http.Header {
+	return rw.w.Header()
+}
+
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	rw.w.WriteHeader(statusCode)
+}
+
+func (rw *responseWriter) Write(data []byte) (int, error) {
+	return rw.w.Write(data)
+}
+
+func (rw *responseWriter) WriteString(s string) (int, error) {
+	return io.WriteString(rw.w, s)
+}
@@ -0,0 +1,44 @@
+package ginmiddleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkteam/devlog/collector"
+	ginmiddleware "github.com/networkteam/devlog/middleware/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serverCollector := collector.NewHTTPServerCollector()
+	defer serverCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := serverCollector.Subscribe(ctx)
+
+	r := gin.New()
+	r.Use(ginmiddleware.Middleware(serverCollector.Middleware))
+	r.GET("/todos/:id", func(c *gin.Context) {
+		c.String(http.StatusTeapot, "todo %s", c.Param("id"))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/42", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "todo 42", rec.Body.String())
+
+	req := <-requests
+	assert.Equal(t, "/todos/42", req.Path)
+	assert.Equal(t, "/todos/:id", req.RoutePattern)
+	assert.Equal(t, http.StatusTeapot, req.StatusCode)
+	require.NotNil(t, req.ResponseBody)
+	assert.Equal(t, "todo 42", req.ResponseBody.String())
+}
@@ -0,0 +1,75 @@
+package devlog
+
+import (
+	"log/slog"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+type Options struct {
+	// LogOptions are the options for the log collector.
+	// Default: nil, will use collector.DefaultLogOptions()
+	LogOptions *collector.LogOptions
+
+	// HTTPClientOptions are the options for the HTTP client collector.
+	// Default: nil, will use collector.DefaultHTTPClientOptions()
+	HTTPClientOptions *collector.HTTPClientOptions
+
+	// HTTPServerOptions are the options for the HTTP server collector.
+	// Default: nil, will use collector.DefaultHTTPServerOptions()
+	HTTPServerOptions *collector.HTTPServerOptions
+
+	// DBQueryOptions are the options for the database query collector.
+	// Default: nil, will use collector.DefaultDBQueryOptions()
+	DBQueryOptions *collector.DBQueryOptions
+
+	// RPCCallOptions are the options for the RPC call collector.
+	// Default: nil, will use collector.DefaultRPCCallOptions()
+	RPCCallOptions *collector.RPCCallOptions
+
+	// CacheOpOptions are the options for the cache operation collector.
+	// Default: nil, will use collector.DefaultCacheOpOptions()
+	CacheOpOptions *collector.CacheOpOptions
+
+	// FlagEvaluationOptions are the options for the feature flag evaluation collector.
+	// Default: nil, will use collector.DefaultFlagEvaluationOptions()
+	FlagEvaluationOptions *collector.FlagEvaluationOptions
+
+	// MailOptions are the options for the mail collector.
+	// Default: nil, will use collector.DefaultMailOptions()
+	MailOptions *collector.MailOptions
+
+	// DBPoolSamplerOptions are the options for the database connection pool sampler.
+	// Default: nil, will use collector.DefaultDBPoolSamplerOptions()
+	DBPoolSamplerOptions *collector.DBPoolSamplerOptions
+
+	// BodyBufferPoolSize limits the memory of all request and response bodies captured by the HTTP client and server
+	// collectors, the least recently written bodies are evicted if it is exceeded (see collector.BodyBufferPool).
+	// It is ignored for collectors with a BodyBufferPool in their options.
+	// Default: 0, will use collector.DefaultBodyBufferPoolSize
+	BodyBufferPoolSize uint64
+
+	// EventProcessors run in order on every completed event of all collectors, e.g. to redact, sample, enrich or drop
+	// events in one place (see collector.EventProcessor).
+	// Default: nil, events are collected unchanged
+	EventProcessors []collector.EventProcessor
+
+	// SeverityThresholds rate events slower than a threshold as warning (see collector.Severity).
+	// Default: zero value, will use collector.DefaultSeverityThresholds()
+	SeverityThresholds collector.SeverityThresholds
+
+	// Disabled starts the instance dormant: collectors pass everything through until Enable is called (see
+	// Instance.Disable). This allows to compile devlog into a production build and enable it only when needed.
+	// Default: false
+	Disabled bool
+
+	// Logger is used for internal logging of devlog, e.g. session cleanup, dropped events and recovered panics.
+	// It is also used by the dashboard unless dashboard.WithLogger is given.
+	// Default: nil, devlog does not log anything
+	Logger *slog.Logger
+}
+
+// New creates a new devlog dashboard with default options.
+func New() *Instance {
+	return NewWithOptions(Options{})
+}
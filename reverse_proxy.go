@@ -0,0 +1,28 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// ReverseProxy returns a handler that forwards requests to upstream (e.g. http://localhost:3000) and captures both the
+// incoming requests and the forwarded requests with their responses. Put it in front of a service that is not
+// instrumented (e.g. written in another language) to show its traffic in the dashboard.
+// Use CollectReverseProxy to capture a customized httputil.ReverseProxy.
+func (i *Instance) ReverseProxy(upstream *url.URL) http.Handler {
+	return i.CollectReverseProxy(httputil.NewSingleHostReverseProxy(upstream))
+}
+
+// CollectReverseProxy captures the requests of a reverse proxy. The transport of proxy is wrapped to collect forwarded
+// requests (http.DefaultTransport if it is nil), which are nested under the incoming request.
+func (i *Instance) CollectReverseProxy(proxy *httputil.ReverseProxy) http.Handler {
+	transport := proxy.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	proxy.Transport = i.CollectHTTPClient(transport)
+	return i.CollectHTTPServer(proxy)
+}
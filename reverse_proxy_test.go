@@ -0,0 +1,64 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/networkteam/devlog"
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInstance_ReverseProxy(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	capture, err := dlog.StartGlobalCapture(10)
+	if err != nil {
+		t.Fatalf("failed to start capture: %v", err)
+	}
+	defer capture.Stop()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created " + string(body)))
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+
+	proxy := httptest.NewServer(dlog.ReverseProxy(upstreamURL))
+	defer proxy.Close()
+
+	resp, err := http.Post(proxy.URL+"/todos", "text/plain", strings.NewReader("todo"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || string(body) != "created todo" {
+		t.Fatalf("expected response of upstream, got %d %q", resp.StatusCode, body)
+	}
+
+	events := capture.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 captured event, got %d", len(events))
+	}
+	request, ok := events[0].Data.(collector.HTTPServerRequest)
+	if !ok || request.Path != "/todos" || request.RequestBody.String() != "todo" || request.ResponseBody.String() != "created todo" {
+		t.Errorf("expected the incoming request with its bodies, got %#v", events[0].Data)
+	}
+	if len(events[0].Children) != 1 {
+		t.Fatalf("expected the forwarded request nested under the incoming request, got %d children", len(events[0].Children))
+	}
+	forwarded, ok := events[0].Children[0].Data.(collector.HTTPClientRequest)
+	if !ok || forwarded.URL != upstream.URL+"/todos" || forwarded.StatusCode != http.StatusCreated {
+		t.Errorf("expected the forwarded request to the upstream, got %#v", events[0].Children[0].Data)
+	}
+}
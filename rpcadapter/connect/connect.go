@@ -0,0 +1,101 @@
+package connectadapter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// Interceptor is a connect.Interceptor that collects RPC calls with the given collect function.
+// Unary calls are collected with their request and response messages rendered as JSON.
+// Streaming handlers are collected without messages, streaming client calls are not collected.
+type Interceptor struct {
+	collect func(ctx context.Context, call collector.RPCCall)
+}
+
+var _ connect.Interceptor = &Interceptor{}
+
+// NewInterceptor creates an interceptor that collects calls with collect, e.g. devlog.Instance.CollectRPCCall().
+// Add it to handlers and clients with connect.WithInterceptors.
+func NewInterceptor(collect func(ctx context.Context, call collector.RPCCall)) *Interceptor {
+	return &Interceptor{collect: collect}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+
+		call := newRPCCall(req.Spec(), req.Peer(), start, err)
+		call.Request = messageJSON(req.Any())
+		// The response is a typed nil pointer if the call failed
+		if err == nil {
+			call.Response = messageJSON(resp.Any())
+		}
+		i.collect(ctx, call)
+
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		start := time.Now()
+		err := next(ctx, conn)
+
+		call := newRPCCall(conn.Spec(), conn.Peer(), start, err)
+		call.Stream = true
+		i.collect(ctx, call)
+
+		return err
+	}
+}
+
+func newRPCCall(spec connect.Spec, peer connect.Peer, start time.Time, err error) collector.RPCCall {
+	call := collector.RPCCall{
+		Procedure: spec.Procedure,
+		Protocol:  peer.Protocol,
+		Client:    spec.IsClient,
+		Peer:      peer.Addr,
+		Timestamp: start,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		call.Error = err
+		call.Code = connect.CodeOf(err).String()
+	}
+	return call
+}
+
+// messageJSON renders a message as JSON, using protojson for protobuf messages
+func messageJSON(msg any) string {
+	if msg == nil {
+		return ""
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if m, ok := msg.(proto.Message); ok {
+		data, err = protojson.Marshal(m)
+	} else {
+		data, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
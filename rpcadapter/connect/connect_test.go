@@ -0,0 +1,101 @@
+package connectadapter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/networkteam/devlog/collector"
+	connectadapter "github.com/networkteam/devlog/rpcadapter/connect"
+)
+
+const greetProcedure = "/greet.v1.GreetService/Greet"
+
+func TestInterceptor(t *testing.T) {
+	rpcCollector := collector.NewRPCCallCollector()
+	defer rpcCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := rpcCollector.Subscribe(ctx)
+
+	interceptor := connectadapter.NewInterceptor(rpcCollector.Collect)
+
+	mux := http.NewServeMux()
+	mux.Handle(greetProcedure, connect.NewUnaryHandler(
+		greetProcedure,
+		func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+			if req.Msg.Value == "" {
+				return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("name is required"))
+			}
+			return connect.NewResponse(wrapperspb.String("Hello, " + req.Msg.Value)), nil
+		},
+		connect.WithInterceptors(interceptor),
+	))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](server.Client(), server.URL+greetProcedure)
+
+	resp, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("devlog")))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, devlog", resp.Msg.Value)
+
+	call := <-calls
+	assert.Equal(t, greetProcedure, call.Procedure)
+	assert.Equal(t, connect.ProtocolConnect, call.Protocol)
+	assert.False(t, call.Client)
+	assert.False(t, call.Stream)
+	assert.JSONEq(t, `"devlog"`, call.Request)
+	assert.JSONEq(t, `"Hello, devlog"`, call.Response)
+	assert.Empty(t, call.Code)
+	assert.NoError(t, call.Error)
+
+	_, err = client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("")))
+	require.Error(t, err)
+
+	call = <-calls
+	assert.Equal(t, "invalid_argument", call.Code)
+	assert.Error(t, call.Error)
+	assert.Empty(t, call.Response)
+}
+
+func TestInterceptor_Client(t *testing.T) {
+	rpcCollector := collector.NewRPCCallCollector()
+	defer rpcCollector.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := rpcCollector.Subscribe(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle(greetProcedure, connect.NewUnaryHandler(
+		greetProcedure,
+		func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+			return connect.NewResponse(wrapperspb.String("Hello, " + req.Msg.Value)), nil
+		},
+	))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](
+		server.Client(),
+		server.URL+greetProcedure,
+		connect.WithInterceptors(connectadapter.NewInterceptor(rpcCollector.Collect)),
+	)
+
+	_, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("devlog")))
+	require.NoError(t, err)
+
+	call := <-calls
+	assert.Equal(t, greetProcedure, call.Procedure)
+	assert.True(t, call.Client)
+	assert.JSONEq(t, `"Hello, devlog"`, call.Response)
+}
@@ -0,0 +1,58 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkteam/devlog"
+)
+
+func TestInstance_Shutdown(t *testing.T) {
+	dlog := devlog.New()
+
+	capture, err := dlog.StartGlobalCapture(10)
+	if err != nil {
+		t.Fatalf("failed to start capture: %v", err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})))
+	defer server.Close()
+
+	go func() {
+		resp, err := http.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	// The request in progress is collected before the instance is closed
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := dlog.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	if got := len(capture.Events()); got != 1 {
+		t.Errorf("expected the request in progress to be captured, got %d events", got)
+	}
+
+	// Closing after shutting down is a no-op
+	dlog.Close()
+	capture.Stop()
+}
@@ -0,0 +1,33 @@
+//go:build !devlog_off
+
+package devlog
+
+import (
+	"sync"
+
+	"github.com/networkteam/devlog/collector"
+)
+
+// RegisterSink forwards all captured events to sink in addition to the dashboard, e.g. to a collector.FileSink,
+// collector.HTTPSink or collector.OTLPSink. Events are exported in batches on a separate goroutine
+// (see collector.SinkStorage), zero options use the defaults of collector.DefaultSinkOptions.
+// Call unregister to stop forwarding, which exports pending events and closes the sink. Sinks that are still
+// registered are closed with the instance.
+//
+//	sink, err := collector.NewFileSink("events.ndjson")
+//	if err != nil {
+//	    return err
+//	}
+//	dlog.RegisterSink(sink, collector.SinkOptions{})
+func (i *Instance) RegisterSink(sink collector.Sink, options collector.SinkOptions) (unregister func()) {
+	storage := collector.NewSinkStorage(sink, options)
+	i.eventAggregator.RegisterStorage(storage)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			i.eventAggregator.UnregisterStorage(storage.ID())
+			storage.Close()
+		})
+	}
+}
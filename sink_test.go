@@ -0,0 +1,56 @@
+//go:build !devlog_off
+
+package devlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/networkteam/devlog"
+	"github.com/networkteam/devlog/collector"
+)
+
+func TestInstance_RegisterSink(t *testing.T) {
+	dlog := devlog.New()
+	defer dlog.Close()
+
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink, err := collector.NewFileSink(path)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	unregister := dlog.RegisterSink(sink, collector.SinkOptions{})
+
+	server := httptest.NewServer(dlog.CollectHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/tea")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Unregistering exports the pending events
+	unregister()
+	unregister()
+	dlog.CollectEvent(context.Background(), "after unregister")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 exported event, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `"path":"/tea"`) {
+		t.Errorf("expected exported request to /tea, got %s", lines[0])
+	}
+}